@@ -0,0 +1,502 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrwallet/internal/zero"
+	"github.com/decred/dcrwallet/snacl"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletdb"
+	"github.com/decred/dcrwallet/walletdb/cryptdb"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// Backups are wallet databases of the configured database backend whose keys
+// and values are encrypted by cryptdb with a key protected by the backup
+// passphrase.  Besides the wallet namespaces, each backup holds the time it
+// was written and a description of the imported keys and scripts, which can
+// not be recovered from the wallet seed, in the backup namespace.
+var (
+	backupNamespaceKey = []byte("backup")
+	backupCreatedKey   = []byte("created")
+	backupImportedKey  = []byte("imported")
+)
+
+// backupTimeFormat is the format of the UTC time appended to the database
+// file name to name each backup.  Backups sort by name in the order they were
+// written.
+const backupTimeFormat = "20060102T150405Z"
+
+// partialBackupSuffix is appended to the name of a backup until it has been
+// written and verified.
+const partialBackupSuffix = ".partial"
+
+// importedMaterial describes the imported keys and scripts of a wallet.
+// Unlike every other key of the wallet, these can not be recovered from the
+// seed, so a wallet restored from its seed must import them again.
+type importedMaterial struct {
+	PubKeyAddresses []importedPubKeyAddress `json:"pubkeyaddresses"`
+	Scripts         []string                `json:"scripts"`
+}
+
+// importedPubKeyAddress is an address of an imported key and its public key.
+type importedPubKeyAddress struct {
+	Address string `json:"address"`
+	PubKey  string `json:"pubkey"`
+}
+
+// walletImportedMaterial describes the imported keys and scripts of w.  Only
+// public data is described, so the wallet does not need to be unlocked.
+func walletImportedMaterial(w *wallet.Wallet) (*importedMaterial, error) {
+	m := &importedMaterial{
+		PubKeyAddresses: []importedPubKeyAddress{},
+		Scripts:         []string{},
+	}
+	err := w.Manager.ForEachAccountAddress(waddrmgr.ImportedAddrAccount,
+		func(maddr waddrmgr.ManagedAddress) error {
+			pka, ok := maddr.(waddrmgr.ManagedPubKeyAddress)
+			if !ok {
+				return nil
+			}
+			m.PubKeyAddresses = append(m.PubKeyAddresses,
+				importedPubKeyAddress{
+					Address: pka.Address().EncodeAddress(),
+					PubKey:  pka.ExportPubKey(),
+				})
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	scripts, err := w.TxStore.StoredTxScripts()
+	if err != nil {
+		return nil, err
+	}
+	for _, script := range scripts {
+		m.Scripts = append(m.Scripts, hex.EncodeToString(script))
+	}
+	return m, nil
+}
+
+// backupFilePrefix returns the prefix of the names of backups of the database
+// backend dbType.
+func backupFilePrefix(dbType string) string {
+	return walletDbFilename(dbType) + "."
+}
+
+// backupWallet writes an encrypted backup of the wallet namespaces of db and
+// the imported material of w to a new file in dir, verifies that the backup
+// can be opened and read, and then removes the oldest backups until at most
+// keep remain.  The path of the new backup is returned.
+func backupWallet(w *wallet.Wallet, db walletdb.DB, dir, dbType string,
+	pass []byte, keep int) (string, error) {
+	material, err := walletImportedMaterial(w)
+	if err != nil {
+		return "", err
+	}
+	if err := checkCreateDir(dir); err != nil {
+		return "", err
+	}
+
+	created := time.Now().UTC()
+	path := filepath.Join(dir, backupFilePrefix(dbType)+
+		created.Format(backupTimeFormat))
+	partialPath := path + partialBackupSuffix
+	err = writeBackup(partialPath, dbType, pass, db, created, material)
+	if err != nil {
+		os.RemoveAll(partialPath)
+		return "", err
+	}
+	if _, _, err := verifyBackup(partialPath, dbType, pass); err != nil {
+		os.RemoveAll(partialPath)
+		return "", fmt.Errorf("backup failed verification: %v", err)
+	}
+	if err := os.Rename(partialPath, path); err != nil {
+		os.RemoveAll(partialPath)
+		return "", err
+	}
+
+	return path, rotateBackups(dir, dbType, keep)
+}
+
+// writeBackup creates the encrypted backup database at path and copies the
+// wallet namespaces of src into it from a single consistent view of src.
+func writeBackup(path, dbType string, pass []byte, src walletdb.DB,
+	created time.Time, material *importedMaterial) error {
+	imported, err := json.Marshal(material)
+	if err != nil {
+		return err
+	}
+
+	rawDst, err := walletdb.Create(dbType, path)
+	if err != nil {
+		return err
+	}
+	dst, err := cryptdb.Create(rawDst, pass)
+	if err != nil {
+		rawDst.Close()
+		return err
+	}
+	defer dst.Close()
+
+	if err := copyWalletNamespaces(dst, src); err != nil {
+		return err
+	}
+
+	ns, err := dst.Namespace(backupNamespaceKey)
+	if err != nil {
+		return err
+	}
+	return ns.Update(func(tx walletdb.Tx) error {
+		root := tx.RootBucket()
+		createdBytes, err := created.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if err := root.Put(backupCreatedKey, createdBytes); err != nil {
+			return err
+		}
+		return root.Put(backupImportedKey, imported)
+	})
+}
+
+// copyWalletNamespaces copies every wallet namespace of src into dst.  The
+// namespaces are read in a single transaction of src, so a backup of a running
+// wallet never mixes the state of one namespace before a write with the state
+// of another namespace after it.
+func copyWalletNamespaces(dst, src walletdb.DB) error {
+	keys := make([][]byte, len(walletNamespaces))
+	for i, wns := range walletNamespaces {
+		keys[i] = wns.key
+	}
+	return walletdb.CopyNamespaces(dst, src, keys)
+}
+
+// openBackup opens the encrypted backup database at path and checks every
+// record of its wallet namespaces.  The time the backup was written and the
+// imported material it describes are returned with the decrypted database.
+func openBackup(path, dbType string, pass []byte) (walletdb.DB, time.Time,
+	*importedMaterial, error) {
+	var created time.Time
+	if !fileExists(path) {
+		return nil, created, nil, fmt.Errorf("backup %s does not exist",
+			path)
+	}
+	rawDb, err := walletdb.Open(dbType, path)
+	if err != nil {
+		return nil, created, nil, err
+	}
+	db, err := cryptdb.Open(rawDb, pass)
+	if err != nil {
+		rawDb.Close()
+		return nil, created, nil, err
+	}
+
+	material, err := readBackupInfo(db, &created)
+	if err != nil {
+		db.Close()
+		return nil, created, nil, err
+	}
+	for _, wns := range walletNamespaces {
+		ns, err := db.Namespace(wns.key)
+		if err != nil {
+			db.Close()
+			return nil, created, nil, err
+		}
+		problems, err := walletdb.CheckNamespace(ns, wns.validate)
+		if err != nil {
+			db.Close()
+			return nil, created, nil, err
+		}
+		if len(problems) != 0 {
+			db.Close()
+			return nil, created, nil, fmt.Errorf("invalid %s "+
+				"record %v", wns.name, problems[0])
+		}
+	}
+	return db, created, material, nil
+}
+
+// readBackupInfo reads the time a backup was written into created and returns
+// the imported material described by the backup.
+func readBackupInfo(db walletdb.DB, created *time.Time) (*importedMaterial,
+	error) {
+	ns, err := db.Namespace(backupNamespaceKey)
+	if err != nil {
+		return nil, err
+	}
+	material := new(importedMaterial)
+	err = ns.View(func(tx walletdb.Tx) error {
+		root := tx.RootBucket()
+		createdBytes := root.Get(backupCreatedKey)
+		imported := root.Get(backupImportedKey)
+		if createdBytes == nil || imported == nil {
+			return errors.New("missing backup description")
+		}
+		if err := created.UnmarshalBinary(createdBytes); err != nil {
+			return err
+		}
+		return json.Unmarshal(imported, material)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return material, nil
+}
+
+// verifyBackup checks that the encrypted backup at path can be opened with
+// pass and that every record of it is valid.
+func verifyBackup(path, dbType string, pass []byte) (time.Time,
+	*importedMaterial, error) {
+	db, created, material, err := openBackup(path, dbType, pass)
+	if err != nil {
+		return created, nil, err
+	}
+	return created, material, db.Close()
+}
+
+// rotateBackups removes the oldest backups of the database backend dbType in
+// dir until at most keep remain.  Partially written backups left behind by
+// interrupted backups are removed as well.
+func rotateBackups(dir, dbType string, keep int) error {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	// The entries are sorted by name, and so by the time each backup was
+	// written.
+	prefix := backupFilePrefix(dbType)
+	var backups []string
+	for _, fi := range fis {
+		name := fi.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if strings.HasSuffix(name, partialBackupSuffix) {
+			err := os.RemoveAll(filepath.Join(dir, name))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		backups = append(backups, name)
+	}
+	for len(backups) > keep {
+		if err := os.RemoveAll(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		log.Infof("Removed old wallet backup %s", backups[0])
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// runBackupCmd runs the configured backup command with the path of a new
+// backup as its final argument.
+func runBackupCmd(command, path string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil
+	}
+	args = append(args, path)
+	output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
+
+// backupPeriodically writes a backup of the wallet to the configured backup
+// directory when started and then after each backup interval, until quit is
+// closed.  Failed backups are logged and retried after the next interval.
+func backupPeriodically(w *wallet.Wallet, db walletdb.DB,
+	quit <-chan struct{}) {
+	ticker := time.NewTicker(cfg.BackupInterval)
+	defer ticker.Stop()
+
+	for {
+		path, err := backupWallet(w, db, cfg.BackupDir, cfg.DbType,
+			[]byte(cfg.BackupPass), cfg.BackupCount)
+		if err != nil {
+			log.Errorf("Unable to back up wallet: %v", err)
+		} else {
+			log.Infof("Wrote verified wallet backup %s", path)
+			if err := runBackupCmd(cfg.BackupCmd, path); err != nil {
+				log.Errorf("Backup command failed: %v", err)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// restoreBackup creates the wallet database at dbPath from the encrypted
+// backup at backupPath.  The restored database is encrypted with the public
+// passphrase when database encryption is enabled.
+func restoreBackup(cfg *config, backupPath, dbPath string) error {
+	backup, created, material, err := openBackup(backupPath, cfg.DbType,
+		[]byte(cfg.BackupPass))
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	// Remove the partially restored database if the restore fails, so the
+	// restore may be retried.
+	db, err := createDb(cfg, dbPath, []byte(cfg.WalletPass))
+	if err != nil {
+		return err
+	}
+	if err := copyWalletNamespaces(db, backup); err != nil {
+		db.Close()
+		os.RemoveAll(dbPath)
+		return err
+	}
+	if err := db.Close(); err != nil {
+		os.RemoveAll(dbPath)
+		return err
+	}
+
+	fmt.Printf("Restored the wallet from the backup written %v, "+
+		"describing %d imported keys and %d scripts.\n",
+		created.Local(), len(material.PubKeyAddresses),
+		len(material.Scripts))
+	return nil
+}
+
+// restoreSeedBackup creates the wallet database at dbPath from the seed
+// backup file at path, written by the exportseedbackup RPC.  The user is
+// prompted for the passphrase of the seed backup and the passphrases of the
+// restored wallet.
+func restoreSeedBackup(cfg *config, path, dbPath string) error {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var b *wallet.SeedBackup
+	for b == nil {
+		pass, err := promptConsolePass(reader, "Enter the passphrase of "+
+			"the seed backup", false)
+		if err != nil {
+			return err
+		}
+		b, err = wallet.DecodeSeedBackup(file, pass)
+		if err == snacl.ErrInvalidPassword {
+			fmt.Println("The passphrase is incorrect")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if b.Network != activeNet.Params.Name {
+		return fmt.Errorf("the seed backup is for the %s network",
+			b.Network)
+	}
+	seed, err := hex.DecodeString(b.Seed)
+	if err != nil {
+		return err
+	}
+	defer zero.Bytes(seed)
+
+	privPass, err := promptConsolePrivatePass(reader, nil)
+	if err != nil {
+		return err
+	}
+	pubPass, err := promptConsolePublicPass(reader, privPass, cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Restoring the wallet...")
+
+	// Remove the partially restored database if the restore fails, so the
+	// restore may be retried.
+	db, err := createDb(cfg, dbPath, pubPass)
+	if err != nil {
+		return err
+	}
+	err = restoreSeedBackupNamespaces(db, b, seed, pubPass, privPass)
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dbPath)
+		return err
+	}
+	if err := db.Close(); err != nil {
+		os.RemoveAll(dbPath)
+		return err
+	}
+
+	fmt.Printf("Restored the wallet from the seed backup written %v, "+
+		"describing %d accounts, %d imported keys and %d scripts.\n",
+		time.Unix(b.Created, 0), len(b.Accounts),
+		len(b.ImportedKeys)+len(b.ImportedPubKeys),
+		len(b.ImportedScripts))
+	return nil
+}
+
+// restoreSeedBackupNamespaces creates the address manager and transaction
+// store of a new wallet database and restores the seed backup to them.
+func restoreSeedBackupNamespaces(db walletdb.DB, b *wallet.SeedBackup,
+	seed, pubPass, privPass []byte) error {
+	waddrmgrNamespace, err := db.Namespace(waddrmgrNamespaceKey)
+	if err != nil {
+		return err
+	}
+	manager, err := waddrmgr.Create(waddrmgrNamespace, seed, pubPass,
+		privPass, activeNet.Params, nil)
+	if err != nil {
+		return err
+	}
+	defer manager.Close()
+	if err := manager.Unlock(privPass); err != nil {
+		return err
+	}
+
+	wtxmgrNamespace, err := db.Namespace(wtxmgrNamespaceKey)
+	if err != nil {
+		return err
+	}
+	txStore, err := wtxmgr.Create(wtxmgrNamespace, activeNet.Params)
+	if err != nil {
+		return err
+	}
+	defer txStore.Close()
+
+	return wallet.RestoreSeedBackup(b, manager, txStore)
+}
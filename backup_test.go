@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+func TestBackupRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dcrwallet-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := walletdb.Create("bdb", filepath.Join(dir, "src.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	created := time.Unix(1476748800, 0).UTC()
+	material := &importedMaterial{
+		PubKeyAddresses: []importedPubKeyAddress{},
+		Scripts:         []string{"51"},
+	}
+	path := filepath.Join(dir, "backup.db")
+	pass := []byte("backup passphrase")
+	err = writeBackup(path, "bdb", pass, src, created, material)
+	if err != nil {
+		t.Fatalf("writeBackup: %v", err)
+	}
+
+	gotCreated, gotMaterial, err := verifyBackup(path, "bdb", pass)
+	if err != nil {
+		t.Fatalf("verifyBackup: %v", err)
+	}
+	if !gotCreated.Equal(created) {
+		t.Errorf("backup created %v, expected %v", gotCreated, created)
+	}
+	if !reflect.DeepEqual(gotMaterial, material) {
+		t.Errorf("backup describes %+v, expected %+v", gotMaterial,
+			material)
+	}
+
+	_, _, err = verifyBackup(path, "bdb", []byte("wrong passphrase"))
+	if err == nil {
+		t.Errorf("backup opened with the wrong passphrase")
+	}
+}
+
+func TestRotateBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dcrwallet-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	names := []string{
+		"wallet.db.20161018T000000Z",
+		"wallet.db.20161019T000000Z",
+		"wallet.db.20161020T000000Z",
+		"wallet.db.20161021T000000Z.partial",
+		"unrelated",
+	}
+	for _, name := range names {
+		err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := rotateBackups(dir, "bdb", 2); err != nil {
+		t.Fatalf("rotateBackups: %v", err)
+	}
+	for i, name := range names {
+		_, err := os.Stat(filepath.Join(dir, name))
+		// The oldest backup and the partial backup are removed.
+		if exists := err == nil; exists != (i == 1 || i == 2 || i == 4) {
+			t.Errorf("%s exists is %v after rotation", name, exists)
+		}
+	}
+}
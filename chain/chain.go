@@ -43,6 +43,11 @@ type Client struct {
 	dequeueVotingNotification chan interface{}
 	currentBlock              chan *waddrmgr.BlockStamp
 
+	// Connection state reported by Stats.
+	connect  string
+	statsMtx sync.Mutex
+	stats    ClientStats
+
 	// Information for reorganization handling.
 	reorganizingLock sync.Mutex
 	reorganizeToHash chainhash.Hash
@@ -56,12 +61,15 @@ type Client struct {
 
 // NewClient creates a client connection to the server described by the connect
 // string.  If disableTLS is false, the remote RPC certificate must be provided
-// in the certs slice.  The connection is not established immediately, but must
-// be done using the Start method.  If the remote server does not operate on
-// the same decred network as described by the passed chain parameters, the
-// connection will be disconnected.
+// in the certs slice.  If proxy is not empty, the connection is made through
+// the SOCKS5 proxy at that address, authenticating with proxyUser and
+// proxyPass.  The connection is not established immediately, but must be done
+// using the Start method.  If the remote server does not operate on the same
+// decred network as described by the passed chain parameters, the connection
+// will be disconnected.
 func NewClient(chainParams *chaincfg.Params, connect, user, pass string,
-	certs []byte, disableTLS bool) (*Client, error) {
+	certs []byte, disableTLS bool, proxy, proxyUser,
+	proxyPass string) (*Client, error) {
 	client := Client{
 		reorganizeToHash:          chainhash.Hash{},
 		reorganizing:              false,
@@ -72,6 +80,7 @@ func NewClient(chainParams *chaincfg.Params, connect, user, pass string,
 		dequeueVotingNotification: make(chan interface{}),
 		currentBlock:              make(chan *waddrmgr.BlockStamp),
 		quit:                      make(chan struct{}),
+		connect:                   connect,
 	}
 	ntfnCallbacks := dcrrpcclient.NotificationHandlers{
 		OnClientConnected:       client.onClientConnect,
@@ -95,6 +104,9 @@ func NewClient(chainParams *chaincfg.Params, connect, user, pass string,
 		DisableAutoReconnect: true,
 		DisableConnectOnNew:  true,
 		DisableTLS:           disableTLS,
+		Proxy:                proxy,
+		ProxyUser:            proxyUser,
+		ProxyPass:            proxyPass,
 	}
 	c, err := dcrrpcclient.New(&conf, &ntfnCallbacks)
 	if err != nil {
@@ -130,6 +142,10 @@ func (c *Client) Start() error {
 	c.started = true
 	c.quitMtx.Unlock()
 
+	c.statsMtx.Lock()
+	c.stats.ConnectedTime = time.Now()
+	c.statsMtx.Unlock()
+
 	c.wg.Add(2)
 	go c.handler()
 	go c.handlerVoting()
@@ -161,6 +177,56 @@ func (c *Client) WaitForShutdown() {
 	c.wg.Wait()
 }
 
+// HealthCheck returns an error if the chain server does not reply to a
+// getbestblock request within the timeout.
+func (c *Client) HealthCheck(timeout time.Duration) error {
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := c.GetBestBlock()
+		errs <- err
+	}()
+	select {
+	case err := <-errs:
+		if err == nil {
+			c.statsMtx.Lock()
+			c.stats.LastHealthCheck = time.Now()
+			c.statsMtx.Unlock()
+		}
+		return err
+	case <-time.After(timeout):
+		return errors.New("no reply from chain server")
+	case <-c.quit:
+		return errors.New("client is shutting down")
+	}
+}
+
+// MonitorHealth health checks the chain server every interval until the client
+// is stopped.  If a check fails, the client is stopped so that callers waiting
+// on WaitForShutdown may fail over to another chain server.
+func (c *Client) MonitorHealth(interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := c.HealthCheck(timeout)
+			if err == nil {
+				continue
+			}
+			select {
+			case <-c.quit:
+			default:
+				log.Warnf("Chain server health check failed: %v",
+					err)
+				c.Stop()
+			}
+			return
+		case <-c.quit:
+			return
+		}
+	}
+}
+
 // Notification types.  These are defined here and processed from from reading
 // a notificationChan to avoid handling these notifications directly in
 // dcrrpcclient callbacks, which isn't very Go-like and doesn't allow
@@ -300,6 +366,7 @@ func (c *Client) onClientConnect() {
 }
 
 func (c *Client) onBlockConnected(hash *chainhash.Hash, height int32, time time.Time, voteBits uint16) {
+	c.setBestBlock(&waddrmgr.BlockStamp{Hash: *hash, Height: height})
 	select {
 	case c.enqueueNotification <- BlockConnected{
 		Block: wtxmgr.Block{
@@ -439,6 +506,7 @@ func (c *Client) handler() {
 	}
 
 	bs := &waddrmgr.BlockStamp{Hash: *hash, Height: height}
+	c.setBestBlock(bs)
 
 	// TODO: Rather than leaving this as an unbounded queue for all types of
 	// notifications, try dropping ones where a later enqueued notification
@@ -471,6 +539,7 @@ out:
 				dequeue = c.dequeueNotification
 			}
 			notifications = append(notifications, n)
+			c.setQueuedNotifications(len(notifications))
 			pingChan = time.After(time.Minute)
 
 		case dequeue <- next:
@@ -483,6 +552,7 @@ out:
 
 			notifications[0] = nil
 			notifications = notifications[1:]
+			c.setQueuedNotifications(len(notifications))
 			if len(notifications) != 0 {
 				next = notifications[0]
 			} else {
@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package chain
+
+import (
+	"time"
+
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// ClientStats describes the state of the connection to the chain server.
+type ClientStats struct {
+	// Endpoint is the address of the chain server.
+	Endpoint string
+
+	// Connected is whether the client is connected to the chain server.
+	Connected bool
+
+	// ConnectedTime is when the connection was established, or the zero
+	// time if it never was.
+	ConnectedTime time.Time
+
+	// BestBlock is the last block notified as connected to the main chain
+	// by the chain server, and BestBlockTime is when the notification was
+	// received.
+	BestBlock     waddrmgr.BlockStamp
+	BestBlockTime time.Time
+
+	// QueuedNotifications is the number of chain server notifications
+	// received but not yet processed by the wallet.
+	QueuedNotifications int
+
+	// LastHealthCheck is when the chain server last passed a health check.
+	LastHealthCheck time.Time
+}
+
+// Stats returns the current state of the connection to the chain server.
+func (c *Client) Stats() ClientStats {
+	c.statsMtx.Lock()
+	stats := c.stats
+	c.statsMtx.Unlock()
+
+	stats.Endpoint = c.connect
+	c.quitMtx.Lock()
+	select {
+	case <-c.quit:
+	default:
+		stats.Connected = c.started && !c.Disconnected()
+	}
+	c.quitMtx.Unlock()
+	return stats
+}
+
+// setBestBlock records the best block notified by the chain server.
+func (c *Client) setBestBlock(bs *waddrmgr.BlockStamp) {
+	c.statsMtx.Lock()
+	c.stats.BestBlock = *bs
+	c.stats.BestBlockTime = time.Now()
+	c.statsMtx.Unlock()
+}
+
+// setQueuedNotifications records the number of notifications waiting to be
+// processed.
+func (c *Client) setQueuedNotifications(n int) {
+	c.statsMtx.Lock()
+	c.stats.QueuedNotifications = n
+	c.statsMtx.Unlock()
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2016 The Decred developers
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+//go:build !pkcs11
+// +build !pkcs11
+
+package main
+
+import (
+	"errors"
+
+	"github.com/decred/dcrwallet/signer"
+)
+
+// openHSM returns an error since HSM support requires cgo and is only built
+// with the pkcs11 build tag.
+func openHSM(module string, slot uint, pin string,
+	audit func(*signer.AuditEvent)) (hsmKeys, error) {
+	return nil, errors.New("dcrsigner was built without PKCS#11 support " +
+		"(rebuild with -tags pkcs11)")
+}
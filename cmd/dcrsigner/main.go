@@ -0,0 +1,273 @@
+// Copyright (c) 2016 The Decred developers
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// dcrsigner signs transactions for a watching-only dcrwallet configured with
+// --remotesigner, using the private keys of a wallet database which never
+// needs to be available to the online wallet.
+//
+// With --pkcs11module, the keys are instead generated in and never leave a
+// hardware security module.  Keys generated with --generatekey are imported
+// to the wallet with the importpubkey method, and every signature request is
+// appended to the --auditlog file.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/go-flags"
+	"github.com/btcsuite/golangcrypto/ssh/terminal"
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/signer"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/walletdb"
+	_ "github.com/decred/dcrwallet/walletdb/bdb"
+	_ "github.com/decred/dcrwallet/walletdb/ldb"
+	_ "github.com/decred/dcrwallet/walletdb/sqlite"
+)
+
+const defaultNet = "mainnet"
+
+var datadir = dcrutil.AppDataDir("dcrwallet", false)
+
+// Flags.
+var opts = struct {
+	DbPath  string `long:"db" description:"Path to the wallet database holding the private keys"`
+	DbType  string `long:"dbtype" description:"Database backend of the wallet database {bdb, ldb, sqlite}"`
+	Listen  string `long:"listen" description:"Interface and port to accept wallet connections on"`
+	Key     string `long:"key" default-mask:"-" description:"Secret shared with the wallet's --remotesignerkey"`
+	PubPass string `long:"pubpass" default-mask:"-" description:"Public passphrase of the wallet database"`
+	TestNet bool   `long:"testnet" description:"Sign for the test network"`
+	SimNet  bool   `long:"simnet" description:"Sign for the simulation test network"`
+
+	PKCS11Module string `long:"pkcs11module" description:"Path of the PKCS#11 module of an HSM holding the keys, instead of the wallet database (requires building with -tags pkcs11)"`
+	PKCS11Slot   uint   `long:"pkcs11slot" description:"Slot ID of the HSM token"`
+	GenerateKey  string `long:"generatekey" description:"Generate a key with this label in the HSM, print its public key and address for importpubkey, and exit"`
+	ListKeys     bool   `long:"listkeys" description:"Print the public keys and addresses of the keys in the HSM and exit"`
+	AuditLog     string `long:"auditlog" description:"File to append a JSON line to for every signature request handled by the HSM"`
+}{
+	DbPath:  filepath.Join(datadir, defaultNet, "wallet.db"),
+	DbType:  "bdb",
+	Listen:  "127.0.0.1:9120",
+	PubPass: "public",
+}
+
+func init() {
+	_, err := flags.Parse(&opts)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// Namespace keys.
+var (
+	waddrmgrNamespace = []byte("waddrmgr")
+)
+
+// keyStore provides the private keys of an unlocked address manager to the
+// signer.
+type keyStore struct {
+	mgr *waddrmgr.Manager
+}
+
+func (s keyStore) PrivKey(addr dcrutil.Address) (chainec.PrivateKey, error) {
+	ai, err := s.mgr.Address(addr)
+	if err != nil {
+		return nil, err
+	}
+	pka, ok := ai.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, errors.New("address is not a public key address")
+	}
+	return pka.PrivKey()
+}
+
+// logger writes signer events to standard output.
+type logger struct{}
+
+func (logger) Infof(format string, params ...interface{}) {
+	fmt.Printf("[INF] "+format+"\n", params...)
+}
+
+func (logger) Warnf(format string, params ...interface{}) {
+	fmt.Printf("[WRN] "+format+"\n", params...)
+}
+
+func main() {
+	os.Exit(mainInt())
+}
+
+func mainInt() int {
+	params := &chaincfg.MainNetParams
+	switch {
+	case opts.TestNet && opts.SimNet:
+		fmt.Println("The testnet and simnet options may not be used together")
+		return 1
+	case opts.TestNet:
+		params = &chaincfg.TestNetParams
+	case opts.SimNet:
+		params = &chaincfg.SimNetParams
+	}
+	if opts.PKCS11Module != "" {
+		return hsmMain(params)
+	}
+	if opts.Key == "" {
+		fmt.Println("A shared key must be set with --key")
+		return 1
+	}
+
+	fmt.Println("Database path:", opts.DbPath)
+	_, err := os.Stat(opts.DbPath)
+	if os.IsNotExist(err) {
+		fmt.Println("Database file does not exist")
+		return 1
+	}
+
+	db, err := walletdb.Open(opts.DbType, opts.DbPath)
+	if err != nil {
+		fmt.Println("Failed to open database:", err)
+		return 1
+	}
+	defer db.Close()
+	ns, err := db.Namespace(waddrmgrNamespace)
+	if err != nil {
+		fmt.Println("Failed to open address manager namespace:", err)
+		return 1
+	}
+	mgr, err := waddrmgr.Open(ns, []byte(opts.PubPass), params, nil)
+	if err != nil {
+		fmt.Println("Failed to open address manager:", err)
+		return 1
+	}
+	defer mgr.Close()
+
+	fmt.Print("Enter the private passphrase of the wallet: ")
+	pass, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	err = mgr.Unlock(pass)
+	if err != nil {
+		fmt.Println("Failed to unlock wallet:", err)
+		return 1
+	}
+
+	return serve(signer.NewServer(keyStore{mgr}, []byte(opts.Key), params,
+		logger{}))
+}
+
+// hsmKeys is the interface of a hardware security module used by dcrsigner.
+type hsmKeys interface {
+	signer.HashSigner
+	GenerateKey(label string) ([]byte, error)
+	Keys() ([]signer.HSMKey, error)
+	Close() error
+}
+
+// hsmMain signs with the keys of an HSM, or generates or lists keys.
+func hsmMain(params *chaincfg.Params) int {
+	fmt.Print("Enter the PIN of the HSM token: ")
+	pin, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	var audit func(*signer.AuditEvent)
+	if opts.AuditLog != "" {
+		f, err := os.OpenFile(opts.AuditLog,
+			os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			fmt.Println("Failed to open audit log:", err)
+			return 1
+		}
+		defer f.Close()
+		enc := json.NewEncoder(f)
+		audit = func(e *signer.AuditEvent) {
+			if err := enc.Encode(e); err != nil {
+				fmt.Println("[ERR] Failed to write audit log:", err)
+			}
+		}
+	}
+	hsm, err := openHSM(opts.PKCS11Module, opts.PKCS11Slot, string(pin),
+		audit)
+	if err != nil {
+		fmt.Println("Failed to open HSM:", err)
+		return 1
+	}
+	defer hsm.Close()
+
+	switch {
+	case opts.GenerateKey != "":
+		pubKey, err := hsm.GenerateKey(opts.GenerateKey)
+		if err != nil {
+			fmt.Println("Failed to generate key:", err)
+			return 1
+		}
+		return printKey(params, opts.GenerateKey, pubKey)
+
+	case opts.ListKeys:
+		keys, err := hsm.Keys()
+		if err != nil {
+			fmt.Println("Failed to list keys:", err)
+			return 1
+		}
+		for _, k := range keys {
+			if ret := printKey(params, k.Label, k.PubKey); ret != 0 {
+				return ret
+			}
+		}
+		return 0
+	}
+
+	if opts.Key == "" {
+		fmt.Println("A shared key must be set with --key")
+		return 1
+	}
+	return serve(signer.NewHashSignerServer(hsm, []byte(opts.Key), params,
+		logger{}))
+}
+
+// printKey prints the label, public key, and P2PKH address of an HSM key.
+func printKey(params *chaincfg.Params, label string, pubKey []byte) int {
+	addr, err := dcrutil.NewAddressPubKeyHash(dcrutil.Hash160(pubKey),
+		params, chainec.ECTypeSecp256k1)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Printf("%s %x %v\n", label, pubKey, addr)
+	return 0
+}
+
+// serve accepts wallet connections until the listener fails.
+func serve(s *signer.Server) int {
+	l, err := net.Listen("tcp", opts.Listen)
+	if err != nil {
+		fmt.Println("Failed to listen:", err)
+		return 1
+	}
+	fmt.Println("Signing on", l.Addr())
+	err = s.Serve(l)
+	fmt.Println(err)
+	return 1
+}
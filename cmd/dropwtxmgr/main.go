@@ -25,6 +25,8 @@ import (
 	"github.com/decred/dcrutil"
 	"github.com/decred/dcrwallet/walletdb"
 	_ "github.com/decred/dcrwallet/walletdb/bdb"
+	_ "github.com/decred/dcrwallet/walletdb/ldb"
+	_ "github.com/decred/dcrwallet/walletdb/sqlite"
 )
 
 const defaultNet = "mainnet"
@@ -35,9 +37,11 @@ var datadir = dcrutil.AppDataDir("dcrwallet", false)
 var opts = struct {
 	Force  bool   `short:"f" description:"Force removal without prompt"`
 	DbPath string `long:"db" description:"Path to wallet database"`
+	DbType string `long:"dbtype" description:"Database backend of the wallet database {bdb, ldb, sqlite}"`
 }{
 	Force:  false,
 	DbPath: filepath.Join(datadir, defaultNet, "wallet.db"),
+	DbType: "bdb",
 }
 
 func init() {
@@ -107,7 +111,7 @@ func mainInt() int {
 		fmt.Println("Enter yes or no.")
 	}
 
-	db, err := walletdb.Open("bdb", opts.DbPath)
+	db, err := walletdb.Open(opts.DbType, opts.DbPath)
 	if err != nil {
 		fmt.Println("Failed to open database:", err)
 		return 1
@@ -20,14 +20,17 @@ package main
 import (
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	flags "github.com/btcsuite/go-flags"
 	"github.com/decred/dcrutil"
 	"github.com/decred/dcrwallet/internal/legacy/keystore"
+	"github.com/decred/dcrwallet/walletdb"
 )
 
 const (
@@ -36,6 +39,7 @@ const (
 	defaultLogLevel          = "info"
 	defaultLogDirname        = "logs"
 	defaultLogFilename       = "dcrwallet.log"
+	defaultLogFormat         = logFormatText
 	defaultDisallowFree      = false
 	defaultRPCMaxClients     = 10
 	defaultRPCMaxWebsockets  = 25
@@ -47,6 +51,20 @@ const (
 	defaultPruneTickets      = false
 	defaultTicketMaxPrice    = 50.0
 	defaultAutomaticRepair   = false
+	defaultMaxFee            = 1.0
+	defaultMaxFeePercent     = 0.0
+	defaultMaxPerBlock       = 5
+	defaultTicketMaxFeeRate  = 0.0
+	defaultPoolFees          = 7.5
+	defaultMaxPerWindow      = 0
+	defaultDbType            = "bdb"
+	defaultBackupInterval    = 24 * time.Hour
+	defaultBackupCount       = 7
+	defaultMinConf           = 1
+	defaultGapLimit          = 20
+	defaultWebhookConfs      = 6
+	defaultFiatCurrency      = "USD"
+	defaultApprovalExpiry    = 24 * time.Hour
 
 	// defaultPubPassphrase is the default public wallet passphrase which is
 	// used when the user indicates they do not want additional protection
@@ -80,13 +98,22 @@ type config struct {
 	CreateWatchingOnly bool     `long:"createwatchingonly" description:"Create the wallet and instantiate it as watching only with an HD extended pubkey; must call with --create"`
 	CAFile             string   `long:"cafile" description:"File containing root certificates to authenticate a TLS connections with dcrd"`
 	RPCConnect         string   `short:"c" long:"rpcconnect" description:"Hostname/IP and port of dcrd RPC server to connect to (default localhost:19109, mainnet: localhost:9109, simnet: localhost:19556)"`
+	RPCConnectFallback []string `long:"rpcconnectfallback" description:"Hostname/IP and port of another dcrd RPC server to fail over to when the current server disconnects or stops responding (may be repeated)"`
+	Offline            bool     `long:"offline" description:"Run without connecting to a dcrd RPC server; transactions created while offline are broadcast after the next online start"`
 	DebugLevel         string   `short:"d" long:"debuglevel" description:"Logging level {trace, debug, info, warn, error, critical}"`
 	ConfigFile         string   `short:"C" long:"configfile" description:"Path to configuration file"`
 	SvrListeners       []string `long:"rpclisten" description:"Listen for RPC/websocket connections on this interface/port (default port: 19110, mainnet: 9110, simnet: 19557)"`
 	DataDir            string   `short:"b" long:"datadir" description:"Directory to store wallets and transactions"`
+	DbType             string   `long:"dbtype" description:"Database backend to store the wallet in {bdb, ldb, sqlite, memdb}"`
+	CompactDB          bool     `long:"compactdb" description:"Compact the wallet database by copying its live data into a new database, then exit"`
+	CheckDB            bool     `long:"checkdb" description:"Check the wallet database for unreadable or malformed records and salvage all valid records into a new database when problems are found, then exit"`
+	PreviewRestore     bool     `long:"previewrestore" description:"Scan the block chain for the transactions of an existing wallet seed and report the funds restoring it would recover without creating a wallet, then exit"`
+	EncryptDB          bool     `long:"encryptdb" description:"Encrypt the keys and values of the wallet database with a key protected by the public passphrase when creating the wallet (requires a non-default public passphrase)"`
 	LogDir             string   `long:"logdir" description:"Directory to log output."`
+	LogFormat          string   `long:"logformat" description:"Format of log output {text, json}"`
 	Username           string   `short:"u" long:"username" description:"Username for client and dcrd authorization"`
 	Password           string   `short:"P" long:"password" default-mask:"-" description:"Password for client and dcrd authorization"`
+	RPCAuth            []string `long:"rpcauth" description:"Additional RPC client credentials as username:password:permission[:limit], where permission is readonly, send (with an optional per-request spend limit in coins), staking, or admin"`
 	DcrdUsername       string   `long:"dcrdusername" description:"Alternative username for dcrd authorization"`
 	DcrdPassword       string   `long:"dcrdpassword" default-mask:"-" description:"Alternative password for dcrd authorization"`
 	WalletPass         string   `long:"walletpass" default-mask:"-" description:"The public wallet password -- Only required if the wallet was created with one"`
@@ -103,6 +130,7 @@ type config struct {
 	Proxy              string   `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser          string   `long:"proxyuser" description:"Username for proxy server"`
 	ProxyPass          string   `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
+	TorIsolation       bool     `long:"torisolation" description:"Enable Tor stream isolation by randomizing the proxy credentials of each connection"`
 	Profile            string   `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	EnableStakeMining  bool     `long:"enablestakemining" description:"Enable stake mining"`
 	VoteBits           uint16   `long:"votebits" description:"Set your stake mining votebits to value (default: 0xFFFF)"`
@@ -112,8 +140,59 @@ type config struct {
 	RollbackTest       bool     `long:"rollbacktest" description:"Rollback testing is a simnet testing mode that eventually stops wallet and examines wtxmgr database integrity"`
 	PruneTickets       bool     `long:"prunetickets" description:"Prune old tickets from the wallet and restore their inputs"`
 	TicketAddress      string   `long:"ticketaddress" description:"Send all ticket outputs to this address (P2PKH or P2SH only)"`
+	RewardAddress      string   `long:"rewardaddress" description:"Commit the rewards of purchased tickets to this address, such as a cold storage address, so votes and revocations pay to it (P2PKH only)"`
 	TicketMaxPrice     float64  `long:"ticketmaxprice" description:"The maximum price the user is willing to spend on buying a ticket"`
 	AutomaticRepair    bool     `long:"automaticrepair" description:"Attempt to repair the wallet automatically if a database inconsistency is found"`
+	MaxFee             float64  `long:"maxfee" description:"Refuse to create transactions paying a fee higher than this amount (0 to disable)"`
+	MaxFeePercent      float64  `long:"maxfeepercent" description:"Refuse to create transactions paying a fee higher than this percentage of the amount sent (0 to disable)"`
+	MaxPerBlock        int      `long:"maxperblock" description:"Maximum number of tickets to purchase per block when stake mining (0 for the network limit)"`
+	TicketMaxFeeRate   float64  `long:"ticketmaxfeerate" description:"Do not purchase tickets when the median fee per kB of tickets in the mempool exceeds this amount (0 for 10 times the minimum relay fee)"`
+	MaxPerWindow       int      `long:"maxperwindow" description:"Maximum number of tickets to purchase per stake difficulty window (0 for no limit)"`
+	NoAutoRevoke       bool     `long:"noautorevoke" description:"Do not automatically revoke missed and expired tickets"`
+	StakePoolMode      bool     `long:"stakepool" description:"Enable stake pool mode, voting tickets which delegate voting rights to the wallet"`
+	PoolAddress        string   `long:"pooladdress" description:"The address that stake pool fees must be committed to in submitted tickets"`
+	PoolFees           float64  `long:"poolfees" description:"The minimum percentage of each submitted ticket's commitment which must be paid to the pool address"`
+	VSPURL             string   `long:"vspurl" description:"Base URL of the voting service provider (dcrstakepool) to register with and purchase pool tickets for"`
+	VSPAPIToken        string   `long:"vspapitoken" default-mask:"-" description:"API token of the wallet's account with the voting service provider"`
+	VotingOnly         bool     `long:"votingonly" description:"Only vote with tickets whose voting rights are delegated to the wallet; never purchase tickets or spend funds"`
+	GRPCListeners      []string `long:"grpclisten" description:"Listen for gRPC connections on this interface/port (disabled by default; default port: 19111, mainnet: 9111, simnet: 19558)"`
+	GRPCClientCA       string   `long:"grpcclientca" description:"File containing the certificate authorities whose signed client certificates are accepted by the gRPC server"`
+
+	BackupDir         string        `long:"backupdir" description:"Directory to periodically write encrypted and verified backups of the wallet database to (disabled by default)"`
+	BackupInterval    time.Duration `long:"backupinterval" description:"Time between backups written to the backup directory"`
+	BackupCount       int           `long:"backupcount" description:"Number of backups kept in the backup directory, removing the oldest first"`
+	BackupPass        string        `long:"backuppass" default-mask:"-" description:"Passphrase encrypting the backups written to the backup directory"`
+	BackupCmd         string        `long:"backupcmd" description:"Command run with the path of each new backup as its final argument, such as to copy it to a remote host"`
+	RestoreBackup     string        `long:"restorebackup" description:"Create the wallet database from a backup written to the backup directory, decrypting it with the backup passphrase, then exit"`
+	RestoreSeedBackup string        `long:"restoreseedbackup" description:"Create the wallet from a seed backup file returned by the exportseedbackup RPC, restoring its accounts and imported keys and scripts, then exit"`
+
+	SpendAlertURL          string `long:"spendalerturl" description:"URL to POST a JSON alert to when wallet funds are spent by a transaction not created or signed by this wallet"`
+	SeparateOrigins        bool   `long:"separateorigins" description:"Never spend credits of different origins, such as mixed and unmixed coins, in the same transaction"`
+	MinConf                int32  `long:"minconf" description:"Minimum number of confirmations of outputs spent by created transactions when a request does not specify it"`
+	SpendUnconfirmedChange bool   `long:"spendunconfirmedchange" description:"Allow spending unconfirmed change and transfers between the wallet's own accounts regardless of the required confirmations"`
+	GapLimit               uint32 `long:"gaplimit" description:"Number of consecutive unused addresses after which getnewaddress warns about or refuses new addresses"`
+
+	WebhookURLs   []string `long:"webhookurl" description:"URL to POST a JSON event to when an address of the wallet receives funds, the deposit reaches --webhookconfirmations, or a ticket votes, is revoked, misses or expires (may be repeated)"`
+	WebhookSecret string   `long:"webhooksecret" default-mask:"-" description:"Key of the HMAC-SHA256 signature of each webhook request body, sent hex encoded in the X-Dcrwallet-Signature header"`
+	WebhookConfs  int32    `long:"webhookconfirmations" description:"Number of confirmations of a deposit at which the confirmed webhook event is sent"`
+
+	FiatRateSource string `long:"fiatratesource" description:"URL of a price source responding with the JSON exchange rate of DCR in --fiatcurrency, recorded for each transaction when it is first seen (disabled by default)"`
+	FiatCurrency   string `long:"fiatcurrency" description:"Currency code of the exchange rates fetched from --fiatratesource"`
+
+	InvariantSampling float64 `long:"invariantsampling" description:"Fraction of transaction store updates, between 0 and 1, after which the store invariants are checked and any violation is logged (disabled by default)"`
+
+	RemoteSigner    string `long:"remotesigner" description:"Address (host:port) of a dcrsigner process signing the wallet's transactions, allowing the wallet to be watching-only"`
+	RemoteSignerKey string `long:"remotesignerkey" default-mask:"-" description:"Secret shared with the remote signer authenticating the messages of both ends"`
+
+	PolicyDailyLimit   float64  `long:"policydailylimit" description:"Maximum amount in coins that transactions signed for RPC users below admin may pay outside of the wallet within any 24 hours"`
+	PolicyAllowAddress []string `long:"policyallowaddress" description:"Address that transactions signed for RPC users below admin may pay; when set, no other addresses outside of the wallet may be paid (may be repeated)"`
+	PolicyBlockAddress []string `long:"policyblockaddress" description:"Address that transactions signed for RPC users below admin may never pay (may be repeated)"`
+	PolicyMinConf      int32    `long:"policyminconf" description:"Minimum number of confirmations of every output spent by transactions signed for RPC users below admin"`
+	PolicyHours        string   `long:"policyhours" description:"Time of day in local time, as HH:MM-HH:MM, during which transactions may be signed for RPC users below admin"`
+
+	ApprovalThreshold float64       `long:"approvalthreshold" description:"Queue sends paying more than this amount in coins unsigned until they are approved by a second RPC user or an approval token (disabled by default)"`
+	ApprovalExpiry    time.Duration `long:"approvalexpiry" description:"Time a queued send waits for approval before it expires"`
+	ApprovalAddress   string        `long:"approvaladdress" description:"Address whose signmessage signatures of \"approvesend <id>\" are accepted as approval tokens for queued sends"`
 }
 
 // cleanAndExpandPath expands environement variables and leading ~ in the
@@ -130,6 +209,20 @@ func cleanAndExpandPath(path string) string {
 	return filepath.Clean(os.ExpandEnv(path))
 }
 
+// validFiatCurrency returns whether or not code is a valid currency code for
+// fiat exchange rates.
+func validFiatCurrency(code string) bool {
+	if code == "" || len(code) > 16 {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
 // validLogLevel returns whether or not logLevel is a valid debug log level.
 func validLogLevel(logLevel string) bool {
 	switch logLevel {
@@ -163,6 +256,17 @@ func supportedSubsystems() []string {
 	return subsystems
 }
 
+// validDbType returns whether or not dbType is a registered walletdb database
+// backend.
+func validDbType(dbType string) bool {
+	for _, t := range walletdb.SupportedDrivers() {
+		if t == dbType {
+			return true
+		}
+	}
+	return false
+}
+
 // parseAndSetDebugLevels attempts to parse the specified debug level and set
 // the levels accordingly.  An appropriate error is returned if anything is
 // invalid.
@@ -195,6 +299,11 @@ func parseAndSetDebugLevels(debugLevel string) error {
 		fields := strings.Split(logLevelPair, "=")
 		subsysID, logLevel := fields[0], fields[1]
 
+		// Accept the subsystem identifiers of earlier releases.
+		if id, ok := subsystemAliases[subsysID]; ok {
+			subsysID = id
+		}
+
 		// Validate subsystem.
 		if _, exists := subsystemLoggers[subsysID]; !exists {
 			str := "The specified subsystem [%v] is invalid -- " +
@@ -276,7 +385,9 @@ func loadConfig() (*config, []string, error) {
 		DebugLevel:        defaultLogLevel,
 		ConfigFile:        defaultConfigFile,
 		DataDir:           defaultDataDir,
+		DbType:            defaultDbType,
 		LogDir:            defaultLogDir,
+		LogFormat:         defaultLogFormat,
 		WalletPass:        defaultPubPassphrase,
 		RPCKey:            defaultRPCKeyFile,
 		RPCCert:           defaultRPCCertFile,
@@ -291,6 +402,19 @@ func loadConfig() (*config, []string, error) {
 		PruneTickets:      defaultPruneTickets,
 		TicketMaxPrice:    defaultTicketMaxPrice,
 		AutomaticRepair:   defaultAutomaticRepair,
+		MaxFee:            defaultMaxFee,
+		MaxFeePercent:     defaultMaxFeePercent,
+		MaxPerBlock:       defaultMaxPerBlock,
+		TicketMaxFeeRate:  defaultTicketMaxFeeRate,
+		MaxPerWindow:      defaultMaxPerWindow,
+		PoolFees:          defaultPoolFees,
+		BackupInterval:    defaultBackupInterval,
+		ApprovalExpiry:    defaultApprovalExpiry,
+		BackupCount:       defaultBackupCount,
+		MinConf:           defaultMinConf,
+		GapLimit:          defaultGapLimit,
+		WebhookConfs:      defaultWebhookConfs,
+		FiatCurrency:      defaultFiatCurrency,
 	}
 
 	// A config file in the current directory takes precedence.
@@ -396,8 +520,20 @@ func loadConfig() (*config, []string, error) {
 		os.Exit(0)
 	}
 
+	// Validate the log format before the logger is initialized.
+	if _, ok := logFormats[cfg.LogFormat]; !ok {
+		str := "%s: The specified log format [%v] is invalid -- " +
+			"supported formats {%s, %s}"
+		err := fmt.Errorf(str, "loadConfig", cfg.LogFormat,
+			logFormatText, logFormatJSON)
+		fmt.Fprintln(os.Stderr, err)
+		parser.WriteHelp(os.Stderr)
+		return nil, nil, err
+	}
+
 	// Initialize logging at the default logging level.
-	initSeelogLogger(filepath.Join(cfg.LogDir, defaultLogFilename))
+	initSeelogLogger(filepath.Join(cfg.LogDir, defaultLogFilename),
+		cfg.LogFormat)
 	setLogLevels(defaultLogLevel)
 
 	// Parse, validate, and set debug log level(s).
@@ -432,9 +568,205 @@ func loadConfig() (*config, []string, error) {
 		os.Exit(0)
 	}
 
+	// Ensure the database backend is one of the registered drivers.
+	if !validDbType(cfg.DbType) {
+		str := "%s: The specified database type [%v] is invalid -- " +
+			"supported types %v"
+		err := fmt.Errorf(str, "loadConfig", cfg.DbType,
+			walletdb.SupportedDrivers())
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// In-memory wallets are lost when the process exits, so they may only
+	// be used for temporary simulation wallets.
+	if cfg.DbType == "memdb" && !cfg.CreateTemp {
+		err := fmt.Errorf("%s: The memdb database type may only be used "+
+			"with --createtemp", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Ensure the wallet exists or create it when the create flag is set.
 	netDir := networkDir(cfg.DataDir, activeNet.Params)
-	dbPath := filepath.Join(netDir, walletDbName)
+	dbPath := filepath.Join(netDir, walletDbFilename(cfg.DbType))
+
+	if cfg.CompactDB && (cfg.Create || cfg.CreateTemp) {
+		err := fmt.Errorf("The flag --compactdb can not be specified " +
+			"together with --create or --createtemp. Use --help for more " +
+			"information.")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	if cfg.CheckDB && (cfg.Create || cfg.CreateTemp || cfg.CompactDB) {
+		err := fmt.Errorf("The flag --checkdb can not be specified " +
+			"together with --create, --createtemp, or --compactdb. Use " +
+			"--help for more information.")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	if cfg.RestoreBackup != "" && (cfg.Create || cfg.CreateTemp ||
+		cfg.CompactDB || cfg.CheckDB) {
+		err := fmt.Errorf("The flag --restorebackup can not be specified " +
+			"together with --create, --createtemp, --compactdb, or " +
+			"--checkdb. Use --help for more information.")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	if cfg.RestoreSeedBackup != "" && (cfg.Create || cfg.CreateTemp ||
+		cfg.CompactDB || cfg.CheckDB || cfg.RestoreBackup != "") {
+		err := fmt.Errorf("The flag --restoreseedbackup can not be " +
+			"specified together with --create, --createtemp, " +
+			"--compactdb, --checkdb, or --restorebackup. Use --help " +
+			"for more information.")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	if cfg.PreviewRestore && (cfg.Create || cfg.CreateTemp ||
+		cfg.CompactDB || cfg.CheckDB || cfg.RestoreBackup != "" ||
+		cfg.RestoreSeedBackup != "" || cfg.Offline) {
+		err := fmt.Errorf("The flag --previewrestore can not be " +
+			"specified together with --create, --createtemp, " +
+			"--compactdb, --checkdb, --restorebackup, " +
+			"--restoreseedbackup, or --offline. Use --help for more " +
+			"information.")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Backups are always encrypted, so the backup passphrase is required
+	// to write or restore them.
+	if (cfg.BackupDir != "" || cfg.RestoreBackup != "") &&
+		cfg.BackupPass == "" {
+		err := fmt.Errorf("%s: the --backupdir and --restorebackup "+
+			"options require a passphrase set with --backuppass",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.BackupDir != "" {
+		if cfg.BackupInterval <= 0 || cfg.BackupCount < 1 {
+			err := fmt.Errorf("%s: the backup interval and count "+
+				"must be positive", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.BackupDir = cleanAndExpandPath(cfg.BackupDir)
+	}
+
+	if cfg.MinConf < 0 {
+		err := fmt.Errorf("%s: the --minconf option must not be "+
+			"negative", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	if cfg.GapLimit == 0 {
+		err := fmt.Errorf("%s: the --gaplimit option must be "+
+			"positive", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	for _, webhookURL := range cfg.WebhookURLs {
+		u, err := url.Parse(webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			err := fmt.Errorf("%s: the --webhookurl option must "+
+				"be an http or https URL", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+	if cfg.WebhookConfs < 1 {
+		err := fmt.Errorf("%s: the --webhookconfirmations option "+
+			"must be positive", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	if cfg.FiatRateSource != "" {
+		u, err := url.Parse(cfg.FiatRateSource)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			err := fmt.Errorf("%s: the --fiatratesource option "+
+				"must be an http or https URL", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+	if !validFiatCurrency(cfg.FiatCurrency) {
+		err := fmt.Errorf("%s: the --fiatcurrency option must be a "+
+			"currency code of up to 16 letters", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	if cfg.InvariantSampling < 0 || cfg.InvariantSampling > 1 {
+		err := fmt.Errorf("%s: the --invariantsampling option must be "+
+			"between 0 and 1", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	if cfg.RemoteSigner != "" {
+		if _, _, err := net.SplitHostPort(cfg.RemoteSigner); err != nil {
+			err := fmt.Errorf("%s: the --remotesigner option must be "+
+				"a host:port address", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.RemoteSignerKey == "" {
+			err := fmt.Errorf("%s: the --remotesigner option requires "+
+				"--remotesignerkey", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	if cfg.SpendAlertURL != "" {
+		u, err := url.Parse(cfg.SpendAlertURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			err := fmt.Errorf("%s: the --spendalerturl option must "+
+				"be an http or https URL", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	if cfg.VSPURL != "" {
+		u, err := url.Parse(cfg.VSPURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			err := fmt.Errorf("%s: the --vspurl option must be an "+
+				"http or https URL", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.VSPAPIToken == "" {
+			err := fmt.Errorf("%s: the --vspurl option requires "+
+				"--vspapitoken", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
 
 	if cfg.CreateTemp && cfg.Create {
 		err := fmt.Errorf("The flags --create and --createtemp can not " +
@@ -497,7 +829,55 @@ func loadConfig() (*config, []string, error) {
 
 		// Created successfully, so exit now with success.
 		os.Exit(0)
-	} else if !fileExists(dbPath) {
+	} else if cfg.RestoreBackup != "" {
+		// Error if the wallet already exists, as it would be replaced
+		// by the restored wallet.
+		if fileExists(dbPath) {
+			err := fmt.Errorf("The wallet database file `%v` "+
+				"already exists.", dbPath)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+
+		// Ensure the data directory for the network exists.
+		if err := checkCreateDir(netDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+
+		backupPath := cleanAndExpandPath(cfg.RestoreBackup)
+		if err := restoreBackup(&cfg, backupPath, dbPath); err != nil {
+			fmt.Fprintln(os.Stderr, "Unable to restore wallet:", err)
+			return nil, nil, err
+		}
+
+		// Restored successfully, so exit now with success.
+		os.Exit(0)
+	} else if cfg.RestoreSeedBackup != "" {
+		// Error if the wallet already exists, as it would be replaced
+		// by the restored wallet.
+		if fileExists(dbPath) {
+			err := fmt.Errorf("The wallet database file `%v` "+
+				"already exists.", dbPath)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+
+		// Ensure the data directory for the network exists.
+		if err := checkCreateDir(netDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+
+		path := cleanAndExpandPath(cfg.RestoreSeedBackup)
+		if err := restoreSeedBackup(&cfg, path, dbPath); err != nil {
+			fmt.Fprintln(os.Stderr, "Unable to restore wallet:", err)
+			return nil, nil, err
+		}
+
+		// Restored successfully, so exit now with success.
+		os.Exit(0)
+	} else if !cfg.PreviewRestore && !fileExists(dbPath) {
 		var err error
 		keystorePath := filepath.Join(netDir, keystore.Filename)
 		if !fileExists(keystorePath) {
@@ -515,8 +895,29 @@ func loadConfig() (*config, []string, error) {
 		cfg.RPCConnect = activeNet.connect
 	}
 
-	// Add default port to connect flag if missing.
+	// Add default port to connect flags if missing.
 	cfg.RPCConnect = normalizeAddress(cfg.RPCConnect, activeNet.dcrdPort)
+	cfg.RPCConnectFallback = normalizeAddresses(cfg.RPCConnectFallback,
+		activeNet.dcrdPort)
+
+	// Tor stream isolation chooses the proxy credentials, and is only
+	// possible when connecting through a proxy.
+	if cfg.TorIsolation {
+		var err error
+		switch {
+		case cfg.Proxy == "":
+			err = fmt.Errorf("%s: the --torisolation option requires "+
+				"a proxy set with --proxy", funcName)
+		case cfg.ProxyUser != "" || cfg.ProxyPass != "":
+			err = fmt.Errorf("%s: the --torisolation option may not "+
+				"be used with --proxyuser or --proxypass", funcName)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
 
 	localhostListeners := map[string]struct{}{
 		"localhost": struct{}{},
@@ -528,14 +929,21 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 	if cfg.DisableClientTLS {
-		if _, ok := localhostListeners[RPCHost]; !ok {
-			str := "%s: the --noclienttls option may not be used " +
-				"when connecting RPC to non localhost " +
-				"addresses: %s"
-			err := fmt.Errorf(str, funcName, cfg.RPCConnect)
-			fmt.Fprintln(os.Stderr, err)
-			fmt.Fprintln(os.Stderr, usageMessage)
-			return nil, nil, err
+		for _, addr := range append([]string{cfg.RPCConnect},
+			cfg.RPCConnectFallback...) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, ok := localhostListeners[host]; !ok {
+				str := "%s: the --noclienttls option may not be " +
+					"used when connecting RPC to non " +
+					"localhost addresses: %s"
+				err := fmt.Errorf(str, funcName, addr)
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, usageMessage)
+				return nil, nil, err
+			}
 		}
 	} else {
 		// If CAFile is unset, choose either the copy or local dcrd cert.
@@ -596,8 +1004,56 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// The gRPC server is only started when listeners are configured, and
+	// always requires clients to authenticate with a certificate.
+	if len(cfg.GRPCListeners) != 0 {
+		if cfg.GRPCClientCA == "" {
+			str := "%s: the --grpclisten option requires the " +
+				"--grpcclientca option to authenticate clients"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.GRPCListeners = normalizeAddresses(cfg.GRPCListeners,
+			activeNet.grpcPort)
+	}
+
+	// Validate the additional RPC client credentials.  The server parses
+	// them again when it is created.
+	for _, s := range cfg.RPCAuth {
+		if _, err := parseRPCUser(s); err != nil {
+			str := "%s: invalid --rpcauth option: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	// Validate the signing policy options.  The policy is created again
+	// when the wallet is opened.
+	if _, err := newSigningPolicy(&cfg); err != nil {
+		str := "%s: invalid signing policy: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the send approval options.  The policy is created again
+	// when the wallet is opened.
+	if _, err := newSendApprovalPolicy(&cfg); err != nil {
+		str := "%s: invalid send approval options: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Expand environment variable and leading ~ for filepaths.
 	cfg.CAFile = cleanAndExpandPath(cfg.CAFile)
+	cfg.GRPCClientCA = cleanAndExpandPath(cfg.GRPCClientCA)
 
 	// If the dcrd username or password are unset, use the same auth as for
 	// the client.  The two settings were previously shared for dcrd and
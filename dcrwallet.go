@@ -18,6 +18,8 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -28,12 +30,31 @@ import (
 	"time"
 
 	"github.com/decred/dcrwallet/chain"
+	"github.com/decred/dcrwallet/wallet"
 )
 
 var (
 	cfg *config
 )
 
+const (
+	// chainHealthCheckInterval is the time between health checks of the
+	// connected chain server.
+	chainHealthCheckInterval = time.Minute
+
+	// chainHealthCheckTimeout is the time the chain server is given to
+	// reply to a health check before failing over to another server.
+	chainHealthCheckTimeout = 30 * time.Second
+
+	// chainConnectInitialBackoff is the delay before retrying the chain
+	// servers after a connection to every server has failed.
+	chainConnectInitialBackoff = 5 * time.Second
+
+	// chainConnectMaxBackoff is the maximum delay between attempts to
+	// connect to the chain servers.
+	chainConnectMaxBackoff = 5 * time.Minute
+)
+
 func main() {
 	// Use all processor cores.
 	runtime.GOMAXPROCS(runtime.NumCPU())
@@ -89,6 +110,52 @@ func walletMain() error {
 		}()
 	}
 
+	// Compact the wallet database and exit when requested.  The database
+	// must not be open while it is being replaced.
+	if cfg.CompactDB {
+		netDir := networkDir(cfg.DataDir, activeNet.Params)
+		before, after, err := compactDb(netDir, cfg.DbType)
+		if err != nil {
+			log.Errorf("Unable to compact wallet database: %v", err)
+			return err
+		}
+		log.Infof("Compacted wallet database from %d to %d bytes "+
+			"(%d bytes reclaimed)", before, after, before-after)
+		return nil
+	}
+
+	// Check the wallet database for corruption and exit when requested.
+	// The original database is left untouched, and any salvaged database
+	// must be moved into its place manually.
+	if cfg.CheckDB {
+		netDir := networkDir(cfg.DataDir, activeNet.Params)
+		salvagePath, problems, err := checkDb(netDir, cfg.DbType,
+			[]byte(cfg.WalletPass))
+		if err != nil {
+			log.Errorf("Unable to check wallet database: %v", err)
+			return err
+		}
+		if problems == 0 {
+			log.Infof("Wallet database check found no problems")
+			return nil
+		}
+		log.Warnf("Wallet database check found %d problems.  All valid "+
+			"records were salvaged to %s, which may replace the "+
+			"wallet database after the original has been backed up",
+			problems, salvagePath)
+		return nil
+	}
+
+	// Report the funds an existing seed would recover and exit when
+	// requested.  No wallet database is created or opened.
+	if cfg.PreviewRestore {
+		if err := previewRestore(cfg); err != nil {
+			log.Errorf("Unable to preview restore: %v", err)
+			return err
+		}
+		return nil
+	}
+
 	// Load the wallet database.  It must have been created with the
 	// --create option already or this will return an appropriate error.
 	wallet, db, err := openWallet(cfg)
@@ -108,69 +175,193 @@ func walletMain() error {
 		return err
 	}
 	server.Start()
+	server.publishMetrics()
+
+	// The wallet runs offline until a chain server connects, or for the
+	// lifetime of the process in offline mode.
+	wallet.StartOffline()
 	server.SetWallet(wallet)
 
 	// Shutdown the server if an interrupt signal is received.
 	addInterruptHandler(server.Stop)
 
-	go func() {
-		for {
-			// Read CA certs and create the RPC client.
-			var certs []byte
-			if !cfg.DisableClientTLS {
-				certs, err = ioutil.ReadFile(cfg.CAFile)
-				if err != nil {
-					log.Warnf("Cannot open CA file: %v", err)
-					// If there's an error reading the CA file, continue
-					// with nil certs and without the client connection
-					certs = nil
+	// Start the gRPC server, if enabled.  It is stopped once the RPC server
+	// shuts down.
+	grpcServer, err := startGRPCServer(wallet)
+	if err != nil {
+		log.Errorf("Unable to create gRPC server: %v", err)
+		return err
+	}
+	if grpcServer != nil {
+		defer grpcServer.Stop()
+	}
+
+	// Periodically back up the wallet, if enabled.  Shutdown waits for a
+	// backup in progress to finish before the database is closed.
+	if cfg.BackupDir != "" {
+		backupsDone := make(chan struct{})
+		go func() {
+			backupPeriodically(wallet, db, server.quit)
+			close(backupsDone)
+		}()
+		defer func() { <-backupsDone }()
+	}
+
+	if cfg.Offline {
+		log.Info("Offline mode is enabled -- not connecting to a Decred " +
+			"RPC chain server")
+		server.WaitForShutdown()
+		log.Info("Shutdown complete")
+		return nil
+	}
+
+	go syncWithChainServers(wallet, server.SetChainServer, server.quit)
+
+	// Wait for the server to shutdown either due to a stop RPC request
+	// or an interrupt.
+	server.WaitForShutdown()
+	log.Info("Shutdown complete")
+	return nil
+}
+
+// syncWithChainServers connects the wallet to the configured chain servers
+// until quit is closed, syncing the wallet with each connected server.
+// setChainServer is called with each connected chain server client, and with
+// nil when the client disconnects and the wallet returns to running offline.
+func syncWithChainServers(w *wallet.Wallet,
+	setChainServer func(*chain.Client), quit <-chan struct{}) {
+	// The chain server connection fails over to the next server when
+	// the current server disconnects or fails a health check.  The
+	// wallet syncs with each newly connected server, rescanning from
+	// the last block both servers agree on, so blocks missed during
+	// the switch are not lost.  After every server has refused a
+	// connection in turn, the next attempt is delayed by a backoff which
+	// doubles with each failed pass over the servers.
+	endpoints := append([]string{cfg.RPCConnect},
+		cfg.RPCConnectFallback...)
+	failed := 0
+	backoff := chainConnectInitialBackoff
+	for i := 0; ; i = (i + 1) % len(endpoints) {
+		endpoint := endpoints[i]
+		rpcc, err := newChainClient(endpoint)
+		if err != nil {
+			log.Errorf("Cannot create chain server RPC client: %v", err)
+			return
+		}
+		err = rpcc.Start()
+		if err != nil {
+			// The wallet remains offline, and RPC methods which
+			// require a chain server return errors until the
+			// connection succeeds.
+			log.Warnf("Connection to Decred RPC chain server %s "+
+				"unsuccessful: %v", endpoint, err)
+			rpcc.Stop()
+			failed++
+			if failed < len(endpoints) {
+				select {
+				case <-quit:
+					return
+				default:
+					continue
 				}
-			} else {
-				log.Info("Client TLS is disabled")
 			}
-			rpcc, err := chain.NewClient(activeNet.Params, cfg.RPCConnect,
-				cfg.DcrdUsername, cfg.DcrdPassword, certs, cfg.DisableClientTLS)
-			if err != nil {
-				log.Errorf("Cannot create chain server RPC client: %v", err)
+			log.Infof("Retrying Decred RPC chain servers in %v", backoff)
+			select {
+			case <-quit:
 				return
+			case <-time.After(backoff):
 			}
-			err = rpcc.Start()
-			if err != nil {
-				log.Warnf("Connection to Decred RPC chain server " +
-					"unsuccessful -- available RPC methods will be limited")
+			failed = 0
+			backoff *= 2
+			if backoff > chainConnectMaxBackoff {
+				backoff = chainConnectMaxBackoff
 			}
-			// Even if Start errored, we still add the server disconnected.
-			// All client methods will then error, so it's obvious to a
-			// client that the there was a connection problem.
-			server.SetChainServer(rpcc)
+			continue
+		}
+		log.Infof("Connected to Decred RPC chain server %s", endpoint)
+		failed = 0
+		backoff = chainConnectInitialBackoff
+		go rpcc.MonitorHealth(chainHealthCheckInterval,
+			chainHealthCheckTimeout)
 
-			// Start wallet goroutines and handle RPC client notifications
-			// if the server is not shutting down.
-			select {
-			case <-server.quit:
-				return
-			default:
-				wallet.Start(rpcc)
-			}
+		// Restart the offline wallet with the chain server and
+		// handle RPC client notifications if the server is not
+		// shutting down.  Transactions created while offline are
+		// broadcast after the wallet syncs.
+		select {
+		case <-quit:
+			rpcc.Stop()
+			return
+		default:
+			w.Stop()
+			setChainServer(rpcc)
+			w.Start(rpcc)
+		}
 
-			// Block goroutine until the client is finished.
-			rpcc.WaitForShutdown()
+		// Block goroutine until the client is finished.
+		rpcc.WaitForShutdown()
 
-			wallet.SetChainSynced(false)
-			wallet.Stop()
+		w.SetChainSynced(false)
+		w.Stop()
 
-			// Reconnect only if the server is not shutting down.
-			select {
-			case <-server.quit:
-				return
-			default:
-			}
+		// Reconnect only if the server is not shutting down.
+		select {
+		case <-quit:
+			return
+		default:
 		}
-	}()
+		setChainServer(nil)
+		w.StartOffline()
+		if len(endpoints) > 1 {
+			log.Infof("Failing over from Decred RPC chain server %s",
+				endpoint)
+		}
+	}
+}
 
-	// Wait for the server to shutdown either due to a stop RPC request
-	// or an interrupt.
-	server.WaitForShutdown()
-	log.Info("Shutdown complete")
-	return nil
+// newChainClient creates an RPC client for the chain server at endpoint using
+// the configured credentials, certificates, and proxy.  The client must be
+// started before use.
+func newChainClient(endpoint string) (*chain.Client, error) {
+	// Read CA certs and create the RPC client.
+	var certs []byte
+	var err error
+	if !cfg.DisableClientTLS {
+		certs, err = ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			log.Warnf("Cannot open CA file: %v", err)
+			// If there's an error reading the CA file, continue
+			// with nil certs and without the client connection
+			certs = nil
+		}
+	} else {
+		log.Info("Client TLS is disabled")
+	}
+
+	// With Tor stream isolation, each connection authenticates to the
+	// proxy with new random credentials so that Tor uses a new circuit for
+	// it.
+	proxyUser, proxyPass := cfg.ProxyUser, cfg.ProxyPass
+	if cfg.TorIsolation {
+		proxyUser, proxyPass, err = randomProxyCredentials()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chain.NewClient(activeNet.Params, endpoint, cfg.DcrdUsername,
+		cfg.DcrdPassword, certs, cfg.DisableClientTLS, cfg.Proxy,
+		proxyUser, proxyPass)
+}
+
+// randomProxyCredentials returns a random username and password for
+// authenticating to a Tor SOCKS5 proxy.  Tor isolates streams using different
+// credentials on separate circuits.
+func randomProxyCredentials() (user, pass string, err error) {
+	var b [16]byte
+	if _, err = rand.Read(b[:]); err != nil {
+		return "", "", err
+	}
+	user = hex.EncodeToString(b[:8])
+	pass = hex.EncodeToString(b[8:])
+	return user, pass, nil
 }
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/decred/dcrwallet/rpc/rpcserver"
+	"github.com/decred/dcrwallet/wallet"
+)
+
+// startGRPCServer creates a gRPC server serving the wallet service on the
+// configured gRPC listeners.  Connections always use TLS with the RPC
+// certificate, and clients must present a certificate signed by one of the
+// authorities in the configured client CA file.  A nil server is returned
+// if no gRPC listeners are configured.
+func startGRPCServer(w *wallet.Wallet) (*grpc.Server, error) {
+	if len(cfg.GRPCListeners) == 0 {
+		return nil, nil
+	}
+
+	// Check for existence of cert file and key file
+	if !fileExists(cfg.RPCKey) && !fileExists(cfg.RPCCert) {
+		// if both files do not exist, we generate them.
+		err := genCertPair(cfg.RPCCert, cfg.RPCKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	keypair, err := tls.LoadX509KeyPair(cfg.RPCCert, cfg.RPCKey)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs, err := ioutil.ReadFile(cfg.GRPCClientCA)
+	if err != nil {
+		return nil, err
+	}
+	clientCAPool := x509.NewCertPool()
+	if !clientCAPool.AppendCertsFromPEM(clientCAs) {
+		return nil, fmt.Errorf("no certificates found in %s",
+			cfg.GRPCClientCA)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{keypair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAPool,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	ipv4ListenAddrs, ipv6ListenAddrs, err := parseListeners(cfg.GRPCListeners)
+	if err != nil {
+		return nil, err
+	}
+	listeners := make([]net.Listener, 0,
+		len(ipv6ListenAddrs)+len(ipv4ListenAddrs))
+	for _, addr := range ipv4ListenAddrs {
+		listener, err := net.Listen("tcp4", addr)
+		if err != nil {
+			grpcLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+	for _, addr := range ipv6ListenAddrs {
+		listener, err := net.Listen("tcp6", addr)
+		if err != nil {
+			grpcLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+	if len(listeners) == 0 {
+		return nil, errors.New("no valid gRPC listen address")
+	}
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	rpcserver.StartWalletService(server, w)
+	for _, listener := range listeners {
+		grpcLog.Infof("gRPC server listening on %s", listener.Addr())
+		go server.Serve(listener)
+	}
+
+	return server, nil
+}
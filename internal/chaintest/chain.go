@@ -0,0 +1,328 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package chaintest provides a scripted, in-memory block chain for tests.
+//
+// A Chain generates blocks on demand, lets tests choose which mempool
+// transactions each block mines, disapprove the regular transaction tree of a
+// parent through the vote bits, and force reorganizations of any depth.
+// Every change is reported to the registered listeners in the order a
+// dcrd chain server would report it, so stores and other consumers can be
+// driven through deterministic sync scenarios without hand written fixtures.
+package chaintest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// genesisTime is the timestamp of the genesis block of every Chain.  Block
+// times are derived from it so that runs are reproducible.
+var genesisTime = time.Unix(1454954400, 0)
+
+// Block describes a block of a Chain.  Txs holds the coinbase followed by the
+// mined transactions in the order they were included.
+type Block struct {
+	wtxmgr.BlockMeta
+	Txs []*wtxmgr.TxRecord
+}
+
+// BlockTemplate describes the contents of a block to mine.
+type BlockTemplate struct {
+	// Txs selects the mempool transactions to include, in order.  A nil
+	// slice includes every mempool transaction.  An empty non-nil slice
+	// mines a block with only a coinbase.
+	Txs []chainhash.Hash
+
+	// DisapproveParent clears the vote bits of the block so that the
+	// regular transaction tree of its parent is disapproved.
+	DisapproveParent bool
+}
+
+// Listener is notified of every change of a Chain.  Returning an error stops
+// the operation in progress and is returned to the caller.
+type Listener interface {
+	// MempoolTx is called when a transaction is accepted to the mempool.
+	MempoolTx(rec *wtxmgr.TxRecord) error
+
+	// BlockConnected is called when a block is attached to the main chain.
+	BlockConnected(b *Block) error
+
+	// BlockDisconnected is called when the tip block is removed from the
+	// main chain.
+	BlockDisconnected(b *Block) error
+}
+
+// Chain is a scripted block chain.  It is not safe for concurrent use.
+type Chain struct {
+	// CoinbaseScript is the output script paid by the coinbase of each new
+	// block and Subsidy is the amount paid.
+	CoinbaseScript []byte
+	Subsidy        int64
+
+	params    *chaincfg.Params
+	blocks    []*Block
+	mempool   []*wtxmgr.TxRecord
+	listeners []Listener
+	nonce     uint32
+}
+
+// New returns a chain for the network params which contains only a genesis
+// block.
+func New(params *chaincfg.Params) *Chain {
+	genesis := &Block{
+		BlockMeta: wtxmgr.BlockMeta{
+			Block:    wtxmgr.Block{Hash: *params.GenesisHash},
+			Time:     genesisTime,
+			VoteBits: dcrutil.BlockValid,
+		},
+	}
+	return &Chain{
+		Subsidy: 1e8,
+		params:  params,
+		blocks:  []*Block{genesis},
+	}
+}
+
+// AddListener connects every block of the current main chain, beginning with
+// the genesis block, to a listener and then registers it for all future
+// changes of the chain.
+func (c *Chain) AddListener(l Listener) error {
+	for _, b := range c.blocks {
+		if err := l.BlockConnected(b); err != nil {
+			return err
+		}
+	}
+	c.listeners = append(c.listeners, l)
+	return nil
+}
+
+// Tip returns the current main chain tip.
+func (c *Chain) Tip() *Block {
+	return c.blocks[len(c.blocks)-1]
+}
+
+// Height returns the height of the main chain tip.
+func (c *Chain) Height() int32 {
+	return c.Tip().Height
+}
+
+// BlockAt returns the main chain block at height, or nil if there is none.
+func (c *Chain) BlockAt(height int32) *Block {
+	if height < 0 || int(height) >= len(c.blocks) {
+		return nil
+	}
+	return c.blocks[height]
+}
+
+// Mempool returns the hashes of the transactions waiting to be mined, in the
+// order they were accepted.
+func (c *Chain) Mempool() []chainhash.Hash {
+	hashes := make([]chainhash.Hash, len(c.mempool))
+	for i, rec := range c.mempool {
+		hashes[i] = rec.Hash
+	}
+	return hashes
+}
+
+// AddTx adds a transaction to the mempool and notifies the listeners.  The
+// transaction is not validated.
+func (c *Chain) AddTx(tx *wire.MsgTx) (*wtxmgr.TxRecord, error) {
+	rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, c.Tip().Time)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range c.mempool {
+		if r.Hash == rec.Hash {
+			return nil, fmt.Errorf("transaction %v is already in the "+
+				"mempool", &rec.Hash)
+		}
+	}
+	c.mempool = append(c.mempool, rec)
+	for _, l := range c.listeners {
+		if err := l.MempoolTx(rec); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// coinbase creates the coinbase transaction of a block at height.  The nonce
+// makes the coinbases of competing blocks at the same height unique.
+func (c *Chain) coinbase(height int32, nonce uint32) (*wtxmgr.TxRecord, error) {
+	var sigScript [8]byte
+	binary.LittleEndian.PutUint32(sigScript[:4], uint32(height))
+	binary.LittleEndian.PutUint32(sigScript[4:], nonce)
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: ^uint32(0)},
+		SignatureScript:  sigScript[:],
+		Sequence:         ^uint32(0),
+	})
+	tx.AddTxOut(wire.NewTxOut(c.Subsidy, c.CoinbaseScript))
+	return wtxmgr.NewTxRecordFromMsgTx(tx, c.blockTime(height))
+}
+
+// blockTime returns the timestamp of blocks at height.
+func (c *Chain) blockTime(height int32) time.Time {
+	return genesisTime.Add(time.Duration(height) * c.params.TargetTimePerBlock)
+}
+
+// blockHash derives a unique hash for a block from its parent, height, nonce,
+// vote bits and transactions.
+func blockHash(parent *chainhash.Hash, b *Block, nonce uint32) chainhash.Hash {
+	buf := make([]byte, 0, chainhash.HashSize*(len(b.Txs)+1)+10)
+	buf = append(buf, parent[:]...)
+	var scratch [10]byte
+	binary.LittleEndian.PutUint32(scratch[0:4], uint32(b.Height))
+	binary.LittleEndian.PutUint32(scratch[4:8], nonce)
+	binary.LittleEndian.PutUint16(scratch[8:10], b.VoteBits)
+	buf = append(buf, scratch[:]...)
+	for _, rec := range b.Txs {
+		buf = append(buf, rec.Hash[:]...)
+	}
+	return chainhash.Hash(chainhash.HashFunc(buf))
+}
+
+// Mine mines a block on the current tip with the mempool transactions
+// selected by the template and notifies the listeners.  A nil template mines
+// every mempool transaction and approves the parent.
+//
+// Disapproving a parent only changes the vote bits of the new block; the
+// transactions of the parent remain mined in it.
+func (c *Chain) Mine(template *BlockTemplate) (*Block, error) {
+	if template == nil {
+		template = &BlockTemplate{}
+	}
+
+	var included []*wtxmgr.TxRecord
+	var remaining []*wtxmgr.TxRecord
+	if template.Txs == nil {
+		included = c.mempool
+	} else {
+		pool := make(map[chainhash.Hash]*wtxmgr.TxRecord, len(c.mempool))
+		for _, rec := range c.mempool {
+			pool[rec.Hash] = rec
+		}
+		for i := range template.Txs {
+			rec, ok := pool[template.Txs[i]]
+			if !ok {
+				return nil, fmt.Errorf("transaction %v is not in "+
+					"the mempool", &template.Txs[i])
+			}
+			delete(pool, rec.Hash)
+			included = append(included, rec)
+		}
+		for _, rec := range c.mempool {
+			if _, ok := pool[rec.Hash]; ok {
+				remaining = append(remaining, rec)
+			}
+		}
+	}
+
+	parent := c.Tip()
+	height := parent.Height + 1
+	c.nonce++
+	cb, err := c.coinbase(height, c.nonce)
+	if err != nil {
+		return nil, err
+	}
+	b := &Block{
+		BlockMeta: wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{Height: height},
+			Time:  c.blockTime(height),
+		},
+		Txs: append([]*wtxmgr.TxRecord{cb}, included...),
+	}
+	if !template.DisapproveParent {
+		b.VoteBits = dcrutil.BlockValid
+	}
+	b.Hash = blockHash(&parent.Hash, b, c.nonce)
+
+	c.blocks = append(c.blocks, b)
+	c.mempool = remaining
+	for _, l := range c.listeners {
+		if err := l.BlockConnected(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Generate mines n blocks, each including every mempool transaction, and
+// returns them.
+func (c *Chain) Generate(n int) ([]*Block, error) {
+	blocks := make([]*Block, 0, n)
+	for i := 0; i < n; i++ {
+		b, err := c.Mine(nil)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// Disconnect removes the n tip blocks from the main chain, tip first, and
+// notifies the listeners of each.  The non-coinbase transactions of the
+// removed blocks return to the mempool.
+func (c *Chain) Disconnect(n int) error {
+	if n >= len(c.blocks) {
+		return errors.New("cannot disconnect the genesis block")
+	}
+	for i := 0; i < n; i++ {
+		b := c.Tip()
+		c.blocks = c.blocks[:len(c.blocks)-1]
+		c.mempool = append(append([]*wtxmgr.TxRecord(nil), b.Txs[1:]...),
+			c.mempool...)
+		for _, l := range c.listeners {
+			if err := l.BlockDisconnected(b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reorg replaces the depth tip blocks of the main chain with a side chain
+// mined from the templates.  The new branch must be longer than the one it
+// replaces.
+func (c *Chain) Reorg(depth int, templates ...*BlockTemplate) ([]*Block, error) {
+	if len(templates) <= depth {
+		return nil, fmt.Errorf("a reorg of depth %d requires at least %d "+
+			"new blocks", depth, depth+1)
+	}
+	if err := c.Disconnect(depth); err != nil {
+		return nil, err
+	}
+	blocks := make([]*Block, 0, len(templates))
+	for _, t := range templates {
+		b, err := c.Mine(t)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
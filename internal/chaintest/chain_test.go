@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package chaintest
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/chain"
+)
+
+func spendTx(hash *chainhash.Hash, value int64) *wire.MsgTx {
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, 0, dcrutil.TxTreeRegular), nil))
+	tx.AddTxOut(wire.NewTxOut(value, nil))
+	return tx
+}
+
+func TestGenerate(t *testing.T) {
+	c := New(&chaincfg.SimNetParams)
+	blocks, err := c.Generate(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Height() != 3 || len(blocks) != 3 {
+		t.Fatalf("got height %d and %d blocks, want 3", c.Height(),
+			len(blocks))
+	}
+	seen := make(map[chainhash.Hash]bool)
+	for i, b := range blocks {
+		if b.Height != int32(i+1) {
+			t.Errorf("block %d: got height %d", i, b.Height)
+		}
+		if c.BlockAt(b.Height) != b {
+			t.Errorf("block %d: not found at its height", i)
+		}
+		if !b.ParentApproved() {
+			t.Errorf("block %d: parent not approved", i)
+		}
+		if len(b.Txs) != 1 || !b.Txs[0].MsgTx.TxIn[0].PreviousOutPoint.Hash.IsEqual(&chainhash.Hash{}) {
+			t.Errorf("block %d: expected only a coinbase", i)
+		}
+		if seen[b.Hash] || seen[b.Txs[0].Hash] {
+			t.Errorf("block %d: duplicate block or coinbase hash", i)
+		}
+		seen[b.Hash] = true
+		seen[b.Txs[0].Hash] = true
+		if !b.Time.After(c.BlockAt(b.Height - 1).Time) {
+			t.Errorf("block %d: time does not increase", i)
+		}
+	}
+}
+
+func TestMineTemplate(t *testing.T) {
+	c := New(&chaincfg.SimNetParams)
+	blocks, err := c.Generate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb := &blocks[0].Txs[0].Hash
+	a, err := c.AddTx(spendTx(cb, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.AddTx(spendTx(cb, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AddTx(spendTx(cb, 2)); err == nil {
+		t.Error("duplicate mempool transaction was accepted")
+	}
+
+	_, err = c.Mine(&BlockTemplate{Txs: []chainhash.Hash{{1}}})
+	if err == nil {
+		t.Error("mined a transaction missing from the mempool")
+	}
+
+	block, err := c.Mine(&BlockTemplate{
+		Txs:              []chainhash.Hash{b.Hash},
+		DisapproveParent: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Txs) != 2 || block.Txs[1] != b {
+		t.Errorf("block does not mine only the selected transaction")
+	}
+	if block.ParentApproved() {
+		t.Errorf("block approves its parent")
+	}
+	if m := c.Mempool(); len(m) != 1 || m[0] != a.Hash {
+		t.Errorf("unexpected mempool %v", m)
+	}
+}
+
+func TestReorg(t *testing.T) {
+	c := New(&chaincfg.SimNetParams)
+	blocks, err := c.Generate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := c.AddTx(spendTx(&blocks[0].Txs[0].Hash, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := c.Mine(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := new(Recorder)
+	if err := c.AddListener(r); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(r.Take()); n != 8 {
+		t.Fatalf("replayed %d notifications, want 8", n)
+	}
+
+	if _, err := c.Reorg(2, nil); err == nil {
+		t.Error("reorg to a shorter chain succeeded")
+	}
+	side, err := c.Reorg(1, &BlockTemplate{Txs: []chainhash.Hash{}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Height() != 4 || side[0].Height != 3 || side[0].Hash == old.Hash {
+		t.Fatalf("unexpected chain after reorg")
+	}
+	if len(side[0].Txs) != 1 || len(side[1].Txs) != 2 || side[1].Txs[1] != tx {
+		t.Errorf("reorganized transaction was not mined again")
+	}
+
+	ntfns := r.Take()
+	want := []interface{}{
+		chain.BlockDisconnected(old.BlockMeta),
+		chain.RelevantTx{},
+		chain.BlockConnected(side[0].BlockMeta),
+		chain.RelevantTx{},
+		chain.RelevantTx{},
+		chain.BlockConnected(side[1].BlockMeta),
+	}
+	if len(ntfns) != len(want) {
+		t.Fatalf("got %d notifications, want %d", len(ntfns), len(want))
+	}
+	for i, n := range ntfns {
+		switch w := want[i].(type) {
+		case chain.RelevantTx:
+			n, ok := n.(chain.RelevantTx)
+			if !ok || n.Block == nil {
+				t.Errorf("notification %d: got %#v, want mined RelevantTx", i, n)
+			}
+		default:
+			if n != w {
+				t.Errorf("notification %d: got %#v, want %#v", i, n, w)
+			}
+		}
+	}
+}
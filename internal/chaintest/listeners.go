@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package chaintest
+
+import (
+	"github.com/decred/dcrwallet/chain"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// Filter reports whether a transaction is relevant to a wallet and which of
+// its outputs are wallet credits.
+type Filter func(rec *wtxmgr.TxRecord) (credits []uint32, relevant bool)
+
+// allOutputs is the default Filter which considers every transaction relevant
+// and every output a credit.
+func allOutputs(rec *wtxmgr.TxRecord) ([]uint32, bool) {
+	credits := make([]uint32, len(rec.MsgTx.TxOut))
+	for i := range credits {
+		credits[i] = uint32(i)
+	}
+	return credits, true
+}
+
+// StoreListener applies chain changes to a transaction store the same way
+// wallet sync does: relevant transactions and their credits are inserted
+// before the block mining them is recorded, and disconnected blocks are
+// rolled back.
+type StoreListener struct {
+	Store *wtxmgr.Store
+
+	// Filter selects the recorded transactions and credits.  When nil,
+	// every transaction is recorded with all outputs as credits.
+	Filter Filter
+
+	// CheckInvariants causes the store invariants to be checked after
+	// every change is applied.
+	CheckInvariants bool
+}
+
+// checked returns err, or the result of checking the store invariants when
+// err is nil and checking is enabled.
+func (l *StoreListener) checked(err error) error {
+	if err != nil || !l.CheckInvariants {
+		return err
+	}
+	return l.Store.CheckInvariants()
+}
+
+func (l *StoreListener) filter(rec *wtxmgr.TxRecord) ([]uint32, bool) {
+	if l.Filter == nil {
+		return allOutputs(rec)
+	}
+	return l.Filter(rec)
+}
+
+func (l *StoreListener) insert(rec *wtxmgr.TxRecord, block *wtxmgr.BlockMeta) error {
+	credits, relevant := l.filter(rec)
+	if !relevant {
+		return nil
+	}
+	err := l.Store.InsertTx(rec, block)
+	if err != nil {
+		return err
+	}
+	for _, index := range credits {
+		err = l.Store.AddCredit(rec, block, index)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MempoolTx implements the Listener interface.
+func (l *StoreListener) MempoolTx(rec *wtxmgr.TxRecord) error {
+	return l.checked(l.insert(rec, nil))
+}
+
+// BlockConnected implements the Listener interface.
+func (l *StoreListener) BlockConnected(b *Block) error {
+	for _, rec := range b.Txs {
+		err := l.insert(rec, &b.BlockMeta)
+		if err != nil {
+			return err
+		}
+	}
+	return l.checked(l.Store.InsertBlock(&b.BlockMeta))
+}
+
+// BlockDisconnected implements the Listener interface.
+func (l *StoreListener) BlockDisconnected(b *Block) error {
+	return l.checked(l.Store.Rollback(b.Height))
+}
+
+// Recorder records chain changes as the notifications a chain.Client
+// delivers: a chain.RelevantTx for every relevant mempool or mined
+// transaction, a chain.BlockConnected after the relevant transactions of each
+// new block, and a chain.BlockDisconnected for every removed block.
+type Recorder struct {
+	// Filter selects the relevant transactions.  When nil, every
+	// transaction is relevant.
+	Filter Filter
+
+	notifications []interface{}
+}
+
+func (r *Recorder) relevant(rec *wtxmgr.TxRecord) bool {
+	if r.Filter == nil {
+		return true
+	}
+	_, relevant := r.Filter(rec)
+	return relevant
+}
+
+// MempoolTx implements the Listener interface.
+func (r *Recorder) MempoolTx(rec *wtxmgr.TxRecord) error {
+	if r.relevant(rec) {
+		r.notifications = append(r.notifications, chain.RelevantTx{
+			TxRecord: rec,
+		})
+	}
+	return nil
+}
+
+// BlockConnected implements the Listener interface.
+func (r *Recorder) BlockConnected(b *Block) error {
+	for _, rec := range b.Txs {
+		if r.relevant(rec) {
+			block := b.BlockMeta
+			r.notifications = append(r.notifications, chain.RelevantTx{
+				TxRecord: rec,
+				Block:    &block,
+			})
+		}
+	}
+	r.notifications = append(r.notifications, chain.BlockConnected(b.BlockMeta))
+	return nil
+}
+
+// BlockDisconnected implements the Listener interface.
+func (r *Recorder) BlockDisconnected(b *Block) error {
+	r.notifications = append(r.notifications, chain.BlockDisconnected(b.BlockMeta))
+	return nil
+}
+
+// Take returns the notifications recorded since the last call and clears
+// them.
+func (r *Recorder) Take() []interface{} {
+	n := r.notifications
+	r.notifications = nil
+	return n
+}
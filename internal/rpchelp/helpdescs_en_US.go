@@ -56,14 +56,16 @@ var helpDescsEnUS = map[string]string{
 	"getaddressesbyaccount--result0":  "All addresses controlled by 'account'",
 
 	// GetBalanceCmd help.
-	"getbalance--synopsis":   "Calculates and returns the balance of one or all accounts.",
-	"getbalance-minconf":     "Minimum number of block confirmations required before an unspent output's value is included in the balance",
-	"getbalance-account":     "DEPRECATED -- The account name to query the balance for, or \"*\" to consider all accounts (default=\"*\")",
-	"getbalance-balancetype": "The type of balance to return, 'spendable', 'locked', 'all', or 'fullscan'",
-	"getbalance--condition0": "account != \"*\"",
-	"getbalance--condition1": "account = \"*\"",
-	"getbalance--result0":    "The balance of 'account' valued in decred",
-	"getbalance--result1":    "The balance of all accounts valued in decred",
+	"getbalance--synopsis":       "Calculates and returns the balance of one or all accounts.",
+	"getbalance-minconf":         "Minimum number of block confirmations required before an unspent output's value is included in the balance",
+	"getbalance-account":         "The account name to query the balance for, \"default\" for the balance of the whole wallet, or \"*\" for the balance of each account (default=\"default\")",
+	"getbalance-balancetype":     "The type of balance to return, 'spendable', 'locked' (value locked in tickets), 'all' (all unspent outputs), or 'fullscan' (spendable balance verified by a scan of all unspent outputs)",
+	"getbalance--condition0":     "account != \"*\"",
+	"getbalance--condition1":     "account = \"*\"",
+	"getbalance--result0":        "The balance of 'account' valued in decred",
+	"getbalance--result1--desc":  "JSON object with account names as keys and decred amounts as values",
+	"getbalance--result1--key":   "The account name",
+	"getbalance--result1--value": "The balance of the account valued in decred",
 
 	// GetBestBlockHashCmd help.
 	"getbestblockhash--synopsis": "Returns the hash of the newest block in the best chain that wallet has finished syncing with.",
@@ -134,7 +136,7 @@ var helpDescsEnUS = map[string]string{
 	"infowalletresult-keypoololdest":   "Unset",
 
 	// GetNewAddressCmd help.
-	"getnewaddress--synopsis": "Generates and returns a new payment address.",
+	"getnewaddress--synopsis": `Generates and returns a new payment address.  An options object may be passed as a third parameter with the optional keys "type" (the address type: "p2pkh", the default, "p2pk" for the public key, or "p2sh" for a 1-of-1 multisig script of the public key, which is imported) and "gappolicy" ("ignore", the default, "warn", or "error" to refuse addresses following gap limit unused addresses).  The reply is then an object with the "address", its "type", the "pubkey", the "redeemscript" of P2SH addresses, the "gap" of unused addresses preceding the address, whether it "exceedsgaplimit", and a "warning" when the gap policy is "warn".`,
 	"getnewaddress-account":   "DEPRECATED -- Account name the new address will belong to (default=\"default\")",
 	"getnewaddress-verbose":   "Show pub key as well as address",
 	"getnewaddress--result0":  "The payment address",
@@ -146,19 +148,19 @@ var helpDescsEnUS = map[string]string{
 	"getrawchangeaddress--result0":  "The internal payment address",
 
 	// GetReceivedByAccountCmd help.
-	"getreceivedbyaccount--synopsis": "DEPRECATED -- Returns the total amount received by addresses of some account, including spent outputs.",
+	"getreceivedbyaccount--synopsis": `DEPRECATED -- Returns the total amount received by addresses of some account, including spent outputs. Outputs of stake transactions which are not yet mature are excluded unless an options object with the key "includeimmaturestake" set to true is passed as a third parameter.`,
 	"getreceivedbyaccount-account":   "Account name to query total received amount for",
 	"getreceivedbyaccount-minconf":   "Minimum number of block confirmations required before an output's value is included in the total",
 	"getreceivedbyaccount--result0":  "The total received amount valued in decred",
 
 	// GetReceivedByAddressCmd help.
-	"getreceivedbyaddress--synopsis": "Returns the total amount received by a single address, including spent outputs.",
+	"getreceivedbyaddress--synopsis": `Returns the total amount received by a single address, including spent outputs. Outputs of stake transactions which are not yet mature are excluded unless an options object with the key "includeimmaturestake" set to true is passed as a third parameter.`,
 	"getreceivedbyaddress-address":   "Payment address which received outputs to include in total",
 	"getreceivedbyaddress-minconf":   "Minimum number of block confirmations required before an output's value is included in the total",
 	"getreceivedbyaddress--result0":  "The total received amount valued in decred",
 
 	// GetTransactionCmd help.
-	"gettransaction--synopsis":        "Returns a JSON object with details regarding a transaction relevant to this wallet.",
+	"gettransaction--synopsis":        `Returns a JSON object with details regarding a transaction relevant to this wallet. An options object with the key "verbose" set to true may be passed as a third parameter to include the details of stake transactions: the "ticket" price and commitments, the "vote" ticket, block voted on, and vote bits, or the "revocation" ticket and refunded amount. Verbose results of mined regular transactions also include the "approval" status of their block: "pending" until the next block is seen, then "approved" or "disapproved" by the votes of the next block. Verbose results of transactions authored by the wallet also include the "change" outputs, with the address, account, and internal branch child index of each change address, so the change may be verified against the account extended public key.`,
 	"gettransaction-txid":             "Hash of the transaction to query",
 	"gettransaction-includewatchonly": "Also consider transactions involving watched addresses",
 
@@ -199,8 +201,14 @@ var helpDescsEnUS = map[string]string{
 	"importprivkey-label":     "Unused (must be unset or 'imported')",
 	"importprivkey-rescan":    "Rescan the blockchain (since the genesis block) for outputs controlled by the imported key",
 
+	// ImportPubKeyCmd help.
+	"importpubkey--synopsis": "Imports a hex encoded public key to the 'imported' account as a watching-only address and returns the address.  Outputs paid to the address may only be spent by a wallet signing with a remote signer holding the private key, such as a dcrsigner process using a hardware security module.",
+	"importpubkey-pubkey":    "The hex encoded public key",
+	"importpubkey-rescan":    "Rescan the blockchain (since the genesis block) for outputs paid to the address of the key",
+	"importpubkey--result0":  "The P2PKH address of the public key",
+
 	// ImportScript help.
-	"importscript--synopsis": "Import a redeem script.",
+	"importscript--synopsis": `Import a redeem script.  An options object may be passed as a second parameter with the key "firstseen", the height of the first block using the script.  Only the blocks since that height are then rescanned, and the reply is an object with the "address" of the script, the "scriptaddresses" the script pays to, and the unspent "outputs" to the script found by the rescan, in the format of listunspent results.`,
 	"importscript-hex":       "Hex encoded script to import",
 
 	// KeypoolRefillCmd help.
@@ -282,14 +290,14 @@ var helpDescsEnUS = map[string]string{
 	"listtransactionsresult-otheraccount":      "Unset",
 
 	// ListTransactionsCmd help.
-	"listtransactions--synopsis":        "Returns a JSON array of objects containing verbose details for wallet transactions.",
-	"listtransactions-account":          "DEPRECATED -- Unused (must be unset or \"*\")",
+	"listtransactions--synopsis":        `Returns a JSON array of objects containing verbose details for wallet transactions.  A filter object may be passed as a fifth parameter with the optional keys "addresses" (array of addresses), "txtypes" (array of "regular", "ticket", "vote", or "revocation"), "starttime" and "endtime" (Unix times), and "cursor" (the "nextcursor" of a previous reply), in which case the results are returned in an object under "transactions" together with the "nextcursor" of the next page.  Transactions stamped with a fiat exchange rate when they were first seen are included in the "fiatrates" object of the reply, mapping their txid to an object with the "currency", the "rate" per coin, and the Unix "time" it was quoted.`,
+	"listtransactions-account":          "The account to list transactions of, or \"*\" for all accounts",
 	"listtransactions-count":            "Maximum number of transactions to create results from",
 	"listtransactions-from":             "Number of transactions to skip before results are created",
 	"listtransactions-includewatchonly": "Unused",
 
 	// ListUnspentCmd help.
-	"listunspent--synopsis": "Returns a JSON array of objects representing unlocked unspent outputs controlled by wallet keys.",
+	"listunspent--synopsis": `Returns a JSON array of objects representing unlocked unspent outputs controlled by wallet keys.  An options object may be passed as a fourth parameter with the optional keys "account" (the account of the outputs) and "includeimmaturestake" (include stake outputs which are not yet spendable), in which case each result additionally includes the "scriptclass" of the output script, whether the output is "spendable", and the "reason" it is not.`,
 	"listunspent-minconf":   "Minimum number of block confirmations required before a transaction output is considered",
 	"listunspent-maxconf":   "Maximum number of block confirmations required before a transaction output is excluded",
 	"listunspent-addresses": "If set, limits the returned details to unspent outputs received by any of these payment addresses",
@@ -321,7 +329,7 @@ var helpDescsEnUS = map[string]string{
 	"sendfrom-fromaccount": "Account to pick unspent outputs from",
 	"sendfrom-toaddress":   "Address to pay",
 	"sendfrom-amount":      "Amount to send to the payment address valued in decred",
-	"sendfrom-minconf":     "Minimum number of block confirmations required before a transaction output is eligible to be spent",
+	"sendfrom-minconf":     "Minimum number of block confirmations required before a transaction output is eligible to be spent, or the wallet's minconf setting when omitted",
 	"sendfrom-comment":     "Unused",
 	"sendfrom-commentto":   "Unused",
 	"sendfrom--result0":    "The transaction hash of the sent transaction",
@@ -334,7 +342,7 @@ var helpDescsEnUS = map[string]string{
 	"sendmany-amounts--desc":  "JSON object using payment addresses as keys and output amounts valued in decred to send to each address",
 	"sendmany-amounts--key":   "Address to pay",
 	"sendmany-amounts--value": "Amount to send to the payment address valued in decred",
-	"sendmany-minconf":        "Minimum number of block confirmations required before a transaction output is eligible to be spent",
+	"sendmany-minconf":        "Minimum number of block confirmations required before a transaction output is eligible to be spent, or the wallet's minconf setting when omitted",
 	"sendmany-comment":        "Unused",
 	"sendmany--result0":       "The transaction hash of the sent transaction",
 
@@ -352,7 +360,7 @@ var helpDescsEnUS = map[string]string{
 	"sendtomultisig--synopsis": "Authors, signs, and sends a transaction that outputs some amount to a multisig address.\n" +
 		"Unlike sendfrom, outputs are always chosen from the default account.\n" +
 		"A change output is automatically included to send extra output value back to the original account.",
-	"sendtomultisig-minconf":     "Minimum number of block confirmations required",
+	"sendtomultisig-minconf":     "Minimum number of block confirmations required, or the wallet's minconf setting when omitted",
 	"sendtomultisig-nrequired":   "The number of signatures required to redeem outputs paid to this address",
 	"sendtomultisig-pubkeys":     "Pubkey to send to.",
 	"sendtomultisig-fromaccount": "Unused",
@@ -406,6 +414,13 @@ var helpDescsEnUS = map[string]string{
 	"signedtransaction-sent":            "Tells if the transaction was sent.",
 	"signedtransaction-signingresult":   "Success or failure of signing.",
 
+	// TicketsForAddressCmd help.
+	"ticketsforaddress--synopsis": "Returns the hashes of the tickets whose voting address is the passed address.",
+	"ticketsforaddress-address":   "The voting address of the tickets",
+
+	// TicketsForAddressResult help.
+	"ticketsforaddressresult-tickets": "The hashes of the tickets",
+
 	// ValidateAddressCmd help.
 	"validateaddress--synopsis": "Verify that an address is valid.\n" +
 		"Extra details are returned if the address is controlled by this wallet.\n" +
@@ -446,7 +461,7 @@ var helpDescsEnUS = map[string]string{
 	"walletpassphrase-timeout":    "The number of seconds to wait before the wallet automatically locks",
 
 	// WalletPassphraseChangeCmd help.
-	"walletpassphrasechange--synopsis":     "Change the wallet passphrase.",
+	"walletpassphrasechange--synopsis":     "Change the wallet passphrase and lock the wallet. The wallet must be unlocked again with the new passphrase.",
 	"walletpassphrasechange-oldpassphrase": "The old wallet passphrase",
 	"walletpassphrasechange-newpassphrase": "The new wallet passphrase",
 
@@ -513,7 +528,7 @@ var helpDescsEnUS = map[string]string{
 	"purchaseticket--result0":      "Hash of the resulting ticket",
 	"purchaseticket-spendlimit":    "Limit on the amount to spend on ticket",
 	"purchaseticket-fromaccount":   "The account to use for purchase (default=\"default\")",
-	"purchaseticket-minconf":       "Minimum number of block confirmations required",
+	"purchaseticket-minconf":       "Minimum number of block confirmations required, or the wallet's minconf setting when omitted",
 	"purchaseticket-ticketaddress": "Override the ticket address to which voting rights are given",
 	"purchaseticket-comment":       "Unused",
 
@@ -528,7 +543,7 @@ var helpDescsEnUS = map[string]string{
 	"sendtosstx--synopsis":      "Send to SStx",
 	"sendtosstx--result0":       "txid of the resulting transaction",
 	"sendtosstx-comment":        "Unused",
-	"sendtosstx-minconf":        "Minimum number of block confirmations required",
+	"sendtosstx-minconf":        "Minimum number of block confirmations required, or the wallet's minconf setting when omitted",
 	"sendtosstx-couts":          "Couts for the tx",
 	"sendtosstx-inputs":         "Inputs for the tx",
 	"sendtosstx-amounts--desc":  "Unused",
@@ -555,4 +570,545 @@ var helpDescsEnUS = map[string]string{
 	"sendtossgen-blockhash":   "Hash for the block being voted on",
 	"sendtossgen-tickethash":  "Hash of the ticket used for vote",
 	"sendtossgen-fromaccount": "The account to use (default=\"default\")",
+
+	// CancelRescanCmd help.
+	"cancelrescan--synopsis": "Stops a rescan started by rescanwallet after the blocks currently being rescanned.",
+
+	// ListAddressTicketsCmd help.
+	"listaddresstickets--synopsis": "Returns every ticket tracked by the wallet whose voting address or any commitment address is the passed address, so users of a stake pool may find the tickets they delegated to it.",
+	"listaddresstickets-address":   "The voting or commitment address of the tickets",
+
+	// ListAddressTicketsResult help.
+	"listaddressticketsresult-ticket":           "The hash of the ticket",
+	"listaddressticketsresult-voting":           "Whether the address is the ticket's voting address",
+	"listaddressticketsresult-commitment":       "Whether the address is one of the ticket's commitment addresses",
+	"listaddressticketsresult-commitmentamount": "The total amount the ticket commits to the address",
+	"listaddressticketsresult-status":           `The status of the ticket: "unmined", "immature", "live", "expired", "missed", "voted", "revoked", or "unknown"`,
+
+	// RescanWalletCmd help.
+	"rescanwallet--synopsis":   "Rescans the main chain from a height or time for transactions involving the wallet's addresses and unspent outputs.  The chain is rescanned in chunks, and the progress after each chunk is sent to websocket clients subscribed with notifyrescanprogress as rescanwalletprogress notifications.  The rescan may be stopped by cancelrescan, and the reply summarizes the rescan once it completes or is cancelled.",
+	"rescanwallet-beginheight": "The height of the first block to rescan",
+	"rescanwallet-begintime":   "If set, the rescan begins at the first block with a timestamp at or after this Unix time instead of beginheight",
+
+	// RescanWalletResult help.
+	"rescanwalletresult-startheight":  "The height of the first rescanned block",
+	"rescanwalletresult-height":       "The height of the last rescanned block",
+	"rescanwalletresult-hash":         "The hash of the last rescanned block",
+	"rescanwalletresult-transactions": "The number of wallet transactions in the rescanned blocks",
+	"rescanwalletresult-cancelled":    "Whether the rescan was cancelled before reaching the best block",
+
+	// RotateImportedKeyCmd help.
+	"rotateimportedkey--synopsis": "Sweeps the funds controlled by an imported private key or script to a new internal address of a wallet account in a single transaction, and then retires the imported address once no outputs paid to it remain, for responding to a suspected exposure of the key.  Unless confirmed, nothing is signed, published, or retired, and the sweep which would be made is described so it can be reviewed first.  Immature, locked, unconfirmed, and dust outputs are skipped and may be swept by rotating the key again once they are spendable, and the address is not retired until they are.  Outputs of an imported script are only swept when the wallet holds enough keys to fully sign for the script.  Payments received later by a retired address are logged as warnings.",
+	"rotateimportedkey-address":   "The imported P2PKH or P2SH address to rotate",
+	"rotateimportedkey-account":   "The account to sweep the funds to",
+	"rotateimportedkey-confirm":   "Sweep the funds and retire the address instead of previewing the sweep",
+
+	// RotateImportedKeyResult help.
+	"rotateimportedkeyresult-address":       "The rotated imported address",
+	"rotateimportedkeyresult-inputs":        "The number of outputs swept",
+	"rotateimportedkeyresult-amount":        "The total value of the swept outputs",
+	"rotateimportedkeyresult-fee":           "The fee paid by the sweep, out of the swept amount",
+	"rotateimportedkeyresult-skipped":       "The number of outputs to the address which could not be swept yet",
+	"rotateimportedkeyresult-skippedamount": "The total value of the skipped outputs",
+	"rotateimportedkeyresult-destination":   "The new address the funds were swept to, omitted for previews and when there was nothing to sweep",
+	"rotateimportedkeyresult-txid":          "The hash of the sweep transaction, omitted for previews and when there was nothing to sweep",
+	"rotateimportedkeyresult-retired":       "Whether the address was retired, which only happens when no outputs were skipped",
+
+	// ListRetiredAddressesCmd help.
+	"listretiredaddresses--synopsis": "Returns the imported keys and scripts retired by rotateimportedkey, ordered by output script.",
+
+	// ListRetiredAddressesResult help.
+	"listretiredaddressesresult-address":   "The retired address",
+	"listretiredaddressesresult-script":    "The hex-encoded output script of the address",
+	"listretiredaddressesresult-retired":   "The Unix time the address was last retired",
+	"listretiredaddressesresult-sweeptxid": "The hash of the transaction which swept the funds of the address, omitted when there was nothing to sweep",
+
+	// GetAddressPathCmd help.
+	"getaddresspath--synopsis": "Returns the account, branch, and index the key of a wallet address was derived at, so hardware and remote signers holding the wallet seed can derive the key of the address.  Imported addresses and scripts are not derived from the seed and have no derivation path.",
+	"getaddresspath-address":   "The wallet address",
+
+	// GetAddressPathResult help.
+	"getaddresspathresult-address":       "The address",
+	"getaddresspathresult-account":       "The name of the account of the address",
+	"getaddresspathresult-accountnumber": "The number of the account of the address",
+	"getaddresspathresult-branch":        "The branch of the address: 0 for external and 1 for internal (change) addresses",
+	"getaddresspathresult-index":         "The index of the address in its branch",
+	"getaddresspathresult-path":          "The BIP0032 derivation path of the key of the address, m/44'/<coin type>'/<account>'/<branch>/<index>",
+
+	// GetAPIInfoCmd help.
+	"getapiinfo--synopsis":  "Returns the version of the wallet JSON-RPC API, which follows the semantic versioning spec, and the optional features provided by the server.  Clients may pass the API version they were written against to check that it is supported.",
+	"getapiinfo-apiversion": "The major.minor or major.minor.patch API version required by the client.  An error is returned unless the major versions match and the server's minor version is at least as new",
+
+	// GetAPIInfoResult help.
+	"getapiinforesult-version":      "The API version string",
+	"getapiinforesult-major":        "The major API version",
+	"getapiinforesult-minor":        "The minor API version",
+	"getapiinforesult-patch":        "The patch API version",
+	"getapiinforesult-capabilities": `The optional features provided by the server, such as "accounthistory", "addresspaths", "addressusage", "balancehistory", "batch", "birthday", "capitalgains", "creditorigins", "decoderawtransaction", "describescript", "fiatrates", "gaplimit", "grpc", "importedbalance", "importpubkey", "jobs", "keyrotation", "multisigwallet", "multiwallet", "notifyconfirmations", "paymenturi", "permissions", "poolshare", "rescanwallet", "seedbackup", "sendapproval", "signinglog", "stakediffestimate", "stakepool", "ticketbuyer", "ticketbuyerlog", "votebits", "votingonly", "vspclient", and "watchonly"`,
+
+	// GetBackendStateCmd help.
+	"getbackendstate--synopsis": "Returns the state of the connection to the chain server, and how far the wallet is behind the chain server in processing blocks, notifications, and rescans.  Times are Unix times, and are 0 when the event has not occurred.",
+
+	// GetBackendStateResult help.
+	"getbackendstateresult-connected":           "Whether the wallet is connected to the chain server",
+	"getbackendstateresult-endpoint":            "The address of the chain server, omitted when the wallet is offline",
+	"getbackendstateresult-connectedtime":       "The Unix time the connection to the chain server was established",
+	"getbackendstateresult-bestblockhash":       "The hash of the last block the chain server notified as connected to the main chain",
+	"getbackendstateresult-bestblockheight":     "The height of the last block the chain server notified as connected to the main chain",
+	"getbackendstateresult-bestblocktime":       "The Unix time the last block notification was received from the chain server",
+	"getbackendstateresult-syncedheight":        "The height of the block the wallet is synced to",
+	"getbackendstateresult-blocksbehind":        "The number of blocks the wallet is behind the best block notified by the chain server",
+	"getbackendstateresult-chainsynced":         "Whether the wallet is in sync with the chain server",
+	"getbackendstateresult-queuednotifications": "The number of chain server notifications waiting to be processed by the wallet",
+	"getbackendstateresult-lasthealthcheck":     "The Unix time the chain server last passed a health check",
+	"getbackendstateresult-rescanqueuedepth":    "The number of rescans running or waiting to run",
+
+	// GetFeesReportCmd help.
+	"getfeesreport--synopsis": "Returns the fees paid by the wallet's transactions within a time range, totalled separately for regular transactions, tickets, votes, and revocations.  Only transactions spending wallet funds are included, and the block time of mined transactions, or the time unmined transactions were received, is compared against the range.",
+	"getfeesreport-starttime": "If set, only transactions at or after this Unix time are included",
+	"getfeesreport-endtime":   "If set, only transactions before this Unix time are included",
+
+	// GetFeesReportResult help.
+	"getfeesreportresult-regularcount":    "The number of regular transactions",
+	"getfeesreportresult-regularfees":     "The fees paid by regular transactions",
+	"getfeesreportresult-ticketcount":     "The number of tickets",
+	"getfeesreportresult-ticketfees":      "The fees paid by tickets",
+	"getfeesreportresult-votecount":       "The number of votes",
+	"getfeesreportresult-votefees":        "The fees implied by votes: the value of the ticket and vote subsidy which is not paid to the vote's outputs",
+	"getfeesreportresult-revocationcount": "The number of revocations",
+	"getfeesreportresult-revocationfees":  "The fees paid by revocations",
+	"getfeesreportresult-unknowncount":    "The number of transactions whose fee is unknown because some inputs do not spend wallet outputs",
+	"getfeesreportresult-totalfees":       "The total fees paid by all included transactions",
+
+	// GetLockInfoCmd help.
+	"getlockinfo--synopsis": "Returns whether the wallet is locked and, for a wallet unlocked with a timeout, when it will be locked again.",
+
+	// GetLockInfoResult help.
+	"getlockinforesult-locked":    "Whether the wallet is locked",
+	"getlockinforesult-locktime":  "The Unix time the wallet will be locked again, or 0 if the wallet is locked or unlocked without a timeout",
+	"getlockinforesult-remaining": "The number of seconds until the wallet is locked again, or 0 if the wallet is locked or unlocked without a timeout",
+
+	// SetUnlockTimeoutCmd help.
+	"setunlocktimeout--synopsis": "Replaces the timeout of an unlocked wallet, extending or shortening the time until it is locked again.",
+	"setunlocktimeout-timeout":   "The number of seconds from now until the wallet is locked, or 0 to keep the wallet unlocked until it is explicitly locked",
+
+	// StartJobCmd help.
+	"startjob--synopsis": "Starts handling a request in the background and returns the ID of the new job.  Only the importprivkey, importscript, rescanwallet, and setbirthday methods, which may take minutes to complete, may be run as jobs, and the caller must have permission to call the method.  The status of the job is returned by getjobstatus, and websocket clients subscribed with notifyjobs receive a jobstatus notification when the job finishes.",
+	"startjob-method":    "The method of the request to run as a job",
+	"startjob-params":    "The parameters of the request",
+	"startjob--result0":  "The ID of the job",
+
+	// GetJobStatusCmd help.
+	"getjobstatus--synopsis": "Returns the status of a job started by startjob, and the result or error of the job's request once it has finished.",
+	"getjobstatus-jobid":     "The ID of the job returned by startjob",
+
+	// ListJobsCmd help.
+	"listjobs--synopsis": "Returns the status of every running job and of the most recently finished jobs, without their results.",
+
+	// JobStatusResult help.
+	"jobstatusresult-jobid":    "The ID of the job",
+	"jobstatusresult-method":   "The method of the job's request",
+	"jobstatusresult-status":   `The status of the job: "running", "done", or "failed"`,
+	"jobstatusresult-started":  "The Unix time the job was started",
+	"jobstatusresult-finished": "The Unix time the job finished, if it has finished",
+	"jobstatusresult-result":   "The result of the job's request, if the job is done",
+	"jobstatusresult-error":    "The error of the job's request, if the job failed",
+
+	// DebugLevelCmd help.
+	"debuglevel--synopsis": "Dynamically changes the debug logging level.\n" +
+		"The levelspec can either be a debug level or of the form:\n" +
+		"<subsystem>=<level>,<subsystem2>=<level2>,...\n" +
+		"The valid debug levels are trace, debug, info, warn, error, and critical.\n" +
+		"The valid subsystems are ADDR, CHNS, DCRW, GRPC, RPCS, STKM, TKBY, WLLT, and WTXM.\n" +
+		"Finally the keyword 'show' will return a list of the available subsystems.",
+	"debuglevel-levelspec":   "The debug level(s) to use or the keyword 'show'",
+	"debuglevel--condition0": "levelspec!=show",
+	"debuglevel--condition1": "levelspec=show",
+	"debuglevel--result0":    "The string 'Done.'",
+	"debuglevel--result1":    "The list of subsystems",
+
+	// LoadWalletCmd help.
+	"loadwallet--synopsis": "Loads a wallet in addition to the default wallet.  The wallet is opened from the wallets/<name> subdirectory of the data directory, where it must have been created with --create.  Requests for the wallet are sent by HTTP POST to /wallet/<name>.",
+	"loadwallet-name":      "The name of the wallet",
+
+	// UnloadWalletCmd help.
+	"unloadwallet--synopsis": "Stops and closes a wallet loaded by loadwallet.",
+	"unloadwallet-name":      "The name of the wallet",
+
+	// ExportSigningLogCmd help.
+	"exportsigninglog--synopsis": "Returns records of the signing log, an append-only log of every transaction signed by the wallet, in the order the transactions were signed.  Each record describes who requested the signature: \"rpc:<user>\" for RPC users, \"grpc\" for gRPC clients, \"ticketbuyer\" for the automatic ticket buyer, and \"wallet\" for votes, revocations, and other transactions signed by the wallet on its own.",
+	"exportsigninglog-start":     "The sequence number of the first record to return",
+	"exportsigninglog-count":     "The maximum number of records to return",
+
+	// ExportSeedBackupCmd help.
+	"exportseedbackup--synopsis":  "Returns a seed backup file, encrypted with the passphrase, holding the wallet seed, the accounts and the number of addresses used by each, and the imported keys and scripts, which restores the wallet fully with the --restoreseedbackup option.  The wallet must be unlocked.",
+	"exportseedbackup-passphrase": "The passphrase to encrypt the seed backup file with",
+	"exportseedbackup--result0":   "The base64 encoded seed backup file",
+
+	// SigningRecordResult help.
+	"signingrecordresult-sequence": "The sequence number of the record",
+	"signingrecordresult-time":     "The Unix time the transaction was signed",
+	"signingrecordresult-origin":   "Who requested the signature",
+	"signingrecordresult-txhash":   "The hash of the signed transaction",
+	"signingrecordresult-inputs":   "The inputs of the signed transaction",
+	"signingrecordresult-outputs":  "The outputs of the signed transaction",
+	"signingrecordresult-hex":      "The serialized signed transaction",
+
+	// SigningRecordInput help.
+	"signingrecordinput-txid":   "The hash of the transaction of the spent output",
+	"signingrecordinput-vout":   "The output index of the spent output",
+	"signingrecordinput-tree":   "The tree of the transaction of the spent output",
+	"signingrecordinput-amount": "The value of the spent output committed to by the input",
+
+	// SigningRecordOutput help.
+	"signingrecordoutput-amount":     "The value of the output",
+	"signingrecordoutput-scripttype": "The type of the output script",
+	"signingrecordoutput-addresses":  "The addresses paid by the output script",
+
+	// GetBalanceHistoryCmd help.
+	"getbalancehistory--synopsis":   "Returns the changes of the wallet balance caused by each block, or by each day, in a range of heights, along with the resulting balance, for charting the balance over time.  Only blocks and days which changed the balance are returned.  The balance includes immature coinbase and stake outputs but excludes unmined transactions and outputs locked in tickets.",
+	"getbalancehistory-startheight": "The height of the first block to include",
+	"getbalancehistory-endheight":   "The height of the last block to include (default: the height the wallet is synced to)",
+	"getbalancehistory-interval":    `The interval to group balance changes by, either "block" or "day" (UTC)`,
+
+	// GetBalanceHistoryResult help.
+	"getbalancehistoryresult-height":  "The height of the last block of the interval",
+	"getbalancehistoryresult-time":    "The Unix time of the last block of the interval",
+	"getbalancehistoryresult-delta":   "The change of the balance during the interval",
+	"getbalancehistoryresult-balance": "The balance after the interval",
+
+	// GetBlockVoteBitsCmd help.
+	"getblockvotebits--synopsis":   "Returns the vote bits of each block recorded by the wallet in a range of heights, and whether they approve the regular transaction tree of the parent block.  Regular transactions of a disapproved block must be mined again.",
+	"getblockvotebits-startheight": "The height of the first block to return",
+	"getblockvotebits-endheight":   "The height of the last block to return (default: the height the wallet is synced to)",
+
+	// GetBlockVoteBitsResult help.
+	"getblockvotebitsresult-height":         "The height of the block",
+	"getblockvotebitsresult-hash":           "The hash of the block",
+	"getblockvotebitsresult-time":           "The Unix time of the block",
+	"getblockvotebitsresult-votebits":       "The vote bits of the block",
+	"getblockvotebitsresult-parentapproved": "Whether the vote bits approve the regular transaction tree of the parent block",
+
+	// GetImportedBalanceCmd help.
+	"getimportedbalance--synopsis":   "Returns the balance of the wallet split by whether it can be recovered by restoring the wallet from its seed.  Outputs paying imported keys and scripts, or scripts whose addresses are not managed by the wallet, can only be recovered from a backup of the wallet or of the imported keys and scripts.",
+	"getimportedbalance-minconf":     "Minimum number of block confirmations required before an unspent output's value is included in the balance",
+	"getimportedbalance-balancetype": "The type of balance to return, 'spendable', 'locked' (value locked in tickets), 'all' (all unspent outputs), or 'fullscan' (spendable balance verified by a scan of all unspent outputs)",
+
+	// GetImportedBalanceResult help.
+	"getimportedbalanceresult-total":          "The balance of the wallet",
+	"getimportedbalanceresult-seed":           "The balance recoverable by restoring the wallet from its seed",
+	"getimportedbalanceresult-imported":       "The balance of imported keys and scripts, which can not be recovered from the seed",
+	"getimportedbalanceresult-backuprequired": "Whether any of the balance can not be recovered from the seed, so the imported keys and scripts must be backed up",
+
+	// GetCreditOriginCmd help.
+	"getcreditorigin--synopsis": `Returns the origin tag of a transaction output, one of "normal", "mixed", "swap", or "poolreward".  Outputs which were never tagged are of the normal origin.`,
+	"getcreditorigin-txid":      "The hash of the transaction",
+	"getcreditorigin-vout":      "The index of the output",
+	"getcreditorigin--result0":  "The origin of the output",
+
+	// SetCreditOriginCmd help.
+	"setcreditorigin--synopsis": "Tags a transaction output with the origin of its funds.  The outputs of transactions spending only credits of a single origin inherit its tag, and with the separateorigins option credits of different origins are never spent in the same transaction, so mixed coins are not merged with unmixed coins.",
+	"setcreditorigin-txid":      "The hash of the transaction",
+	"setcreditorigin-vout":      "The index of the output",
+	"setcreditorigin-origin":    `The origin of the output, one of "normal", "mixed", "swap", or "poolreward"`,
+
+	// SetBirthdayCmd help.
+	"setbirthday--synopsis": "Moves the wallet birthday earlier and rescans the blocks which were skipped because of the previous birthday for transactions involving the wallet's addresses.  Outputs found by the rescan are then watched through the blocks the wallet is already synced through so their spends are recorded, but the rest of the wallet's history is unchanged.  The progress is sent to websocket clients subscribed with notifyrescanprogress as rescanwalletprogress notifications, the rescan may be stopped by cancelrescan, and the birthday is only changed once the rescan completes.",
+	"setbirthday-birthday":  "The new birthday as a Unix time, which must be before the current birthday",
+
+	// DecodeAddressCmd help.
+	"decodeaddress--synopsis": "Describes the output script paying to an address, including its class, the keys and hashes involved, and whether the wallet controls it.",
+	"decodeaddress-address":   "The address or hex-encoded public key to describe",
+
+	// DescribeScriptCmd help.
+	"describescript--synopsis": "Decodes an output script and describes its class, stake subclass, required signatures, the keys and hashes involved, and whether the wallet controls it.",
+	"describescript-script":    "The hex-encoded output script",
+	"describescript-version":   "The script version",
+
+	// DescribeScriptResult help.
+	"describescriptresult-script":        "The hex-encoded output script",
+	"describescriptresult-class":         "The class of the script",
+	"describescriptresult-stakesubclass": "The class of the script tagged by the stake opcode, omitted when the script is not a stake output",
+	"describescriptresult-reqsigs":       "The number of signatures required to spend outputs paying to the script",
+	"describescriptresult-addresses":     "The addresses of the keys and script hashes involved in the script",
+	"describescriptresult-hashes":        "The hex-encoded public key or hash of each address",
+	"describescriptresult-walletkeys":    "The number of involved addresses managed by the wallet, counting the addresses of the redeem script for pay-to-script-hash scripts whose redeem script is known",
+	"describescriptresult-controlled":    "Whether the wallet holds enough private keys to spend outputs paying to the script",
+	"describescriptresult-redeemscript":  "The hex-encoded redeem script of a pay-to-script-hash script, omitted when it is not known to the wallet",
+
+	// DecodeRawTransactionCmd help.
+	"decoderawtransaction--synopsis": "Decodes a serialized transaction, identifying the structures of tickets, votes, and revocations, and annotates which inputs and outputs belong to the wallet.",
+	"decoderawtransaction-hextx":     "The serialized transaction hex-encoded",
+
+	// DecodeRawTransactionResult help.
+	"decoderawtransactionresult-txid":       "The hash of the transaction",
+	"decoderawtransactionresult-type":       `The stake type of the transaction, one of "regular", "ticket", "vote", or "revocation"`,
+	"decoderawtransactionresult-version":    "The transaction version",
+	"decoderawtransactionresult-locktime":   "The transaction lock time",
+	"decoderawtransactionresult-expiry":     "The height after which the transaction may not be mined, or zero when it does not expire",
+	"decoderawtransactionresult-vin":        "The inputs of the transaction",
+	"decoderawtransactionresult-vout":       "The outputs of the transaction",
+	"decoderawtransactionresult-ticket":     "The price and commitments of a ticket, omitted for other transactions",
+	"decoderawtransactionresult-vote":       "The ticket spent by a vote and the block and vote bits it votes with, omitted for other transactions",
+	"decoderawtransactionresult-revocation": "The ticket spent by a revocation and the amount refunded, omitted for other transactions",
+
+	// DecodeRawTransactionInput help.
+	"decoderawtransactioninput-txid":      "The hash of the transaction of the spent output",
+	"decoderawtransactioninput-vout":      "The output index of the spent output",
+	"decoderawtransactioninput-tree":      "The tree of the transaction of the spent output",
+	"decoderawtransactioninput-sequence":  "The input sequence number",
+	"decoderawtransactioninput-amountin":  "The value of the spent output committed to by the input",
+	"decoderawtransactioninput-stakebase": "Whether the input is the stakebase of a vote, which does not spend an output",
+	"decoderawtransactioninput-mine":      "Whether the input spends an output of the wallet",
+
+	// DecodeRawTransactionOutput help.
+	"decoderawtransactionoutput-n":            "The index of the output",
+	"decoderawtransactionoutput-value":        "The value of the output",
+	"decoderawtransactionoutput-version":      "The script version of the output",
+	"decoderawtransactionoutput-scriptpubkey": "The output script and whether the wallet controls it, as described by describescript",
+
+	// TicketDetailsResult help.
+	"ticketdetailsresult-price":       "The price of the ticket",
+	"ticketdetailsresult-commitments": "The commitment outputs of the ticket",
+
+	// TicketCommitmentResult help.
+	"ticketcommitmentresult-address":            "The address the commitment is returned to",
+	"ticketcommitmentresult-amount":             "The amount committed",
+	"ticketcommitmentresult-share":              "The percentage of all committed amounts",
+	"ticketcommitmentresult-owned":              "Whether the commitment address belongs to the wallet",
+	"ticketcommitmentresult-votefeelimit":       "The maximum fee a vote may deduct from the commitment, omitted when the fee is not allowed",
+	"ticketcommitmentresult-revocationfeelimit": "The maximum fee a revocation may deduct from the commitment, omitted when the fee is not allowed",
+	"ticketcommitmentresult-changeaddress":      "The address of the change output paired with the commitment",
+	"ticketcommitmentresult-changeamount":       "The amount of the change output paired with the commitment",
+
+	// VoteDetailsResult help.
+	"votedetailsresult-ticket":      "The hash of the ticket spent by the vote",
+	"votedetailsresult-blockhash":   "The hash of the block voted on",
+	"votedetailsresult-blockheight": "The height of the block voted on",
+	"votedetailsresult-votebits":    "The vote bits of the vote",
+
+	// RevocationDetailsResult help.
+	"revocationdetailsresult-ticket":   "The hash of the ticket spent by the revocation",
+	"revocationdetailsresult-refunded": "The amount refunded to the commitment addresses of the ticket",
+
+	// CreateMultisigWalletCmd help.
+	"createmultisigwallet--synopsis": "Sets up a multisig wallet shared by cosigners.  The redeem scripts are created from the keys of the cosigners, sorted so every cosigner creates the same scripts, imported, and their addresses are watched.\n" +
+		"When any key is an account extended public key, such as returned by getmasterpubkey, a script is created for each child index of the external branch below count, and otherwise the single script of the public keys is created.\n" +
+		"The result is a recovery bundle each cosigner should store.  Passing the same nrequired, keys, and count again recreates the scripts, and the wallet should then be rescanned from the bundle height.",
+	"createmultisigwallet-nrequired": "The number of signatures required to redeem outputs paid to the scripts",
+	"createmultisigwallet-keys":      "The hex-encoded public keys or account extended public keys of the cosigners",
+	"createmultisigwallet-count":     "The number of scripts to create when any key is an extended public key",
+
+	// CreateMultisigWalletResult help.
+	"createmultisigwalletresult-nrequired": "The number of signatures required to redeem outputs paid to the scripts",
+	"createmultisigwalletresult-keys":      "The keys of the cosigners",
+	"createmultisigwalletresult-count":     "The number of created scripts",
+	"createmultisigwalletresult-branch":    "The branch of the extended public keys the child keys are derived from",
+	"createmultisigwalletresult-network":   "The network the scripts were created for",
+	"createmultisigwalletresult-height":    "The height of the best block when the scripts were imported, which a restored wallet should rescan from",
+	"createmultisigwalletresult-scripts":   "The created scripts",
+
+	// MultisigWalletScript help.
+	"multisigwalletscript-index":        "The child index of the extended public keys used by the script",
+	"multisigwalletscript-address":      "The pay-to-script-hash address of the script",
+	"multisigwalletscript-redeemscript": "The hex-encoded redeem script",
+	// ListPendingSendsCmd help.
+	"listpendingsends--synopsis": "Returns the sends waiting for approval, in the order they were requested.  Sends paying more than the approval threshold are created unsigned and queued until they are approved with approvesend, rejected with rejectsend, or expire.",
+
+	// PendingSendResult help.
+	"pendingsendresult-id":      "The id of the pending send, which is the hash its transaction has once signed",
+	"pendingsendresult-origin":  "The signing origin which requested the send",
+	"pendingsendresult-amount":  "The total amount paid by the send, excluding change",
+	"pendingsendresult-fee":     "The estimated fee of the signed transaction",
+	"pendingsendresult-inputs":  "The outputs spent by the send, in the order of the transaction inputs",
+	"pendingsendresult-outputs": "The payments of the send, excluding change",
+	"pendingsendresult-created": "The Unix time the send was requested",
+	"pendingsendresult-expires": "The Unix time the send expires unless it is approved",
+	"pendingsendresult-hex":     "The hex-encoded unsigned transaction",
+
+	// PendingSendInput help.
+	"pendingsendinput-txid":    "The hash of the transaction of the spent output",
+	"pendingsendinput-vout":    "The output index of the spent output",
+	"pendingsendinput-tree":    "The tree of the transaction of the spent output",
+	"pendingsendinput-amount":  "The amount of the spent output",
+	"pendingsendinput-address": "The address paid by the spent output",
+	"pendingsendinput-account": "The account of the address",
+	"pendingsendinput-path":    "The derivation path of the key of the address, omitted for imported addresses and scripts",
+
+	// PendingSendOutput help.
+	"pendingsendoutput-address": "The paid address",
+	"pendingsendoutput-amount":  "The amount paid to the address",
+
+	// ApproveSendCmd help.
+	"approvesend--synopsis": "Approves a send waiting for approval, signing and broadcasting its transaction.\n" +
+		"Without a signature, the send must be approved by a different RPC user than the one which requested it.  With a signature, the send is approved by the holder of the key of the configured approval address, which signs the message \"approvesend <id>\" with signmessage.\n" +
+		"The wallet must be unlocked.  When the transaction can not be signed, the send remains pending.",
+	"approvesend-id":        "The id of the pending send",
+	"approvesend-signature": "The base64-encoded signmessage signature of the approval address approving the send",
+	"approvesend--result0":  "The transaction hash of the sent transaction",
+
+	// RejectSendCmd help.
+	"rejectsend--synopsis": "Removes a send waiting for approval without signing it, releasing the outputs it would spend.",
+	"rejectsend-id":        "The id of the pending send",
+
+	// RegisterVSPCmd help.
+	"registervsp--synopsis": "Registers a public key address of the wallet with the voting service provider configured by --vspurl and --vspapitoken, and imports the multisig redeem script shared with the provider, which tickets delegated to it vote with.\n" +
+		"The script is checked to include a key of the wallet and to hash to the provider's ticket address before it is imported.  When an address was already registered, such as before restoring the wallet from seed, only the script is imported.",
+	"registervsp-rescanfrom": "The height to rescan from for tickets already purchased with the script (default: the best block)",
+
+	// RegisterVSPResult help.
+	"registervspresult-registered":    "Whether a public key address was registered by this request",
+	"registervspresult-ticketaddress": "The P2SH address of the multisig script tickets vote with",
+	"registervspresult-script":        "The hex-encoded multisig redeem script",
+	"registervspresult-pooladdress":   "The address tickets must commit the pool fee to",
+	"registervspresult-poolfees":      "The percentage of each ticket's price and fee which must be committed to the pool address",
+
+	// PurchaseVSPTicketsCmd help.
+	"purchasevsptickets--synopsis": "Purchases tickets voted by the configured voting service provider at the current ticket price.  Each ticket votes with the provider's ticket address and commits the pool fee to its pool address, along with the wallet's share of the ticket.  A split transaction first creates the exact outputs spent by each ticket.\n" +
+		"The provider's script must have been imported with registervsp, and the wallet must be unlocked.",
+	"purchasevsptickets-count":      "The number of tickets to purchase",
+	"purchasevsptickets-minbalance": "The minimum balance in coins to leave in the wallet",
+	"purchasevsptickets-minconf":    "The minimum number of confirmations of spent outputs (default: --minconf)",
+	"purchasevsptickets--result0":   "The hashes of the purchased tickets",
+
+	// ListVSPTicketsCmd help.
+	"listvsptickets--synopsis": "Returns the wallet's tickets voted by the configured voting service provider, sorted by hash, with their status and whether each commits the pool fee the provider requires to vote it.",
+
+	// ListVSPTicketsResult help.
+	"listvspticketsresult-ticket":  "The hash of the ticket",
+	"listvspticketsresult-status":  "The lifecycle status of the ticket: unmined, immature, live, voted, missed, expired, or revoked",
+	"listvspticketsresult-price":   "The price of the ticket",
+	"listvspticketsresult-poolfee": "The amount the ticket commits to the pool address",
+	"listvspticketsresult-feepaid": "Whether the committed pool fee satisfies the provider's fee percentage",
+
+	// EstimateStakeDiffCmd help.
+	"estimatestakediff--synopsis": "Forecasts the ticket price of the next stake difficulty window from the live ticket pool size and the tickets purchased in the recent windows, projecting the tickets purchased in the remainder of the current window.\n" +
+		"The automatic ticket buyer waits for the next window late in a window when the expected price is lower than the current price.",
+
+	// EstimateStakeDiffResult help.
+	"estimatestakediffresult-height":    "The height of the block the forecast is made at",
+	"estimatestakediffresult-remaining": "The number of blocks remaining in the current window",
+	"estimatestakediffresult-current":   "The ticket price of the current window",
+	"estimatestakediffresult-min":       "The next ticket price if no more tickets are purchased in the current window",
+	"estimatestakediffresult-expected":  "The next ticket price if tickets continue to be purchased at the rate of the current window",
+	"estimatestakediffresult-max":       "The next ticket price if every remaining block of the current window purchases the maximum number of tickets",
+
+	// GetStakeInfoCmd help.
+	"getstakeinfo--synopsis": "Returns the number of the wallet's tickets in each stage of their lifecycle and the subsidy earned by their votes.",
+
+	// GetStakeInfoResult help.
+	"getstakeinforesult-blockheight":   "The height of the block the wallet is synced to",
+	"getstakeinforesult-difficulty":    "The current ticket price",
+	"getstakeinforesult-ownmempooltix": "The number of the wallet's tickets which have not been mined",
+	"getstakeinforesult-immature":      "The number of the wallet's tickets which have not reached ticket maturity",
+	"getstakeinforesult-live":          "The number of the wallet's live tickets",
+	"getstakeinforesult-voted":         "The number of the wallet's tickets which voted",
+	"getstakeinforesult-missed":        "The number of the wallet's tickets which missed their vote",
+	"getstakeinforesult-expired":       "The number of the wallet's tickets which expired without being called to vote",
+	"getstakeinforesult-revoked":       "The number of the wallet's tickets which were revoked",
+	"getstakeinforesult-totalsubsidy":  "The total subsidy earned by the wallet's votes",
+
+	// GetTicketPoolShareCmd help.
+	"getticketpoolshare--synopsis": "Returns the wallet's share of the live ticket pool and the statistical expectation of its live tickets being called to vote, assuming the pool size and the wallet's live tickets do not change.",
+	"getticketpoolshare-days":      "The number of days for which the probability of a vote is reported",
+
+	// GetTicketPoolShareResult help.
+	"getticketpoolshareresult-height":            "The height of the best block",
+	"getticketpoolshareresult-livetickets":       "The number of live tickets of the wallet",
+	"getticketpoolshareresult-poolsize":          "The number of live tickets of the network",
+	"getticketpoolshareresult-share":             "The fraction of the live ticket pool owned by the wallet",
+	"getticketpoolshareresult-voteprobability":   "The probability that at least one ticket of the wallet votes in each block",
+	"getticketpoolshareresult-expectedblocks":    "The expected number of blocks until the next vote, or zero without live tickets",
+	"getticketpoolshareresult-expectedtime":      "The expected number of seconds until the next vote, or zero without live tickets",
+	"getticketpoolshareresult-days":              "The number of days the vote probability is reported for",
+	"getticketpoolshareresult-withinprobability": "The probability that at least one ticket of the wallet votes within the days",
+
+	// GetTicketBuyerLogCmd help.
+	"getticketbuyerlog--synopsis": "Returns the most recent decisions of the automatic ticket buyer, oldest first.  A decision is recorded in the wallet database for every block the ticket buyer runs at, describing its inputs and why tickets were or were not purchased.",
+	"getticketbuyerlog-count":     "The number of most recent decisions to return",
+
+	// GetTicketBuyerLogResult help.
+	"getticketbuyerlogresult-id":          "The sequence number of the decision",
+	"getticketbuyerlogresult-height":      "The height of the block the ticket buyer ran at",
+	"getticketbuyerlogresult-time":        "The Unix time of the decision",
+	"getticketbuyerlogresult-ticketprice": "The ticket price",
+	"getticketbuyerlogresult-spendable":   "The spendable balance observed",
+	"getticketbuyerlogresult-mempoolfee":  "The median fee per kB of the tickets in the mempool",
+	"getticketbuyerlogresult-feerate":     "The fee per kB paid by the purchased tickets, or zero when none were purchased",
+	"getticketbuyerlogresult-attempted":   "The number of attempted ticket purchases",
+	"getticketbuyerlogresult-purchased":   "The number of purchased tickets",
+	"getticketbuyerlogresult-batch":       "The ticket batch of the purchased tickets, omitted when none were purchased",
+	"getticketbuyerlogresult-reason":      "Why the ticket buyer purchased or skipped tickets",
+
+	// ListAddressUsageCmd help.
+	"listaddressusage--synopsis": "Returns the usage of every address of the wallet, built from an index of the recorded transactions, so reused addresses may be identified and retired.  Addresses are ordered by account, external addresses before internal ones, and then by first use, with unused addresses last.",
+	"listaddressusage-account":   `The account of the addresses, or "*" for all accounts`,
+
+	// ListAddressUsageResult help.
+	"listaddressusageresult-address":       "The address",
+	"listaddressusageresult-account":       "The account of the address",
+	"listaddressusageresult-branch":        `The branch of the address: "external", "internal", or "imported"`,
+	"listaddressusageresult-firstused":     "The Unix time of the first transaction paying to the address, or zero when it is unused",
+	"listaddressusageresult-totalreceived": "The total amount received by the address",
+	"listaddressusageresult-balance":       "The amount of the unspent outputs paying to the address, regardless of their confirmations",
+	"listaddressusageresult-txcount":       "The number of transactions paying to or spending from the address",
+	"listaddressusageresult-reused":        "Whether the address was paid by more than one transaction",
+
+	// CreatePaymentURICmd help.
+	"createpaymenturi--synopsis": "Derives a new address of an account and returns a decred: payment URI requesting payment to it, which may be shared as an invoice.",
+	"createpaymenturi-account":   "The account of the new address",
+	"createpaymenturi-amount":    "The requested amount, or none for any amount",
+	"createpaymenturi-label":     "A label for the recipient",
+	"createpaymenturi-message":   "A message describing the payment",
+	"createpaymenturi-expiry":    "The number of seconds the payment request is valid for, or 0 if it never expires",
+
+	// CreatePaymentURIResult help.
+	"createpaymenturiresult-uri":     "The payment URI",
+	"createpaymenturiresult-address": "The new address the URI requests payment to",
+	"createpaymenturiresult-expires": "The Unix time the payment request expires at, or 0 if it never expires",
+
+	// DecodePaymentURICmd help.
+	"decodepaymenturi--synopsis": "Validates and decodes a decred: payment URI, so a payment request may be checked before it is paid.",
+	"decodepaymenturi-uri":       "The payment URI",
+
+	// DecodePaymentURIResult help.
+	"decodepaymenturiresult-isvalid": "Whether the URI is a valid payment request for the wallet's network",
+	"decodepaymenturiresult-error":   "Why the URI is not valid",
+	"decodepaymenturiresult-address": "The address the URI requests payment to",
+	"decodepaymenturiresult-amount":  "The requested amount, omitted when any amount may be paid",
+	"decodepaymenturiresult-label":   "The label of the recipient",
+	"decodepaymenturiresult-message": "The message describing the payment",
+	"decodepaymenturiresult-expires": "The Unix time the payment request expires at, omitted when it never expires",
+	"decodepaymenturiresult-expired": "Whether the payment request has expired",
+	"decodepaymenturiresult-ismine":  "Whether the address belongs to the wallet",
+
+	// ExportCapitalGainsCmd help.
+	"exportcapitalgains--synopsis": `Returns the gains realized by the wallet during a calendar year in UTC as CSV, for tax reporting.  The wallet's mined transactions are replayed: transactions increasing the wallet's coins acquire a lot, and transactions decreasing them, including by the fees they pay, dispose of coins which are matched with lots by the lot method.  Coins are valued with the fiat rates recorded when the transactions were first seen (see the fiatratesource option), and the CSV columns are "disposed", "disposaltxid", "acquired", "acquisitiontxid", "amount", "proceeds", "costbasis", "gain" and "currency".  Unknown values are empty.`,
+	"exportcapitalgains-year":      "The calendar year of the report",
+	"exportcapitalgains-method":    `The lot matching method: "fifo" or "lifo" to match disposals with the oldest or newest lots held by the wallet, or "specific" to identify the lots by the coins actually spent`,
+
+	// ExportCapitalGainsResult help.
+	"exportcapitalgainsresult-year":          "The calendar year of the report",
+	"exportcapitalgainsresult-method":        "The lot matching method",
+	"exportcapitalgainsresult-currency":      "The fiat currency of the values, or empty if no rates were recorded",
+	"exportcapitalgainsresult-totalproceeds": "The total proceeds of the gains whose proceeds and cost basis are known",
+	"exportcapitalgainsresult-totalcost":     "The total cost basis of the gains whose proceeds and cost basis are known",
+	"exportcapitalgainsresult-totalgain":     "The total realized gain, negative for a loss",
+	"exportcapitalgainsresult-unknowncount":  "The number of gains excluded from the totals because no fiat rate was recorded for their transactions or the acquisition of the coins is not recorded",
+	"exportcapitalgainsresult-csv":           "The realized gains as CSV with a header row",
+
+	// ExportAccountHistoryCmd help.
+	"exportaccounthistory--synopsis": `Exports the mined transaction history of an account, oldest first, with the account's running balance after each transaction, for reconciliation.  Each transaction is a CSV row or a JSON object line with the "height", "blockhash", "time", "txid", "type", "received" (outputs paying the account), "sent" (outputs of the account spent), "amount" (received minus sent) and "balance".  The CSV header row is only included when no cursor is passed.  Unmined transactions are not exported, as their position in the history is not yet known.  The history is exported in pages: passing the nextcursor of a reply resumes the history after it, and also picks up transactions mined since.`,
+	"exportaccounthistory-account":   "The account to export the history of",
+	"exportaccounthistory-format":    `The export format: "csv" or "jsonl" for JSON lines`,
+	"exportaccounthistory-cursor":    "The nextcursor of a previous reply to resume the history after, or none to start with the first transaction",
+	"exportaccounthistory-count":     "The maximum number of transactions to export",
+
+	// ExportAccountHistoryResult help.
+	"exportaccounthistoryresult-account":    "The account of the history",
+	"exportaccounthistoryresult-format":     "The export format",
+	"exportaccounthistoryresult-count":      "The number of exported transactions",
+	"exportaccounthistoryresult-complete":   "Whether the history was exported up to the latest mined transaction",
+	"exportaccounthistoryresult-nextcursor": "The cursor to pass to resume the history after the last exported transaction, omitted when nothing was exported yet",
+	"exportaccounthistoryresult-data":       "The exported transactions",
 }
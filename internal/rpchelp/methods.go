@@ -17,7 +17,10 @@
 
 package rpchelp
 
-import "github.com/decred/dcrd/dcrjson"
+import (
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrwallet/walletjson"
+)
 
 // Common return types.
 var (
@@ -40,7 +43,7 @@ var Methods = []struct {
 	{"getaccount", returnsString},
 	{"getaccountaddress", returnsString},
 	{"getaddressesbyaccount", returnsStringArray},
-	{"getbalance", append(returnsNumber, returnsNumber[0])},
+	{"getbalance", []interface{}{(*float64)(nil), (*map[string]float64)(nil)}},
 	{"getbestblockhash", returnsString},
 	{"getblockcount", returnsNumber},
 	{"getinfo", []interface{}{(*dcrjson.InfoWalletResult)(nil)}},
@@ -77,6 +80,7 @@ var Methods = []struct {
 	{"signmessage", returnsString},
 	{"signrawtransaction", []interface{}{(*dcrjson.SignRawTransactionResult)(nil)}},
 	{"signrawtransactions", []interface{}{(*dcrjson.SignRawTransactionsResult)(nil)}},
+	{"ticketsforaddress", []interface{}{(*dcrjson.TicketsForAddressResult)(nil)}},
 	{"validateaddress", []interface{}{(*dcrjson.ValidateAddressWalletResult)(nil)}},
 	{"verifymessage", returnsBool},
 	{"walletlock", nil},
@@ -94,6 +98,51 @@ var Methods = []struct {
 	{"sendtossrtx", returnsString},
 	{"sendtosstx", returnsString},
 	{"sendtossgen", returnsString},
+	{"cancelrescan", nil},
+	{"rescanwallet", []interface{}{(*walletjson.RescanWalletResult)(nil)}},
+	{"getlockinfo", []interface{}{(*walletjson.GetLockInfoResult)(nil)}},
+	{"setunlocktimeout", nil},
+	{"getapiinfo", []interface{}{(*walletjson.GetAPIInfoResult)(nil)}},
+	{"listaddresstickets", []interface{}{(*[]walletjson.ListAddressTicketsResult)(nil)}},
+	{"getfeesreport", []interface{}{(*walletjson.GetFeesReportResult)(nil)}},
+	{"startjob", []interface{}{(*int64)(nil)}},
+	{"getjobstatus", []interface{}{(*walletjson.JobStatusResult)(nil)}},
+	{"listjobs", []interface{}{(*[]walletjson.JobStatusResult)(nil)}},
+	{"getbackendstate", []interface{}{(*walletjson.GetBackendStateResult)(nil)}},
+	{"debuglevel", append(returnsString, returnsString[0])},
+	{"loadwallet", nil},
+	{"unloadwallet", nil},
+	{"exportsigninglog", []interface{}{(*[]walletjson.SigningRecordResult)(nil)}},
+	{"getblockvotebits", []interface{}{(*[]walletjson.GetBlockVoteBitsResult)(nil)}},
+	{"getbalancehistory", []interface{}{(*[]walletjson.GetBalanceHistoryResult)(nil)}},
+	{"getimportedbalance", []interface{}{(*walletjson.GetImportedBalanceResult)(nil)}},
+	{"getcreditorigin", returnsString},
+	{"setcreditorigin", nil},
+	{"setbirthday", []interface{}{(*walletjson.RescanWalletResult)(nil)}},
+	{"decodeaddress", []interface{}{(*walletjson.DescribeScriptResult)(nil)}},
+	{"describescript", []interface{}{(*walletjson.DescribeScriptResult)(nil)}},
+	{"decoderawtransaction", []interface{}{(*walletjson.DecodeRawTransactionResult)(nil)}},
+	{"createmultisigwallet", []interface{}{(*walletjson.CreateMultisigWalletResult)(nil)}},
+	{"listpendingsends", []interface{}{(*[]walletjson.PendingSendResult)(nil)}},
+	{"approvesend", returnsString},
+	{"rejectsend", nil},
+	{"registervsp", []interface{}{(*walletjson.RegisterVSPResult)(nil)}},
+	{"purchasevsptickets", returnsStringArray},
+	{"listvsptickets", []interface{}{(*[]walletjson.ListVSPTicketsResult)(nil)}},
+	{"estimatestakediff", []interface{}{(*walletjson.EstimateStakeDiffResult)(nil)}},
+	{"getticketpoolshare", []interface{}{(*walletjson.GetTicketPoolShareResult)(nil)}},
+	{"getstakeinfo", []interface{}{(*walletjson.GetStakeInfoResult)(nil)}},
+	{"getticketbuyerlog", []interface{}{(*[]walletjson.GetTicketBuyerLogResult)(nil)}},
+	{"listaddressusage", []interface{}{(*[]walletjson.ListAddressUsageResult)(nil)}},
+	{"createpaymenturi", []interface{}{(*walletjson.CreatePaymentURIResult)(nil)}},
+	{"decodepaymenturi", []interface{}{(*walletjson.DecodePaymentURIResult)(nil)}},
+	{"exportcapitalgains", []interface{}{(*walletjson.ExportCapitalGainsResult)(nil)}},
+	{"exportaccounthistory", []interface{}{(*walletjson.ExportAccountHistoryResult)(nil)}},
+	{"importpubkey", returnsString},
+	{"exportseedbackup", returnsString},
+	{"getaddresspath", []interface{}{(*walletjson.GetAddressPathResult)(nil)}},
+	{"rotateimportedkey", []interface{}{(*walletjson.RotateImportedKeyResult)(nil)}},
+	{"listretiredaddresses", []interface{}{(*[]walletjson.ListRetiredAddressesResult)(nil)}},
 }
 
 var HelpDescs = []struct {
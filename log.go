@@ -18,13 +18,18 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/btcsuite/btclog"
 	"github.com/btcsuite/seelog"
 
 	"github.com/decred/dcrwallet/chain"
+	"github.com/decred/dcrwallet/rpc/rpcserver"
+	"github.com/decred/dcrwallet/waddrmgr"
 	"github.com/decred/dcrwallet/wallet"
 	"github.com/decred/dcrwallet/wstakemgr"
 	"github.com/decred/dcrwallet/wtxmgr"
@@ -48,17 +53,31 @@ var (
 	log        = btclog.Disabled
 	walletLog  = btclog.Disabled
 	txmgrLog   = btclog.Disabled
+	addrLog    = btclog.Disabled
 	stkmLog    = btclog.Disabled
 	chainLog   = btclog.Disabled
+	rpcsLog    = btclog.Disabled
+	tkbyLog    = btclog.Disabled
+	grpcLog    = btclog.Disabled
 )
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
 var subsystemLoggers = map[string]btclog.Logger{
 	"DCRW": log,
 	"WLLT": walletLog,
-	"TMGR": txmgrLog,
+	"WTXM": txmgrLog,
+	"ADDR": addrLog,
 	"STKM": stkmLog,
 	"CHNS": chainLog,
+	"RPCS": rpcsLog,
+	"TKBY": tkbyLog,
+	"GRPC": grpcLog,
+}
+
+// subsystemAliases maps subsystem identifiers used by earlier releases to the
+// current identifiers so existing debuglevel settings keep working.
+var subsystemAliases = map[string]string{
+	"TMGR": "WTXM",
 }
 
 // logClosure is used to provide a closure over expensive logging operations
@@ -91,21 +110,84 @@ func useLogger(subsystemID string, logger btclog.Logger) {
 	case "WLLT":
 		walletLog = logger
 		wallet.UseLogger(logger)
-	case "TMGR":
+	case "WTXM":
 		txmgrLog = logger
 		wtxmgr.UseLogger(logger)
+	case "ADDR":
+		addrLog = logger
+		waddrmgr.UseLogger(logger)
 	case "STKM":
 		stkmLog = logger
 		wstakemgr.UseLogger(logger)
 	case "CHNS":
 		chainLog = logger
 		chain.UseLogger(logger)
+	case "RPCS":
+		rpcsLog = logger
+	case "TKBY":
+		tkbyLog = logger
+		wallet.UseTicketBuyerLogger(logger)
+	case "GRPC":
+		grpcLog = logger
+		rpcserver.UseLogger(logger)
+	}
+}
+
+// Supported log output formats.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// logFormats maps each log output format to the seelog format of its entries.
+var logFormats = map[string]string{
+	logFormatText: "%%Time %%Date [%%LEV] %%Msg%%n",
+	logFormatJSON: "%%JSONEntry%%n",
+}
+
+func init() {
+	err := seelog.RegisterCustomFormatter("JSONEntry", newJSONEntryFormatter)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// jsonLogEntry is a log entry written by the json log format.
+type jsonLogEntry struct {
+	Time      string `json:"time"`
+	Level     string `json:"level"`
+	Subsystem string `json:"subsystem,omitempty"`
+	Message   string `json:"message"`
+}
+
+// newJSONEntryFormatter returns a seelog formatter which formats each message
+// as a single line JSON object, for ingestion by log aggregation systems.  The
+// subsystem prefix added by the subsystem loggers is moved into its own field.
+func newJSONEntryFormatter(param string) seelog.FormatterFunc {
+	return func(message string, level seelog.LogLevel,
+		context seelog.LogContextInterface) interface{} {
+		entry := jsonLogEntry{
+			Time:    time.Now().Format(time.RFC3339Nano),
+			Level:   level.String(),
+			Message: message,
+		}
+		// All subsystem identifiers are four characters long.
+		if strings.Index(message, ": ") == 4 {
+			entry.Subsystem = message[:4]
+			entry.Message = message[6:]
+		}
+		b, err := json.Marshal(&entry)
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
 	}
 }
 
 // initSeelogLogger initializes a new seelog logger that is used as the backend
-// for all logging subsytems.
-func initSeelogLogger(logFile string) {
+// for all logging subsytems.  Entries are written in the passed log format,
+// which must be one of the keys of logFormats.
+func initSeelogLogger(logFile, logFormat string) {
 	config := `
         <seelog type="adaptive" mininterval="2000000" maxinterval="100000000"
                 critmsgcount="500" minlevel="trace">
@@ -114,7 +196,7 @@ func initSeelogLogger(logFile string) {
                         <rollingfile type="size" filename="%s" maxsize="10485760" maxrolls="3" />
                 </outputs>
                 <formats>
-                        <format id="all" format="%%Time %%Date [%%LEV] %%Msg%%n" />
+                        <format id="all" format="` + logFormats[logFormat] + `" />
                 </formats>
         </seelog>`
 	config = fmt.Sprintf(config, logFile)
@@ -30,6 +30,7 @@ type params struct {
 	connect  string
 	dcrdPort string
 	svrPort  string
+	grpcPort string
 }
 
 // mainNetParams contains parameters specific running dcrwallet and
@@ -39,6 +40,7 @@ var mainNetParams = params{
 	connect:  "localhost:9109",
 	dcrdPort: "9109",
 	svrPort:  "9110",
+	grpcPort: "9111",
 }
 
 // testNetParams contains parameters specific running dcrwallet and
@@ -48,6 +50,7 @@ var testNetParams = params{
 	connect:  "localhost:19109",
 	dcrdPort: "19109",
 	svrPort:  "19110",
+	grpcPort: "19111",
 }
 
 // simNetParams contains parameters specific to the simulation test network
@@ -57,4 +60,5 @@ var simNetParams = params{
 	connect:  "localhost:19556",
 	dcrdPort: "19556",
 	svrPort:  "19557",
+	grpcPort: "19558",
 }
@@ -0,0 +1,216 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/chain"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletdb"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// previewSyncPollInterval is how often previewRestore checks whether the
+// restored wallet has finished syncing with the chain server.
+const previewSyncPollInterval = time.Second
+
+// errPreviewInterrupted describes an error where a restore preview was
+// interrupted before the wallet finished syncing.
+var errPreviewInterrupted = errors.New("restore preview interrupted")
+
+// previewRestore prompts for an existing wallet seed and reports the funds
+// which restoring it would recover.  The wallet is restored into a memory
+// database, which discovers the used addresses of the seed and rescans the
+// block chain after the seed's birthday exactly as a restored wallet would,
+// and the database is discarded afterwards, so no wallet file is created or
+// replaced.
+func previewRestore(cfg *config) error {
+	reader := bufio.NewReader(os.Stdin)
+	seed, birthday, err := promptConsoleExistingSeed(reader)
+	if err != nil {
+		return err
+	}
+
+	db, err := walletdb.Create("memdb")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// The private passphrase protects keys which only ever exist in
+	// memory and are never used, so a random one is chosen.
+	var privPass [32]byte
+	if _, err := rand.Read(privPass[:]); err != nil {
+		return err
+	}
+	pubPass := []byte(defaultPubPassphrase)
+	addrMgrNS, err := db.Namespace(waddrmgrNamespaceKey)
+	if err != nil {
+		return err
+	}
+	manager, err := waddrmgr.Create(addrMgrNS, seed, pubPass, privPass[:],
+		activeNet.Params, nil)
+	if err != nil {
+		return err
+	}
+	if !birthday.IsZero() {
+		err = manager.SetBirthday(birthday)
+	}
+	manager.Close()
+	if err != nil {
+		return err
+	}
+
+	txMgrNS, err := db.Namespace(wtxmgrNamespaceKey)
+	if err != nil {
+		return err
+	}
+	stMgrNS, err := db.Namespace(wstakemgrNamespaceKey)
+	if err != nil {
+		return err
+	}
+
+	// Stake mining, voting, automatic revocations, and repairs are
+	// disabled so that the preview never creates transactions.
+	w, err := wallet.Open(pubPass, activeNet.Params, db, addrMgrNS,
+		txMgrNS, stMgrNS, nil, &wallet.Config{
+			VoteBits:            cfg.VoteBits,
+			PruneTickets:        cfg.PruneTickets,
+			MaxFee:              cfg.MaxFee,
+			MaxFeePercent:       cfg.MaxFeePercent,
+			MaxTicketsPerBlock:  cfg.MaxPerBlock,
+			TicketMaxFeeRate:    cfg.TicketMaxFeeRate,
+			MaxTicketsPerWindow: cfg.MaxPerWindow,
+		})
+	if err != nil {
+		return err
+	}
+	defer w.CloseDatabases()
+
+	rpcc, err := newChainClient(cfg.RPCConnect)
+	if err != nil {
+		return err
+	}
+	err = rpcc.Start()
+	if err != nil {
+		rpcc.Stop()
+		return err
+	}
+	defer func() {
+		rpcc.Stop()
+		rpcc.WaitForShutdown()
+	}()
+
+	fmt.Println("Scanning the block chain for the seed's transactions.  " +
+		"This may take a while...")
+	w.Start(rpcc)
+	err = waitForPreviewSync(w, rpcc)
+	w.Stop()
+	w.WaitForShutdown()
+	if err != nil {
+		return err
+	}
+
+	return printPreview(w)
+}
+
+// waitForPreviewSync blocks until the wallet has synced with the chain
+// server.  An error is returned if the chain server disconnects or the
+// process is interrupted first.
+func waitForPreviewSync(w *wallet.Wallet, rpcc *chain.Client) error {
+	disconnected := make(chan struct{})
+	go func() {
+		rpcc.WaitForShutdown()
+		close(disconnected)
+	}()
+	interrupted := make(chan struct{})
+	addInterruptHandler(func() {
+		select {
+		case <-interrupted:
+		default:
+			close(interrupted)
+		}
+	})
+
+	ticker := time.NewTicker(previewSyncPollInterval)
+	defer ticker.Stop()
+	for !w.ChainSynced() {
+		select {
+		case <-ticker.C:
+		case <-disconnected:
+			return errors.New("chain server disconnected before " +
+				"the wallet synced")
+		case <-interrupted:
+			return errPreviewInterrupted
+		}
+	}
+	return nil
+}
+
+// printPreview prints the transactions and balances found by a restore
+// preview.
+func printPreview(w *wallet.Wallet) error {
+	var txs int
+	err := w.TxStore.RangeTransactions(0, -1,
+		func(details []wtxmgr.TxDetails) (bool, error) {
+			txs += len(details)
+			return false, nil
+		})
+	if err != nil {
+		return err
+	}
+	var addrs int
+	err = w.Manager.ForEachActiveAddress(func(a dcrutil.Address) error {
+		addrs++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	spendable, err := w.CalculateBalance(1, wtxmgr.BFBalanceSpendable)
+	if err != nil {
+		return err
+	}
+	locked, err := w.CalculateBalance(1, wtxmgr.BFBalanceLockedStake)
+	if err != nil {
+		return err
+	}
+	total, err := w.CalculateBalance(0, wtxmgr.BFBalanceAll)
+	if err != nil {
+		return err
+	}
+
+	syncedTo := w.Manager.SyncedTo()
+	fmt.Printf("Restoring this seed would recover (synced to block %v, "+
+		"height %d):\n", syncedTo.Hash, syncedTo.Height)
+	fmt.Printf("  Transactions:      %d\n", txs)
+	fmt.Printf("  Active addresses:  %d\n", addrs)
+	fmt.Printf("  Spendable balance: %v\n", spendable)
+	fmt.Printf("  Locked in tickets: %v\n", locked)
+	fmt.Printf("  Total balance:     %v\n", total)
+	fmt.Println("No wallet was created.  Run with --create to restore " +
+		"the wallet from this seed.")
+	return nil
+}
@@ -0,0 +1,3 @@
+#!/bin/sh
+
+protoc -I. api.proto --go_out=plugins=grpc:walletrpc
@@ -0,0 +1,364 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package rpcserver implements the wallet's gRPC API.  The service is
+// defined by rpc/api.proto and the generated walletrpc package, and is
+// registered with a gRPC server by the main package.
+package rpcserver
+
+import (
+	"bytes"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	pb "github.com/decred/dcrwallet/rpc/walletrpc"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// signingOrigin is the origin recorded in the wallet's signing log for
+// transactions signed by gRPC requests.
+const signingOrigin = "grpc"
+
+// errorCode returns the gRPC status code best describing a wallet error.
+func errorCode(err error) codes.Code {
+	switch {
+	case waddrmgr.IsError(err, waddrmgr.ErrLocked):
+		return codes.FailedPrecondition
+	case waddrmgr.IsError(err, waddrmgr.ErrAccountNotFound),
+		waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound):
+		return codes.NotFound
+	case err == wallet.ErrVotingOnly, err == wallet.ErrNotSynced,
+		err == wallet.ErrBlockchainReorganizing, err == wallet.ErrOffline:
+		return codes.FailedPrecondition
+	}
+	switch err.(type) {
+	case wallet.InsufficientFundsError, wallet.FeeLimitExceededError:
+		return codes.ResourceExhausted
+	case wallet.TxRejectedError:
+		return codes.FailedPrecondition
+	}
+	return codes.Unknown
+}
+
+// translateError converts a wallet error to a gRPC error with a matching
+// status code.
+func translateError(err error) error {
+	return grpc.Errorf(errorCode(err), "%s", err.Error())
+}
+
+// walletServer implements the WalletService.
+type walletServer struct {
+	wallet *wallet.Wallet
+}
+
+// StartWalletService creates an implementation of the WalletService and
+// registers it with the gRPC server.
+func StartWalletService(server *grpc.Server, w *wallet.Wallet) {
+	service := &walletServer{w}
+	pb.RegisterWalletServiceServer(server, service)
+}
+
+func (s *walletServer) Ping(ctx context.Context, req *pb.PingRequest) (
+	*pb.PingResponse, error) {
+	return &pb.PingResponse{}, nil
+}
+
+func (s *walletServer) Network(ctx context.Context, req *pb.NetworkRequest) (
+	*pb.NetworkResponse, error) {
+	return &pb.NetworkResponse{
+		ActiveNetwork: uint32(s.wallet.ChainParams().Net),
+	}, nil
+}
+
+func (s *walletServer) Balance(ctx context.Context, req *pb.BalanceRequest) (
+	*pb.BalanceResponse, error) {
+	total, err := s.wallet.CalculateAccountBalance(req.AccountNumber, 0)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	spendable, err := s.wallet.CalculateAccountBalance(req.AccountNumber,
+		req.RequiredConfirmations)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	resp := &pb.BalanceResponse{
+		Total:     int64(total),
+		Spendable: int64(spendable),
+	}
+
+	if req.AccountNumber == waddrmgr.DefaultAccountNum {
+		syncBlock := s.wallet.Manager.SyncedTo()
+		locked, err := s.wallet.TxStore.Balance(req.RequiredConfirmations,
+			syncBlock.Height, wtxmgr.BFBalanceLockedStake)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		resp.LockedByTickets = int64(locked)
+	}
+
+	return resp, nil
+}
+
+// marshalTransactionDetails converts the details of a wallet transaction to
+// its gRPC representation.
+func marshalTransactionDetails(details *wtxmgr.TxDetails) *pb.TransactionDetails {
+	serializedTx := details.SerializedTx
+	if serializedTx == nil {
+		var buf bytes.Buffer
+		buf.Grow(details.MsgTx.SerializeSize())
+		details.MsgTx.Serialize(&buf)
+		serializedTx = buf.Bytes()
+	}
+
+	var debitTotal, outputTotal dcrutil.Amount
+	debits := make([]*pb.TransactionDetails_Input, len(details.Debits))
+	for i, d := range details.Debits {
+		debits[i] = &pb.TransactionDetails_Input{
+			Index:          d.Index,
+			PreviousAmount: int64(d.Amount),
+		}
+		debitTotal += d.Amount
+	}
+	credits := make([]*pb.TransactionDetails_Output, len(details.Credits))
+	for i, c := range details.Credits {
+		credits[i] = &pb.TransactionDetails_Output{
+			Index:  c.Index,
+			Amount: int64(c.Amount),
+			Change: c.Change,
+		}
+	}
+	for _, txOut := range details.MsgTx.TxOut {
+		outputTotal += dcrutil.Amount(txOut.Value)
+	}
+
+	// The fee is only known when every input spends a wallet output.
+	var fee dcrutil.Amount
+	if len(details.Debits) == len(details.MsgTx.TxIn) {
+		fee = debitTotal - outputTotal
+	}
+
+	txd := &pb.TransactionDetails{
+		Hash:        details.Hash[:],
+		Transaction: serializedTx,
+		Debits:      debits,
+		Credits:     credits,
+		Fee:         int64(fee),
+		Timestamp:   details.Received.Unix(),
+		BlockHeight: details.Block.Height,
+	}
+	if details.Block.Height != -1 {
+		txd.BlockHash = details.Block.Hash[:]
+	}
+	return txd
+}
+
+func (s *walletServer) GetTransactions(req *pb.GetTransactionsRequest,
+	server pb.WalletService_GetTransactionsServer) error {
+	start, end := req.StartingBlockHeight, req.EndingBlockHeight
+	if start == 0 && end == 0 {
+		end = -1
+	}
+
+	err := s.wallet.TxStore.RangeTransactions(start, end,
+		func(details []wtxmgr.TxDetails) (bool, error) {
+			txs := make([]*pb.TransactionDetails, len(details))
+			for i := range details {
+				txs[i] = marshalTransactionDetails(&details[i])
+			}
+			resp := &pb.GetTransactionsResponse{Transactions: txs}
+			return false, server.Send(resp)
+		})
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (s *walletServer) NextAddress(ctx context.Context,
+	req *pb.NextAddressRequest) (*pb.NextAddressResponse, error) {
+	var addr dcrutil.Address
+	var err error
+	switch req.Kind {
+	case pb.NextAddressRequest_BIP0044_EXTERNAL:
+		addr, err = s.wallet.NewAddress(req.Account)
+	case pb.NextAddressRequest_BIP0044_INTERNAL:
+		addr, err = s.wallet.NewChangeAddress(req.Account)
+	default:
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"kind=%v", req.Kind)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &pb.NextAddressResponse{Address: addr.EncodeAddress()}, nil
+}
+
+func (s *walletServer) ConstructTransaction(ctx context.Context,
+	req *pb.ConstructTransactionRequest) (
+	*pb.ConstructTransactionResponse, error) {
+	if len(req.Outputs) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"no outputs to create")
+	}
+	if req.RequiredConfirmations < 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"required_confirmations must be non-negative")
+	}
+
+	params := s.wallet.ChainParams()
+	pairs := make(map[string]dcrutil.Amount, len(req.Outputs))
+	for _, output := range req.Outputs {
+		addr, err := dcrutil.DecodeAddress(output.Address, params)
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument,
+				"invalid address %v: %v", output.Address, err)
+		}
+		if output.Amount <= 0 {
+			return nil, grpc.Errorf(codes.InvalidArgument,
+				"output amount must be positive")
+		}
+		pairs[addr.EncodeAddress()] += dcrutil.Amount(output.Amount)
+	}
+
+	createdTx, err := s.wallet.CreateSimpleTx(req.SourceAccount, pairs,
+		req.RequiredConfirmations, signingOrigin)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(createdTx.MsgTx.SerializeSize())
+	err = createdTx.MsgTx.Serialize(&buf)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &pb.ConstructTransactionResponse{
+		Transaction: buf.Bytes(),
+		ChangeIndex: int32(createdTx.ChangeIndex),
+	}, nil
+}
+
+func (s *walletServer) SignTransaction(ctx context.Context,
+	req *pb.SignTransactionRequest) (*pb.SignTransactionResponse, error) {
+	var tx wire.MsgTx
+	err := tx.Deserialize(bytes.NewReader(req.SerializedTransaction))
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"bytes do not represent a valid raw transaction: %v", err)
+	}
+
+	signed, err := s.wallet.SignTransaction(&tx, signingOrigin)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(tx.SerializeSize())
+	err = tx.Serialize(&buf)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &pb.SignTransactionResponse{
+		Transaction:  buf.Bytes(),
+		SignedInputs: uint32(signed),
+	}, nil
+}
+
+func (s *walletServer) PublishTransaction(ctx context.Context,
+	req *pb.PublishTransactionRequest) (*pb.PublishTransactionResponse, error) {
+	var tx wire.MsgTx
+	err := tx.Deserialize(bytes.NewReader(req.SignedTransaction))
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"bytes do not represent a valid raw transaction: %v", err)
+	}
+
+	hash, err := s.wallet.PublishTransaction(&tx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &pb.PublishTransactionResponse{TransactionHash: hash[:]}, nil
+}
+
+func (s *walletServer) PurchaseTickets(ctx context.Context,
+	req *pb.PurchaseTicketsRequest) (*pb.PurchaseTicketsResponse, error) {
+	if req.Count == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"count must be positive")
+	}
+	if req.RequiredConfirmations < 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"required_confirmations must be non-negative")
+	}
+
+	var ticketAddr dcrutil.Address
+	if req.TicketAddress != "" {
+		addr, err := dcrutil.DecodeAddress(req.TicketAddress,
+			s.wallet.ChainParams())
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument,
+				"invalid ticket address: %v", err)
+		}
+		ticketAddr = addr
+	}
+	var rewardAddr dcrutil.Address
+	if req.RewardAddress != "" {
+		addr, err := dcrutil.DecodeAddress(req.RewardAddress,
+			s.wallet.ChainParams())
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument,
+				"invalid reward address: %v", err)
+		}
+		if _, ok := addr.(*dcrutil.AddressPubKeyHash); !ok {
+			return nil, grpc.Errorf(codes.InvalidArgument,
+				"reward address must be a pubkey hash address")
+		}
+		rewardAddr = addr
+	}
+	opts := &wallet.PurchaseTicketsOptions{
+		Account:       req.Account,
+		RewardAccount: req.RewardAccount,
+		TicketAddress: ticketAddr,
+		RewardAddress: rewardAddr,
+	}
+
+	hashes, err := s.wallet.PurchaseTicketsWithOptions(
+		dcrutil.Amount(req.MinBalance), int(req.Count),
+		req.RequiredConfirmations, opts, signingOrigin)
+	if len(hashes) == 0 && err != nil {
+		return nil, translateError(err)
+	}
+	if err != nil {
+		log.Warnf("Purchased %d of %d requested tickets: %v", len(hashes),
+			req.Count, err)
+	}
+
+	ticketHashes := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		ticketHashes[i] = hash[:]
+	}
+	return &pb.PurchaseTicketsResponse{TicketHashes: ticketHashes}, nil
+}
@@ -0,0 +1,638 @@
+// Code generated by protoc-gen-go.
+// source: api.proto
+// DO NOT EDIT!
+
+/*
+Package walletrpc is a generated protocol buffer package.
+
+It is generated from these files:
+	api.proto
+
+It has these top-level messages:
+	TransactionDetails
+	PingRequest
+	PingResponse
+	NetworkRequest
+	NetworkResponse
+	BalanceRequest
+	BalanceResponse
+	GetTransactionsRequest
+	GetTransactionsResponse
+	NextAddressRequest
+	NextAddressResponse
+	ConstructTransactionRequest
+	ConstructTransactionResponse
+	SignTransactionRequest
+	SignTransactionResponse
+	PublishTransactionRequest
+	PublishTransactionResponse
+	PurchaseTicketsRequest
+	PurchaseTicketsResponse
+*/
+package walletrpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type NextAddressRequest_Kind int32
+
+const (
+	NextAddressRequest_BIP0044_EXTERNAL NextAddressRequest_Kind = 0
+	NextAddressRequest_BIP0044_INTERNAL NextAddressRequest_Kind = 1
+)
+
+var NextAddressRequest_Kind_name = map[int32]string{
+	0: "BIP0044_EXTERNAL",
+	1: "BIP0044_INTERNAL",
+}
+var NextAddressRequest_Kind_value = map[string]int32{
+	"BIP0044_EXTERNAL": 0,
+	"BIP0044_INTERNAL": 1,
+}
+
+func (x NextAddressRequest_Kind) String() string {
+	return proto.EnumName(NextAddressRequest_Kind_name, int32(x))
+}
+
+type TransactionDetails struct {
+	Hash        []byte                       `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Transaction []byte                       `protobuf:"bytes,2,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Debits      []*TransactionDetails_Input  `protobuf:"bytes,3,rep,name=debits" json:"debits,omitempty"`
+	Credits     []*TransactionDetails_Output `protobuf:"bytes,4,rep,name=credits" json:"credits,omitempty"`
+	Fee         int64                        `protobuf:"varint,5,opt,name=fee" json:"fee,omitempty"`
+	Timestamp   int64                        `protobuf:"varint,6,opt,name=timestamp" json:"timestamp,omitempty"`
+	BlockHeight int32                        `protobuf:"varint,7,opt,name=block_height,json=blockHeight" json:"block_height,omitempty"`
+	BlockHash   []byte                       `protobuf:"bytes,8,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+}
+
+func (m *TransactionDetails) Reset()         { *m = TransactionDetails{} }
+func (m *TransactionDetails) String() string { return proto.CompactTextString(m) }
+func (*TransactionDetails) ProtoMessage()    {}
+
+func (m *TransactionDetails) GetDebits() []*TransactionDetails_Input {
+	if m != nil {
+		return m.Debits
+	}
+	return nil
+}
+
+func (m *TransactionDetails) GetCredits() []*TransactionDetails_Output {
+	if m != nil {
+		return m.Credits
+	}
+	return nil
+}
+
+type TransactionDetails_Input struct {
+	Index          uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	PreviousAmount int64  `protobuf:"varint,2,opt,name=previous_amount,json=previousAmount" json:"previous_amount,omitempty"`
+}
+
+func (m *TransactionDetails_Input) Reset()         { *m = TransactionDetails_Input{} }
+func (m *TransactionDetails_Input) String() string { return proto.CompactTextString(m) }
+func (*TransactionDetails_Input) ProtoMessage()    {}
+
+type TransactionDetails_Output struct {
+	Index  uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	Amount int64  `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
+	Change bool   `protobuf:"varint,3,opt,name=change" json:"change,omitempty"`
+}
+
+func (m *TransactionDetails_Output) Reset()         { *m = TransactionDetails_Output{} }
+func (m *TransactionDetails_Output) String() string { return proto.CompactTextString(m) }
+func (*TransactionDetails_Output) ProtoMessage()    {}
+
+type PingRequest struct {
+}
+
+func (m *PingRequest) Reset()         { *m = PingRequest{} }
+func (m *PingRequest) String() string { return proto.CompactTextString(m) }
+func (*PingRequest) ProtoMessage()    {}
+
+type PingResponse struct {
+}
+
+func (m *PingResponse) Reset()         { *m = PingResponse{} }
+func (m *PingResponse) String() string { return proto.CompactTextString(m) }
+func (*PingResponse) ProtoMessage()    {}
+
+type NetworkRequest struct {
+}
+
+func (m *NetworkRequest) Reset()         { *m = NetworkRequest{} }
+func (m *NetworkRequest) String() string { return proto.CompactTextString(m) }
+func (*NetworkRequest) ProtoMessage()    {}
+
+type NetworkResponse struct {
+	ActiveNetwork uint32 `protobuf:"varint,1,opt,name=active_network,json=activeNetwork" json:"active_network,omitempty"`
+}
+
+func (m *NetworkResponse) Reset()         { *m = NetworkResponse{} }
+func (m *NetworkResponse) String() string { return proto.CompactTextString(m) }
+func (*NetworkResponse) ProtoMessage()    {}
+
+type BalanceRequest struct {
+	AccountNumber         uint32 `protobuf:"varint,1,opt,name=account_number,json=accountNumber" json:"account_number,omitempty"`
+	RequiredConfirmations int32  `protobuf:"varint,2,opt,name=required_confirmations,json=requiredConfirmations" json:"required_confirmations,omitempty"`
+}
+
+func (m *BalanceRequest) Reset()         { *m = BalanceRequest{} }
+func (m *BalanceRequest) String() string { return proto.CompactTextString(m) }
+func (*BalanceRequest) ProtoMessage()    {}
+
+// BalanceResponse reports the balance of an account.  Tickets are always
+// purchased from the default account, so locked_by_tickets is only reported
+// for account 0.
+type BalanceResponse struct {
+	Total           int64 `protobuf:"varint,1,opt,name=total" json:"total,omitempty"`
+	Spendable       int64 `protobuf:"varint,2,opt,name=spendable" json:"spendable,omitempty"`
+	LockedByTickets int64 `protobuf:"varint,3,opt,name=locked_by_tickets,json=lockedByTickets" json:"locked_by_tickets,omitempty"`
+}
+
+func (m *BalanceResponse) Reset()         { *m = BalanceResponse{} }
+func (m *BalanceResponse) String() string { return proto.CompactTextString(m) }
+func (*BalanceResponse) ProtoMessage()    {}
+
+type GetTransactionsRequest struct {
+	StartingBlockHeight int32 `protobuf:"varint,1,opt,name=starting_block_height,json=startingBlockHeight" json:"starting_block_height,omitempty"`
+	EndingBlockHeight   int32 `protobuf:"varint,2,opt,name=ending_block_height,json=endingBlockHeight" json:"ending_block_height,omitempty"`
+}
+
+func (m *GetTransactionsRequest) Reset()         { *m = GetTransactionsRequest{} }
+func (m *GetTransactionsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTransactionsRequest) ProtoMessage()    {}
+
+type GetTransactionsResponse struct {
+	Transactions []*TransactionDetails `protobuf:"bytes,1,rep,name=transactions" json:"transactions,omitempty"`
+}
+
+func (m *GetTransactionsResponse) Reset()         { *m = GetTransactionsResponse{} }
+func (m *GetTransactionsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTransactionsResponse) ProtoMessage()    {}
+
+func (m *GetTransactionsResponse) GetTransactions() []*TransactionDetails {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+type NextAddressRequest struct {
+	Account uint32                  `protobuf:"varint,1,opt,name=account" json:"account,omitempty"`
+	Kind    NextAddressRequest_Kind `protobuf:"varint,2,opt,name=kind,enum=walletrpc.NextAddressRequest_Kind" json:"kind,omitempty"`
+}
+
+func (m *NextAddressRequest) Reset()         { *m = NextAddressRequest{} }
+func (m *NextAddressRequest) String() string { return proto.CompactTextString(m) }
+func (*NextAddressRequest) ProtoMessage()    {}
+
+type NextAddressResponse struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+}
+
+func (m *NextAddressResponse) Reset()         { *m = NextAddressResponse{} }
+func (m *NextAddressResponse) String() string { return proto.CompactTextString(m) }
+func (*NextAddressResponse) ProtoMessage()    {}
+
+// ConstructTransactionRequest creates a transaction funded by outputs of
+// source_account paying the requested outputs.  The transaction is signed
+// by the wallet, which must be unlocked, but is not published.
+type ConstructTransactionRequest struct {
+	SourceAccount         uint32                                `protobuf:"varint,1,opt,name=source_account,json=sourceAccount" json:"source_account,omitempty"`
+	RequiredConfirmations int32                                 `protobuf:"varint,2,opt,name=required_confirmations,json=requiredConfirmations" json:"required_confirmations,omitempty"`
+	Outputs               []*ConstructTransactionRequest_Output `protobuf:"bytes,3,rep,name=outputs" json:"outputs,omitempty"`
+}
+
+func (m *ConstructTransactionRequest) Reset()         { *m = ConstructTransactionRequest{} }
+func (m *ConstructTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*ConstructTransactionRequest) ProtoMessage()    {}
+
+func (m *ConstructTransactionRequest) GetOutputs() []*ConstructTransactionRequest_Output {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+type ConstructTransactionRequest_Output struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Amount  int64  `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
+}
+
+func (m *ConstructTransactionRequest_Output) Reset()         { *m = ConstructTransactionRequest_Output{} }
+func (m *ConstructTransactionRequest_Output) String() string { return proto.CompactTextString(m) }
+func (*ConstructTransactionRequest_Output) ProtoMessage()    {}
+
+type ConstructTransactionResponse struct {
+	Transaction []byte `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	ChangeIndex int32  `protobuf:"varint,2,opt,name=change_index,json=changeIndex" json:"change_index,omitempty"`
+}
+
+func (m *ConstructTransactionResponse) Reset()         { *m = ConstructTransactionResponse{} }
+func (m *ConstructTransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*ConstructTransactionResponse) ProtoMessage()    {}
+
+// SignTransactionRequest signs each input of the transaction spending an
+// output controlled by the wallet, which must be unlocked.
+type SignTransactionRequest struct {
+	SerializedTransaction []byte `protobuf:"bytes,1,opt,name=serialized_transaction,json=serializedTransaction,proto3" json:"serialized_transaction,omitempty"`
+}
+
+func (m *SignTransactionRequest) Reset()         { *m = SignTransactionRequest{} }
+func (m *SignTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*SignTransactionRequest) ProtoMessage()    {}
+
+type SignTransactionResponse struct {
+	Transaction  []byte `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	SignedInputs uint32 `protobuf:"varint,2,opt,name=signed_inputs,json=signedInputs" json:"signed_inputs,omitempty"`
+}
+
+func (m *SignTransactionResponse) Reset()         { *m = SignTransactionResponse{} }
+func (m *SignTransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*SignTransactionResponse) ProtoMessage()    {}
+
+type PublishTransactionRequest struct {
+	SignedTransaction []byte `protobuf:"bytes,1,opt,name=signed_transaction,json=signedTransaction,proto3" json:"signed_transaction,omitempty"`
+}
+
+func (m *PublishTransactionRequest) Reset()         { *m = PublishTransactionRequest{} }
+func (m *PublishTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*PublishTransactionRequest) ProtoMessage()    {}
+
+type PublishTransactionResponse struct {
+	TransactionHash []byte `protobuf:"bytes,1,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+}
+
+func (m *PublishTransactionResponse) Reset()         { *m = PublishTransactionResponse{} }
+func (m *PublishTransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*PublishTransactionResponse) ProtoMessage()    {}
+
+type PurchaseTicketsRequest struct {
+	Count                 uint32 `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+	MinBalance            int64  `protobuf:"varint,2,opt,name=min_balance,json=minBalance" json:"min_balance,omitempty"`
+	RequiredConfirmations int32  `protobuf:"varint,3,opt,name=required_confirmations,json=requiredConfirmations" json:"required_confirmations,omitempty"`
+	TicketAddress         string `protobuf:"bytes,4,opt,name=ticket_address,json=ticketAddress" json:"ticket_address,omitempty"`
+	Account               uint32 `protobuf:"varint,5,opt,name=account" json:"account,omitempty"`
+	RewardAccount         uint32 `protobuf:"varint,6,opt,name=reward_account,json=rewardAccount" json:"reward_account,omitempty"`
+	RewardAddress         string `protobuf:"bytes,7,opt,name=reward_address,json=rewardAddress" json:"reward_address,omitempty"`
+}
+
+func (m *PurchaseTicketsRequest) Reset()         { *m = PurchaseTicketsRequest{} }
+func (m *PurchaseTicketsRequest) String() string { return proto.CompactTextString(m) }
+func (*PurchaseTicketsRequest) ProtoMessage()    {}
+
+type PurchaseTicketsResponse struct {
+	TicketHashes [][]byte `protobuf:"bytes,1,rep,name=ticket_hashes,json=ticketHashes,proto3" json:"ticket_hashes,omitempty"`
+}
+
+func (m *PurchaseTicketsResponse) Reset()         { *m = PurchaseTicketsResponse{} }
+func (m *PurchaseTicketsResponse) String() string { return proto.CompactTextString(m) }
+func (*PurchaseTicketsResponse) ProtoMessage()    {}
+
+func init() {
+	proto.RegisterType((*TransactionDetails)(nil), "walletrpc.TransactionDetails")
+	proto.RegisterType((*TransactionDetails_Input)(nil), "walletrpc.TransactionDetails.Input")
+	proto.RegisterType((*TransactionDetails_Output)(nil), "walletrpc.TransactionDetails.Output")
+	proto.RegisterType((*PingRequest)(nil), "walletrpc.PingRequest")
+	proto.RegisterType((*PingResponse)(nil), "walletrpc.PingResponse")
+	proto.RegisterType((*NetworkRequest)(nil), "walletrpc.NetworkRequest")
+	proto.RegisterType((*NetworkResponse)(nil), "walletrpc.NetworkResponse")
+	proto.RegisterType((*BalanceRequest)(nil), "walletrpc.BalanceRequest")
+	proto.RegisterType((*BalanceResponse)(nil), "walletrpc.BalanceResponse")
+	proto.RegisterType((*GetTransactionsRequest)(nil), "walletrpc.GetTransactionsRequest")
+	proto.RegisterType((*GetTransactionsResponse)(nil), "walletrpc.GetTransactionsResponse")
+	proto.RegisterType((*NextAddressRequest)(nil), "walletrpc.NextAddressRequest")
+	proto.RegisterType((*NextAddressResponse)(nil), "walletrpc.NextAddressResponse")
+	proto.RegisterType((*ConstructTransactionRequest)(nil), "walletrpc.ConstructTransactionRequest")
+	proto.RegisterType((*ConstructTransactionRequest_Output)(nil), "walletrpc.ConstructTransactionRequest.Output")
+	proto.RegisterType((*ConstructTransactionResponse)(nil), "walletrpc.ConstructTransactionResponse")
+	proto.RegisterType((*SignTransactionRequest)(nil), "walletrpc.SignTransactionRequest")
+	proto.RegisterType((*SignTransactionResponse)(nil), "walletrpc.SignTransactionResponse")
+	proto.RegisterType((*PublishTransactionRequest)(nil), "walletrpc.PublishTransactionRequest")
+	proto.RegisterType((*PublishTransactionResponse)(nil), "walletrpc.PublishTransactionResponse")
+	proto.RegisterType((*PurchaseTicketsRequest)(nil), "walletrpc.PurchaseTicketsRequest")
+	proto.RegisterType((*PurchaseTicketsResponse)(nil), "walletrpc.PurchaseTicketsResponse")
+	proto.RegisterEnum("walletrpc.NextAddressRequest_Kind", NextAddressRequest_Kind_name, NextAddressRequest_Kind_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// Client API for WalletService service
+
+type WalletServiceClient interface {
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	Network(ctx context.Context, in *NetworkRequest, opts ...grpc.CallOption) (*NetworkResponse, error)
+	Balance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
+	GetTransactions(ctx context.Context, in *GetTransactionsRequest, opts ...grpc.CallOption) (WalletService_GetTransactionsClient, error)
+	NextAddress(ctx context.Context, in *NextAddressRequest, opts ...grpc.CallOption) (*NextAddressResponse, error)
+	ConstructTransaction(ctx context.Context, in *ConstructTransactionRequest, opts ...grpc.CallOption) (*ConstructTransactionResponse, error)
+	SignTransaction(ctx context.Context, in *SignTransactionRequest, opts ...grpc.CallOption) (*SignTransactionResponse, error)
+	PublishTransaction(ctx context.Context, in *PublishTransactionRequest, opts ...grpc.CallOption) (*PublishTransactionResponse, error)
+	PurchaseTickets(ctx context.Context, in *PurchaseTicketsRequest, opts ...grpc.CallOption) (*PurchaseTicketsResponse, error)
+}
+
+type walletServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewWalletServiceClient(cc *grpc.ClientConn) WalletServiceClient {
+	return &walletServiceClient{cc}
+}
+
+func (c *walletServiceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	out := new(PingResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/Ping", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) Network(ctx context.Context, in *NetworkRequest, opts ...grpc.CallOption) (*NetworkResponse, error) {
+	out := new(NetworkResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/Network", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) Balance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	out := new(BalanceResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/Balance", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) GetTransactions(ctx context.Context, in *GetTransactionsRequest, opts ...grpc.CallOption) (WalletService_GetTransactionsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletService_serviceDesc.Streams[0], c.cc, "/walletrpc.WalletService/GetTransactions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletServiceGetTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletService_GetTransactionsClient interface {
+	Recv() (*GetTransactionsResponse, error)
+	grpc.ClientStream
+}
+
+type walletServiceGetTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletServiceGetTransactionsClient) Recv() (*GetTransactionsResponse, error) {
+	m := new(GetTransactionsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *walletServiceClient) NextAddress(ctx context.Context, in *NextAddressRequest, opts ...grpc.CallOption) (*NextAddressResponse, error) {
+	out := new(NextAddressResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/NextAddress", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) ConstructTransaction(ctx context.Context, in *ConstructTransactionRequest, opts ...grpc.CallOption) (*ConstructTransactionResponse, error) {
+	out := new(ConstructTransactionResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/ConstructTransaction", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) SignTransaction(ctx context.Context, in *SignTransactionRequest, opts ...grpc.CallOption) (*SignTransactionResponse, error) {
+	out := new(SignTransactionResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/SignTransaction", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) PublishTransaction(ctx context.Context, in *PublishTransactionRequest, opts ...grpc.CallOption) (*PublishTransactionResponse, error) {
+	out := new(PublishTransactionResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/PublishTransaction", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) PurchaseTickets(ctx context.Context, in *PurchaseTicketsRequest, opts ...grpc.CallOption) (*PurchaseTicketsResponse, error) {
+	out := new(PurchaseTicketsResponse)
+	err := grpc.Invoke(ctx, "/walletrpc.WalletService/PurchaseTickets", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for WalletService service
+
+type WalletServiceServer interface {
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	Network(context.Context, *NetworkRequest) (*NetworkResponse, error)
+	Balance(context.Context, *BalanceRequest) (*BalanceResponse, error)
+	GetTransactions(*GetTransactionsRequest, WalletService_GetTransactionsServer) error
+	NextAddress(context.Context, *NextAddressRequest) (*NextAddressResponse, error)
+	ConstructTransaction(context.Context, *ConstructTransactionRequest) (*ConstructTransactionResponse, error)
+	SignTransaction(context.Context, *SignTransactionRequest) (*SignTransactionResponse, error)
+	PublishTransaction(context.Context, *PublishTransactionRequest) (*PublishTransactionResponse, error)
+	PurchaseTickets(context.Context, *PurchaseTicketsRequest) (*PurchaseTicketsResponse, error)
+}
+
+func RegisterWalletServiceServer(s *grpc.Server, srv WalletServiceServer) {
+	s.RegisterService(&_WalletService_serviceDesc, srv)
+}
+
+func _WalletService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(WalletServiceServer).Ping(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _WalletService_Network_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(NetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(WalletServiceServer).Network(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _WalletService_Balance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(BalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(WalletServiceServer).Balance(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _WalletService_GetTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletServiceServer).GetTransactions(m, &walletServiceGetTransactionsServer{stream})
+}
+
+type WalletService_GetTransactionsServer interface {
+	Send(*GetTransactionsResponse) error
+	grpc.ServerStream
+}
+
+type walletServiceGetTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletServiceGetTransactionsServer) Send(m *GetTransactionsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _WalletService_NextAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(NextAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(WalletServiceServer).NextAddress(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _WalletService_ConstructTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(ConstructTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(WalletServiceServer).ConstructTransaction(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _WalletService_SignTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SignTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(WalletServiceServer).SignTransaction(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _WalletService_PublishTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(PublishTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(WalletServiceServer).PublishTransaction(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _WalletService_PurchaseTickets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(PurchaseTicketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(WalletServiceServer).PurchaseTickets(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var _WalletService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "walletrpc.WalletService",
+	HandlerType: (*WalletServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ping",
+			Handler:    _WalletService_Ping_Handler,
+		},
+		{
+			MethodName: "Network",
+			Handler:    _WalletService_Network_Handler,
+		},
+		{
+			MethodName: "Balance",
+			Handler:    _WalletService_Balance_Handler,
+		},
+		{
+			MethodName: "NextAddress",
+			Handler:    _WalletService_NextAddress_Handler,
+		},
+		{
+			MethodName: "ConstructTransaction",
+			Handler:    _WalletService_ConstructTransaction_Handler,
+		},
+		{
+			MethodName: "SignTransaction",
+			Handler:    _WalletService_SignTransaction_Handler,
+		},
+		{
+			MethodName: "PublishTransaction",
+			Handler:    _WalletService_PublishTransaction_Handler,
+		},
+		{
+			MethodName: "PurchaseTickets",
+			Handler:    _WalletService_PurchaseTickets_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetTransactions",
+			Handler:       _WalletService_GetTransactions_Handler,
+			ServerStreams: true,
+		},
+	},
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"errors"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletjson"
+)
+
+// maxConfirmationWatches is the maximum number of transactions a single
+// websocket client may watch with notifyconfirmations at a time.
+const maxConfirmationWatches = 10000
+
+// watchConfirmations registers the client to be notified when the transaction
+// with hash reaches confs confirmations.  Watching a transaction again
+// replaces the previous threshold.
+func (c *websocketClient) watchConfirmations(hash *chainhash.Hash,
+	confs int32) error {
+	c.ntfnMtx.Lock()
+	defer c.ntfnMtx.Unlock()
+
+	if c.confWatches == nil {
+		c.confWatches = make(map[chainhash.Hash]int32)
+	}
+	_, ok := c.confWatches[*hash]
+	if !ok && len(c.confWatches) >= maxConfirmationWatches {
+		return errors.New("too many watched transactions")
+	}
+	c.confWatches[*hash] = confs
+	return nil
+}
+
+// reachedConfirmations removes the client's watches for transactions which
+// have reached their confirmation threshold and returns a txconfirmed
+// notification for each.  Confirmations are counted from the block the
+// transaction store currently records the transaction in, so a transaction
+// which is reorganized out of the main chain before reaching its threshold
+// must be mined and confirmed again.
+func (c *websocketClient) reachedConfirmations(w *wallet.Wallet) []interface{} {
+	c.ntfnMtx.Lock()
+	defer c.ntfnMtx.Unlock()
+
+	if len(c.confWatches) == 0 {
+		return nil
+	}
+
+	syncBlock := w.Manager.SyncedTo()
+	var ntfns []interface{}
+	for hash, confs := range c.confWatches {
+		hash := hash
+		details, err := w.TxStore.TxDetails(&hash)
+		if err != nil {
+			rpcsLog.Errorf("Cannot fetch details of watched "+
+				"transaction %v: %v", &hash, err)
+			continue
+		}
+		if details == nil || details.Block.Height == -1 {
+			continue
+		}
+		n := confirms(details.Block.Height, syncBlock.Height)
+		if n < confs {
+			continue
+		}
+		delete(c.confWatches, hash)
+		ntfns = append(ntfns, walletjson.NewTxConfirmedNtfn(hash.String(),
+			n, details.Block.Hash.String(), details.Block.Height))
+	}
+	return ntfns
+}
+
+// sendReachedConfirmations sends the client a txconfirmed notification for
+// each watched transaction which has reached its confirmation threshold.
+func (s *rpcServer) sendReachedConfirmations(c *websocketClient) error {
+	for _, n := range c.reachedConfirmations(s.wallet) {
+		mn, err := dcrjson.MarshalCmd(nil, n)
+		// All notifications are expected to be marshalable.
+		if err != nil {
+			panic(err)
+		}
+		if err := c.send(mn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleNotifyConfirmations handles a notifyconfirmations request by watching
+// the transaction for the websocket client.
+func (s *rpcServer) handleNotifyConfirmations(req *dcrjson.Request,
+	c *websocketClient) (interface{}, *dcrjson.RPCError) {
+	icmd, err := dcrjson.UnmarshalCmd(req)
+	if err != nil {
+		return nil, dcrjson.ErrRPCInvalidRequest
+	}
+	cmd := icmd.(*walletjson.NotifyConfirmationsCmd)
+
+	hash, err := chainhash.NewHashFromStr(cmd.TxID)
+	if err != nil {
+		return nil, &dcrjson.RPCError{
+			Code:    dcrjson.ErrRPCDecodeHexString,
+			Message: "Transaction hash string decode failed: " + err.Error(),
+		}
+	}
+	if cmd.Confirmations < 1 {
+		return nil, jsonError(InvalidParameterError{
+			errors.New("confirmations must be positive")})
+	}
+	if err := c.watchConfirmations(hash, cmd.Confirmations); err != nil {
+		return nil, jsonError(InvalidParameterError{err})
+	}
+	return nil, nil
+}
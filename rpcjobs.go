@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrwallet/walletjson"
+)
+
+// rpcJobMethods is the set of methods which may be run as jobs by the
+// startjob method.  These are the methods which may take minutes to
+// complete, longer than clients are willing to wait for a response.
+var rpcJobMethods = map[string]struct{}{
+	"importprivkey": {},
+	"importscript":  {},
+	"rescanwallet":  {},
+	"setbirthday":   {},
+}
+
+// Statuses of jobs started by the startjob method.
+const (
+	jobStatusRunning = "running"
+	jobStatusDone    = "done"
+	jobStatusFailed  = "failed"
+)
+
+// maxFinishedJobs is the number of finished jobs remembered by the server.
+// The oldest finished jobs are forgotten first.
+const maxFinishedJobs = 100
+
+// rpcJob is a request which is handled in the background after its job ID
+// has been returned to the client.
+type rpcJob struct {
+	id       int64
+	method   string
+	started  time.Time
+	finished time.Time
+	result   interface{}
+	err      *dcrjson.RPCError
+}
+
+// status describes the job as a getjobstatus result.
+func (j *rpcJob) status() walletjson.JobStatusResult {
+	r := walletjson.JobStatusResult{
+		JobID:   j.id,
+		Method:  j.method,
+		Status:  jobStatusRunning,
+		Started: j.started.Unix(),
+	}
+	if j.finished.IsZero() {
+		return r
+	}
+	r.Finished = j.finished.Unix()
+	if j.err != nil {
+		r.Status = jobStatusFailed
+		r.Error = j.err.Message
+	} else {
+		r.Status = jobStatusDone
+		r.Result = j.result
+	}
+	return r
+}
+
+// rpcJobManager records the running and recently finished jobs of the RPC
+// server.  The zero value is ready to use.
+type rpcJobManager struct {
+	mu       sync.Mutex
+	lastID   int64
+	jobs     map[int64]*rpcJob
+	finished []int64 // IDs in the order jobs finished
+}
+
+// start records a new running job for a method.
+func (m *rpcJobManager) start(method string) *rpcJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.jobs == nil {
+		m.jobs = make(map[int64]*rpcJob)
+	}
+	m.lastID++
+	j := &rpcJob{
+		id:      m.lastID,
+		method:  method,
+		started: time.Now(),
+	}
+	m.jobs[j.id] = j
+	return j
+}
+
+// finish records the result of a job and returns its final status.  The
+// oldest finished job is forgotten if more than maxFinishedJobs have finished.
+func (m *rpcJobManager) finish(j *rpcJob, result interface{},
+	jsonErr *dcrjson.RPCError) walletjson.JobStatusResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j.finished = time.Now()
+	j.result = result
+	j.err = jsonErr
+	m.finished = append(m.finished, j.id)
+	if len(m.finished) > maxFinishedJobs {
+		delete(m.jobs, m.finished[0])
+		m.finished = m.finished[1:]
+	}
+	return j.status()
+}
+
+// status returns the status of a job, or false if no job with the ID is
+// known.
+func (m *rpcJobManager) status(id int64) (walletjson.JobStatusResult, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return walletjson.JobStatusResult{}, false
+	}
+	return j.status(), true
+}
+
+// list returns the status of every known job, without their results, ordered
+// by job ID.
+func (m *rpcJobManager) list() []walletjson.JobStatusResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	results := make([]walletjson.JobStatusResult, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		r := j.status()
+		r.Result = nil
+		results = append(results, r)
+	}
+	sort.Sort(jobStatusesByID(results))
+	return results
+}
+
+type jobStatusesByID []walletjson.JobStatusResult
+
+func (s jobStatusesByID) Len() int           { return len(s) }
+func (s jobStatusesByID) Less(i, j int) bool { return s[i].JobID < s[j].JobID }
+func (s jobStatusesByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// isJobMethod returns whether the method is one of the methods used to start
+// and query jobs.  These are handled by the server rather than a request
+// handler, as jobs outlive the requests which start them.
+func isJobMethod(method string) bool {
+	switch method {
+	case "startjob", "getjobstatus", "listjobs":
+		return true
+	}
+	return false
+}
+
+// handleJobRequest handles the startjob, getjobstatus, and listjobs methods
+// for a client which has already been checked for the permission to call
+// them.
+func (s *rpcServer) handleJobRequest(req *dcrjson.Request,
+	user *rpcUser) (interface{}, *dcrjson.RPCError) {
+	cmd, err := dcrjson.UnmarshalCmd(req)
+	if err != nil {
+		return nil, dcrjson.ErrRPCInvalidRequest
+	}
+
+	switch cmd := cmd.(type) {
+	case *walletjson.StartJobCmd:
+		return s.startJob(req, cmd, user)
+
+	case *walletjson.GetJobStatusCmd:
+		status, ok := s.jobs.status(cmd.JobID)
+		if !ok {
+			return nil, jsonError(InvalidParameterError{
+				fmt.Errorf("unknown job %d", cmd.JobID)})
+		}
+		return status, nil
+
+	case *walletjson.ListJobsCmd:
+		return s.jobs.list(), nil
+
+	default:
+		return nil, dcrjson.ErrRPCMethodNotFound
+	}
+}
+
+// startJob starts handling the request described by a startjob command in the
+// background and returns the job ID.  The client must have the permission to
+// perform the request.  Clients subscribed with notifyjobs are notified when
+// the job finishes.
+func (s *rpcServer) startJob(req *dcrjson.Request, cmd *walletjson.StartJobCmd,
+	user *rpcUser) (interface{}, *dcrjson.RPCError) {
+	if _, ok := rpcJobMethods[cmd.Method]; !ok {
+		return nil, jsonError(InvalidParameterError{
+			fmt.Errorf("method %s may not be run as a job", cmd.Method)})
+	}
+
+	jobReq := &dcrjson.Request{
+		Jsonrpc: req.Jsonrpc,
+		ID:      req.ID,
+		Method:  cmd.Method,
+	}
+	if cmd.Params != nil {
+		for _, p := range *cmd.Params {
+			param, err := json.Marshal(p)
+			if err != nil {
+				return nil, jsonError(InvalidParameterError{err})
+			}
+			jobReq.Params = append(jobReq.Params, param)
+		}
+	}
+	if jsonErr := user.checkPermission(jobReq); jsonErr != nil {
+		return nil, jsonErr
+	}
+	// Reject invalid requests now rather than starting a job which is
+	// certain to fail.
+	if _, err := unmarshalCmd(jobReq); err != nil {
+		return nil, jsonError(InvalidParameterError{
+			fmt.Errorf("invalid job parameters: %v", err)})
+	}
+
+	f := s.HandlerClosure(jobReq.Method, user.signingOrigin())
+	job := s.jobs.start(jobReq.Method)
+	rpcsLog.Infof("Started job %d (%s)", job.id, job.method)
+	go func() {
+		result, jsonErr := f(jobReq)
+		status := s.jobs.finish(job, result, jsonErr)
+		rpcsLog.Infof("Job %d (%s) %s", job.id, job.method, status.Status)
+		select {
+		case s.finishedJobs <- status:
+		case <-s.quit:
+		}
+	}()
+	return job.id, nil
+}
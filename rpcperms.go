@@ -0,0 +1,382 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wallet"
+)
+
+// rpcPermission is a set of capabilities granted to an RPC client.  Every
+// method requires a single capability, and admin clients may call every
+// method.
+type rpcPermission uint8
+
+// Capabilities that may be granted to RPC clients.
+const (
+	rpcPermReadOnly rpcPermission = 1 << iota
+	rpcPermSend
+	rpcPermStaking
+	rpcPermAdmin
+)
+
+// rpcPermissionSets maps the permission names accepted by the --rpcauth
+// option to the capabilities they grant.
+var rpcPermissionSets = map[string]rpcPermission{
+	"readonly": rpcPermReadOnly,
+	"send":     rpcPermReadOnly | rpcPermSend,
+	"staking":  rpcPermReadOnly | rpcPermStaking,
+	"admin":    rpcPermReadOnly | rpcPermSend | rpcPermStaking | rpcPermAdmin,
+}
+
+// rpcMethodPermissions maps RPC methods to the capability required to call
+// them.  Methods which are not listed, including any method passed through
+// to the chain server, require admin.  The startjob method additionally
+// requires the capability needed by the request run as a job.
+var rpcMethodPermissions = map[string]rpcPermission{
+	"createmultisig":          rpcPermReadOnly,
+	"decodeaddress":           rpcPermReadOnly,
+	"decodepaymenturi":        rpcPermReadOnly,
+	"decoderawtransaction":    rpcPermReadOnly,
+	"describescript":          rpcPermReadOnly,
+	"estimatestakediff":       rpcPermReadOnly,
+	"exportaccounthistory":    rpcPermReadOnly,
+	"exportcapitalgains":      rpcPermReadOnly,
+	"getaccount":              rpcPermReadOnly,
+	"getaddressesbyaccount":   rpcPermReadOnly,
+	"getaddresspath":          rpcPermReadOnly,
+	"getapiinfo":              rpcPermReadOnly,
+	"getbackendstate":         rpcPermReadOnly,
+	"getbalancehistory":       rpcPermReadOnly,
+	"getbalance":              rpcPermReadOnly,
+	"getbestblock":            rpcPermReadOnly,
+	"getbestblockhash":        rpcPermReadOnly,
+	"getblockcount":           rpcPermReadOnly,
+	"getblockvotebits":        rpcPermReadOnly,
+	"getcreditorigin":         rpcPermReadOnly,
+	"getfeesreport":           rpcPermReadOnly,
+	"getimportedbalance":      rpcPermReadOnly,
+	"getinfo":                 rpcPermReadOnly,
+	"getjobstatus":            rpcPermReadOnly,
+	"getlockinfo":             rpcPermReadOnly,
+	"getmasterpubkey":         rpcPermReadOnly,
+	"getmultisigoutinfo":      rpcPermReadOnly,
+	"getreceivedbyaccount":    rpcPermReadOnly,
+	"getreceivedbyaddress":    rpcPermReadOnly,
+	"getstakeinfo":            rpcPermReadOnly,
+	"getticketbuyerlog":       rpcPermReadOnly,
+	"getticketmaxprice":       rpcPermReadOnly,
+	"getticketpoolshare":      rpcPermReadOnly,
+	"gettickets":              rpcPermReadOnly,
+	"gettransaction":          rpcPermReadOnly,
+	"getunconfirmedbalance":   rpcPermReadOnly,
+	"getwalletfee":            rpcPermReadOnly,
+	"help":                    rpcPermReadOnly,
+	"listaccounts":            rpcPermReadOnly,
+	"listaddresstickets":      rpcPermReadOnly,
+	"listaddresstransactions": rpcPermReadOnly,
+	"listaddressusage":        rpcPermReadOnly,
+	"listalltransactions":     rpcPermReadOnly,
+	"listjobs":                rpcPermReadOnly,
+	"listpendingsends":        rpcPermReadOnly,
+	"listretiredaddresses":    rpcPermReadOnly,
+	"listvsptickets":          rpcPermReadOnly,
+	"listlockunspent":         rpcPermReadOnly,
+	"listreceivedbyaccount":   rpcPermReadOnly,
+	"listreceivedbyaddress":   rpcPermReadOnly,
+	"listsinceblock":          rpcPermReadOnly,
+	"listtransactions":        rpcPermReadOnly,
+	"listunspent":             rpcPermReadOnly,
+	"notifybalance":           rpcPermReadOnly,
+	"notifyblockconnected":    rpcPermReadOnly,
+	"notifyconfirmations":     rpcPermReadOnly,
+	"notifyjobs":              rpcPermReadOnly,
+	"notifynewtransactions":   rpcPermReadOnly,
+	"notifyrescanprogress":    rpcPermReadOnly,
+	"notifytickets":           rpcPermReadOnly,
+	"startjob":                rpcPermReadOnly,
+	"ticketsforaddress":       rpcPermReadOnly,
+	"validateaddress":         rpcPermReadOnly,
+	"verifymessage":           rpcPermReadOnly,
+	"walletislocked":          rpcPermReadOnly,
+
+	"getaccountaddress":   rpcPermSend,
+	"getnewaddress":       rpcPermSend,
+	"createpaymenturi":    rpcPermSend,
+	"getrawchangeaddress": rpcPermSend,
+	"approvesend":         rpcPermSend,
+	"lockunspent":         rpcPermSend,
+	"rejectsend":          rpcPermSend,
+	"sendfrom":            rpcPermSend,
+	"sendmany":            rpcPermSend,
+	"sendtoaddress":       rpcPermSend,
+	"sendtomultisig":      rpcPermSend,
+	"setcreditorigin":     rpcPermSend,
+
+	"purchaseticket":     rpcPermStaking,
+	"purchasevsptickets": rpcPermStaking,
+	"sendtossgen":        rpcPermStaking,
+	"sendtossrtx":        rpcPermStaking,
+	"sendtosstx":         rpcPermStaking,
+	"setgenerate":        rpcPermStaking,
+	"setticketmaxprice":  rpcPermStaking,
+}
+
+// rpcUser describes the credentials and permissions of an RPC client.
+type rpcUser struct {
+	name    string
+	authsha [sha256.Size]byte
+	perms   rpcPermission
+
+	// spendLimit is the maximum amount a single send request may pay to
+	// its recipients, or zero if sends are not limited.
+	spendLimit dcrutil.Amount
+}
+
+// signingOrigin returns the origin recorded in the signing log for
+// transactions signed by the user's requests.
+func (u *rpcUser) signingOrigin() string {
+	return "rpc:" + u.name
+}
+
+// newRPCUser creates an RPC user with the hash of the HTTP basic
+// authorization header for the username and password.
+func newRPCUser(name, password string, perms rpcPermission,
+	spendLimit dcrutil.Amount) *rpcUser {
+	login := name + ":" + password
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+	return &rpcUser{
+		name:       name,
+		authsha:    sha256.Sum256([]byte(auth)),
+		perms:      perms,
+		spendLimit: spendLimit,
+	}
+}
+
+// parseRPCUser parses an RPC user from an --rpcauth option value in the form
+// username:password:permission[:limit].  The limit, in coins, is only valid
+// for the send permission.
+func parseRPCUser(s string) (*rpcUser, error) {
+	fields := strings.Split(s, ":")
+	if len(fields) != 3 && len(fields) != 4 {
+		return nil, fmt.Errorf("RPC user must be specified as " +
+			"username:password:permission[:limit]")
+	}
+	name, password, permName := fields[0], fields[1], fields[2]
+	if name == "" || password == "" {
+		return nil, fmt.Errorf("RPC user requires a username and " +
+			"password")
+	}
+	perms, ok := rpcPermissionSets[permName]
+	if !ok {
+		return nil, fmt.Errorf("unknown permission %q for RPC user %q "+
+			"(must be one of readonly, send, staking, or admin)",
+			permName, name)
+	}
+	var spendLimit dcrutil.Amount
+	if len(fields) == 4 {
+		if permName != "send" {
+			return nil, fmt.Errorf("a spend limit may only be "+
+				"set for RPC users with the send permission, "+
+				"not %q", permName)
+		}
+		limit, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spend limit for RPC "+
+				"user %q: %v", name, err)
+		}
+		spendLimit, err = dcrutil.NewAmount(limit)
+		if err != nil || spendLimit <= 0 {
+			return nil, fmt.Errorf("spend limit for RPC user %q "+
+				"must be positive", name)
+		}
+	}
+	return newRPCUser(name, password, perms, spendLimit), nil
+}
+
+// requestSendAmount returns the total amount paid to the recipients of a
+// send request.
+func requestSendAmount(req *dcrjson.Request) (dcrutil.Amount, error) {
+	icmd, err := unmarshalCmd(req)
+	if err != nil {
+		return 0, err
+	}
+	cmd, _ := unwrapExtendedCmd(icmd)
+
+	var amounts []float64
+	switch cmd := cmd.(type) {
+	case *dcrjson.SendFromCmd:
+		amounts = append(amounts, cmd.Amount)
+	case *dcrjson.SendManyCmd:
+		for _, amount := range cmd.Amounts {
+			amounts = append(amounts, amount)
+		}
+	case *dcrjson.SendToAddressCmd:
+		amounts = append(amounts, cmd.Amount)
+	case *dcrjson.SendToMultiSigCmd:
+		amounts = append(amounts, cmd.Amount)
+	}
+
+	var total dcrutil.Amount
+	for _, amount := range amounts {
+		amt, err := dcrutil.NewAmount(amount)
+		if err != nil {
+			return 0, err
+		}
+		total += amt
+	}
+	return total, nil
+}
+
+// checkPermission returns an error if the user may not perform the request.
+func (u *rpcUser) checkPermission(req *dcrjson.Request) *dcrjson.RPCError {
+	required, ok := rpcMethodPermissions[req.Method]
+	if !ok {
+		required = rpcPermAdmin
+	}
+	if u.perms&(required|rpcPermAdmin) == 0 {
+		return &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCMisc,
+			Message: fmt.Sprintf("RPC user %q is not permitted "+
+				"to call %s", u.name, req.Method),
+		}
+	}
+
+	if required != rpcPermSend || u.perms&rpcPermAdmin != 0 ||
+		u.spendLimit == 0 {
+		return nil
+	}
+	amount, err := requestSendAmount(req)
+	if err != nil {
+		return dcrjson.ErrRPCInvalidRequest
+	}
+	if amount > u.spendLimit {
+		return &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCMisc,
+			Message: fmt.Sprintf("send of %v exceeds the spend "+
+				"limit of %v for RPC user %q", amount,
+				u.spendLimit, u.name),
+		}
+	}
+	return nil
+}
+
+// newSigningPolicy creates the wallet signing policy configured by the policy
+// options.  The policy is enforced for the RPC users without the admin
+// permission, and nil is returned when no policy option is set.
+func newSigningPolicy(cfg *config) (*wallet.SigningPolicy, error) {
+	if cfg.PolicyDailyLimit == 0 && len(cfg.PolicyAllowAddress) == 0 &&
+		len(cfg.PolicyBlockAddress) == 0 && cfg.PolicyMinConf == 0 &&
+		cfg.PolicyHours == "" {
+		return nil, nil
+	}
+
+	p := &wallet.SigningPolicy{
+		Origins:          make(map[string]struct{}),
+		AllowedAddresses: make(map[string]struct{}),
+		BlockedAddresses: make(map[string]struct{}),
+		MinConf:          cfg.PolicyMinConf,
+	}
+	for _, s := range cfg.RPCAuth {
+		user, err := parseRPCUser(s)
+		if err != nil {
+			return nil, err
+		}
+		if user.perms&rpcPermAdmin == 0 {
+			p.Origins[user.signingOrigin()] = struct{}{}
+		}
+	}
+
+	limit, err := dcrutil.NewAmount(cfg.PolicyDailyLimit)
+	if err != nil || limit < 0 {
+		return nil, fmt.Errorf("daily limit must not be negative")
+	}
+	p.DailyLimit = limit
+	if p.MinConf < 0 {
+		return nil, fmt.Errorf("minimum confirmations must not be " +
+			"negative")
+	}
+
+	addrSets := []struct {
+		addrs []string
+		set   map[string]struct{}
+	}{
+		{cfg.PolicyAllowAddress, p.AllowedAddresses},
+		{cfg.PolicyBlockAddress, p.BlockedAddresses},
+	}
+	for _, addrSet := range addrSets {
+		for _, s := range addrSet.addrs {
+			addr, err := dcrutil.DecodeAddress(s, activeNet.Params)
+			if err != nil || !addr.IsForNet(activeNet.Params) {
+				return nil, fmt.Errorf("invalid address %q", s)
+			}
+			addrSet.set[addr.EncodeAddress()] = struct{}{}
+		}
+	}
+
+	if cfg.PolicyHours != "" {
+		p.Hours, err = wallet.ParseDailyWindow(cfg.PolicyHours)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// newSendApprovalPolicy creates the wallet send approval policy configured by
+// the approval options, returning nil when no approval threshold is set.  The
+// approval address, which signs approval tokens, is validated but is not part
+// of the policy, as tokens are verified by the RPC server.
+func newSendApprovalPolicy(cfg *config) (*wallet.SendApprovalPolicy, error) {
+	threshold, err := dcrutil.NewAmount(cfg.ApprovalThreshold)
+	if err != nil || threshold < 0 {
+		return nil, fmt.Errorf("approval threshold must not be negative")
+	}
+	if cfg.ApprovalExpiry <= 0 {
+		return nil, fmt.Errorf("approval expiry must be positive")
+	}
+	if cfg.ApprovalAddress != "" {
+		addr, err := dcrutil.DecodeAddress(cfg.ApprovalAddress,
+			activeNet.Params)
+		if err != nil || !addr.IsForNet(activeNet.Params) {
+			return nil, fmt.Errorf("invalid approval address %q",
+				cfg.ApprovalAddress)
+		}
+		switch addr.(type) {
+		case *dcrutil.AddressPubKeyHash, *dcrutil.AddressSecpPubKey:
+		default:
+			return nil, fmt.Errorf("approval address %q can not "+
+				"sign messages", cfg.ApprovalAddress)
+		}
+	}
+	if threshold == 0 {
+		return nil, nil
+	}
+
+	return &wallet.SendApprovalPolicy{
+		Threshold: threshold,
+		Expiry:    cfg.ApprovalExpiry,
+	}, nil
+}
@@ -23,9 +23,11 @@ import (
 	"crypto/subtle"
 	"crypto/tls"
 	"encoding/base64"
+	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -34,6 +36,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -49,9 +54,11 @@ import (
 	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrrpcclient"
 	"github.com/decred/dcrutil"
+	"github.com/decred/dcrutil/hdkeychain"
 	"github.com/decred/dcrwallet/chain"
 	"github.com/decred/dcrwallet/waddrmgr"
 	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletjson"
 	"github.com/decred/dcrwallet/wstakemgr"
 	"github.com/decred/dcrwallet/wtxmgr"
 )
@@ -107,6 +114,11 @@ var (
 		Message: "Request requires a wallet but wallet has not loaded yet",
 	}
 
+	ErrOfflineWallet = dcrjson.RPCError{
+		Code:    dcrjson.ErrRPCClientNotConnected,
+		Message: "Request requires a chain server but the wallet is offline",
+	}
+
 	ErrWalletUnlockNeeded = dcrjson.RPCError{
 		Code:    dcrjson.ErrRPCWalletUnlockNeeded,
 		Message: "Enter the wallet passphrase with walletpassphrase first",
@@ -151,26 +163,81 @@ func confirms(txHeight, curHeight int32) int32 {
 	}
 }
 
+// wsNotificationType is a bitmask of the groups of notifications a websocket
+// client may subscribe to.  Notifications with no type are sent to every
+// client.
+type wsNotificationType uint32
+
+const (
+	wsNtfnNewTransactions wsNotificationType = 1 << iota
+	wsNtfnBalance
+	wsNtfnTickets
+	wsNtfnBlockConnected
+	wsNtfnRescanProgress
+	wsNtfnJobs
+)
+
+// wsSubscriptionMethods maps the websocket-only subscription methods to the
+// group of notifications each subscribes the client to.
+var wsSubscriptionMethods = map[string]wsNotificationType{
+	"notifynewtransactions": wsNtfnNewTransactions,
+	"notifybalance":         wsNtfnBalance,
+	"notifytickets":         wsNtfnTickets,
+	"notifyblockconnected":  wsNtfnBlockConnected,
+	"notifyrescanprogress":  wsNtfnRescanProgress,
+	"notifyjobs":            wsNtfnJobs,
+}
+
 type websocketClient struct {
-	conn          *websocket.Conn
-	authenticated bool
-	remoteAddr    string
-	allRequests   chan []byte
-	responses     chan []byte
-	quit          chan struct{} // closed on disconnect
-	wg            sync.WaitGroup
+	conn        *websocket.Conn
+	user        *rpcUser // nil until authenticated
+	remoteAddr  string
+	allRequests chan []byte
+	responses   chan []byte
+	quit        chan struct{} // closed on disconnect
+	wg          sync.WaitGroup
+
+	// subscriptions is the set of notification groups subscribed to by
+	// the client.  It is modified by the client's request handler and
+	// read by the notification handler, so it is protected by ntfnMtx.
+	subscriptions wsNotificationType
+	ntfnMtx       sync.Mutex
+
+	// confWatches maps the transactions watched with notifyconfirmations
+	// to the number of confirmations the client waits for.  It is also
+	// protected by ntfnMtx.
+	confWatches map[chainhash.Hash]int32
 }
 
-func newWebsocketClient(c *websocket.Conn, authenticated bool,
+func newWebsocketClient(c *websocket.Conn, user *rpcUser,
 	remoteAddr string) *websocketClient {
 	return &websocketClient{
-		conn:          c,
-		authenticated: authenticated,
-		remoteAddr:    remoteAddr,
-		allRequests:   make(chan []byte),
-		responses:     make(chan []byte),
-		quit:          make(chan struct{}),
+		conn:        c,
+		user:        user,
+		remoteAddr:  remoteAddr,
+		allRequests: make(chan []byte),
+		responses:   make(chan []byte),
+		quit:        make(chan struct{}),
+	}
+}
+
+// subscribe adds a group of notifications to the client's subscriptions.
+func (c *websocketClient) subscribe(t wsNotificationType) {
+	c.ntfnMtx.Lock()
+	c.subscriptions |= t
+	c.ntfnMtx.Unlock()
+}
+
+// wantsNotification returns whether a notification of type t should be sent
+// to the client.  Clients which have not subscribed to any notifications
+// receive all of them, as they did before subscriptions were supported.
+func (c *websocketClient) wantsNotification(t wsNotificationType) bool {
+	if t == 0 {
+		return true
 	}
+	c.ntfnMtx.Lock()
+	defer c.ntfnMtx.Unlock()
+	return c.subscriptions == 0 || c.subscriptions&t != 0
 }
 
 func (c *websocketClient) send(b []byte) error {
@@ -224,7 +291,7 @@ func parseListeners(addrs []string) ([]string, []string, error) {
 
 // genCertPair generates a key/cert pair to the paths provided.
 func genCertPair(certFile, keyFile string) error {
-	log.Infof("Generating TLS certificates...")
+	rpcsLog.Infof("Generating TLS certificates...")
 
 	// Create directories for cert and key files if they do not yet exist.
 	certDir, _ := filepath.Split(certFile)
@@ -250,12 +317,12 @@ func genCertPair(certFile, keyFile string) error {
 	}
 	if err = ioutil.WriteFile(keyFile, key, 0600); err != nil {
 		if rmErr := os.Remove(certFile); rmErr != nil {
-			log.Warnf("Cannot remove written certificates: %v", rmErr)
+			rpcsLog.Warnf("Cannot remove written certificates: %v", rmErr)
 		}
 		return err
 	}
 
-	log.Info("Done generating TLS certificates")
+	rpcsLog.Info("Done generating TLS certificates")
 	return nil
 }
 
@@ -269,9 +336,20 @@ type rpcServer struct {
 	handlerMu     sync.Mutex
 
 	listeners []net.Listener
-	authsha   [sha256.Size]byte
+	users     []*rpcUser // Admin user is first
 	upgrader  websocket.Upgrader
 
+	// jobs records the requests started in the background by the
+	// startjob method.  The final status of each job is sent to
+	// finishedJobs to notify websocket clients.
+	jobs         rpcJobManager
+	finishedJobs chan walletjson.JobStatusResult
+
+	// wallets are the wallets loaded by the loadwallet method, keyed by
+	// name.
+	wallets   map[string]*loadedWallet
+	walletsMu sync.Mutex
+
 	maxPostClients      int64 // Max concurrent HTTP POST clients.
 	maxWebsocketClients int64 // Max concurrent websocket clients.
 
@@ -287,6 +365,10 @@ type rpcServer struct {
 	ticketsPurchased   <-chan wstakemgr.StakeNotification
 	votesCreated       <-chan wstakemgr.StakeNotification
 	revocationsCreated <-chan wstakemgr.StakeNotification
+	ticketOutcomes     <-chan wallet.TicketOutcome
+	ticketWarnings     <-chan wallet.TicketWarning
+	unexpectedSpends   <-chan wallet.UnexpectedSpend
+	rescanProgress     <-chan wallet.RescanWalletProgress
 	relevantTxs        <-chan chain.RelevantTx
 	managerLocked      <-chan bool
 	confirmedBalance   <-chan dcrutil.Amount
@@ -314,11 +396,18 @@ type rpcServer struct {
 // HTTP POST and websocket.
 func newRPCServer(listenAddrs []string, maxPost,
 	maxWebsockets int64) (*rpcServer, error) {
-	login := cfg.Username + ":" + cfg.Password
-	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+	users := []*rpcUser{newRPCUser(cfg.Username, cfg.Password,
+		rpcPermissionSets["admin"], 0)}
+	for _, s := range cfg.RPCAuth {
+		user, err := parseRPCUser(s)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
 	s := rpcServer{
 		handlerLookup:       unloadedWalletHandlerFunc,
-		authsha:             sha256.Sum256([]byte(auth)),
+		users:               users,
 		maxPostClients:      maxPost,
 		maxWebsocketClients: maxWebsockets,
 		upgrader: websocket.Upgrader{
@@ -328,6 +417,7 @@ func newRPCServer(listenAddrs []string, maxPost,
 		registerWSC:             make(chan *websocketClient),
 		unregisterWSC:           make(chan *websocketClient),
 		registerWalletNtfns:     make(chan struct{}),
+		finishedJobs:            make(chan walletjson.JobStatusResult),
 		enqueueNotification:     make(chan wsClientNotification),
 		dequeueNotification:     make(chan wsClientNotification),
 		notificationHandlerQuit: make(chan struct{}),
@@ -360,7 +450,7 @@ func newRPCServer(listenAddrs []string, maxPost,
 			return tls.Listen(net, laddr, &tlsConfig)
 		}
 	} else {
-		log.Info("Server TLS is disabled")
+		rpcsLog.Info("Server TLS is disabled")
 	}
 
 	ipv4ListenAddrs, ipv6ListenAddrs, err := parseListeners(listenAddrs)
@@ -372,7 +462,7 @@ func newRPCServer(listenAddrs []string, maxPost,
 	for _, addr := range ipv4ListenAddrs {
 		listener, err := listenFunc("tcp4", addr)
 		if err != nil {
-			log.Warnf("RPCS: Can't listen on %s: %v", addr,
+			rpcsLog.Warnf("Can't listen on %s: %v", addr,
 				err)
 			continue
 		}
@@ -382,7 +472,7 @@ func newRPCServer(listenAddrs []string, maxPost,
 	for _, addr := range ipv6ListenAddrs {
 		listener, err := listenFunc("tcp6", addr)
 		if err != nil {
-			log.Warnf("RPCS: Can't listen on %s: %v", addr,
+			rpcsLog.Warnf("Can't listen on %s: %v", addr,
 				err)
 			continue
 		}
@@ -411,7 +501,7 @@ func (s *rpcServer) Start() {
 	go s.notificationQueue()
 	go s.notificationHandler()
 
-	log.Trace("Starting RPC server")
+	rpcsLog.Trace("Starting RPC server")
 
 	serveMux := http.NewServeMux()
 	const rpcAuthTimeoutSeconds = 10
@@ -430,28 +520,33 @@ func (s *rpcServer) Start() {
 			w.Header().Set("Content-Type", "application/json")
 			r.Close = true
 
-			if err := s.checkAuthHeader(r); err != nil {
-				log.Warnf("Unauthorized client connection attempt")
+			user, err := s.checkAuthHeader(r)
+			if err != nil {
+				rpcsLog.Warnf("Unauthorized client connection attempt")
 				jsonAuthFail(w)
 				return
 			}
+			walletName, ok := walletNameFromPath(r.URL.Path)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
 			s.wg.Add(1)
-			s.PostClientRPC(w, r)
+			s.PostClientRPC(w, r, user, walletName)
 			s.wg.Done()
 		}))
 
 	serveMux.Handle("/ws", throttledFn(s.maxWebsocketClients,
 		func(w http.ResponseWriter, r *http.Request) {
-			authenticated := false
-			switch s.checkAuthHeader(r) {
+			user, err := s.checkAuthHeader(r)
+			switch err {
 			case nil:
-				authenticated = true
 			case ErrNoAuth:
 				// nothing
 			default:
 				// If auth was supplied but incorrect, rather than simply
 				// being missing, immediately terminate the connection.
-				log.Warnf("Disconnecting improperly authorized " +
+				rpcsLog.Warnf("Disconnecting improperly authorized " +
 					"websocket client")
 				jsonAuthFail(w)
 				return
@@ -459,20 +554,20 @@ func (s *rpcServer) Start() {
 
 			conn, err := s.upgrader.Upgrade(w, r, nil)
 			if err != nil {
-				log.Warnf("Cannot websocket upgrade client %s: %v",
+				rpcsLog.Warnf("Cannot websocket upgrade client %s: %v",
 					r.RemoteAddr, err)
 				return
 			}
-			wsc := newWebsocketClient(conn, authenticated, r.RemoteAddr)
+			wsc := newWebsocketClient(conn, user, r.RemoteAddr)
 			s.WebsocketClientRPC(wsc)
 		}))
 
 	for _, listener := range s.listeners {
 		s.wg.Add(1)
 		go func(listener net.Listener) {
-			log.Infof("RPCS: RPC server listening on %s", listener.Addr())
+			rpcsLog.Infof("RPC server listening on %s", listener.Addr())
 			_ = httpServer.Serve(listener)
-			log.Tracef("RPCS: RPC listener done for %s", listener.Addr())
+			rpcsLog.Tracef("RPC listener done for %s", listener.Addr())
 			s.wg.Done()
 		}(listener)
 	}
@@ -491,8 +586,9 @@ func (s *rpcServer) Stop() {
 	default:
 	}
 
-	log.Warn("Server shutting down")
+	rpcsLog.Warn("Server shutting down")
 	s.wallet.CloseDatabases()
+	s.unloadAllWallets()
 
 	// Stop the connected wallet and chain server, if any.
 	s.handlerMu.Lock()
@@ -508,7 +604,7 @@ func (s *rpcServer) Stop() {
 	for _, listener := range s.listeners {
 		err := listener.Close()
 		if err != nil {
-			log.Errorf("Cannot close listener %s: %v",
+			rpcsLog.Errorf("Cannot close listener %s: %v",
 				listener.Addr(), err)
 		}
 	}
@@ -553,48 +649,88 @@ func (s *rpcServer) SetWallet(wallet *wallet.Wallet) {
 		// With both the wallet and chain server set, all handlers are
 		// ok to run.
 		s.handlerLookup = lookupAnyHandler
+	} else {
+		// Without a chain server, only the handlers which do not
+		// require one are ok to run.
+		s.handlerLookup = offlineWalletHandlerFunc
 	}
 }
 
 // SetChainServer sets the chain server client component needed to run a fully
-// functional decred wallet RPC server.  This should be set even before the
-// client is connected, as any request handlers should return the error for
-// a never connected client, rather than panicking (or never being looked up)
-// if the client was never conneceted and added.
+// functional decred wallet RPC server.  If chainSvr is nil, the wallet is
+// running offline and only the wallet methods which do not require a chain
+// server may be handled.
 func (s *rpcServer) SetChainServer(chainSvr *chain.Client) {
 	defer s.handlerMu.Unlock()
 	s.handlerMu.Lock()
 
 	s.chainSvr = chainSvr
 
-	if s.wallet != nil {
+	if s.wallet == nil {
+		return
+	}
+	if chainSvr != nil {
 		// With both the chain server and wallet set, all handlers are
 		// ok to run.
 		s.handlerLookup = lookupAnyHandler
+	} else {
+		s.handlerLookup = offlineWalletHandlerFunc
 	}
 }
 
+// publishMetrics publishes the state of the chain server connection, as
+// returned by getbackendstate, as the "backendstate" expvar.  It is served by
+// the profile server at /debug/vars.
+func (s *rpcServer) publishMetrics() {
+	expvar.Publish("backendstate", expvar.Func(func() interface{} {
+		s.handlerMu.Lock()
+		w, chainSvr := s.wallet, s.chainSvr
+		s.handlerMu.Unlock()
+		if w == nil {
+			return nil
+		}
+		return backendState(w, chainSvr)
+	}))
+}
+
 // HandlerClosure creates a closure function for handling requests of the given
 // method.  This may be a request that is handled directly by dcrwallet, or
 // a chain server request that is handled by passing the request down to dcrd.
 //
+// Transactions signed by the request are attributed to origin in the signing
+// log.
+//
 // NOTE: These handlers do not handle special cases, such as the authenticate
 // method.  Each of these must be checked beforehand (the method is already
 // known) and handled accordingly.
-func (s *rpcServer) HandlerClosure(method string) requestHandlerClosure {
+func (s *rpcServer) HandlerClosure(method, origin string) requestHandlerClosure {
 	defer s.handlerMu.Unlock()
 	s.handlerMu.Lock()
 
-	// With the lock held, make copies of these pointers for the closure.
-	wallet := s.wallet
-	chainSvr := s.chainSvr
+	// With the lock held, the closure is created with copies of the wallet
+	// and chain server pointers.
+	return handlerClosure(s.handlerLookup, s.wallet, s.chainSvr, method,
+		origin)
+}
 
-	if handler, ok := s.handlerLookup(method); ok {
+// handlerClosure creates a closure function for handling requests of the
+// given method with a wallet and chain server.  The handler is looked up using
+// lookup, and requests for which no handler is found are passed through to the
+// chain server.  Requests of methods in rpcSigningMethods are passed to their
+// handlers as a signingCmd, so the transactions they sign are attributed to
+// origin.
+func handlerClosure(lookup func(string) (requestHandler, bool),
+	wallet *wallet.Wallet, chainSvr *chain.Client,
+	method, origin string) requestHandlerClosure {
+	if handler, ok := lookup(method); ok {
 		return func(req *dcrjson.Request) (interface{}, *dcrjson.RPCError) {
-			cmd, err := dcrjson.UnmarshalCmd(req)
+			cmd, err := unmarshalCmd(req)
 			if err != nil {
 				return nil, dcrjson.ErrRPCInvalidRequest
 			}
+			if _, ok := rpcSigningMethods[method]; ok {
+				cmd = &signingCmd{cmd: cmd, origin: origin}
+			}
 			res, err := handler(wallet, chainSvr, cmd)
 			if err != nil {
 				return nil, jsonError(err)
@@ -618,28 +754,182 @@ func (s *rpcServer) HandlerClosure(method string) requestHandlerClosure {
 	}
 }
 
+// rpcExtensionParams maps the methods which accept an extension parameter,
+// which is not understood by dcrjson, to the number of parameters of the
+// dcrjson command.  The extension parameter follows every command parameter
+// and must be a JSON object.
+var rpcExtensionParams = map[string]int{
+	"getnewaddress":        2,
+	"getreceivedbyaccount": 2,
+	"getreceivedbyaddress": 2,
+	"gettransaction":       2,
+	"importscript":         1,
+	"listtransactions":     4,
+	"listunspent":          3,
+}
+
+// rpcSigningMethods is the set of methods which may sign transactions with
+// wallet keys.  The transactions they sign are attributed to the RPC user in
+// the signing log and are checked against the signing policy of the user.
+var rpcSigningMethods = map[string]struct{}{
+	"approvesend":         {},
+	"purchaseticket":      {},
+	"purchasevsptickets":  {},
+	"redeemmultisigout":   {},
+	"redeemmultisigouts":  {},
+	"rotateimportedkey":   {},
+	"sendfrom":            {},
+	"sendmany":            {},
+	"sendtoaddress":       {},
+	"sendtomultisig":      {},
+	"sendtossgen":         {},
+	"sendtossrtx":         {},
+	"sendtosstx":          {},
+	"signrawtransaction":  {},
+	"signrawtransactions": {},
+}
+
+// extendedCmd is a command parsed by dcrjson together with the raw extension
+// parameter passed after the command's parameters.  It is passed to the
+// handlers of methods in rpcExtensionParams in place of the dcrjson command
+// when the extension parameter is used.
+type extendedCmd struct {
+	cmd interface{}
+	ext json.RawMessage
+}
+
+// signingCmd is a command together with the signing origin of the request.
+// It is passed to the handlers of methods in rpcSigningMethods in place of the
+// command, so the transactions they sign are attributed to the RPC user
+// rather than to the wallet.
+type signingCmd struct {
+	cmd    interface{}
+	origin string
+}
+
+// unwrapSigningCmd returns the command and signing origin of a command passed
+// to a handler.  Commands not wrapped by handlerClosure are signed by the
+// wallet on its own.
+func unwrapSigningCmd(icmd interface{}) (interface{}, string) {
+	if s, ok := icmd.(*signingCmd); ok {
+		return s.cmd, s.origin
+	}
+	return icmd, wallet.SigningOriginWallet
+}
+
+// unmarshalCmd unmarshals the command of a request, splitting off the
+// extension parameter of methods which accept one.
+func unmarshalCmd(req *dcrjson.Request) (interface{}, error) {
+	n, ok := rpcExtensionParams[req.Method]
+	if !ok || len(req.Params) <= n {
+		cmd, err := dcrjson.UnmarshalCmd(req)
+		if err != nil {
+			return nil, err
+		}
+		clearOmittedMinConf(cmd, req.Params)
+		return cmd, nil
+	}
+	if len(req.Params) > n+1 {
+		return nil, errors.New("too many parameters")
+	}
+
+	stripped := *req
+	stripped.Params = req.Params[:n]
+	cmd, err := dcrjson.UnmarshalCmd(&stripped)
+	if err != nil {
+		return nil, err
+	}
+	return &extendedCmd{cmd: cmd, ext: req.Params[n]}, nil
+}
+
+// clearOmittedMinConf clears the minconf parameter of commands which spend
+// wallet outputs when it was omitted from the request parameters or passed as
+// null.  dcrjson fills omitted parameters with their default, which would
+// otherwise hide that the wallet's default minimum confirmations apply.
+func clearOmittedMinConf(cmd interface{}, params []json.RawMessage) {
+	omitted := func(i int) bool {
+		return len(params) <= i || string(params[i]) == "null"
+	}
+	switch cmd := cmd.(type) {
+	case *dcrjson.PurchaseTicketCmd:
+		if omitted(2) {
+			cmd.MinConf = nil
+		}
+	case *dcrjson.SendFromCmd:
+		if omitted(3) {
+			cmd.MinConf = nil
+		}
+	case *dcrjson.SendManyCmd:
+		if omitted(2) {
+			cmd.MinConf = nil
+		}
+	case *dcrjson.SendToMultiSigCmd:
+		if omitted(4) {
+			cmd.MinConf = nil
+		}
+	case *dcrjson.SendToSStxCmd:
+		if omitted(4) {
+			cmd.MinConf = nil
+		}
+	}
+}
+
+// requestMinConf returns the minimum confirmations of outputs spent by a
+// request, which is the wallet's default when the request omits it.
+func requestMinConf(w *wallet.Wallet, minConf *int) (int32, error) {
+	if minConf == nil {
+		return w.SpendPolicy().MinConf, nil
+	}
+	if *minConf < 0 {
+		return 0, ErrNeedPositiveMinconf
+	}
+	return int32(*minConf), nil
+}
+
+// unwrapExtendedCmd returns the dcrjson command and extension parameter, if
+// any, of a command passed to a handler.
+func unwrapExtendedCmd(icmd interface{}) (interface{}, json.RawMessage) {
+	if e, ok := icmd.(*extendedCmd); ok {
+		return e.cmd, e.ext
+	}
+	return icmd, nil
+}
+
 // ErrNoAuth represents an error where authentication could not succeed
 // due to a missing Authorization HTTP header.
 var ErrNoAuth = errors.New("no auth")
 
 // checkAuthHeader checks the HTTP Basic authentication supplied by a client
-// in the HTTP request r.  It errors with ErrNoAuth if the request does not
-// contain the Authorization header, or another non-nil error if the
-// authentication was provided but incorrect.
+// in the HTTP request r and returns the authenticated user.  It errors with
+// ErrNoAuth if the request does not contain the Authorization header, or
+// another non-nil error if the authentication was provided but incorrect.
 //
 // This check is time-constant.
-func (s *rpcServer) checkAuthHeader(r *http.Request) error {
+func (s *rpcServer) checkAuthHeader(r *http.Request) (*rpcUser, error) {
 	authhdr := r.Header["Authorization"]
 	if len(authhdr) == 0 {
-		return ErrNoAuth
+		return nil, ErrNoAuth
 	}
 
 	authsha := sha256.Sum256([]byte(authhdr[0]))
-	cmp := subtle.ConstantTimeCompare(authsha[:], s.authsha[:])
-	if cmp != 1 {
-		return errors.New("bad auth")
+	user := s.lookupUser(authsha)
+	if user == nil {
+		return nil, errors.New("bad auth")
 	}
-	return nil
+	return user, nil
+}
+
+// lookupUser returns the user whose authorization header hashes to authsha,
+// or nil if there is no such user.  Every user is compared so the time taken
+// does not depend on which user matched.
+func (s *rpcServer) lookupUser(authsha [sha256.Size]byte) *rpcUser {
+	var user *rpcUser
+	for _, u := range s.users {
+		if subtle.ConstantTimeCompare(authsha[:], u.authsha[:]) == 1 {
+			user = u
+		}
+	}
+	return user
 }
 
 // throttledFn wraps an http.HandlerFunc with throttling of concurrent active
@@ -658,7 +948,7 @@ func throttled(threshold int64, h http.Handler) http.Handler {
 		defer atomic.AddInt64(&active, -1)
 
 		if current-1 >= threshold {
-			log.Warnf("Reached threshold of %d concurrent active clients",
+			rpcsLog.Warnf("Reached threshold of %d concurrent active clients",
 				threshold)
 			http.Error(w, "429 Too Many Requests", 429)
 			return
@@ -674,8 +964,8 @@ func throttled(threshold int64, h http.Handler) http.Handler {
 func sanitizeRequest(r *dcrjson.Request) string {
 	// These are considered unsafe to log, so sanitize parameters.
 	switch r.Method {
-	case "encryptwallet", "importprivkey", "importwallet",
-		"signrawtransaction", "walletpassphrase",
+	case "encryptwallet", "exportseedbackup", "importprivkey",
+		"importwallet", "signrawtransaction", "walletpassphrase",
 		"walletpassphrasechange":
 
 		return fmt.Sprintf(
@@ -698,23 +988,23 @@ func idPointer(id interface{}) (p *interface{}) {
 	return
 }
 
-// invalidAuth checks whether a websocket request is a valid (parsable)
-// authenticate request and checks the supplied username and passphrase
-// against the server auth.
-func (s *rpcServer) invalidAuth(req *dcrjson.Request) bool {
+// authenticateRequest checks whether a websocket request is a valid
+// (parsable) authenticate request and checks the supplied username and
+// passphrase against the server's users.  The authenticated user is returned,
+// or nil if the request is invalid or the credentials are incorrect.
+func (s *rpcServer) authenticateRequest(req *dcrjson.Request) *rpcUser {
 	cmd, err := dcrjson.UnmarshalCmd(req)
 	if err != nil {
-		return false
+		return nil
 	}
 	authCmd, ok := cmd.(*dcrjson.AuthenticateCmd)
 	if !ok {
-		return false
+		return nil
 	}
 	// Check credentials.
 	login := authCmd.Username + ":" + authCmd.Passphrase
 	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
-	authSha := sha256.Sum256([]byte(auth))
-	return subtle.ConstantTimeCompare(authSha[:], s.authsha[:]) != 1
+	return s.lookupUser(sha256.Sum256([]byte(auth)))
 }
 
 func (s *rpcServer) WebsocketClientRead(wsc *websocketClient) {
@@ -722,7 +1012,7 @@ func (s *rpcServer) WebsocketClientRead(wsc *websocketClient) {
 		_, request, err := wsc.conn.ReadMessage()
 		if err != nil {
 			if err != io.EOF && err != io.ErrUnexpectedEOF {
-				log.Warnf("Websocket receive failed from client %s: %v",
+				rpcsLog.Warnf("Websocket receive failed from client %s: %v",
 					wsc.remoteAddr, err)
 			}
 			close(wsc.allRequests)
@@ -750,7 +1040,7 @@ out:
 			var req dcrjson.Request
 			err := json.Unmarshal(reqBytes, &req)
 			if err != nil {
-				if !wsc.authenticated {
+				if wsc.user == nil {
 					// Disconnect immediately.
 					break out
 				}
@@ -771,11 +1061,15 @@ out:
 			}
 
 			if req.Method == "authenticate" {
-				if wsc.authenticated || s.invalidAuth(&req) {
+				if wsc.user != nil {
+					// Disconnect immediately.
+					break out
+				}
+				wsc.user = s.authenticateRequest(&req)
+				if wsc.user == nil {
 					// Disconnect immediately.
 					break out
 				}
-				wsc.authenticated = true
 				resp := makeResponse(req.ID, nil, nil)
 				// Expected to never fail.
 				mresp, err := json.Marshal(resp)
@@ -789,11 +1083,25 @@ out:
 				continue
 			}
 
-			if !wsc.authenticated {
+			if wsc.user == nil {
 				// Disconnect immediately.
 				break out
 			}
 
+			if jsonErr := wsc.user.checkPermission(&req); jsonErr != nil {
+				resp := makeResponse(req.ID, nil, jsonErr)
+				mresp, err := json.Marshal(resp)
+				// Expected to never fail.
+				if err != nil {
+					panic(err)
+				}
+				err = wsc.send(mresp)
+				if err != nil {
+					break out
+				}
+				continue
+			}
+
 			switch req.Method {
 			case "stop":
 				s.Stop()
@@ -809,15 +1117,77 @@ out:
 					break out
 				}
 
+			case "notifynewtransactions", "notifybalance",
+				"notifytickets", "notifyblockconnected",
+				"notifyrescanprogress", "notifyjobs":
+				wsc.subscribe(wsSubscriptionMethods[req.Method])
+				resp := makeResponse(req.ID, nil, nil)
+				mresp, err := json.Marshal(resp)
+				// Expected to never fail.
+				if err != nil {
+					panic(err)
+				}
+				err = wsc.send(mresp)
+				if err != nil {
+					break out
+				}
+
+			case "startjob", "getjobstatus", "listjobs":
+				resp, jsonErr := s.handleJobRequest(&req, wsc.user)
+				mresp, err := dcrjson.MarshalResponse(req.ID, resp,
+					jsonErr)
+				if err != nil {
+					rpcsLog.Errorf("Unable to marshal response: %v", err)
+					continue
+				}
+				err = wsc.send(mresp)
+				if err != nil {
+					break out
+				}
+
+			case "loadwallet", "unloadwallet":
+				resp, jsonErr := s.handleWalletLoaderRequest(&req)
+				mresp, err := dcrjson.MarshalResponse(req.ID, resp,
+					jsonErr)
+				if err != nil {
+					rpcsLog.Errorf("Unable to marshal response: %v", err)
+					continue
+				}
+				err = wsc.send(mresp)
+				if err != nil {
+					break out
+				}
+
+			case "notifyconfirmations":
+				resp, jsonErr := s.handleNotifyConfirmations(&req, wsc)
+				mresp, err := dcrjson.MarshalResponse(req.ID, resp,
+					jsonErr)
+				if err != nil {
+					rpcsLog.Errorf("Unable to marshal response: %v", err)
+					continue
+				}
+				err = wsc.send(mresp)
+				if err != nil {
+					break out
+				}
+				// The transaction may already be confirmed.
+				if jsonErr == nil {
+					err = s.sendReachedConfirmations(wsc)
+					if err != nil {
+						break out
+					}
+				}
+
 			default:
 				req := req // Copy for the closure
-				f := s.HandlerClosure(req.Method)
+				f := s.HandlerClosure(req.Method,
+					wsc.user.signingOrigin())
 				wsc.wg.Add(1)
 				go func() {
 					resp, jsonErr := f(&req)
 					mresp, err := dcrjson.MarshalResponse(req.ID, resp, jsonErr)
 					if err != nil {
-						log.Errorf("Unable to marshal response: %v", err)
+						rpcsLog.Errorf("Unable to marshal response: %v", err)
 					} else {
 						_ = wsc.send(mresp)
 					}
@@ -858,13 +1228,13 @@ out:
 			}
 			err := wsc.conn.SetWriteDeadline(time.Now().Add(deadline))
 			if err != nil {
-				log.Warnf("Cannot set write deadline on "+
+				rpcsLog.Warnf("Cannot set write deadline on "+
 					"client %s: %v", wsc.remoteAddr, err)
 			}
 			err = wsc.conn.WriteMessage(websocket.TextMessage,
 				response)
 			if err != nil {
-				log.Warnf("Failed websocket send to client "+
+				rpcsLog.Warnf("Failed websocket send to client "+
 					"%s: %v", wsc.remoteAddr, err)
 				break out
 			}
@@ -874,19 +1244,19 @@ out:
 		}
 	}
 	close(wsc.quit)
-	log.Infof("Disconnected websocket client %s", wsc.remoteAddr)
+	rpcsLog.Infof("Disconnected websocket client %s", wsc.remoteAddr)
 	s.wg.Done()
 }
 
 // WebsocketClientRPC starts the goroutines to serve JSON-RPC requests and
 // notifications over a websocket connection for a single client.
 func (s *rpcServer) WebsocketClientRPC(wsc *websocketClient) {
-	log.Infof("New websocket client %s", wsc.remoteAddr)
+	rpcsLog.Infof("New websocket client %s", wsc.remoteAddr)
 
 	// Clear the read deadline set before the websocket hijacked
 	// the connection.
 	if err := wsc.conn.SetReadDeadline(time.Time{}); err != nil {
-		log.Warnf("Cannot remove read deadline: %v", err)
+		rpcsLog.Warnf("Cannot remove read deadline: %v", err)
 	}
 
 	// Add client context so notifications duplicated to each
@@ -914,8 +1284,20 @@ func (s *rpcServer) WebsocketClientRPC(wsc *websocketClient) {
 // that may be read from a client.  This is currently limited to 4MB.
 const maxRequestSize = 1024 * 1024 * 4
 
-// PostClientRPC processes and replies to a JSON-RPC client request.
-func (s *rpcServer) PostClientRPC(w http.ResponseWriter, r *http.Request) {
+// maxBatchRequests is the maximum number of requests in a single JSON-RPC
+// batch.  A batch only occupies one of the HTTP POST client slots, so this
+// bounds the work a single client may queue at once.
+const maxBatchRequests = 100
+
+// maxConcurrentBatchRequests is the maximum number of read-only requests of a
+// single batch that are handled concurrently.
+const maxConcurrentBatchRequests = 4
+
+// PostClientRPC processes and replies to a JSON-RPC client request from an
+// authenticated user.  Requests are handled by the loaded wallet named by
+// walletName, or by the default wallet if the name is empty.
+func (s *rpcServer) PostClientRPC(w http.ResponseWriter, r *http.Request,
+	user *rpcUser, walletName string) {
 	body := http.MaxBytesReader(w, r.Body, maxRequestSize)
 	rpcRequest, err := ioutil.ReadAll(body)
 	if err != nil {
@@ -925,6 +1307,13 @@ func (s *rpcServer) PostClientRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Batches of requests are sent as a JSON array.
+	if trimmed := bytes.TrimSpace(rpcRequest); len(trimmed) != 0 &&
+		trimmed[0] == '[' {
+		s.postClientBatch(w, trimmed, user, walletName)
+		return
+	}
+
 	// First check whether wallet has a handler for this request's method.
 	// If unfound, the request is sent to the chain server for further
 	// processing.  While checking the methods, disallow authenticate
@@ -935,44 +1324,190 @@ func (s *rpcServer) PostClientRPC(w http.ResponseWriter, r *http.Request) {
 		resp, err := dcrjson.MarshalResponse(req.ID, nil,
 			dcrjson.ErrRPCInvalidRequest)
 		if err != nil {
-			log.Errorf("Unable to marshal response: %v", err)
+			rpcsLog.Errorf("Unable to marshal response: %v", err)
 			http.Error(w, "500 Internal Server Error",
 				http.StatusInternalServerError)
 			return
 		}
 		_, err = w.Write(resp)
 		if err != nil {
-			log.Warnf("Cannot write invalid request request to "+
+			rpcsLog.Warnf("Cannot write invalid request request to "+
 				"client: %v", err)
 		}
 		return
 	}
 
-	// Create the response and error from the request.  Two special cases
-	// are handled for the authenticate and stop request methods.
-	var res interface{}
-	var jsonErr *dcrjson.RPCError
-	switch req.Method {
-	case "authenticate":
+	// Authenticate requests are invalid for HTTP POST clients.
+	if req.Method == "authenticate" {
 		// Drop it.
 		return
-	case "stop":
-		s.Stop()
-		res = "dcrwallet stopping"
-	default:
-		res, jsonErr = s.HandlerClosure(req.Method)(&req)
 	}
+	res, jsonErr := s.handlePostRequest(&req, user, walletName)
 
 	// Marshal and send.
 	mresp, err := dcrjson.MarshalResponse(req.ID, res, jsonErr)
 	if err != nil {
-		log.Errorf("Unable to marshal response: %v", err)
+		rpcsLog.Errorf("Unable to marshal response: %v", err)
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	_, err = w.Write(mresp)
+	if err != nil {
+		rpcsLog.Warnf("Unable to respond to client: %v", err)
+	}
+}
+
+// handlePostRequest creates the response and error for a request from an HTTP
+// POST client after checking the user's permissions.  The request is handled
+// by the loaded wallet named by walletName, or by the default wallet if the
+// name is empty.  The stop request method, the wallet loader methods, and the
+// job methods are handled as special cases.
+func (s *rpcServer) handlePostRequest(req *dcrjson.Request, user *rpcUser,
+	walletName string) (interface{}, *dcrjson.RPCError) {
+	if jsonErr := user.checkPermission(req); jsonErr != nil {
+		return nil, jsonErr
+	}
+	if req.Method == "stop" {
+		s.Stop()
+		return "dcrwallet stopping", nil
+	}
+	if isWalletLoaderMethod(req.Method) {
+		return s.handleWalletLoaderRequest(req)
+	}
+	if walletName != "" {
+		// Jobs are only run by the default wallet.
+		if isJobMethod(req.Method) {
+			return nil, &dcrjson.RPCError{
+				Code: dcrjson.ErrRPCInvalidParameter,
+				Message: "jobs may only be run by the default " +
+					"wallet",
+			}
+		}
+		return s.loadedWalletHandlerClosure(walletName, req.Method,
+			user.signingOrigin())(req)
+	}
+	if isJobMethod(req.Method) {
+		return s.handleJobRequest(req, user)
+	}
+	return s.HandlerClosure(req.Method, user.signingOrigin())(req)
+}
+
+// concurrentBatchRequest returns whether a request of a batch may be handled
+// concurrently with other requests.  Only read-only requests may, except for
+// startjob, which only requires the read-only permission itself but starts
+// requests which may modify the wallet.
+func concurrentBatchRequest(req *dcrjson.Request) bool {
+	return req.Method != "startjob" &&
+		rpcMethodPermissions[req.Method] == rpcPermReadOnly
+}
+
+// postClientBatch processes and replies to a JSON-RPC batch request, which is
+// a JSON array of requests.  The responses are written as an array in the
+// order of the requests.
+//
+// Consecutive read-only requests are handled concurrently.  Every other
+// request may modify the wallet, so it is only handled after all earlier
+// requests in the batch have finished, and before any later request begins.
+// This includes startjob, which starts requests that may modify the wallet.
+// At most maxConcurrentBatchRequests requests are handled at once, and batches
+// of more than maxBatchRequests requests are rejected.
+func (s *rpcServer) postClientBatch(w http.ResponseWriter, batch []byte,
+	user *rpcUser, walletName string) {
+	var rawReqs []json.RawMessage
+	err := json.Unmarshal(batch, &rawReqs)
+	if err != nil || len(rawReqs) == 0 {
+		resp, err := dcrjson.MarshalResponse(nil, nil,
+			dcrjson.ErrRPCInvalidRequest)
+		if err != nil {
+			rpcsLog.Errorf("Unable to marshal response: %v", err)
+			http.Error(w, "500 Internal Server Error",
+				http.StatusInternalServerError)
+			return
+		}
+		_, err = w.Write(resp)
+		if err != nil {
+			rpcsLog.Warnf("Cannot write invalid request request to "+
+				"client: %v", err)
+		}
+		return
+	}
+	if len(rawReqs) > maxBatchRequests {
+		resp, err := dcrjson.MarshalResponse(nil, nil, &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCInvalidRequest.Code,
+			Message: fmt.Sprintf("batch of %d requests exceeds the "+
+				"limit of %d", len(rawReqs), maxBatchRequests),
+		})
+		if err != nil {
+			rpcsLog.Errorf("Unable to marshal response: %v", err)
+			http.Error(w, "500 Internal Server Error",
+				http.StatusInternalServerError)
+			return
+		}
+		_, err = w.Write(resp)
+		if err != nil {
+			rpcsLog.Warnf("Cannot write invalid request request to "+
+				"client: %v", err)
+		}
+		return
+	}
+
+	responses := make([]json.RawMessage, len(rawReqs))
+	respond := func(i int, req *dcrjson.Request) {
+		var res interface{}
+		var jsonErr *dcrjson.RPCError
+		if req.Method == "authenticate" {
+			jsonErr = dcrjson.ErrRPCInvalidRequest
+		} else {
+			res, jsonErr = s.handlePostRequest(req, user, walletName)
+		}
+		mresp, err := dcrjson.MarshalResponse(req.ID, res, jsonErr)
+		if err != nil {
+			rpcsLog.Errorf("Unable to marshal response: %v", err)
+			mresp, _ = dcrjson.MarshalResponse(req.ID, nil,
+				&dcrjson.RPCError{
+					Code:    dcrjson.ErrRPCInternal.Code,
+					Message: "Unexpected error marshalling result",
+				})
+		}
+		responses[i] = mresp
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentBatchRequests)
+	for i, rawReq := range rawReqs {
+		req := new(dcrjson.Request)
+		err := json.Unmarshal(rawReq, req)
+		if err != nil {
+			responses[i], _ = dcrjson.MarshalResponse(nil, nil,
+				dcrjson.ErrRPCInvalidRequest)
+			continue
+		}
+
+		if concurrentBatchRequest(req) {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(i int) {
+				respond(i, req)
+				<-sem
+				wg.Done()
+			}(i)
+			continue
+		}
+
+		wg.Wait()
+		respond(i, req)
+	}
+	wg.Wait()
+
+	mresp, err := json.Marshal(responses)
+	if err != nil {
+		rpcsLog.Errorf("Unable to marshal response: %v", err)
 		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 	_, err = w.Write(mresp)
 	if err != nil {
-		log.Warnf("Unable to respond to client: %v", err)
+		rpcsLog.Warnf("Unable to respond to client: %v", err)
 	}
 }
 
@@ -982,6 +1517,10 @@ type (
 		// This returns a slice only because some of these types result
 		// in multpile client notifications.
 		notificationCmds(w *wallet.Wallet) []interface{}
+
+		// notificationType returns the group of notifications clients
+		// must subscribe to for the notification to be sent to them.
+		notificationType() wsNotificationType
 	}
 
 	blockConnected    wtxmgr.BlockMeta
@@ -991,6 +1530,14 @@ type (
 	ticketPurchased   wstakemgr.StakeNotification
 	voteCreated       wstakemgr.StakeNotification
 	revocationCreated wstakemgr.StakeNotification
+	ticketOutcome     wallet.TicketOutcome
+	ticketWarning     wallet.TicketWarning
+
+	unexpectedSpend wallet.UnexpectedSpend
+
+	rescanProgress wallet.RescanWalletProgress
+
+	jobStatus walletjson.JobStatusResult
 
 	relevantTx chain.RelevantTx
 
@@ -1002,6 +1549,46 @@ type (
 	daemonConnected bool
 )
 
+func (blockConnected) notificationType() wsNotificationType {
+	return wsNtfnBlockConnected
+}
+func (blockDisconnected) notificationType() wsNotificationType {
+	return wsNtfnBlockConnected
+}
+func (ticketPurchased) notificationType() wsNotificationType {
+	return wsNtfnTickets
+}
+func (voteCreated) notificationType() wsNotificationType {
+	return wsNtfnTickets
+}
+func (revocationCreated) notificationType() wsNotificationType {
+	return wsNtfnTickets
+}
+func (ticketOutcome) notificationType() wsNotificationType {
+	return wsNtfnTickets
+}
+func (ticketWarning) notificationType() wsNotificationType {
+	return wsNtfnTickets
+}
+func (rescanProgress) notificationType() wsNotificationType {
+	return wsNtfnRescanProgress
+}
+func (jobStatus) notificationType() wsNotificationType {
+	return wsNtfnJobs
+}
+func (relevantTx) notificationType() wsNotificationType {
+	return wsNtfnNewTransactions
+}
+func (confirmedBalance) notificationType() wsNotificationType {
+	return wsNtfnBalance
+}
+func (unconfirmedBalance) notificationType() wsNotificationType {
+	return wsNtfnBalance
+}
+func (managerLocked) notificationType() wsNotificationType   { return 0 }
+func (daemonConnected) notificationType() wsNotificationType { return 0 }
+func (unexpectedSpend) notificationType() wsNotificationType { return 0 }
+
 func (b ticketPurchased) notificationCmds(w *wallet.Wallet) []interface{} {
 	n := dcrjson.NewTicketPurchasedNtfn(b.TxHash.String(), b.Amount)
 	return []interface{}{n}
@@ -1024,6 +1611,62 @@ func (b revocationCreated) notificationCmds(w *wallet.Wallet) []interface{} {
 	return []interface{}{n}
 }
 
+func (o ticketOutcome) notificationCmds(w *wallet.Wallet) []interface{} {
+	var txHash string
+	if o.TxHash != (chainhash.Hash{}) {
+		txHash = o.TxHash.String()
+	}
+	n := walletjson.NewTicketOutcomeNtfn(o.Ticket.String(),
+		o.Status.String(), o.Height, o.Amount.ToCoin(), txHash)
+	return []interface{}{n}
+}
+
+func (t ticketWarning) notificationCmds(w *wallet.Wallet) []interface{} {
+	var ticket string
+	if t.Kind == wallet.TicketWarningExpiring {
+		ticket = t.Ticket.String()
+	}
+	n := walletjson.NewTicketWarningNtfn(t.Kind.String(), ticket, t.Height,
+		t.Deadline, t.Remaining, t.Price.ToCoin())
+	return []interface{}{n}
+}
+
+// newUnexpectedSpendNtfn returns the unexpectedspend notification describing
+// an unexpected spend of wallet funds.
+func newUnexpectedSpendNtfn(
+	spend *wallet.UnexpectedSpend) *walletjson.UnexpectedSpendNtfn {
+	inputs := make([]string, len(spend.Inputs))
+	for i, op := range spend.Inputs {
+		inputs[i] = fmt.Sprintf("%v:%d", op.Hash, op.Index)
+	}
+	return walletjson.NewUnexpectedSpendNtfn(spend.TxHash.String(), inputs,
+		spend.Amount.ToCoin(), spend.Height)
+}
+
+func (u unexpectedSpend) notificationCmds(w *wallet.Wallet) []interface{} {
+	spend := wallet.UnexpectedSpend(u)
+	return []interface{}{newUnexpectedSpendNtfn(&spend)}
+}
+
+func (p rescanProgress) notificationCmds(w *wallet.Wallet) []interface{} {
+	var hash, errStr string
+	if p.Hash != (chainhash.Hash{}) {
+		hash = p.Hash.String()
+	}
+	if p.Err != nil {
+		errStr = p.Err.Error()
+	}
+	n := walletjson.NewRescanWalletProgressNtfn(p.StartHeight, p.Height,
+		hash, p.Transactions, p.Done, errStr)
+	return []interface{}{n}
+}
+
+func (j jobStatus) notificationCmds(w *wallet.Wallet) []interface{} {
+	n := walletjson.NewJobStatusNtfn(j.JobID, j.Method, j.Status, j.Result,
+		j.Error)
+	return []interface{}{n}
+}
+
 func (b blockConnected) notificationCmds(w *wallet.Wallet) []interface{} {
 	n := dcrjson.NewBlockConnectedNtfn(b.Hash.String(), b.Height, b.Time.Unix(),
 		b.VoteBits)
@@ -1045,12 +1688,12 @@ func (t relevantTx) notificationCmds(w *wallet.Wallet) []interface{} {
 	}
 	details, err := w.TxStore.UniqueTxDetails(&t.TxRecord.Hash, block)
 	if err != nil {
-		log.Errorf("Cannot fetch transaction details for "+
+		rpcsLog.Errorf("Cannot fetch transaction details for "+
 			"client notification: %v", err)
 		return nil
 	}
 	if details == nil {
-		log.Errorf("No details found for client transaction notification")
+		rpcsLog.Errorf("No details found for client transaction notification")
 		return nil
 	}
 
@@ -1086,10 +1729,19 @@ func (b daemonConnected) notificationCmds(w *wallet.Wallet) []interface{} {
 }
 
 func (s *rpcServer) notificationListener() {
+	var webhooks *webhookNotifier
+	if len(cfg.WebhookURLs) != 0 {
+		webhooks = newWebhookNotifier(cfg.WebhookURLs,
+			cfg.WebhookSecret, cfg.WebhookConfs)
+	}
+
 out:
 	for {
 		select {
 		case n := <-s.connectedBlocks:
+			if webhooks != nil {
+				go webhooks.connectedBlock(s.wallet)
+			}
 			s.enqueueNotification <- blockConnected(n)
 		case n := <-s.disconnectedBlocks:
 			s.enqueueNotification <- blockDisconnected(n)
@@ -1099,7 +1751,26 @@ out:
 			s.enqueueNotification <- voteCreated(n)
 		case n := <-s.revocationsCreated:
 			s.enqueueNotification <- revocationCreated(n)
+		case n := <-s.ticketOutcomes:
+			if webhooks != nil {
+				go webhooks.ticketOutcome(&n)
+			}
+			s.enqueueNotification <- ticketOutcome(n)
+		case n := <-s.ticketWarnings:
+			s.enqueueNotification <- ticketWarning(n)
+		case n := <-s.unexpectedSpends:
+			if cfg.SpendAlertURL != "" {
+				go postSpendAlert(cfg.SpendAlertURL, &n)
+			}
+			s.enqueueNotification <- unexpectedSpend(n)
+		case n := <-s.rescanProgress:
+			s.enqueueNotification <- rescanProgress(n)
+		case n := <-s.finishedJobs:
+			s.enqueueNotification <- jobStatus(n)
 		case n := <-s.relevantTxs:
+			if webhooks != nil {
+				go webhooks.relevantTx(s.wallet, n)
+			}
 			s.enqueueNotification <- relevantTx(n)
 		case n := <-s.managerLocked:
 			s.enqueueNotification <- managerLocked(n)
@@ -1113,60 +1784,84 @@ out:
 		case <-s.registerWalletNtfns:
 			connectedBlocks, err := s.wallet.ListenConnectedBlocks()
 			if err != nil {
-				log.Errorf("Could not register for new "+
+				rpcsLog.Errorf("Could not register for new "+
 					"connected block notifications: %v",
 					err)
 				continue
 			}
 			disconnectedBlocks, err := s.wallet.ListenDisconnectedBlocks()
 			if err != nil {
-				log.Errorf("Could not register for new "+
+				rpcsLog.Errorf("Could not register for new "+
 					"disconnected block notifications: %v",
 					err)
 				continue
 			}
 			ticketsPurchased, err := s.wallet.ListenTicketsPurchased()
 			if err != nil {
-				log.Errorf("Could not register for newly created "+
+				rpcsLog.Errorf("Could not register for newly created "+
 					"tickets notifications: %v",
 					err)
 				continue
 			}
 			votesCreated, err := s.wallet.ListenVotesCreated()
 			if err != nil {
-				log.Errorf("Could not register for newly created "+
+				rpcsLog.Errorf("Could not register for newly created "+
 					"votes notifications: %v",
 					err)
 				continue
 			}
 			revocationsCreated, err := s.wallet.ListenRevocationsCreated()
 			if err != nil {
-				log.Errorf("Could not register for newly created "+
+				rpcsLog.Errorf("Could not register for newly created "+
 					"revocations notifications: %v",
 					err)
 				continue
 			}
+			ticketOutcomes, err := s.wallet.ListenTicketOutcomes()
+			if err != nil {
+				rpcsLog.Errorf("Could not register for ticket "+
+					"outcome notifications: %v", err)
+				continue
+			}
+			ticketWarnings, err := s.wallet.ListenTicketWarnings()
+			if err != nil {
+				rpcsLog.Errorf("Could not register for ticket "+
+					"warning notifications: %v", err)
+				continue
+			}
+			unexpectedSpends, err := s.wallet.ListenUnexpectedSpends()
+			if err != nil {
+				rpcsLog.Errorf("Could not register for unexpected "+
+					"spend notifications: %v", err)
+				continue
+			}
+			rescanProgress, err := s.wallet.ListenRescanWalletProgress()
+			if err != nil {
+				rpcsLog.Errorf("Could not register for rescan "+
+					"progress notifications: %v", err)
+				continue
+			}
 			relevantTxs, err := s.wallet.ListenRelevantTxs()
 			if err != nil {
-				log.Errorf("Could not register for new relevant "+
+				rpcsLog.Errorf("Could not register for new relevant "+
 					"transaction notifications: %v", err)
 				continue
 			}
 			managerLocked, err := s.wallet.ListenLockStatus()
 			if err != nil {
-				log.Errorf("Could not register for manager "+
+				rpcsLog.Errorf("Could not register for manager "+
 					"lock state changes: %v", err)
 				continue
 			}
 			confirmedBalance, err := s.wallet.ListenConfirmedBalance()
 			if err != nil {
-				log.Errorf("Could not register for confirmed "+
+				rpcsLog.Errorf("Could not register for confirmed "+
 					"balance changes: %v", err)
 				continue
 			}
 			unconfirmedBalance, err := s.wallet.ListenUnconfirmedBalance()
 			if err != nil {
-				log.Errorf("Could not register for unconfirmed "+
+				rpcsLog.Errorf("Could not register for unconfirmed "+
 					"balance changes: %v", err)
 				continue
 			}
@@ -1175,6 +1870,10 @@ out:
 			s.ticketsPurchased = ticketsPurchased
 			s.votesCreated = votesCreated
 			s.revocationsCreated = revocationsCreated
+			s.ticketOutcomes = ticketOutcomes
+			s.ticketWarnings = ticketWarnings
+			s.unexpectedSpends = unexpectedSpends
+			s.rescanProgress = rescanProgress
 			s.relevantTxs = relevantTxs
 			s.managerLocked = managerLocked
 			s.confirmedBalance = confirmedBalance
@@ -1197,6 +1896,10 @@ func (s *rpcServer) drainNotifications() {
 		case <-s.ticketsPurchased:
 		case <-s.votesCreated:
 		case <-s.revocationsCreated:
+		case <-s.ticketOutcomes:
+		case <-s.ticketWarnings:
+		case <-s.unexpectedSpends:
+		case <-s.rescanProgress:
 		case <-s.relevantTxs:
 		case <-s.managerLocked:
 		case <-s.confirmedBalance:
@@ -1274,6 +1977,7 @@ out:
 				continue
 			}
 
+			ntfnType := nmsg.notificationType()
 			ns := nmsg.notificationCmds(s.wallet)
 			for _, n := range ns {
 				mn, err := dcrjson.MarshalCmd(nil, n)
@@ -1283,12 +1987,26 @@ out:
 					panic(err)
 				}
 				for _, c := range clients {
+					if !c.wantsNotification(ntfnType) {
+						continue
+					}
 					if err := c.send(mn); err != nil {
 						delete(clients, c.quit)
 					}
 				}
 			}
 
+			// Each connected block may confirm transactions
+			// watched by clients.
+			if _, ok := nmsg.(blockConnected); ok {
+				for _, c := range clients {
+					err := s.sendReachedConfirmations(c)
+					if err != nil {
+						delete(clients, c.quit)
+					}
+				}
+			}
+
 		case <-s.quit:
 			break out
 		}
@@ -1321,6 +2039,7 @@ var rpcHandlers = map[string]struct {
 	// Reference implementation wallet methods (implemented)
 	"addmultisigaddress":     {handler: AddMultiSigAddress},
 	"createmultisig":         {handler: CreateMultiSig},
+	"decoderawtransaction":   {handler: DecodeRawTransaction},
 	"dumpprivkey":            {handler: DumpPrivKey},
 	"getaccount":             {handler: GetAccount},
 	"getaccountaddress":      {handler: GetAccountAddress},
@@ -1353,6 +2072,7 @@ var rpcHandlers = map[string]struct {
 	"listunspent":            {handler: ListUnspent},
 	"lockunspent":            {handler: LockUnspent},
 	"purchaseticket":         {handler: PurchaseTicket},
+	"rescanwallet":           {handler: RescanWallet},
 	"sendfrom":               {handler: SendFrom},
 	"sendmany":               {handler: SendMany},
 	"sendtoaddress":          {handler: SendToAddress},
@@ -1389,20 +2109,138 @@ var rpcHandlers = map[string]struct {
 	"setaccount":    {handler: Unsupported, noHelp: true},
 
 	// Extensions to the reference client JSON-RPC API
-	"createnewaccount": {handler: CreateNewAccount},
-	"getbestblock":     {handler: GetBestBlock},
+	"approvesend":          {handler: ApproveSend},
+	"cancelrescan":         {handler: CancelRescan},
+	"createmultisigwallet": {handler: CreateMultisigWallet},
+	"createpaymenturi":     {handler: CreatePaymentURI},
+	"createnewaccount":     {handler: CreateNewAccount},
+	"debuglevel":           {handler: DebugLevel},
+	"decodeaddress":        {handler: DecodeAddress},
+	"decodepaymenturi":     {handler: DecodePaymentURI},
+	"describescript":       {handler: DescribeScript},
+	"estimatestakediff":    {handler: EstimateStakeDiff},
+	"exportaccounthistory": {handler: ExportAccountHistory},
+	"exportcapitalgains":   {handler: ExportCapitalGains},
+	"exportseedbackup":     {handler: ExportSeedBackup},
+	"exportsigninglog":     {handler: ExportSigningLog},
+	"getaddresspath":       {handler: GetAddressPath},
+	"getapiinfo":           {handler: GetAPIInfo},
+	"getbackendstate":      {handler: GetBackendState},
+	"getbalancehistory":    {handler: GetBalanceHistory},
+	"getbestblock":         {handler: GetBestBlock},
+	"getblockvotebits":     {handler: GetBlockVoteBits},
+	"getcreditorigin":      {handler: GetCreditOrigin},
+	"getfeesreport":        {handler: GetFeesReport},
+	"getimportedbalance":   {handler: GetImportedBalance},
+	"getlockinfo":          {handler: GetLockInfo},
+	"getstakeinfo":         {handler: GetStakeInfo},
+	"getticketbuyerlog":    {handler: GetTicketBuyerLog},
+	"getticketpoolshare":   {handler: GetTicketPoolShare},
+	"importpubkey":         {handler: ImportPubKey},
+	"listaddressusage":     {handler: ListAddressUsage},
+	"listpendingsends":     {handler: ListPendingSends},
+	"listretiredaddresses": {handler: ListRetiredAddresses},
+	"listvsptickets":       {handler: ListVSPTickets},
+	"purchasevsptickets":   {handler: PurchaseVSPTickets},
+	"registervsp":          {handler: RegisterVSP},
+	"rejectsend":           {handler: RejectSend},
+	"rotateimportedkey":    {handler: RotateImportedKey},
 
 	// This was an extension but the reference implementation added it as
 	// well, but with a different API (no account parameter).  It's listed
 	// here because it hasn't been update to use the reference
 	// implemenation's API.
 	"getunconfirmedbalance":   {handler: GetUnconfirmedBalance},
+	"listaddresstickets":      {handler: ListAddressTickets},
 	"listaddresstransactions": {handler: ListAddressTransactions},
 	"listalltransactions":     {handler: ListAllTransactions},
 	"renameaccount":           {handler: RenameAccount},
+	"setbirthday":             {handler: SetBirthday},
+	"setcreditorigin":         {handler: SetCreditOrigin},
+	"setunlocktimeout":        {handler: SetUnlockTimeout},
 	"walletislocked":          {handler: WalletIsLocked},
 }
 
+// rpcOfflineMethods is the set of wallet methods which do not require a chain
+// server and may be handled while the wallet is offline.  All other wallet
+// methods return an error until a chain server connects.
+var rpcOfflineMethods = map[string]struct{}{
+	"approvesend":             {},
+	"createmultisig":          {},
+	"createnewaccount":        {},
+	"debuglevel":              {},
+	"createpaymenturi":        {},
+	"decodeaddress":           {},
+	"decodepaymenturi":        {},
+	"decoderawtransaction":    {},
+	"describescript":          {},
+	"dumpprivkey":             {},
+	"exportaccounthistory":    {},
+	"exportcapitalgains":      {},
+	"exportseedbackup":        {},
+	"exportsigninglog":        {},
+	"getaccount":              {},
+	"getaccountaddress":       {},
+	"getaddressesbyaccount":   {},
+	"getaddresspath":          {},
+	"getapiinfo":              {},
+	"getbackendstate":         {},
+	"getbalancehistory":       {},
+	"getblockvotebits":        {},
+	"getbalance":              {},
+	"getbestblock":            {},
+	"getbestblockhash":        {},
+	"getblockcount":           {},
+	"getcreditorigin":         {},
+	"getfeesreport":           {},
+	"getimportedbalance":      {},
+	"getlockinfo":             {},
+	"getmasterpubkey":         {},
+	"getmultisigoutinfo":      {},
+	"getnewaddress":           {},
+	"getrawchangeaddress":     {},
+	"getreceivedbyaccount":    {},
+	"getreceivedbyaddress":    {},
+	"getseed":                 {},
+	"getstakeinfo":            {},
+	"getticketbuyerlog":       {},
+	"getticketmaxprice":       {},
+	"gettransaction":          {},
+	"getunconfirmedbalance":   {},
+	"getwalletfee":            {},
+	"help":                    {},
+	"keypoolrefill":           {},
+	"listaccounts":            {},
+	"listaddresstransactions": {},
+	"listaddressusage":        {},
+	"listalltransactions":     {},
+	"listlockunspent":         {},
+	"listpendingsends":        {},
+	"listretiredaddresses":    {},
+	"listreceivedbyaccount":   {},
+	"listreceivedbyaddress":   {},
+	"listtransactions":        {},
+	"listunspent":             {},
+	"listvsptickets":          {},
+	"rejectsend":              {},
+	"renameaccount":           {},
+	"rotateimportedkey":       {},
+	"sendfrom":                {},
+	"sendmany":                {},
+	"sendtoaddress":           {},
+	"setticketmaxprice":       {},
+	"settxfee":                {},
+	"setcreditorigin":         {},
+	"setunlocktimeout":        {},
+	"signmessage":             {},
+	"validateaddress":         {},
+	"verifymessage":           {},
+	"walletislocked":          {},
+	"walletlock":              {},
+	"walletpassphrase":        {},
+	"walletpassphrasechange":  {},
+}
+
 // Unimplemented handles an unimplemented RPC request with the
 // appropiate error.
 func Unimplemented(*wallet.Wallet, *chain.Client,
@@ -1430,6 +2268,13 @@ func UnloadedWallet(*wallet.Wallet, *chain.Client,
 	return nil, &ErrUnloadedWallet
 }
 
+// OfflineWallet is the handler func that is run when a wallet RPC requiring a
+// chain server is requested while the wallet is offline.
+func OfflineWallet(*wallet.Wallet, *chain.Client,
+	interface{}) (interface{}, error) {
+	return nil, &ErrOfflineWallet
+}
+
 // NoEncryptedWallet is the handler func that is run when no wallet has been
 // created by the user yet.
 // loaded yet when trying to execute a wallet RPC.
@@ -1470,6 +2315,24 @@ func unloadedWalletHandlerFunc(method string) (f requestHandler, ok bool) {
 	return
 }
 
+// offlineWalletHandlerFunc looks up a request handler func for the passed
+// method when the wallet is running without a chain server.  Wallet methods
+// which require a chain server are given a specialized handler func to return
+// errors for the offline wallet.  If ok is false, the function is invalid and
+// should be passed through instead.
+func offlineWalletHandlerFunc(method string) (f requestHandler, ok bool) {
+	handlerData, ok := rpcHandlers[method]
+	if !ok {
+		return
+	}
+	if _, offline := rpcOfflineMethods[method]; offline {
+		f = handlerData.handler
+	} else {
+		f = OfflineWallet
+	}
+	return
+}
+
 // missingWalletHandlerFunc looks up whether a request requires a wallet, and
 // if so, returns a specialized handler func to return errors for no wallets
 // being created yet with the createencryptedwallet RPC.  If ok is false, the
@@ -1654,6 +2517,109 @@ func CreateMultiSig(w *wallet.Wallet, chainSvr *chain.Client,
 	}, nil
 }
 
+// maxMultisigWalletScripts is the maximum number of scripts which may be
+// created by a single createmultisigwallet request.
+const maxMultisigWalletScripts = 1000
+
+// parseMultisigCosigner parses the key of a multisig wallet cosigner, which
+// is either an account extended public key or a hex-encoded public key.
+func parseMultisigCosigner(key string) (wallet.MultisigCosigner, error) {
+	xpub, err := hdkeychain.NewKeyFromString(key)
+	if err == nil {
+		if xpub.IsPrivate() {
+			return wallet.MultisigCosigner{}, InvalidParameterError{
+				fmt.Errorf("private keys not accepted: %v", key),
+			}
+		}
+		return wallet.MultisigCosigner{XPub: xpub}, nil
+	}
+
+	addr, err := decodeAddress(key, activeNet.Params)
+	if err != nil {
+		return wallet.MultisigCosigner{}, err
+	}
+	pubKey, ok := addr.(*dcrutil.AddressSecpPubKey)
+	if !ok {
+		return wallet.MultisigCosigner{}, InvalidParameterError{
+			fmt.Errorf("cosigner key %v is not a public key or "+
+				"extended public key", key),
+		}
+	}
+	return wallet.MultisigCosigner{PubKey: pubKey}, nil
+}
+
+// CreateMultisigWallet handles a createmultisigwallet request by creating the
+// redeem scripts of a multisig wallet from the keys of its cosigners,
+// importing them, and watching their addresses.  The reply is a recovery
+// bundle each cosigner should store to recreate the scripts.
+func CreateMultisigWallet(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.CreateMultisigWalletCmd)
+
+	if *cmd.Count == 0 || *cmd.Count > maxMultisigWalletScripts {
+		return nil, InvalidParameterError{
+			fmt.Errorf("count must be between 1 and %d",
+				maxMultisigWalletScripts),
+		}
+	}
+	cosigners := make([]wallet.MultisigCosigner, len(cmd.Keys))
+	for i, key := range cmd.Keys {
+		c, err := parseMultisigCosigner(key)
+		if err != nil {
+			return nil, err
+		}
+		cosigners[i] = c
+	}
+
+	mw, err := w.CreateMultisigWallet(cmd.NRequired, cosigners, *cmd.Count)
+	switch {
+	case err == wallet.ErrNoCosigners, err == wallet.ErrMultisigRequiredSigs:
+		return nil, InvalidParameterError{err}
+	case waddrmgr.IsError(err, waddrmgr.ErrLocked):
+		return nil, &ErrWalletUnlockNeeded
+	case err != nil:
+		return nil, err
+	}
+
+	result := &walletjson.CreateMultisigWalletResult{
+		NRequired: cmd.NRequired,
+		Keys:      cmd.Keys,
+		Count:     uint32(len(mw.Scripts)),
+		Branch:    waddrmgr.ExternalBranch,
+		Network:   activeNet.Params.Name,
+		Height:    mw.Height,
+		Scripts:   make([]walletjson.MultisigWalletScript, len(mw.Scripts)),
+	}
+	for i, script := range mw.Scripts {
+		result.Scripts[i] = walletjson.MultisigWalletScript{
+			Index:        script.Index,
+			Address:      script.Address.EncodeAddress(),
+			RedeemScript: hex.EncodeToString(script.RedeemScript),
+		}
+	}
+	return result, nil
+}
+
+// DebugLevel handles a debuglevel request by changing the logging level of all
+// subsystems, or of the subsystems listed in the level spec, while the wallet
+// is running.  The level spec "show" returns the supported subsystems instead.
+func DebugLevel(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*dcrjson.DebugLevelCmd)
+
+	if cmd.LevelSpec == "show" {
+		return fmt.Sprintf("Supported subsystems %v",
+			supportedSubsystems()), nil
+	}
+
+	err := parseAndSetDebugLevels(cmd.LevelSpec)
+	if err != nil {
+		return nil, InvalidParameterError{err}
+	}
+	rpcsLog.Infof("Debug level changed to %s", cmd.LevelSpec)
+	return "Done.", nil
+}
+
 // DumpPrivKey handles a dumpprivkey request with the private key
 // for a single address, or an appropiate error if the wallet
 // is locked.
@@ -1709,6 +2675,28 @@ func GetAddressesByAccount(w *wallet.Wallet, chainSvr *chain.Client,
 	return addrStrs, err
 }
 
+// parseBalanceType returns the balance type flag named by the balancetype
+// parameter of the getbalance and getimportedbalance requests.  The spendable
+// balance is returned when the parameter is omitted.
+func parseBalanceType(balanceType *string) (wtxmgr.BehaviorFlags, error) {
+	if balanceType == nil {
+		return wtxmgr.BFBalanceSpendable, nil
+	}
+	switch *balanceType {
+	case "spendable":
+		return wtxmgr.BFBalanceSpendable, nil
+	case "locked":
+		return wtxmgr.BFBalanceLockedStake, nil
+	case "all":
+		return wtxmgr.BFBalanceAll, nil
+	case "fullscan":
+		return wtxmgr.BFBalanceFullScan, nil
+	default:
+		return 0, fmt.Errorf("unknown balance type '%v', please use "+
+			"spendable, locked, all, or fullscan", *balanceType)
+	}
+}
+
 // GetBalance handles a getbalance request by returning the balance for an
 // account (wallet), or an error if the requested account does not
 // exist.
@@ -1717,37 +2705,48 @@ func GetBalance(w *wallet.Wallet, chainSvr *chain.Client,
 	cmd := icmd.(*dcrjson.GetBalanceCmd)
 
 	var balance dcrutil.Amount
-	var err error
 	accountName := "default"
 	if cmd.Account != nil {
 		accountName = *cmd.Account
 	}
-	balType := wtxmgr.BFBalanceSpendable
-	if cmd.BalanceType != nil {
-		switch *cmd.BalanceType {
-		case "spendable":
-			balType = wtxmgr.BFBalanceSpendable
-		case "locked":
-			balType = wtxmgr.BFBalanceLockedStake
-		case "all":
-			balType = wtxmgr.BFBalanceAll
-		case "fullscan":
-			balType = wtxmgr.BFBalanceFullScan
-		default:
-			return nil, fmt.Errorf("unknown balance type '%v', please use "+
-				"spendable, locked, all, or fullscan", *cmd.BalanceType)
-		}
+	balType, err := parseBalanceType(cmd.BalanceType)
+	if err != nil {
+		return nil, err
 	}
-	if accountName == "default" {
+	switch accountName {
+	case "default":
 		balance, err = w.CalculateBalance(int32(*cmd.MinConf),
 			balType)
-	} else {
+	case "*":
+		// Report the balance of every account, keyed by account name.
+		balances, err := w.CalculateAccountBalances(int32(*cmd.MinConf),
+			balType)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]float64)
+		err = w.Manager.ForEachAccount(func(account uint32) error {
+			name, err := w.Manager.AccountName(account)
+			if err != nil {
+				return err
+			}
+			result[name] = balances[account].ToCoin()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	default:
 		var account uint32
 		account, err = w.Manager.LookupAccount(accountName)
 		if err != nil {
 			return nil, err
 		}
-		balance, err = w.CalculateAccountBalance(account, int32(*cmd.MinConf))
+		var balances map[uint32]dcrutil.Amount
+		balances, err = w.CalculateAccountBalances(int32(*cmd.MinConf),
+			balType)
+		balance = balances[account]
 	}
 	if err != nil {
 		return nil, err
@@ -1767,6 +2766,196 @@ func GetBestBlock(w *wallet.Wallet, chainSvr *chain.Client,
 	return result, nil
 }
 
+// These constants define the version of the wallet JSON-RPC API and follow
+// the semantic versioning 2.0.0 spec (http://semver.org/).  The major version
+// is incremented for changes which break existing clients, and the minor
+// version for backwards compatible additions.
+const (
+	jsonrpcSemverMajor = 1
+	jsonrpcSemverMinor = 28
+	jsonrpcSemverPatch = 0
+)
+
+// jsonrpcCapabilities returns the optional features provided by the RPC
+// server and wallet, sorted by name.
+func jsonrpcCapabilities(w *wallet.Wallet) []string {
+	capabilities := []string{"accounthistory", "addresspaths",
+		"addressusage", "balancehistory", "batch", "birthday",
+		"capitalgains", "creditorigins", "decoderawtransaction",
+		"describescript", "fiatrates", "gaplimit", "importedbalance",
+		"importpubkey", "jobs", "keyrotation", "multisigwallet",
+		"multiwallet", "notifyconfirmations", "paymenturi",
+		"permissions", "poolshare", "rescanwallet", "seedbackup",
+		"sendapproval", "signinglog", "stakediffestimate",
+		"ticketbuyerlog", "votebits"}
+	if len(cfg.GRPCListeners) != 0 {
+		capabilities = append(capabilities, "grpc")
+	}
+	if cfg.StakePoolMode {
+		capabilities = append(capabilities, "stakepool")
+	}
+	if w.StakeMiningEnabled {
+		capabilities = append(capabilities, "ticketbuyer")
+	}
+	if cfg.VotingOnly {
+		capabilities = append(capabilities, "votingonly")
+	}
+	if cfg.VSPURL != "" {
+		capabilities = append(capabilities, "vspclient")
+	}
+	if w.Manager.WatchingOnly() {
+		capabilities = append(capabilities, "watchonly")
+	}
+	sort.Strings(capabilities)
+	return capabilities
+}
+
+// checkAPIVersion returns an error if a client written against the requested
+// API version may not use the server's API.  The requested version is
+// formatted as major.minor or major.minor.patch, and is supported when the
+// major versions match and the requested minor version is not newer.
+func checkAPIVersion(requested string) error {
+	parts := strings.Split(requested, ".")
+	if len(parts) != 2 && len(parts) != 3 {
+		return InvalidParameterError{
+			fmt.Errorf("malformed API version %q", requested),
+		}
+	}
+	var version [3]uint64
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return InvalidParameterError{
+				fmt.Errorf("malformed API version %q", requested),
+			}
+		}
+		version[i] = n
+	}
+	if version[0] != jsonrpcSemverMajor ||
+		version[1] > jsonrpcSemverMinor {
+		return &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("API version %s is not supported "+
+				"(server API version is %d.%d.%d)", requested,
+				jsonrpcSemverMajor, jsonrpcSemverMinor,
+				jsonrpcSemverPatch),
+		}
+	}
+	return nil
+}
+
+// GetAddressPath handles a getaddresspath request by returning the account,
+// branch, and index the key of a wallet address was derived at, so hardware
+// and remote signers holding the wallet seed can derive the key of the
+// address.  Imported addresses and scripts have no derivation path.
+func GetAddressPath(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetAddressPathCmd)
+
+	addr, err := decodeAddress(cmd.Address, w.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+	ma, err := w.Manager.Address(addr)
+	if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+		return nil, &ErrAddressNotInWallet
+	}
+	if err != nil {
+		return nil, err
+	}
+	path, ok := w.Manager.DerivationPath(ma)
+	if !ok {
+		return nil, &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCWallet,
+			Message: "address is imported and not derived from the " +
+				"wallet seed",
+		}
+	}
+	accountName, err := w.Manager.AccountName(path.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &walletjson.GetAddressPathResult{
+		Address:       ma.Address().EncodeAddress(),
+		Account:       accountName,
+		AccountNumber: path.Account,
+		Branch:        path.Branch,
+		Index:         path.Index,
+		Path:          path.String(),
+	}, nil
+}
+
+// GetAPIInfo handles a getapiinfo request by returning the version of the
+// wallet JSON-RPC API and the optional features provided by the server.  If
+// the client passes the API version it was written against, an error is
+// returned when that version is not supported.
+func GetAPIInfo(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetAPIInfoCmd)
+
+	if cmd.APIVersion != nil {
+		if err := checkAPIVersion(*cmd.APIVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	return &walletjson.GetAPIInfoResult{
+		Version: fmt.Sprintf("%d.%d.%d", jsonrpcSemverMajor,
+			jsonrpcSemverMinor, jsonrpcSemverPatch),
+		Major:        jsonrpcSemverMajor,
+		Minor:        jsonrpcSemverMinor,
+		Patch:        jsonrpcSemverPatch,
+		Capabilities: jsonrpcCapabilities(w),
+	}, nil
+}
+
+// GetBackendState handles a getbackendstate request by returning the state
+// of the connection to the chain server and how far the wallet is behind it.
+func GetBackendState(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	return backendState(w, chainSvr), nil
+}
+
+// backendState describes the state of the chain server connection and the
+// wallet's progress processing the chain server's notifications.  chainSvr
+// is nil when the wallet is offline.
+func backendState(w *wallet.Wallet,
+	chainSvr *chain.Client) *walletjson.GetBackendStateResult {
+	unixTime := func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Unix()
+	}
+
+	synced := w.Manager.SyncedTo()
+	result := &walletjson.GetBackendStateResult{
+		SyncedHeight:     synced.Height,
+		ChainSynced:      w.ChainSynced(),
+		RescanQueueDepth: w.RescanQueueDepth(),
+	}
+	if chainSvr == nil {
+		return result
+	}
+
+	stats := chainSvr.Stats()
+	result.Connected = stats.Connected
+	result.Endpoint = stats.Endpoint
+	result.ConnectedTime = unixTime(stats.ConnectedTime)
+	if !stats.BestBlockTime.IsZero() {
+		result.BestBlockHash = stats.BestBlock.Hash.String()
+		result.BestBlockHeight = stats.BestBlock.Height
+		result.BestBlockTime = stats.BestBlockTime.Unix()
+		if behind := stats.BestBlock.Height - synced.Height; behind > 0 {
+			result.BlocksBehind = behind
+		}
+	}
+	result.QueuedNotifications = stats.QueuedNotifications
+	result.LastHealthCheck = unixTime(stats.LastHealthCheck)
+	return result
+}
+
 // GetBestBlockHash handles a getbestblockhash request by returning the hash
 // of the most recently processed block.
 func GetBestBlockHash(w *wallet.Wallet, chainSvr *chain.Client,
@@ -1962,15 +3151,72 @@ func ImportPrivKey(w *wallet.Wallet, chainSvr *chain.Client,
 	return nil, err
 }
 
-// ImportScript imports a redeem script for a P2SH output.
+// ImportPubKey imports a hex encoded public key, such as one exported from a
+// hardware security module by dcrsigner, as a watching-only address of the
+// imported account, and returns the address.
+func ImportPubKey(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.ImportPubKeyCmd)
+
+	pubKey, err := hex.DecodeString(cmd.PubKey)
+	if err != nil {
+		return nil, &dcrjson.RPCError{
+			Code:    dcrjson.ErrRPCInvalidAddressOrKey,
+			Message: "public key decode failed: " + err.Error(),
+		}
+	}
+
+	addr, err := w.ImportPublicKey(pubKey, nil, *cmd.Rescan)
+	switch {
+	case waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress):
+		// Do not return duplicate key errors to the client, so keys
+		// may be imported again.  The key was parsed by the import.
+		addr, err = dcrutil.NewAddressPubKeyHash(dcrutil.Hash160(pubKey),
+			activeNet.Params, chainec.ECTypeSecp256k1)
+		if err != nil {
+			return nil, err
+		}
+	case waddrmgr.IsError(err, waddrmgr.ErrInvalidKeyType):
+		return nil, &dcrjson.RPCError{
+			Code:    dcrjson.ErrRPCInvalidAddressOrKey,
+			Message: err.Error(),
+		}
+	case err != nil:
+		return nil, err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// ImportScript imports a redeem script for a P2SH output.  If an options
+// object with the height the script was first seen at is passed, only the
+// blocks since that height are rescanned, and the reply reports the outputs
+// discovered by the rescan.
 func ImportScript(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, ext := unwrapExtendedCmd(icmd)
 	cmd := icmd.(*dcrjson.ImportScriptCmd)
 	rs, err := hex.DecodeString(cmd.Hex)
 	if err != nil {
 		return nil, err
 	}
 
+	if ext != nil {
+		var opts importScriptOptions
+		if err := json.Unmarshal(ext, &opts); err != nil {
+			return nil, InvalidParameterError{
+				fmt.Errorf("invalid importscript options: %v", err),
+			}
+		}
+		imported, err := w.ImportScript(rs, opts.FirstSeen)
+		if err != nil {
+			if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+				return nil, &ErrWalletUnlockNeeded
+			}
+			return nil, err
+		}
+		return marshalImportedScript(imported), nil
+	}
+
 	if len(rs) == 0 {
 		return nil, fmt.Errorf("passed empty script")
 	}
@@ -1992,7 +3238,7 @@ func ImportScript(w *wallet.Wallet, chainSvr *chain.Client,
 		case waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress):
 			return nil, err
 		case waddrmgr.IsError(err, waddrmgr.ErrLocked):
-			log.Debugf("failed to attempt script importation " +
+			rpcsLog.Debugf("failed to attempt script importation " +
 				"of incoming tx because addrmgr was locked")
 			return nil, err
 		default:
@@ -2017,7 +3263,7 @@ func ImportScript(w *wallet.Wallet, chainSvr *chain.Client,
 		// required to be read, so discard the return value.
 		_ = w.SubmitRescan(job)
 
-		log.Infof("Redeem script hash %x (address %v) successfully added.",
+		rpcsLog.Infof("Redeem script hash %x (address %v) successfully added.",
 			mscriptaddr.Address().ScriptAddress(),
 			mscriptaddr.Address().EncodeAddress())
 	}
@@ -2025,6 +3271,29 @@ func ImportScript(w *wallet.Wallet, chainSvr *chain.Client,
 	return nil, nil
 }
 
+// importScriptOptions is the options object which may be passed to
+// importscript after the script.
+type importScriptOptions struct {
+	FirstSeen int32 `json:"firstseen"`
+}
+
+// marshalImportedScript returns the importscript reply describing an
+// imported script and the outputs discovered for it.
+func marshalImportedScript(imported *wallet.ImportedScript) *walletjson.ImportScriptResult {
+	result := &walletjson.ImportScriptResult{
+		Address:         imported.Address.EncodeAddress(),
+		ScriptAddresses: make([]string, len(imported.ScriptAddresses)),
+		Outputs:         make([]dcrjson.ListUnspentResult, len(imported.Outputs)),
+	}
+	for i, addr := range imported.ScriptAddresses {
+		result.ScriptAddresses[i] = addr.EncodeAddress()
+	}
+	for i := range imported.Outputs {
+		result.Outputs[i] = imported.Outputs[i].ListUnspentResult
+	}
+	return result
+}
+
 // KeypoolRefill handles the keypoolrefill command. Since we handle the keypool
 // automatically this does nothing since refilling is never manually required.
 func KeypoolRefill(w *wallet.Wallet, chainSvr *chain.Client,
@@ -2092,6 +3361,128 @@ func RenameAccount(w *wallet.Wallet, chainSvr *chain.Client,
 	return nil, w.Manager.RenameAccount(account, cmd.NewAccount)
 }
 
+// RescanWallet handles a rescanwallet request by rescanning the main chain
+// from a height or time for transactions involving the wallet.  Progress is
+// sent to websocket clients as rescanwalletprogress notifications, and the
+// reply summarizes the rescan once it has completed or was cancelled.
+func RescanWallet(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.RescanWalletCmd)
+
+	var beginTime time.Time
+	if cmd.BeginTime != nil {
+		if *cmd.BeginTime <= 0 {
+			return nil, InvalidParameterError{
+				errors.New("begin time must be positive"),
+			}
+		}
+		beginTime = time.Unix(*cmd.BeginTime, 0)
+	}
+
+	progress, err := w.RescanWallet(*cmd.BeginHeight, beginTime)
+	return rescanWalletResult(progress, err)
+}
+
+// rescanWalletResult creates the reply to a request which performed a wallet
+// rescan from the final progress of the rescan and its error.  Cancelled
+// rescans are not errors.
+func rescanWalletResult(progress *wallet.RescanWalletProgress,
+	err error) (*walletjson.RescanWalletResult, error) {
+	if err != nil && err != wallet.ErrRescanCancelled {
+		return nil, err
+	}
+	result := &walletjson.RescanWalletResult{
+		StartHeight:  progress.StartHeight,
+		Height:       progress.Height,
+		Transactions: progress.Transactions,
+		Cancelled:    err == wallet.ErrRescanCancelled,
+	}
+	if progress.Hash != (chainhash.Hash{}) {
+		result.Hash = progress.Hash.String()
+	}
+	return result, nil
+}
+
+// RotateImportedKey handles a rotateimportedkey request by sweeping the funds
+// of an imported key or script to a new internal address of an account and
+// retiring the imported address, in response to a suspected exposure of the
+// key.  Unless confirmed, the sweep is only previewed, so the funds which
+// would be swept and the fee can be reviewed first.
+func RotateImportedKey(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
+	cmd := icmd.(*walletjson.RotateImportedKeyCmd)
+
+	addr, err := decodeAddress(cmd.Address, w.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+	account, err := w.Manager.LookupAccount(*cmd.Account)
+	if err != nil {
+		return nil, &ErrAccountNameNotFound
+	}
+
+	r, err := w.RotateImportedAddress(addr, account, *cmd.Confirm,
+		origin)
+	if err != nil {
+		switch {
+		case waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound):
+			return nil, &ErrAddressNotInWallet
+		case waddrmgr.IsError(err, waddrmgr.ErrLocked):
+			return nil, &ErrWalletUnlockNeeded
+		case err == wallet.ErrNotImportedAddress:
+			return nil, InvalidParameterError{err}
+		}
+		return nil, err
+	}
+
+	result := &walletjson.RotateImportedKeyResult{
+		Address:       r.Address.EncodeAddress(),
+		Inputs:        len(r.Inputs),
+		Amount:        r.Amount.ToCoin(),
+		Fee:           r.Fee.ToCoin(),
+		Skipped:       r.SkippedCount,
+		SkippedAmount: r.Skipped.ToCoin(),
+		Retired:       r.Retired,
+	}
+	if r.Destination != nil {
+		result.Destination = r.Destination.EncodeAddress()
+	}
+	if r.SweepTx != nil {
+		result.TxID = r.SweepTx.String()
+	}
+	return result, nil
+}
+
+// SetBirthday handles a setbirthday request by moving the wallet birthday
+// earlier and rescanning the blocks which were skipped because of the
+// previous birthday.  The reply summarizes the rescan like rescanwallet, and
+// the birthday is unchanged if the rescan was cancelled.
+func SetBirthday(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.SetBirthdayCmd)
+
+	if cmd.Birthday <= 0 {
+		return nil, InvalidParameterError{
+			errors.New("birthday must be positive"),
+		}
+	}
+
+	progress, err := w.MoveBirthday(time.Unix(cmd.Birthday, 0))
+	switch err {
+	case wallet.ErrBirthdayUnknown, wallet.ErrBirthdayNotEarlier:
+		return nil, InvalidParameterError{err}
+	}
+	return rescanWalletResult(progress, err)
+}
+
+// CancelRescan handles a cancelrescan request by stopping the running
+// rescanwallet rescan.
+func CancelRescan(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	return nil, w.CancelRescanWallet()
+}
+
 // GetMultisigOutInfo displays information about a given multisignature
 // output.
 func GetMultisigOutInfo(w *wallet.Wallet, chainSvr *chain.Client,
@@ -2167,11 +3558,12 @@ func GetMultisigOutInfo(w *wallet.Wallet, chainSvr *chain.Client,
 
 // GetNewAddress handles a getnewaddress request by returning a new
 // address for an account.  If the account does not exist an appropiate
-// error is returned.
-// TODO: Follow BIP 0044 and warn if number of unused addresses exceeds
-// the gap limit.
+// error is returned.  When an options object is passed, the address is of
+// the requested type and is checked against the gap limit of unused
+// addresses.
 func GetNewAddress(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, ext := unwrapExtendedCmd(icmd)
 	cmd := icmd.(*dcrjson.GetNewAddressCmd)
 
 	acctName := "default"
@@ -2183,6 +3575,10 @@ func GetNewAddress(w *wallet.Wallet, chainSvr *chain.Client,
 		return nil, err
 	}
 
+	if ext != nil {
+		return getNewAddressWithOptions(w, account, ext)
+	}
+
 	var addr dcrutil.Address
 	if acctName == "default" {
 		addr, err = w.GetNewAddressExternal()
@@ -2223,24 +3619,104 @@ func GetNewAddress(w *wallet.Wallet, chainSvr *chain.Client,
 	return addr.EncodeAddress(), nil
 }
 
-// GetRawChangeAddress handles a getrawchangeaddress request by creating
-// and returning a new change address for an account.
-//
-// Note: bitcoind allows specifying the account as an optional parameter,
-// but ignores the parameter.
-func GetRawChangeAddress(w *wallet.Wallet, chainSvr *chain.Client,
-	icmd interface{}) (interface{}, error) {
-	cmd := icmd.(*dcrjson.GetRawChangeAddressCmd)
+// getNewAddressOptions is the options object which may be passed to
+// getnewaddress after the verbose parameter.
+type getNewAddressOptions struct {
+	Type      string `json:"type"`
+	GapPolicy string `json:"gappolicy"`
+}
 
-	acctName := "default"
-	if cmd.Account != nil {
-		acctName = *cmd.Account
+// getNewAddressTypes maps the address types of the getnewaddress options
+// object to the wallet's address types.
+var getNewAddressTypes = map[string]wallet.AddressType{
+	"":      wallet.AddressTypeP2PKH,
+	"p2pkh": wallet.AddressTypeP2PKH,
+	"p2pk":  wallet.AddressTypeP2PK,
+	"p2sh":  wallet.AddressTypeP2SH,
+}
+
+// getNewAddressGapPolicies maps the gap policies of the getnewaddress
+// options object to the wallet's gap policies.
+var getNewAddressGapPolicies = map[string]wallet.GapPolicy{
+	"":       wallet.GapPolicyIgnore,
+	"ignore": wallet.GapPolicyIgnore,
+	"warn":   wallet.GapPolicyWarn,
+	"error":  wallet.GapPolicyError,
+}
+
+// getNewAddressWithOptions returns a new address of an account as described
+// by the getnewaddress options object ext.
+func getNewAddressWithOptions(w *wallet.Wallet, account uint32,
+	ext json.RawMessage) (interface{}, error) {
+	var opts getNewAddressOptions
+	if err := json.Unmarshal(ext, &opts); err != nil {
+		return nil, InvalidParameterError{
+			fmt.Errorf("invalid getnewaddress options: %v", err),
+		}
 	}
-	account, err := w.Manager.LookupAccount(acctName)
-	if err != nil {
-		return nil, err
+	addrType, ok := getNewAddressTypes[opts.Type]
+	if !ok {
+		return nil, InvalidParameterError{
+			fmt.Errorf("unknown address type %q", opts.Type),
+		}
 	}
-	addr, err := w.NewChangeAddress(account)
+	gapPolicy, ok := getNewAddressGapPolicies[opts.GapPolicy]
+	if !ok {
+		return nil, InvalidParameterError{
+			fmt.Errorf("unknown gap policy %q", opts.GapPolicy),
+		}
+	}
+
+	addr, err := w.NewAddressWithOptions(account, &wallet.NewAddressOptions{
+		Type:      addrType,
+		GapPolicy: gapPolicy,
+	})
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		return nil, err
+	}
+
+	if opts.Type == "" {
+		opts.Type = "p2pkh"
+	}
+	result := &walletjson.GetNewAddressResult{
+		Address:         addr.Address.EncodeAddress(),
+		Type:            opts.Type,
+		PubKey:          addr.PubKey.String(),
+		Gap:             addr.Gap,
+		ExceedsGapLimit: addr.ExceedsGapLimit,
+	}
+	if addr.Script != nil {
+		result.RedeemScript = hex.EncodeToString(addr.Script)
+	}
+	if addr.ExceedsGapLimit && gapPolicy == wallet.GapPolicyWarn {
+		result.Warning = fmt.Sprintf("the address follows %d unused "+
+			"addresses and may not be discovered when restoring the "+
+			"wallet from its seed", addr.Gap)
+	}
+	return result, nil
+}
+
+// GetRawChangeAddress handles a getrawchangeaddress request by creating
+// and returning a new change address for an account.
+//
+// Note: bitcoind allows specifying the account as an optional parameter,
+// but ignores the parameter.
+func GetRawChangeAddress(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*dcrjson.GetRawChangeAddressCmd)
+
+	acctName := "default"
+	if cmd.Account != nil {
+		acctName = *cmd.Account
+	}
+	account, err := w.Manager.LookupAccount(acctName)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := w.NewChangeAddress(account)
 	if err != nil {
 		return nil, err
 	}
@@ -2253,14 +3729,24 @@ func GetRawChangeAddress(w *wallet.Wallet, chainSvr *chain.Client,
 // the total amount received by addresses of an account.
 func GetReceivedByAccount(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, ext := unwrapExtendedCmd(icmd)
 	cmd := icmd.(*dcrjson.GetReceivedByAccountCmd)
 
+	if *cmd.MinConf < 0 {
+		return nil, ErrNeedPositiveMinconf
+	}
+	opts, err := parseReceivedByOptions(ext)
+	if err != nil {
+		return nil, err
+	}
+
 	account, err := w.Manager.LookupAccount(cmd.Account)
 	if err != nil {
 		return nil, err
 	}
 
-	bal, _, err := w.TotalReceivedForAccount(account, int32(*cmd.MinConf))
+	bal, _, err := w.TotalReceivedForAccount(account, int32(*cmd.MinConf),
+		opts.IncludeImmatureStake)
 	if err != nil {
 		return nil, err
 	}
@@ -2272,13 +3758,23 @@ func GetReceivedByAccount(w *wallet.Wallet, chainSvr *chain.Client,
 // the total amount received by a single address.
 func GetReceivedByAddress(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, ext := unwrapExtendedCmd(icmd)
 	cmd := icmd.(*dcrjson.GetReceivedByAddressCmd)
 
+	if *cmd.MinConf < 0 {
+		return nil, ErrNeedPositiveMinconf
+	}
+	opts, err := parseReceivedByOptions(ext)
+	if err != nil {
+		return nil, err
+	}
+
 	addr, err := decodeAddress(cmd.Address, activeNet.Params)
 	if err != nil {
 		return nil, err
 	}
-	total, err := w.TotalReceivedForAddr(addr, int32(*cmd.MinConf))
+	total, err := w.TotalReceivedForAddr(addr, int32(*cmd.MinConf),
+		opts.IncludeImmatureStake)
 	if err != nil {
 		return nil, err
 	}
@@ -2286,6 +3782,27 @@ func GetReceivedByAddress(w *wallet.Wallet, chainSvr *chain.Client,
 	return total.ToUnit(dcrutil.AmountCoin), nil
 }
 
+// receivedByOptions is the options object which may be passed to
+// getreceivedbyaccount and getreceivedbyaddress after the minconf parameter.
+type receivedByOptions struct {
+	IncludeImmatureStake bool `json:"includeimmaturestake"`
+}
+
+// parseReceivedByOptions decodes the optional extension parameter of the
+// getreceivedby* methods.
+func parseReceivedByOptions(ext json.RawMessage) (*receivedByOptions, error) {
+	var opts receivedByOptions
+	if ext == nil {
+		return &opts, nil
+	}
+	if err := json.Unmarshal(ext, &opts); err != nil {
+		return nil, InvalidParameterError{
+			fmt.Errorf("invalid options: %v", err),
+		}
+	}
+	return &opts, nil
+}
+
 // GetMasterPubkey handles a getmasterpubkey request by returning the wallet
 // master pubkey encoded as a string.
 func GetMasterPubkey(w *wallet.Wallet, chainSvr *chain.Client,
@@ -2400,8 +3917,18 @@ func GetTickets(w *wallet.Wallet, chainSvr *chain.Client,
 // a single transaction saved by wallet.
 func GetTransaction(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, ext := unwrapExtendedCmd(icmd)
 	cmd := icmd.(*dcrjson.GetTransactionCmd)
 
+	var opts getTransactionOptions
+	if ext != nil {
+		if err := json.Unmarshal(ext, &opts); err != nil {
+			return nil, InvalidParameterError{
+				fmt.Errorf("invalid gettransaction options: %v", err),
+			}
+		}
+	}
+
 	txSha, err := chainhash.NewHashFromStr(cmd.Txid)
 	if err != nil {
 		return nil, &dcrjson.RPCError{
@@ -2539,7 +4066,121 @@ func GetTransaction(w *wallet.Wallet, chainSvr *chain.Client,
 	}
 
 	ret.Amount = creditTotal.ToCoin()
-	return ret, nil
+	if !opts.Verbose {
+		return ret, nil
+	}
+
+	stakeDetails, err := w.StakeDetails(txSha)
+	if err != nil {
+		return nil, err
+	}
+	verboseRet := &getTransactionVerboseResult{GetTransactionResult: ret}
+	if details.Approval != wtxmgr.ApprovalNone {
+		verboseRet.Approval = details.Approval.String()
+	}
+	verboseRet.Ticket, verboseRet.Vote, verboseRet.Revocation =
+		stakeDetailsResults(stakeDetails)
+	for _, c := range details.ChangeIndexes {
+		if int(c.Output) >= len(details.MsgTx.TxOut) {
+			continue
+		}
+		var address string
+		txOut := details.MsgTx.TxOut[c.Output]
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.Version,
+			txOut.PkScript, activeNet.Params)
+		if err == nil && len(addrs) == 1 {
+			address = addrs[0].EncodeAddress()
+		}
+		accountName, err := w.Manager.AccountName(c.Account)
+		if err != nil {
+			accountName = ""
+		}
+		verboseRet.Change = append(verboseRet.Change,
+			walletjson.ChangeIndexResult{
+				Vout:    c.Output,
+				Address: address,
+				Account: accountName,
+				Index:   c.Index,
+			})
+	}
+	return verboseRet, nil
+}
+
+// getTransactionOptions is the options object which may be passed to
+// gettransaction after the includewatchonly parameter.
+type getTransactionOptions struct {
+	Verbose bool `json:"verbose"`
+}
+
+// getTransactionVerboseResult is a gettransaction result when the verbose
+// option is set.  Stake transactions include the details of the ticket, vote,
+// or revocation, and mined regular transactions include whether their block
+// was approved by the votes of the next block.  Transactions authored by the
+// wallet include the internal branch indexes of their change addresses.
+type getTransactionVerboseResult struct {
+	dcrjson.GetTransactionResult
+	Approval   string                              `json:"approval,omitempty"`
+	Ticket     *walletjson.TicketDetailsResult     `json:"ticket,omitempty"`
+	Vote       *walletjson.VoteDetailsResult       `json:"vote,omitempty"`
+	Revocation *walletjson.RevocationDetailsResult `json:"revocation,omitempty"`
+	Change     []walletjson.ChangeIndexResult      `json:"change,omitempty"`
+}
+
+// stakeDetailsResults returns the results describing the stake details of a
+// ticket, vote, or revocation.  Only the result matching the stake details is
+// set, and all are nil for regular transactions.
+func stakeDetailsResults(details *wallet.StakeDetails) (
+	*walletjson.TicketDetailsResult, *walletjson.VoteDetailsResult,
+	*walletjson.RevocationDetailsResult) {
+
+	switch {
+	case details == nil:
+		// Regular transactions have no stake details.
+	case details.Ticket != nil:
+		t := details.Ticket
+		res := &walletjson.TicketDetailsResult{
+			Price: t.Price.ToCoin(),
+			Commitments: make([]walletjson.TicketCommitmentResult, 0,
+				len(t.Commitments)),
+		}
+		for _, c := range t.Commitments {
+			cr := walletjson.TicketCommitmentResult{
+				Address:      c.Address.EncodeAddress(),
+				Amount:       c.Amount.ToCoin(),
+				Share:        c.Share,
+				Owned:        c.Owned,
+				ChangeAmount: c.ChangeAmount.ToCoin(),
+			}
+			if c.VoteFeeAllowed {
+				limit := c.VoteFeeLimit.ToCoin()
+				cr.VoteFeeLimit = &limit
+			}
+			if c.RevocationAllowed {
+				limit := c.RevocationFeeLimit.ToCoin()
+				cr.RevocationFeeLimit = &limit
+			}
+			if c.ChangeAddress != nil {
+				cr.ChangeAddress = c.ChangeAddress.EncodeAddress()
+			}
+			res.Commitments = append(res.Commitments, cr)
+		}
+		return res, nil, nil
+	case details.Vote != nil:
+		v := details.Vote
+		return nil, &walletjson.VoteDetailsResult{
+			Ticket:      v.Ticket.String(),
+			BlockHash:   v.BlockHash.String(),
+			BlockHeight: v.BlockHeight,
+			VoteBits:    v.VoteBits,
+		}, nil
+	case details.Revocation != nil:
+		r := details.Revocation
+		return nil, nil, &walletjson.RevocationDetailsResult{
+			Ticket:   r.Ticket.String(),
+			Refunded: r.Refunded.ToCoin(),
+		}
+	}
+	return nil, nil, nil
 }
 
 // GetWalletFee returns the currently set tx fee for the requested wallet
@@ -2739,7 +4380,7 @@ func ListReceivedByAccount(w *wallet.Wallet, chainSvr *chain.Client,
 			return nil, &ErrAccountNameNotFound
 		}
 		bal, confirmations, err := w.TotalReceivedForAccount(account,
-			minConf)
+			minConf, true)
 		if err != nil {
 			return nil, err
 		}
@@ -2907,23 +4548,174 @@ func ListSinceBlock(w *wallet.Wallet, chainSvr *chain.Client,
 // array of maps with details of sent and recevied wallet transactions.
 func ListTransactions(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, ext := unwrapExtendedCmd(icmd)
 	cmd := icmd.(*dcrjson.ListTransactionsCmd)
 
-	// TODO: ListTransactions does not currently understand the difference
-	// between transactions pertaining to one account from another.  This
-	// will be resolved when wtxmgr is combined with the waddrmgr namespace.
+	allAccounts := cmd.Account == nil || *cmd.Account == "*"
+	if allAccounts && ext == nil {
+		return w.ListTransactions(*cmd.From, *cmd.Count)
+	}
 
-	if cmd.Account != nil && *cmd.Account != "*" {
-		// For now, don't bother trying to continue if the user
-		// specified an account, since this can't be (easily or
-		// efficiently) calculated.
-		return nil, &dcrjson.RPCError{
-			Code:    dcrjson.ErrRPCWallet,
-			Message: "Transactions are not yet grouped by account",
+	filter := wallet.TransactionFilter{
+		Skip:  *cmd.From,
+		Count: *cmd.Count,
+	}
+	if !allAccounts {
+		account, err := w.Manager.LookupAccount(*cmd.Account)
+		if err != nil {
+			return nil, err
+		}
+		filter.Account = &account
+	}
+	if ext == nil {
+		txs, _, err := w.ListTransactionsFiltered(&filter)
+		return txs, err
+	}
+
+	var f listTransactionsFilter
+	if err := json.Unmarshal(ext, &f); err != nil {
+		return nil, InvalidParameterError{
+			fmt.Errorf("invalid transaction filter: %v", err),
+		}
+	}
+	if err := f.apply(&filter); err != nil {
+		return nil, err
+	}
+
+	txs, next, err := w.ListTransactionsFiltered(&filter)
+	if err != nil {
+		return nil, err
+	}
+	result := &listTransactionsFilteredResult{Transactions: txs}
+	if next != nil {
+		result.NextCursor = next.String()
+	}
+	result.FiatRates, err = fiatRates(w, txs)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fiatRates returns the fiat rates recorded for the transactions of
+// listtransactions results, keyed by txid.  Nil is returned when no rates were
+// recorded.
+func fiatRates(w *wallet.Wallet, txs []dcrjson.ListTransactionsResult) (
+	map[string]*fiatRateResult, error) {
+	var rates map[string]*fiatRateResult
+	seen := make(map[string]struct{})
+	for i := range txs {
+		txid := txs[i].TxID
+		if _, ok := seen[txid]; ok {
+			continue
+		}
+		seen[txid] = struct{}{}
+		hash, err := chainhash.NewHashFromStr(txid)
+		if err != nil {
+			return nil, err
+		}
+		r, err := w.TxStore.FiatRate(hash)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			continue
+		}
+		if rates == nil {
+			rates = make(map[string]*fiatRateResult)
+		}
+		rates[txid] = &fiatRateResult{
+			Currency: r.Currency,
+			Rate:     r.Rate,
+			Time:     r.Time.Unix(),
+		}
+	}
+	return rates, nil
+}
+
+// listTransactionsFilter is the filter object which may be passed to
+// listtransactions after the includewatchonly parameter.  Times are UNIX
+// timestamps, and the cursor is the nextcursor of a previous reply.
+type listTransactionsFilter struct {
+	Addresses []string `json:"addresses"`
+	TxTypes   []string `json:"txtypes"`
+	StartTime int64    `json:"starttime"`
+	EndTime   int64    `json:"endtime"`
+	Cursor    string   `json:"cursor"`
+}
+
+// listTransactionsTxTypes maps the transaction types accepted by the
+// listtransactions filter to stake transaction types.
+var listTransactionsTxTypes = map[string]stake.TxType{
+	"regular":    stake.TxTypeRegular,
+	"ticket":     stake.TxTypeSStx,
+	"vote":       stake.TxTypeSSGen,
+	"revocation": stake.TxTypeSSRtx,
+}
+
+// txTypeString returns the name of a stake transaction type as accepted by the
+// listtransactions filter.
+func txTypeString(txType stake.TxType) string {
+	for name, t := range listTransactionsTxTypes {
+		if t == txType {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// apply sets the fields of a wallet transaction filter from the filter
+// object, returning an InvalidParameterError for invalid values.
+func (f *listTransactionsFilter) apply(filter *wallet.TransactionFilter) error {
+	if f.Addresses != nil {
+		filter.Addresses = make(map[string]struct{}, len(f.Addresses))
+		for _, addrStr := range f.Addresses {
+			addr, err := decodeAddress(addrStr, activeNet.Params)
+			if err != nil {
+				return err
+			}
+			filter.Addresses[addr.EncodeAddress()] = struct{}{}
+		}
+	}
+	for _, t := range f.TxTypes {
+		txType, ok := listTransactionsTxTypes[t]
+		if !ok {
+			return InvalidParameterError{
+				fmt.Errorf("unknown transaction type %q", t),
+			}
+		}
+		filter.TxTypes = append(filter.TxTypes, txType)
+	}
+	if f.StartTime != 0 {
+		filter.StartTime = time.Unix(f.StartTime, 0)
+	}
+	if f.EndTime != 0 {
+		filter.EndTime = time.Unix(f.EndTime, 0)
+	}
+	if f.Cursor != "" {
+		cursor, err := wallet.ParseTransactionCursor(f.Cursor)
+		if err != nil {
+			return InvalidParameterError{err}
 		}
+		filter.Cursor = cursor
 	}
+	return nil
+}
+
+// listTransactionsFilteredResult is the reply of listtransactions when a
+// filter object is passed.
+type listTransactionsFilteredResult struct {
+	Transactions []dcrjson.ListTransactionsResult `json:"transactions"`
+	NextCursor   string                           `json:"nextcursor,omitempty"`
+	FiatRates    map[string]*fiatRateResult       `json:"fiatrates,omitempty"`
+}
 
-	return w.ListTransactions(*cmd.From, *cmd.Count)
+// fiatRateResult describes the fiat exchange rate recorded when a transaction
+// was first seen.
+type fiatRateResult struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	Time     int64   `json:"time"`
 }
 
 // ListAddressTransactions handles a listaddresstransactions request by
@@ -2977,6 +4769,7 @@ func ListAllTransactions(w *wallet.Wallet, chainSvr *chain.Client,
 // ListUnspent handles the listunspent command.
 func ListUnspent(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, ext := unwrapExtendedCmd(icmd)
 	cmd := icmd.(*dcrjson.ListUnspentCmd)
 
 	var addresses map[string]struct{}
@@ -2992,7 +4785,63 @@ func ListUnspent(w *wallet.Wallet, chainSvr *chain.Client,
 		}
 	}
 
-	return w.ListUnspent(int32(*cmd.MinConf), int32(*cmd.MaxConf), addresses)
+	if ext == nil {
+		return w.ListUnspent(int32(*cmd.MinConf), int32(*cmd.MaxConf),
+			addresses)
+	}
+
+	var opts listUnspentOptions
+	if err := json.Unmarshal(ext, &opts); err != nil {
+		return nil, InvalidParameterError{
+			fmt.Errorf("invalid listunspent options: %v", err),
+		}
+	}
+	filter := wallet.UnspentFilter{
+		MinConf:              int32(*cmd.MinConf),
+		MaxConf:              int32(*cmd.MaxConf),
+		Addresses:            addresses,
+		IncludeImmatureStake: opts.IncludeImmatureStake,
+	}
+	if opts.Account != nil && *opts.Account != "*" {
+		account, err := w.Manager.LookupAccount(*opts.Account)
+		if err != nil {
+			return nil, err
+		}
+		filter.Account = &account
+	}
+
+	outputs, err := w.ListUnspentFiltered(&filter)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]listUnspentVerboseResult, len(outputs))
+	for i := range outputs {
+		o := &outputs[i]
+		results[i] = listUnspentVerboseResult{
+			ListUnspentResult: o.ListUnspentResult,
+			ScriptClass:       o.ScriptClass.String(),
+			Spendable:         o.Spendable,
+			Reason:            o.Reason,
+		}
+	}
+	return results, nil
+}
+
+// listUnspentOptions is the options object which may be passed to
+// listunspent after the addresses parameter.
+type listUnspentOptions struct {
+	Account              *string `json:"account"`
+	IncludeImmatureStake bool    `json:"includeimmaturestake"`
+}
+
+// listUnspentVerboseResult is a listunspent result when an options object
+// is passed, additionally describing the output script class and whether
+// the output is spendable.
+type listUnspentVerboseResult struct {
+	dcrjson.ListUnspentResult
+	ScriptClass string `json:"scriptclass"`
+	Spendable   bool   `json:"spendable"`
+	Reason      string `json:"reason,omitempty"`
 }
 
 // ListUnspentMultisig handles the listunspentmultisig command.
@@ -3033,6 +4882,8 @@ func LockUnspent(w *wallet.Wallet, chainSvr *chain.Client,
 func PurchaseTicket(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
 
+	icmd, origin := unwrapSigningCmd(icmd)
+
 	// Enforce valid and positive spend limit.
 	cmd := icmd.(*dcrjson.PurchaseTicketCmd)
 	spendLimit, err := dcrutil.NewAmount(cmd.SpendLimit)
@@ -3045,12 +4896,9 @@ func PurchaseTicket(w *wallet.Wallet, chainSvr *chain.Client,
 
 	// Override the minimum number of required confirmations if specified
 	// and enforce it is positive.
-	minConf := int32(1)
-	if cmd.MinConf != nil {
-		minConf = int32(*cmd.MinConf)
-		if minConf < 0 {
-			return nil, ErrNeedPositiveMinconf
-		}
+	minConf, err := requestMinConf(w, cmd.MinConf)
+	if err != nil {
+		return nil, err
 	}
 
 	// Set ticket address if specified.
@@ -3063,7 +4911,8 @@ func PurchaseTicket(w *wallet.Wallet, chainSvr *chain.Client,
 		ticketAddr = addr
 	}
 
-	hash, err := w.CreatePurchaseTicket(0, spendLimit, minConf, ticketAddr)
+	hash, err := w.CreatePurchaseTicket(0, spendLimit, minConf, ticketAddr,
+		origin)
 	if err != nil {
 		if err == wallet.ErrSStxInputOverflow {
 			hash = ""
@@ -3080,6 +4929,7 @@ func PurchaseTicket(w *wallet.Wallet, chainSvr *chain.Client,
 // the user to export to others to sign.
 func RedeemMultiSigOut(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.RedeemMultiSigOutCmd)
 
 	// Convert the address to a useable format. If
@@ -3177,7 +5027,8 @@ func RedeemMultiSigOut(w *wallet.Wallet, chainSvr *chain.Client,
 	}
 
 	// Sign it and give the results to the user.
-	signedTxResult, err := SignRawTransaction(w, chainSvr, srtc)
+	signedTxResult, err := SignRawTransaction(w, chainSvr,
+		&signingCmd{cmd: srtc, origin: origin})
 	if signedTxResult == nil || err != nil {
 		return nil, err
 	}
@@ -3194,6 +5045,7 @@ func RedeemMultiSigOut(w *wallet.Wallet, chainSvr *chain.Client,
 // addresses in this wallet.
 func RedeemMultiSigOuts(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.RedeemMultiSigOutsCmd)
 
 	// Get all the multisignature outpoints that are unspent for this
@@ -3224,7 +5076,8 @@ func RedeemMultiSigOuts(w *wallet.Wallet, chainSvr *chain.Client,
 			Tree:    mso.OutPoint.Tree,
 			Address: cmd.ToAddress,
 		}
-		redeemResult, err := RedeemMultiSigOut(w, chainSvr, rmsoRequest)
+		redeemResult, err := RedeemMultiSigOut(w, chainSvr,
+			&signingCmd{cmd: rmsoRequest, origin: origin})
 		if err != nil {
 			return nil, err
 		}
@@ -3259,13 +5112,113 @@ func TicketsForAddress(w *wallet.Wallet, chainSvr *chain.Client,
 	return dcrjson.TicketsForAddressResult{ticketsStr}, nil
 }
 
-// sendPairs creates and sends payment transactions.
+// ListAddressTickets handles a listaddresstickets request by returning every
+// ticket tracked by the wallet whose voting address or any commitment address
+// is the requested address, sorted by ticket hash.
+func ListAddressTickets(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.ListAddressTicketsCmd)
+
+	addr, err := decodeAddress(cmd.Address, activeNet.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := w.StakeMgr.TicketsForAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]walletjson.ListAddressTicketsResult, 0, len(tickets))
+	for i := range tickets {
+		t := &tickets[i]
+		status, err := w.StakeMgr.TicketStatus(&t.Hash)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, walletjson.ListAddressTicketsResult{
+			Ticket:           t.Hash.String(),
+			Voting:           t.Voting,
+			Commitment:       t.Commitment,
+			CommitmentAmount: t.CommitmentAmount.ToCoin(),
+			Status:           status.String(),
+		})
+	}
+	sort.Sort(addressTicketsByHash(results))
+	return results, nil
+}
+
+// ListAddressUsage handles a listaddressusage request by returning the usage
+// of every address of the wallet, or of the addresses of an account.
+func ListAddressUsage(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.ListAddressUsageCmd)
+
+	var account *uint32
+	if cmd.Account != nil && *cmd.Account != "*" {
+		acct, err := w.Manager.LookupAccount(*cmd.Account)
+		if err != nil {
+			return nil, err
+		}
+		account = &acct
+	}
+
+	usage, err := w.AddressUsageReport(account)
+	if err != nil {
+		return nil, err
+	}
+
+	accountNames := make(map[uint32]string)
+	results := make([]walletjson.ListAddressUsageResult, 0, len(usage))
+	for i := range usage {
+		u := &usage[i]
+		name, ok := accountNames[u.Account]
+		if !ok {
+			name, err = w.Manager.AccountName(u.Account)
+			if err != nil {
+				return nil, err
+			}
+			accountNames[u.Account] = name
+		}
+		branch := "external"
+		switch {
+		case u.Imported:
+			branch = "imported"
+		case u.Internal:
+			branch = "internal"
+		}
+		var firstUsed int64
+		if !u.FirstUsed.IsZero() {
+			firstUsed = u.FirstUsed.Unix()
+		}
+		results = append(results, walletjson.ListAddressUsageResult{
+			Address:       u.Address.EncodeAddress(),
+			Account:       name,
+			Branch:        branch,
+			FirstUsed:     firstUsed,
+			TotalReceived: u.TotalReceived.ToCoin(),
+			Balance:       u.Balance.ToCoin(),
+			TxCount:       u.TxCount,
+			Reused:        u.Reused,
+		})
+	}
+	return results, nil
+}
+
+// addressTicketsByHash sorts listaddresstickets results by ticket hash.
+type addressTicketsByHash []walletjson.ListAddressTicketsResult
+
+func (s addressTicketsByHash) Len() int           { return len(s) }
+func (s addressTicketsByHash) Less(i, j int) bool { return s[i].Ticket < s[j].Ticket }
+func (s addressTicketsByHash) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// sendPairs creates and sends payment transactions signed for origin.
 // It returns the transaction hash in string format upon success
 // All errors are returned in dcrjson.RPCError format
 func sendPairs(w *wallet.Wallet, chainSvr *chain.Client,
-	amounts map[string]dcrutil.Amount, account uint32, minconf int32) (string,
-	error) {
-	createdTx, err := w.SendPairs(amounts, account, minconf)
+	amounts map[string]dcrutil.Amount, account uint32, minconf int32,
+	origin string) (string, error) {
+	createdTx, err := w.SendPairs(amounts, account, minconf, origin)
 	if err != nil {
 		if err == wallet.ErrNonPositiveAmount {
 			return "", ErrNeedPositiveAmount
@@ -3276,6 +5229,11 @@ func sendPairs(w *wallet.Wallet, chainSvr *chain.Client,
 		switch err.(type) {
 		case dcrjson.RPCError:
 			return "", err
+		case wallet.ApprovalRequiredError:
+			return "", &dcrjson.RPCError{
+				Code:    dcrjson.ErrRPCWallet,
+				Message: err.Error(),
+			}
 		}
 
 		return "", &dcrjson.RPCError{
@@ -3285,55 +5243,244 @@ func sendPairs(w *wallet.Wallet, chainSvr *chain.Client,
 	}
 
 	txShaStr := createdTx.MsgTx.TxSha().String()
-	log.Infof("Successfully sent transaction %v", txShaStr)
+	rpcsLog.Infof("Successfully sent transaction %v", txShaStr)
 	return txShaStr, nil
 }
 
-func isNilOrEmpty(s *string) bool {
-	return s == nil || *s == ""
+// approvalMessage returns the message an approval token for the pending send
+// id signs.
+func approvalMessage(id string) string {
+	return "approvesend " + id
 }
 
-// SendFrom handles a sendfrom RPC request by creating a new transaction
-// spending unspent transaction outputs for a wallet to another payment
-// address.  Leftover inputs not sent to the payment address or a fee for
-// the miner are sent back to a new address in the wallet.  Upon success,
-// the TxID for the created transaction is returned.
-func SendFrom(w *wallet.Wallet, chainSvr *chain.Client,
-	icmd interface{}) (interface{}, error) {
-	cmd := icmd.(*dcrjson.SendFromCmd)
+// parsePendingSendID parses the id of a pending send, which is the hash of its
+// transaction.
+func parsePendingSendID(id string) (*chainhash.Hash, error) {
+	hash, err := chainhash.NewHashFromStr(id)
+	if err != nil {
+		return nil, InvalidParameterError{
+			fmt.Errorf("invalid pending send id: %v", err)}
+	}
+	return hash, nil
+}
 
-	// Transaction comments are not yet supported.  Error instead of
-	// pretending to save them.
-	if !isNilOrEmpty(cmd.Comment) || !isNilOrEmpty(cmd.CommentTo) {
-		return nil, &dcrjson.RPCError{
-			Code:    dcrjson.ErrRPCUnimplemented,
-			Message: "Transaction comments are not yet supported",
+// ListPendingSends handles a listpendingsends request by returning the sends
+// waiting for approval, in the order they were requested.
+func ListPendingSends(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	sends := w.PendingSends()
+	result := make([]walletjson.PendingSendResult, 0, len(sends))
+	for _, s := range sends {
+		var buf bytes.Buffer
+		buf.Grow(s.Tx.SerializeSize())
+		if err := s.Tx.Serialize(&buf); err != nil {
+			return nil, err
 		}
+		inputs := make([]walletjson.PendingSendInput, 0, len(s.Inputs))
+		for i := range s.Inputs {
+			inputs = append(inputs, pendingSendInput(w, &s.Inputs[i]))
+		}
+		outputs := make([]walletjson.PendingSendOutput, 0,
+			len(s.Outputs))
+		for addr, amt := range s.Outputs {
+			outputs = append(outputs, walletjson.PendingSendOutput{
+				Address: addr,
+				Amount:  amt.ToCoin(),
+			})
+		}
+		sort.Sort(pendingSendOutputsByAddress(outputs))
+		result = append(result, walletjson.PendingSendResult{
+			ID:      s.ID.String(),
+			Origin:  s.Origin,
+			Amount:  s.Amount.ToCoin(),
+			Fee:     s.Fee.ToCoin(),
+			Inputs:  inputs,
+			Outputs: outputs,
+			Created: s.Created.Unix(),
+			Expires: s.Expires.Unix(),
+			Hex:     hex.EncodeToString(buf.Bytes()),
+		})
 	}
+	return result, nil
+}
 
-	account, err := w.Manager.LookupAccount(cmd.FromAccount)
+// pendingSendInput describes an output spent by a pending send, including the
+// derivation path of the key of its address when the address was derived from
+// the wallet seed.
+func pendingSendInput(w *wallet.Wallet,
+	c *wtxmgr.Credit) walletjson.PendingSendInput {
+	input := walletjson.PendingSendInput{
+		TxID:   c.Hash.String(),
+		Vout:   c.Index,
+		Tree:   c.Tree,
+		Amount: c.Amount.ToCoin(),
+	}
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+		txscript.DefaultScriptVersion, c.PkScript, w.ChainParams())
+	if err != nil || len(addrs) != 1 {
+		return input
+	}
+	input.Address = addrs[0].EncodeAddress()
+	ma, err := w.Manager.Address(addrs[0])
 	if err != nil {
-		return nil, err
+		return input
 	}
-
-	// Check that signed integer parameters are positive.
-	if cmd.Amount < 0 {
-		return nil, ErrNeedPositiveAmount
+	if name, err := w.Manager.AccountName(ma.Account()); err == nil {
+		input.Account = name
 	}
-	minConf := int32(*cmd.MinConf)
-	if minConf < 0 {
-		return nil, ErrNeedPositiveMinconf
+	if path, ok := w.Manager.DerivationPath(ma); ok {
+		input.Path = path.String()
 	}
-	// Create map of address and amount pairs.
-	amt, err := dcrutil.NewAmount(cmd.Amount)
+	return input
+}
+
+// pendingSendOutputsByAddress sorts listpendingsends outputs by address.
+type pendingSendOutputsByAddress []walletjson.PendingSendOutput
+
+func (s pendingSendOutputsByAddress) Len() int           { return len(s) }
+func (s pendingSendOutputsByAddress) Less(i, j int) bool { return s[i].Address < s[j].Address }
+func (s pendingSendOutputsByAddress) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// ListRetiredAddresses handles a listretiredaddresses request by returning
+// the imported keys and scripts retired by rotateimportedkey, ordered by
+// output script.
+func ListRetiredAddresses(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	retired, err := w.TxStore.RetiredScripts()
 	if err != nil {
 		return nil, err
 	}
-	pairs := map[string]dcrutil.Amount{
-		cmd.ToAddress: amt,
+
+	result := make([]walletjson.ListRetiredAddressesResult, 0, len(retired))
+	for _, r := range retired {
+		entry := walletjson.ListRetiredAddressesResult{
+			Script:  hex.EncodeToString(r.PkScript),
+			Retired: r.Time.Unix(),
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			txscript.DefaultScriptVersion, r.PkScript, w.ChainParams())
+		if err == nil && len(addrs) == 1 {
+			entry.Address = addrs[0].EncodeAddress()
+		}
+		if r.SweepTx != (chainhash.Hash{}) {
+			entry.SweepTxID = r.SweepTx.String()
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// ApproveSend handles an approvesend request by signing and broadcasting a
+// pending send, returning its transaction hash.  Without a signature, the
+// send is approved by the requesting RPC user, who must not be the user
+// which requested it.  With a signature, the send is approved by the holder
+// of the key of the configured approval address, which signed the message
+// "approvesend <id>" with signmessage.
+func ApproveSend(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
+	cmd := icmd.(*walletjson.ApproveSendCmd)
+
+	id, err := parsePendingSendID(cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byToken := cmd.Signature != nil
+	if byToken {
+		if cfg.ApprovalAddress == "" {
+			return nil, InvalidParameterError{errors.New("approval " +
+				"tokens are not accepted without an approval address")}
+		}
+		addr, err := decodeAddress(cfg.ApprovalAddress, activeNet.Params)
+		if err != nil {
+			return nil, err
+		}
+		valid, err := verifySignedMessage(addr, *cmd.Signature,
+			approvalMessage(cmd.ID))
+		if err != nil || !valid {
+			return nil, InvalidParameterError{errors.New("approval " +
+				"signature is not valid for this pending send")}
+		}
+	}
+
+	hash, err := w.ApprovePendingSend(id, byToken, origin)
+	if err != nil {
+		if err == wallet.ErrPendingSendNotFound {
+			return nil, InvalidParameterError{err}
+		}
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		return nil, err
+	}
+	rpcsLog.Infof("Successfully sent transaction %v", hash)
+	return hash.String(), nil
+}
+
+// RejectSend handles a rejectsend request by removing a pending send without
+// signing it.
+func RejectSend(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.RejectSendCmd)
+
+	id, err := parsePendingSendID(cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = w.RejectPendingSend(id)
+	if err == wallet.ErrPendingSendNotFound {
+		return nil, InvalidParameterError{err}
+	}
+	return nil, err
+}
+
+func isNilOrEmpty(s *string) bool {
+	return s == nil || *s == ""
+}
+
+// SendFrom handles a sendfrom RPC request by creating a new transaction
+// spending unspent transaction outputs for a wallet to another payment
+// address.  Leftover inputs not sent to the payment address or a fee for
+// the miner are sent back to a new address in the wallet.  Upon success,
+// the TxID for the created transaction is returned.
+func SendFrom(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
+	cmd := icmd.(*dcrjson.SendFromCmd)
+
+	// Transaction comments are not yet supported.  Error instead of
+	// pretending to save them.
+	if !isNilOrEmpty(cmd.Comment) || !isNilOrEmpty(cmd.CommentTo) {
+		return nil, &dcrjson.RPCError{
+			Code:    dcrjson.ErrRPCUnimplemented,
+			Message: "Transaction comments are not yet supported",
+		}
+	}
+
+	account, err := w.Manager.LookupAccount(cmd.FromAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that signed integer parameters are positive.
+	if cmd.Amount < 0 {
+		return nil, ErrNeedPositiveAmount
+	}
+	minConf, err := requestMinConf(w, cmd.MinConf)
+	if err != nil {
+		return nil, err
+	}
+	// Create map of address and amount pairs.
+	amt, err := dcrutil.NewAmount(cmd.Amount)
+	if err != nil {
+		return nil, err
+	}
+	pairs := map[string]dcrutil.Amount{
+		cmd.ToAddress: amt,
 	}
 
-	return sendPairs(w, chainSvr, pairs, account, minConf)
+	return sendPairs(w, chainSvr, pairs, account, minConf, origin)
 }
 
 // SendMany handles a sendmany RPC request by creating a new transaction
@@ -3343,6 +5490,7 @@ func SendFrom(w *wallet.Wallet, chainSvr *chain.Client,
 // Upon success, the TxID for the created transaction is returned.
 func SendMany(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.SendManyCmd)
 
 	// Transaction comments are not yet supported.  Error instead of
@@ -3359,10 +5507,9 @@ func SendMany(w *wallet.Wallet, chainSvr *chain.Client,
 		return nil, err
 	}
 
-	// Check that minconf is positive.
-	minConf := int32(*cmd.MinConf)
-	if minConf < 0 {
-		return nil, ErrNeedPositiveMinconf
+	minConf, err := requestMinConf(w, cmd.MinConf)
+	if err != nil {
+		return nil, err
 	}
 
 	// Recreate address/amount pairs, using dcrutil.Amount.
@@ -3375,7 +5522,7 @@ func SendMany(w *wallet.Wallet, chainSvr *chain.Client,
 		pairs[k] = amt
 	}
 
-	return sendPairs(w, chainSvr, pairs, account, minConf)
+	return sendPairs(w, chainSvr, pairs, account, minConf, origin)
 }
 
 // SendToAddress handles a sendtoaddress RPC request by creating a new
@@ -3385,6 +5532,7 @@ func SendMany(w *wallet.Wallet, chainSvr *chain.Client,
 // the TxID for the created transaction is returned.
 func SendToAddress(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.SendToAddressCmd)
 
 	// Transaction comments are not yet supported.  Error instead of
@@ -3411,8 +5559,10 @@ func SendToAddress(w *wallet.Wallet, chainSvr *chain.Client,
 		cmd.Address: amt,
 	}
 
-	// sendtoaddress always spends from the default account, this matches bitcoind
-	return sendPairs(w, chainSvr, pairs, waddrmgr.DefaultAccountNum, 1)
+	// sendtoaddress always spends from the default account, this matches
+	// bitcoind, and outputs with the wallet's default minimum confirmations.
+	return sendPairs(w, chainSvr, pairs, waddrmgr.DefaultAccountNum,
+		w.SpendPolicy().MinConf, origin)
 }
 
 // SendToMultiSig handles a sendtomultisig RPC request by creating a new
@@ -3428,6 +5578,7 @@ func SendToAddress(w *wallet.Wallet, chainSvr *chain.Client,
 // TODO Use with non-default accounts as well
 func SendToMultiSig(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.SendToMultiSigCmd)
 	account := uint32(waddrmgr.DefaultAccountNum)
 	amount, err := dcrutil.NewAmount(cmd.Amount)
@@ -3435,7 +5586,10 @@ func SendToMultiSig(w *wallet.Wallet, chainSvr *chain.Client,
 		return nil, err
 	}
 	nrequired := int8(*cmd.NRequired)
-	minconf := int32(*cmd.MinConf)
+	minconf, err := requestMinConf(w, cmd.MinConf)
+	if err != nil {
+		return nil, err
+	}
 	pubkeys := make([]*dcrutil.AddressSecpPubKey, len(cmd.Pubkeys))
 
 	// The address list will made up either of addreseses (pubkey hash), for
@@ -3474,7 +5628,8 @@ func SendToMultiSig(w *wallet.Wallet, chainSvr *chain.Client,
 	}
 
 	ctx, addr, script, err :=
-		w.CreateMultisigTx(account, amount, pubkeys, nrequired, minconf)
+		w.CreateMultisigTx(account, amount, pubkeys, nrequired, minconf,
+			origin)
 	if err != nil {
 		return nil, fmt.Errorf("CreateMultisigTx error: %v", err.Error())
 	}
@@ -3490,7 +5645,7 @@ func SendToMultiSig(w *wallet.Wallet, chainSvr *chain.Client,
 		return nil, err
 	}
 
-	log.Infof("Successfully sent funds to multisignature output in "+
+	rpcsLog.Infof("Successfully sent funds to multisignature output in "+
 		"transaction %v", ctx.MsgTx.TxSha().String())
 
 	return result, nil
@@ -3503,17 +5658,16 @@ func SendToMultiSig(w *wallet.Wallet, chainSvr *chain.Client,
 // DECRED TODO: Clean these up
 func SendToSStx(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.SendToSStxCmd)
-	minconf := int32(*cmd.MinConf)
-
-	account, err := w.Manager.LookupAccount(cmd.FromAccount)
+	minconf, err := requestMinConf(w, cmd.MinConf)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check that minconf is positive.
-	if minconf < 0 {
-		return nil, ErrNeedPositiveMinconf
+	account, err := w.Manager.LookupAccount(cmd.FromAccount)
+	if err != nil {
+		return nil, err
 	}
 
 	// Recreate address/amount pairs, using dcrutil.Amount.
@@ -3547,7 +5701,7 @@ func SendToSStx(w *wallet.Wallet, chainSvr *chain.Client,
 	// Create transaction, replying with an error if the creation
 	// was not successful.
 	createdTx, err := w.CreateSStxTx(pair, usedEligible, cmd.Inputs,
-		cmd.COuts, minconf)
+		cmd.COuts, minconf, origin)
 	if err != nil {
 		switch err {
 		case wallet.ErrNonPositiveAmount:
@@ -3561,11 +5715,11 @@ func SendToSStx(w *wallet.Wallet, chainSvr *chain.Client,
 		var ok bool
 		createdTx.msgtx, ok, err = chainSvr.SignRawTransaction(createdTx.msgtx)
 		if err != nil {
-			log.Errorf("Error signing tx: %v", err)
+			rpcsLog.Errorf("Error signing tx: %v", err)
 			return nil, err
 		}
 		if !ok {
-			log.Errorf("Not all inputs have been signed for sstx")
+			rpcsLog.Errorf("Not all inputs have been signed for sstx")
 			return nil, err
 		}
 	*/
@@ -3574,7 +5728,7 @@ func SendToSStx(w *wallet.Wallet, chainSvr *chain.Client,
 	if err != nil {
 		return nil, err
 	}
-	log.Infof("Successfully sent SStx purchase transaction %v", txSha)
+	rpcsLog.Infof("Successfully sent SStx purchase transaction %v", txSha)
 	return txSha.String(), nil
 }
 
@@ -3584,6 +5738,7 @@ func SendToSStx(w *wallet.Wallet, chainSvr *chain.Client,
 // DECRED TODO: Clean these up
 func SendToSSGen(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, _ = unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.SendToSSGenCmd)
 
 	_, err := w.Manager.LookupAccount(cmd.FromAccount)
@@ -3618,7 +5773,7 @@ func SendToSSGen(w *wallet.Wallet, chainSvr *chain.Client,
 
 	txSha := createdTx.MsgTx.TxSha()
 
-	log.Infof("Successfully sent transaction %v", txSha)
+	rpcsLog.Infof("Successfully sent transaction %v", txSha)
 	return txSha.String(), nil
 }
 
@@ -3628,6 +5783,7 @@ func SendToSSGen(w *wallet.Wallet, chainSvr *chain.Client,
 // DECRED TODO: Clean these up
 func SendToSSRtx(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, _ = unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.SendToSSRtxCmd)
 
 	_, err := w.Manager.LookupAccount(cmd.FromAccount)
@@ -3657,7 +5813,7 @@ func SendToSSRtx(w *wallet.Wallet, chainSvr *chain.Client,
 	if err != nil {
 		return nil, err
 	}
-	log.Infof("Successfully sent transaction %v", txSha)
+	rpcsLog.Infof("Successfully sent transaction %v", txSha)
 	return txSha.String(), nil
 }
 
@@ -3757,6 +5913,7 @@ type pendingTx struct {
 // SignRawTransaction handles the signrawtransaction command.
 func SignRawTransaction(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.SignRawTransactionCmd)
 
 	serializedTx, err := decodeHexStr(cmd.RawTx)
@@ -3799,7 +5956,7 @@ func SignRawTransaction(w *wallet.Wallet, chainSvr *chain.Client,
 					if err != nil {
 						return nil, err
 					}
-					log.Debugf("Marked address %v used", addr)
+					rpcsLog.Debugf("Marked address %v used", addr)
 				} else {
 					// Missing addresses are skipped.  Other errors should
 					// be propagated.
@@ -3827,7 +5984,7 @@ func SignRawTransaction(w *wallet.Wallet, chainSvr *chain.Client,
 					case waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress):
 						break
 					case waddrmgr.IsError(err, waddrmgr.ErrLocked):
-						log.Debugf("failed to attempt script importation " +
+						rpcsLog.Debugf("failed to attempt script importation " +
 							"of incoming tx because addrmgr was locked")
 						break
 					default:
@@ -4042,6 +6199,16 @@ func SignRawTransaction(w *wallet.Wallet, chainSvr *chain.Client,
 		}
 	}
 
+	// Transactions signed for RPC users must satisfy the signing policy
+	// and send approval threshold, like the transactions created by the
+	// wallet.
+	if err := w.CheckSigningPolicy(msgTx, origin); err != nil {
+		return nil, err
+	}
+	if err := w.CheckSendApproval(msgTx, origin); err != nil {
+		return nil, err
+	}
+
 	// All args collected. Now we can sign all the inputs that we can.
 	// `complete' denotes that we successfully signed all outputs and that
 	// all scripts will run to completion. This is returned as part of the
@@ -4217,6 +6384,15 @@ func SignRawTransaction(w *wallet.Wallet, chainSvr *chain.Client,
 		}
 	}
 
+	// Spends of wallet credits by this transaction are expected once it is
+	// published.  Transaction hashes do not commit to signature scripts, so
+	// the hash is the same however many inputs were signed.
+	txHash := msgTx.TxSha()
+	w.RecordAuthoredTx(&txHash)
+	if err := w.RecordSigning(msgTx, origin); err != nil {
+		return nil, err
+	}
+
 	var buf bytes.Buffer
 	buf.Grow(msgTx.SerializeSize())
 
@@ -4236,6 +6412,7 @@ func SignRawTransaction(w *wallet.Wallet, chainSvr *chain.Client,
 // SignRawTransactions handles the signrawtransactions command.
 func SignRawTransactions(w *wallet.Wallet, chainSvr *chain.Client,
 	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
 	cmd := icmd.(*dcrjson.SignRawTransactionsCmd)
 
 	// Sign each transaction sequentially and record the results.
@@ -4248,7 +6425,8 @@ func SignRawTransactions(w *wallet.Wallet, chainSvr *chain.Client,
 			RawTx: etx,
 			Flags: &flagAll,
 		}
-		result, err := SignRawTransaction(w, chainSvr, srtc)
+		result, err := SignRawTransaction(w, chainSvr,
+			&signingCmd{cmd: srtc, origin: origin})
 		if err != nil {
 			return nil, err
 		}
@@ -4406,6 +6584,214 @@ func ValidateAddress(w *wallet.Wallet, chainSvr *chain.Client,
 	return result, nil
 }
 
+// DecodeAddress handles a decodeaddress request by describing the output
+// script paying to an address and whether the wallet controls it.
+func DecodeAddress(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.DecodeAddressCmd)
+
+	addr, err := decodeAddress(cmd.Address, activeNet.Params)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := w.DescribeAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	return describeScriptResult(desc), nil
+}
+
+// CreatePaymentURI handles a createpaymenturi request by deriving a new
+// address of an account and returning a payment URI requesting payment to
+// it.
+func CreatePaymentURI(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.CreatePaymentURICmd)
+
+	uri := &wallet.PaymentURI{}
+	if cmd.Amount != nil {
+		amount, err := dcrutil.NewAmount(*cmd.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if amount <= 0 {
+			return nil, ErrNeedPositiveAmount
+		}
+		uri.Amount = amount
+	}
+	if cmd.Label != nil {
+		uri.Label = *cmd.Label
+	}
+	if cmd.Message != nil {
+		uri.Message = *cmd.Message
+	}
+	if *cmd.Expiry < 0 {
+		return nil, InvalidParameterError{
+			errors.New("expiry must not be negative"),
+		}
+	}
+	if *cmd.Expiry > 0 {
+		expires := time.Now().Add(time.Duration(*cmd.Expiry) * time.Second)
+		uri.Expires = time.Unix(expires.Unix(), 0)
+	}
+
+	account, err := w.Manager.LookupAccount(*cmd.Account)
+	if err != nil {
+		return nil, err
+	}
+	if account == waddrmgr.DefaultAccountNum {
+		uri.Address, err = w.GetNewAddressExternal()
+	} else {
+		uri.Address, err = w.NewAddress(account)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var expires int64
+	if !uri.Expires.IsZero() {
+		expires = uri.Expires.Unix()
+	}
+	return &walletjson.CreatePaymentURIResult{
+		URI:     uri.String(),
+		Address: uri.Address.EncodeAddress(),
+		Expires: expires,
+	}, nil
+}
+
+// DecodePaymentURI handles a decodepaymenturi request by validating and
+// decoding a payment URI, so a payment request may be checked before it is
+// paid.
+func DecodePaymentURI(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.DecodePaymentURICmd)
+
+	uri, err := wallet.ParsePaymentURI(cmd.URI, activeNet.Params)
+	if err != nil {
+		return &walletjson.DecodePaymentURIResult{Error: err.Error()}, nil
+	}
+	_, err = w.Manager.Address(uri.Address)
+	result := &walletjson.DecodePaymentURIResult{
+		IsValid: true,
+		Address: uri.Address.EncodeAddress(),
+		Amount:  uri.Amount.ToCoin(),
+		Label:   uri.Label,
+		Message: uri.Message,
+		Expired: uri.Expired(time.Now()),
+		IsMine:  err == nil,
+	}
+	if !uri.Expires.IsZero() {
+		result.Expires = uri.Expires.Unix()
+	}
+	return result, nil
+}
+
+// DescribeScript handles a describescript request by describing an output
+// script and whether the wallet controls it.
+func DescribeScript(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.DescribeScriptCmd)
+
+	script, err := decodeHexStr(cmd.Script)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := w.DescribeScript(*cmd.Version, script)
+	if err != nil {
+		return nil, err
+	}
+	return describeScriptResult(desc), nil
+}
+
+// describeScriptResult creates the reply to the describescript and
+// decodeaddress methods from a script description.
+func describeScriptResult(desc *wallet.ScriptDescription) *walletjson.DescribeScriptResult {
+	result := &walletjson.DescribeScriptResult{
+		Script:     hex.EncodeToString(desc.Script),
+		Class:      desc.Class.String(),
+		ReqSigs:    int32(desc.RequiredSigs),
+		Addresses:  make([]string, len(desc.Addresses)),
+		Hashes:     make([]string, len(desc.Addresses)),
+		WalletKeys: int32(desc.WalletKeys),
+		Controlled: desc.Controlled,
+	}
+	if desc.StakeSubClass != txscript.NonStandardTy {
+		result.StakeSubClass = desc.StakeSubClass.String()
+	}
+	for i, addr := range desc.Addresses {
+		result.Addresses[i] = addr.EncodeAddress()
+		result.Hashes[i] = hex.EncodeToString(addr.ScriptAddress())
+	}
+	if desc.RedeemScript != nil {
+		result.RedeemScript = hex.EncodeToString(desc.RedeemScript)
+	}
+	return result
+}
+
+// DecodeRawTransaction handles a decoderawtransaction request by decoding a
+// serialized transaction.  Unlike the decoder of the chain server, the stake
+// details of tickets, votes, and revocations are decoded, and each input and
+// output is annotated with whether it belongs to the wallet.
+func DecodeRawTransaction(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*dcrjson.DecodeRawTransactionCmd)
+
+	serializedTx, err := decodeHexStr(cmd.HexTx)
+	if err != nil {
+		return nil, err
+	}
+	msgTx := wire.NewMsgTx()
+	err = msgTx.Deserialize(bytes.NewBuffer(serializedTx))
+	if err != nil {
+		e := errors.New("TX decode failed")
+		return nil, DeserializationError{e}
+	}
+
+	decoded, err := w.DecodeTransaction(msgTx)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRawTransactionResult(msgTx, decoded), nil
+}
+
+// decodeRawTransactionResult returns the decoderawtransaction result for a
+// transaction decoded by the wallet.
+func decodeRawTransactionResult(tx *wire.MsgTx,
+	decoded *wallet.DecodedTx) *walletjson.DecodeRawTransactionResult {
+	result := &walletjson.DecodeRawTransactionResult{
+		TxID:     decoded.Hash.String(),
+		Type:     txTypeString(decoded.Type),
+		Version:  tx.Version,
+		LockTime: tx.LockTime,
+		Expiry:   tx.Expiry,
+		Vin:      make([]walletjson.DecodeRawTransactionInput, len(tx.TxIn)),
+		Vout:     make([]walletjson.DecodeRawTransactionOutput, len(tx.TxOut)),
+	}
+	for i, txIn := range tx.TxIn {
+		op := &txIn.PreviousOutPoint
+		result.Vin[i] = walletjson.DecodeRawTransactionInput{
+			TxID:      op.Hash.String(),
+			Vout:      op.Index,
+			Tree:      op.Tree,
+			Sequence:  txIn.Sequence,
+			AmountIn:  dcrutil.Amount(txIn.ValueIn).ToCoin(),
+			Stakebase: decoded.Type == stake.TxTypeSSGen && i == 0,
+			Mine:      decoded.Inputs[i].Mine,
+		}
+	}
+	for i, txOut := range tx.TxOut {
+		result.Vout[i] = walletjson.DecodeRawTransactionOutput{
+			N:            uint32(i),
+			Value:        dcrutil.Amount(txOut.Value).ToCoin(),
+			Version:      txOut.Version,
+			ScriptPubKey: *describeScriptResult(decoded.Outputs[i]),
+		}
+	}
+	result.Ticket, result.Vote, result.Revocation =
+		stakeDetailsResults(decoded.Stake)
+	return result
+}
+
 // VerifyMessage handles the verifymessage command by verifying the provided
 // compact signature for the given address and message.
 func VerifyMessage(w *wallet.Wallet, chainSvr *chain.Client,
@@ -4417,19 +6803,31 @@ func VerifyMessage(w *wallet.Wallet, chainSvr *chain.Client,
 		return nil, err
 	}
 
-	// decode base64 signature
-	sig, err := base64.StdEncoding.DecodeString(cmd.Signature)
+	valid, err := verifySignedMessage(addr, cmd.Signature, cmd.Message)
 	if err != nil {
 		return nil, err
 	}
+	return valid, nil
+}
+
+// verifySignedMessage returns whether the base64-encoded signature is a valid
+// signmessage signature of message by the key of addr.
+func verifySignedMessage(addr dcrutil.Address, signature,
+	message string) (bool, error) {
+
+	// decode base64 signature
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, err
+	}
 
 	// Validate the signature - this just shows that it was valid at all.
 	// we will compare it with the key next.
 	pk, wasCompressed, err := chainec.Secp256k1.RecoverCompact(sig,
 		chainhash.HashFuncB([]byte("Decred Signed Message:\n"+
-			cmd.Message)))
+			message)))
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
 	// Decred: This should actually be a universalized constructor.
@@ -4449,7 +6847,7 @@ func VerifyMessage(w *wallet.Wallet, chainSvr *chain.Client,
 	case *dcrutil.AddressSecpPubKey: // ok
 		return string(serializedPubKey) == checkAddr.String(), nil
 	default:
-		return nil, errors.New("address type not supported")
+		return false, errors.New("address type not supported")
 	}
 }
 
@@ -4461,6 +6859,613 @@ func WalletIsLocked(w *wallet.Wallet, chainSvr *chain.Client,
 	return w.Locked(), nil
 }
 
+// EstimateStakeDiff handles an estimatestakediff request by forecasting the
+// ticket price of the next stake difficulty window from the live ticket pool
+// size and the tickets purchased in the recent windows.
+func EstimateStakeDiff(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	estimate, err := w.EstimateStakeDifficulty()
+	if err != nil {
+		return nil, err
+	}
+	return &walletjson.EstimateStakeDiffResult{
+		Height:    estimate.Height,
+		Remaining: estimate.Remaining,
+		Current:   estimate.Current.ToCoin(),
+		Min:       estimate.Min.ToCoin(),
+		Expected:  estimate.Expected.ToCoin(),
+		Max:       estimate.Max.ToCoin(),
+	}, nil
+}
+
+// formatCoin formats an amount in coins without a unit for CSV exports.
+func formatCoin(amount dcrutil.Amount) string {
+	return strconv.FormatFloat(amount.ToCoin(), 'f', -1, 64)
+}
+
+// accountHistoryLine is a line of a JSON lines account history export.
+type accountHistoryLine struct {
+	Height    int32   `json:"height"`
+	BlockHash string  `json:"blockhash"`
+	Time      int64   `json:"time"`
+	TxID      string  `json:"txid"`
+	Type      string  `json:"type"`
+	Received  float64 `json:"received"`
+	Sent      float64 `json:"sent"`
+	Amount    float64 `json:"amount"`
+	Balance   float64 `json:"balance"`
+}
+
+// ExportAccountHistory handles an exportaccounthistory request by returning a
+// page of the mined transaction history of an account, oldest first, with the
+// account's running balance, as CSV or JSON lines.  The history is resumed
+// after the cursor of a previous reply, so it may be exported in pages and
+// later extended with newly mined transactions.
+func ExportAccountHistory(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.ExportAccountHistoryCmd)
+
+	account, err := w.Manager.LookupAccount(cmd.Account)
+	if err != nil {
+		return nil, err
+	}
+	format := *cmd.Format
+	if format != "csv" && format != "jsonl" {
+		return nil, InvalidParameterError{
+			fmt.Errorf("unknown history format %q", format),
+		}
+	}
+	if *cmd.Count <= 0 {
+		return nil, InvalidParameterError{
+			errors.New("count must be positive"),
+		}
+	}
+	var cursor *wallet.AccountHistoryCursor
+	if cmd.Cursor != nil && *cmd.Cursor != "" {
+		cursor, err = wallet.ParseAccountHistoryCursor(*cmd.Cursor)
+		if err != nil {
+			return nil, InvalidParameterError{err}
+		}
+	}
+
+	// One more entry than requested is fetched to report whether the
+	// history is complete.
+	entries, _, err := w.AccountHistory(account, cursor, *cmd.Count+1)
+	if err != nil {
+		return nil, err
+	}
+	complete := len(entries) <= *cmd.Count
+	if !complete {
+		entries = entries[:*cmd.Count]
+	}
+
+	var buf bytes.Buffer
+	if format == "csv" {
+		cw := csv.NewWriter(&buf)
+		if cursor == nil {
+			cw.Write([]string{"height", "blockhash", "time", "txid",
+				"type", "received", "sent", "amount", "balance"})
+		}
+		for i := range entries {
+			e := &entries[i]
+			cw.Write([]string{
+				strconv.Itoa(int(e.Height)),
+				e.BlockHash.String(),
+				strconv.FormatInt(e.Time.Unix(), 10),
+				e.Hash.String(),
+				txTypeString(e.TxType),
+				formatCoin(e.Received),
+				formatCoin(e.Sent),
+				formatCoin(e.Received - e.Sent),
+				formatCoin(e.Balance),
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return nil, err
+		}
+	} else {
+		enc := json.NewEncoder(&buf)
+		for i := range entries {
+			e := &entries[i]
+			err := enc.Encode(&accountHistoryLine{
+				Height:    e.Height,
+				BlockHash: e.BlockHash.String(),
+				Time:      e.Time.Unix(),
+				TxID:      e.Hash.String(),
+				Type:      txTypeString(e.TxType),
+				Received:  e.Received.ToCoin(),
+				Sent:      e.Sent.ToCoin(),
+				Amount:    (e.Received - e.Sent).ToCoin(),
+				Balance:   e.Balance.ToCoin(),
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := &walletjson.ExportAccountHistoryResult{
+		Account:  cmd.Account,
+		Format:   format,
+		Count:    len(entries),
+		Complete: complete,
+		Data:     buf.String(),
+	}
+	next := cursor
+	if len(entries) != 0 {
+		last := &entries[len(entries)-1]
+		next = &wallet.AccountHistoryCursor{
+			Height:  last.Height,
+			Hash:    last.Hash,
+			Balance: last.Balance,
+		}
+	}
+	if next != nil {
+		result.NextCursor = next.String()
+	}
+	return result, nil
+}
+
+// ExportCapitalGains handles an exportcapitalgains request by returning the
+// gains realized by the wallet during a calendar year as CSV, valued with the
+// fiat rates recorded for the wallet's transactions.
+func ExportCapitalGains(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.ExportCapitalGainsCmd)
+
+	method, err := wallet.ParseLotMethod(*cmd.Method)
+	if err != nil {
+		return nil, InvalidParameterError{err}
+	}
+	if cmd.Year < 1 || cmd.Year > 9999 {
+		return nil, InvalidParameterError{
+			fmt.Errorf("invalid year %d", cmd.Year),
+		}
+	}
+
+	report, err := w.CapitalGainsReport(cmd.Year, method)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = report.WriteCSV(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return &walletjson.ExportCapitalGainsResult{
+		Year:          report.Year,
+		Method:        report.Method.String(),
+		Currency:      report.Currency,
+		TotalProceeds: report.TotalProceeds,
+		TotalCost:     report.TotalCost,
+		TotalGain:     report.TotalGain,
+		UnknownCount:  report.Unknown,
+		CSV:           buf.String(),
+	}, nil
+}
+
+// ExportSigningLog handles an exportsigninglog request by returning records of
+// the signing log, describing every transaction signed by the wallet, in the
+// order they were signed.
+func ExportSigningLog(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.ExportSigningLogCmd)
+
+	if *cmd.Count < 1 {
+		return nil, InvalidParameterError{
+			errors.New("count must be positive"),
+		}
+	}
+	recs, err := w.TxStore.SigningRecords(*cmd.Start, *cmd.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]walletjson.SigningRecordResult, 0, len(recs))
+	for _, rec := range recs {
+		var buf bytes.Buffer
+		buf.Grow(rec.MsgTx.SerializeSize())
+		if err := rec.MsgTx.Serialize(&buf); err != nil {
+			return nil, err
+		}
+		result := walletjson.SigningRecordResult{
+			Sequence: rec.Sequence,
+			Time:     rec.Time.Unix(),
+			Origin:   rec.Origin,
+			TxHash:   rec.MsgTx.TxSha().String(),
+			Inputs: make([]walletjson.SigningRecordInput, 0,
+				len(rec.MsgTx.TxIn)),
+			Outputs: make([]walletjson.SigningRecordOutput, 0,
+				len(rec.MsgTx.TxOut)),
+			Hex: hex.EncodeToString(buf.Bytes()),
+		}
+		for _, txIn := range rec.MsgTx.TxIn {
+			op := &txIn.PreviousOutPoint
+			result.Inputs = append(result.Inputs,
+				walletjson.SigningRecordInput{
+					TxID:   op.Hash.String(),
+					Vout:   op.Index,
+					Tree:   op.Tree,
+					Amount: dcrutil.Amount(txIn.ValueIn).ToCoin(),
+				})
+		}
+		for _, txOut := range rec.MsgTx.TxOut {
+			class, addrs, _, _ := txscript.ExtractPkScriptAddrs(
+				txOut.Version, txOut.PkScript, activeNet.Params)
+			encodedAddrs := make([]string, len(addrs))
+			for i, addr := range addrs {
+				encodedAddrs[i] = addr.EncodeAddress()
+			}
+			result.Outputs = append(result.Outputs,
+				walletjson.SigningRecordOutput{
+					Amount:     dcrutil.Amount(txOut.Value).ToCoin(),
+					ScriptType: class.String(),
+					Addresses:  encodedAddrs,
+				})
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ExportSeedBackup handles an exportseedbackup request by returning a base64
+// encoded seed backup file, encrypted with the passphrase, describing the
+// seed, accounts, and imported keys and scripts of the wallet.  The wallet
+// must be unlocked.
+func ExportSeedBackup(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.ExportSeedBackupCmd)
+
+	if cmd.Passphrase == "" {
+		return nil, InvalidParameterError{
+			errors.New("passphrase must not be empty"),
+		}
+	}
+
+	b, err := w.SeedBackup()
+	if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+		return nil, &ErrWalletUnlockNeeded
+	}
+	if err != nil {
+		return nil, err
+	}
+	file, err := wallet.EncodeSeedBackup(b, []byte(cmd.Passphrase))
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString(file), nil
+}
+
+// GetBalanceHistory handles a getbalancehistory request by returning the
+// changes of the wallet balance caused by each block, or by each day, in a
+// range of heights, along with the resulting balance.
+func GetBalanceHistory(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetBalanceHistoryCmd)
+
+	endHeight := w.Manager.SyncedTo().Height
+	if cmd.EndHeight != nil {
+		endHeight = *cmd.EndHeight
+	}
+	if *cmd.StartHeight < 0 || endHeight < *cmd.StartHeight {
+		return nil, InvalidParameterError{
+			errors.New("invalid block height range"),
+		}
+	}
+	var daily bool
+	switch *cmd.Interval {
+	case "block":
+	case "day":
+		daily = true
+	default:
+		return nil, InvalidParameterError{
+			fmt.Errorf("unknown interval %q", *cmd.Interval),
+		}
+	}
+
+	history, err := w.TxStore.BalanceHistory(*cmd.StartHeight, endHeight)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]walletjson.GetBalanceHistoryResult, 0, len(history))
+	var last *walletjson.GetBalanceHistoryResult
+	var lastDay time.Time
+	for i := range history {
+		h := &history[i]
+		if daily {
+			day := h.Time.UTC().Truncate(24 * time.Hour)
+			if last != nil && day.Equal(lastDay) {
+				last.Height = h.Height
+				last.Time = h.Time.Unix()
+				last.Delta += h.Delta.ToCoin()
+				last.Balance = h.Balance.ToCoin()
+				continue
+			}
+			lastDay = day
+		}
+		results = append(results, walletjson.GetBalanceHistoryResult{
+			Height:  h.Height,
+			Time:    h.Time.Unix(),
+			Delta:   h.Delta.ToCoin(),
+			Balance: h.Balance.ToCoin(),
+		})
+		last = &results[len(results)-1]
+	}
+	return results, nil
+}
+
+// GetBlockVoteBits handles a getblockvotebits request by returning the vote
+// bits of each block recorded by the wallet in a range of heights, and whether
+// they approve the regular transaction tree of the parent block.
+func GetBlockVoteBits(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetBlockVoteBitsCmd)
+
+	endHeight := w.Manager.SyncedTo().Height
+	if cmd.EndHeight != nil {
+		endHeight = *cmd.EndHeight
+	}
+	if cmd.StartHeight < 0 || endHeight < cmd.StartHeight {
+		return nil, InvalidParameterError{
+			errors.New("invalid block height range"),
+		}
+	}
+
+	blocks, err := w.TxStore.BlockVoteBits(cmd.StartHeight, endHeight)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]walletjson.GetBlockVoteBitsResult, 0, len(blocks))
+	for i := range blocks {
+		b := &blocks[i]
+		results = append(results, walletjson.GetBlockVoteBitsResult{
+			Height:         b.Height,
+			Hash:           b.Hash.String(),
+			Time:           b.Time.Unix(),
+			VoteBits:       b.VoteBits,
+			ParentApproved: b.ParentApproved(),
+		})
+	}
+	return results, nil
+}
+
+// GetCreditOrigin handles a getcreditorigin request by returning the origin
+// tag of a transaction output.
+func GetCreditOrigin(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetCreditOriginCmd)
+
+	hash, err := chainhash.NewHashFromStr(cmd.TxID)
+	if err != nil {
+		return nil, &dcrjson.RPCError{
+			Code:    dcrjson.ErrRPCDecodeHexString,
+			Message: "Transaction hash string decode failed: " + err.Error(),
+		}
+	}
+	op := wire.OutPoint{Hash: *hash, Index: cmd.Vout}
+	origin, err := w.TxStore.CreditOrigin(&op)
+	if err != nil {
+		return nil, err
+	}
+	return origin.String(), nil
+}
+
+// GetFeesReport handles a getfeesreport request by returning the fees paid
+// by the wallet's transactions within a time range, grouped by the kind of
+// transaction.
+func GetFeesReport(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetFeesReportCmd)
+
+	var startTime, endTime time.Time
+	if cmd.StartTime != nil {
+		startTime = time.Unix(*cmd.StartTime, 0)
+	}
+	if cmd.EndTime != nil {
+		endTime = time.Unix(*cmd.EndTime, 0)
+	}
+	if !startTime.IsZero() && !endTime.IsZero() && !endTime.After(startTime) {
+		return nil, InvalidParameterError{
+			errors.New("end time must be after start time"),
+		}
+	}
+
+	report, err := w.FeeReport(startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	return &walletjson.GetFeesReportResult{
+		RegularCount:    report.Regular.Count,
+		RegularFees:     report.Regular.Fees.ToCoin(),
+		TicketCount:     report.Tickets.Count,
+		TicketFees:      report.Tickets.Fees.ToCoin(),
+		VoteCount:       report.Votes.Count,
+		VoteFees:        report.Votes.Fees.ToCoin(),
+		RevocationCount: report.Revocations.Count,
+		RevocationFees:  report.Revocations.Fees.ToCoin(),
+		UnknownCount:    report.Unknown,
+		TotalFees:       report.Total.ToCoin(),
+	}, nil
+}
+
+// GetImportedBalance handles a getimportedbalance request by returning the
+// balance of the wallet split by whether it can be recovered by restoring the
+// wallet from its seed, so users can tell whether the imported keys and
+// scripts must be backed up as well.
+func GetImportedBalance(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetImportedBalanceCmd)
+
+	balType, err := parseBalanceType(cmd.BalanceType)
+	if err != nil {
+		return nil, err
+	}
+	bal, err := w.CalculateImportedBalance(int32(*cmd.MinConf), balType)
+	if err != nil {
+		return nil, err
+	}
+	return &walletjson.GetImportedBalanceResult{
+		Total:          (bal.Seed + bal.Imported).ToCoin(),
+		Seed:           bal.Seed.ToCoin(),
+		Imported:       bal.Imported.ToCoin(),
+		BackupRequired: bal.Imported != 0,
+	}, nil
+}
+
+// GetLockInfo handles a getlockinfo request by returning whether the wallet
+// is locked and when an unlocked wallet will be locked again.
+func GetLockInfo(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	info := w.LockInfo()
+	result := &walletjson.GetLockInfoResult{Locked: info.Locked}
+	if !info.LockTime.IsZero() {
+		result.LockTime = info.LockTime.Unix()
+		remaining := info.LockTime.Sub(time.Now())
+		if remaining > 0 {
+			result.Remaining = int64((remaining + time.Second - 1) /
+				time.Second)
+		}
+	}
+	return result, nil
+}
+
+// GetTicketBuyerLog handles a getticketbuyerlog request by returning the most
+// recent decisions of the automatic ticket buyer, oldest first.
+func GetTicketBuyerLog(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetTicketBuyerLogCmd)
+	if *cmd.Count <= 0 {
+		return nil, InvalidParameterError{
+			errors.New("count must be positive"),
+		}
+	}
+
+	decisions, err := w.TicketBuyerDecisions(*cmd.Count)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]walletjson.GetTicketBuyerLogResult, 0, len(decisions))
+	for _, d := range decisions {
+		result = append(result, walletjson.GetTicketBuyerLogResult{
+			ID:          d.ID,
+			Height:      d.Height,
+			Time:        d.Time.Unix(),
+			TicketPrice: d.TicketPrice.ToCoin(),
+			Spendable:   d.Spendable.ToCoin(),
+			MempoolFee:  d.MempoolFee.ToCoin(),
+			FeeRate:     d.FeeRate.ToCoin(),
+			Attempted:   d.Attempted,
+			Purchased:   d.Purchased,
+			Batch:       d.Batch,
+			Reason:      d.Reason,
+		})
+	}
+	return result, nil
+}
+
+// GetStakeInfo handles a getstakeinfo request by returning the number of
+// the wallet's tickets in each stage of their lifecycle and the subsidy
+// earned by their votes.
+func GetStakeInfo(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	info, err := w.StakeInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &walletjson.GetStakeInfoResult{
+		BlockHeight:   info.BlockHeight,
+		Difficulty:    info.Difficulty.ToCoin(),
+		OwnMempoolTix: info.OwnMempoolTix,
+		Immature:      info.Immature,
+		Live:          info.Live,
+		Voted:         info.Voted,
+		Missed:        info.Missed,
+		Expired:       info.Expired,
+		Revoked:       info.Revoked,
+		TotalSubsidy:  info.TotalSubsidy.ToCoin(),
+	}, nil
+}
+
+// GetTicketPoolShare handles a getticketpoolshare request by returning the
+// wallet's share of the live ticket pool and the expected time until one of
+// its tickets votes.
+func GetTicketPoolShare(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.GetTicketPoolShareCmd)
+	if *cmd.Days <= 0 {
+		return nil, InvalidParameterError{
+			errors.New("days must be positive"),
+		}
+	}
+
+	share, err := w.TicketPoolShare(time.Duration(*cmd.Days) * 24 * time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	return &walletjson.GetTicketPoolShareResult{
+		Height:            share.Height,
+		LiveTickets:       share.LiveTickets,
+		PoolSize:          share.PoolSize,
+		Share:             share.Share,
+		VoteProbability:   share.VoteProbability,
+		ExpectedBlocks:    share.ExpectedBlocks,
+		ExpectedTime:      int64(share.ExpectedTime / time.Second),
+		Days:              *cmd.Days,
+		WithinProbability: share.WithinProbability,
+	}, nil
+}
+
+// SetCreditOrigin handles a setcreditorigin request by tagging a transaction
+// output with the origin of its funds.  The outputs of transactions spending
+// only credits of this origin inherit the tag.
+func SetCreditOrigin(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.SetCreditOriginCmd)
+
+	hash, err := chainhash.NewHashFromStr(cmd.TxID)
+	if err != nil {
+		return nil, &dcrjson.RPCError{
+			Code:    dcrjson.ErrRPCDecodeHexString,
+			Message: "Transaction hash string decode failed: " + err.Error(),
+		}
+	}
+	origin, err := wtxmgr.ParseCreditOrigin(cmd.Origin)
+	if err != nil {
+		return nil, InvalidParameterError{err}
+	}
+	op := wire.OutPoint{Hash: *hash, Index: cmd.Vout}
+	return nil, w.TxStore.SetCreditOrigin(&op, origin)
+}
+
+// SetUnlockTimeout handles a setunlocktimeout request by replacing the time
+// after which an unlocked wallet is locked again.
+func SetUnlockTimeout(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.SetUnlockTimeoutCmd)
+
+	if cmd.Timeout < 0 {
+		return nil, InvalidParameterError{
+			errors.New("timeout must not be negative"),
+		}
+	}
+	timeout := time.Second * time.Duration(cmd.Timeout)
+	err := w.SetUnlockTimeout(timeout)
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		return nil, err
+	}
+
+	if timeout > 0 {
+		rpcsLog.Infof("The wallet unlock is set to expire in %v.", timeout)
+	} else {
+		rpcsLog.Infof("The wallet unlock no longer has a time limit.")
+	}
+	return nil, nil
+}
+
 // WalletLock handles a walletlock request by locking the all account
 // wallets, returning an error if any wallet is not encrypted (for example,
 // a watching-only wallet).
@@ -4482,10 +7487,10 @@ func WalletPassphrase(w *wallet.Wallet, chainSvr *chain.Client,
 
 	if err == nil {
 		if timeout > 0 {
-			log.Infof("The wallet has been unlocked. This is set to expire  "+
+			rpcsLog.Infof("The wallet has been unlocked. This is set to expire  "+
 				"in %v.", timeout)
 		} else {
-			log.Infof("The wallet has been unlocked without a time limit.")
+			rpcsLog.Infof("The wallet has been unlocked without a time limit.")
 		}
 	}
 
@@ -18,11 +18,28 @@
 package main
 
 import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/stakepoolclient"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletjson"
+	"github.com/decred/dcrwallet/wtxmgr"
 )
 
 func TestThrottle(t *testing.T) {
@@ -55,3 +72,698 @@ func TestThrottle(t *testing.T) {
 		t.Fatalf("status codes: want: %v, got: %v", want, got)
 	}
 }
+
+func TestWebsocketSubscriptions(t *testing.T) {
+	wsc := newWebsocketClient(nil, nil, "")
+
+	// Clients which have not subscribed receive all notifications.
+	for _, typ := range wsSubscriptionMethods {
+		if !wsc.wantsNotification(typ) {
+			t.Fatalf("unsubscribed client does not want type %v", typ)
+		}
+	}
+
+	wsc.subscribe(wsSubscriptionMethods["notifytickets"])
+	tests := []struct {
+		typ  wsNotificationType
+		want bool
+	}{
+		{wsNtfnTickets, true},
+		{wsNtfnBalance, false},
+		{wsNtfnNewTransactions, false},
+		{wsNtfnBlockConnected, false},
+		{0, true},
+	}
+	for _, test := range tests {
+		if got := wsc.wantsNotification(test.typ); got != test.want {
+			t.Errorf("wantsNotification(%v): want %v, got %v",
+				test.typ, test.want, got)
+		}
+	}
+}
+
+func TestWatchConfirmations(t *testing.T) {
+	wsc := newWebsocketClient(nil, nil, "")
+
+	var hash chainhash.Hash
+	if err := wsc.watchConfirmations(&hash, 6); err != nil {
+		t.Fatal(err)
+	}
+	// Watching a transaction again replaces its threshold.
+	if err := wsc.watchConfirmations(&hash, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(wsc.confWatches) != 1 || wsc.confWatches[hash] != 1 {
+		t.Fatalf("unexpected watches %v", wsc.confWatches)
+	}
+
+	for i := 1; i < maxConfirmationWatches; i++ {
+		binary.BigEndian.PutUint32(hash[:], uint32(i))
+		if err := wsc.watchConfirmations(&hash, 1); err != nil {
+			t.Fatalf("watch %d: %v", i, err)
+		}
+	}
+	hash[31] = 1
+	if err := wsc.watchConfirmations(&hash, 1); err == nil {
+		t.Fatal("watch beyond the limit was accepted")
+	}
+}
+
+func TestUnmarshalExtendedCmd(t *testing.T) {
+	params := []json.RawMessage{
+		json.RawMessage(`"*"`),
+		json.RawMessage(`20`),
+		json.RawMessage(`0`),
+		json.RawMessage(`false`),
+		json.RawMessage(`{"txtypes":["ticket"]}`),
+	}
+	req := &dcrjson.Request{
+		Jsonrpc: "1.0",
+		Method:  "listtransactions",
+		Params:  params[:4],
+	}
+
+	cmd, err := unmarshalCmd(req)
+	if err != nil {
+		t.Fatalf("unmarshalCmd failed: %v", err)
+	}
+	if _, ok := cmd.(*dcrjson.ListTransactionsCmd); !ok {
+		t.Fatalf("unexpected command type %T", cmd)
+	}
+
+	req.Params = params
+	cmd, err = unmarshalCmd(req)
+	if err != nil {
+		t.Fatalf("unmarshalCmd failed: %v", err)
+	}
+	icmd, ext := unwrapExtendedCmd(cmd)
+	ltCmd, ok := icmd.(*dcrjson.ListTransactionsCmd)
+	if !ok {
+		t.Fatalf("unexpected command type %T", icmd)
+	}
+	if *ltCmd.Count != 20 {
+		t.Errorf("count is %d, expected 20", *ltCmd.Count)
+	}
+	var f listTransactionsFilter
+	if err := json.Unmarshal(ext, &f); err != nil {
+		t.Fatalf("unable to decode extension parameter: %v", err)
+	}
+	if !reflect.DeepEqual(f.TxTypes, []string{"ticket"}) {
+		t.Errorf("txtypes are %v, expected [ticket]", f.TxTypes)
+	}
+
+	req.Params = append(params, json.RawMessage(`1`))
+	if _, err := unmarshalCmd(req); err == nil {
+		t.Errorf("unmarshalCmd accepted too many parameters")
+	}
+}
+
+func TestClearOmittedMinConf(t *testing.T) {
+	params := []json.RawMessage{
+		json.RawMessage(`"default"`),
+		json.RawMessage(`{"DsExampleAddress": 1}`),
+		json.RawMessage(`6`),
+	}
+	tests := []struct {
+		params  []json.RawMessage
+		minConf *int
+	}{
+		{params[:2], nil},
+		{append(params[:2:2], json.RawMessage(`null`)), nil},
+		{params, func() *int { n := 6; return &n }()},
+	}
+	for i, test := range tests {
+		req := &dcrjson.Request{
+			Jsonrpc: "1.0",
+			Method:  "sendmany",
+			Params:  test.params,
+		}
+		cmd, err := unmarshalCmd(req)
+		if err != nil {
+			t.Fatalf("test %d: unmarshalCmd failed: %v", i, err)
+		}
+		smCmd, ok := cmd.(*dcrjson.SendManyCmd)
+		if !ok {
+			t.Fatalf("test %d: unexpected command type %T", i, cmd)
+		}
+		if !reflect.DeepEqual(smCmd.MinConf, test.minConf) {
+			t.Errorf("test %d: minconf is %v, expected %v", i,
+				smCmd.MinConf, test.minConf)
+		}
+	}
+}
+
+func TestRPCUserPermissions(t *testing.T) {
+	for _, s := range []string{"user:pass", "user:pass:root",
+		"user:pass:readonly:1", "user:pass:send:-1", ":pass:admin"} {
+		if _, err := parseRPCUser(s); err == nil {
+			t.Errorf("parseRPCUser accepted %q", s)
+		}
+	}
+
+	newRequest := func(method string, params ...string) *dcrjson.Request {
+		req := &dcrjson.Request{Jsonrpc: "1.0", Method: method}
+		for _, p := range params {
+			req.Params = append(req.Params, json.RawMessage(p))
+		}
+		return req
+	}
+	send := newRequest("sendtoaddress", `"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"`, `20`)
+	sendMany := newRequest("sendmany", `"default"`, `{"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu":6,"DsX2WdbGn8v1mS8Nc8cyDfhaDA7FJAahUGB":6}`)
+	tests := []struct {
+		user    string
+		req     *dcrjson.Request
+		allowed bool
+	}{
+		{"monitor:pass:readonly", newRequest("getbalance"), true},
+		{"monitor:pass:readonly", send, false},
+		{"monitor:pass:readonly", newRequest("getrawtransaction"), false},
+		{"payments:pass:send:10", send, false},
+		{"payments:pass:send:10", sendMany, false},
+		{"payments:pass:send:20", send, true},
+		{"payments:pass:send", send, true},
+		{"payments:pass:send", newRequest("purchaseticket"), false},
+		{"staker:pass:staking", newRequest("purchaseticket"), true},
+		{"staker:pass:staking", newRequest("dumpprivkey"), false},
+		{"admin:pass:admin", newRequest("dumpprivkey"), true},
+	}
+	for _, test := range tests {
+		user, err := parseRPCUser(test.user)
+		if err != nil {
+			t.Fatalf("parseRPCUser(%q) failed: %v", test.user, err)
+		}
+		jsonErr := user.checkPermission(test.req)
+		if allowed := jsonErr == nil; allowed != test.allowed {
+			t.Errorf("user %q calling %s: allowed is %v, expected %v",
+				test.user, test.req.Method, allowed, test.allowed)
+		}
+	}
+}
+
+func TestPostClientBatch(t *testing.T) {
+	s := &rpcServer{handlerLookup: unloadedWalletHandlerFunc}
+	user, err := parseRPCUser("monitor:pass:readonly")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := `[{"jsonrpc":"1.0","id":1,"method":"getbalance","params":[]},
+		{"jsonrpc":"1.0","id":2,"method":"sendtoaddress","params":[]},
+		"invalid",
+		{"jsonrpc":"1.0","id":4,"method":"authenticate","params":["monitor","pass"]},
+		{"jsonrpc":"1.0","id":5,"method":"listunspent","params":[]}]`
+	rec := httptest.NewRecorder()
+	s.postClientBatch(rec, []byte(batch), user, "")
+
+	var responses []dcrjson.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("unable to decode batch response: %v", err)
+	}
+	expectedIDs := []interface{}{1.0, 2.0, nil, 4.0, 5.0}
+	if len(responses) != len(expectedIDs) {
+		t.Fatalf("got %d responses, expected %d", len(responses),
+			len(expectedIDs))
+	}
+	for i, resp := range responses {
+		var id interface{}
+		if resp.ID != nil {
+			id = *resp.ID
+		}
+		if id != expectedIDs[i] {
+			t.Errorf("response %d has id %v, expected %v", i, id,
+				expectedIDs[i])
+		}
+		if resp.Error == nil {
+			t.Errorf("response %d has no error", i)
+		}
+	}
+	if responses[0].Error.Code != ErrUnloadedWallet.Code {
+		t.Errorf("getbalance error code is %d, expected %d",
+			responses[0].Error.Code, ErrUnloadedWallet.Code)
+	}
+	if responses[1].Error.Code != dcrjson.ErrRPCMisc {
+		t.Errorf("sendtoaddress error code is %d, expected %d",
+			responses[1].Error.Code, dcrjson.ErrRPCMisc)
+	}
+}
+
+func TestConcurrentBatchRequest(t *testing.T) {
+	tests := []struct {
+		method     string
+		concurrent bool
+	}{
+		{"getbalance", true},
+		{"listunspent", true},
+		{"validateaddress", true},
+		{"startjob", false},
+		{"sendtoaddress", false},
+		{"unknownmethod", false},
+	}
+	for _, test := range tests {
+		req := &dcrjson.Request{Method: test.method}
+		if got := concurrentBatchRequest(req); got != test.concurrent {
+			t.Errorf("%s: concurrent is %v, expected %v", test.method,
+				got, test.concurrent)
+		}
+	}
+}
+
+func TestPostClientBatchLimit(t *testing.T) {
+	s := &rpcServer{handlerLookup: unloadedWalletHandlerFunc}
+	user, err := parseRPCUser("monitor:pass:readonly")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := `{"jsonrpc":"1.0","id":1,"method":"getbalance","params":[]}`
+	reqs := make([]string, maxBatchRequests+1)
+	for i := range reqs {
+		reqs[i] = req
+	}
+	batch := "[" + strings.Join(reqs, ",") + "]"
+	rec := httptest.NewRecorder()
+	s.postClientBatch(rec, []byte(batch), user, "")
+
+	var resp dcrjson.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unable to decode batch response: %v", err)
+	}
+	if resp.Error == nil ||
+		resp.Error.Code != dcrjson.ErrRPCInvalidRequest.Code {
+		t.Errorf("oversized batch was not rejected: %v", resp.Error)
+	}
+}
+
+func TestCheckAPIVersion(t *testing.T) {
+	tests := []struct {
+		version   string
+		supported bool
+	}{
+		{fmt.Sprintf("%d.%d", jsonrpcSemverMajor, jsonrpcSemverMinor), true},
+		{fmt.Sprintf("%d.0.0", jsonrpcSemverMajor), true},
+		{fmt.Sprintf("%d.%d.0", jsonrpcSemverMajor, jsonrpcSemverMinor+1), false},
+		{fmt.Sprintf("%d.0.0", jsonrpcSemverMajor+1), false},
+		{"1", false},
+		{"1.x.0", false},
+	}
+	for _, test := range tests {
+		err := checkAPIVersion(test.version)
+		if supported := err == nil; supported != test.supported {
+			t.Errorf("API version %s: supported is %v, expected %v",
+				test.version, supported, test.supported)
+		}
+	}
+}
+
+func TestParseBalanceType(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		balanceType *string
+		flag        wtxmgr.BehaviorFlags
+		valid       bool
+	}{
+		{nil, wtxmgr.BFBalanceSpendable, true},
+		{str("spendable"), wtxmgr.BFBalanceSpendable, true},
+		{str("locked"), wtxmgr.BFBalanceLockedStake, true},
+		{str("all"), wtxmgr.BFBalanceAll, true},
+		{str("fullscan"), wtxmgr.BFBalanceFullScan, true},
+		{str("imported"), 0, false},
+	}
+	for i, test := range tests {
+		flag, err := parseBalanceType(test.balanceType)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("test %d: valid is %v, expected %v", i, valid,
+				test.valid)
+			continue
+		}
+		if flag != test.flag {
+			t.Errorf("test %d: flag is %v, expected %v", i, flag,
+				test.flag)
+		}
+	}
+}
+
+func TestRPCJobManager(t *testing.T) {
+	var m rpcJobManager
+
+	first := m.start("rescanwallet")
+	status, ok := m.status(first.id)
+	if !ok {
+		t.Fatalf("job %d is unknown", first.id)
+	}
+	if status.Status != jobStatusRunning {
+		t.Errorf("new job status is %q, expected %q", status.Status,
+			jobStatusRunning)
+	}
+
+	status = m.finish(first, "result", nil)
+	if status.Status != jobStatusDone || status.Result != "result" {
+		t.Errorf("finished job status is %q with result %v",
+			status.Status, status.Result)
+	}
+	failed := m.start("importscript")
+	status = m.finish(failed, nil, dcrjson.ErrRPCInvalidRequest)
+	if status.Status != jobStatusFailed ||
+		status.Error != dcrjson.ErrRPCInvalidRequest.Message {
+		t.Errorf("failed job status is %q with error %q",
+			status.Status, status.Error)
+	}
+
+	jobs := m.list()
+	if len(jobs) != 2 || jobs[0].JobID != first.id ||
+		jobs[1].JobID != failed.id {
+		t.Fatalf("listed jobs %v, expected jobs %d and %d", jobs,
+			first.id, failed.id)
+	}
+	if jobs[0].Result != nil {
+		t.Errorf("listed job includes result %v", jobs[0].Result)
+	}
+
+	// Finishing more jobs must forget the oldest finished job first.
+	for i := 0; i < maxFinishedJobs-1; i++ {
+		m.finish(m.start("rescanwallet"), nil, nil)
+	}
+	if _, ok := m.status(first.id); ok {
+		t.Errorf("oldest finished job %d was not forgotten", first.id)
+	}
+	if _, ok := m.status(failed.id); !ok {
+		t.Errorf("finished job %d was forgotten", failed.id)
+	}
+}
+
+func TestOfflineWalletHandlers(t *testing.T) {
+	for method := range rpcOfflineMethods {
+		if _, ok := rpcHandlers[method]; !ok {
+			t.Errorf("offline method %s has no handler", method)
+		}
+	}
+
+	// Chain server methods are passed through and fail as disconnected.
+	if _, ok := offlineWalletHandlerFunc("getblock"); ok {
+		t.Error("offline handler found for chain server method getblock")
+	}
+
+	f, ok := offlineWalletHandlerFunc("getbalance")
+	if !ok {
+		t.Fatal("no offline handler for getbalance")
+	}
+	if reflect.ValueOf(f).Pointer() !=
+		reflect.ValueOf(rpcHandlers["getbalance"].handler).Pointer() {
+		t.Error("getbalance is not handled while offline")
+	}
+
+	f, ok = offlineWalletHandlerFunc("purchaseticket")
+	if !ok {
+		t.Fatal("no offline handler for purchaseticket")
+	}
+	if _, err := f(nil, nil, nil); err != &ErrOfflineWallet {
+		t.Errorf("purchaseticket returned %v while offline, expected %v",
+			err, &ErrOfflineWallet)
+	}
+}
+
+func TestWalletNameFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		name string
+		ok   bool
+	}{
+		{"/", "", true},
+		{"/wallet/exchange", "exchange", true},
+		{"/wallet/", "", false},
+	}
+	for _, test := range tests {
+		name, ok := walletNameFromPath(test.path)
+		if name != test.name || ok != test.ok {
+			t.Errorf("path %s: got wallet %q (%v), expected %q (%v)",
+				test.path, name, ok, test.name, test.ok)
+		}
+	}
+
+	for _, name := range []string{"", ".", "..", "a/b", `a\b`} {
+		if checkWalletName(name) == nil {
+			t.Errorf("wallet name %q was accepted", name)
+		}
+	}
+	if err := checkWalletName("exchange"); err != nil {
+		t.Errorf("wallet name exchange was rejected: %v", err)
+	}
+}
+
+func TestSetBirthday(t *testing.T) {
+	for _, birthday := range []int64{0, -1} {
+		cmd := walletjson.NewSetBirthdayCmd(birthday)
+		_, err := SetBirthday(nil, nil, cmd)
+		if _, ok := err.(InvalidParameterError); !ok {
+			t.Errorf("birthday %d: got error %v, expected invalid "+
+				"parameter", birthday, err)
+		}
+	}
+
+	progress := &wallet.RescanWalletProgress{
+		StartHeight:  100,
+		Height:       150,
+		Hash:         chainhash.Hash{1},
+		Transactions: 2,
+		Done:         true,
+	}
+	result, err := rescanWalletResult(progress, wallet.ErrRescanCancelled)
+	if err != nil {
+		t.Fatalf("cancelled rescan returned error: %v", err)
+	}
+	if !result.Cancelled || result.Height != 150 ||
+		result.Hash != progress.Hash.String() {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if _, err := rescanWalletResult(progress,
+		wallet.ErrBirthdayNotEarlier); err != wallet.ErrBirthdayNotEarlier {
+		t.Errorf("got error %v, expected %v", err,
+			wallet.ErrBirthdayNotEarlier)
+	}
+}
+
+func TestDescribeScriptResult(t *testing.T) {
+	addr, err := dcrutil.DecodeAddress("Tsk7JZPtyeQHuNsSZ2K5Q8apJBusEzNtWPk",
+		&chaincfg.TestNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	desc := &wallet.ScriptDescription{
+		Script:        pkScript,
+		Class:         txscript.PubKeyHashTy,
+		StakeSubClass: txscript.NonStandardTy,
+		RequiredSigs:  1,
+		Addresses:     []dcrutil.Address{addr},
+		WalletKeys:    1,
+		Controlled:    true,
+	}
+	result := describeScriptResult(desc)
+	want := &walletjson.DescribeScriptResult{
+		Script:     hex.EncodeToString(pkScript),
+		Class:      "pubkeyhash",
+		ReqSigs:    1,
+		Addresses:  []string{addr.EncodeAddress()},
+		Hashes:     []string{hex.EncodeToString(addr.ScriptAddress())},
+		WalletKeys: 1,
+		Controlled: true,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %+v, expected %+v", result, want)
+	}
+
+	desc.Class = txscript.StakeSubmissionTy
+	desc.StakeSubClass = txscript.PubKeyHashTy
+	desc.RedeemScript = []byte{txscript.OP_TRUE}
+	result = describeScriptResult(desc)
+	if result.Class != "stakesubmission" ||
+		result.StakeSubClass != "pubkeyhash" ||
+		result.RedeemScript != "51" {
+		t.Errorf("unexpected stake result %+v", result)
+	}
+}
+
+func TestDecodeRawTransactionResult(t *testing.T) {
+	ticket := chainhash.Hash{1}
+	block := chainhash.Hash{2}
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(&wire.TxIn{ValueIn: 1e8})
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: ticket, Tree: 1},
+		ValueIn:          2e8,
+	})
+	tx.AddTxOut(&wire.TxOut{Value: 3e8, PkScript: []byte{txscript.OP_TRUE}})
+
+	decoded := &wallet.DecodedTx{
+		Hash:   tx.TxSha(),
+		Type:   stake.TxTypeSSGen,
+		Inputs: []wallet.DecodedInput{{}, {Mine: true}},
+		Outputs: []*wallet.ScriptDescription{{
+			Script:        []byte{txscript.OP_TRUE},
+			Class:         txscript.NonStandardTy,
+			StakeSubClass: txscript.NonStandardTy,
+		}},
+		Stake: &wallet.StakeDetails{Vote: &wallet.VoteDetails{
+			Ticket:      ticket,
+			BlockHash:   block,
+			BlockHeight: 100,
+			VoteBits:    1,
+		}},
+	}
+	result := decodeRawTransactionResult(tx, decoded)
+
+	if result.TxID != decoded.Hash.String() || result.Type != "vote" {
+		t.Errorf("unexpected transaction %v of type %v", result.TxID,
+			result.Type)
+	}
+	if len(result.Vin) != 2 || !result.Vin[0].Stakebase ||
+		result.Vin[0].Mine || result.Vin[1].Stakebase ||
+		!result.Vin[1].Mine || result.Vin[1].AmountIn != 2 ||
+		result.Vin[1].TxID != ticket.String() || result.Vin[1].Tree != 1 {
+		t.Errorf("unexpected inputs %+v", result.Vin)
+	}
+	if len(result.Vout) != 1 || result.Vout[0].Value != 3 ||
+		result.Vout[0].ScriptPubKey.Class != "nonstandard" {
+		t.Errorf("unexpected outputs %+v", result.Vout)
+	}
+	wantVote := &walletjson.VoteDetailsResult{
+		Ticket:      ticket.String(),
+		BlockHash:   block.String(),
+		BlockHeight: 100,
+		VoteBits:    1,
+	}
+	if !reflect.DeepEqual(result.Vote, wantVote) {
+		t.Errorf("got vote %+v, expected %+v", result.Vote, wantVote)
+	}
+	if result.Ticket != nil || result.Revocation != nil {
+		t.Errorf("unexpected ticket or revocation details")
+	}
+}
+
+func TestNewSigningPolicy(t *testing.T) {
+	cfg := &config{RPCAuth: []string{"monitor:pass:readonly",
+		"payments:pass:send:10", "admin:pass:admin"}}
+	policy, err := newSigningPolicy(cfg)
+	if err != nil || policy != nil {
+		t.Fatalf("expected no policy without policy options, got %v "+
+			"(err %v)", policy, err)
+	}
+
+	cfg.PolicyDailyLimit = 50
+	cfg.PolicyAllowAddress = []string{"Tsk7JZPtyeQHuNsSZ2K5Q8apJBusEzNtWPk"}
+	cfg.PolicyMinConf = 6
+	cfg.PolicyHours = "09:00-17:00"
+	policy, err = newSigningPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOrigins := map[string]struct{}{"rpc:monitor": {}, "rpc:payments": {}}
+	if !reflect.DeepEqual(policy.Origins, wantOrigins) {
+		t.Errorf("got restricted origins %v, expected %v",
+			policy.Origins, wantOrigins)
+	}
+	if policy.DailyLimit != 50e8 || policy.MinConf != 6 ||
+		policy.Hours == nil || len(policy.BlockedAddresses) != 0 {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+	if _, ok := policy.AllowedAddresses[cfg.PolicyAllowAddress[0]]; !ok {
+		t.Errorf("allowed address missing from policy")
+	}
+
+	for _, mutate := range []func(*config){
+		func(cfg *config) { cfg.PolicyDailyLimit = -1 },
+		func(cfg *config) { cfg.PolicyMinConf = -1 },
+		func(cfg *config) { cfg.PolicyHours = "9-5" },
+		func(cfg *config) {
+			cfg.PolicyBlockAddress = []string{"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"}
+		},
+	} {
+		invalid := *cfg
+		mutate(&invalid)
+		if _, err := newSigningPolicy(&invalid); err == nil {
+			t.Errorf("invalid policy options accepted: %+v", invalid)
+		}
+	}
+}
+
+func TestNewSendApprovalPolicy(t *testing.T) {
+	cfg := &config{ApprovalExpiry: defaultApprovalExpiry}
+	policy, err := newSendApprovalPolicy(cfg)
+	if err != nil || policy != nil {
+		t.Fatalf("expected no policy without a threshold, got %v "+
+			"(err %v)", policy, err)
+	}
+
+	cfg.ApprovalThreshold = 100
+	cfg.ApprovalAddress = "Tsk7JZPtyeQHuNsSZ2K5Q8apJBusEzNtWPk"
+	policy, err = newSendApprovalPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Threshold != 100e8 || policy.Expiry != defaultApprovalExpiry {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+
+	for _, mutate := range []func(*config){
+		func(cfg *config) { cfg.ApprovalThreshold = -1 },
+		func(cfg *config) { cfg.ApprovalExpiry = 0 },
+		func(cfg *config) {
+			cfg.ApprovalAddress = "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"
+		},
+	} {
+		invalid := *cfg
+		mutate(&invalid)
+		if _, err := newSendApprovalPolicy(&invalid); err == nil {
+			t.Errorf("invalid approval options accepted: %+v", invalid)
+		}
+	}
+}
+
+func TestDecodeVSPPurchaseInfo(t *testing.T) {
+	script := []byte{txscript.OP_TRUE}
+	scriptAddr, err := dcrutil.NewAddressScriptHash(script, activeNet.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := stakepoolclient.PurchaseInfo{
+		PoolAddress:   "Tsk7JZPtyeQHuNsSZ2K5Q8apJBusEzNtWPk",
+		PoolFees:      7.5,
+		Script:        hex.EncodeToString(script),
+		TicketAddress: scriptAddr.EncodeAddress(),
+	}
+	decoded, err := decodeVSPPurchaseInfo(&info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ticketAddr.EncodeAddress() != info.TicketAddress ||
+		decoded.poolAddr.EncodeAddress() != info.PoolAddress ||
+		decoded.poolFees != info.PoolFees ||
+		!reflect.DeepEqual(decoded.script, script) {
+		t.Errorf("unexpected decoded purchase info %+v", decoded)
+	}
+
+	for _, mutate := range []func(*stakepoolclient.PurchaseInfo){
+		func(info *stakepoolclient.PurchaseInfo) {
+			info.TicketAddress = info.PoolAddress
+		},
+		func(info *stakepoolclient.PurchaseInfo) {
+			info.PoolAddress = info.TicketAddress
+		},
+		func(info *stakepoolclient.PurchaseInfo) {
+			info.PoolAddress = "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"
+		},
+		func(info *stakepoolclient.PurchaseInfo) { info.PoolFees = 0 },
+		func(info *stakepoolclient.PurchaseInfo) { info.PoolFees = 100 },
+		func(info *stakepoolclient.PurchaseInfo) { info.Script = "zz" },
+	} {
+		invalid := info
+		mutate(&invalid)
+		if _, err := decodeVSPPurchaseInfo(&invalid); err == nil {
+			t.Errorf("invalid purchase info accepted: %+v", invalid)
+		}
+	}
+}
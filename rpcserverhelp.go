@@ -10,48 +10,49 @@ func helpDescsEnUS() map[string]string {
 		"getaccount":              "getaccount \"address\"\n\nDEPRECATED -- Lookup the account name that some wallet address belongs to.\n\nArguments:\n1. address (string, required) The address to query the account for\n\nResult:\n\"value\" (string) The name of the account that 'address' belongs to\n",
 		"getaccountaddress":       "getaccountaddress \"account\"\n\nDEPRECATED -- Returns the most recent external payment address for an account that has not been seen publicly.\nA new address is generated for the account if the most recently generated address has been seen on the blockchain or in mempool.\n\nArguments:\n1. account (string, required) The account of the returned address\n\nResult:\n\"value\" (string) The unused address for 'account'\n",
 		"getaddressesbyaccount":   "getaddressesbyaccount \"account\"\n\nDEPRECATED -- Returns all addresses strings controlled by a single account.\n\nArguments:\n1. account (string, required) Account name to fetch addresses for\n\nResult:\n[\"value\",...] (array of string) All addresses controlled by 'account'\n",
-		"getbalance":              "getbalance (\"account\" minconf=1 \"balancetype\")\n\nCalculates and returns the balance of one or all accounts.\n\nArguments:\n1. account     (string, optional)             DEPRECATED -- The account name to query the balance for, or \"*\" to consider all accounts (default=\"*\")\n2. minconf     (numeric, optional, default=1) Minimum number of block confirmations required before an unspent output's value is included in the balance\n3. balancetype (string, optional)             The type of balance to return, 'spendable', 'locked', 'all', or 'fullscan'\n\nResult (account != \"*\"):\nn.nnn (numeric) The balance of 'account' valued in decred\n\nResult (account = \"*\"):\nn.nnn (numeric) The balance of all accounts valued in decred\n",
+		"getbalance":              "getbalance (\"account\" minconf=1 \"balancetype\")\n\nCalculates and returns the balance of one or all accounts.\n\nArguments:\n1. account     (string, optional)             The account name to query the balance for, \"default\" for the balance of the whole wallet, or \"*\" for the balance of each account (default=\"default\")\n2. minconf     (numeric, optional, default=1) Minimum number of block confirmations required before an unspent output's value is included in the balance\n3. balancetype (string, optional)             The type of balance to return, 'spendable', 'locked' (value locked in tickets), 'all' (all unspent outputs), or 'fullscan' (spendable balance verified by a scan of all unspent outputs)\n\nResult (account != \"*\"):\nn.nnn (numeric) The balance of 'account' valued in decred\n\nResult (account = \"*\"):\n{\n \"The account name\": The balance of the account valued in decred, (object) JSON object with account names as keys and decred amounts as values\n ...\n}\n",
 		"getbestblockhash":        "getbestblockhash\n\nReturns the hash of the newest block in the best chain that wallet has finished syncing with.\n\nArguments:\nNone\n\nResult:\n\"value\" (string) The hash of the most recent synced-to block\n",
 		"getblockcount":           "getblockcount\n\nReturns the blockchain height of the newest block in the best chain that wallet has finished syncing with.\n\nArguments:\nNone\n\nResult:\nn.nnn (numeric) The blockchain height of the most recent synced-to block\n",
 		"getinfo":                 "getinfo\n\nReturns a JSON object containing various state info.\n\nArguments:\nNone\n\nResult:\n{\n \"version\": n,          (numeric) The version of the server\n \"protocolversion\": n,  (numeric) The latest supported protocol version\n \"walletversion\": n,    (numeric) The version of the address manager database\n \"balance\": n.nnn,      (numeric) The balance of all accounts calculated with one block confirmation\n \"blocks\": n,           (numeric) The number of blocks processed\n \"timeoffset\": n,       (numeric) The time offset\n \"connections\": n,      (numeric) The number of connected peers\n \"proxy\": \"value\",      (string)  The proxy used by the server\n \"difficulty\": n.nnn,   (numeric) The current target difficulty\n \"testnet\": true|false, (boolean) Whether or not server is using testnet\n \"keypoololdest\": n,    (numeric) Unset\n \"keypoolsize\": n,      (numeric) Unset\n \"unlocked_until\": n,   (numeric) Unset\n \"paytxfee\": n.nnn,     (numeric) The increment used each time more fee is required for an authored transaction\n \"relayfee\": n.nnn,     (numeric) The minimum relay fee for non-free transactions in DCR/KB\n \"errors\": \"value\",     (string)  Any current errors\n}                       \n",
 		"getmasterpubkey":         "getmasterpubkey\n\nRequests the master pubkey from the wallet.\n\nArguments:\nNone\n\nResult:\n{\n \"key\": \"value\", (string) The master pubkey for the wallet\n}                \n",
 		"getmultisigoutinfo":      "getmultisigoutinfo \"hash\" index\n\nReturns information about a multisignature output.\n\nArguments:\n1. hash  (string, required)  Input hash to check.\n2. index (numeric, required) Index of input.\n\nResult:\n{\n \"address\": \"value\",       (string)          Script address.\n \"redeemscript\": \"value\",  (string)          Hex of the redeeming script.\n \"m\": n,                   (numeric)         m (in m-of-n)\n \"n\": n,                   (numeric)         n (in m-of-n)\n \"pubkeys\": [\"value\",...], (array of string) Associated pubkeys.\n \"txhash\": \"value\",        (string)          txhash\n \"blockheight\": n,         (numeric)         Height of the containing block.\n \"blockhash\": \"value\",     (string)          Hash of the containing block.\n \"spent\": true|false,      (boolean)         If it has been spent.\n \"spentby\": \"value\",       (string)          Hash of spending tx.\n \"spentbyindex\": n,        (numeric)         Index of spending tx.\n \"amount\": n.nnn,          (numeric)         Amount of coins contained.\n}                          \n",
 		"getseed":                 "getseed\n\nReturns the seed needed to recreate the wallet.\n\nArguments:\nNone\n\nResult:\n{\n \"seed\": \"value\", (string) The seed cooresponding to the wallet.\n}                 \n",
-		"getnewaddress":           "getnewaddress (\"account\" verbose=false)\n\nGenerates and returns a new payment address.\n\nArguments:\n1. account (string, optional)                 DEPRECATED -- Account name the new address will belong to (default=\"default\")\n2. verbose (boolean, optional, default=false) Show pub key as well as address\n\nResult:\n\"value\" (string) The payment address\n",
+		"getnewaddress":           "getnewaddress (\"account\" verbose=false)\n\nGenerates and returns a new payment address.  An options object may be passed as a third parameter with the optional keys \"type\" (the address type: \"p2pkh\", the default, \"p2pk\" for the public key, or \"p2sh\" for a 1-of-1 multisig script of the public key, which is imported) and \"gappolicy\" (\"ignore\", the default, \"warn\", or \"error\" to refuse addresses following gap limit unused addresses).  The reply is then an object with the \"address\", its \"type\", the \"pubkey\", the \"redeemscript\" of P2SH addresses, the \"gap\" of unused addresses preceding the address, whether it \"exceedsgaplimit\", and a \"warning\" when the gap policy is \"warn\".\n\nArguments:\n1. account (string, optional)                 DEPRECATED -- Account name the new address will belong to (default=\"default\")\n2. verbose (boolean, optional, default=false) Show pub key as well as address\n\nResult:\n\"value\" (string) The payment address\n",
 		"getrawchangeaddress":     "getrawchangeaddress (\"account\" verbose=false)\n\nGenerates and returns a new internal payment address for use as a change address in raw transactions.\n\nArguments:\n1. account (string, optional)                 Account name the new internal address will belong to (default=\"default\")\n2. verbose (boolean, optional, default=false) Show pub key as well as address\n\nResult:\n\"value\" (string) The internal payment address\n",
-		"getreceivedbyaccount":    "getreceivedbyaccount \"account\" (minconf=1)\n\nDEPRECATED -- Returns the total amount received by addresses of some account, including spent outputs.\n\nArguments:\n1. account (string, required)             Account name to query total received amount for\n2. minconf (numeric, optional, default=1) Minimum number of block confirmations required before an output's value is included in the total\n\nResult:\nn.nnn (numeric) The total received amount valued in decred\n",
-		"getreceivedbyaddress":    "getreceivedbyaddress \"address\" (minconf=1)\n\nReturns the total amount received by a single address, including spent outputs.\n\nArguments:\n1. address (string, required)             Payment address which received outputs to include in total\n2. minconf (numeric, optional, default=1) Minimum number of block confirmations required before an output's value is included in the total\n\nResult:\nn.nnn (numeric) The total received amount valued in decred\n",
+		"getreceivedbyaccount":    "getreceivedbyaccount \"account\" (minconf=1)\n\nDEPRECATED -- Returns the total amount received by addresses of some account, including spent outputs. Outputs of stake transactions which are not yet mature are excluded unless an options object with the key \"includeimmaturestake\" set to true is passed as a third parameter.\n\nArguments:\n1. account (string, required)             Account name to query total received amount for\n2. minconf (numeric, optional, default=1) Minimum number of block confirmations required before an output's value is included in the total\n\nResult:\nn.nnn (numeric) The total received amount valued in decred\n",
+		"getreceivedbyaddress":    "getreceivedbyaddress \"address\" (minconf=1)\n\nReturns the total amount received by a single address, including spent outputs. Outputs of stake transactions which are not yet mature are excluded unless an options object with the key \"includeimmaturestake\" set to true is passed as a third parameter.\n\nArguments:\n1. address (string, required)             Payment address which received outputs to include in total\n2. minconf (numeric, optional, default=1) Minimum number of block confirmations required before an output's value is included in the total\n\nResult:\nn.nnn (numeric) The total received amount valued in decred\n",
 		"gettickets":              "gettickets includeimmature\n\nReturning the hashes of the tickets currently owned by wallet.\n\nArguments:\n1. includeimmature (boolean, required) If true include immature tickets in the results.\n\nResult:\n{\n \"hashes\": [\"value\",...], (array of string) Hashes of the tickets owned by the wallet encoded as strings\n}                         \n",
 		"getticketmaxprice":       "getticketmaxprice\n\nReturns the max price the wallet will pay for a ticket.\n\nArguments:\nNone\n\nResult:\nn.nnn (numeric) Max price wallet will spend on a ticket.\n",
-		"gettransaction":          "gettransaction \"txid\" (includewatchonly=false)\n\nReturns a JSON object with details regarding a transaction relevant to this wallet.\n\nArguments:\n1. txid             (string, required)                 Hash of the transaction to query\n2. includewatchonly (boolean, optional, default=false) Also consider transactions involving watched addresses\n\nResult:\n{\n \"amount\": n.nnn,                  (numeric)         The total amount this transaction credits to the wallet, valued in decred\n \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value, or 0 if 'txid' is not a sent transaction\n \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n \"blockindex\": n,                  (numeric)         Unset\n \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n \"txid\": \"value\",                  (string)          The transaction hash\n \"walletconflicts\": [\"value\",...], (array of string) Unset\n \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n \"details\": [{                     (array of object) Additional details for each recorded wallet credit and debit\n  \"account\": \"value\",              (string)          DEPRECATED -- Unset\n  \"address\": \"value\",              (string)          The address an output was paid to, or the empty string if the output is nonstandard or this detail is regarding a transaction input\n  \"amount\": n.nnn,                 (numeric)         The amount of a received output\n  \"category\": \"value\",             (string)          The kind of detail: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs\n  \"involveswatchonly\": true|false, (boolean)         Unset\n  \"fee\": n.nnn,                    (numeric)         The included fee for a sent transaction\n  \"vout\": n,                       (numeric)         The transaction output index\n },...],                                             \n \"hex\": \"value\",                   (string)          The transaction encoded as a hexadecimal string\n}                                  \n",
+		"gettransaction":          "gettransaction \"txid\" (includewatchonly=false)\n\nReturns a JSON object with details regarding a transaction relevant to this wallet. An options object with the key \"verbose\" set to true may be passed as a third parameter to include the details of stake transactions: the \"ticket\" price and commitments, the \"vote\" ticket, block voted on, and vote bits, or the \"revocation\" ticket and refunded amount. Verbose results of mined regular transactions also include the \"approval\" status of their block: \"pending\" until the next block is seen, then \"approved\" or \"disapproved\" by the votes of the next block. Verbose results of transactions authored by the wallet also include the \"change\" outputs, with the address, account, and internal branch child index of each change address, so the change may be verified against the account extended public key.\n\nArguments:\n1. txid             (string, required)                 Hash of the transaction to query\n2. includewatchonly (boolean, optional, default=false) Also consider transactions involving watched addresses\n\nResult:\n{\n \"amount\": n.nnn,                  (numeric)         The total amount this transaction credits to the wallet, valued in decred\n \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value, or 0 if 'txid' is not a sent transaction\n \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n \"blockindex\": n,                  (numeric)         Unset\n \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n \"txid\": \"value\",                  (string)          The transaction hash\n \"walletconflicts\": [\"value\",...], (array of string) Unset\n \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n \"details\": [{                     (array of object) Additional details for each recorded wallet credit and debit\n  \"account\": \"value\",              (string)          DEPRECATED -- Unset\n  \"address\": \"value\",              (string)          The address an output was paid to, or the empty string if the output is nonstandard or this detail is regarding a transaction input\n  \"amount\": n.nnn,                 (numeric)         The amount of a received output\n  \"category\": \"value\",             (string)          The kind of detail: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs\n  \"involveswatchonly\": true|false, (boolean)         Unset\n  \"fee\": n.nnn,                    (numeric)         The included fee for a sent transaction\n  \"vout\": n,                       (numeric)         The transaction output index\n },...],                                             \n \"hex\": \"value\",                   (string)          The transaction encoded as a hexadecimal string\n}                                  \n",
 		"help":                    "help (\"command\")\n\nReturns a list of all commands or help for a specified command.\n\nArguments:\n1. command (string, optional) The command to retrieve help for\n\nResult (no command provided):\n\"value\" (string) List of commands\n\nResult (command specified):\n\"value\" (string) Help for specified command\n",
 		"importprivkey":           "importprivkey \"privkey\" (\"label\" rescan=true)\n\nImports a WIF-encoded private key to the 'imported' account.\n\nArguments:\n1. privkey (string, required)                The WIF-encoded private key\n2. label   (string, optional)                Unused (must be unset or 'imported')\n3. rescan  (boolean, optional, default=true) Rescan the blockchain (since the genesis block) for outputs controlled by the imported key\n\nResult:\nNothing\n",
-		"importscript":            "importscript \"hex\"\n\nImport a redeem script.\n\nArguments:\n1. hex (string, required) Hex encoded script to import\n\nResult:\nNothing\n",
+		"importscript":            "importscript \"hex\"\n\nImport a redeem script.  An options object may be passed as a second parameter with the key \"firstseen\", the height of the first block using the script.  Only the blocks since that height are then rescanned, and the reply is an object with the \"address\" of the script, the \"scriptaddresses\" the script pays to, and the unspent \"outputs\" to the script found by the rescan, in the format of listunspent results.\n\nArguments:\n1. hex (string, required) Hex encoded script to import\n\nResult:\nNothing\n",
 		"keypoolrefill":           "keypoolrefill (newsize=100)\n\nDEPRECATED -- This request does nothing since no keypool is maintained.\n\nArguments:\n1. newsize (numeric, optional, default=100) Unused\n\nResult:\nNothing\n",
 		"listaccounts":            "listaccounts (minconf=1)\n\nDEPRECATED -- Returns a JSON object of all accounts and their balances.\n\nArguments:\n1. minconf (numeric, optional, default=1) Minimum number of block confirmations required before an unspent output's value is included in the balance\n\nResult:\n{\n \"The account name\": The account balance valued in decred, (object) JSON object with account names as keys and decred amounts as values\n ...\n}\n",
 		"listlockunspent":         "listlockunspent\n\nReturns a JSON array of outpoints marked as locked (with lockunspent) for this wallet session.\n\nArguments:\nNone\n\nResult:\n[{\n \"txid\": \"value\", (string)  The transaction hash of the referenced output\n \"vout\": n,       (numeric) The output index of the referenced output\n \"tree\": n,       (numeric) The tree to generate transaction for\n},...]\n",
 		"listreceivedbyaccount":   "listreceivedbyaccount (minconf=1 includeempty=false includewatchonly=false)\n\nDEPRECATED -- Returns a JSON array of objects listing all accounts and the total amount received by each account.\n\nArguments:\n1. minconf          (numeric, optional, default=1)     Minimum number of block confirmations required before a transaction is considered\n2. includeempty     (boolean, optional, default=false) Unused\n3. includewatchonly (boolean, optional, default=false) Unused\n\nResult:\n[{\n \"account\": \"value\", (string)  The name of the account\n \"amount\": n.nnn,    (numeric) Total amount received by payment addresses of the account valued in decred\n \"confirmations\": n, (numeric) Number of block confirmations of the most recent transaction relevant to the account\n},...]\n",
 		"listreceivedbyaddress":   "listreceivedbyaddress (minconf=1 includeempty=false includewatchonly=false)\n\nReturns a JSON array of objects listing wallet payment addresses and their total received amounts.\n\nArguments:\n1. minconf          (numeric, optional, default=1)     Minimum number of block confirmations required before a transaction is considered\n2. includeempty     (boolean, optional, default=false) Unused\n3. includewatchonly (boolean, optional, default=false) Unused\n\nResult:\n[{\n \"account\": \"value\",              (string)          DEPRECATED -- Unset\n \"address\": \"value\",              (string)          The payment address\n \"amount\": n.nnn,                 (numeric)         Total amount received by the payment address valued in decred\n \"confirmations\": n,              (numeric)         Number of block confirmations of the most recent transaction relevant to the address\n \"txids\": [\"value\",...],          (array of string) Transaction hashes of all transactions involving this address\n \"involvesWatchonly\": true|false, (boolean)         Unset\n},...]\n",
 		"listsinceblock":          "listsinceblock (\"blockhash\" targetconfirmations=1 includewatchonly=false)\n\nReturns a JSON array of objects listing details of all wallet transactions after some block.\n\nArguments:\n1. blockhash           (string, optional)                 Hash of the parent block of the first block to consider transactions from, or unset to list all transactions\n2. targetconfirmations (numeric, optional, default=1)     Minimum number of block confirmations of the last block in the result object.  Must be 1 or greater.  Note: The transactions array in the result object is not affected by this parameter\n3. includewatchonly    (boolean, optional, default=false) Unused\n\nResult:\n{\n \"transactions\": [{                 (array of object) JSON array of objects containing verbose details of the each transaction\n  \"account\": \"value\",               (string)          DEPRECATED -- Unset\n  \"address\": \"value\",               (string)          Payment address for a transaction output\n  \"amount\": n.nnn,                  (numeric)         The value of the transaction output valued in decred\n  \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n  \"blockindex\": n,                  (numeric)         Unset\n  \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n  \"category\": \"value\",              (string)          The kind of transaction: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs.  Note: A single output may be included multiple times under different categories\n  \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n  \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value for sent transactions\n  \"generated\": true|false,          (boolean)         Whether the transaction output is a coinbase output\n  \"involveswatchonly\": true|false,  (boolean)         Unset\n  \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n  \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n  \"txid\": \"value\",                  (string)          The hash of the transaction\n  \"vout\": n,                        (numeric)         The transaction output index\n  \"walletconflicts\": [\"value\",...], (array of string) Unset\n  \"comment\": \"value\",               (string)          Unset\n  \"otheraccount\": \"value\",          (string)          Unset\n },...],                                              \n \"lastblock\": \"value\",              (string)          Hash of the latest-synced block to be used in later calls to listsinceblock\n}                                   \n",
-		"listtransactions":        "listtransactions (\"account\" count=10 from=0 includewatchonly=false)\n\nReturns a JSON array of objects containing verbose details for wallet transactions.\n\nArguments:\n1. account          (string, optional)                 DEPRECATED -- Unused (must be unset or \"*\")\n2. count            (numeric, optional, default=10)    Maximum number of transactions to create results from\n3. from             (numeric, optional, default=0)     Number of transactions to skip before results are created\n4. includewatchonly (boolean, optional, default=false) Unused\n\nResult:\n[{\n \"account\": \"value\",               (string)          DEPRECATED -- Unset\n \"address\": \"value\",               (string)          Payment address for a transaction output\n \"amount\": n.nnn,                  (numeric)         The value of the transaction output valued in decred\n \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n \"blockindex\": n,                  (numeric)         Unset\n \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n \"category\": \"value\",              (string)          The kind of transaction: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs.  Note: A single output may be included multiple times under different categories\n \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value for sent transactions\n \"generated\": true|false,          (boolean)         Whether the transaction output is a coinbase output\n \"involveswatchonly\": true|false,  (boolean)         Unset\n \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n \"txid\": \"value\",                  (string)          The hash of the transaction\n \"vout\": n,                        (numeric)         The transaction output index\n \"walletconflicts\": [\"value\",...], (array of string) Unset\n \"comment\": \"value\",               (string)          Unset\n \"otheraccount\": \"value\",          (string)          Unset\n},...]\n",
-		"listunspent":             "listunspent (minconf=1 maxconf=9999999 [\"address\",...])\n\nReturns a JSON array of objects representing unlocked unspent outputs controlled by wallet keys.\n\nArguments:\n1. minconf   (numeric, optional, default=1)       Minimum number of block confirmations required before a transaction output is considered\n2. maxconf   (numeric, optional, default=9999999) Maximum number of block confirmations required before a transaction output is excluded\n3. addresses (array of string, optional)          If set, limits the returned details to unspent outputs received by any of these payment addresses\n\nResult:\n{\n \"txid\": \"value\",         (string)  The transaction hash of the referenced output\n \"vout\": n,               (numeric) The output index of the referenced output\n \"tree\": n,               (numeric) The tree the transaction comes from\n \"txtype\": n,             (numeric) The type of the transaction\n \"address\": \"value\",      (string)  The payment address that received the output\n \"account\": \"value\",      (string)  The account associated with the receiving payment address\n \"scriptPubKey\": \"value\", (string)  The output script encoded as a hexadecimal string\n \"redeemScript\": \"value\", (string)  Unset\n \"amount\": n.nnn,         (numeric) The amount of the output valued in decred\n \"confirmations\": n,      (numeric) The number of block confirmations of the transaction\n}                         \n",
+		"listtransactions":        "listtransactions (\"account\" count=10 from=0 includewatchonly=false)\n\nReturns a JSON array of objects containing verbose details for wallet transactions.  A filter object may be passed as a fifth parameter with the optional keys \"addresses\" (array of addresses), \"txtypes\" (array of \"regular\", \"ticket\", \"vote\", or \"revocation\"), \"starttime\" and \"endtime\" (Unix times), and \"cursor\" (the \"nextcursor\" of a previous reply), in which case the results are returned in an object under \"transactions\" together with the \"nextcursor\" of the next page.  Transactions stamped with a fiat exchange rate when they were first seen are included in the \"fiatrates\" object of the reply, mapping their txid to an object with the \"currency\", the \"rate\" per coin, and the Unix \"time\" it was quoted.\n\nArguments:\n1. account          (string, optional)                 The account to list transactions of, or \"*\" for all accounts\n2. count            (numeric, optional, default=10)    Maximum number of transactions to create results from\n3. from             (numeric, optional, default=0)     Number of transactions to skip before results are created\n4. includewatchonly (boolean, optional, default=false) Unused\n\nResult:\n[{\n \"account\": \"value\",               (string)          DEPRECATED -- Unset\n \"address\": \"value\",               (string)          Payment address for a transaction output\n \"amount\": n.nnn,                  (numeric)         The value of the transaction output valued in decred\n \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n \"blockindex\": n,                  (numeric)         Unset\n \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n \"category\": \"value\",              (string)          The kind of transaction: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs.  Note: A single output may be included multiple times under different categories\n \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value for sent transactions\n \"generated\": true|false,          (boolean)         Whether the transaction output is a coinbase output\n \"involveswatchonly\": true|false,  (boolean)         Unset\n \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n \"txid\": \"value\",                  (string)          The hash of the transaction\n \"vout\": n,                        (numeric)         The transaction output index\n \"walletconflicts\": [\"value\",...], (array of string) Unset\n \"comment\": \"value\",               (string)          Unset\n \"otheraccount\": \"value\",          (string)          Unset\n},...]\n",
+		"listunspent":             "listunspent (minconf=1 maxconf=9999999 [\"address\",...])\n\nReturns a JSON array of objects representing unlocked unspent outputs controlled by wallet keys.  An options object may be passed as a fourth parameter with the optional keys \"account\" (the account of the outputs) and \"includeimmaturestake\" (include stake outputs which are not yet spendable), in which case each result additionally includes the \"scriptclass\" of the output script, whether the output is \"spendable\", and the \"reason\" it is not.\n\nArguments:\n1. minconf   (numeric, optional, default=1)       Minimum number of block confirmations required before a transaction output is considered\n2. maxconf   (numeric, optional, default=9999999) Maximum number of block confirmations required before a transaction output is excluded\n3. addresses (array of string, optional)          If set, limits the returned details to unspent outputs received by any of these payment addresses\n\nResult:\n{\n \"txid\": \"value\",         (string)  The transaction hash of the referenced output\n \"vout\": n,               (numeric) The output index of the referenced output\n \"tree\": n,               (numeric) The tree the transaction comes from\n \"txtype\": n,             (numeric) The type of the transaction\n \"address\": \"value\",      (string)  The payment address that received the output\n \"account\": \"value\",      (string)  The account associated with the receiving payment address\n \"scriptPubKey\": \"value\", (string)  The output script encoded as a hexadecimal string\n \"redeemScript\": \"value\", (string)  Unset\n \"amount\": n.nnn,         (numeric) The amount of the output valued in decred\n \"confirmations\": n,      (numeric) The number of block confirmations of the transaction\n}                         \n",
 		"lockunspent":             "lockunspent unlock [{\"txid\":\"value\",\"vout\":n,\"tree\":n},...]\n\nLocks or unlocks an unspent output.\nLocked outputs are not chosen for transaction inputs of authored transactions and are not included in 'listunspent' results.\nLocked outputs are volatile and are not saved across wallet restarts.\nIf unlock is true and no transaction outputs are specified, all locked outputs are marked unlocked.\n\nArguments:\n1. unlock       (boolean, required)         True to unlock outputs, false to lock\n2. transactions (array of object, required) Transaction outputs to lock or unlock\n[{\n \"txid\": \"value\", (string)  The transaction hash of the referenced output\n \"vout\": n,       (numeric) The output index of the referenced output\n \"tree\": n,       (numeric) The tree to generate transaction for\n},...]\n\nResult:\ntrue|false (boolean) The boolean 'true'\n",
 		"redeemmultisigout":       "redeemmultisigout \"hash\" index tree (\"address\")\n\nTakes the input and constructs a P2PKH paying to the specified address.\n\nArguments:\n1. hash    (string, required)  Hash of the input transaction\n2. index   (numeric, required) Idx of the input transaction\n3. tree    (numeric, required) Tree the transaction is on.\n4. address (string, optional)  Address to pay to.\n\nResult:\n{\n \"hex\": \"value\",         (string)          Resulting hash.\n \"complete\": true|false, (boolean)         Shows if opperation was completed.\n \"errors\": [{            (array of object) Any errors generated.\n  \"txid\": \"value\",       (string)          The transaction hash of the referenced previous output\n  \"vout\": n,             (numeric)         The output index of the referenced previous output\n  \"scriptSig\": \"value\",  (string)          The hex-encoded signature script\n  \"sequence\": n,         (numeric)         Script sequence number\n  \"error\": \"value\",      (string)          Verification or signing error related to the input\n },...],                                   \n}                        \n",
 		"redeemmultisigouts":      "redeemmultisigouts \"fromscraddress\" (\"toaddress\" number)\n\nTakes a hash, looks up all unspent outpoints and generates list artially signed transactions spending to either an address specified or internal addresses\n\nArguments:\n1. fromscraddress (string, required)  Input script hash address.\n2. toaddress      (string, optional)  Address to look for (if not internal addresses).\n3. number         (numeric, optional) Number of outpoints found.\n\nResult:\n{\n \"hex\": \"value\",         (string)          Resulting hash.\n \"complete\": true|false, (boolean)         Shows if opperation was completed.\n \"errors\": [{            (array of object) Any errors generated.\n  \"txid\": \"value\",       (string)          The transaction hash of the referenced previous output\n  \"vout\": n,             (numeric)         The output index of the referenced previous output\n  \"scriptSig\": \"value\",  (string)          The hex-encoded signature script\n  \"sequence\": n,         (numeric)         Script sequence number\n  \"error\": \"value\",      (string)          Verification or signing error related to the input\n },...],                                   \n}                        \n",
-		"sendfrom":                "sendfrom \"fromaccount\" \"toaddress\" amount (minconf=1 \"comment\" \"commentto\")\n\nDEPRECATED -- Authors, signs, and sends a transaction that outputs some amount to a payment address.\nA change output is automatically included to send extra output value back to the original account.\n\nArguments:\n1. fromaccount (string, required)             Account to pick unspent outputs from\n2. toaddress   (string, required)             Address to pay\n3. amount      (numeric, required)            Amount to send to the payment address valued in decred\n4. minconf     (numeric, optional, default=1) Minimum number of block confirmations required before a transaction output is eligible to be spent\n5. comment     (string, optional)             Unused\n6. commentto   (string, optional)             Unused\n\nResult:\n\"value\" (string) The transaction hash of the sent transaction\n",
-		"sendmany":                "sendmany \"fromaccount\" {\"address\":amount,...} (minconf=1 \"comment\")\n\nAuthors, signs, and sends a transaction that outputs to many payment addresses.\nA change output is automatically included to send extra output value back to the original account.\n\nArguments:\n1. fromaccount (string, required) DEPRECATED -- Account to pick unspent outputs from\n2. amounts     (object, required) Pairs of payment addresses and the output amount to pay each\n{\n \"Address to pay\": Amount to send to the payment address valued in decred, (object) JSON object using payment addresses as keys and output amounts valued in decred to send to each address\n ...\n}\n3. minconf (numeric, optional, default=1) Minimum number of block confirmations required before a transaction output is eligible to be spent\n4. comment (string, optional)             Unused\n\nResult:\n\"value\" (string) The transaction hash of the sent transaction\n",
+		"sendfrom":                "sendfrom \"fromaccount\" \"toaddress\" amount (minconf=1 \"comment\" \"commentto\")\n\nDEPRECATED -- Authors, signs, and sends a transaction that outputs some amount to a payment address.\nA change output is automatically included to send extra output value back to the original account.\n\nArguments:\n1. fromaccount (string, required)             Account to pick unspent outputs from\n2. toaddress   (string, required)             Address to pay\n3. amount      (numeric, required)            Amount to send to the payment address valued in decred\n4. minconf     (numeric, optional, default=1) Minimum number of block confirmations required before a transaction output is eligible to be spent, or the wallet's minconf setting when omitted\n5. comment     (string, optional)             Unused\n6. commentto   (string, optional)             Unused\n\nResult:\n\"value\" (string) The transaction hash of the sent transaction\n",
+		"sendmany":                "sendmany \"fromaccount\" {\"address\":amount,...} (minconf=1 \"comment\")\n\nAuthors, signs, and sends a transaction that outputs to many payment addresses.\nA change output is automatically included to send extra output value back to the original account.\n\nArguments:\n1. fromaccount (string, required) DEPRECATED -- Account to pick unspent outputs from\n2. amounts     (object, required) Pairs of payment addresses and the output amount to pay each\n{\n \"Address to pay\": Amount to send to the payment address valued in decred, (object) JSON object using payment addresses as keys and output amounts valued in decred to send to each address\n ...\n}\n3. minconf (numeric, optional, default=1) Minimum number of block confirmations required before a transaction output is eligible to be spent, or the wallet's minconf setting when omitted\n4. comment (string, optional)             Unused\n\nResult:\n\"value\" (string) The transaction hash of the sent transaction\n",
 		"sendtoaddress":           "sendtoaddress \"address\" amount (\"comment\" \"commentto\")\n\nAuthors, signs, and sends a transaction that outputs some amount to a payment address.\nUnlike sendfrom, outputs are always chosen from the default account.\nA change output is automatically included to send extra output value back to the original account.\n\nArguments:\n1. address   (string, required)  Address to pay\n2. amount    (numeric, required) Amount to send to the payment address valued in decred\n3. comment   (string, optional)  Unused\n4. commentto (string, optional)  Unused\n\nResult:\n\"value\" (string) The transaction hash of the sent transaction\n",
-		"sendtomultisig":          "sendtomultisig \"fromaccount\" amount [\"pubkey\",...] (nrequired=1 minconf=1 \"comment\")\n\nAuthors, signs, and sends a transaction that outputs some amount to a multisig address.\nUnlike sendfrom, outputs are always chosen from the default account.\nA change output is automatically included to send extra output value back to the original account.\n\nArguments:\n1. fromaccount (string, required)             Unused\n2. amount      (numeric, required)            Amount to send to the payment address valued in decred\n3. pubkeys     (array of string, required)    Pubkey to send to.\n4. nrequired   (numeric, optional, default=1) The number of signatures required to redeem outputs paid to this address\n5. minconf     (numeric, optional, default=1) Minimum number of block confirmations required\n6. comment     (string, optional)             Unused\n\nResult:\n\"value\" (string) The transaction hash of the sent transaction\n",
+		"sendtomultisig":          "sendtomultisig \"fromaccount\" amount [\"pubkey\",...] (nrequired=1 minconf=1 \"comment\")\n\nAuthors, signs, and sends a transaction that outputs some amount to a multisig address.\nUnlike sendfrom, outputs are always chosen from the default account.\nA change output is automatically included to send extra output value back to the original account.\n\nArguments:\n1. fromaccount (string, required)             Unused\n2. amount      (numeric, required)            Amount to send to the payment address valued in decred\n3. pubkeys     (array of string, required)    Pubkey to send to.\n4. nrequired   (numeric, optional, default=1) The number of signatures required to redeem outputs paid to this address\n5. minconf     (numeric, optional, default=1) Minimum number of block confirmations required, or the wallet's minconf setting when omitted\n6. comment     (string, optional)             Unused\n\nResult:\n\"value\" (string) The transaction hash of the sent transaction\n",
 		"setticketmaxprice":       "setticketmaxprice max\n\nSet the max price user is willing to pay for a ticket.\n\nArguments:\n1. max (numeric, required) The max price (in dcr).\n\nResult:\nNothing\n",
 		"settxfee":                "settxfee amount\n\nModify the increment used each time more fee is required for an authored transaction.\n\nArguments:\n1. amount (numeric, required) The new fee increment valued in decred\n\nResult:\ntrue|false (boolean) The boolean 'true'\n",
 		"signmessage":             "signmessage \"address\" \"message\"\n\nSigns a message using the private key of a payment address.\n\nArguments:\n1. address (string, required) Payment address of private key used to sign the message with\n2. message (string, required) Message to sign\n\nResult:\n\"value\" (string) The signed message encoded as a base64 string\n",
 		"signrawtransaction":      "signrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"tree\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\n\nSigns transaction inputs using private keys from this wallet and request.\nThe valid flags options are ALL, NONE, SINGLE, ALL|ANYONECANPAY, NONE|ANYONECANPAY, and SINGLE|ANYONECANPAY.\n\nArguments:\n1. rawtx    (string, required)                Unsigned or partially unsigned transaction to sign encoded as a hexadecimal string\n2. inputs   (array of object, optional)       Additional data regarding inputs that this wallet may not be tracking\n3. privkeys (array of string, optional)       Additional WIF-encoded private keys to use when creating signatures\n4. flags    (string, optional, default=\"ALL\") Sighash flags\n\nResult:\n{\n \"hex\": \"value\",         (string)          The resulting transaction encoded as a hexadecimal string\n \"complete\": true|false, (boolean)         Whether all input signatures have been created\n \"errors\": [{            (array of object) Script verification errors (if exists)\n  \"txid\": \"value\",       (string)          The transaction hash of the referenced previous output\n  \"vout\": n,             (numeric)         The output index of the referenced previous output\n  \"scriptSig\": \"value\",  (string)          The hex-encoded signature script\n  \"sequence\": n,         (numeric)         Script sequence number\n  \"error\": \"value\",      (string)          Verification or signing error related to the input\n },...],                                   \n}                        \n",
 		"signrawtransactions":     "signrawtransactions [\"rawtx\",...] (send=true)\n\nSigns transaction inputs using private keys from this wallet and request for a list of transactions.\n\n\nArguments:\n1. rawtxs (array of string, required)       A list of transactions to sign (and optionally send).\n2. send   (boolean, optional, default=true) Set true to send the transactions after signing.\n\nResult:\n{\n \"results\": [{             (array of object) Returned values from the signrawtransactions command.\n  \"signingresult\": {       (object)          Success or failure of signing.\n   \"hex\": \"value\",         (string)          The resulting transaction encoded as a hexadecimal string\n   \"complete\": true|false, (boolean)         Whether all input signatures have been created\n   \"errors\": [{            (array of object) Script verification errors (if exists)\n    \"txid\": \"value\",       (string)          The transaction hash of the referenced previous output\n    \"vout\": n,             (numeric)         The output index of the referenced previous output\n    \"scriptSig\": \"value\",  (string)          The hex-encoded signature script\n    \"sequence\": n,         (numeric)         Script sequence number\n    \"error\": \"value\",      (string)          Verification or signing error related to the input\n   },...],                                   \n  },                                         \n  \"sent\": true|false,      (boolean)         Tells if the transaction was sent.\n  \"txhash\": \"value\",       (string)          The hash of the signed tx.\n },...],                                     \n}                          \n",
+		"ticketsforaddress":       "ticketsforaddress \"address\"\n\nReturns the hashes of the tickets whose voting address is the passed address.\n\nArguments:\n1. address (string, required) The voting address of the tickets\n\nResult:\n{\n \"tickets\": [\"value\",...], (array of string) The hashes of the tickets\n}                           \n",
 		"validateaddress":         "validateaddress \"address\"\n\nVerify that an address is valid.\nExtra details are returned if the address is controlled by this wallet.\nThe following fields are valid only when the address is controlled by this wallet (ismine=true): isscript, pubkey, iscompressed, account, addresses, hex, script, and sigsrequired.\nThe following fields are only valid when address has an associated public key: pubkey, iscompressed.\nThe following fields are only valid when address is a pay-to-script-hash address: addresses, hex, and script.\nIf the address is a multisig address controlled by this wallet, the multisig fields will be left unset if the wallet is locked since the redeem script cannot be decrypted.\n\nArguments:\n1. address (string, required) Address to validate\n\nResult:\n{\n \"isvalid\": true|false,      (boolean)         Whether or not the address is valid\n \"address\": \"value\",         (string)          The payment address (only when isvalid is true)\n \"ismine\": true|false,       (boolean)         Whether this address is controlled by the wallet (only when isvalid is true)\n \"iswatchonly\": true|false,  (boolean)         Unset\n \"isscript\": true|false,     (boolean)         Whether the payment address is a pay-to-script-hash address (only when isvalid is true)\n \"pubkeyaddr\": \"value\",      (string)          The pubkey for this payment address (only when isvalid is true)\n \"pubkey\": \"value\",          (string)          The associated public key of the payment address, if any (only when isvalid is true)\n \"iscompressed\": true|false, (boolean)         Whether the address was created by hashing a compressed public key, if any (only when isvalid is true)\n \"account\": \"value\",         (string)          The account this payment address belongs to (only when isvalid is true)\n \"addresses\": [\"value\",...], (array of string) All associated payment addresses of the script if address is a multisig address (only when isvalid is true)\n \"hex\": \"value\",             (string)          The redeem script \n \"script\": \"value\",          (string)          The class of redeem script for a multisig address\n \"sigsrequired\": n,          (numeric)         The number of required signatures to redeem outputs to the multisig address\n}                            \n",
 		"verifymessage":           "verifymessage \"address\" \"signature\" \"message\"\n\nVerify a message was signed with the associated private key of some address.\n\nArguments:\n1. address   (string, required) Address used to sign message\n2. signature (string, required) The signature to verify\n3. message   (string, required) The message to verify\n\nResult:\ntrue|false (boolean) Whether the message was signed with the private key of 'address'\n",
 		"walletlock":              "walletlock\n\nLock the wallet.\n\nArguments:\nNone\n\nResult:\nNothing\n",
 		"walletpassphrase":        "walletpassphrase \"passphrase\" timeout\n\nUnlock the wallet.\n\nArguments:\n1. passphrase (string, required)  The wallet passphrase\n2. timeout    (numeric, required) The number of seconds to wait before the wallet automatically locks\n\nResult:\nNothing\n",
-		"walletpassphrasechange":  "walletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\n\nChange the wallet passphrase.\n\nArguments:\n1. oldpassphrase (string, required) The old wallet passphrase\n2. newpassphrase (string, required) The new wallet passphrase\n\nResult:\nNothing\n",
+		"walletpassphrasechange":  "walletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\n\nChange the wallet passphrase and lock the wallet. The wallet must be unlocked again with the new passphrase.\n\nArguments:\n1. oldpassphrase (string, required) The old wallet passphrase\n2. newpassphrase (string, required) The new wallet passphrase\n\nResult:\nNothing\n",
 		"createnewaccount":        "createnewaccount \"account\"\n\nCreates a new account.\nThe wallet must be unlocked for this request to succeed.\n\nArguments:\n1. account (string, required) Name of the new account\n\nResult:\nNothing\n",
 		"exportwatchingwallet":    "exportwatchingwallet (\"account\" download=false)\n\nCreates and returns a duplicate of the wallet database without any private keys to be used as a watching-only wallet.\n\nArguments:\n1. account  (string, optional)                 Unused (must be unset or \"*\")\n2. download (boolean, optional, default=false) Unused\n\nResult:\n\"value\" (string) The watching-only database encoded as a base64 string\n",
 		"getbestblock":            "getbestblock\n\nReturns the hash and height of the newest block in the best chain that wallet has finished syncing with.\n\nArguments:\nNone\n\nResult:\n{\n \"hash\": \"value\", (string)  The hash of the block\n \"height\": n,     (numeric) The blockchain height of the block\n}                 \n",
@@ -60,10 +61,55 @@ func helpDescsEnUS() map[string]string {
 		"listalltransactions":     "listalltransactions (\"account\")\n\nReturns a JSON array of objects in the same format as 'listtransactions' without limiting the number of returned objects.\n\nArguments:\n1. account (string, optional) Unused (must be unset or \"*\")\n\nResult:\n[{\n \"account\": \"value\",               (string)          DEPRECATED -- Unset\n \"address\": \"value\",               (string)          Payment address for a transaction output\n \"amount\": n.nnn,                  (numeric)         The value of the transaction output valued in decred\n \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n \"blockindex\": n,                  (numeric)         Unset\n \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n \"category\": \"value\",              (string)          The kind of transaction: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs.  Note: A single output may be included multiple times under different categories\n \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value for sent transactions\n \"generated\": true|false,          (boolean)         Whether the transaction output is a coinbase output\n \"involveswatchonly\": true|false,  (boolean)         Unset\n \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n \"txid\": \"value\",                  (string)          The hash of the transaction\n \"vout\": n,                        (numeric)         The transaction output index\n \"walletconflicts\": [\"value\",...], (array of string) Unset\n \"comment\": \"value\",               (string)          Unset\n \"otheraccount\": \"value\",          (string)          Unset\n},...]\n",
 		"renameaccount":           "renameaccount \"oldaccount\" \"newaccount\"\n\nRenames an account.\n\nArguments:\n1. oldaccount (string, required) The old account name to rename\n2. newaccount (string, required) The new name for the account\n\nResult:\nNothing\n",
 		"walletislocked":          "walletislocked\n\nReturns whether or not the wallet is locked.\n\nArguments:\nNone\n\nResult:\ntrue|false (boolean) Whether the wallet is locked\n",
-		"purchaseticket":          "purchaseticket \"fromaccount\" spendlimit (minconf=1 \"ticketaddress\" \"comment\")\n\nPurchase ticket using available funds.\n\nArguments:\n1. fromaccount   (string, required)             The account to use for purchase (default=\"default\")\n2. spendlimit    (numeric, required)            Limit on the amount to spend on ticket\n3. minconf       (numeric, optional, default=1) Minimum number of block confirmations required\n4. ticketaddress (string, optional)             Override the ticket address to which voting rights are given\n5. comment       (string, optional)             Unused\n\nResult:\n\"value\" (string) Hash of the resulting ticket\n",
+		"purchaseticket":          "purchaseticket \"fromaccount\" spendlimit (minconf=1 \"ticketaddress\" \"comment\")\n\nPurchase ticket using available funds.\n\nArguments:\n1. fromaccount   (string, required)             The account to use for purchase (default=\"default\")\n2. spendlimit    (numeric, required)            Limit on the amount to spend on ticket\n3. minconf       (numeric, optional, default=1) Minimum number of block confirmations required, or the wallet's minconf setting when omitted\n4. ticketaddress (string, optional)             Override the ticket address to which voting rights are given\n5. comment       (string, optional)             Unused\n\nResult:\n\"value\" (string) Hash of the resulting ticket\n",
 		"sendtossrtx":             "sendtossrtx \"fromaccount\" \"tickethash\" (\"comment\")\n\nSend to SS Revocation transaction\n\nArguments:\n1. fromaccount (string, required) The account to spend a stake ticket from (default=\"default\")\n2. tickethash  (string, required) Hash of the ticket to be revoked\n3. comment     (string, optional) Unused\n\nResult:\n\"value\" (string) txid of the resulting transaction\n",
-		"sendtosstx":              "sendtosstx \"fromaccount\" amounts [{\"txid\":\"value\",\"vout\":n,\"tree\":n,\"amt\":n},...] [{\"addr\":\"value\",\"commitamt\":n,\"changeaddr\":\"value\",\"changeamt\":n},...] (minconf=1 \"comment\")\n\nSend to SStx\n\nArguments:\n1. fromaccount (string, required) The account sent from\n2. amounts     (object, required) Amounts to send\n{\n \"Key\": Value, (object) Unused\n ...\n}\n3. inputs (array of object, required) Inputs for the tx\n[{\n \"txid\": \"value\", (string)  Txid to use\n \"vout\": n,       (numeric) Vout for the input tx\n \"tree\": n,       (numeric) Input tree\n \"amt\": n,        (numeric) Amount\n},...]\n4. couts (array of object, required) Couts for the tx\n[{\n \"addr\": \"value\",       (string)  Address to use\n \"commitamt\": n,        (numeric) Amount to commit\n \"changeaddr\": \"value\", (string)  Change address to use\n \"changeamt\": n,        (numeric) Change amount\n},...]\n5. minconf (numeric, optional, default=1) Minimum number of block confirmations required\n6. comment (string, optional)             Unused\n\nResult:\n\"value\" (string) txid of the resulting transaction\n",
+		"sendtosstx":              "sendtosstx \"fromaccount\" amounts [{\"txid\":\"value\",\"vout\":n,\"tree\":n,\"amt\":n},...] [{\"addr\":\"value\",\"commitamt\":n,\"changeaddr\":\"value\",\"changeamt\":n},...] (minconf=1 \"comment\")\n\nSend to SStx\n\nArguments:\n1. fromaccount (string, required) The account sent from\n2. amounts     (object, required) Amounts to send\n{\n \"Key\": Value, (object) Unused\n ...\n}\n3. inputs (array of object, required) Inputs for the tx\n[{\n \"txid\": \"value\", (string)  Txid to use\n \"vout\": n,       (numeric) Vout for the input tx\n \"tree\": n,       (numeric) Input tree\n \"amt\": n,        (numeric) Amount\n},...]\n4. couts (array of object, required) Couts for the tx\n[{\n \"addr\": \"value\",       (string)  Address to use\n \"commitamt\": n,        (numeric) Amount to commit\n \"changeaddr\": \"value\", (string)  Change address to use\n \"changeamt\": n,        (numeric) Change amount\n},...]\n5. minconf (numeric, optional, default=1) Minimum number of block confirmations required, or the wallet's minconf setting when omitted\n6. comment (string, optional)             Unused\n\nResult:\n\"value\" (string) txid of the resulting transaction\n",
 		"sendtossgen":             "sendtossgen \"fromaccount\" \"tickethash\" \"blockhash\" height votebits (\"comment\")\n\nGenerate a vote tx\n\nArguments:\n1. fromaccount (string, required)  The account to use (default=\"default\")\n2. tickethash  (string, required)  Hash of the ticket used for vote\n3. blockhash   (string, required)  Hash for the block being voted on\n4. height      (numeric, required) Blockheight for vote\n5. votebits    (numeric, required) Votebits to set\n6. comment     (string, optional)  Unused\n\nResult:\n\"value\" (string) txid of the resulting transaction\n",
+		"cancelrescan":            "cancelrescan\n\nStops a rescan started by rescanwallet after the blocks currently being rescanned.\n\nArguments:\nNone\n\nResult:\nNothing\n",
+		"rescanwallet":            "rescanwallet (beginheight=0 begintime)\n\nRescans the main chain from a height or time for transactions involving the wallet's addresses and unspent outputs.  The chain is rescanned in chunks, and the progress after each chunk is sent to websocket clients subscribed with notifyrescanprogress as rescanwalletprogress notifications.  The rescan may be stopped by cancelrescan, and the reply summarizes the rescan once it completes or is cancelled.\n\nArguments:\n1. beginheight (numeric, optional, default=0) The height of the first block to rescan\n2. begintime   (numeric, optional)            If set, the rescan begins at the first block with a timestamp at or after this Unix time instead of beginheight\n\nResult:\n{\n \"startheight\": n,        (numeric) The height of the first rescanned block\n \"height\": n,             (numeric) The height of the last rescanned block\n \"hash\": \"value\",         (string)  The hash of the last rescanned block\n \"transactions\": n,       (numeric) The number of wallet transactions in the rescanned blocks\n \"cancelled\": true|false, (boolean) Whether the rescan was cancelled before reaching the best block\n}                         \n",
+		"getlockinfo":             "getlockinfo\n\nReturns whether the wallet is locked and, for a wallet unlocked with a timeout, when it will be locked again.\n\nArguments:\nNone\n\nResult:\n{\n \"locked\": true|false, (boolean) Whether the wallet is locked\n \"locktime\": n,        (numeric) The Unix time the wallet will be locked again, or 0 if the wallet is locked or unlocked without a timeout\n \"remaining\": n,       (numeric) The number of seconds until the wallet is locked again, or 0 if the wallet is locked or unlocked without a timeout\n}                       \n",
+		"setunlocktimeout":        "setunlocktimeout timeout\n\nReplaces the timeout of an unlocked wallet, extending or shortening the time until it is locked again.\n\nArguments:\n1. timeout (numeric, required) The number of seconds from now until the wallet is locked, or 0 to keep the wallet unlocked until it is explicitly locked\n\nResult:\nNothing\n",
+		"getapiinfo":              "getapiinfo (\"apiversion\")\n\nReturns the version of the wallet JSON-RPC API, which follows the semantic versioning spec, and the optional features provided by the server.  Clients may pass the API version they were written against to check that it is supported.\n\nArguments:\n1. apiversion (string, optional) The major.minor or major.minor.patch API version required by the client.  An error is returned unless the major versions match and the server's minor version is at least as new\n\nResult:\n{\n \"version\": \"value\",            (string)          The API version string\n \"major\": n,                    (numeric)         The major API version\n \"minor\": n,                    (numeric)         The minor API version\n \"patch\": n,                    (numeric)         The patch API version\n \"capabilities\": [\"value\",...], (array of string) The optional features provided by the server, such as \"accounthistory\", \"addresspaths\", \"addressusage\", \"balancehistory\", \"batch\", \"birthday\", \"capitalgains\", \"creditorigins\", \"decoderawtransaction\", \"describescript\", \"fiatrates\", \"gaplimit\", \"grpc\", \"importedbalance\", \"importpubkey\", \"jobs\", \"keyrotation\", \"multisigwallet\", \"multiwallet\", \"notifyconfirmations\", \"paymenturi\", \"permissions\", \"poolshare\", \"rescanwallet\", \"seedbackup\", \"sendapproval\", \"signinglog\", \"stakediffestimate\", \"stakepool\", \"ticketbuyer\", \"ticketbuyerlog\", \"votebits\", \"votingonly\", \"vspclient\", and \"watchonly\"\n}                                \n",
+		"getbackendstate":         "getbackendstate\n\nReturns the state of the connection to the chain server, and how far the wallet is behind the chain server in processing blocks, notifications, and rescans.  Times are Unix times, and are 0 when the event has not occurred.\n\nArguments:\nNone\n\nResult:\n{\n \"connected\": true|false,   (boolean) Whether the wallet is connected to the chain server\n \"endpoint\": \"value\",       (string)  The address of the chain server, omitted when the wallet is offline\n \"connectedtime\": n,        (numeric) The Unix time the connection to the chain server was established\n \"bestblockhash\": \"value\",  (string)  The hash of the last block the chain server notified as connected to the main chain\n \"bestblockheight\": n,      (numeric) The height of the last block the chain server notified as connected to the main chain\n \"bestblocktime\": n,        (numeric) The Unix time the last block notification was received from the chain server\n \"syncedheight\": n,         (numeric) The height of the block the wallet is synced to\n \"blocksbehind\": n,         (numeric) The number of blocks the wallet is behind the best block notified by the chain server\n \"chainsynced\": true|false, (boolean) Whether the wallet is in sync with the chain server\n \"queuednotifications\": n,  (numeric) The number of chain server notifications waiting to be processed by the wallet\n \"lasthealthcheck\": n,      (numeric) The Unix time the chain server last passed a health check\n \"rescanqueuedepth\": n,     (numeric) The number of rescans running or waiting to run\n}                            \n",
+		"listaddresstickets":      "listaddresstickets \"address\"\n\nReturns every ticket tracked by the wallet whose voting address or any commitment address is the passed address, so users of a stake pool may find the tickets they delegated to it.\n\nArguments:\n1. address (string, required) The voting or commitment address of the tickets\n\nResult:\n[{\n \"ticket\": \"value\",         (string)  The hash of the ticket\n \"voting\": true|false,      (boolean) Whether the address is the ticket's voting address\n \"commitment\": true|false,  (boolean) Whether the address is one of the ticket's commitment addresses\n \"commitmentamount\": n.nnn, (numeric) The total amount the ticket commits to the address\n \"status\": \"value\",         (string)  The status of the ticket: \"unmined\", \"immature\", \"live\", \"expired\", \"missed\", \"voted\", \"revoked\", or \"unknown\"\n},...]\n",
+		"getfeesreport":           "getfeesreport (starttime endtime)\n\nReturns the fees paid by the wallet's transactions within a time range, totalled separately for regular transactions, tickets, votes, and revocations.  Only transactions spending wallet funds are included, and the block time of mined transactions, or the time unmined transactions were received, is compared against the range.\n\nArguments:\n1. starttime (numeric, optional) If set, only transactions at or after this Unix time are included\n2. endtime   (numeric, optional) If set, only transactions before this Unix time are included\n\nResult:\n{\n \"regularcount\": n,       (numeric) The number of regular transactions\n \"regularfees\": n.nnn,    (numeric) The fees paid by regular transactions\n \"ticketcount\": n,        (numeric) The number of tickets\n \"ticketfees\": n.nnn,     (numeric) The fees paid by tickets\n \"votecount\": n,          (numeric) The number of votes\n \"votefees\": n.nnn,       (numeric) The fees implied by votes: the value of the ticket and vote subsidy which is not paid to the vote's outputs\n \"revocationcount\": n,    (numeric) The number of revocations\n \"revocationfees\": n.nnn, (numeric) The fees paid by revocations\n \"unknowncount\": n,       (numeric) The number of transactions whose fee is unknown because some inputs do not spend wallet outputs\n \"totalfees\": n.nnn,      (numeric) The total fees paid by all included transactions\n}                          \n",
+		"startjob":                "startjob \"method\" ([param,...])\n\nStarts handling a request in the background and returns the ID of the new job.  Only the importprivkey, importscript, rescanwallet, and setbirthday methods, which may take minutes to complete, may be run as jobs, and the caller must have permission to call the method.  The status of the job is returned by getjobstatus, and websocket clients subscribed with notifyjobs receive a jobstatus notification when the job finishes.\n\nArguments:\n1. method (string, required)         The method of the request to run as a job\n2. params (array of value, optional) The parameters of the request\n\nResult:\nn (numeric) The ID of the job\n",
+		"getjobstatus":            "getjobstatus jobid\n\nReturns the status of a job started by startjob, and the result or error of the job's request once it has finished.\n\nArguments:\n1. jobid (numeric, required) The ID of the job returned by startjob\n\nResult:\n{\n \"jobid\": n,        (numeric) The ID of the job\n \"method\": \"value\", (string)  The method of the job's request\n \"status\": \"value\", (string)  The status of the job: \"running\", \"done\", or \"failed\"\n \"started\": n,      (numeric) The Unix time the job was started\n \"finished\": n,     (numeric) The Unix time the job finished, if it has finished\n \"result\": value,   (value)   The result of the job's request, if the job is done\n \"error\": \"value\",  (string)  The error of the job's request, if the job failed\n}                    \n",
+		"listjobs":                "listjobs\n\nReturns the status of every running job and of the most recently finished jobs, without their results.\n\nArguments:\nNone\n\nResult:\n[{\n \"jobid\": n,        (numeric) The ID of the job\n \"method\": \"value\", (string)  The method of the job's request\n \"status\": \"value\", (string)  The status of the job: \"running\", \"done\", or \"failed\"\n \"started\": n,      (numeric) The Unix time the job was started\n \"finished\": n,     (numeric) The Unix time the job finished, if it has finished\n \"result\": value,   (value)   The result of the job's request, if the job is done\n \"error\": \"value\",  (string)  The error of the job's request, if the job failed\n},...]\n",
+		"debuglevel":              "debuglevel \"levelspec\"\n\nDynamically changes the debug logging level.\nThe levelspec can either be a debug level or of the form:\n<subsystem>=<level>,<subsystem2>=<level2>,...\nThe valid debug levels are trace, debug, info, warn, error, and critical.\nThe valid subsystems are ADDR, CHNS, DCRW, GRPC, RPCS, STKM, TKBY, WLLT, and WTXM.\nFinally the keyword 'show' will return a list of the available subsystems.\n\nArguments:\n1. levelspec (string, required) The debug level(s) to use or the keyword 'show'\n\nResult (levelspec!=show):\n\"value\" (string) The string 'Done.'\n\nResult (levelspec=show):\n\"value\" (string) The list of subsystems\n",
+		"loadwallet":              "loadwallet \"name\"\n\nLoads a wallet in addition to the default wallet.  The wallet is opened from the wallets/<name> subdirectory of the data directory, where it must have been created with --create.  Requests for the wallet are sent by HTTP POST to /wallet/<name>.\n\nArguments:\n1. name (string, required) The name of the wallet\n\nResult:\nNothing\n",
+		"unloadwallet":            "unloadwallet \"name\"\n\nStops and closes a wallet loaded by loadwallet.\n\nArguments:\n1. name (string, required) The name of the wallet\n\nResult:\nNothing\n",
+		"exportsigninglog":        "exportsigninglog (start=0 count=1000)\n\nReturns records of the signing log, an append-only log of every transaction signed by the wallet, in the order the transactions were signed.  Each record describes who requested the signature: \"rpc:<user>\" for RPC users, \"grpc\" for gRPC clients, \"ticketbuyer\" for the automatic ticket buyer, and \"wallet\" for votes, revocations, and other transactions signed by the wallet on its own.\n\nArguments:\n1. start (numeric, optional, default=0)    The sequence number of the first record to return\n2. count (numeric, optional, default=1000) The maximum number of records to return\n\nResult:\n[{\n \"sequence\": n,               (numeric)         The sequence number of the record\n \"time\": n,                   (numeric)         The Unix time the transaction was signed\n \"origin\": \"value\",           (string)          Who requested the signature\n \"txhash\": \"value\",           (string)          The hash of the signed transaction\n \"inputs\": [{                 (array of object) The inputs of the signed transaction\n  \"txid\": \"value\",            (string)          The hash of the transaction of the spent output\n  \"vout\": n,                  (numeric)         The output index of the spent output\n  \"tree\": n,                  (numeric)         The tree of the transaction of the spent output\n  \"amount\": n.nnn,            (numeric)         The value of the spent output committed to by the input\n },...],                                        \n \"outputs\": [{                (array of object) The outputs of the signed transaction\n  \"amount\": n.nnn,            (numeric)         The value of the output\n  \"scripttype\": \"value\",      (string)          The type of the output script\n  \"addresses\": [\"value\",...], (array of string) The addresses paid by the output script\n },...],                                        \n \"hex\": \"value\",              (string)          The serialized signed transaction\n},...]\n",
+		"getblockvotebits":        "getblockvotebits startheight (endheight)\n\nReturns the vote bits of each block recorded by the wallet in a range of heights, and whether they approve the regular transaction tree of the parent block.  Regular transactions of a disapproved block must be mined again.\n\nArguments:\n1. startheight (numeric, required) The height of the first block to return\n2. endheight   (numeric, optional) The height of the last block to return (default: the height the wallet is synced to)\n\nResult:\n[{\n \"height\": n,                  (numeric) The height of the block\n \"hash\": \"value\",              (string)  The hash of the block\n \"time\": n,                    (numeric) The Unix time of the block\n \"votebits\": n,                (numeric) The vote bits of the block\n \"parentapproved\": true|false, (boolean) Whether the vote bits approve the regular transaction tree of the parent block\n},...]\n",
+		"getbalancehistory":       "getbalancehistory (startheight=0 endheight interval=\"block\")\n\nReturns the changes of the wallet balance caused by each block, or by each day, in a range of heights, along with the resulting balance, for charting the balance over time.  Only blocks and days which changed the balance are returned.  The balance includes immature coinbase and stake outputs but excludes unmined transactions and outputs locked in tickets.\n\nArguments:\n1. startheight (numeric, optional, default=0)      The height of the first block to include\n2. endheight   (numeric, optional)                 The height of the last block to include (default: the height the wallet is synced to)\n3. interval    (string, optional, default=\"block\") The interval to group balance changes by, either \"block\" or \"day\" (UTC)\n\nResult:\n[{\n \"height\": n,      (numeric) The height of the last block of the interval\n \"time\": n,        (numeric) The Unix time of the last block of the interval\n \"delta\": n.nnn,   (numeric) The change of the balance during the interval\n \"balance\": n.nnn, (numeric) The balance after the interval\n},...]\n",
+		"getimportedbalance":      "getimportedbalance (minconf=1 balancetype=\"spendable\")\n\nReturns the balance of the wallet split by whether it can be recovered by restoring the wallet from its seed.  Outputs paying imported keys and scripts, or scripts whose addresses are not managed by the wallet, can only be recovered from a backup of the wallet or of the imported keys and scripts.\n\nArguments:\n1. minconf     (numeric, optional, default=1)          Minimum number of block confirmations required before an unspent output's value is included in the balance\n2. balancetype (string, optional, default=\"spendable\") The type of balance to return, 'spendable', 'locked' (value locked in tickets), 'all' (all unspent outputs), or 'fullscan' (spendable balance verified by a scan of all unspent outputs)\n\nResult:\n{\n \"total\": n.nnn,               (numeric) The balance of the wallet\n \"seed\": n.nnn,                (numeric) The balance recoverable by restoring the wallet from its seed\n \"imported\": n.nnn,            (numeric) The balance of imported keys and scripts, which can not be recovered from the seed\n \"backuprequired\": true|false, (boolean) Whether any of the balance can not be recovered from the seed, so the imported keys and scripts must be backed up\n}                              \n",
+		"getcreditorigin":         "getcreditorigin \"txid\" vout\n\nReturns the origin tag of a transaction output, one of \"normal\", \"mixed\", \"swap\", or \"poolreward\".  Outputs which were never tagged are of the normal origin.\n\nArguments:\n1. txid (string, required)  The hash of the transaction\n2. vout (numeric, required) The index of the output\n\nResult:\n\"value\" (string) The origin of the output\n",
+		"setcreditorigin":         "setcreditorigin \"txid\" vout \"origin\"\n\nTags a transaction output with the origin of its funds.  The outputs of transactions spending only credits of a single origin inherit its tag, and with the separateorigins option credits of different origins are never spent in the same transaction, so mixed coins are not merged with unmixed coins.\n\nArguments:\n1. txid   (string, required)  The hash of the transaction\n2. vout   (numeric, required) The index of the output\n3. origin (string, required)  The origin of the output, one of \"normal\", \"mixed\", \"swap\", or \"poolreward\"\n\nResult:\nNothing\n",
+		"setbirthday":             "setbirthday birthday\n\nMoves the wallet birthday earlier and rescans the blocks which were skipped because of the previous birthday for transactions involving the wallet's addresses.  Outputs found by the rescan are then watched through the blocks the wallet is already synced through so their spends are recorded, but the rest of the wallet's history is unchanged.  The progress is sent to websocket clients subscribed with notifyrescanprogress as rescanwalletprogress notifications, the rescan may be stopped by cancelrescan, and the birthday is only changed once the rescan completes.\n\nArguments:\n1. birthday (numeric, required) The new birthday as a Unix time, which must be before the current birthday\n\nResult:\n{\n \"startheight\": n,        (numeric) The height of the first rescanned block\n \"height\": n,             (numeric) The height of the last rescanned block\n \"hash\": \"value\",         (string)  The hash of the last rescanned block\n \"transactions\": n,       (numeric) The number of wallet transactions in the rescanned blocks\n \"cancelled\": true|false, (boolean) Whether the rescan was cancelled before reaching the best block\n}                         \n",
+		"decodeaddress":           "decodeaddress \"address\"\n\nDescribes the output script paying to an address, including its class, the keys and hashes involved, and whether the wallet controls it.\n\nArguments:\n1. address (string, required) The address or hex-encoded public key to describe\n\nResult:\n{\n \"script\": \"value\",          (string)          The hex-encoded output script\n \"class\": \"value\",           (string)          The class of the script\n \"stakesubclass\": \"value\",   (string)          The class of the script tagged by the stake opcode, omitted when the script is not a stake output\n \"reqsigs\": n,               (numeric)         The number of signatures required to spend outputs paying to the script\n \"addresses\": [\"value\",...], (array of string) The addresses of the keys and script hashes involved in the script\n \"hashes\": [\"value\",...],    (array of string) The hex-encoded public key or hash of each address\n \"walletkeys\": n,            (numeric)         The number of involved addresses managed by the wallet, counting the addresses of the redeem script for pay-to-script-hash scripts whose redeem script is known\n \"controlled\": true|false,   (boolean)         Whether the wallet holds enough private keys to spend outputs paying to the script\n \"redeemscript\": \"value\",    (string)          The hex-encoded redeem script of a pay-to-script-hash script, omitted when it is not known to the wallet\n}                            \n",
+		"describescript":          "describescript \"script\" (version=0)\n\nDecodes an output script and describes its class, stake subclass, required signatures, the keys and hashes involved, and whether the wallet controls it.\n\nArguments:\n1. script  (string, required)             The hex-encoded output script\n2. version (numeric, optional, default=0) The script version\n\nResult:\n{\n \"script\": \"value\",          (string)          The hex-encoded output script\n \"class\": \"value\",           (string)          The class of the script\n \"stakesubclass\": \"value\",   (string)          The class of the script tagged by the stake opcode, omitted when the script is not a stake output\n \"reqsigs\": n,               (numeric)         The number of signatures required to spend outputs paying to the script\n \"addresses\": [\"value\",...], (array of string) The addresses of the keys and script hashes involved in the script\n \"hashes\": [\"value\",...],    (array of string) The hex-encoded public key or hash of each address\n \"walletkeys\": n,            (numeric)         The number of involved addresses managed by the wallet, counting the addresses of the redeem script for pay-to-script-hash scripts whose redeem script is known\n \"controlled\": true|false,   (boolean)         Whether the wallet holds enough private keys to spend outputs paying to the script\n \"redeemscript\": \"value\",    (string)          The hex-encoded redeem script of a pay-to-script-hash script, omitted when it is not known to the wallet\n}                            \n",
+		"decoderawtransaction":    "decoderawtransaction \"hextx\"\n\nDecodes a serialized transaction, identifying the structures of tickets, votes, and revocations, and annotates which inputs and outputs belong to the wallet.\n\nArguments:\n1. hextx (string, required) The serialized transaction hex-encoded\n\nResult:\n{\n \"txid\": \"value\",               (string)          The hash of the transaction\n \"type\": \"value\",               (string)          The stake type of the transaction, one of \"regular\", \"ticket\", \"vote\", or \"revocation\"\n \"version\": n,                  (numeric)         The transaction version\n \"locktime\": n,                 (numeric)         The transaction lock time\n \"expiry\": n,                   (numeric)         The height after which the transaction may not be mined, or zero when it does not expire\n \"vin\": [{                      (array of object) The inputs of the transaction\n  \"txid\": \"value\",              (string)          The hash of the transaction of the spent output\n  \"vout\": n,                    (numeric)         The output index of the spent output\n  \"tree\": n,                    (numeric)         The tree of the transaction of the spent output\n  \"sequence\": n,                (numeric)         The input sequence number\n  \"amountin\": n.nnn,            (numeric)         The value of the spent output committed to by the input\n  \"stakebase\": true|false,      (boolean)         Whether the input is the stakebase of a vote, which does not spend an output\n  \"mine\": true|false,           (boolean)         Whether the input spends an output of the wallet\n },...],                                          \n \"vout\": [{                     (array of object) The outputs of the transaction\n  \"n\": n,                       (numeric)         The index of the output\n  \"value\": n.nnn,               (numeric)         The value of the output\n  \"version\": n,                 (numeric)         The script version of the output\n  \"scriptpubkey\": {             (object)          The output script and whether the wallet controls it, as described by describescript\n   \"script\": \"value\",           (string)          The hex-encoded output script\n   \"class\": \"value\",            (string)          The class of the script\n   \"stakesubclass\": \"value\",    (string)          The class of the script tagged by the stake opcode, omitted when the script is not a stake output\n   \"reqsigs\": n,                (numeric)         The number of signatures required to spend outputs paying to the script\n   \"addresses\": [\"value\",...],  (array of string) The addresses of the keys and script hashes involved in the script\n   \"hashes\": [\"value\",...],     (array of string) The hex-encoded public key or hash of each address\n   \"walletkeys\": n,             (numeric)         The number of involved addresses managed by the wallet, counting the addresses of the redeem script for pay-to-script-hash scripts whose redeem script is known\n   \"controlled\": true|false,    (boolean)         Whether the wallet holds enough private keys to spend outputs paying to the script\n   \"redeemscript\": \"value\",     (string)          The hex-encoded redeem script of a pay-to-script-hash script, omitted when it is not known to the wallet\n  },                                              \n },...],                                          \n \"ticket\": {                    (object)          The price and commitments of a ticket, omitted for other transactions\n  \"price\": n.nnn,               (numeric)         The price of the ticket\n  \"commitments\": [{             (array of object) The commitment outputs of the ticket\n   \"address\": \"value\",          (string)          The address the commitment is returned to\n   \"amount\": n.nnn,             (numeric)         The amount committed\n   \"share\": n.nnn,              (numeric)         The percentage of all committed amounts\n   \"owned\": true|false,         (boolean)         Whether the commitment address belongs to the wallet\n   \"votefeelimit\": n.nnn,       (numeric)         The maximum fee a vote may deduct from the commitment, omitted when the fee is not allowed\n   \"revocationfeelimit\": n.nnn, (numeric)         The maximum fee a revocation may deduct from the commitment, omitted when the fee is not allowed\n   \"changeaddress\": \"value\",    (string)          The address of the change output paired with the commitment\n   \"changeamount\": n.nnn,       (numeric)         The amount of the change output paired with the commitment\n  },...],                                         \n },                                               \n \"vote\": {                      (object)          The ticket spent by a vote and the block and vote bits it votes with, omitted for other transactions\n  \"ticket\": \"value\",            (string)          The hash of the ticket spent by the vote\n  \"blockhash\": \"value\",         (string)          The hash of the block voted on\n  \"blockheight\": n,             (numeric)         The height of the block voted on\n  \"votebits\": n,                (numeric)         The vote bits of the vote\n },                                               \n \"revocation\": {                (object)          The ticket spent by a revocation and the amount refunded, omitted for other transactions\n  \"ticket\": \"value\",            (string)          The hash of the ticket spent by the revocation\n  \"refunded\": n.nnn,            (numeric)         The amount refunded to the commitment addresses of the ticket\n },                                               \n}                               \n",
+		"createmultisigwallet":    "createmultisigwallet nrequired [\"key\",...] (count=20)\n\nSets up a multisig wallet shared by cosigners.  The redeem scripts are created from the keys of the cosigners, sorted so every cosigner creates the same scripts, imported, and their addresses are watched.\nWhen any key is an account extended public key, such as returned by getmasterpubkey, a script is created for each child index of the external branch below count, and otherwise the single script of the public keys is created.\nThe result is a recovery bundle each cosigner should store.  Passing the same nrequired, keys, and count again recreates the scripts, and the wallet should then be rescanned from the bundle height.\n\nArguments:\n1. nrequired (numeric, required)             The number of signatures required to redeem outputs paid to the scripts\n2. keys      (array of string, required)     The hex-encoded public keys or account extended public keys of the cosigners\n3. count     (numeric, optional, default=20) The number of scripts to create when any key is an extended public key\n\nResult:\n{\n \"nrequired\": n,           (numeric)         The number of signatures required to redeem outputs paid to the scripts\n \"keys\": [\"value\",...],    (array of string) The keys of the cosigners\n \"count\": n,               (numeric)         The number of created scripts\n \"branch\": n,              (numeric)         The branch of the extended public keys the child keys are derived from\n \"network\": \"value\",       (string)          The network the scripts were created for\n \"height\": n,              (numeric)         The height of the best block when the scripts were imported, which a restored wallet should rescan from\n \"scripts\": [{             (array of object) The created scripts\n  \"index\": n,              (numeric)         The child index of the extended public keys used by the script\n  \"address\": \"value\",      (string)          The pay-to-script-hash address of the script\n  \"redeemscript\": \"value\", (string)          The hex-encoded redeem script\n },...],                                     \n}                          \n",
+		"listpendingsends":        "listpendingsends\n\nReturns the sends waiting for approval, in the order they were requested.  Sends paying more than the approval threshold are created unsigned and queued until they are approved with approvesend, rejected with rejectsend, or expire.\n\nArguments:\nNone\n\nResult:\n[{\n \"id\": \"value\",       (string)          The id of the pending send, which is the hash its transaction has once signed\n \"origin\": \"value\",   (string)          The signing origin which requested the send\n \"amount\": n.nnn,     (numeric)         The total amount paid by the send, excluding change\n \"fee\": n.nnn,        (numeric)         The estimated fee of the signed transaction\n \"inputs\": [{         (array of object) The outputs spent by the send, in the order of the transaction inputs\n  \"txid\": \"value\",    (string)          The hash of the transaction of the spent output\n  \"vout\": n,          (numeric)         The output index of the spent output\n  \"tree\": n,          (numeric)         The tree of the transaction of the spent output\n  \"amount\": n.nnn,    (numeric)         The amount of the spent output\n  \"address\": \"value\", (string)          The address paid by the spent output\n  \"account\": \"value\", (string)          The account of the address\n  \"path\": \"value\",    (string)          The derivation path of the key of the address, omitted for imported addresses and scripts\n },...],                                \n \"outputs\": [{        (array of object) The payments of the send, excluding change\n  \"address\": \"value\", (string)          The paid address\n  \"amount\": n.nnn,    (numeric)         The amount paid to the address\n },...],                                \n \"created\": n,        (numeric)         The Unix time the send was requested\n \"expires\": n,        (numeric)         The Unix time the send expires unless it is approved\n \"hex\": \"value\",      (string)          The hex-encoded unsigned transaction\n},...]\n",
+		"approvesend":             "approvesend \"id\" (\"signature\")\n\nApproves a send waiting for approval, signing and broadcasting its transaction.\nWithout a signature, the send must be approved by a different RPC user than the one which requested it.  With a signature, the send is approved by the holder of the key of the configured approval address, which signs the message \"approvesend <id>\" with signmessage.\nThe wallet must be unlocked.  When the transaction can not be signed, the send remains pending.\n\nArguments:\n1. id        (string, required) The id of the pending send\n2. signature (string, optional) The base64-encoded signmessage signature of the approval address approving the send\n\nResult:\n\"value\" (string) The transaction hash of the sent transaction\n",
+		"rejectsend":              "rejectsend \"id\"\n\nRemoves a send waiting for approval without signing it, releasing the outputs it would spend.\n\nArguments:\n1. id (string, required) The id of the pending send\n\nResult:\nNothing\n",
+		"registervsp":             "registervsp (rescanfrom)\n\nRegisters a public key address of the wallet with the voting service provider configured by --vspurl and --vspapitoken, and imports the multisig redeem script shared with the provider, which tickets delegated to it vote with.\nThe script is checked to include a key of the wallet and to hash to the provider's ticket address before it is imported.  When an address was already registered, such as before restoring the wallet from seed, only the script is imported.\n\nArguments:\n1. rescanfrom (numeric, optional) The height to rescan from for tickets already purchased with the script (default: the best block)\n\nResult:\n{\n \"registered\": true|false, (boolean) Whether a public key address was registered by this request\n \"ticketaddress\": \"value\", (string)  The P2SH address of the multisig script tickets vote with\n \"script\": \"value\",        (string)  The hex-encoded multisig redeem script\n \"pooladdress\": \"value\",   (string)  The address tickets must commit the pool fee to\n \"poolfees\": n.nnn,        (numeric) The percentage of each ticket's price and fee which must be committed to the pool address\n}                          \n",
+		"purchasevsptickets":      "purchasevsptickets count (minbalance=0 minconf)\n\nPurchases tickets voted by the configured voting service provider at the current ticket price.  Each ticket votes with the provider's ticket address and commits the pool fee to its pool address, along with the wallet's share of the ticket.  A split transaction first creates the exact outputs spent by each ticket.\nThe provider's script must have been imported with registervsp, and the wallet must be unlocked.\n\nArguments:\n1. count      (numeric, required)            The number of tickets to purchase\n2. minbalance (numeric, optional, default=0) The minimum balance in coins to leave in the wallet\n3. minconf    (numeric, optional)            The minimum number of confirmations of spent outputs (default: --minconf)\n\nResult:\n[\"value\",...] (array of string) The hashes of the purchased tickets\n",
+		"listvsptickets":          "listvsptickets\n\nReturns the wallet's tickets voted by the configured voting service provider, sorted by hash, with their status and whether each commits the pool fee the provider requires to vote it.\n\nArguments:\nNone\n\nResult:\n[{\n \"ticket\": \"value\",     (string)  The hash of the ticket\n \"status\": \"value\",     (string)  The lifecycle status of the ticket: unmined, immature, live, voted, missed, expired, or revoked\n \"price\": n.nnn,        (numeric) The price of the ticket\n \"poolfee\": n.nnn,      (numeric) The amount the ticket commits to the pool address\n \"feepaid\": true|false, (boolean) Whether the committed pool fee satisfies the provider's fee percentage\n},...]\n",
+		"estimatestakediff":       "estimatestakediff\n\nForecasts the ticket price of the next stake difficulty window from the live ticket pool size and the tickets purchased in the recent windows, projecting the tickets purchased in the remainder of the current window.\nThe automatic ticket buyer waits for the next window late in a window when the expected price is lower than the current price.\n\nArguments:\nNone\n\nResult:\n{\n \"height\": n,       (numeric) The height of the block the forecast is made at\n \"remaining\": n,    (numeric) The number of blocks remaining in the current window\n \"current\": n.nnn,  (numeric) The ticket price of the current window\n \"min\": n.nnn,      (numeric) The next ticket price if no more tickets are purchased in the current window\n \"expected\": n.nnn, (numeric) The next ticket price if tickets continue to be purchased at the rate of the current window\n \"max\": n.nnn,      (numeric) The next ticket price if every remaining block of the current window purchases the maximum number of tickets\n}                   \n",
+		"getticketpoolshare":      "getticketpoolshare (days=30)\n\nReturns the wallet's share of the live ticket pool and the statistical expectation of its live tickets being called to vote, assuming the pool size and the wallet's live tickets do not change.\n\nArguments:\n1. days (numeric, optional, default=30) The number of days for which the probability of a vote is reported\n\nResult:\n{\n \"height\": n,                (numeric) The height of the best block\n \"livetickets\": n,           (numeric) The number of live tickets of the wallet\n \"poolsize\": n,              (numeric) The number of live tickets of the network\n \"share\": n.nnn,             (numeric) The fraction of the live ticket pool owned by the wallet\n \"voteprobability\": n.nnn,   (numeric) The probability that at least one ticket of the wallet votes in each block\n \"expectedblocks\": n.nnn,    (numeric) The expected number of blocks until the next vote, or zero without live tickets\n \"expectedtime\": n,          (numeric) The expected number of seconds until the next vote, or zero without live tickets\n \"days\": n,                  (numeric) The number of days the vote probability is reported for\n \"withinprobability\": n.nnn, (numeric) The probability that at least one ticket of the wallet votes within the days\n}                            \n",
+		"getstakeinfo":            "getstakeinfo\n\nReturns the number of the wallet's tickets in each stage of their lifecycle and the subsidy earned by their votes.\n\nArguments:\nNone\n\nResult:\n{\n \"blockheight\": n,      (numeric) The height of the block the wallet is synced to\n \"difficulty\": n.nnn,   (numeric) The current ticket price\n \"ownmempooltix\": n,    (numeric) The number of the wallet's tickets which have not been mined\n \"immature\": n,         (numeric) The number of the wallet's tickets which have not reached ticket maturity\n \"live\": n,             (numeric) The number of the wallet's live tickets\n \"voted\": n,            (numeric) The number of the wallet's tickets which voted\n \"missed\": n,           (numeric) The number of the wallet's tickets which missed their vote\n \"expired\": n,          (numeric) The number of the wallet's tickets which expired without being called to vote\n \"revoked\": n,          (numeric) The number of the wallet's tickets which were revoked\n \"totalsubsidy\": n.nnn, (numeric) The total subsidy earned by the wallet's votes\n}                       \n",
+		"getticketbuyerlog":       "getticketbuyerlog (count=100)\n\nReturns the most recent decisions of the automatic ticket buyer, oldest first.  A decision is recorded in the wallet database for every block the ticket buyer runs at, describing its inputs and why tickets were or were not purchased.\n\nArguments:\n1. count (numeric, optional, default=100) The number of most recent decisions to return\n\nResult:\n[{\n \"id\": n,              (numeric) The sequence number of the decision\n \"height\": n,          (numeric) The height of the block the ticket buyer ran at\n \"time\": n,            (numeric) The Unix time of the decision\n \"ticketprice\": n.nnn, (numeric) The ticket price\n \"spendable\": n.nnn,   (numeric) The spendable balance observed\n \"mempoolfee\": n.nnn,  (numeric) The median fee per kB of the tickets in the mempool\n \"feerate\": n.nnn,     (numeric) The fee per kB paid by the purchased tickets, or zero when none were purchased\n \"attempted\": n,       (numeric) The number of attempted ticket purchases\n \"purchased\": n,       (numeric) The number of purchased tickets\n \"batch\": n,           (numeric) The ticket batch of the purchased tickets, omitted when none were purchased\n \"reason\": \"value\",    (string)  Why the ticket buyer purchased or skipped tickets\n},...]\n",
+		"listaddressusage":        "listaddressusage (\"account\")\n\nReturns the usage of every address of the wallet, built from an index of the recorded transactions, so reused addresses may be identified and retired.  Addresses are ordered by account, external addresses before internal ones, and then by first use, with unused addresses last.\n\nArguments:\n1. account (string, optional) The account of the addresses, or \"*\" for all accounts\n\nResult:\n[{\n \"address\": \"value\",     (string)  The address\n \"account\": \"value\",     (string)  The account of the address\n \"branch\": \"value\",      (string)  The branch of the address: \"external\", \"internal\", or \"imported\"\n \"firstused\": n,         (numeric) The Unix time of the first transaction paying to the address, or zero when it is unused\n \"totalreceived\": n.nnn, (numeric) The total amount received by the address\n \"balance\": n.nnn,       (numeric) The amount of the unspent outputs paying to the address, regardless of their confirmations\n \"txcount\": n,           (numeric) The number of transactions paying to or spending from the address\n \"reused\": true|false,   (boolean) Whether the address was paid by more than one transaction\n},...]\n",
+		"createpaymenturi":        "createpaymenturi (account=\"default\" amount \"label\" \"message\" expiry=0)\n\nDerives a new address of an account and returns a decred: payment URI requesting payment to it, which may be shared as an invoice.\n\nArguments:\n1. account (string, optional, default=\"default\") The account of the new address\n2. amount  (numeric, optional)                   The requested amount, or none for any amount\n3. label   (string, optional)                    A label for the recipient\n4. message (string, optional)                    A message describing the payment\n5. expiry  (numeric, optional, default=0)        The number of seconds the payment request is valid for, or 0 if it never expires\n\nResult:\n{\n \"uri\": \"value\",     (string)  The payment URI\n \"address\": \"value\", (string)  The new address the URI requests payment to\n \"expires\": n,       (numeric) The Unix time the payment request expires at, or 0 if it never expires\n}                    \n",
+		"decodepaymenturi":        "decodepaymenturi \"uri\"\n\nValidates and decodes a decred: payment URI, so a payment request may be checked before it is paid.\n\nArguments:\n1. uri (string, required) The payment URI\n\nResult:\n{\n \"isvalid\": true|false, (boolean) Whether the URI is a valid payment request for the wallet's network\n \"error\": \"value\",      (string)  Why the URI is not valid\n \"address\": \"value\",    (string)  The address the URI requests payment to\n \"amount\": n.nnn,       (numeric) The requested amount, omitted when any amount may be paid\n \"label\": \"value\",      (string)  The label of the recipient\n \"message\": \"value\",    (string)  The message describing the payment\n \"expires\": n,          (numeric) The Unix time the payment request expires at, omitted when it never expires\n \"expired\": true|false, (boolean) Whether the payment request has expired\n \"ismine\": true|false,  (boolean) Whether the address belongs to the wallet\n}                       \n",
+		"exportcapitalgains":      "exportcapitalgains year (method=\"fifo\")\n\nReturns the gains realized by the wallet during a calendar year in UTC as CSV, for tax reporting.  The wallet's mined transactions are replayed: transactions increasing the wallet's coins acquire a lot, and transactions decreasing them, including by the fees they pay, dispose of coins which are matched with lots by the lot method.  Coins are valued with the fiat rates recorded when the transactions were first seen (see the fiatratesource option), and the CSV columns are \"disposed\", \"disposaltxid\", \"acquired\", \"acquisitiontxid\", \"amount\", \"proceeds\", \"costbasis\", \"gain\" and \"currency\".  Unknown values are empty.\n\nArguments:\n1. year   (numeric, required)                The calendar year of the report\n2. method (string, optional, default=\"fifo\") The lot matching method: \"fifo\" or \"lifo\" to match disposals with the oldest or newest lots held by the wallet, or \"specific\" to identify the lots by the coins actually spent\n\nResult:\n{\n \"year\": n,              (numeric) The calendar year of the report\n \"method\": \"value\",      (string)  The lot matching method\n \"currency\": \"value\",    (string)  The fiat currency of the values, or empty if no rates were recorded\n \"totalproceeds\": n.nnn, (numeric) The total proceeds of the gains whose proceeds and cost basis are known\n \"totalcost\": n.nnn,     (numeric) The total cost basis of the gains whose proceeds and cost basis are known\n \"totalgain\": n.nnn,     (numeric) The total realized gain, negative for a loss\n \"unknowncount\": n,      (numeric) The number of gains excluded from the totals because no fiat rate was recorded for their transactions or the acquisition of the coins is not recorded\n \"csv\": \"value\",         (string)  The realized gains as CSV with a header row\n}                        \n",
+		"exportaccounthistory":    "exportaccounthistory \"account\" (format=\"csv\" \"cursor\" count=1000)\n\nExports the mined transaction history of an account, oldest first, with the account's running balance after each transaction, for reconciliation.  Each transaction is a CSV row or a JSON object line with the \"height\", \"blockhash\", \"time\", \"txid\", \"type\", \"received\" (outputs paying the account), \"sent\" (outputs of the account spent), \"amount\" (received minus sent) and \"balance\".  The CSV header row is only included when no cursor is passed.  Unmined transactions are not exported, as their position in the history is not yet known.  The history is exported in pages: passing the nextcursor of a reply resumes the history after it, and also picks up transactions mined since.\n\nArguments:\n1. account (string, required)                The account to export the history of\n2. format  (string, optional, default=\"csv\") The export format: \"csv\" or \"jsonl\" for JSON lines\n3. cursor  (string, optional)                The nextcursor of a previous reply to resume the history after, or none to start with the first transaction\n4. count   (numeric, optional, default=1000) The maximum number of transactions to export\n\nResult:\n{\n \"account\": \"value\",     (string)  The account of the history\n \"format\": \"value\",      (string)  The export format\n \"count\": n,             (numeric) The number of exported transactions\n \"complete\": true|false, (boolean) Whether the history was exported up to the latest mined transaction\n \"nextcursor\": \"value\",  (string)  The cursor to pass to resume the history after the last exported transaction, omitted when nothing was exported yet\n \"data\": \"value\",        (string)  The exported transactions\n}                        \n",
+		"importpubkey":            "importpubkey \"pubkey\" (rescan=true)\n\nImports a hex encoded public key to the 'imported' account as a watching-only address and returns the address.  Outputs paid to the address may only be spent by a wallet signing with a remote signer holding the private key, such as a dcrsigner process using a hardware security module.\n\nArguments:\n1. pubkey (string, required)                The hex encoded public key\n2. rescan (boolean, optional, default=true) Rescan the blockchain (since the genesis block) for outputs paid to the address of the key\n\nResult:\n\"value\" (string) The P2PKH address of the public key\n",
+		"exportseedbackup":        "exportseedbackup \"passphrase\"\n\nReturns a seed backup file, encrypted with the passphrase, holding the wallet seed, the accounts and the number of addresses used by each, and the imported keys and scripts, which restores the wallet fully with the --restoreseedbackup option.  The wallet must be unlocked.\n\nArguments:\n1. passphrase (string, required) The passphrase to encrypt the seed backup file with\n\nResult:\n\"value\" (string) The base64 encoded seed backup file\n",
+		"getaddresspath":          "getaddresspath \"address\"\n\nReturns the account, branch, and index the key of a wallet address was derived at, so hardware and remote signers holding the wallet seed can derive the key of the address.  Imported addresses and scripts are not derived from the seed and have no derivation path.\n\nArguments:\n1. address (string, required) The wallet address\n\nResult:\n{\n \"address\": \"value\", (string)  The address\n \"account\": \"value\", (string)  The name of the account of the address\n \"accountnumber\": n, (numeric) The number of the account of the address\n \"branch\": n,        (numeric) The branch of the address: 0 for external and 1 for internal (change) addresses\n \"index\": n,         (numeric) The index of the address in its branch\n \"path\": \"value\",    (string)  The BIP0032 derivation path of the key of the address, m/44'/<coin type>'/<account>'/<branch>/<index>\n}                    \n",
+		"rotateimportedkey":       "rotateimportedkey \"address\" (account=\"default\" confirm=false)\n\nSweeps the funds controlled by an imported private key or script to a new internal address of a wallet account in a single transaction, and then retires the imported address once no outputs paid to it remain, for responding to a suspected exposure of the key.  Unless confirmed, nothing is signed, published, or retired, and the sweep which would be made is described so it can be reviewed first.  Immature, locked, unconfirmed, and dust outputs are skipped and may be swept by rotating the key again once they are spendable, and the address is not retired until they are.  Outputs of an imported script are only swept when the wallet holds enough keys to fully sign for the script.  Payments received later by a retired address are logged as warnings.\n\nArguments:\n1. address (string, required)                    The imported P2PKH or P2SH address to rotate\n2. account (string, optional, default=\"default\") The account to sweep the funds to\n3. confirm (boolean, optional, default=false)    Sweep the funds and retire the address instead of previewing the sweep\n\nResult:\n{\n \"address\": \"value\",     (string)  The rotated imported address\n \"inputs\": n,            (numeric) The number of outputs swept\n \"amount\": n.nnn,        (numeric) The total value of the swept outputs\n \"fee\": n.nnn,           (numeric) The fee paid by the sweep, out of the swept amount\n \"skipped\": n,           (numeric) The number of outputs to the address which could not be swept yet\n \"skippedamount\": n.nnn, (numeric) The total value of the skipped outputs\n \"destination\": \"value\", (string)  The new address the funds were swept to, omitted for previews and when there was nothing to sweep\n \"txid\": \"value\",        (string)  The hash of the sweep transaction, omitted for previews and when there was nothing to sweep\n \"retired\": true|false,  (boolean) Whether the address was retired, which only happens when no outputs were skipped\n}                        \n",
+		"listretiredaddresses":    "listretiredaddresses\n\nReturns the imported keys and scripts retired by rotateimportedkey, ordered by output script.\n\nArguments:\nNone\n\nResult:\n[{\n \"address\": \"value\",   (string)  The retired address\n \"script\": \"value\",    (string)  The hex-encoded output script of the address\n \"retired\": n,         (numeric) The Unix time the address was last retired\n \"sweeptxid\": \"value\", (string)  The hash of the transaction which swept the funds of the address, omitted when there was nothing to sweep\n},...]\n",
 	}
 }
 
@@ -71,4 +117,4 @@ var localeHelpDescs = map[string]func() map[string]string{
 	"en_US": helpDescsEnUS,
 }
 
-var requestUsages = "addmultisigaddress nrequired [\"key\",...] (\"account\")\ncreatemultisig nrequired [\"key\",...]\ndumpprivkey \"address\"\ngetaccount \"address\"\ngetaccountaddress \"account\"\ngetaddressesbyaccount \"account\"\ngetbalance (\"account\" minconf=1 \"balancetype\")\ngetbestblockhash\ngetblockcount\ngetinfo\ngetmasterpubkey\ngetmultisigoutinfo \"hash\" index\ngetseed\ngetnewaddress (\"account\" verbose=false)\ngetrawchangeaddress (\"account\" verbose=false)\ngetreceivedbyaccount \"account\" (minconf=1)\ngetreceivedbyaddress \"address\" (minconf=1)\ngettickets includeimmature\ngetticketmaxprice\ngettransaction \"txid\" (includewatchonly=false)\nhelp (\"command\")\nimportprivkey \"privkey\" (\"label\" rescan=true)\nimportscript \"hex\"\nkeypoolrefill (newsize=100)\nlistaccounts (minconf=1)\nlistlockunspent\nlistreceivedbyaccount (minconf=1 includeempty=false includewatchonly=false)\nlistreceivedbyaddress (minconf=1 includeempty=false includewatchonly=false)\nlistsinceblock (\"blockhash\" targetconfirmations=1 includewatchonly=false)\nlisttransactions (\"account\" count=10 from=0 includewatchonly=false)\nlistunspent (minconf=1 maxconf=9999999 [\"address\",...])\nlockunspent unlock [{\"txid\":\"value\",\"vout\":n,\"tree\":n},...]\nredeemmultisigout \"hash\" index tree (\"address\")\nredeemmultisigouts \"fromscraddress\" (\"toaddress\" number)\nsendfrom \"fromaccount\" \"toaddress\" amount (minconf=1 \"comment\" \"commentto\")\nsendmany \"fromaccount\" {\"address\":amount,...} (minconf=1 \"comment\")\nsendtoaddress \"address\" amount (\"comment\" \"commentto\")\nsendtomultisig \"fromaccount\" amount [\"pubkey\",...] (nrequired=1 minconf=1 \"comment\")\nsetticketmaxprice max\nsettxfee amount\nsignmessage \"address\" \"message\"\nsignrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"tree\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\nsignrawtransactions [\"rawtx\",...] (send=true)\nvalidateaddress \"address\"\nverifymessage \"address\" \"signature\" \"message\"\nwalletlock\nwalletpassphrase \"passphrase\" timeout\nwalletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\ncreatenewaccount \"account\"\nexportwatchingwallet (\"account\" download=false)\ngetbestblock\ngetunconfirmedbalance (\"account\")\nlistaddresstransactions [\"address\",...] (\"account\")\nlistalltransactions (\"account\")\nrenameaccount \"oldaccount\" \"newaccount\"\nwalletislocked\npurchaseticket \"fromaccount\" spendlimit (minconf=1 \"ticketaddress\" \"comment\")\nsendtossrtx \"fromaccount\" \"tickethash\" (\"comment\")\nsendtosstx \"fromaccount\" amounts [{\"txid\":\"value\",\"vout\":n,\"tree\":n,\"amt\":n},...] [{\"addr\":\"value\",\"commitamt\":n,\"changeaddr\":\"value\",\"changeamt\":n},...] (minconf=1 \"comment\")\nsendtossgen \"fromaccount\" \"tickethash\" \"blockhash\" height votebits (\"comment\")"
+var requestUsages = "addmultisigaddress nrequired [\"key\",...] (\"account\")\ncreatemultisig nrequired [\"key\",...]\ndumpprivkey \"address\"\ngetaccount \"address\"\ngetaccountaddress \"account\"\ngetaddressesbyaccount \"account\"\ngetbalance (\"account\" minconf=1 \"balancetype\")\ngetbestblockhash\ngetblockcount\ngetinfo\ngetmasterpubkey\ngetmultisigoutinfo \"hash\" index\ngetseed\ngetnewaddress (\"account\" verbose=false)\ngetrawchangeaddress (\"account\" verbose=false)\ngetreceivedbyaccount \"account\" (minconf=1)\ngetreceivedbyaddress \"address\" (minconf=1)\ngettickets includeimmature\ngetticketmaxprice\ngettransaction \"txid\" (includewatchonly=false)\nhelp (\"command\")\nimportprivkey \"privkey\" (\"label\" rescan=true)\nimportscript \"hex\"\nkeypoolrefill (newsize=100)\nlistaccounts (minconf=1)\nlistlockunspent\nlistreceivedbyaccount (minconf=1 includeempty=false includewatchonly=false)\nlistreceivedbyaddress (minconf=1 includeempty=false includewatchonly=false)\nlistsinceblock (\"blockhash\" targetconfirmations=1 includewatchonly=false)\nlisttransactions (\"account\" count=10 from=0 includewatchonly=false)\nlistunspent (minconf=1 maxconf=9999999 [\"address\",...])\nlockunspent unlock [{\"txid\":\"value\",\"vout\":n,\"tree\":n},...]\nredeemmultisigout \"hash\" index tree (\"address\")\nredeemmultisigouts \"fromscraddress\" (\"toaddress\" number)\nsendfrom \"fromaccount\" \"toaddress\" amount (minconf=1 \"comment\" \"commentto\")\nsendmany \"fromaccount\" {\"address\":amount,...} (minconf=1 \"comment\")\nsendtoaddress \"address\" amount (\"comment\" \"commentto\")\nsendtomultisig \"fromaccount\" amount [\"pubkey\",...] (nrequired=1 minconf=1 \"comment\")\nsetticketmaxprice max\nsettxfee amount\nsignmessage \"address\" \"message\"\nsignrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"tree\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\nsignrawtransactions [\"rawtx\",...] (send=true)\nticketsforaddress \"address\"\nvalidateaddress \"address\"\nverifymessage \"address\" \"signature\" \"message\"\nwalletlock\nwalletpassphrase \"passphrase\" timeout\nwalletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\ncreatenewaccount \"account\"\nexportwatchingwallet (\"account\" download=false)\ngetbestblock\ngetunconfirmedbalance (\"account\")\nlistaddresstransactions [\"address\",...] (\"account\")\nlistalltransactions (\"account\")\nrenameaccount \"oldaccount\" \"newaccount\"\nwalletislocked\npurchaseticket \"fromaccount\" spendlimit (minconf=1 \"ticketaddress\" \"comment\")\nsendtossrtx \"fromaccount\" \"tickethash\" (\"comment\")\nsendtosstx \"fromaccount\" amounts [{\"txid\":\"value\",\"vout\":n,\"tree\":n,\"amt\":n},...] [{\"addr\":\"value\",\"commitamt\":n,\"changeaddr\":\"value\",\"changeamt\":n},...] (minconf=1 \"comment\")\nsendtossgen \"fromaccount\" \"tickethash\" \"blockhash\" height votebits (\"comment\")\ncancelrescan\nrescanwallet (beginheight=0 begintime)\ngetlockinfo\nsetunlocktimeout timeout\ngetapiinfo (\"apiversion\")\nlistaddresstickets \"address\"\ngetfeesreport (starttime endtime)\nstartjob \"method\" ([param,...])\ngetjobstatus jobid\nlistjobs\ngetbackendstate\ndebuglevel \"levelspec\"\nloadwallet \"name\"\nunloadwallet \"name\"\nexportsigninglog (start=0 count=1000)\ngetblockvotebits startheight (endheight)\ngetbalancehistory (startheight=0 endheight interval=\"block\")\ngetimportedbalance (minconf=1 balancetype=\"spendable\")\ngetcreditorigin \"txid\" vout\nsetcreditorigin \"txid\" vout \"origin\"\nsetbirthday birthday\ndecodeaddress \"address\"\ndescribescript \"script\" (version=0)\ndecoderawtransaction \"hextx\"\ncreatemultisigwallet nrequired [\"key\",...] (count=20)\nlistpendingsends\napprovesend \"id\" (\"signature\")\nrejectsend \"id\"\nregistervsp (rescanfrom)\npurchasevsptickets count (minbalance=0 minconf)\nlistvsptickets\nestimatestakediff\ngetticketpoolshare (days=30)\ngetstakeinfo\ngetticketbuyerlog (count=100)\nlistaddressusage (\"account\")\ncreatepaymenturi (account=\"default\" amount \"label\" \"message\" expiry=0)\ndecodepaymenturi \"uri\"\nexportcapitalgains year (method=\"fifo\")\nexportaccounthistory \"account\" (format=\"csv\" \"cursor\" count=1000)\nimportpubkey \"pubkey\" (rescan=true)\nexportseedbackup \"passphrase\"\ngetaddresspath \"address\"\nrotateimportedkey \"address\" (account=\"default\" confirm=false)\nlistretiredaddresses"
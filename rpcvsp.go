@@ -0,0 +1,258 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/chain"
+	"github.com/decred/dcrwallet/stakepoolclient"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletjson"
+)
+
+// errNoVSP is returned by the voting service provider methods when no
+// provider is configured.
+var errNoVSP = InvalidParameterError{
+	errors.New("no voting service provider is configured (--vspurl)"),
+}
+
+// vspClient returns a client for the configured voting service provider.
+func vspClient() (*stakepoolclient.Client, error) {
+	if cfg.VSPURL == "" {
+		return nil, errNoVSP
+	}
+	return stakepoolclient.New(cfg.VSPURL, cfg.VSPAPIToken), nil
+}
+
+// vspPurchaseInfo describes the decoded purchase information of the
+// configured voting service provider.
+type vspPurchaseInfo struct {
+	ticketAddr dcrutil.Address
+	poolAddr   dcrutil.Address
+	poolFees   float64
+	script     []byte
+}
+
+// decodeVSPPurchaseInfo decodes and checks the purchase information returned
+// by a voting service provider.
+func decodeVSPPurchaseInfo(info *stakepoolclient.PurchaseInfo) (*vspPurchaseInfo,
+	error) {
+	ticketAddr, err := dcrutil.DecodeAddress(info.TicketAddress,
+		activeNet.Params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket address from voting "+
+			"service provider: %v", err)
+	}
+	if _, ok := ticketAddr.(*dcrutil.AddressScriptHash); !ok ||
+		!ticketAddr.IsForNet(activeNet.Params) {
+		return nil, fmt.Errorf("ticket address %v from voting service "+
+			"provider is not a %s P2SH address", info.TicketAddress,
+			activeNet.Params.Name)
+	}
+	poolAddr, err := dcrutil.DecodeAddress(info.PoolAddress, activeNet.Params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pool address from voting "+
+			"service provider: %v", err)
+	}
+	if _, ok := poolAddr.(*dcrutil.AddressPubKeyHash); !ok ||
+		!poolAddr.IsForNet(activeNet.Params) {
+		return nil, fmt.Errorf("pool address %v from voting service "+
+			"provider is not a %s pubkey hash address", info.PoolAddress,
+			activeNet.Params.Name)
+	}
+	if info.PoolFees <= 0 || info.PoolFees >= 100 {
+		return nil, fmt.Errorf("invalid pool fees %v from voting service "+
+			"provider", info.PoolFees)
+	}
+	script, err := hex.DecodeString(info.Script)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script from voting service "+
+			"provider: %v", err)
+	}
+	return &vspPurchaseInfo{
+		ticketAddr: ticketAddr,
+		poolAddr:   poolAddr,
+		poolFees:   info.PoolFees,
+		script:     script,
+	}, nil
+}
+
+// fetchVSPPurchaseInfo fetches the purchase information of the wallet's
+// account from the configured voting service provider.
+func fetchVSPPurchaseInfo() (*vspPurchaseInfo, error) {
+	client, err := vspClient()
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.PurchaseInfo()
+	if err != nil {
+		return nil, err
+	}
+	return decodeVSPPurchaseInfo(info)
+}
+
+// RegisterVSP handles a registervsp request by registering a public key
+// address of the wallet with the configured voting service provider, unless
+// one was already registered, and importing the multisig script shared with
+// the provider after checking it includes a key of the wallet.
+func RegisterVSP(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*walletjson.RegisterVSPCmd)
+
+	client, err := vspClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// The API only allows the address of an account to be set once, so
+	// registering again just imports the existing script, such as after
+	// restoring the wallet from seed.
+	registered := false
+	info, err := client.PurchaseInfo()
+	if err != nil {
+		if _, ok := err.(*stakepoolclient.APIError); !ok {
+			return nil, err
+		}
+		pubKeyAddr, err := w.VSPPubKeyAddress(waddrmgr.DefaultAccountNum)
+		if err != nil {
+			return nil, err
+		}
+		err = client.SetAddress(pubKeyAddr.EncodeAddress())
+		if err != nil {
+			return nil, err
+		}
+		registered = true
+		info, err = client.PurchaseInfo()
+		if err != nil {
+			return nil, err
+		}
+	}
+	purchaseInfo, err := decodeVSPPurchaseInfo(info)
+	if err != nil {
+		return nil, err
+	}
+
+	firstSeen := w.Manager.SyncedTo().Height
+	if cmd.RescanFrom != nil {
+		firstSeen = *cmd.RescanFrom
+	}
+	_, err = w.ImportVSPScript(purchaseInfo.script, purchaseInfo.ticketAddr,
+		firstSeen)
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		return nil, err
+	}
+
+	return &walletjson.RegisterVSPResult{
+		Registered:    registered,
+		TicketAddress: info.TicketAddress,
+		Script:        info.Script,
+		PoolAddress:   info.PoolAddress,
+		PoolFees:      info.PoolFees,
+	}, nil
+}
+
+// PurchaseVSPTickets handles a purchasevsptickets request by purchasing
+// tickets voting with the ticket address of the configured voting service
+// provider and committing its pool fee.  The provider's script must have
+// been imported with registervsp.
+func PurchaseVSPTickets(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	icmd, origin := unwrapSigningCmd(icmd)
+	cmd := icmd.(*walletjson.PurchaseVSPTicketsCmd)
+
+	minBalance, err := dcrutil.NewAmount(*cmd.MinBalance)
+	if err != nil {
+		return nil, err
+	}
+	if minBalance < 0 {
+		return nil, ErrNeedPositiveAmount
+	}
+	minConf, err := requestMinConf(w, cmd.MinConf)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := fetchVSPPurchaseInfo()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Manager.Address(info.ticketAddr); err != nil {
+		return nil, InvalidParameterError{fmt.Errorf("the script of "+
+			"ticket address %v is not imported; use registervsp",
+			info.ticketAddr)}
+	}
+
+	hashes, err := w.PurchasePoolTickets(minBalance, cmd.Count, minConf,
+		info.ticketAddr, info.poolAddr, info.poolFees, origin)
+	hashStrs := make([]string, len(hashes))
+	for i, h := range hashes {
+		hashStrs[i] = h.String()
+	}
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		if len(hashes) == 0 {
+			return nil, err
+		}
+		// Report the tickets which were published before the error.
+		return nil, &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCWallet,
+			Message: fmt.Sprintf("purchased %d of %d tickets (%v): %v",
+				len(hashes), cmd.Count, hashStrs, err),
+		}
+	}
+	return hashStrs, nil
+}
+
+// ListVSPTickets handles a listvsptickets request by returning the status of
+// every ticket of the wallet voting with the ticket address of the configured
+// voting service provider, and whether each pays the pool fee.
+func ListVSPTickets(w *wallet.Wallet, chainSvr *chain.Client,
+	icmd interface{}) (interface{}, error) {
+	info, err := fetchVSPPurchaseInfo()
+	if err != nil {
+		return nil, err
+	}
+	tickets, err := w.VSPTickets(info.ticketAddr, info.poolAddr,
+		info.poolFees)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]walletjson.ListVSPTicketsResult, len(tickets))
+	for i := range tickets {
+		t := &tickets[i]
+		results[i] = walletjson.ListVSPTicketsResult{
+			Ticket:  t.Hash.String(),
+			Status:  t.Status.String(),
+			Price:   t.Price.ToCoin(),
+			PoolFee: t.PoolFee.ToCoin(),
+			FeePaid: t.FeePaid,
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrwallet/chain"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletdb"
+	"github.com/decred/dcrwallet/walletjson"
+)
+
+// walletPathPrefix is the HTTP path prefix of requests for a wallet loaded by
+// the loadwallet method.  Requests POSTed to /wallet/<name> are handled by the
+// wallet with that name, while requests POSTed to any other path are handled
+// by the wallet the process was started with.
+const walletPathPrefix = "/wallet/"
+
+// loadedWallet is a wallet loaded by the loadwallet method in addition to the
+// wallet the process was started with.  Each loaded wallet has its own
+// database, lock state, and chain server connection.
+type loadedWallet struct {
+	name   string
+	wallet *wallet.Wallet
+	db     walletdb.DB
+
+	mu       sync.Mutex
+	chainSvr *chain.Client
+
+	// quit is closed to stop syncing the wallet with chain servers, and
+	// done is closed once syncing has stopped.
+	quit chan struct{}
+	done chan struct{}
+}
+
+// walletDataDir returns the data directory of the wallet with the given name.
+// It is created with the --create option by passing it as --datadir.
+func walletDataDir(name string) string {
+	return filepath.Join(cfg.DataDir, "wallets", name)
+}
+
+// checkWalletName returns an error if name may not be used as the name of a
+// loaded wallet.  Names are directory names, so they may not be empty or
+// refer to any other directory than one in the wallets directory.
+func checkWalletName(name string) error {
+	if name == "" || name == "." || name == ".." ||
+		strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid wallet name %q", name)
+	}
+	return nil
+}
+
+// walletNameFromPath returns the name of the wallet which handles requests
+// POSTed to an HTTP path.  The name is empty for requests handled by the
+// wallet the process was started with.  ok is false if the path names no
+// wallet after the wallet path prefix.
+func walletNameFromPath(path string) (name string, ok bool) {
+	if !strings.HasPrefix(path, walletPathPrefix) {
+		return "", true
+	}
+	name = path[len(walletPathPrefix):]
+	return name, name != ""
+}
+
+// setChainServer records the chain server client the wallet is syncing with.
+// Clients connected after the wallet began unloading are stopped instead.
+func (lw *loadedWallet) setChainServer(chainSvr *chain.Client) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	select {
+	case <-lw.quit:
+		if chainSvr != nil {
+			chainSvr.Stop()
+		}
+		return
+	default:
+	}
+	lw.chainSvr = chainSvr
+}
+
+// handlerClosure creates a closure function for handling requests of the
+// given method with the loaded wallet.  Methods which require a chain server
+// return errors while the wallet is offline.
+func (lw *loadedWallet) handlerClosure(method,
+	origin string) requestHandlerClosure {
+	lw.mu.Lock()
+	chainSvr := lw.chainSvr
+	lw.mu.Unlock()
+
+	lookup := offlineWalletHandlerFunc
+	if chainSvr != nil {
+		lookup = lookupAnyHandler
+	}
+	return handlerClosure(lookup, lw.wallet, chainSvr, method, origin)
+}
+
+// unload stops the wallet and its chain server connection and closes the
+// wallet database.
+func (lw *loadedWallet) unload() {
+	lw.mu.Lock()
+	close(lw.quit)
+	if lw.chainSvr != nil {
+		lw.chainSvr.Stop()
+	}
+	lw.mu.Unlock()
+
+	<-lw.done
+	lw.wallet.Stop()
+	lw.wallet.WaitForShutdown()
+	lw.wallet.CloseDatabases()
+	if err := lw.db.Close(); err != nil {
+		rpcsLog.Errorf("Cannot close database of wallet %s: %v",
+			lw.name, err)
+	}
+}
+
+// loadWallet opens the wallet with the given name from its data directory and
+// begins syncing it with the configured chain servers, unless running
+// offline.  The wallet is opened with the same options as the wallet the
+// process was started with.
+func (s *rpcServer) loadWallet(name string) error {
+	if err := checkWalletName(name); err != nil {
+		return InvalidParameterError{err}
+	}
+
+	s.walletsMu.Lock()
+	defer s.walletsMu.Unlock()
+
+	if _, ok := s.wallets[name]; ok {
+		return fmt.Errorf("wallet %s is already loaded", name)
+	}
+
+	// Opening the database creates it when it does not exist, so check
+	// that the wallet was created first.
+	walletCfg := *cfg
+	walletCfg.DataDir = walletDataDir(name)
+	dbPath := filepath.Join(networkDir(walletCfg.DataDir, activeNet.Params),
+		walletDbFilename(walletCfg.DbType))
+	if !fileExists(dbPath) {
+		return fmt.Errorf("wallet %s does not exist -- create it with "+
+			"--create --datadir=%s", name, walletCfg.DataDir)
+	}
+
+	w, db, err := openWallet(&walletCfg)
+	if err != nil {
+		if db != nil {
+			db.Close()
+		}
+		return err
+	}
+	w.StartOffline()
+
+	lw := &loadedWallet{
+		name:   name,
+		wallet: w,
+		db:     db,
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if cfg.Offline {
+		close(lw.done)
+	} else {
+		go func() {
+			syncWithChainServers(w, lw.setChainServer, lw.quit)
+			close(lw.done)
+		}()
+	}
+
+	if s.wallets == nil {
+		s.wallets = make(map[string]*loadedWallet)
+	}
+	s.wallets[name] = lw
+	rpcsLog.Infof("Loaded wallet %s", name)
+	return nil
+}
+
+// unloadWallet stops and closes the loaded wallet with the given name.
+func (s *rpcServer) unloadWallet(name string) error {
+	s.walletsMu.Lock()
+	lw, ok := s.wallets[name]
+	delete(s.wallets, name)
+	s.walletsMu.Unlock()
+
+	if !ok {
+		return InvalidParameterError{
+			fmt.Errorf("wallet %s is not loaded", name)}
+	}
+	lw.unload()
+	rpcsLog.Infof("Unloaded wallet %s", name)
+	return nil
+}
+
+// unloadAllWallets stops and closes every loaded wallet.
+func (s *rpcServer) unloadAllWallets() {
+	s.walletsMu.Lock()
+	wallets := s.wallets
+	s.wallets = nil
+	s.walletsMu.Unlock()
+
+	for _, lw := range wallets {
+		lw.unload()
+	}
+}
+
+// loadedWalletHandlerClosure creates a closure function for handling requests
+// of the given method with the loaded wallet of the given name.  Transactions
+// signed by the request are attributed to origin in the signing log.
+func (s *rpcServer) loadedWalletHandlerClosure(name, method,
+	origin string) requestHandlerClosure {
+	s.walletsMu.Lock()
+	lw, ok := s.wallets[name]
+	s.walletsMu.Unlock()
+
+	if !ok {
+		return func(*dcrjson.Request) (interface{}, *dcrjson.RPCError) {
+			return nil, &dcrjson.RPCError{
+				Code:    dcrjson.ErrRPCWallet,
+				Message: fmt.Sprintf("wallet %s is not loaded", name),
+			}
+		}
+	}
+	return lw.handlerClosure(method, origin)
+}
+
+// isWalletLoaderMethod returns whether the method is one of the methods used
+// to load and unload wallets.  These are handled by the server rather than a
+// request handler, as they do not act on any single wallet.
+func isWalletLoaderMethod(method string) bool {
+	switch method {
+	case "loadwallet", "unloadwallet":
+		return true
+	}
+	return false
+}
+
+// handleWalletLoaderRequest handles the loadwallet and unloadwallet methods
+// for a client which has already been checked for the permission to call
+// them.
+func (s *rpcServer) handleWalletLoaderRequest(
+	req *dcrjson.Request) (interface{}, *dcrjson.RPCError) {
+	cmd, err := dcrjson.UnmarshalCmd(req)
+	if err != nil {
+		return nil, dcrjson.ErrRPCInvalidRequest
+	}
+
+	switch cmd := cmd.(type) {
+	case *walletjson.LoadWalletCmd:
+		return nil, jsonError(s.loadWallet(cmd.Name))
+
+	case *walletjson.UnloadWalletCmd:
+		return nil, jsonError(s.unloadWallet(cmd.Name))
+
+	default:
+		return nil, dcrjson.ErrRPCMethodNotFound
+	}
+}
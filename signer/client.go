@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package signer
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrutil"
+)
+
+// Client timeouts.
+const (
+	dialTimeout    = 10 * time.Second
+	requestTimeout = 30 * time.Second
+)
+
+// ErrAuthentication is returned by a Client when a response of the signer
+// fails authentication, which happens when the wallet and signer are
+// configured with different shared keys.
+var ErrAuthentication = errors.New("remote signer response failed " +
+	"authentication")
+
+// Client requests signatures from a remote signer.  It connects on first use
+// and reconnects after any connection failure.  It is safe for concurrent
+// use; requests are sent one at a time.
+type Client struct {
+	addr string
+	key  []byte
+
+	mu        sync.Mutex
+	conn      net.Conn
+	r         *bufio.Reader
+	challenge []byte
+	seq       uint64
+}
+
+// NewClient returns a client of the signer listening on addr which
+// authenticates with key.
+func NewClient(addr string, key []byte) *Client {
+	return &Client{addr: addr, key: key}
+}
+
+// connect dials the signer and reads its hello.  The client mutex must be
+// held.
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout("tcp", c.addr, dialTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+	r := bufio.NewReaderSize(conn, maxMessageSize)
+	var h hello
+	err = readMessage(r, &h)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to read remote signer hello: %v", err)
+	}
+	challenge, err := hex.DecodeString(h.Challenge)
+	if err != nil || len(challenge) != challengeSize {
+		conn.Close()
+		return errors.New("remote signer sent an invalid challenge")
+	}
+	c.conn = conn
+	c.r = r
+	c.challenge = challenge
+	c.seq = 0
+	return nil
+}
+
+// disconnect closes the connection.  The client mutex must be held.
+func (c *Client) disconnect() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// SignHash returns the DER encoded signature of the 32-byte signature hash
+// made by the remote signer with the private key of the P2PKH address.
+func (c *Client) SignHash(addr dcrutil.Address, sigHash []byte) ([]byte, error) {
+	if len(sigHash) != sigHashSize {
+		return nil, errors.New("signature hash must be 32 bytes")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		err := c.connect()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.seq++
+	req := request{
+		Seq:     c.seq,
+		Address: addr.EncodeAddress(),
+		SigHash: hex.EncodeToString(sigHash),
+	}
+	req.MAC = req.mac(c.key, c.challenge)
+	c.conn.SetDeadline(time.Now().Add(requestTimeout))
+	err := writeMessage(c.conn, &req)
+	if err != nil {
+		c.disconnect()
+		return nil, err
+	}
+	var resp response
+	err = readMessage(c.r, &resp)
+	if err != nil {
+		c.disconnect()
+		return nil, fmt.Errorf("failed to read remote signer response: %v",
+			err)
+	}
+	if resp.Seq != req.Seq || !validMAC(resp.MAC, resp.mac(c.key, c.challenge)) {
+		c.disconnect()
+		return nil, ErrAuthentication
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("remote signer: %s", resp.Error)
+	}
+	sig, err := hex.DecodeString(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer sent an invalid signature: %v",
+			err)
+	}
+	return sig, nil
+}
+
+// Close closes the connection to the signer, if any.  The client may still be
+// used, and reconnects on the next request.
+func (c *Client) Close() {
+	c.mu.Lock()
+	c.disconnect()
+	c.mu.Unlock()
+}
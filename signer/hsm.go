@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package signer
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainec"
+)
+
+// secp256k1OID is the DER encoded object identifier of the secp256k1 curve,
+// the EC parameters of keys generated in a hardware security module.
+var secp256k1OID = []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}
+
+// HSMKey describes a signing key held by a hardware security module.
+type HSMKey struct {
+	// Label is the label the key was generated with.
+	Label string
+
+	// PubKey is the compressed serialized public key, which may be
+	// imported to a watching-only wallet with the importpubkey method.
+	PubKey []byte
+}
+
+// AuditEvent records a single signature request handled by a hardware
+// security module, whether or not it succeeded.
+type AuditEvent struct {
+	Time     time.Time `json:"time"`
+	Address  string    `json:"address"`
+	SigHash  string    `json:"sighash"`
+	KeyLabel string    `json:"keylabel,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// derSignature converts the raw ECDSA signature returned by a hardware
+// security module, the 32-byte R and S values concatenated, to the DER
+// encoding used in signature scripts.  S is replaced by N-S when it is in the
+// upper half of the curve order, since such signatures are not standard.
+func derSignature(raw []byte) ([]byte, error) {
+	if len(raw) != 64 {
+		return nil, errors.New("signature must be 64 bytes")
+	}
+	n := chainec.Secp256k1.GetN()
+	r := new(big.Int).SetBytes(raw[:32])
+	s := new(big.Int).SetBytes(raw[32:])
+	if r.Sign() == 0 || r.Cmp(n) >= 0 || s.Sign() == 0 || s.Cmp(n) >= 0 {
+		return nil, errors.New("signature values out of range")
+	}
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s.Sub(n, s)
+	}
+	return chainec.Secp256k1.NewSignature(r, s).Serialize(), nil
+}
+
+// parseECPoint parses the CKA_EC_POINT attribute of a public key object,
+// returning the compressed serialized public key.  The point is DER encoded
+// as an octet string by conforming modules, but some return it bare.
+func parseECPoint(point []byte) ([]byte, error) {
+	if len(point) == 67 && point[0] == 0x04 && point[1] == 65 {
+		point = point[2:]
+	}
+	pubKey, err := chainec.Secp256k1.ParsePubKey(point)
+	if err != nil {
+		return nil, err
+	}
+	return pubKey.SerializeCompressed(), nil
+}
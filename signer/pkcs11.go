@@ -0,0 +1,291 @@
+// Copyright (c) 2016 The Decred developers
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// This file implements signing with keys held by a hardware security module
+// through a PKCS#11 module.  The module library is loaded with cgo, so it is
+// only built with the pkcs11 build tag:
+//
+//   go build -tags pkcs11 github.com/decred/dcrwallet/cmd/dcrsigner
+
+//go:build pkcs11
+// +build pkcs11
+
+package signer
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrutil"
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS11Config describes the token holding the keys of a PKCS11Signer.
+type PKCS11Config struct {
+	// Module is the path of the PKCS#11 module library of the HSM.
+	Module string
+
+	// Slot is the ID of the slot of the token.
+	Slot uint
+
+	// PIN is the user PIN of the token.
+	PIN string
+}
+
+// PKCS11Signer generates and signs with secp256k1 keys which never leave a
+// hardware security module.  Each private key is found by its CKA_ID, the
+// hash160 of its compressed public key, which is also the hash of its P2PKH
+// address.  It is safe for concurrent use, although signatures are made one
+// at a time.
+type PKCS11Signer struct {
+	// Audit, if set, is called with an event for every signature request,
+	// including refused and failed requests.  It is called while requests
+	// are serialized, so it must not call the signer.
+	Audit func(*AuditEvent)
+
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+}
+
+// OpenPKCS11 loads the PKCS#11 module and logs in to the token.
+func OpenPKCS11(cfg *PKCS11Config) (*PKCS11Signer, error) {
+	ctx := pkcs11.New(cfg.Module)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %s",
+			cfg.Module)
+	}
+	err := ctx.Initialize()
+	if err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+	session, err := ctx.OpenSession(cfg.Slot,
+		pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	err = ctx.Login(session, pkcs11.CKU_USER, cfg.PIN)
+	if err != nil {
+		ctx.CloseSession(session)
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return &PKCS11Signer{ctx: ctx, session: session}, nil
+}
+
+// Close logs out of the token and unloads the module.
+func (s *PKCS11Signer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ctx.Logout(s.session)
+	s.ctx.CloseSession(s.session)
+	s.ctx.Finalize()
+	s.ctx.Destroy()
+	return err
+}
+
+// GenerateKey generates a new secp256k1 key pair in the token, returning the
+// compressed serialized public key.  The private key is marked sensitive and
+// not extractable, so it never leaves the token.
+func (s *PKCS11Signer) GenerateKey(label string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pubTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, secp256k1OID),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	privTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	mech := []*pkcs11.Mechanism{
+		pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil),
+	}
+	pubObj, privObj, err := s.ctx.GenerateKeyPair(s.session, mech,
+		pubTemplate, privTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := s.pubKey(pubObj)
+	if err != nil {
+		return nil, err
+	}
+
+	// Identify both objects by the hash of the P2PKH address so the
+	// private key of an address can be found when signing.
+	id := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_ID, dcrutil.Hash160(pubKey)),
+	}
+	err = s.ctx.SetAttributeValue(s.session, pubObj, id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.ctx.SetAttributeValue(s.session, privObj, id)
+	if err != nil {
+		return nil, err
+	}
+	return pubKey, nil
+}
+
+// Keys returns every secp256k1 key of the token which may be used for
+// signing.
+func (s *PKCS11Signer) Keys() ([]HSMKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objs, err := s.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, secp256k1OID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]HSMKey, 0, len(objs))
+	for _, obj := range objs {
+		pubKey, err := s.pubKey(obj)
+		if err != nil {
+			return nil, err
+		}
+		attrs, err := s.ctx.GetAttributeValue(s.session, obj,
+			[]*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+			})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, HSMKey{
+			Label:  string(attrs[0].Value),
+			PubKey: pubKey,
+		})
+	}
+	return keys, nil
+}
+
+// SignHash signs the signature hash with the private key of the P2PKH
+// address, returning the DER encoded signature.
+func (s *PKCS11Signer) SignHash(addr dcrutil.Address, sigHash []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event := &AuditEvent{
+		Time:    time.Now(),
+		Address: addr.EncodeAddress(),
+		SigHash: hex.EncodeToString(sigHash),
+	}
+	sig, err := s.signHash(addr, sigHash, event)
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if s.Audit != nil {
+		s.Audit(event)
+	}
+	return sig, err
+}
+
+// signHash signs for SignHash, recording the label of the key in the audit
+// event.  The signer mutex must be held.
+func (s *PKCS11Signer) signHash(addr dcrutil.Address, sigHash []byte,
+	event *AuditEvent) ([]byte, error) {
+	if _, ok := addr.(*dcrutil.AddressPubKeyHash); !ok {
+		return nil, errors.New("only P2PKH addresses are supported")
+	}
+	if len(sigHash) != sigHashSize {
+		return nil, errors.New("signature hash must be 32 bytes")
+	}
+
+	objs, err := s.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, addr.ScriptAddress()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) != 1 {
+		return nil, fmt.Errorf("no key for address %v in the HSM", addr)
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.session, objs[0],
+		[]*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil)})
+	if err == nil {
+		event.KeyLabel = string(attrs[0].Value)
+	}
+
+	mech := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}
+	err = s.ctx.SignInit(s.session, mech, objs[0])
+	if err != nil {
+		return nil, err
+	}
+	raw, err := s.ctx.Sign(s.session, sigHash)
+	if err != nil {
+		return nil, err
+	}
+	return derSignature(raw)
+}
+
+// pubKey returns the compressed serialized public key of a public key
+// object.  The signer mutex must be held.
+func (s *PKCS11Signer) pubKey(obj pkcs11.ObjectHandle) ([]byte, error) {
+	attrs, err := s.ctx.GetAttributeValue(s.session, obj,
+		[]*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil)})
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := parseECPoint(attrs[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key in the HSM: %v", err)
+	}
+	return pubKey, nil
+}
+
+// findObjects returns every object of the token matching the template.  The
+// signer mutex must be held.
+func (s *PKCS11Signer) findObjects(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	err := s.ctx.FindObjectsInit(s.session, template)
+	if err != nil {
+		return nil, err
+	}
+	defer s.ctx.FindObjectsFinal(s.session)
+
+	var objs []pkcs11.ObjectHandle
+	for {
+		found, _, err := s.ctx.FindObjects(s.session, 64)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return objs, nil
+		}
+		objs = append(objs, found...)
+	}
+}
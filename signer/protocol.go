@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package signer implements a remote signer which holds the private keys of a
+// wallet on a separate, hardened host.  The online wallet stays watching-only:
+// it syncs with the chain and constructs transactions, and forwards the
+// signature hash of each input to the signer, which returns a signature made
+// with the key of the input's address.
+//
+// The wallet and signer speak a small line-based JSON protocol over TCP.  After
+// accepting a connection, the signer sends a hello message with a random
+// challenge:
+//
+//   {"challenge":"<32 bytes hex>"}
+//
+// The wallet then sends sign requests, one per line, with sequence numbers
+// starting at 1 and incremented for every request:
+//
+//   {"seq":1,"address":"<P2PKH address>","sighash":"<32 bytes hex>","mac":"<hex>"}
+//
+// and the signer replies to each request in order:
+//
+//   {"seq":1,"signature":"<DER signature hex>","mac":"<hex>"}
+//   {"seq":2,"error":"<description>","mac":"<hex>"}
+//
+// Every request and response is authenticated by its mac, an HMAC-SHA256 keyed
+// with a secret shared by the wallet and the signer, of the connection's
+// challenge, the message kind and sequence number, and the message fields.
+// Binding the challenge and sequence number prevents messages from being
+// replayed on the same or another connection.  The protocol authenticates but
+// does not encrypt messages; signature hashes and signatures reveal no secrets.
+// A connection is closed by the signer on the first message that fails
+// authentication.
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// challengeSize is the size of the random challenge sent by the signer.
+const challengeSize = 32
+
+// sigHashSize is the size of the signature hashes signed by the signer.
+const sigHashSize = 32
+
+// hello is the first message the signer sends on each connection.
+type hello struct {
+	Challenge string `json:"challenge"`
+}
+
+// request is a sign request sent by the wallet.
+type request struct {
+	Seq     uint64 `json:"seq"`
+	Address string `json:"address"`
+	SigHash string `json:"sighash"`
+	MAC     string `json:"mac"`
+}
+
+// response is the signer's reply to a request.
+type response struct {
+	Seq       uint64 `json:"seq"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+	MAC       string `json:"mac"`
+}
+
+// Message kinds bound by the mac so that a request can never be accepted as
+// a response or the reverse.
+const (
+	kindRequest  = "request"
+	kindResponse = "response"
+)
+
+// messageMAC returns the hex encoded mac of a message of the kind with the
+// sequence number and fields, on the connection with the challenge.  Each
+// field is length prefixed so that no two messages have the same encoding.
+func messageMAC(key, challenge []byte, kind string, seq uint64,
+	fields ...[]byte) string {
+	mac := hmac.New(sha256.New, key)
+	write := func(b []byte) {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(b)))
+		mac.Write(length[:])
+		mac.Write(b)
+	}
+	var seqBytes [8]byte
+	binary.BigEndian.PutUint64(seqBytes[:], seq)
+	write(challenge)
+	write([]byte(kind))
+	write(seqBytes[:])
+	for _, f := range fields {
+		write(f)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validMAC returns whether the hex encoded mac equals the expected mac, in
+// constant time.
+func validMAC(mac, expected string) bool {
+	return hmac.Equal([]byte(mac), []byte(expected))
+}
+
+func (r *request) mac(key, challenge []byte) string {
+	return messageMAC(key, challenge, kindRequest, r.Seq,
+		[]byte(r.Address), []byte(r.SigHash))
+}
+
+func (r *response) mac(key, challenge []byte) string {
+	return messageMAC(key, challenge, kindResponse, r.Seq,
+		[]byte(r.Signature), []byte(r.Error))
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package signer
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrutil"
+)
+
+// maxMessageSize is the maximum length of a single protocol message.
+const maxMessageSize = 4096
+
+// idleTimeout is the time a connection may remain idle before the signer
+// closes it.
+const idleTimeout = 10 * time.Minute
+
+// KeyStore provides the private keys used by a Server.
+type KeyStore interface {
+	// PrivKey returns the private key of a P2PKH address.
+	PrivKey(addr dcrutil.Address) (chainec.PrivateKey, error)
+}
+
+// HashSigner signs for a Server with private keys which are never revealed
+// to it, such as keys held by a hardware security module.
+type HashSigner interface {
+	// SignHash returns the DER encoded signature of the 32-byte signature
+	// hash made with the private key of the P2PKH address.
+	SignHash(addr dcrutil.Address, sigHash []byte) ([]byte, error)
+}
+
+// keyStoreSigner signs with the private keys of a key store.
+type keyStoreSigner struct {
+	keys KeyStore
+}
+
+func (s keyStoreSigner) SignHash(addr dcrutil.Address, sigHash []byte) ([]byte, error) {
+	privKey, err := s.keys.PrivKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	r, ss, err := chainec.Secp256k1.Sign(privKey, sigHash)
+	if err != nil {
+		return nil, err
+	}
+	return chainec.Secp256k1.NewSignature(r, ss).Serialize(), nil
+}
+
+// Logger receives a line for each signature made or refused by a Server.
+type Logger interface {
+	Infof(format string, params ...interface{})
+	Warnf(format string, params ...interface{})
+}
+
+// Server signs the signature hashes requested by wallets which authenticate
+// with the shared key.
+type Server struct {
+	signer HashSigner
+	key    []byte
+	params *chaincfg.Params
+	log    Logger
+}
+
+// NewServer returns a server signing with the keys of the key store for
+// wallets on the network params which authenticate with key.  Signatures
+// and refused requests are reported to log, which may be nil.
+func NewServer(keys KeyStore, key []byte, params *chaincfg.Params,
+	log Logger) *Server {
+	return NewHashSignerServer(keyStoreSigner{keys}, key, params, log)
+}
+
+// NewHashSignerServer returns a server like NewServer which signs with the
+// hash signer instead of private keys it has access to.
+func NewHashSignerServer(signer HashSigner, key []byte,
+	params *chaincfg.Params, log Logger) *Server {
+	return &Server{signer: signer, key: key, params: params, log: log}
+}
+
+// Serve accepts connections from the listener and serves each of them in a
+// new goroutine.  It returns when the listener fails, such as when it is
+// closed.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn serves sign requests on a single connection until it is closed or
+// a request fails authentication.  The connection is closed on return.
+func (s *Server) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+
+	challenge := make([]byte, challengeSize)
+	_, err := rand.Read(challenge)
+	if err != nil {
+		return err
+	}
+	err = writeMessage(conn, &hello{hex.EncodeToString(challenge)})
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReaderSize(conn, maxMessageSize)
+	for seq := uint64(1); ; seq++ {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		var req request
+		err := readMessage(r, &req)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !validMAC(req.MAC, req.mac(s.key, challenge)) {
+			s.warnf("Closing connection from %v: request failed "+
+				"authentication", conn.RemoteAddr())
+			return errors.New("request failed authentication")
+		}
+		if req.Seq != seq {
+			s.warnf("Closing connection from %v: request %d is out of "+
+				"sequence", conn.RemoteAddr(), req.Seq)
+			return fmt.Errorf("request %d is out of sequence", req.Seq)
+		}
+
+		resp := response{Seq: seq}
+		sig, err := s.sign(&req)
+		if err != nil {
+			s.warnf("Refused to sign for %v: %v", req.Address, err)
+			resp.Error = err.Error()
+		} else {
+			s.infof("Signed hash %v for %v", req.SigHash, req.Address)
+			resp.Signature = hex.EncodeToString(sig)
+		}
+		resp.MAC = resp.mac(s.key, challenge)
+		err = writeMessage(conn, &resp)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sign signs the signature hash of an authenticated request.
+func (s *Server) sign(req *request) ([]byte, error) {
+	addr, err := dcrutil.DecodeAddress(req.Address, s.params)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := addr.(*dcrutil.AddressPubKeyHash); !ok {
+		return nil, errors.New("only P2PKH addresses are supported")
+	}
+	sigHash, err := hex.DecodeString(req.SigHash)
+	if err != nil || len(sigHash) != sigHashSize {
+		return nil, errors.New("signature hash must be 32 bytes of hex")
+	}
+	return s.signer.SignHash(addr, sigHash)
+}
+
+func (s *Server) infof(format string, params ...interface{}) {
+	if s.log != nil {
+		s.log.Infof(format, params...)
+	}
+}
+
+func (s *Server) warnf(format string, params ...interface{}) {
+	if s.log != nil {
+		s.log.Warnf(format, params...)
+	}
+}
+
+// writeMessage writes a message as a single line of JSON.
+func writeMessage(w io.Writer, msg interface{}) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// readMessage reads a line of JSON into msg.  Lines longer than
+// maxMessageSize are rejected.
+func readMessage(r *bufio.Reader, msg interface{}) error {
+	line, isPrefix, err := r.ReadLine()
+	if err != nil {
+		return err
+	}
+	if isPrefix {
+		return errors.New("message exceeds the maximum size")
+	}
+	return json.Unmarshal(line, msg)
+}
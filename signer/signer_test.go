@@ -0,0 +1,259 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package signer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrutil"
+)
+
+var (
+	testKey     = []byte("shared signer key")
+	testSigHash = bytes.Repeat([]byte{0x5a}, sigHashSize)
+	testParams  = &chaincfg.SimNetParams
+)
+
+type testKeyStore struct {
+	addr    dcrutil.Address
+	privKey chainec.PrivateKey
+}
+
+func (s *testKeyStore) PrivKey(addr dcrutil.Address) (chainec.PrivateKey, error) {
+	if addr.EncodeAddress() != s.addr.EncodeAddress() {
+		return nil, errors.New("unknown address")
+	}
+	return s.privKey, nil
+}
+
+func newTestKeyStore(t *testing.T) (*testKeyStore, chainec.PublicKey) {
+	privKey, pubKey := chainec.Secp256k1.PrivKeyFromBytes(
+		bytes.Repeat([]byte{0x01}, 32))
+	addr, err := dcrutil.NewAddressPubKeyHash(
+		dcrutil.Hash160(pubKey.SerializeCompressed()), testParams,
+		chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKeyStore{addr, privKey}, pubKey
+}
+
+func startTestServer(t *testing.T, keys KeyStore, key []byte) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go NewServer(keys, key, testParams, nil).Serve(l)
+	return l
+}
+
+func TestSignHash(t *testing.T) {
+	keys, pubKey := newTestKeyStore(t)
+	l := startTestServer(t, keys, testKey)
+	defer l.Close()
+
+	c := NewClient(l.Addr().String(), testKey)
+	defer c.Close()
+
+	// Several requests on the same connection must keep the sequence
+	// numbers of both ends in step.
+	for i := 0; i < 3; i++ {
+		sig, err := c.SignHash(keys.addr, testSigHash)
+		if err != nil {
+			t.Fatalf("SignHash %d: %v", i, err)
+		}
+		parsed, err := chainec.Secp256k1.ParseDERSignature(sig)
+		if err != nil {
+			t.Fatalf("SignHash %d: invalid signature: %v", i, err)
+		}
+		if !chainec.Secp256k1.Verify(pubKey, testSigHash, parsed.GetR(),
+			parsed.GetS()) {
+			t.Fatalf("SignHash %d: signature does not verify", i)
+		}
+	}
+
+	// Refused requests are reported without closing the connection.
+	other, err := dcrutil.NewAddressPubKeyHash(make([]byte, 20), testParams,
+		chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.SignHash(other, testSigHash)
+	if err == nil {
+		t.Fatal("SignHash succeeded for an unknown address")
+	}
+	_, err = c.SignHash(keys.addr, testSigHash)
+	if err != nil {
+		t.Fatalf("SignHash after refused request: %v", err)
+	}
+}
+
+func TestSignHashWrongKey(t *testing.T) {
+	keys, _ := newTestKeyStore(t)
+	l := startTestServer(t, keys, testKey)
+	defer l.Close()
+
+	c := NewClient(l.Addr().String(), []byte("wrong key"))
+	defer c.Close()
+
+	_, err := c.SignHash(keys.addr, testSigHash)
+	if err == nil {
+		t.Fatal("SignHash succeeded with the wrong shared key")
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	keys, pubKey := newTestKeyStore(t)
+	s := NewServer(keys, testKey, testParams, nil)
+
+	// Servers created with a key store sign with its private keys.
+	sig, err := s.sign(&request{
+		Address: keys.addr.EncodeAddress(),
+		SigHash: hex.EncodeToString(testSigHash),
+	})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	parsed, err := chainec.Secp256k1.ParseDERSignature(sig)
+	if err != nil {
+		t.Fatalf("sign: invalid signature: %v", err)
+	}
+	if !chainec.Secp256k1.Verify(pubKey, testSigHash, parsed.GetR(),
+		parsed.GetS()) {
+		t.Fatal("sign: signature does not verify")
+	}
+
+	// Addresses without a key in the key store are refused.
+	other, err := dcrutil.NewAddressPubKeyHash(make([]byte, 20), testParams,
+		chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.sign(&request{
+		Address: other.EncodeAddress(),
+		SigHash: hex.EncodeToString(testSigHash),
+	})
+	if err == nil {
+		t.Fatal("sign succeeded for an address without a key")
+	}
+}
+
+func TestMessageMAC(t *testing.T) {
+	challenge := bytes.Repeat([]byte{0x01}, challengeSize)
+	req := request{Seq: 1, Address: "addr", SigHash: "00"}
+	mac := req.mac(testKey, challenge)
+
+	// Changing any authenticated field must change the MAC.
+	changed := []request{
+		{Seq: 2, Address: "addr", SigHash: "00"},
+		{Seq: 1, Address: "addr2", SigHash: "00"},
+		{Seq: 1, Address: "addr", SigHash: "01"},
+	}
+	for i := range changed {
+		if validMAC(mac, changed[i].mac(testKey, challenge)) {
+			t.Errorf("MAC of changed request %d is unchanged", i)
+		}
+	}
+	if validMAC(mac, req.mac(testKey, make([]byte, challengeSize))) {
+		t.Error("MAC does not depend on the challenge")
+	}
+	// A response with the same fields must not be accepted as a request.
+	resp := response{Seq: 1}
+	if validMAC(mac, resp.mac(testKey, challenge)) {
+		t.Error("request and response MACs collide")
+	}
+}
+
+func TestDERSignature(t *testing.T) {
+	privKey, pubKey := chainec.Secp256k1.PrivKeyFromBytes(
+		bytes.Repeat([]byte{0x01}, 32))
+	r, s, err := chainec.Secp256k1.Sign(privKey, testSigHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Signatures with either S value must be converted to the same low S
+	// signature which verifies.
+	n := chainec.Secp256k1.GetN()
+	highS := new(big.Int).Sub(n, s)
+	if s.Cmp(highS) > 0 {
+		s, highS = highS, s
+	}
+	var want []byte
+	for i, sv := range []*big.Int{s, highS} {
+		raw := make([]byte, 64)
+		rb, sb := r.Bytes(), sv.Bytes()
+		copy(raw[32-len(rb):32], rb)
+		copy(raw[64-len(sb):], sb)
+		sig, err := derSignature(raw)
+		if err != nil {
+			t.Fatalf("derSignature %d: %v", i, err)
+		}
+		if i == 0 {
+			want = sig
+		} else if !bytes.Equal(sig, want) {
+			t.Fatalf("derSignature %d: got %x, want low S %x", i, sig,
+				want)
+		}
+		parsed, err := chainec.Secp256k1.ParseDERSignature(sig)
+		if err != nil {
+			t.Fatalf("derSignature %d: invalid signature: %v", i, err)
+		}
+		if !chainec.Secp256k1.Verify(pubKey, testSigHash, parsed.GetR(),
+			parsed.GetS()) {
+			t.Fatalf("derSignature %d: signature does not verify", i)
+		}
+	}
+
+	for _, raw := range [][]byte{make([]byte, 63), make([]byte, 64)} {
+		if _, err := derSignature(raw); err == nil {
+			t.Errorf("derSignature accepted invalid signature %x", raw)
+		}
+	}
+}
+
+func TestParseECPoint(t *testing.T) {
+	_, pubKey := chainec.Secp256k1.PrivKeyFromBytes(
+		bytes.Repeat([]byte{0x01}, 32))
+	want := pubKey.SerializeCompressed()
+	uncompressed := pubKey.SerializeUncompressed()
+
+	// Both the DER octet string and the bare point are accepted.
+	points := [][]byte{
+		append([]byte{0x04, byte(len(uncompressed))}, uncompressed...),
+		uncompressed,
+	}
+	for i, point := range points {
+		got, err := parseECPoint(point)
+		if err != nil {
+			t.Fatalf("parseECPoint %d: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("parseECPoint %d: got %x, want %x", i, got, want)
+		}
+	}
+	if _, err := parseECPoint(uncompressed[1:]); err == nil {
+		t.Error("parseECPoint accepted an invalid point")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletjson"
+)
+
+// spendAlertTimeout is the maximum time spent delivering an unexpected spend
+// alert to the --spendalerturl webhook.
+const spendAlertTimeout = 30 * time.Second
+
+// spendAlert is the JSON body POSTed to the --spendalerturl webhook.  It
+// carries the same fields as the unexpectedspend websocket notification.
+type spendAlert struct {
+	Alert string `json:"alert"`
+	*walletjson.UnexpectedSpendNtfn
+}
+
+// postSpendAlert POSTs an alert describing an unexpected spend of wallet funds
+// to the webhook URL.  Failures are logged but not retried.
+func postSpendAlert(url string, spend *wallet.UnexpectedSpend) {
+	body, err := json.Marshal(&spendAlert{
+		Alert:               walletjson.UnexpectedSpendNtfnMethod,
+		UnexpectedSpendNtfn: newUnexpectedSpendNtfn(spend),
+	})
+	if err != nil {
+		rpcsLog.Errorf("Cannot encode unexpected spend alert: %v", err)
+		return
+	}
+
+	client := http.Client{Timeout: spendAlertTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		rpcsLog.Errorf("Cannot deliver unexpected spend alert for "+
+			"transaction %v: %v", spend.TxHash, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		rpcsLog.Errorf("Unexpected spend alert for transaction %v was "+
+			"rejected: %v", spend.TxHash, resp.Status)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package stakepoolclient implements a client for the API of voting service
+// providers running dcrstakepool.  A wallet registers the public key address
+// it will sign votes with, and receives the multisig redeem script shared
+// with the pool along with the address and percentage of the pool fee which
+// tickets must commit to.
+package stakepoolclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is the maximum time spent on a single API request.
+const DefaultTimeout = 30 * time.Second
+
+// apiPath is the path of version 1 of the API relative to the base URL of the
+// voting service provider.
+const apiPath = "/api/v1/"
+
+// PurchaseInfo describes how tickets must be purchased to be voted by the
+// voting service provider.  Script is the hex encoded 1-of-2 multisig redeem
+// script of the user's and the pool's keys, and TicketAddress is its P2SH
+// address which tickets must vote with.  Tickets must commit at least
+// PoolFees percent of their value to PoolAddress.
+type PurchaseInfo struct {
+	PoolAddress   string  `json:"PoolAddress"`
+	PoolFees      float64 `json:"PoolFees"`
+	Script        string  `json:"Script"`
+	TicketAddress string  `json:"TicketAddress"`
+}
+
+// APIError describes an error response of the API.
+type APIError struct {
+	Code    int
+	Message string
+}
+
+// Error satisfies the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("stake pool API error %d: %s", e.Code, e.Message)
+}
+
+// response is the envelope of every API response.
+type response struct {
+	Status  string          `json:"status"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Client makes authenticated requests to the API of a voting service
+// provider.
+type Client struct {
+	baseURL  string
+	apiToken string
+	http     *http.Client
+}
+
+// New returns a client for the voting service provider at baseURL which
+// authenticates with the API token of the user's account.
+func New(baseURL, apiToken string) *Client {
+	return &Client{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		apiToken: apiToken,
+		http:     &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// do performs an API request and decodes the data of a successful response
+// into result, which may be nil.
+func (c *Client) do(method, path string, form url.Values,
+	result interface{}) error {
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, c.baseURL+apiPath+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("cannot decode %s response (HTTP status %s): %v",
+			path, resp.Status, err)
+	}
+	if r.Status != "success" {
+		return &APIError{Code: r.Code, Message: r.Message}
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(r.Data, result); err != nil {
+		return fmt.Errorf("cannot decode %s response data: %v", path, err)
+	}
+	return nil
+}
+
+// PurchaseInfo returns the purchase information of the user's account.  It
+// is an error if the user has not yet registered a public key address.
+func (c *Client) PurchaseInfo() (*PurchaseInfo, error) {
+	info := new(PurchaseInfo)
+	err := c.do("GET", "getpurchaseinfo", nil, info)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// SetAddress registers the public key address the user will sign votes with,
+// from which the voting service provider creates the multisig redeem script.
+// The address of an account may only be set once.
+func (c *Client) SetAddress(pubKeyAddr string) error {
+	form := url.Values{"UserPubKeyAddr": {pubKeyAddr}}
+	return c.do("POST", "address", form, nil)
+}
@@ -0,0 +1,64 @@
+package stakepoolclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	var registered string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			fmt.Fprint(w, `{"status":"error","code":9,"message":"invalid token"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/address":
+			registered = r.FormValue("UserPubKeyAddr")
+			fmt.Fprint(w, `{"status":"success","code":0,"message":"ok"}`)
+		case "/api/v1/getpurchaseinfo":
+			if registered == "" {
+				fmt.Fprint(w, `{"status":"error","code":5,"message":"no address"}`)
+				return
+			}
+			fmt.Fprint(w, `{"status":"success","code":0,"message":"ok",`+
+				`"data":{"PoolAddress":"pool","PoolFees":7.5,`+
+				`"Script":"51","TicketAddress":"ticket"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL+"/", "token")
+	_, err := c.PurchaseInfo()
+	if e, ok := err.(*APIError); !ok || e.Code != 5 {
+		t.Fatalf("expected API error 5 before registering, got %v", err)
+	}
+	if err := c.SetAddress("pubkeyaddr"); err != nil {
+		t.Fatal(err)
+	}
+	if registered != "pubkeyaddr" {
+		t.Fatalf("registered address %q", registered)
+	}
+	info, err := c.PurchaseInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PurchaseInfo{PoolAddress: "pool", PoolFees: 7.5, Script: "51",
+		TicketAddress: "ticket"}
+	if *info != want {
+		t.Fatalf("purchase info %+v, want %+v", *info, want)
+	}
+
+	_, err = New(srv.URL, "bad").PurchaseInfo()
+	if e, ok := err.(*APIError); !ok || e.Code != 9 {
+		t.Fatalf("expected API error 9 for bad token, got %v", err)
+	}
+	if _, err := New(srv.URL+"/missing", "token").PurchaseInfo(); err == nil {
+		t.Fatal("expected error decoding a non-API response")
+	}
+}
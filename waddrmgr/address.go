@@ -81,6 +81,11 @@ type ManagedPubKeyAddress interface {
 	// ExportPrivKey returns the private key associated with the address
 	// serialized as Wallet Import Format (WIF).
 	ExportPrivKey() (*dcrutil.WIF, error)
+
+	// Index returns the child index of the address in its account branch.
+	// Imported addresses are not derived from the wallet seed and always
+	// return zero.
+	Index() uint32
 }
 
 // ManagedScriptAddress extends ManagedAddress and represents a pay-to-script-hash
@@ -98,6 +103,7 @@ type managedAddress struct {
 	manager          *Manager
 	account          uint32
 	address          *dcrutil.AddressPubKeyHash
+	index            uint32
 	imported         bool
 	internal         bool
 	multisig         bool
@@ -186,6 +192,14 @@ func (a *managedAddress) Internal() bool {
 	return a.internal
 }
 
+// Index returns the child index of the address in its account branch, or
+// zero for imported addresses.
+//
+// This is part of the ManagedPubKeyAddress interface implementation.
+func (a *managedAddress) Index() uint32 {
+	return a.index
+}
+
 // Multisig returns true if the address was created for multisig use.
 //
 // This is part of the ManagedAddress interface implementation.
@@ -249,6 +263,12 @@ func (a *managedAddress) PrivKey() (chainec.PrivateKey, error) {
 		return nil, managerError(ErrLocked, errLocked, nil)
 	}
 
+	// Imported public keys have no private key.
+	if a.imported && len(a.privKeyEncrypted) == 0 {
+		str := fmt.Sprintf("no private key is stored for %s", a.address)
+		return nil, managerError(ErrWatchingOnly, str, nil)
+	}
+
 	// Decrypt the key as needed.  Also, make sure it's a copy since the
 	// private key stored in memory can be cleared at any time.  Otherwise
 	// the returned private key could be invalidated from under the caller.
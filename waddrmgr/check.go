@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package waddrmgr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// ValidateRecord checks that a record of the address manager namespace can be
+// deserialized.  The path holds the keys of the buckets containing the record,
+// relative to the namespace's root bucket, and a nil v indicates a nested
+// bucket.  It has the signature of walletdb.RecordValidator so it can be used
+// to check and salvage the namespace with walletdb.CheckNamespace and
+// walletdb.SalvageNamespace.  Records without a known format are accepted.
+func ValidateRecord(path [][]byte, k, v []byte) error {
+	// Buckets carry no data of their own and the root bucket only contains
+	// buckets.
+	if v == nil || len(path) == 0 {
+		return nil
+	}
+	nested := len(path) > 1
+
+	switch {
+	case bytes.Equal(path[0], mainBucketName):
+		switch {
+		case bytes.Equal(k, mgrVersionName):
+			return checkRecordSize(k, v, 4)
+		case bytes.Equal(k, mgrCreateDateName):
+			return checkRecordSize(k, v, 8)
+		case bytes.Equal(k, watchingOnlyName):
+			return checkRecordSize(k, v, 1)
+		case bytes.Equal(k, lastDefaultAddsrName):
+			return checkRecordSize(k, v, 40)
+		}
+
+	case bytes.Equal(path[0], syncBucketName):
+		switch {
+		case bytes.Equal(k, syncedToName), bytes.Equal(k, startBlockName):
+			return checkRecordSize(k, v, 36)
+		case bytes.Equal(k, birthdayName):
+			return checkRecordSize(k, v, 8)
+		case bytes.Equal(k, recentBlocksName):
+			if len(v) < 8 {
+				return checkRecordSize(k, v, 8)
+			}
+			numHashes := binary.LittleEndian.Uint32(v[4:8])
+			return checkRecordSize(k, v, 8+32*int(numHashes))
+		}
+
+	case bytes.Equal(path[0], metaBucketName):
+		if bytes.Equal(k, lastAccountName) {
+			return checkRecordSize(k, v, 4)
+		}
+
+	case bytes.Equal(path[0], acctBucketName) && !nested:
+		row, err := deserializeAccountRow(k, v)
+		if err != nil {
+			return err
+		}
+		switch row.acctType {
+		case actBIP0044:
+			_, err = deserializeBIP0044AccountRow(k, row)
+			return err
+		}
+		str := fmt.Sprintf("unsupported account type '%d'", row.acctType)
+		return managerError(ErrDatabase, str, nil)
+
+	case bytes.Equal(path[0], addrBucketName) && !nested:
+		row, err := deserializeAddressRow(v)
+		if err != nil {
+			return err
+		}
+		switch row.addrType {
+		case adtChain:
+			_, err = deserializeChainedAddress(row)
+		case adtImport:
+			_, err = deserializeImportedAddress(row)
+		case adtScript:
+			_, err = deserializeScriptAddress(row)
+		default:
+			str := fmt.Sprintf("unsupported address type '%d'",
+				row.addrType)
+			err = managerError(ErrDatabase, str, nil)
+		}
+		return err
+
+	case bytes.Equal(path[0], addrAcctIdxBucketName) && !nested,
+		bytes.Equal(path[0], acctNameIdxBucketName):
+		return checkRecordSize(k, v, 4)
+
+	case bytes.Equal(path[0], acctIDIdxBucketName):
+		if len(v) < 4 {
+			return checkRecordSize(k, v, 4)
+		}
+		nameLen := binary.LittleEndian.Uint32(v[0:4])
+		return checkRecordSize(k, v, 4+int(nameLen))
+	}
+
+	return nil
+}
+
+// checkRecordSize returns an error when the value v stored under the key k is
+// not exactly size bytes long.
+func checkRecordSize(k, v []byte, size int) error {
+	if len(v) != size {
+		str := fmt.Sprintf("malformed value for key %x (expected %d "+
+			"bytes, read %d)", k, size, len(v))
+		return managerError(ErrDatabase, str, nil)
+	}
+	return nil
+}
@@ -207,6 +207,7 @@ var (
 	startBlockName       = []byte("startblock")
 	recentBlocksName     = []byte("recentblocks")
 	lastDefaultAddsrName = []byte("lastaddrs")
+	birthdayName         = []byte("birthday")
 
 	// Account related key names (account bucket).
 	acctNumAcctsName = []byte("numaccts")
@@ -1457,6 +1458,45 @@ func putSyncedTo(tx walletdb.Tx, bs *BlockStamp) error {
 	return nil
 }
 
+// fetchBirthday loads the birthday of the manager from the database.  The zero
+// time is returned if no birthday has been stored.
+func fetchBirthday(tx walletdb.Tx) (time.Time, error) {
+	bucket := tx.RootBucket().Bucket(syncBucketName)
+
+	// The serialized birthday format is:
+	//   <unixtime>
+	//
+	// 8 bytes Unix time in seconds
+	buf := bucket.Get(birthdayName)
+	if buf == nil {
+		return time.Time{}, nil
+	}
+	if len(buf) != 8 {
+		str := "malformed birthday stored in database"
+		return time.Time{}, managerError(ErrDatabase, str, nil)
+	}
+	return time.Unix(int64(binary.LittleEndian.Uint64(buf)), 0), nil
+}
+
+// putBirthday stores the provided birthday to the database.
+func putBirthday(tx walletdb.Tx, birthday time.Time) error {
+	bucket := tx.RootBucket().Bucket(syncBucketName)
+
+	// The serialized birthday format is:
+	//   <unixtime>
+	//
+	// 8 bytes Unix time in seconds
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, uint64(birthday.Unix()))
+
+	err := bucket.Put(birthdayName, buf)
+	if err != nil {
+		str := fmt.Sprintf("failed to store birthday %v", birthday)
+		return managerError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
 // fetchStartBlock loads the start block stamp for the manager from the
 // database.
 func fetchStartBlock(tx walletdb.Tx) (*BlockStamp, error) {
@@ -1743,6 +1783,11 @@ func upgradeManager(namespace walletdb.Namespace, pubPassPhrase []byte, chainPar
 		return managerError(ErrDatabase, str, err)
 	}
 
+	if version < latestMgrVersion {
+		log.Infof("Upgrading address manager database from version %d "+
+			"to %d", version, latestMgrVersion)
+	}
+
 	// NOTE: There are currently no upgrades, but this is provided here as a
 	// template for how to properly do upgrades.  Each function to upgrade
 	// to the next version must include serializing the new version as a
@@ -416,10 +416,48 @@ func (m *Manager) keyToManaged(derivedKey *hdkeychain.ExtendedKey, account,
 	if branch == InternalBranch {
 		ma.internal = true
 	}
+	ma.index = index
 
 	return ma, nil
 }
 
+// DerivationPath describes the BIP0044 derivation path of the key of an
+// address derived from the wallet seed:
+//   m/44'/<coin type>'/<account>'/<branch>/<address index>
+type DerivationPath struct {
+	CoinType uint32
+	Account  uint32
+	Branch   uint32
+	Index    uint32
+}
+
+// String returns the derivation path in the notation of BIP0032, with
+// hardened children marked by an apostrophe.
+func (p DerivationPath) String() string {
+	return fmt.Sprintf("m/44'/%d'/%d'/%d/%d", p.CoinType, p.Account,
+		p.Branch, p.Index)
+}
+
+// DerivationPath returns the derivation path of the key of a managed address.
+// The boolean is false for imported and script addresses, which are not
+// derived from the wallet seed.
+func (m *Manager) DerivationPath(ma ManagedAddress) (DerivationPath, bool) {
+	pka, ok := ma.(ManagedPubKeyAddress)
+	if !ok || pka.Imported() {
+		return DerivationPath{}, false
+	}
+	branch := ExternalBranch
+	if pka.Internal() {
+		branch = InternalBranch
+	}
+	return DerivationPath{
+		CoinType: m.chainParams.HDCoinType,
+		Account:  pka.Account(),
+		Branch:   branch,
+		Index:    pka.Index(),
+	}, true
+}
+
 // deriveKey returns either a public or private derived extended key based on
 // the private flag for the given an account info, branch, and index.
 func (m *Manager) deriveKey(acctInfo *accountInfo, branch, index uint32,
@@ -851,6 +889,45 @@ func (m *Manager) AddrAccount(address dcrutil.Address) (uint32, error) {
 	return account, nil
 }
 
+// AddressDerivation describes where a chained address was derived: the child
+// Index of the Branch of the account's extended public key.
+type AddressDerivation struct {
+	Account uint32
+	Branch  uint32
+	Index   uint32
+}
+
+// AddressDerivation returns the derivation of a chained address of the wallet.
+// A nil derivation is returned for imported and script addresses, which are
+// not derived from an account key, and an error with the ErrAddressNotFound
+// code is returned for addresses which are not known to the address manager.
+func (m *Manager) AddressDerivation(
+	address dcrutil.Address) (*AddressDerivation, error) {
+	if pka, ok := address.(*dcrutil.AddressSecpPubKey); ok {
+		address = pka.AddressPubKeyHash()
+	}
+
+	var rowInterface interface{}
+	err := m.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		rowInterface, err = fetchAddress(tx, address.ScriptAddress())
+		return err
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	row, ok := rowInterface.(*dbChainAddressRow)
+	if !ok {
+		return nil, nil
+	}
+	return &AddressDerivation{
+		Account: row.account,
+		Branch:  row.branch,
+		Index:   row.index,
+	}, nil
+}
+
 // ChangePassphrase changes either the public or private passphrase to the
 // provided value depending on the private flag.  In order to change the private
 // password, the address manager must not be watching-only.  The new passphrase
@@ -1337,6 +1414,92 @@ func (m *Manager) ImportPrivateKey(wif *dcrutil.WIF,
 	return managedAddr, nil
 }
 
+// ImportPublicKey imports a serialized public key into the address manager as
+// a pay-to-pubkey-hash address, without a private key.  Transactions spending
+// outputs to the address must be signed with the private key kept elsewhere,
+// such as in a hardware security module, and PrivKey of the returned address
+// returns an error with the ErrWatchingOnly error code.
+//
+// All imported addresses will be part of the account defined by the
+// ImportedAddrAccount constant.
+//
+// This function will return an error if the public key is invalid or the
+// address already exists.  Any other errors returned are generally unexpected.
+func (m *Manager) ImportPublicKey(serializedPubKey []byte,
+	bs *BlockStamp) (ManagedPubKeyAddress, error) {
+	pubKey, err := chainec.Secp256k1.ParsePubKey(serializedPubKey)
+	if err != nil {
+		str := "invalid public key"
+		return nil, managerError(ErrInvalidKeyType, str, err)
+	}
+	compressed := len(serializedPubKey) ==
+		chainec.Secp256k1.PubKeyBytesLenCompressed()
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	// Prevent duplicates.
+	pubKeyHash := dcrutil.Hash160(serializedPubKey)
+	alreadyExists, err := m.existsAddress(pubKeyHash)
+	if err != nil {
+		return nil, err
+	}
+	if alreadyExists {
+		str := fmt.Sprintf("address for public key %x already exists",
+			serializedPubKey)
+		return nil, managerError(ErrDuplicateAddress, str, nil)
+	}
+
+	// Encrypt public key.
+	encryptedPubKey, err := m.cryptoKeyPub.Encrypt(serializedPubKey)
+	if err != nil {
+		str := fmt.Sprintf("failed to encrypt public key for %x",
+			serializedPubKey)
+		return nil, managerError(ErrCrypto, str, err)
+	}
+
+	// The start block needs to be updated when the newly imported address
+	// is before the current one.
+	updateStartBlock := bs.Height < m.syncState.startBlock.Height
+
+	// Save the new imported address to the db and update start block (if
+	// needed) in a single transaction.
+	err = m.namespace.Update(func(tx walletdb.Tx) error {
+		err := putImportedAddress(tx, pubKeyHash, ImportedAddrAccount,
+			ssNone, encryptedPubKey, nil)
+		if err != nil {
+			return err
+		}
+
+		if updateStartBlock {
+			return putStartBlock(tx, bs)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Now that the database has been updated, update the start block in
+	// memory too if needed.
+	if updateStartBlock {
+		m.syncState.startBlock = *bs
+	}
+
+	managedAddr, err := newManagedAddressWithoutPrivKey(m,
+		ImportedAddrAccount, pubKey, compressed)
+	if err != nil {
+		return nil, err
+	}
+	managedAddr.imported = true
+
+	// Add the new managed address to the cache of recent addresses and
+	// return it.
+	m.addrs[addrKey(managedAddr.Address().ScriptAddress())] = managedAddr
+	return managedAddr, nil
+}
+
 // ImportScript imports a user-provided script into the address manager.  The
 // imported script will act as a pay-to-script-hash address.
 //
@@ -1790,6 +1953,7 @@ func (m *Manager) nextAddresses(account uint32, numAddresses uint32,
 		if internal {
 			managedAddr.internal = true
 		}
+		managedAddr.index = nextIndex - 1
 		info := unlockDeriveInfo{
 			managedAddr: managedAddr,
 			branch:      branchNum,
@@ -2501,6 +2665,11 @@ func loadManager(namespace walletdb.Namespace, pubPassphrase []byte,
 // If a config structure is passed to the function, that configuration
 // will override the defaults.
 //
+// The namespace may belong to a database opened read-only, in which case the
+// manager may be used to inspect addresses and accounts but not to modify
+// them.  Opening a manager which must be upgraded to the latest database
+// version fails for read-only databases.
+//
 // A ManagerError with an error code of ErrNoExist will be returned if the
 // passed manager does not exist in the specified namespace.
 func Open(namespace walletdb.Namespace, pubPassphrase []byte,
@@ -23,6 +23,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainec"
@@ -1872,3 +1873,158 @@ func TestEncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+// TestBirthday ensures the manager birthday is unset for a new manager and is
+// stored by SetBirthday.
+func TestBirthday(t *testing.T) {
+	teardown, mgr := setupManager(t)
+	defer teardown()
+
+	birthday, err := mgr.Birthday()
+	if err != nil {
+		t.Fatalf("Birthday: unexpected error: %v", err)
+	}
+	if !birthday.IsZero() {
+		t.Fatalf("Birthday: got %v for new manager, want zero time",
+			birthday)
+	}
+
+	want := time.Unix(1454954400, 0)
+	if err := mgr.SetBirthday(want); err != nil {
+		t.Fatalf("SetBirthday: unexpected error: %v", err)
+	}
+	birthday, err = mgr.Birthday()
+	if err != nil {
+		t.Fatalf("Birthday: unexpected error: %v", err)
+	}
+	if !birthday.Equal(want) {
+		t.Fatalf("Birthday: got %v, want %v", birthday, want)
+	}
+}
+
+// TestImportPublicKey tests that public keys are imported as addresses of the
+// imported account without private keys.
+func TestImportPublicKey(t *testing.T) {
+	teardown, mgr := setupManager(t)
+	defer teardown()
+
+	_, pubKey := chainec.Secp256k1.PrivKeyFromBytes(
+		[]byte("an imported key of thirty-two b!"))
+	serializedPubKey := pubKey.SerializeCompressed()
+	wantAddr, err := dcrutil.NewAddressPubKeyHash(
+		dcrutil.Hash160(serializedPubKey), &chaincfg.MainNetParams,
+		chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs := &waddrmgr.BlockStamp{Hash: *chaincfg.MainNetParams.GenesisHash}
+	addr, err := mgr.ImportPublicKey(serializedPubKey, bs)
+	if err != nil {
+		t.Fatalf("ImportPublicKey: unexpected error: %v", err)
+	}
+	if addr.Address().EncodeAddress() != wantAddr.EncodeAddress() {
+		t.Fatalf("ImportPublicKey: got address %v, want %v",
+			addr.Address(), wantAddr)
+	}
+	if !addr.Imported() || addr.Account() != waddrmgr.ImportedAddrAccount {
+		t.Fatal("ImportPublicKey: address is not in the imported account")
+	}
+	if addr.ExportPubKey() != hex.EncodeToString(serializedPubKey) {
+		t.Fatalf("ImportPublicKey: got public key %v, want %x",
+			addr.ExportPubKey(), serializedPubKey)
+	}
+
+	_, err = mgr.ImportPublicKey(serializedPubKey, bs)
+	if !waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress) {
+		t.Fatalf("ImportPublicKey: got %v for duplicate key, want "+
+			"ErrDuplicateAddress", err)
+	}
+	_, err = mgr.ImportPublicKey(serializedPubKey[1:], bs)
+	if !waddrmgr.IsError(err, waddrmgr.ErrInvalidKeyType) {
+		t.Fatalf("ImportPublicKey: got %v for invalid key, want "+
+			"ErrInvalidKeyType", err)
+	}
+
+	// The private key is never available, even when unlocked.
+	if err := mgr.Unlock(privPassphrase); err != nil {
+		t.Fatalf("Unlock: unexpected error: %v", err)
+	}
+	_, err = addr.PrivKey()
+	if !waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly) {
+		t.Fatalf("PrivKey: got %v, want ErrWatchingOnly", err)
+	}
+}
+
+// TestDerivationPath tests that the derivation paths of chained addresses
+// describe the account, branch, and index the addresses were derived at, and
+// that imported addresses have no derivation path.
+func TestDerivationPath(t *testing.T) {
+	teardown, mgr := setupManager(t)
+	defer teardown()
+
+	extAddrs, err := mgr.NextExternalAddresses(0, 3)
+	if err != nil {
+		t.Fatalf("NextExternalAddresses: unexpected error: %v", err)
+	}
+	intAddrs, err := mgr.NextInternalAddresses(0, 2)
+	if err != nil {
+		t.Fatalf("NextInternalAddresses: unexpected error: %v", err)
+	}
+
+	coinType := chaincfg.MainNetParams.HDCoinType
+	tests := []struct {
+		addr          waddrmgr.ManagedAddress
+		branch, index uint32
+	}{
+		{extAddrs[0], waddrmgr.ExternalBranch, 0},
+		{extAddrs[2], waddrmgr.ExternalBranch, 2},
+		{intAddrs[1], waddrmgr.InternalBranch, 1},
+	}
+	for _, test := range tests {
+		want := waddrmgr.DerivationPath{
+			CoinType: coinType,
+			Account:  0,
+			Branch:   test.branch,
+			Index:    test.index,
+		}
+		path, ok := mgr.DerivationPath(test.addr)
+		if !ok {
+			t.Errorf("DerivationPath: no path for %v",
+				test.addr.Address())
+			continue
+		}
+		if path != want {
+			t.Errorf("DerivationPath: got %v for %v, want %v", path,
+				test.addr.Address(), want)
+		}
+
+		// Addresses looked up by the manager have the same path.
+		ma, err := mgr.Address(test.addr.Address())
+		if err != nil {
+			t.Fatalf("Address: unexpected error: %v", err)
+		}
+		path, ok = mgr.DerivationPath(ma)
+		if !ok || path != want {
+			t.Errorf("DerivationPath: got %v for looked up address "+
+				"%v, want %v", path, test.addr.Address(), want)
+		}
+	}
+
+	path, _ := mgr.DerivationPath(intAddrs[1])
+	want := fmt.Sprintf("m/44'/%d'/0'/1/1", coinType)
+	if s := path.String(); s != want {
+		t.Errorf("String: got %q, want %q", s, want)
+	}
+
+	_, pubKey := chainec.Secp256k1.PrivKeyFromBytes(
+		[]byte("an imported key of thirty-two b!"))
+	bs := &waddrmgr.BlockStamp{Hash: *chaincfg.MainNetParams.GenesisHash}
+	imported, err := mgr.ImportPublicKey(pubKey.SerializeCompressed(), bs)
+	if err != nil {
+		t.Fatalf("ImportPublicKey: unexpected error: %v", err)
+	}
+	if _, ok := mgr.DerivationPath(imported); ok {
+		t.Errorf("DerivationPath: imported address has a path")
+	}
+}
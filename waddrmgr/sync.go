@@ -19,6 +19,7 @@ package waddrmgr
 
 import (
 	"sync"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrwallet/walletdb"
@@ -242,3 +243,31 @@ func (m *Manager) SyncedTo() BlockStamp {
 
 	return m.syncState.syncedTo
 }
+
+// Birthday returns the time the manager's keys were created, as recorded by
+// SetBirthday, or the zero time if it is not known.
+func (m *Manager) Birthday() (time.Time, error) {
+	var birthday time.Time
+	err := m.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		birthday, err = fetchBirthday(tx)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, maybeConvertDbError(err)
+	}
+	return birthday, nil
+}
+
+// SetBirthday records the time the manager's keys were created.  Blocks before
+// the birthday are not expected to contain transactions involving the keys,
+// so an initial sync may skip them.
+func (m *Manager) SetBirthday(birthday time.Time) error {
+	err := m.namespace.Update(func(tx walletdb.Tx) error {
+		return putBirthday(tx, birthday)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+	return nil
+}
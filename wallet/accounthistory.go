@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// AccountHistoryEntry describes how a mined transaction changed the balance of
+// an account.  Received is the value of the transaction's outputs paying the
+// account, Sent is the value of the account's outputs it spends, and Balance
+// is the account's balance after the transaction.
+type AccountHistoryEntry struct {
+	Height    int32
+	BlockHash chainhash.Hash
+	Time      time.Time
+	Hash      chainhash.Hash
+	TxType    stake.TxType
+	Received  dcrutil.Amount
+	Sent      dcrutil.Amount
+	Balance   dcrutil.Amount
+}
+
+// AccountHistoryCursor is the position of a transaction in an account's
+// history, ordered oldest first, and the account's balance after it.
+type AccountHistoryCursor struct {
+	Height  int32
+	Hash    chainhash.Hash
+	Balance dcrutil.Amount
+}
+
+// String returns the cursor encoded as height:hash:balance, with the balance
+// in atoms.
+func (c *AccountHistoryCursor) String() string {
+	return fmt.Sprintf("%d:%v:%d", c.Height, &c.Hash, int64(c.Balance))
+}
+
+// ParseAccountHistoryCursor decodes a cursor encoded by
+// AccountHistoryCursor.String.
+func ParseAccountHistoryCursor(s string) (*AccountHistoryCursor, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed account history cursor %q", s)
+	}
+	height, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil || height < 0 {
+		return nil, fmt.Errorf("malformed account history cursor %q", s)
+	}
+	hash, err := chainhash.NewHashFromStr(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed account history cursor %q", s)
+	}
+	balance, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed account history cursor %q", s)
+	}
+	return &AccountHistoryCursor{
+		Height:  int32(height),
+		Hash:    *hash,
+		Balance: dcrutil.Amount(balance),
+	}, nil
+}
+
+// accountPays returns whether an output script pays to an address of the
+// account.
+func (w *Wallet) accountPays(account uint32, version uint16,
+	pkScript []byte) bool {
+	_, addrs, _, _ := txscript.ExtractPkScriptAddrs(version, pkScript,
+		w.chainParams)
+	for _, addr := range addrs {
+		if pka, ok := addr.(*dcrutil.AddressSecpPubKey); ok {
+			addr = pka.AddressPubKeyHash()
+		}
+		a, err := w.Manager.AddrAccount(addr)
+		if err == nil && a == account {
+			return true
+		}
+	}
+	return false
+}
+
+// accountHistoryEntry returns the change of the account's balance by a mined
+// transaction, or false if the transaction does not involve the account.
+func (w *Wallet) accountHistoryEntry(account uint32,
+	details *wtxmgr.TxDetails) (*AccountHistoryEntry, bool, error) {
+	e := &AccountHistoryEntry{
+		Height:    details.Block.Height,
+		BlockHash: details.Block.Hash,
+		Time:      details.Block.Time,
+		Hash:      details.Hash,
+		TxType:    details.TxType,
+	}
+	for _, c := range details.Credits {
+		txOut := details.MsgTx.TxOut[c.Index]
+		if w.accountPays(account, txOut.Version, txOut.PkScript) {
+			e.Received += c.Amount
+		}
+	}
+	if len(details.Debits) != 0 {
+		// The previous output scripts of mined transactions are
+		// returned in the order of the debits.
+		pkScripts, err := w.TxStore.PreviousPkScripts(&details.TxRecord,
+			&details.Block.Block)
+		if err != nil {
+			return nil, false, err
+		}
+		for i, pkScript := range pkScripts {
+			if i >= len(details.Debits) {
+				break
+			}
+			if w.accountPays(account, txscript.DefaultScriptVersion,
+				pkScript) {
+				e.Sent += details.Debits[i].Amount
+			}
+		}
+	}
+	if e.Received == 0 && e.Sent == 0 {
+		return nil, false, nil
+	}
+	return e, true, nil
+}
+
+// AccountHistory returns the mined transactions changing the balance of an
+// account, oldest first, with the account's running balance after each.  The
+// history begins after the cursor, or at the first transaction if the cursor
+// is nil, and at most count entries are returned if count is positive.  The
+// returned cursor is the position of the last returned entry, or the passed
+// cursor if no entries were returned, and may be passed to a later call to
+// resume the history, including after new blocks were mined.  Unmined
+// transactions are not included, as their position in the history is not yet
+// known.  ErrInvalidTransactionCursor is returned if the cursor's transaction
+// is no longer mined at its height.
+func (w *Wallet) AccountHistory(account uint32, cursor *AccountHistoryCursor,
+	count int) ([]AccountHistoryEntry, *AccountHistoryCursor, error) {
+	var entries []AccountHistoryEntry
+	next := cursor
+
+	var begin int32
+	var balance dcrutil.Amount
+	var cursorFound bool
+	if cursor != nil {
+		begin = cursor.Height
+		balance = cursor.Balance
+	}
+	err := w.TxStore.RangeTransactions(begin, -1, func(details []wtxmgr.TxDetails) (bool, error) {
+		if details[0].Block.Height == -1 {
+			return true, nil
+		}
+
+		// Transactions at the cursor's height up to and including the
+		// cursor were returned by an earlier call.  The cursor's block
+		// must be the first ranged, as it records the cursor's
+		// transaction.
+		i := 0
+		if cursor != nil && !cursorFound {
+			if details[0].Block.Height != cursor.Height {
+				return true, ErrInvalidTransactionCursor
+			}
+			cursorFound = true
+			for i < len(details) && details[i].Hash != cursor.Hash {
+				i++
+			}
+			if i == len(details) {
+				return true, ErrInvalidTransactionCursor
+			}
+			i++
+		}
+
+		for ; i < len(details); i++ {
+			if count > 0 && len(entries) >= count {
+				return true, nil
+			}
+			e, ok, err := w.accountHistoryEntry(account, &details[i])
+			if err != nil {
+				return true, err
+			}
+			if !ok {
+				continue
+			}
+			balance += e.Received - e.Sent
+			e.Balance = balance
+			entries = append(entries, *e)
+			next = &AccountHistoryCursor{
+				Height:  e.Height,
+				Hash:    e.Hash,
+				Balance: balance,
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil && !cursorFound {
+		return nil, nil, ErrInvalidTransactionCursor
+	}
+	return entries, next, nil
+}
@@ -0,0 +1,34 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+func TestAccountHistoryCursor(t *testing.T) {
+	cursors := []AccountHistoryCursor{
+		{Height: 0, Balance: 0},
+		{Height: 123456, Hash: chainhash.Hash{1, 2, 3}, Balance: 5e8},
+		{Height: 7, Hash: chainhash.Hash{4}, Balance: -1},
+	}
+	for i := range cursors {
+		c := &cursors[i]
+		parsed, err := ParseAccountHistoryCursor(c.String())
+		if err != nil {
+			t.Fatalf("ParseAccountHistoryCursor(%q): %v", c, err)
+		}
+		if *parsed != *c {
+			t.Errorf("cursor %q parsed as %q", c, parsed)
+		}
+	}
+
+	malformed := []string{"", "1:2", "-1:" + chainhash.Hash{}.String() + ":0",
+		"1:nothash:0", "1:" + chainhash.Hash{}.String() + ":x",
+		"1:" + chainhash.Hash{}.String() + ":0:0"}
+	for _, s := range malformed {
+		if _, err := ParseAccountHistoryCursor(s); err == nil {
+			t.Errorf("ParseAccountHistoryCursor accepted %q", s)
+		}
+	}
+}
@@ -173,7 +173,7 @@ func (a *addressPool) GetNewAddress() (dcrutil.Address, error) {
 	// Add the address to the notifications watcher.
 	addrs := make([]dcrutil.Address, 1)
 	addrs[0] = curAddress
-	if err := a.wallet.chainSvr.NotifyReceived(addrs); err != nil {
+	if err := a.wallet.notifyReceived(addrs); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,244 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"sort"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// AddressUsage describes how an address of the wallet was used by the
+// transactions recorded in the transaction store.  FirstUsed is the block
+// time of the first mined transaction paying to the address, or the time the
+// first unmined transaction was received, and is zero for unused addresses.
+// Balance totals the unspent outputs paying to the address regardless of
+// their confirmations.  TxCount counts the transactions paying to or spending
+// from the address, and Reused is set when the address was paid by more than
+// one transaction.
+type AddressUsage struct {
+	Address       dcrutil.Address
+	Account       uint32
+	Internal      bool
+	Imported      bool
+	FirstUsed     time.Time
+	TotalReceived dcrutil.Amount
+	Balance       dcrutil.Amount
+	TxCount       int
+	Reused        bool
+}
+
+// addressIndexEntry records the transactions of a single address in an
+// address index.
+type addressIndexEntry struct {
+	firstUsed     time.Time
+	totalReceived dcrutil.Amount
+	balance       dcrutil.Amount
+	received      map[chainhash.Hash]struct{}
+	txs           map[chainhash.Hash]struct{}
+}
+
+// addressIndex maps the encoded pubkey hash and script hash addresses paid by
+// the wallet's credits to the transactions using them.
+type addressIndex map[string]*addressIndexEntry
+
+// entry returns the index entry of an encoded address, creating it if
+// necessary.
+func (idx addressIndex) entry(addr string) *addressIndexEntry {
+	e, ok := idx[addr]
+	if !ok {
+		e = &addressIndexEntry{
+			received: make(map[chainhash.Hash]struct{}),
+			txs:      make(map[chainhash.Hash]struct{}),
+		}
+		idx[addr] = e
+	}
+	return e
+}
+
+// indexedAddresses returns the encoded addresses an output script pays to.
+// Pay-to-pubkey outputs are indexed by the pubkey hash address of the key, as
+// they are credited to it.
+func indexedAddresses(version uint16, pkScript []byte,
+	params *chaincfg.Params) []string {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(version, pkScript,
+		params)
+	if err != nil {
+		// Non standard script, skip.
+		return nil
+	}
+	encoded := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if pka, ok := addr.(*dcrutil.AddressSecpPubKey); ok {
+			addr = pka.AddressPubKeyHash()
+		}
+		encoded = append(encoded, addr.EncodeAddress())
+	}
+	return encoded
+}
+
+// buildAddressIndex creates an address index of every transaction recorded
+// in the transaction store.  Spent credits are matched with the transactions
+// spending them after all transactions were visited, so debits are indexed
+// regardless of the order transactions are ranged in.
+func (w *Wallet) buildAddressIndex() (addressIndex, error) {
+	// Outpoints are keyed without their tree, which previous outpoints
+	// of inputs do not reliably record.
+	type outPoint struct {
+		hash  chainhash.Hash
+		index uint32
+	}
+	type debit struct {
+		tx       chainhash.Hash
+		outPoint outPoint
+	}
+	idx := make(addressIndex)
+	creditAddrs := make(map[outPoint][]string)
+	var debits []debit
+	err := w.TxStore.RangeTransactions(0, -1, func(details []wtxmgr.TxDetails) (bool, error) {
+		for i := range details {
+			detail := &details[i]
+			t := detail.Received
+			if detail.Block.Height != -1 {
+				t = detail.Block.Time
+			}
+			for _, credit := range detail.Credits {
+				txOut := detail.MsgTx.TxOut[credit.Index]
+				addrs := indexedAddresses(txOut.Version,
+					txOut.PkScript, w.chainParams)
+				for _, addr := range addrs {
+					e := idx.entry(addr)
+					if e.firstUsed.IsZero() || t.Before(e.firstUsed) {
+						e.firstUsed = t
+					}
+					e.totalReceived += credit.Amount
+					if !credit.Spent {
+						e.balance += credit.Amount
+					}
+					e.received[detail.Hash] = struct{}{}
+					e.txs[detail.Hash] = struct{}{}
+				}
+				creditAddrs[outPoint{detail.Hash, credit.Index}] = addrs
+			}
+			for _, d := range detail.Debits {
+				prev := &detail.MsgTx.TxIn[d.Index].PreviousOutPoint
+				debits = append(debits, debit{
+					tx:       detail.Hash,
+					outPoint: outPoint{prev.Hash, prev.Index},
+				})
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range debits {
+		for _, addr := range creditAddrs[d.outPoint] {
+			idx[addr].txs[d.tx] = struct{}{}
+		}
+	}
+	return idx, nil
+}
+
+// AddressUsageReport returns the usage of every address of the wallet, or of
+// the addresses of a single account when account is not nil, built from an
+// address index of the transaction store.  Addresses are ordered by account,
+// external addresses before internal ones, and then by first use, with unused
+// addresses last.  Reused addresses may be identified and retired by their
+// usage.
+func (w *Wallet) AddressUsageReport(account *uint32) ([]AddressUsage, error) {
+	var accounts []uint32
+	if account != nil {
+		accounts = []uint32{*account}
+	} else {
+		err := w.Manager.ForEachAccount(func(account uint32) error {
+			accounts = append(accounts, account)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var usage []AddressUsage
+	for _, account := range accounts {
+		err := w.Manager.ForEachAccountAddress(account,
+			func(maddr waddrmgr.ManagedAddress) error {
+				usage = append(usage, AddressUsage{
+					Address:  maddr.Address(),
+					Account:  maddr.Account(),
+					Internal: maddr.Internal(),
+					Imported: maddr.Imported(),
+				})
+				return nil
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idx, err := w.buildAddressIndex()
+	if err != nil {
+		return nil, err
+	}
+	for i := range usage {
+		u := &usage[i]
+		e, ok := idx[u.Address.EncodeAddress()]
+		if !ok {
+			continue
+		}
+		u.FirstUsed = e.firstUsed
+		u.TotalReceived = e.totalReceived
+		u.Balance = e.balance
+		u.TxCount = len(e.txs)
+		u.Reused = len(e.received) > 1
+	}
+
+	sort.Sort(addressUsageByFirstUse(usage))
+	return usage, nil
+}
+
+// addressUsageByFirstUse sorts address usage by account, branch, and first
+// use, with unused addresses last.
+type addressUsageByFirstUse []AddressUsage
+
+func (s addressUsageByFirstUse) Len() int { return len(s) }
+func (s addressUsageByFirstUse) Less(i, j int) bool {
+	a, b := &s[i], &s[j]
+	if a.Account != b.Account {
+		return a.Account < b.Account
+	}
+	if a.Internal != b.Internal {
+		return !a.Internal
+	}
+	if a.FirstUsed.IsZero() != b.FirstUsed.IsZero() {
+		return !a.FirstUsed.IsZero()
+	}
+	if !a.FirstUsed.Equal(b.FirstUsed) {
+		return a.FirstUsed.Before(b.FirstUsed)
+	}
+	return a.Address.EncodeAddress() < b.Address.EncodeAddress()
+}
+func (s addressUsageByFirstUse) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// AgendaChoice is one of the choices which may be voted for an agenda, and
+// the vote bits encoding it.
+type AgendaChoice struct {
+	ID          string
+	Description string
+	Bits        uint16
+	IsAbstain   bool
+	IsNo        bool
+}
+
+// Agenda is a consensus change which may be voted on by tickets.  Mask
+// selects the vote bits used by the agenda.
+type Agenda struct {
+	ID          string
+	Description string
+	Mask        uint16
+	Choices     []AgendaChoice
+}
+
+// ErrNoAgendas indicates that the network's chain parameters define no
+// consensus agendas which may be voted on.
+var ErrNoAgendas = errors.New("the network defines no consensus agendas " +
+	"which may be voted on")
+
+// agendasForNetwork returns the agendas which may be voted on by tickets of
+// the network described by params.  Agendas are defined by the consensus
+// deployments of the chain parameters, and the chain parameters used by this
+// release define none, so no agendas are returned for any network.
+func agendasForNetwork(params *chaincfg.Params) []Agenda {
+	return nil
+}
+
+// Agendas returns the consensus agendas which may be voted on by the
+// wallet's tickets.  ErrNoAgendas is returned with an empty list when the
+// network defines no agendas.
+func (w *Wallet) Agendas() ([]Agenda, error) {
+	agendas := agendasForNetwork(w.chainParams)
+	if len(agendas) == 0 {
+		return nil, ErrNoAgendas
+	}
+	return agendas, nil
+}
+
+// AgendaChoices returns the agenda vote choices set for a ticket, or the
+// choices set for all tickets of the wallet if the ticket is nil.  Agendas
+// without a choice are voted with the bits of the wallet's vote bits.
+func (w *Wallet) AgendaChoices(ticket *chainhash.Hash) ([]*wstakemgr.VoteChoice,
+	error) {
+	return w.StakeMgr.VoteChoices(ticket)
+}
+
+// SetAgendaChoice sets the choice voted for an agenda by a ticket, or by all
+// tickets of the wallet if the ticket is nil.  Choices set for a ticket take
+// precedence over the wallet's choices.
+func (w *Wallet) SetAgendaChoice(ticket *chainhash.Hash, agendaID,
+	choiceID string) error {
+	agendas, err := w.Agendas()
+	if err != nil {
+		return err
+	}
+	for _, agenda := range agendas {
+		if agenda.ID != agendaID {
+			continue
+		}
+		for _, choice := range agenda.Choices {
+			if choice.ID != choiceID {
+				continue
+			}
+			return w.StakeMgr.SetVoteChoice(ticket, &wstakemgr.VoteChoice{
+				AgendaID: agendaID,
+				ChoiceID: choiceID,
+				Mask:     agenda.Mask,
+				Bits:     choice.Bits,
+			})
+		}
+		return fmt.Errorf("agenda %v has no choice %v", agendaID, choiceID)
+	}
+	return fmt.Errorf("unknown agenda %v", agendaID)
+}
+
+// ClearAgendaChoice removes the choice for an agenda from a ticket, or from
+// the wallet if the ticket is nil.
+func (w *Wallet) ClearAgendaChoice(ticket *chainhash.Hash, agendaID string) error {
+	return w.StakeMgr.ClearVoteChoice(ticket, agendaID)
+}
@@ -0,0 +1,144 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// insertTestTx records a transaction mined at height paying each amount to
+// the address at the same index, and credits all of its outputs.
+func insertTestTx(t *testing.T, w *Wallet, height int32,
+	addrs []dcrutil.Address, amounts []dcrutil.Amount) {
+	msgTx := wire.NewMsgTx()
+	prevHash := chainhash.Hash{byte(height)}
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0,
+		dcrutil.TxTreeRegular), nil))
+	for i, addr := range addrs {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgTx.AddTxOut(wire.NewTxOut(int64(amounts[i]), pkScript))
+	}
+
+	rec, err := wtxmgr.NewTxRecordFromMsgTx(msgTx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &wtxmgr.BlockMeta{
+		Block: wtxmgr.Block{Hash: chainhash.Hash{1, byte(height)},
+			Height: height},
+		Time: time.Now(),
+	}
+	if err := w.TxStore.InsertTx(rec, block); err != nil {
+		t.Fatal(err)
+	}
+	for i := range addrs {
+		if err := w.TxStore.AddCredit(rec, block, uint32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// balanceTestAddrs returns an address of the default account, an address of
+// a second account, and an address not managed by the wallet, along with the
+// number of the second account.
+func balanceTestAddrs(t *testing.T, w *Wallet) ([]dcrutil.Address, uint32) {
+	account, err := w.Manager.NewAccount("second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultAddr, err := w.NewAddress(waddrmgr.DefaultAccountNum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondAddr, err := w.NewAddress(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreignAddr, err := dcrutil.NewAddressPubKeyHash(make([]byte, 20),
+		w.chainParams, chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []dcrutil.Address{defaultAddr, secondAddr, foreignAddr}, account
+}
+
+func TestCalculateAccountBalances(t *testing.T) {
+	w, teardown := newTestWallet(t)
+	defer teardown()
+
+	addrs, account := balanceTestAddrs(t, w)
+	insertTestTx(t, w, 1, addrs, []dcrutil.Amount{1e8, 2e8, 4e8})
+	insertTestTx(t, w, 5, addrs[:1], []dcrutil.Amount{8e8})
+	err := w.Manager.SetSyncedTo(&waddrmgr.BlockStamp{Height: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		confirms int32
+		balances map[uint32]dcrutil.Amount
+	}{
+		{1, map[uint32]dcrutil.Amount{
+			waddrmgr.DefaultAccountNum: 9e8,
+			account:                    2e8,
+		}},
+		{2, map[uint32]dcrutil.Amount{
+			waddrmgr.DefaultAccountNum: 1e8,
+			account:                    2e8,
+		}},
+		{6, map[uint32]dcrutil.Amount{}},
+	}
+	for _, test := range tests {
+		balances, err := w.CalculateAccountBalances(test.confirms,
+			wtxmgr.BFBalanceSpendable)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(balances) != len(test.balances) {
+			t.Errorf("%d confirmations: got balances %v, want %v",
+				test.confirms, balances, test.balances)
+			continue
+		}
+		for acct, want := range test.balances {
+			if balances[acct] != want {
+				t.Errorf("%d confirmations: account %d balance "+
+					"is %v, want %v", test.confirms, acct,
+					balances[acct], want)
+			}
+		}
+	}
+}
+
+func TestCalculateImportedBalance(t *testing.T) {
+	w, teardown := newTestWallet(t)
+	defer teardown()
+
+	addrs, _ := balanceTestAddrs(t, w)
+	insertTestTx(t, w, 1, addrs, []dcrutil.Amount{1e8, 2e8, 4e8})
+	err := w.Manager.SetSyncedTo(&waddrmgr.BlockStamp{Height: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bal, err := w.CalculateImportedBalance(1, wtxmgr.BFBalanceSpendable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bal.Seed != 3e8 {
+		t.Errorf("seed balance is %v, want %v", bal.Seed, dcrutil.Amount(3e8))
+	}
+	if bal.Imported != 4e8 {
+		t.Errorf("imported balance is %v, want %v", bal.Imported,
+			dcrutil.Amount(4e8))
+	}
+}
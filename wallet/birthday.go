@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"time"
+
+	"github.com/decred/dcrd/wire"
+)
+
+var (
+	// ErrBirthdayUnknown describes an error where the wallet birthday was
+	// moved but no birthday is recorded.  Such wallets rescan the whole
+	// block chain, so no blocks are uncovered by an earlier birthday.
+	ErrBirthdayUnknown = errors.New("wallet birthday is not set")
+
+	// ErrBirthdayNotEarlier describes an error where the wallet birthday was
+	// moved to a time which is not before the current birthday.
+	ErrBirthdayNotEarlier = errors.New("new birthday is not before the " +
+		"current wallet birthday")
+)
+
+// MoveBirthday moves the wallet birthday earlier to birthday and rescans the
+// blocks which were skipped because of the previous birthday for
+// transactions involving the wallet's active addresses.  Outputs found by
+// this rescan are then watched through the blocks the wallet is already
+// synced through, so later spends of them are recorded.  Neither rescan
+// changes the wallet's sync state or its existing history.
+//
+// The new birthday is only recorded once the rescans succeed.  The rescans
+// are wallet rescans: their progress is passed to listeners of
+// ListenRescanWalletProgress, they may be cancelled by CancelRescanWallet,
+// and they may not run at the same time as RescanWallet.
+func (w *Wallet) MoveBirthday(birthday time.Time) (*RescanWalletProgress, error) {
+	oldBirthday, err := w.Manager.Birthday()
+	if err != nil {
+		return nil, err
+	}
+	if oldBirthday.IsZero() {
+		return nil, ErrBirthdayUnknown
+	}
+	if !birthday.Before(oldBirthday) {
+		return nil, ErrBirthdayNotEarlier
+	}
+
+	cancel, err := w.beginRescanWallet()
+	if err != nil {
+		return nil, err
+	}
+	defer w.endRescanWallet()
+
+	// The initial sync of the wallet began at the first block at or after
+	// the old birthday less birthdayMargin (see syncToBirthday), so the
+	// uncovered blocks end before it.  Blocks the wallet has not synced
+	// through yet will be scanned by the normal sync.
+	_, bestHeight, err := w.chainSvr.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+	startHeight, err := w.heightForTime(birthday.Add(-birthdayMargin),
+		bestHeight)
+	if err != nil {
+		return nil, err
+	}
+	oldStartHeight, err := w.heightForTime(oldBirthday.Add(-birthdayMargin),
+		bestHeight)
+	if err != nil {
+		return nil, err
+	}
+	syncedTo := w.Manager.SyncedTo()
+	endHeight := oldStartHeight - 1
+	if endHeight > syncedTo.Height {
+		endHeight = syncedTo.Height
+	}
+
+	progress := &RescanWalletProgress{
+		StartHeight: startHeight,
+		Height:      startHeight - 1,
+	}
+	if endHeight > 0 && startHeight <= endHeight {
+		log.Infof("Started rescan of blocks %d through %d uncovered by "+
+			"wallet birthday %v", startHeight, endHeight, birthday)
+		progress.Err = w.rescanUncovered(progress, endHeight,
+			syncedTo.Height, cancel)
+	}
+	progress, err = w.finishRescanWallet(progress)
+	if err != nil {
+		return progress, err
+	}
+
+	err = w.Manager.SetBirthday(birthday)
+	if err != nil {
+		return progress, err
+	}
+	log.Infof("Moved wallet birthday from %v to %v", oldBirthday, birthday)
+	return progress, nil
+}
+
+// rescanUncovered performs the rescans of MoveBirthday.  The blocks after
+// progress.Height through endHeight are rescanned for all active addresses
+// and unspent outputs, and then the blocks after endHeight through
+// syncedHeight are rescanned for the unspent outputs which were found.
+func (w *Wallet) rescanUncovered(progress *RescanWalletProgress, endHeight,
+	syncedHeight int32, cancel <-chan struct{}) error {
+	addrs, unspent, err := w.rescanWalletData()
+	if err != nil {
+		return err
+	}
+	err = w.rescanChunks(progress, endHeight, addrs, unspent, true, cancel)
+	if err != nil {
+		return err
+	}
+	if endHeight >= syncedHeight {
+		return nil
+	}
+
+	known := make(map[wire.OutPoint]struct{}, len(unspent))
+	for _, op := range unspent {
+		known[*op] = struct{}{}
+	}
+	_, unspent, err = w.rescanWalletData()
+	if err != nil {
+		return err
+	}
+	var found []*wire.OutPoint
+	for _, op := range unspent {
+		if _, ok := known[*op]; !ok {
+			found = append(found, op)
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	log.Infof("Watching %d %s through height %d", len(found),
+		pickNoun(len(found), "uncovered output", "uncovered outputs"),
+		syncedHeight)
+	return w.rescanChunks(progress, syncedHeight, nil, found, true, cancel)
+}
@@ -0,0 +1,393 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// LotMethod selects the lots of coins a disposal is matched with when
+// computing realized gains.
+type LotMethod uint8
+
+// Lot matching methods.  LotFIFO and LotLIFO match disposals with the oldest
+// or newest lots held by the wallet.  LotSpecific identifies the lots by the
+// coins the disposing transaction actually spends, in input order, carrying
+// the lots of any coins which are not disposed of to the transaction's change.
+const (
+	LotFIFO LotMethod = iota
+	LotLIFO
+	LotSpecific
+)
+
+var lotMethodStrings = []string{
+	LotFIFO:     "fifo",
+	LotLIFO:     "lifo",
+	LotSpecific: "specific",
+}
+
+// String returns the name of the lot method.
+func (m LotMethod) String() string {
+	if int(m) < len(lotMethodStrings) {
+		return lotMethodStrings[m]
+	}
+	return "unknown"
+}
+
+// ParseLotMethod returns the lot method with a name returned by String.
+func ParseLotMethod(s string) (LotMethod, error) {
+	for m, name := range lotMethodStrings {
+		if s == name {
+			return LotMethod(m), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown lot method %q", s)
+}
+
+// lot is an amount of coins acquired at once, and its cost basis.  Lots are
+// split when only a part of them is disposed of or carried to an output.
+type lot struct {
+	acquired  time.Time
+	tx        chainhash.Hash
+	amount    dcrutil.Amount
+	cost      float64
+	costKnown bool
+}
+
+// split removes amount from the lot, returning the removed part with its
+// share of the cost basis.
+func (l *lot) split(amount dcrutil.Amount) lot {
+	part := *l
+	part.amount = amount
+	part.cost = l.cost * float64(amount) / float64(l.amount)
+	l.amount -= amount
+	l.cost -= part.cost
+	return part
+}
+
+// takeLots removes amount from the lots, from the front unless fromBack is
+// set, and returns the removed lots and the lots left.  An unknown lot is
+// returned for the part of the amount exceeding the total of the lots, such
+// as when the wallet does not record how the coins were acquired.
+func takeLots(lots []lot, amount dcrutil.Amount, fromBack bool) (taken, left []lot) {
+	for amount > 0 && len(lots) != 0 {
+		i := 0
+		if fromBack {
+			i = len(lots) - 1
+		}
+		l := &lots[i]
+		if l.amount > amount {
+			taken = append(taken, l.split(amount))
+			return taken, lots
+		}
+		taken = append(taken, *l)
+		amount -= l.amount
+		if fromBack {
+			lots = lots[:i]
+		} else {
+			lots = lots[1:]
+		}
+	}
+	if amount > 0 {
+		taken = append(taken, lot{amount: amount})
+	}
+	return taken, lots
+}
+
+// RealizedGain is the gain realized by disposing of (part of) a lot.
+// ProceedsKnown and CostKnown are false when no fiat rate was recorded for
+// the disposing or acquiring transaction, and Gain is only meaningful when
+// both are known.  Acquired is zero, and AcquisitionTx the zero hash, when
+// the wallet does not record how the coins were acquired.
+type RealizedGain struct {
+	Disposed      time.Time
+	DisposalTx    chainhash.Hash
+	Acquired      time.Time
+	AcquisitionTx chainhash.Hash
+	Amount        dcrutil.Amount
+	Proceeds      float64
+	ProceedsKnown bool
+	CostBasis     float64
+	CostKnown     bool
+	Gain          float64
+}
+
+// CapitalGainsReport is the report of the gains realized by the wallet during
+// a tax year.  The totals only include gains whose proceeds and cost basis are
+// both known, and Unknown counts the gains excluded from them.
+type CapitalGainsReport struct {
+	Year          int
+	Method        LotMethod
+	Currency      string
+	Gains         []RealizedGain
+	TotalProceeds float64
+	TotalCost     float64
+	TotalGain     float64
+	Unknown       int
+}
+
+// capitalGains tracks the lots held by the wallet while the transaction
+// history is replayed.
+type capitalGains struct {
+	method   LotMethod
+	currency string
+	start    time.Time
+	end      time.Time
+	report   *CapitalGainsReport
+
+	// Lots held by the wallet, oldest first, for LotFIFO and LotLIFO.
+	pool []lot
+
+	// Lots of each unspent wallet output for LotSpecific.
+	outputs map[wire.OutPoint][]lot
+}
+
+// rate returns the fiat rate of a transaction in the report currency.  The
+// currency of the first recorded rate is used as the report currency.
+func (c *capitalGains) rate(details *wtxmgr.TxDetails) (float64, bool) {
+	r := details.FiatRate
+	if r == nil {
+		return 0, false
+	}
+	if c.currency == "" {
+		c.currency = r.Currency
+	}
+	if r.Currency != c.currency {
+		return 0, false
+	}
+	return r.Rate, true
+}
+
+// addTx replays a mined transaction.  Transactions increasing the wallet's
+// coins acquire a lot of the increase, valued at the transaction's fiat rate.
+// Transactions decreasing the wallet's coins, including by the fee of
+// transfers between the wallet's own outputs, dispose of the decrease.
+func (c *capitalGains) addTx(details *wtxmgr.TxDetails) {
+	var debits, credits dcrutil.Amount
+	for _, d := range details.Debits {
+		debits += d.Amount
+	}
+	for _, cr := range details.Credits {
+		credits += cr.Amount
+	}
+	if debits == 0 && credits == 0 {
+		return
+	}
+	rate, rateKnown := c.rate(details)
+	t := details.Block.Time
+
+	var acquired []lot
+	if net := credits - debits; net > 0 {
+		acquired = append(acquired, lot{
+			acquired:  t,
+			tx:        details.Hash,
+			amount:    net,
+			cost:      net.ToCoin() * rate,
+			costKnown: rateKnown,
+		})
+	}
+
+	// Gather the lots of the spent coins for the specific lot method.
+	var spent []lot
+	if c.method == LotSpecific {
+		for _, d := range details.Debits {
+			op := details.MsgTx.TxIn[d.Index].PreviousOutPoint
+			op.Tree = 0
+			lots, ok := c.outputs[op]
+			if !ok {
+				lots = []lot{{amount: d.Amount}}
+			}
+			delete(c.outputs, op)
+			spent = append(spent, lots...)
+		}
+	}
+
+	var disposed []lot
+	if disposal := debits - credits; disposal > 0 {
+		switch c.method {
+		case LotSpecific:
+			disposed, spent = takeLots(spent, disposal, false)
+		default:
+			disposed, c.pool = takeLots(c.pool, disposal,
+				c.method == LotLIFO)
+		}
+	}
+
+	switch c.method {
+	case LotSpecific:
+		// Carry the lots of the coins which were not disposed of, and
+		// any acquired lot, to the credited outputs in output order.
+		carried := append(spent, acquired...)
+		for _, cr := range details.Credits {
+			var lots []lot
+			lots, carried = takeLots(carried, cr.Amount, false)
+			op := wire.OutPoint{Hash: details.Hash, Index: cr.Index}
+			c.outputs[op] = lots
+		}
+	default:
+		c.pool = append(c.pool, acquired...)
+	}
+
+	if t.Before(c.start) || !t.Before(c.end) {
+		return
+	}
+	for i := range disposed {
+		l := &disposed[i]
+		g := RealizedGain{
+			Disposed:      t,
+			DisposalTx:    details.Hash,
+			Acquired:      l.acquired,
+			AcquisitionTx: l.tx,
+			Amount:        l.amount,
+			Proceeds:      l.amount.ToCoin() * rate,
+			ProceedsKnown: rateKnown,
+			CostBasis:     l.cost,
+			CostKnown:     l.costKnown,
+		}
+		r := c.report
+		if g.ProceedsKnown && g.CostKnown {
+			g.Gain = g.Proceeds - g.CostBasis
+			r.TotalProceeds += g.Proceeds
+			r.TotalCost += g.CostBasis
+			r.TotalGain += g.Gain
+		} else {
+			r.Unknown++
+		}
+		r.Gains = append(r.Gains, g)
+	}
+}
+
+// orderBlockTxs orders the transactions of a block so that transactions
+// spending outputs of other transactions of the block follow them.
+func orderBlockTxs(details []wtxmgr.TxDetails) []*wtxmgr.TxDetails {
+	inBlock := make(map[chainhash.Hash]*wtxmgr.TxDetails, len(details))
+	for i := range details {
+		inBlock[details[i].Hash] = &details[i]
+	}
+	ordered := make([]*wtxmgr.TxDetails, 0, len(details))
+	added := make(map[chainhash.Hash]bool, len(details))
+	var add func(d *wtxmgr.TxDetails)
+	add = func(d *wtxmgr.TxDetails) {
+		if added[d.Hash] {
+			return
+		}
+		added[d.Hash] = true
+		for _, in := range d.MsgTx.TxIn {
+			parent, ok := inBlock[in.PreviousOutPoint.Hash]
+			if ok {
+				add(parent)
+			}
+		}
+		ordered = append(ordered, d)
+	}
+	for i := range details {
+		add(&details[i])
+	}
+	return ordered
+}
+
+// CapitalGainsReport replays the wallet's mined transactions, matching the
+// coins disposed of with the lots they were acquired in using the lot method,
+// and reports the gains realized during the calendar year in UTC.  Lots are
+// valued with the fiat rates recorded when the transactions were first seen,
+// and gains of transactions without a recorded rate are reported as unknown.
+// Coins acquired by votes are the vote reward, and the fees paid by
+// transactions spending wallet funds are disposed of at their value.
+func (w *Wallet) CapitalGainsReport(year int, method LotMethod) (*CapitalGainsReport, error) {
+	if method > LotSpecific {
+		return nil, fmt.Errorf("unknown lot method %d", method)
+	}
+	report := &CapitalGainsReport{Year: year, Method: method}
+	c := &capitalGains{
+		method:  method,
+		start:   time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		end:     time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC),
+		report:  report,
+		outputs: make(map[wire.OutPoint][]lot),
+	}
+	err := w.TxStore.RangeTransactions(0, -1, func(details []wtxmgr.TxDetails) (bool, error) {
+		if details[0].Block.Height == -1 {
+			return true, nil
+		}
+		if !details[0].Block.Time.Before(c.end) {
+			return true, nil
+		}
+		for _, detail := range orderBlockTxs(details) {
+			c.addTx(detail)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Currency = c.currency
+	return report, nil
+}
+
+// formatFiat formats a fiat amount for the CSV report, or returns the empty
+// string if the amount is unknown.
+func formatFiat(v float64, known bool) string {
+	if !known {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// WriteCSV writes the realized gains of the report as CSV with a header row.
+// Times are written in RFC 3339 format in UTC, and unknown values are empty.
+func (r *CapitalGainsReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"disposed", "disposaltxid", "acquired",
+		"acquisitiontxid", "amount", "proceeds", "costbasis", "gain",
+		"currency"})
+	if err != nil {
+		return err
+	}
+	for i := range r.Gains {
+		g := &r.Gains[i]
+		var acquired, acquisitionTx string
+		if !g.Acquired.IsZero() {
+			acquired = g.Acquired.UTC().Format(time.RFC3339)
+			acquisitionTx = g.AcquisitionTx.String()
+		}
+		err := cw.Write([]string{
+			g.Disposed.UTC().Format(time.RFC3339),
+			g.DisposalTx.String(),
+			acquired,
+			acquisitionTx,
+			strconv.FormatFloat(g.Amount.ToCoin(), 'f', -1, 64),
+			formatFiat(g.Proceeds, g.ProceedsKnown),
+			formatFiat(g.CostBasis, g.CostKnown),
+			formatFiat(g.Gain, g.ProceedsKnown && g.CostKnown),
+			r.Currency,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
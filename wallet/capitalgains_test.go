@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// gainsTestTx creates the details of a mined transaction spending the
+// outpoints and crediting the wallet with amounts in coins by output index.
+func gainsTestTx(hash byte, t time.Time, rate float64, spends []wire.OutPoint,
+	debits []dcrutil.Amount, credits map[uint32]dcrutil.Amount) *wtxmgr.TxDetails {
+	d := &wtxmgr.TxDetails{
+		TxRecord: wtxmgr.TxRecord{Hash: chainhash.Hash{hash}},
+		Block:    wtxmgr.BlockMeta{Time: t},
+		FiatRate: &wtxmgr.FiatRate{Currency: "USD", Rate: rate, Time: t},
+	}
+	for i := range spends {
+		d.MsgTx.TxIn = append(d.MsgTx.TxIn, &wire.TxIn{
+			PreviousOutPoint: spends[i],
+		})
+		d.Debits = append(d.Debits, wtxmgr.DebitRecord{
+			Amount: debits[i],
+			Index:  uint32(i),
+		})
+	}
+	for i := uint32(0); i < 2; i++ {
+		if amount, ok := credits[i]; ok {
+			d.Credits = append(d.Credits, wtxmgr.CreditRecord{
+				Index:  i,
+				Amount: amount,
+			})
+		}
+	}
+	return d
+}
+
+func TestCapitalGains(t *testing.T) {
+	date := func(year int, month time.Month) time.Time {
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	// A and B acquire 10 coins each at 10 and 20.  C spends B, paying 6
+	// coins out of the wallet at 30 and returning 4 coins as change.  D
+	// spends A and the change of C, paying all 14 coins out at 40 in the
+	// following year.
+	a := gainsTestTx(1, date(2016, 1), 10, nil, nil,
+		map[uint32]dcrutil.Amount{0: 10e8})
+	b := gainsTestTx(2, date(2016, 2), 20, nil, nil,
+		map[uint32]dcrutil.Amount{0: 10e8})
+	c := gainsTestTx(3, date(2016, 3), 30,
+		[]wire.OutPoint{{Hash: b.Hash}},
+		[]dcrutil.Amount{10e8},
+		map[uint32]dcrutil.Amount{1: 4e8})
+	d := gainsTestTx(4, date(2017, 1), 40,
+		[]wire.OutPoint{{Hash: a.Hash}, {Hash: c.Hash, Index: 1}},
+		[]dcrutil.Amount{10e8, 4e8}, nil)
+	txs := []*wtxmgr.TxDetails{a, b, c, d}
+
+	tests := []struct {
+		method LotMethod
+		year   int
+		gain   float64
+		cost   float64
+	}{
+		{LotFIFO, 2016, 6*30 - 6*10, 6 * 10},
+		{LotLIFO, 2016, 6*30 - 6*20, 6 * 20},
+		{LotSpecific, 2016, 6*30 - 6*20, 6 * 20},
+		{LotFIFO, 2017, 14*40 - (4*10 + 10*20), 4*10 + 10*20},
+		{LotLIFO, 2017, 14*40 - (4*20 + 10*10), 4*20 + 10*10},
+		{LotSpecific, 2017, 14*40 - (10*10 + 4*20), 10*10 + 4*20},
+		{LotFIFO, 2015, 0, 0},
+	}
+	for _, test := range tests {
+		report := &CapitalGainsReport{Year: test.year, Method: test.method}
+		c := &capitalGains{
+			method:  test.method,
+			start:   date(test.year, 1),
+			end:     date(test.year+1, 1),
+			report:  report,
+			outputs: make(map[wire.OutPoint][]lot),
+		}
+		for _, tx := range txs {
+			c.addTx(tx)
+		}
+		if report.TotalGain != test.gain || report.TotalCost != test.cost ||
+			report.Unknown != 0 {
+			t.Errorf("%v %d: got gain %v cost %v (%d unknown), want "+
+				"gain %v cost %v", test.method, test.year,
+				report.TotalGain, report.TotalCost, report.Unknown,
+				test.gain, test.cost)
+		}
+	}
+
+	// Disposing of coins the wallet has no record of acquiring reports
+	// the gain as unknown.
+	report := &CapitalGainsReport{Year: 2017}
+	cg := &capitalGains{
+		start:   date(2017, 1),
+		end:     date(2018, 1),
+		report:  report,
+		outputs: make(map[wire.OutPoint][]lot),
+	}
+	cg.currency = "USD"
+	cg.addTx(d)
+	if report.Unknown != 1 || len(report.Gains) != 1 ||
+		report.Gains[0].CostKnown || report.TotalGain != 0 {
+		t.Fatalf("Unexpected report of unknown lot: %+v", report)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], ",14,560.00,,,USD") {
+		t.Fatalf("Unexpected CSV report:\n%s", buf.String())
+	}
+}
+
+func TestParseLotMethod(t *testing.T) {
+	for _, m := range []LotMethod{LotFIFO, LotLIFO, LotSpecific} {
+		parsed, err := ParseLotMethod(m.String())
+		if err != nil || parsed != m {
+			t.Errorf("ParseLotMethod(%q) = %v, %v", m.String(),
+				parsed, err)
+		}
+	}
+	if _, err := ParseLotMethod("average"); err == nil {
+		t.Error("ParseLotMethod accepted an unknown method")
+	}
+}
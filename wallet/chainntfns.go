@@ -26,6 +26,7 @@ import (
 	"github.com/decred/dcrutil"
 	"github.com/decred/dcrwallet/chain"
 	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wstakemgr"
 	"github.com/decred/dcrwallet/wtxmgr"
 )
 
@@ -79,85 +80,6 @@ func (w *Wallet) handleChainNotifications() {
 	w.wg.Done()
 }
 
-// handleTicketPurchases autopurchases stake tickets for the wallet
-// if stake mining is enabled.
-func (w *Wallet) handleTicketPurchases() {
-	purchased := 0
-	attempts := 0
-	maxTickets := int(w.chainParams.MaxFreshStakePerBlock)
-	maxAttempts := 20 // Sane-ish?
-
-	sdiff := dcrutil.Amount(w.GetStakeDifficulty().StakeDifficulty)
-	maxToPay := w.GetTicketMaxPrice()
-	if sdiff > maxToPay {
-		return
-	}
-
-ticketPurchaseLoop:
-	for {
-		if purchased >= maxTickets {
-			break
-		}
-
-		if attempts >= maxAttempts {
-			break
-		}
-
-		// eligible may also be the tx hash as a string; however, for the
-		// too many inputs error, the list of eligible Credits from
-		// wtxmgr is instead returned. We can use this to compress the
-		// amount to the ticket price, thus avoiding more costly db
-		// lookups.
-		eligible, err := w.CreatePurchaseTicket(w.BalanceToMaintain, -1,
-			0, nil)
-		if err != nil {
-			switch {
-			case err == ErrSStxNotEnoughFunds:
-				break ticketPurchaseLoop
-			case err == ErrSStxInputOverflow:
-				switch v := eligible.(type) {
-				case string:
-					log.Errorf("Was given a string instead of eligible credits!")
-					continue
-				case []wtxmgr.Credit:
-					err := w.compressEligible(v)
-					if err != nil {
-						log.Errorf("Failed to compress outputs: %v", err.Error())
-					}
-					attempts++
-					continue
-				}
-			case waddrmgr.IsError(err, waddrmgr.ErrLocked):
-				log.Warnf("Ticket purchase for stake mining is enabled, " +
-					"but tickets could not be purchased because the " +
-					"wallet is currently locked!")
-				break ticketPurchaseLoop
-			case err == ErrTicketPriceNotSet:
-				// TODO make this trigger a request to the daemon
-				// through chainsvr to get the latest ticket price.
-				// The current behaviour simply waits for a block
-				// to be connected to get the stake difficulty.
-				// Probably need a retrigger for the ntfn like
-				// "rebroadcaststakediff"
-				log.Warnf("Ticket prices not yet established because the " +
-					"client was recently connected; aborting ticket purchase " +
-					"attempts")
-				break ticketPurchaseLoop
-			case err == ErrClientPurchaseTicket:
-				log.Warnf("A chainSvr error was returned attempting to " +
-					"purchase a ticket; ticket purchases aborted.")
-				break ticketPurchaseLoop
-			default:
-				log.Errorf("PurchaseTicket error returned: %v", err)
-			}
-		} else {
-			purchased++
-		}
-
-		attempts++
-	}
-}
-
 // connectBlock handles a chain server notification by marking a wallet
 // that's currently in-sync with the chain server as being synced up to
 // the passed block.
@@ -175,6 +97,7 @@ func (w *Wallet) connectBlock(b wtxmgr.BlockMeta) {
 			"connect block for hash %v (height %d): %v", b.Hash,
 			b.Height, err)
 	}
+	w.attachSideChainBlock(b)
 	w.notifyConnectedBlock(b)
 	log.Infof("Connecting block %v, height %v", bs.Hash, bs.Height)
 
@@ -192,14 +115,29 @@ func (w *Wallet) connectBlock(b wtxmgr.BlockMeta) {
 		}
 	}
 
+	expired, err := w.StakeMgr.ConnectTicketStatuses(bs.Height)
+	if err != nil {
+		log.Errorf("Failed to update ticket statuses at height %v: %v",
+			bs.Height, err)
+	}
+	w.notifyTicketsExpired(expired, bs.Height)
+	if !isReorganizing {
+		w.checkTicketWarnings(bs.Height)
+	}
+
 	if bs.Height >= int32(w.chainParams.CoinbaseMaturity) &&
-		w.StakeMiningEnabled &&
+		w.StakeMiningEnabled && !w.votingOnly &&
+		!isReorganizing {
+		w.handleTicketPurchases(&bs.Hash, bs.Height)
+	}
+
+	if bs.Height > int32(w.chainParams.StakeValidationHeight) &&
 		!isReorganizing {
-		w.handleTicketPurchases()
+		w.revokeExpiredTickets(&bs.Hash, bs.Height)
 	}
 
 	// Insert the block if we haven't already through a relevant tx.
-	err := w.TxStore.InsertBlock(&b)
+	err = w.TxStore.InsertBlock(&b)
 	if err != nil {
 		log.Errorf("Couldn't insert block %v into database: %v",
 			b.Hash, err)
@@ -267,6 +205,41 @@ func (w *Wallet) connectBlock(b wtxmgr.BlockMeta) {
 	}
 }
 
+// attachSideChainBlock inserts the cached wallet transactions of a block which
+// was reorganized out of the main chain and has now been connected again, so
+// they are mined immediately instead of after the chain server notifies them.
+// Cached blocks too old to be reorganized back are pruned.
+func (w *Wallet) attachSideChainBlock(b wtxmgr.BlockMeta) {
+	err := w.TxStore.PruneSideChainBlocks(b.Height -
+		wtxmgr.MaxSideChainDepth)
+	if err != nil {
+		log.Errorf("Failed to prune side chain blocks: %v", err)
+	}
+
+	cached, recs, err := w.TxStore.SideChainBlock(&b.Hash)
+	if err != nil {
+		log.Errorf("Failed to read side chain block %v: %v", b.Hash, err)
+		return
+	}
+	if cached == nil {
+		return
+	}
+	log.Infof("Attaching %d transaction(s) of side chain block %v from "+
+		"cache", len(recs), b.Hash)
+	for _, rec := range recs {
+		err := w.addRelevantTx(rec, &b)
+		if err != nil {
+			log.Errorf("Cannot attach cached transaction %v: %v",
+				rec.Hash, err)
+		}
+	}
+	err = w.TxStore.RemoveSideChainBlock(&b.Hash)
+	if err != nil {
+		log.Errorf("Failed to remove side chain block %v: %v", b.Hash,
+			err)
+	}
+}
+
 // disconnectBlock handles a chain server reorganize by rolling back all
 // block history from the reorged block for a wallet in-sync with the chain
 // server.
@@ -306,6 +279,11 @@ func (w *Wallet) disconnectBlock(b wtxmgr.BlockMeta) error {
 		}
 	}
 
+	err := w.StakeMgr.RollbackTicketStatuses(b.Height)
+	if err != nil {
+		return err
+	}
+
 	w.notifyDisconnectedBlock(b)
 	w.notifyBalances(b.Height-1, wtxmgr.BFBalanceSpendable)
 
@@ -390,12 +368,33 @@ func (w *Wallet) addRelevantTx(rec *wtxmgr.TxRecord,
 			}
 		}
 
+		// In stake pool mode, tickets which delegate voting rights to
+		// the wallet are only voted if they pay the pool fee.
+		if insert && w.stakePoolEnabled {
+			insert = w.evaluateStakePoolTicket(tx, block)
+		}
+
+		// Split tickets which the wallet contributed to but does not
+		// vote are tracked through their lifecycle as well.
+		tracked := insert
 		if insert {
 			err := w.StakeMgr.InsertSStx(tx)
 			if err != nil {
 				log.Errorf("Failed to insert SStx %v"+
 					"into the stake store.", tx.Sha())
 			}
+		} else {
+			tracked = w.recordSplitTicket(tx)
+		}
+
+		if tracked {
+			status := wstakemgr.TicketStatusUnmined
+			height := w.Manager.SyncedTo().Height
+			if block != nil {
+				status, height = wstakemgr.TicketStatusImmature,
+					block.Height
+			}
+			w.updateTicketStatus(tx.Sha(), status, height)
 		}
 	}
 
@@ -410,6 +409,13 @@ func (w *Wallet) addRelevantTx(rec *wtxmgr.TxRecord,
 					tx.Sha(),
 					w.VoteBits,
 					&txInHash)
+				w.ticketVoted(&txInHash, tx, block.Height)
+				if w.stakePoolEnabled {
+					w.updateStakePoolTicket(&txInHash,
+						wstakemgr.TSVoted, block, tx.Sha())
+				}
+			} else if w.isSplitTicket(&txInHash) {
+				w.ticketVoted(&txInHash, tx, block.Height)
 			}
 		} else {
 			// If there's no associated block, it's potentially a
@@ -430,14 +436,41 @@ func (w *Wallet) addRelevantTx(rec *wtxmgr.TxRecord,
 					int64(block.Height),
 					tx.Sha(),
 					&txInHash)
+				w.ticketRevoked(&txInHash, tx, block.Height)
+				if w.stakePoolEnabled {
+					w.updateStakePoolTicket(&txInHash,
+						wstakemgr.TSMissed, block, tx.Sha())
+				}
+			} else if w.isSplitTicket(&txInHash) {
+				w.ticketRevoked(&txInHash, tx, block.Height)
 			}
 		}
 	}
 
+	// Transactions created by the wallet are recorded in the transaction
+	// store before they are published, so only transactions that are new
+	// to the store may be unexpected spends.
+	watched := w.watchedTx(tx, block)
+	if watched {
+		known, err := w.TxStore.TxDetails(&rec.Hash)
+		if err != nil {
+			return err
+		}
+		watched = known == nil
+	}
+
 	err := w.TxStore.InsertTx(rec, block)
 	if err != nil {
 		return err
 	}
+	w.stampFiatRate(&rec.Hash, block)
+
+	if watched {
+		err = w.checkUnexpectedSpend(rec, block)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Handle input scripts that contain P2PKs that we care about.
 	for i, input := range rec.MsgTx.TxIn {
@@ -564,13 +597,12 @@ func (w *Wallet) addRelevantTx(rec *wtxmgr.TxRecord,
 		switch {
 		case class == txscript.PubKeyHashTy:
 			for _, addr := range addrs {
-				ma, err := w.Manager.Address(addr)
+				_, err := w.Manager.Address(addr)
 				if err == nil {
 					// TODO: Credits should be added with the
 					// account they belong to, so wtxmgr is able to
 					// track per-account balances.
-					err = w.TxStore.AddCredit(rec, block, uint32(i),
-						ma.Internal())
+					err = w.TxStore.AddCredit(rec, block, uint32(i))
 					if err != nil {
 						return err
 					}
@@ -579,6 +611,7 @@ func (w *Wallet) addRelevantTx(rec *wtxmgr.TxRecord,
 						return err
 					}
 					log.Debugf("Marked address %v used", addr)
+					w.warnRetiredScript(rec, i)
 					continue
 				}
 
@@ -630,6 +663,7 @@ func (w *Wallet) addRelevantTx(rec *wtxmgr.TxRecord,
 			if expClass != txscript.MultiSigTy {
 				continue
 			}
+			w.warnRetiredScript(rec, i)
 
 			for _, maddr := range multisigAddrs {
 				_, err := w.Manager.Address(maddr)
@@ -648,7 +682,7 @@ func (w *Wallet) addRelevantTx(rec *wtxmgr.TxRecord,
 		}
 	}
 
-	// TODO: Notify connected clients of the added transaction.
+	w.notifyRelevantTx(chain.RelevantTx{TxRecord: rec, Block: block})
 
 	bs, err := w.chainSvr.BlockStamp()
 	if err == nil {
@@ -669,6 +703,7 @@ func (w *Wallet) handleStakeDifficulty(blockHash *chainhash.Hash,
 		blockHeight,
 		StakeDifficulty,
 	})
+	w.recordWindowPrice(blockHeight, dcrutil.Amount(StakeDifficulty))
 
 	return nil
 }
@@ -694,7 +729,29 @@ func (w *Wallet) notifyBalances(curHeight int32, balanceFlag wtxmgr.BehaviorFlag
 	w.notifyUnconfirmedBalance(unconfirmed - confirmed)
 }
 
+// missedTicketsQueueSize is the number of missed tickets notifications which
+// may wait for the missed tickets handler before the voting notification
+// handler blocks.
+const missedTicketsQueueSize = 64
+
 func (w *Wallet) handleChainVotingNotifications() {
+	// Missed tickets are handled by their own goroutine, as revoking them
+	// writes to the database and must never delay the votes of the
+	// following blocks.
+	missed := make(chan chain.MissedTickets, missedTicketsQueueSize)
+	missedDone := make(chan struct{})
+	go func() {
+		for n := range missed {
+			err := w.handleMissedTickets(n.BlockHash, n.BlockHeight,
+				n.Tickets)
+			if err != nil {
+				log.Errorf("Cannot handle chain server voting "+
+					"notification MissedTickets: %v", err)
+			}
+		}
+		close(missedDone)
+	}()
+
 	for n := range w.chainSvr.NotificationsVoting() {
 		var err error
 		strErrType := ""
@@ -704,8 +761,7 @@ func (w *Wallet) handleChainVotingNotifications() {
 			err = w.handleWinningTickets(n.BlockHash, n.BlockHeight, n.Tickets)
 			strErrType = "WinningTickets"
 		case chain.MissedTickets:
-			err = w.handleMissedTickets(n.BlockHash, n.BlockHeight, n.Tickets)
-			strErrType = "MissedTickets"
+			missed <- n
 		default:
 			err = fmt.Errorf("voting handler received unknown ntfn type")
 		}
@@ -714,6 +770,9 @@ func (w *Wallet) handleChainVotingNotifications() {
 				"notification %v: %v", strErrType, err)
 		}
 	}
+
+	close(missed)
+	<-missedDone
 	w.wg.Done()
 }
 
@@ -741,8 +800,9 @@ func (w *Wallet) handleWinningTickets(blockHash *chainhash.Hash,
 		ntfns, err := w.StakeMgr.HandleWinningTicketsNtfn(blockHash,
 			blockHeight,
 			tickets,
-			w.VoteBits)
+			w.GetVoteBits())
 
+		w.recordStakeSigning(ntfns)
 		if ntfns != nil {
 			// Send notifications for newly created votes by the RPC.
 			for _, ntfn := range ntfns {
@@ -766,35 +826,80 @@ func (w *Wallet) handleWinningTickets(blockHash *chainhash.Hash,
 	return nil
 }
 
+// updateTicketStatus records a change in the lifecycle status of an owned
+// ticket, logging any error.
+func (w *Wallet) updateTicketStatus(ticket *chainhash.Hash,
+	status wstakemgr.TicketStatus, height int32) {
+	err := w.StakeMgr.UpdateTicketStatus(ticket, status, height)
+	if err != nil {
+		log.Errorf("Failed to update status of ticket %v to %v: %v",
+			ticket, status, err)
+	}
+}
+
+// resolveTicketStatuses records the mined height of tickets which the stake
+// store has recorded as unmined but which the transaction store has mined,
+// such as tickets owned before ticket statuses were tracked, and advances
+// them to their status at the synced height.
+func (w *Wallet) resolveTicketStatuses() error {
+	unmined, err := w.StakeMgr.UnminedTickets()
+	if err != nil {
+		return err
+	}
+
+	resolved := 0
+	for i := range unmined {
+		hash := &unmined[i]
+		details, err := w.TxStore.TxDetails(hash)
+		if err != nil {
+			return err
+		}
+		if details == nil || details.Block.Height == -1 {
+			continue
+		}
+		err = w.StakeMgr.UpdateTicketStatus(hash,
+			wstakemgr.TicketStatusImmature, details.Block.Height)
+		if err != nil {
+			return err
+		}
+		resolved++
+	}
+	if resolved == 0 {
+		return nil
+	}
+
+	bs := w.Manager.SyncedTo()
+	_, err = w.StakeMgr.ConnectTicketStatuses(bs.Height)
+	if err != nil {
+		return err
+	}
+	log.Infof("Resolved the status of %d mined tickets", resolved)
+	return nil
+}
+
 // handleMissedTickets receives a list of hashes and some block information
 // and submits it to the wstakemgr to handle SSRtx production.
 func (w *Wallet) handleMissedTickets(blockHash *chainhash.Hash,
 	blockHeight int64,
 	tickets []*chainhash.Hash) error {
 
-	if !w.StakeMiningEnabled {
+	for _, ticket := range tickets {
+		if w.StakeMgr.CheckHashInStore(ticket) || w.isSplitTicket(ticket) {
+			w.ticketMissed(ticket, int32(blockHeight))
+		}
+	}
+
+	if !w.autoRevoke {
 		return nil
 	}
 
-	if blockHeight >= w.chainParams.StakeValidationHeight+1 &&
-		w.StakeMiningEnabled {
+	if blockHeight >= w.chainParams.StakeValidationHeight+1 {
 		ntfns, err := w.StakeMgr.HandleMissedTicketsNtfn(blockHash,
 			blockHeight,
 			tickets)
 
-		if ntfns != nil {
-			// Send notifications for newly created revocations by the RPC.
-			for _, ntfn := range ntfns {
-				if ntfn != nil {
-					w.notifyRevocationCreated(*ntfn)
-
-					// Inform the console that we've revoked our ticket.
-					log.Infof("Revoked missed ticket %v (tx hash: %v)",
-						ntfn.SStxIn,
-						ntfn.TxHash)
-				}
-			}
-		}
+		// Send notifications for newly created revocations by the RPC.
+		w.handleRevocationNtfns(ntfns, "missed")
 
 		return err
 	}
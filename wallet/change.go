@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// addressChangeSource implements wtxmgr.ChangeSource using the address
+// manager.  An output is change when it pays to an address of the internal
+// branch of one of the wallet's accounts.
+type addressChangeSource struct {
+	manager *waddrmgr.Manager
+	params  *chaincfg.Params
+}
+
+// IsChangeOutput returns whether the output pays to an internal address of the
+// wallet.  Non-standard outputs and outputs paying to addresses unknown to the
+// address manager are not change.
+func (s *addressChangeSource) IsChangeOutput(output *wire.TxOut) (bool, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(output.Version,
+		output.PkScript, s.params)
+	if err != nil {
+		return false, nil
+	}
+	for _, addr := range addrs {
+		ma, err := s.manager.Address(addr)
+		if err != nil {
+			if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+				continue
+			}
+			return false, err
+		}
+		if ma.Internal() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
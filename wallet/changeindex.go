@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// changeIndexes returns the outputs of tx which pay addresses of the internal
+// branch of a wallet account, along with the child index of each address.
+func (w *Wallet) changeIndexes(tx *wire.MsgTx) ([]wtxmgr.ChangeIndex, error) {
+	var changes []wtxmgr.ChangeIndex
+	for i, txOut := range tx.TxOut {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.Version,
+			txOut.PkScript, w.chainParams)
+		if err != nil || len(addrs) != 1 {
+			continue
+		}
+		d, err := w.Manager.AddressDerivation(addrs[0])
+		if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if d == nil || d.Branch != waddrmgr.InternalBranch {
+			continue
+		}
+		changes = append(changes, wtxmgr.ChangeIndex{
+			Output:  uint32(i),
+			Account: d.Account,
+			Index:   d.Index,
+		})
+	}
+	return changes, nil
+}
+
+// recordChangeIndexes records the internal branch indexes of the change
+// outputs of a transaction signed by the wallet, so the change of every
+// authored transaction can be audited against the account extended public
+// keys.
+func (w *Wallet) recordChangeIndexes(tx *wire.MsgTx) error {
+	changes, err := w.changeIndexes(tx)
+	if err != nil {
+		return err
+	}
+	hash := tx.TxSha()
+	return w.TxStore.RecordChangeIndexes(&hash, changes)
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/decred/dcrd/blockchain/stake"
 	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainec"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrjson"
 	"github.com/decred/dcrd/txscript"
@@ -111,6 +112,15 @@ const FeeIncrementMainnet = 5e6
 // measured in atoms) added to transactions requiring a fee for TestNet.
 const FeeIncrementTestnet = 1e3
 
+// defaultFeeIncrement returns the default minimum fee increment of the network
+// described by params.
+func defaultFeeIncrement(params *chaincfg.Params) dcrutil.Amount {
+	if params == &chaincfg.MainNetParams {
+		return FeeIncrementMainnet
+	}
+	return FeeIncrementTestnet
+}
+
 // --------------------------------------------------------------------------------
 // Error Handling
 
@@ -134,6 +144,20 @@ func (e InsufficientFundsError) Error() string {
 		e.fee, e.in)
 }
 
+// FeeLimitExceededError represents an error where the fee of a transaction
+// created by the wallet exceeds either the absolute maximum fee or the
+// maximum fee relative to the amount sent.  Transactions exceeding the limit
+// are never signed or broadcast.
+type FeeLimitExceededError struct {
+	fee, limit dcrutil.Amount
+}
+
+// Error satisifies the builtin error interface.
+func (e FeeLimitExceededError) Error() string {
+	return fmt.Sprintf("transaction fee of %v exceeds the maximum allowed "+
+		"fee of %v", e.fee, e.limit)
+}
+
 // ErrUnsupportedTransactionType represents an error where a transaction
 // cannot be signed as the API only supports spending P2PKH outputs.
 var ErrUnsupportedTransactionType = errors.New("Only P2PKH transactions " +
@@ -177,6 +201,21 @@ var ErrTicketPriceNotSet = errors.New("ticket price not yet established")
 var ErrClientPurchaseTicket = errors.New("sendrawtransaction failed: the " +
 	"client has been shutdown")
 
+// ErrSStxBalanceReserve indicates that purchasing a ticket would reduce the
+// spendable balance of the wallet below the balance to maintain.
+var ErrSStxBalanceReserve = errors.New("ticket purchase would reduce the " +
+	"spendable balance below the balance to maintain")
+
+// ErrTicketWindowLimit indicates that the maximum number of tickets have
+// already been purchased in the current stake difficulty window.
+var ErrTicketWindowLimit = errors.New("maximum number of tickets already " +
+	"purchased in this stake difficulty window")
+
+// ErrVotingOnly indicates that a transaction spending funds was requested
+// from a wallet running in voting-only mode.
+var ErrVotingOnly = errors.New("wallet is in voting-only mode and does " +
+	"not spend funds")
+
 // --------------------------------------------------------------------------------
 // Transaction creation
 
@@ -220,13 +259,12 @@ func (w *Wallet) insertCreditsIntoTxMgr(msgTx *wire.MsgTx,
 			continue
 		}
 		for _, addr := range addrs {
-			ma, err := w.Manager.Address(addr)
+			_, err := w.Manager.Address(addr)
 			if err == nil {
 				// TODO: Credits should be added with the
 				// account they belong to, so wtxmgr is able to
 				// track per-account balances.
-				err = w.TxStore.AddCredit(rec, nil, uint32(i),
-					ma.Internal())
+				err = w.TxStore.AddCredit(rec, nil, uint32(i))
 				if err != nil {
 					return err
 				}
@@ -276,7 +314,7 @@ func (w *Wallet) NewAddress(account uint32) (dcrutil.Address, error) {
 	for i, addr := range addrs {
 		utilAddrs[i] = addr.Address()
 	}
-	if err := w.chainSvr.NotifyReceived(utilAddrs); err != nil {
+	if err := w.notifyReceived(utilAddrs); err != nil {
 		return nil, err
 	}
 
@@ -297,7 +335,7 @@ func (w *Wallet) NewChangeAddress(account uint32) (dcrutil.Address, error) {
 		utilAddrs[i] = addr.Address()
 	}
 
-	if err := w.chainSvr.NotifyReceived(utilAddrs); err != nil {
+	if err := w.notifyReceived(utilAddrs); err != nil {
 		return nil, err
 	}
 
@@ -325,24 +363,23 @@ func (w *Wallet) ReusedAddress() (dcrutil.Address, error) {
 // address. InsufficientFundsError is returned if there are not enough
 // eligible unspent outputs to create the transaction.
 func (w *Wallet) txToPairs(pairs map[string]dcrutil.Amount, account uint32,
-	minconf int32, addrFunc func() (dcrutil.Address, error)) (*CreatedTx,
-	error) {
-	isReorganizing, _ := w.chainSvr.GetReorganizing()
-	if isReorganizing {
+	minconf int32, addrFunc func() (dcrutil.Address, error),
+	origin string) (*CreatedTx, error) {
+	if w.chainReorganizing() {
 		return nil, ErrBlockchainReorganizing
 	}
 
 	// Address manager must be unlocked to compose transaction.  Grab
 	// the unlock if possible (to prevent future unlocks), or return the
 	// error if already locked.
-	heldUnlock, err := w.HoldUnlock()
+	release, err := w.holdSigningUnlock()
 	if err != nil {
 		return nil, err
 	}
-	defer heldUnlock.Release()
+	defer release()
 
 	// Get current block's height and hash.
-	bs, err := w.chainSvr.BlockStamp()
+	bs, err := w.chainBlockStamp()
 	if err != nil {
 		return nil, err
 	}
@@ -353,15 +390,7 @@ func (w *Wallet) txToPairs(pairs map[string]dcrutil.Amount, account uint32,
 	}
 
 	// Simple fee guesstimate.
-	var feeIncrement dcrutil.Amount
-	switch {
-	case w.chainParams == &chaincfg.MainNetParams:
-		feeIncrement = FeeIncrementMainnet
-	case w.chainParams == &chaincfg.TestNetParams:
-		feeIncrement = FeeIncrementTestnet
-	default:
-		feeIncrement = FeeIncrementTestnet
-	}
+	feeIncrement := defaultFeeIncrement(w.chainParams)
 	needed += feeForSize(feeIncrement,
 		estimateTxSize(len(pairs), len(pairs)))
 
@@ -371,19 +400,19 @@ func (w *Wallet) txToPairs(pairs map[string]dcrutil.Amount, account uint32,
 	}
 
 	return w.createTx(eligible, pairs, bs, w.FeeIncrement(), account,
-		addrFunc, w.chainParams, w.DisallowFree)
+		addrFunc, w.chainParams, w.DisallowFree, origin)
 }
 
 // createTx selects inputs (from the given slice of eligible utxos)
 // whose amount are sufficient to fulfil all the desired outputs plus
 // the mining fee. It then creates and returns a CreatedTx containing
 // the selected inputs and the given outputs, validating it (using
-// validateMsgTx) as well.
+// validateMsgTx) as well.  The transaction is signed for origin.
 func (w *Wallet) createTx(eligible []wtxmgr.Credit,
 	outputs map[string]dcrutil.Amount, bs *waddrmgr.BlockStamp,
 	feeIncrement dcrutil.Amount, account uint32,
 	addrFunc func() (dcrutil.Address, error), chainParams *chaincfg.Params,
-	disallowFree bool) (*CreatedTx, error) {
+	disallowFree bool, origin string) (*CreatedTx, error) {
 
 	msgtx := wire.NewMsgTx()
 	minAmount, err := addOutputs(msgtx, outputs, chainParams)
@@ -391,50 +420,10 @@ func (w *Wallet) createTx(eligible []wtxmgr.Credit,
 		return nil, err
 	}
 
-	// Sort eligible inputs so that we first pick the ones with highest
-	// amount, thus reducing number of inputs.
-	sort.Sort(sort.Reverse(ByAmount(eligible)))
-
-	// Start by adding enough inputs to cover for the total amount of all
-	// desired outputs.
-	var input wtxmgr.Credit
-	var inputs []wtxmgr.Credit
-	totalAdded := dcrutil.Amount(0)
-	for totalAdded < minAmount {
-		if len(eligible) == 0 {
-			bal, err := w.TxStore.Balance(1, bs.Height,
-				wtxmgr.BFBalanceSpendable)
-			if err != nil {
-				return nil, err
-			}
-			return nil, InsufficientFundsError{bal, minAmount, 0}
-		}
-		input, eligible = eligible[0], eligible[1:]
-		inputs = append(inputs, input)
-		msgtx.AddTxIn(wire.NewTxIn(&input.OutPoint, nil))
-		totalAdded += input.Amount
-	}
-
-	// Get an initial fee estimate based on the number of selected inputs
-	// and added outputs, with no change.
-	szEst := estimateTxSize(len(inputs), len(msgtx.TxOut))
-	feeEst := minimumFee(feeIncrement, szEst, msgtx.TxOut, inputs, bs.Height,
-		disallowFree)
-
-	// Now make sure the sum amount of all our inputs is enough for the
-	// sum amount of all outputs plus the fee. If necessary we add more,
-	// inputs, but in that case we also need to recalculate the fee.
-	for totalAdded < minAmount+feeEst {
-		if len(eligible) == 0 {
-			return nil, InsufficientFundsError{totalAdded, minAmount, feeEst}
-		}
-		input, eligible = eligible[0], eligible[1:]
-		inputs = append(inputs, input)
-		msgtx.AddTxIn(wire.NewTxIn(&input.OutPoint, nil))
-		szEst += txInEstimate
-		totalAdded += input.Amount
-		feeEst = minimumFee(feeIncrement, szEst, msgtx.TxOut, inputs, bs.Height,
-			disallowFree)
+	sel, err := w.selectInputs(eligible, msgtx.TxOut, minAmount, bs,
+		feeIncrement, disallowFree)
+	if err != nil {
+		return nil, err
 	}
 
 	// If we're spending the outputs of an imported address, we default
@@ -443,64 +432,61 @@ func (w *Wallet) createTx(eligible []wtxmgr.Credit,
 		account = waddrmgr.DefaultAccountNum
 	}
 
+	// The transaction is rebuilt and signed for every fee tried, so the
+	// fee is raised until it pays for the size of the signed transaction.
+	outs := msgtx.TxOut
 	var changeAddr dcrutil.Address
 	// changeIdx is -1 unless there's a change output.
 	changeIdx := -1
-
-	for {
-		change := totalAdded - minAmount - feeEst
+	signedSize := func(inputs []wtxmgr.Credit, change dcrutil.Amount) (int,
+		error) {
+		msgtx.TxIn = nil
+		for i := range inputs {
+			msgtx.AddTxIn(wire.NewTxIn(&inputs[i].OutPoint, nil))
+		}
+		msgtx.TxOut = append([]*wire.TxOut(nil), outs...)
+		changeIdx = -1
 		if change > 0 {
 			if changeAddr == nil {
-				changeAddr, err = addrFunc()
+				addr, err := addrFunc()
 				if err != nil {
-					return nil, err
+					return 0, err
 				}
+				changeAddr = addr
 			}
-
-			changeIdx, err = addChange(msgtx, change, changeAddr)
+			idx, err := addChange(msgtx, change, changeAddr)
 			if err != nil {
-				return nil, err
+				return 0, err
 			}
+			changeIdx = idx
 		}
 
-		if err = signMsgTx(msgtx, inputs, w.Manager, chainParams); err != nil {
-			return nil, err
+		if err := w.checkSigningPolicy(msgtx, origin); err != nil {
+			return 0, err
 		}
-
-		if feeForSize(feeIncrement, msgtx.SerializeSize()) <= feeEst {
-			// The required fee for this size is less than or equal to what
-			// we guessed, so we're done.
-			break
+		if err := w.CheckSendApproval(msgtx, origin); err != nil {
+			return 0, err
 		}
-
-		if change > 0 {
-			// Remove the change output since the next iteration will add
-			// it again (with a new amount) if necessary.
-			tmp := msgtx.TxOut[:changeIdx]
-			tmp = append(tmp, msgtx.TxOut[changeIdx+1:]...)
-			msgtx.TxOut = tmp
-		}
-
-		feeEst += feeIncrement
-		for totalAdded < minAmount+feeEst {
-			if len(eligible) == 0 {
-				return nil, InsufficientFundsError{totalAdded, minAmount, feeEst}
-			}
-			input, eligible = eligible[0], eligible[1:]
-			inputs = append(inputs, input)
-			msgtx.AddTxIn(wire.NewTxIn(&input.OutPoint, nil))
-			szEst += txInEstimate
-			totalAdded += input.Amount
-			feeEst = minimumFee(feeIncrement, szEst, msgtx.TxOut, inputs,
-				bs.Height, disallowFree)
+		if err := w.signMsgTx(msgtx, inputs); err != nil {
+			return 0, err
 		}
+		return msgtx.SerializeSize(), nil
 	}
+	funding, err := w.fundTx(sel, outs, minAmount, bs, feeIncrement,
+		disallowFree, signedSize)
+	if err != nil {
+		return nil, err
+	}
+	inputs := funding.inputs
 
 	if err := validateMsgTx(msgtx, inputs); err != nil {
 		return nil, err
 	}
+	if err := w.recordSigning(msgtx, origin); err != nil {
+		return nil, err
+	}
 
-	_, err = w.chainSvr.SendRawTransaction(msgtx, false)
+	_, err = w.sendRawTransaction(msgtx)
 	if err != nil {
 		return nil, err
 	}
@@ -528,6 +514,146 @@ func (w *Wallet) createTx(eligible []wtxmgr.Credit,
 	return info, nil
 }
 
+// inputSelection describes the inputs selected by selectInputs, their total
+// amount, and the estimated size and minimum fee of the transaction spending
+// them without a change output.  The eligible outputs which were not selected
+// remain to cover a larger fee found after signing.
+type inputSelection struct {
+	inputs     []wtxmgr.Credit
+	remaining  []wtxmgr.Credit
+	totalAdded dcrutil.Amount
+	fee        dcrutil.Amount
+	size       int
+}
+
+// selectInputs selects inputs from eligible, picking those with the highest
+// amount first, whose total amount covers minAmount paid by outputs and the
+// minimum fee of the transaction.  Both createTx and previewTx select inputs
+// with it, so a preview describes the transaction which would be created.
+func (w *Wallet) selectInputs(eligible []wtxmgr.Credit, outputs []*wire.TxOut,
+	minAmount dcrutil.Amount, bs *waddrmgr.BlockStamp,
+	feeIncrement dcrutil.Amount, disallowFree bool) (*inputSelection, error) {
+
+	// Sort eligible inputs so that we first pick the ones with highest
+	// amount, thus reducing number of inputs.
+	sort.Sort(sort.Reverse(ByAmount(eligible)))
+
+	// Start by adding enough inputs to cover for the total amount of all
+	// desired outputs.
+	sel := &inputSelection{}
+	for sel.totalAdded < minAmount {
+		if len(eligible) == 0 {
+			bal, err := w.TxStore.Balance(1, bs.Height,
+				wtxmgr.BFBalanceSpendable)
+			if err != nil {
+				return nil, err
+			}
+			return nil, InsufficientFundsError{bal, minAmount, 0}
+		}
+		sel.inputs = append(sel.inputs, eligible[0])
+		sel.totalAdded += eligible[0].Amount
+		eligible = eligible[1:]
+	}
+
+	// Get an initial fee estimate based on the number of selected inputs
+	// and added outputs, with no change.
+	sel.size = estimateTxSize(len(sel.inputs), len(outputs))
+	sel.fee = minimumFee(feeIncrement, sel.size, outputs, sel.inputs,
+		bs.Height, disallowFree)
+
+	// Now make sure the sum amount of all our inputs is enough for the
+	// sum amount of all outputs plus the fee. If necessary we add more,
+	// inputs, but in that case we also need to recalculate the fee.
+	for sel.totalAdded < minAmount+sel.fee {
+		if len(eligible) == 0 {
+			return nil, InsufficientFundsError{sel.totalAdded,
+				minAmount, sel.fee}
+		}
+		sel.inputs = append(sel.inputs, eligible[0])
+		sel.totalAdded += eligible[0].Amount
+		eligible = eligible[1:]
+		sel.size += txInEstimate
+		sel.fee = minimumFee(feeIncrement, sel.size, outputs, sel.inputs,
+			bs.Height, disallowFree)
+	}
+
+	sel.remaining = eligible
+	return sel, nil
+}
+
+// txFunding describes the inputs funding a transaction, their total amount,
+// and the change, fee, and size of the transaction as found by fundTx.
+type txFunding struct {
+	inputs     []wtxmgr.Credit
+	totalAdded dcrutil.Amount
+	change     dcrutil.Amount
+	fee        dcrutil.Amount
+	size       int
+}
+
+// fundTx raises the fee of a transaction paying minAmount to outputs from the
+// inputs selected by selectInputs until the fee pays for the size of the
+// transaction, adding the remaining eligible outputs of the selection as
+// inputs when necessary.  The size function returns the size of the
+// transaction spending inputs with a change output of change, or without one
+// when change is zero.  createTx signs the transaction to measure it, while
+// previews estimate the size of the signed transaction, so both raise the fee
+// in the same steps.
+//
+// Change which would not be positive is paid to the miner.  The fee, including
+// such change, is checked against the fee limits of the wallet before size is
+// called, so transactions paying too much are never signed.
+func (w *Wallet) fundTx(sel *inputSelection, outputs []*wire.TxOut,
+	minAmount dcrutil.Amount, bs *waddrmgr.BlockStamp,
+	feeIncrement dcrutil.Amount, disallowFree bool,
+	size func(inputs []wtxmgr.Credit, change dcrutil.Amount) (int,
+		error)) (*txFunding, error) {
+
+	inputs, eligible := sel.inputs, sel.remaining
+	totalAdded, feeEst, szEst := sel.totalAdded, sel.fee, sel.size
+	for {
+		change := totalAdded - minAmount - feeEst
+		fee := feeEst
+		if change <= 0 {
+			fee = totalAdded - minAmount
+			change = 0
+		}
+		if err := w.checkFeeLimit(fee, minAmount); err != nil {
+			return nil, err
+		}
+
+		sz, err := size(inputs, change)
+		if err != nil {
+			return nil, err
+		}
+		if feeForSize(feeIncrement, sz) <= feeEst {
+			// The required fee for this size is less than or
+			// equal to what we guessed, so we're done.
+			return &txFunding{
+				inputs:     inputs,
+				totalAdded: totalAdded,
+				change:     change,
+				fee:        fee,
+				size:       sz,
+			}, nil
+		}
+
+		feeEst += feeIncrement
+		for totalAdded < minAmount+feeEst {
+			if len(eligible) == 0 {
+				return nil, InsufficientFundsError{totalAdded,
+					minAmount, feeEst}
+			}
+			inputs = append(inputs, eligible[0])
+			totalAdded += eligible[0].Amount
+			eligible = eligible[1:]
+			szEst += txInEstimate
+			feeEst = minimumFee(feeIncrement, szEst, outputs, inputs,
+				bs.Height, disallowFree)
+		}
+	}
+}
+
 // addChange adds a new output with the given amount and address, and
 // randomizes the index (and returns it) of the newly added output.
 func addChange(msgtx *wire.MsgTx, change dcrutil.Amount,
@@ -582,10 +708,10 @@ func constructMultiSigScript(keys []dcrutil.AddressSecpPubKey,
 }
 
 // txToMultisig spends funds to a multisig output, partially signs the
-// transaction, then returns fund
+// transaction for origin, then returns fund
 func (w *Wallet) txToMultisig(account uint32, amount dcrutil.Amount,
 	pubkeys []*dcrutil.AddressSecpPubKey, nRequired int8,
-	minconf int32) (*CreatedTx, dcrutil.Address, []byte, error) {
+	minconf int32, origin string) (*CreatedTx, dcrutil.Address, []byte, error) {
 	// Initialize the address pool for use.
 	pool := w.internalPool
 	pool.mutex.Lock()
@@ -603,6 +729,9 @@ func (w *Wallet) txToMultisig(account uint32, amount dcrutil.Amount,
 			return nil, nil, nil, err
 		}
 
+	if w.chainSvr == nil {
+		return errorOut(ErrOffline)
+	}
 	isReorganizing, _ := w.chainSvr.GetReorganizing()
 	if isReorganizing {
 		return errorOut(ErrBlockchainReorganizing)
@@ -611,11 +740,11 @@ func (w *Wallet) txToMultisig(account uint32, amount dcrutil.Amount,
 	// Address manager must be unlocked to compose transaction.  Grab
 	// the unlock if possible (to prevent future unlocks), or return the
 	// error if already locked.
-	heldUnlock, err := w.HoldUnlock()
+	release, err := w.holdSigningUnlock()
 	if err != nil {
 		return errorOut(err)
 	}
-	defer heldUnlock.Release()
+	defer release()
 
 	// Get current block's height and hash.
 	bs, err := w.chainSvr.BlockStamp()
@@ -699,21 +828,16 @@ func (w *Wallet) txToMultisig(account uint32, amount dcrutil.Amount,
 	// we don't need to add a change output in this
 	// case.
 	feeSize := estimateTxSize(numInputs, 2)
-	var feeIncrement dcrutil.Amount
-	switch {
-	case w.chainParams == &chaincfg.MainNetParams:
-		feeIncrement = FeeIncrementMainnet
-	case w.chainParams == &chaincfg.TestNetParams:
-		feeIncrement = FeeIncrementTestnet
-	default:
-		feeIncrement = FeeIncrementTestnet
-	}
+	feeIncrement := defaultFeeIncrement(w.chainParams)
 	feeEst := feeForSize(feeIncrement, feeSize)
 
 	if totalInput < amount+feeEst {
 		return errorOut(fmt.Errorf("Not enough funds to send to " +
 			"multisig address after accounting for fees"))
 	}
+	if err := w.checkFeeLimit(feeEst, amount); err != nil {
+		return errorOut(err)
+	}
 	if totalInput > amount+feeEst {
 		changeAddr, err := addrFunc()
 		if err != nil {
@@ -727,12 +851,22 @@ func (w *Wallet) txToMultisig(account uint32, amount dcrutil.Amount,
 		msgtx.AddTxOut(wire.NewTxOut(int64(change), pkScript))
 	}
 
-	if err = signMsgTx(msgtx, forSigning, w.Manager,
-		w.chainParams); err != nil {
+	if err = w.checkSigningPolicy(msgtx, origin); err != nil {
+		return errorOut(err)
+	}
+	// The multisig output is sent even though its script is imported, since
+	// the wallet may not hold the keys to spend it.
+	if err = w.checkSendApproval(amount, origin); err != nil {
+		return errorOut(err)
+	}
+	if err = w.signMsgTx(msgtx, forSigning); err != nil {
+		return errorOut(err)
+	}
+	if err = w.recordSigning(msgtx, origin); err != nil {
 		return errorOut(err)
 	}
 
-	_, err = w.chainSvr.SendRawTransaction(msgtx, false)
+	_, err = w.sendRawTransaction(msgtx)
 	if err != nil {
 		return errorOut(err)
 	}
@@ -760,8 +894,12 @@ func (w *Wallet) txToMultisig(account uint32, amount dcrutil.Amount,
 }
 
 // compressWallet compresses all the utxos in a wallet into a single change
-// address. For use when it becomes dusty.
-func (w *Wallet) compressWallet(maxNumIns int) error {
+// address. For use when it becomes dusty.  The transaction is signed for
+// origin.
+func (w *Wallet) compressWallet(maxNumIns int, origin string) error {
+	if w.chainSvr == nil {
+		return ErrOffline
+	}
 	isReorganizing, _ := w.chainSvr.GetReorganizing()
 	if isReorganizing {
 		return ErrBlockchainReorganizing
@@ -788,7 +926,7 @@ func (w *Wallet) compressWallet(maxNumIns int) error {
 	addrFunc := pool.GetNewAddress
 
 	account := uint32(waddrmgr.DefaultAccountNum)
-	minconf := int32(1)
+	minconf := w.SpendPolicy().MinConf
 	eligible, err := w.findEligibleOutputs(account, minconf, bs)
 	if err != nil {
 		return err
@@ -806,15 +944,7 @@ func (w *Wallet) compressWallet(maxNumIns int) error {
 	// Get an initial fee estimate based on the number of selected inputs
 	// and added outputs, with no change.
 	szEst := estimateTxSize(txInCount, 1)
-	var feeIncrement dcrutil.Amount
-	switch {
-	case w.chainParams == &chaincfg.MainNetParams:
-		feeIncrement = FeeIncrementMainnet
-	case w.chainParams == &chaincfg.TestNetParams:
-		feeIncrement = FeeIncrementTestnet
-	default:
-		feeIncrement = FeeIncrementTestnet
-	}
+	feeIncrement := defaultFeeIncrement(w.chainParams)
 	feeEst := feeForSize(feeIncrement, szEst)
 
 	msgtx := wire.NewMsgTx()
@@ -847,15 +977,23 @@ func (w *Wallet) compressWallet(maxNumIns int) error {
 	}
 	msgtx.AddTxOut(wire.NewTxOut(int64(outputAmt), pkScript))
 
-	if err = signMsgTx(msgtx, forSigning, w.Manager,
-		w.chainParams); err != nil {
+	if err := w.checkFeeLimit(feeEst, outputAmt); err != nil {
+		return err
+	}
+	if err = w.checkSigningPolicy(msgtx, origin); err != nil {
+		return err
+	}
+	if err = w.signMsgTx(msgtx, forSigning); err != nil {
 		return err
 	}
 	if err := validateMsgTx(msgtx, forSigning); err != nil {
 		return err
 	}
+	if err := w.recordSigning(msgtx, origin); err != nil {
+		return err
+	}
 
-	txSha, err := w.chainSvr.SendRawTransaction(msgtx, false)
+	txSha, err := w.sendRawTransaction(msgtx)
 	if err != nil {
 		return err
 	}
@@ -877,8 +1015,8 @@ func (w *Wallet) compressWallet(maxNumIns int) error {
 }
 
 // compressEligible compresses all the utxos passed to it into a single
-// output back to the wallet.
-func (w *Wallet) compressEligible(eligible []wtxmgr.Credit) error {
+// output back to the wallet.  The transaction is signed for origin.
+func (w *Wallet) compressEligible(eligible []wtxmgr.Credit, origin string) error {
 	// Initialize the address pool for use.
 	pool := w.internalPool
 	pool.mutex.Lock()
@@ -902,15 +1040,7 @@ func (w *Wallet) compressEligible(eligible []wtxmgr.Credit) error {
 	// Get an initial fee estimate based on the number of selected inputs
 	// and added outputs, with no change.
 	szEst := estimateTxSize(txInCount, 1)
-	var feeIncrement dcrutil.Amount
-	switch {
-	case w.chainParams == &chaincfg.MainNetParams:
-		feeIncrement = FeeIncrementMainnet
-	case w.chainParams == &chaincfg.TestNetParams:
-		feeIncrement = FeeIncrementTestnet
-	default:
-		feeIncrement = FeeIncrementTestnet
-	}
+	feeIncrement := defaultFeeIncrement(w.chainParams)
 	feeEst := feeForSize(feeIncrement, szEst)
 
 	msgtx := wire.NewMsgTx()
@@ -937,15 +1067,23 @@ func (w *Wallet) compressEligible(eligible []wtxmgr.Credit) error {
 	}
 	msgtx.AddTxOut(wire.NewTxOut(int64(outputAmt), pkScript))
 
-	if err = signMsgTx(msgtx, forSigning, w.Manager,
-		w.chainParams); err != nil {
+	if err := w.checkFeeLimit(feeEst, outputAmt); err != nil {
+		return err
+	}
+	if err = w.checkSigningPolicy(msgtx, origin); err != nil {
+		return err
+	}
+	if err = w.signMsgTx(msgtx, forSigning); err != nil {
 		return err
 	}
 	if err := validateMsgTx(msgtx, forSigning); err != nil {
 		return err
 	}
+	if err := w.recordSigning(msgtx, origin); err != nil {
+		return err
+	}
 
-	txSha, err := w.chainSvr.SendRawTransaction(msgtx, false)
+	txSha, err := w.sendRawTransaction(msgtx)
 	if err != nil {
 		return err
 	}
@@ -978,10 +1116,13 @@ func (w *Wallet) compressEligible(eligible []wtxmgr.Credit) error {
 func (w *Wallet) txToSStx(pair map[string]dcrutil.Amount,
 	inputCredits []wtxmgr.Credit, inputs []dcrjson.SStxInput,
 	payouts []dcrjson.SStxCommitOut, account uint32,
-	addrFunc func() (dcrutil.Address, error), minconf int32) (*CreatedTx,
-	error) {
+	addrFunc func() (dcrutil.Address, error), minconf int32,
+	origin string) (*CreatedTx, error) {
 
 	// Quit if the blockchain is reorganizing.
+	if w.chainSvr == nil {
+		return nil, ErrOffline
+	}
 	isReorganizing, _ := w.chainSvr.GetReorganizing()
 	if isReorganizing {
 		return nil, ErrBlockchainReorganizing
@@ -990,11 +1131,11 @@ func (w *Wallet) txToSStx(pair map[string]dcrutil.Amount,
 	// Address manager must be unlocked to compose transaction.  Grab
 	// the unlock if possible (to prevent future unlocks), or return the
 	// error if already locked.
-	heldUnlock, err := w.HoldUnlock()
+	release, err := w.holdSigningUnlock()
 	if err != nil {
 		return nil, err
 	}
-	defer heldUnlock.Release()
+	defer release()
 
 	if len(inputs) != len(payouts) {
 		return nil, fmt.Errorf("input and payout must have the same length")
@@ -1142,13 +1283,28 @@ func (w *Wallet) txToSStx(pair map[string]dcrutil.Amount,
 	if _, err := stake.IsSStx(dcrutil.NewTx(msgtx)); err != nil {
 		return nil, err
 	}
-	if err = signMsgTx(msgtx, inputCredits, w.Manager,
-		w.chainParams); err != nil {
+
+	// The fee is whatever the inputs neither commit to the ticket nor
+	// return as change.
+	outputTotal := dcrutil.Amount(0)
+	for _, txOut := range msgtx.TxOut {
+		outputTotal += dcrutil.Amount(txOut.Value)
+	}
+	if err := w.checkFeeLimit(totalAdded-outputTotal, minAmount); err != nil {
+		return nil, err
+	}
+	if err = w.checkSigningPolicy(msgtx, origin); err != nil {
+		return nil, err
+	}
+	if err = w.signMsgTx(msgtx, inputCredits); err != nil {
 		return nil, err
 	}
 	if err := validateMsgTx(msgtx, inputCredits); err != nil {
 		return nil, err
 	}
+	if err := w.recordSigning(msgtx, origin); err != nil {
+		return nil, err
+	}
 	info := &CreatedTx{
 		MsgTx:       msgtx,
 		ChangeAddr:  nil,
@@ -1160,6 +1316,32 @@ func (w *Wallet) txToSStx(pair map[string]dcrutil.Amount,
 	return info, nil
 }
 
+// purchaseTicketAddress returns the address a purchased ticket pays to.  The
+// passed address is preferred, followed by the ticket address specified on
+// the command line, falling back to generating a new address.
+func (w *Wallet) purchaseTicketAddress(ticketAddr dcrutil.Address,
+	addrFunc func() (dcrutil.Address, error)) (dcrutil.Address, error) {
+	if ticketAddr != nil {
+		return ticketAddr, nil
+	}
+	if w.ticketAddress != nil {
+		return w.ticketAddress, nil
+	}
+	return addrFunc()
+}
+
+// purchaseRewardAddress returns the address a purchased ticket commits its
+// rewards to.  Votes and revocations of the ticket pay to this address.  The
+// reward address specified on the command line is preferred, falling back to
+// generating a new address.
+func (w *Wallet) purchaseRewardAddress(addrFunc func() (dcrutil.Address,
+	error)) (dcrutil.Address, error) {
+	if w.rewardAddress != nil {
+		return w.rewardAddress, nil
+	}
+	return addrFunc()
+}
+
 // purchaseTicket indicates to the wallet that a ticket should be purchased
 // using all currently available funds.  The ticket address parameter in the
 // request can be nil in which case the ticket address associated with the
@@ -1187,6 +1369,9 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 		addrFunc = w.ReusedAddress
 	}
 
+	if w.chainSvr == nil {
+		return "", ErrOffline
+	}
 	isReorganizing, _ := w.chainSvr.GetReorganizing()
 	if isReorganizing {
 		return "", ErrBlockchainReorganizing
@@ -1216,21 +1401,20 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 		return nil, err
 	}
 
+	// Refuse to purchase more tickets in this stake difficulty window than
+	// the wallet is configured to.
+	window := int64(bs.Height) / w.chainParams.StakeDiffWindowSize
+	if !w.ticketWindowAvailable(window) {
+		return nil, ErrTicketWindowLimit
+	}
+
 	// Prefer using the ticket address passed to this function.  When one
 	// was not passed, attempt to use the ticket address specified on the
 	// command line.  When that one is not specified either, fall back to
 	// generating a new one.
-	ticketAddr := req.ticketAddr
-	if ticketAddr == nil {
-		if w.ticketAddress != nil {
-			ticketAddr = w.ticketAddress
-		} else {
-			newAddress, err := addrFunc()
-			if err != nil {
-				return nil, err
-			}
-			ticketAddr = newAddress
-		}
+	ticketAddr, err := w.purchaseTicketAddress(req.ticketAddr, addrFunc)
+	if err != nil {
+		return nil, err
 	}
 
 	// Recreate address/amount pairs, using btcutil.Amount.
@@ -1253,6 +1437,10 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 		return eligible, ErrSStxInputOverflow
 	}
 
+	// Bid a fee which competes with the tickets in the mempool.
+	feeIncrement := w.ticketFeeIncrement(1)
+	w.setLastTicketFeeRate(feeIncrement)
+
 	// Prepare inputs and commit outs to create new sstx.
 	couts := []dcrjson.SStxCommitOut{}
 	inputs := []dcrjson.SStxInput{}
@@ -1260,7 +1448,7 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 	inputSum := int64(0)
 	outputSum := int64(0)
 	for i, credit := range eligible {
-		newAddress, err := addrFunc()
+		newAddress, err := w.purchaseRewardAddress(addrFunc)
 		if err != nil {
 			return nil, err
 		}
@@ -1299,15 +1487,6 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 
 			// Calculate the amount of fees needed.
 			s := estimateSSTxSize(i, i)
-			var feeIncrement dcrutil.Amount
-			switch {
-			case w.chainParams == &chaincfg.MainNetParams:
-				feeIncrement = FeeIncrementMainnet
-			case w.chainParams == &chaincfg.TestNetParams:
-				feeIncrement = FeeIncrementTestnet
-			default:
-				feeIncrement = FeeIncrementTestnet
-			}
 			fee := feeForSize(feeIncrement, s)
 
 			// Not enough funds after taking fee into account.
@@ -1339,7 +1518,7 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 	// Create transaction, replying with an error if the creation
 	// was not successful.
 	createdTx, err := w.txToSStx(pair, usedCredits, inputs, couts, account,
-		addrFunc, req.minConf)
+		addrFunc, req.minConf, req.origin)
 	if err != nil {
 		switch {
 		case err == ErrNonPositiveAmount:
@@ -1349,7 +1528,30 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 		}
 	}
 
-	txSha, err := w.chainSvr.SendRawTransaction(createdTx.MsgTx, false)
+	outputTotal := int64(0)
+	for _, txOut := range createdTx.MsgTx.TxOut {
+		outputTotal += txOut.Value
+	}
+
+	// Never spend below the balance to maintain, whether it was requested
+	// by the caller or configured for the wallet.  Only the ticket price and
+	// fee leave the spendable balance, as the change is returned to the
+	// wallet.
+	reserve := req.minBalance
+	if w.BalanceToMaintain > reserve {
+		reserve = w.BalanceToMaintain
+	}
+	spendable, err := w.TxStore.Balance(req.minConf, bs.Height,
+		wtxmgr.BFBalanceSpendable)
+	if err != nil {
+		return nil, err
+	}
+	spent := ticketPrice + dcrutil.Amount(inputSum-outputTotal)
+	if spendable-spent < reserve {
+		return nil, ErrSStxBalanceReserve
+	}
+
+	txSha, err := w.sendRawTransaction(createdTx.MsgTx)
 	if err != nil {
 		log.Warnf("Failed to send raw transaction: %v", err.Error())
 		inconsistent := strings.Contains(err.Error(),
@@ -1363,17 +1565,32 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 		return nil, ErrClientPurchaseTicket
 	}
 	txSucceeded = true
+	w.recordWindowPurchase(window)
 
-	// Insert the transaction and credits into the transaction manager.
-	rec, err := w.insertIntoTxMgr(createdTx.MsgTx)
+	err = w.recordTicketPurchase(createdTx.MsgTx, ticketAddr, ticketPrice)
 	if err != nil {
 		return nil, err
 	}
-	err = w.insertCreditsIntoTxMgr(createdTx.MsgTx, rec)
+
+	return txSha.String(), nil
+}
+
+// recordTicketPurchase inserts a published ticket purchase and its credits
+// into the transaction manager, records the ticket in the stake manager when
+// the wallet owns the ticket address, and notifies the purchase.
+func (w *Wallet) recordTicketPurchase(ticket *wire.MsgTx,
+	ticketAddr dcrutil.Address, ticketPrice dcrutil.Amount) error {
+	// Insert the transaction and credits into the transaction manager.
+	rec, err := w.insertIntoTxMgr(ticket)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	err = w.insertCreditsIntoTxMgr(ticket, rec)
+	if err != nil {
+		return err
 	}
-	txTemp := dcrutil.NewTx(createdTx.MsgTx)
+	txTemp := dcrutil.NewTx(ticket)
+	txSha := txTemp.Sha()
 
 	// The ticket address may be for another wallet. Don't insert the
 	// ticket into the stake manager unless we actually own output zero
@@ -1383,8 +1600,8 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 		if w.ticketAddress == nil {
 			err = w.StakeMgr.InsertSStx(txTemp)
 			if err != nil {
-				return nil, fmt.Errorf("Failed to insert SStx %v"+
-					"into the stake store", txTemp.Sha())
+				return fmt.Errorf("Failed to insert SStx %v"+
+					"into the stake store", txSha)
 			}
 		}
 	}
@@ -1403,7 +1620,7 @@ func (w *Wallet) purchaseTicket(req purchaseTicketRequest) (interface{},
 	}
 	w.notifyTicketPurchase(ntfn)
 
-	return txSha.String(), nil
+	return nil
 }
 
 // addOutputsSStx is used to add outputs for a stake SStx.
@@ -1420,6 +1637,9 @@ func addOutputsSStx(msgtx *wire.MsgTx,
 // DECRED TODO
 func (w *Wallet) txToSSGen(ticketHash chainhash.Hash, blockHash chainhash.Hash,
 	height int64, votebits uint16) (*CreatedTx, error) {
+	if w.chainSvr == nil {
+		return nil, ErrOffline
+	}
 	isReorganizing, _ := w.chainSvr.GetReorganizing()
 	if isReorganizing {
 		return nil, ErrBlockchainReorganizing
@@ -1431,6 +1651,9 @@ func (w *Wallet) txToSSGen(ticketHash chainhash.Hash, blockHash chainhash.Hash,
 // txToSSRtx ...
 // DECRED TODO
 func (w *Wallet) txToSSRtx(ticketHash chainhash.Hash) (*CreatedTx, error) {
+	if w.chainSvr == nil {
+		return nil, ErrOffline
+	}
 	isReorganizing, _ := w.chainSvr.GetReorganizing()
 	if isReorganizing {
 		return nil, ErrBlockchainReorganizing
@@ -1439,6 +1662,14 @@ func (w *Wallet) txToSSRtx(ticketHash chainhash.Hash) (*CreatedTx, error) {
 	return nil, nil
 }
 
+// zeroChangeAddress returns the address paid by ticket change outputs which
+// carry no value.  No key controls it, so the wallet does not derive an
+// address which would never be paid.
+func (w *Wallet) zeroChangeAddress() (dcrutil.Address, error) {
+	return dcrutil.NewAddressPubKeyHash(make([]byte, 20), w.chainParams,
+		chainec.ECTypeSecp256k1)
+}
+
 // addSStxChange adds a new output with the given amount and address, and
 // randomizes the index (and returns it) of the newly added output.
 func addSStxChange(msgtx *wire.MsgTx, change dcrutil.Amount,
@@ -1470,15 +1701,28 @@ func (w *Wallet) findEligibleOutputs(account uint32, minconf int32,
 	// Because one of these filters requires matching the output script to
 	// the desired account, this change depends on making wtxmgr a waddrmgr
 	// dependancy and requesting unspent outputs for a single account.
+	policy := w.SpendPolicy()
+	spendsWallet := make(map[chainhash.Hash]bool)
 	eligible := make([]wtxmgr.Credit, 0, len(unspent))
 	for i := range unspent {
 		output := unspent[i]
 
 		// Only include this output if it meets the required number of
-		// confirmations.  Coinbase transactions must have have reached
+		// confirmations, or is unmined change the spend policy allows
+		// spending.  Coinbase transactions must have have reached
 		// maturity before their outputs may be spent.
 		if !confirmed(minconf, output.Height, bs.Height) {
-			continue
+			if output.Height != -1 {
+				continue
+			}
+			spendable, err := w.unminedChangeSpendable(&policy,
+				&output.Hash, spendsWallet)
+			if err != nil {
+				return nil, err
+			}
+			if !spendable {
+				continue
+			}
 		}
 
 		// Locked unspent outputs are skipped.
@@ -1547,10 +1791,24 @@ func (w *Wallet) FindEligibleOutputs(account uint32, minconf int32,
 }
 
 // findEligibleOutputsAmount uses wtxmgr to find a number of unspent
-// outputs while doing maturity checks there.
+// outputs while doing maturity checks there.  When the wallet separates credit
+// origins or its spend policy allows spending unconfirmed change, every
+// eligible output is considered by findEligibleOutputs instead, and when
+// separating origins only those of a single origin are returned.
 func (w *Wallet) findEligibleOutputsAmount(account uint32, minconf int32,
 	amount dcrutil.Amount, bs *waddrmgr.BlockStamp) ([]wtxmgr.Credit, error) {
 
+	if w.SeparateCreditOrigins || w.SpendPolicy().SpendUnconfirmedChange {
+		eligible, err := w.findEligibleOutputs(account, minconf, bs)
+		if err != nil {
+			return nil, err
+		}
+		if w.SeparateCreditOrigins {
+			eligible = singleOriginCredits(eligible, amount)
+		}
+		return eligible, nil
+	}
+
 	unspent, err := w.TxStore.UnspentOutputsForAmount(amount, bs.Height, minconf)
 	if err != nil {
 		errRepair := w.attemptToRepairInconsistencies()
@@ -1603,8 +1861,8 @@ func (w *Wallet) findEligibleOutputsAmount(account uint32, minconf int32,
 // signMsgTx sets the SignatureScript for every item in msgtx.TxIn.
 // It must be called every time a msgtx is changed.
 // Only P2PKH outputs are supported at this point.
-func signMsgTx(msgtx *wire.MsgTx, prevOutputs []wtxmgr.Credit,
-	mgr *waddrmgr.Manager, chainParams *chaincfg.Params) error {
+func (w *Wallet) signMsgTx(msgtx *wire.MsgTx,
+	prevOutputs []wtxmgr.Credit) error {
 	if len(prevOutputs) != len(msgtx.TxIn) {
 		return fmt.Errorf(
 			"Number of prevOutputs (%d) does not match number of tx inputs (%d)",
@@ -1614,7 +1872,7 @@ func signMsgTx(msgtx *wire.MsgTx, prevOutputs []wtxmgr.Credit,
 		// Errors don't matter here, as we only consider the
 		// case where len(addrs) == 1.
 		_, addrs, _, _ := txscript.ExtractPkScriptAddrs(
-			txscript.DefaultScriptVersion, output.PkScript, chainParams)
+			txscript.DefaultScriptVersion, output.PkScript, w.chainParams)
 		if len(addrs) != 1 {
 			continue
 		}
@@ -1623,22 +1881,15 @@ func signMsgTx(msgtx *wire.MsgTx, prevOutputs []wtxmgr.Credit,
 			return ErrUnsupportedTransactionType
 		}
 
-		ai, err := mgr.Address(apkh)
+		ai, err := w.Manager.Address(apkh)
 		if err != nil {
 			return fmt.Errorf("cannot get address info: %v", err)
 		}
 
-		pka := ai.(waddrmgr.ManagedPubKeyAddress)
-		privkey, err := pka.PrivKey()
+		sigscript, err := w.p2pkhSignatureScript(msgtx, i,
+			output.PkScript, ai)
 		if err != nil {
-			return fmt.Errorf("cannot get private key: %v", err)
-		}
-
-		sigscript, err := txscript.SignatureScript(msgtx, i,
-			output.PkScript, txscript.SigHashAll, privkey,
-			ai.Compressed())
-		if err != nil {
-			return fmt.Errorf("cannot create sigscript: %s", err)
+			return err
 		}
 		msgtx.TxIn[i].SignatureScript = sigscript
 	}
@@ -1675,6 +1926,23 @@ func validateMsgTx(msgtx *wire.MsgTx, prevOutputs []wtxmgr.Credit) error {
 	return nil
 }
 
+// checkFeeLimit returns a FeeLimitExceededError if fee exceeds the maximum
+// absolute fee of the wallet, or the maximum fee percentage of the amount
+// sent.  A zero limit disables the respective check.
+func (w *Wallet) checkFeeLimit(fee, sent dcrutil.Amount) error {
+	maxFee, maxFeePercent := w.FeeLimits()
+	if maxFee > 0 && fee > maxFee {
+		return FeeLimitExceededError{fee, maxFee}
+	}
+	if maxFeePercent > 0 && sent > 0 {
+		limit := dcrutil.Amount(float64(sent) * maxFeePercent / 100)
+		if fee > limit {
+			return FeeLimitExceededError{fee, limit}
+		}
+	}
+	return nil
+}
+
 // minimumFee estimates the minimum fee required for a transaction.
 // If cfg.DisallowFree is false, a fee may be zero so long as txLen
 // s less than 1 kilobyte and none of the outputs contain a value
@@ -11,6 +11,7 @@ import (
 
 	"github.com/decred/dcrd/blockchain"
 	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/txscript"
 	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
@@ -18,6 +19,7 @@ import (
 	"github.com/decred/dcrwallet/waddrmgr"
 	"github.com/decred/dcrwallet/walletdb"
 	_ "github.com/decred/dcrwallet/walletdb/bdb"
+	_ "github.com/decred/dcrwallet/walletdb/memdb"
 	"github.com/decred/dcrwallet/wtxmgr"
 )
 
@@ -251,3 +253,179 @@ func mockCredits(t *testing.T, txHex string, indices []uint32) []wtxmgr.Credit {
 	}
 	return eligible
 }
+
+func TestSingleOriginCredits(t *testing.T) {
+	credit := func(amount dcrutil.Amount, origin wtxmgr.CreditOrigin) wtxmgr.Credit {
+		return wtxmgr.Credit{Amount: amount, Origin: origin}
+	}
+	eligible := []wtxmgr.Credit{
+		credit(5e8, wtxmgr.OriginNormal),
+		credit(3e8, wtxmgr.OriginMixed),
+		credit(5e8, wtxmgr.OriginMixed),
+		credit(1e8, wtxmgr.OriginSwap),
+		credit(2e8, wtxmgr.OriginNormal),
+	}
+	tests := []struct {
+		amount dcrutil.Amount
+		origin wtxmgr.CreditOrigin
+	}{
+		// The first origin with enough funds is chosen.
+		{6e8, wtxmgr.OriginNormal},
+		{7e8, wtxmgr.OriginNormal},
+		// Only the mixed credits cover this amount.
+		{7e8 + 1, wtxmgr.OriginMixed},
+		// No origin suffices, so the greatest total is chosen.
+		{10e8, wtxmgr.OriginMixed},
+	}
+	for _, test := range tests {
+		credits := singleOriginCredits(eligible, test.amount)
+		if len(credits) == 0 {
+			t.Errorf("amount %v: no credits selected", test.amount)
+			continue
+		}
+		for _, c := range credits {
+			if c.Origin != test.origin {
+				t.Errorf("amount %v: selected credit of origin %v, "+
+					"expected %v", test.amount, c.Origin, test.origin)
+			}
+		}
+	}
+}
+
+// newTestWallet creates a testnet wallet backed by an in-memory database, with
+// an unlocked address manager and no chain server, for tests which create and
+// sign transactions.  The returned function closes the database.
+func newTestWallet(t *testing.T) (*Wallet, func()) {
+	db, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	teardown := func() {
+		db.Close()
+	}
+
+	addrNS, err := db.Namespace(waddrmgrNamespaceKey)
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+	txNS, err := db.Namespace(wtxmgrNamespaceKey)
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+	seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+	params := &chaincfg.TestNetParams
+	mgr, err := waddrmgr.Create(addrNS, seed, []byte("pub"), []byte("priv"),
+		params, fastScrypt)
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+	if err := mgr.Unlock([]byte("priv")); err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+	txs, err := wtxmgr.Create(txNS, params)
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+
+	w := newWallet(&walletConfig{}, mgr, txs, nil, &db, params)
+	return w, teardown
+}
+
+// testCredits returns mined credits of the given amounts paying to new
+// external addresses of the default account.
+func testCredits(t *testing.T, w *Wallet,
+	amounts ...dcrutil.Amount) []wtxmgr.Credit {
+	addrs, err := w.Manager.NextExternalAddresses(
+		waddrmgr.DefaultAccountNum, uint32(len(amounts)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	credits := make([]wtxmgr.Credit, len(amounts))
+	for i, addr := range addrs {
+		pkScript, err := txscript.PayToAddrScript(addr.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Each credit is given a unique outpoint from the hash160 of
+		// its address.
+		var hash chainhash.Hash
+		copy(hash[:], addr.Address().ScriptAddress())
+		credits[i] = wtxmgr.Credit{
+			OutPoint: wire.OutPoint{Hash: hash, Index: uint32(i)},
+			BlockMeta: wtxmgr.BlockMeta{
+				Block: wtxmgr.Block{Height: 1},
+			},
+			Amount:   amounts[i],
+			PkScript: pkScript,
+			Received: time.Now(),
+		}
+	}
+	return credits
+}
+
+func TestPreviewMatchesCreatedTx(t *testing.T) {
+	w, teardown := newTestWallet(t)
+	defer teardown()
+
+	bs := &waddrmgr.BlockStamp{Height: 100}
+	outputs := map[string]dcrutil.Amount{outAddr1: 35e7, outAddr2: 1e7}
+	account := uint32(waddrmgr.DefaultAccountNum)
+	addrFunc := func() (dcrutil.Address, error) {
+		return w.NewChangeAddress(account)
+	}
+
+	for _, disallowFree := range []bool{false, true} {
+		eligible := testCredits(t, w, 3e8, 1e8, 2e8, 5e7)
+		preview, err := w.previewCreateTx(
+			append([]wtxmgr.Credit(nil), eligible...), outputs, bs,
+			w.FeeIncrement(), w.chainParams, disallowFree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		created, err := w.createTx(
+			append([]wtxmgr.Credit(nil), eligible...), outputs, bs,
+			w.FeeIncrement(), account, addrFunc, w.chainParams,
+			disallowFree, SigningOriginWallet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tx := created.MsgTx
+		if len(tx.TxIn) != len(preview.Inputs) {
+			t.Fatalf("disallowFree=%v: created %d inputs, previewed %d",
+				disallowFree, len(tx.TxIn), len(preview.Inputs))
+		}
+		for i, txIn := range tx.TxIn {
+			if txIn.PreviousOutPoint != preview.Inputs[i].OutPoint {
+				t.Errorf("disallowFree=%v: input %d spends %v, "+
+					"previewed %v", disallowFree, i,
+					txIn.PreviousOutPoint, preview.Inputs[i].OutPoint)
+			}
+		}
+		var totalOut dcrutil.Amount
+		for _, txOut := range tx.TxOut {
+			totalOut += dcrutil.Amount(txOut.Value)
+		}
+		if fee := preview.TotalInput - totalOut; fee != preview.Fee {
+			t.Errorf("disallowFree=%v: created fee %v, previewed %v",
+				disallowFree, fee, preview.Fee)
+		}
+		var change dcrutil.Amount
+		if created.ChangeIndex >= 0 {
+			change = dcrutil.Amount(tx.TxOut[created.ChangeIndex].Value)
+		}
+		if change != preview.Change {
+			t.Errorf("disallowFree=%v: created change %v, previewed %v",
+				disallowFree, change, preview.Change)
+		}
+	}
+}
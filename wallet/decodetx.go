@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+)
+
+// DecodedInput describes an input of a decoded transaction.  Mine is set when
+// the input spends an output credited to the wallet.
+type DecodedInput struct {
+	Mine bool
+}
+
+// DecodedTx describes a transaction which does not need to be saved by the
+// wallet.  Inputs and Outputs describe the wallet's relationship to each input
+// and output script, and Stake decodes the ticket commitments, vote, or
+// revocation of stake transactions.
+type DecodedTx struct {
+	Hash    chainhash.Hash
+	Type    stake.TxType
+	Inputs  []DecodedInput
+	Outputs []*ScriptDescription
+	Stake   *StakeDetails
+}
+
+// DecodeTransaction decodes a transaction, identifying the stake structures
+// of tickets, votes, and revocations, and annotates which of its inputs and
+// outputs belong to the wallet.
+func (w *Wallet) DecodeTransaction(tx *wire.MsgTx) (*DecodedTx, error) {
+	txType := stake.DetermineTxType(dcrutil.NewTx(tx))
+	decoded := &DecodedTx{
+		Hash:    tx.TxSha(),
+		Type:    txType,
+		Inputs:  make([]DecodedInput, len(tx.TxIn)),
+		Outputs: make([]*ScriptDescription, len(tx.TxOut)),
+	}
+
+	for i, txIn := range tx.TxIn {
+		// The stakebase input of a vote does not spend an output.
+		if txType == stake.TxTypeSSGen && i == 0 {
+			continue
+		}
+		op := &txIn.PreviousOutPoint
+		details, err := w.TxStore.TxDetails(&op.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if details == nil {
+			continue
+		}
+		for _, cred := range details.Credits {
+			if cred.Index == op.Index {
+				decoded.Inputs[i].Mine = true
+				break
+			}
+		}
+	}
+
+	for i, txOut := range tx.TxOut {
+		desc, err := w.DescribeScript(txOut.Version, txOut.PkScript)
+		if err != nil {
+			return nil, err
+		}
+		decoded.Outputs[i] = desc
+	}
+
+	stakeDetails, err := w.stakeDetails(tx, txType)
+	if err != nil {
+		return nil, err
+	}
+	decoded.Stake = stakeDetails
+
+	return decoded, nil
+}
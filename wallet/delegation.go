@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// DelegatedTicket is a ticket whose voting rights are delegated to another
+// wallet, together with the private key of the ticket's voting address.
+// BlockHeight is the height of the block mining the ticket, or -1 if the
+// ticket is not yet mined.
+type DelegatedTicket struct {
+	Ticket      *wire.MsgTx
+	BlockHeight int32
+	VotingKey   *dcrutil.WIF
+}
+
+// ticketVotingAddress returns the address which must sign the votes and
+// revocation of a ticket.
+func (w *Wallet) ticketVotingAddress(ticket *wire.MsgTx) (dcrutil.Address,
+	error) {
+	if is, err := stake.IsSStx(dcrutil.NewTx(ticket)); !is {
+		return nil, fmt.Errorf("transaction %v is not a ticket: %v",
+			ticket.TxSha(), err)
+	}
+	txOut := ticket.TxOut[0]
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.Version,
+		txOut.PkScript, w.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) != 1 {
+		return nil, fmt.Errorf("ticket %v has no single voting address",
+			ticket.TxSha())
+	}
+	return addrs[0], nil
+}
+
+// ExportVotingDelegation exports the voting rights of the passed tickets, or
+// of every ticket of the wallet which may still vote if no tickets are
+// passed, so they may be voted by a wallet running in voting-only mode.  The
+// wallet must be unlocked to export the voting keys.
+func (w *Wallet) ExportVotingDelegation(tickets []*chainhash.Hash) (
+	[]*DelegatedTicket, error) {
+	if len(tickets) == 0 {
+		hashes, err := w.StakeMgr.DumpSStxHashes()
+		if err != nil {
+			return nil, err
+		}
+		for i := range hashes {
+			status, err := w.StakeMgr.TicketStatus(&hashes[i])
+			if err != nil {
+				return nil, err
+			}
+			if status > wstakemgr.TicketStatusLive {
+				continue
+			}
+			tickets = append(tickets, &hashes[i])
+		}
+	}
+
+	delegation := make([]*DelegatedTicket, 0, len(tickets))
+	for _, hash := range tickets {
+		details, err := w.TxStore.TxDetails(hash)
+		if err != nil {
+			return nil, err
+		}
+		if details == nil {
+			return nil, fmt.Errorf("ticket %v not found", hash)
+		}
+		addr, err := w.ticketVotingAddress(&details.MsgTx)
+		if err != nil {
+			return nil, err
+		}
+		wif, err := w.DumpWIFPrivateKey(addr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to export voting key of "+
+				"ticket %v: %v", hash, err)
+		}
+		key, err := dcrutil.DecodeWIF(wif)
+		if err != nil {
+			return nil, err
+		}
+		delegation = append(delegation, &DelegatedTicket{
+			Ticket:      &details.MsgTx,
+			BlockHeight: details.Block.Height,
+			VotingKey:   key,
+		})
+	}
+
+	return delegation, nil
+}
+
+// ImportVotingDelegation imports the voting keys of delegated tickets and
+// records the tickets in the stake store, so they are voted or revoked when
+// called by the network.  The chain server is asked to notify the wallet of
+// further transactions involving the voting addresses.
+func (w *Wallet) ImportVotingDelegation(delegation []*DelegatedTicket) error {
+	bs := w.Manager.SyncedTo()
+	addrs := make([]dcrutil.Address, 0, len(delegation))
+	for _, d := range delegation {
+		hash := d.Ticket.TxSha()
+		addr, err := w.ticketVotingAddress(d.Ticket)
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Manager.Address(addr); err != nil {
+			_, err := w.ImportPrivateKey(d.VotingKey, nil, false)
+			if err != nil && !waddrmgr.IsError(err,
+				waddrmgr.ErrDuplicateAddress) {
+				return err
+			}
+			if _, err := w.Manager.Address(addr); err != nil {
+				return fmt.Errorf("voting key of ticket %v does "+
+					"not match voting address %v", hash, addr)
+			}
+		}
+		addrs = append(addrs, addr)
+
+		if !w.StakeMgr.CheckHashInStore(&hash) {
+			err := w.StakeMgr.InsertSStx(dcrutil.NewTx(d.Ticket))
+			if err != nil {
+				return err
+			}
+		}
+		if d.BlockHeight >= 0 {
+			w.updateTicketStatus(&hash, wstakemgr.TicketStatusImmature,
+				d.BlockHeight)
+		} else {
+			w.updateTicketStatus(&hash, wstakemgr.TicketStatusUnmined,
+				bs.Height)
+		}
+		log.Infof("Imported voting rights of ticket %v", hash)
+	}
+
+	// Advance the imported tickets to their status at the current height.
+	if _, err := w.StakeMgr.ConnectTicketStatuses(bs.Height); err != nil {
+		return err
+	}
+
+	return w.chainSvr.NotifyReceived(addrs)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// ScriptDescription describes an output script and the wallet's relationship
+// to it.  StakeSubClass is the class of the script tagged by a stake opcode,
+// and is NonStandardTy for scripts which are not stake outputs.  Addresses
+// are the addresses of the keys and script hashes involved in the script.
+//
+// WalletKeys is the number of the involved addresses managed by the wallet,
+// and Controlled is whether the wallet holds enough private keys to spend
+// outputs paying to the script.  For pay-to-script-hash scripts whose redeem
+// script is known, RedeemScript is set and both describe the redeem script.
+type ScriptDescription struct {
+	Script        []byte
+	Class         txscript.ScriptClass
+	StakeSubClass txscript.ScriptClass
+	RequiredSigs  int
+	Addresses     []dcrutil.Address
+	WalletKeys    int
+	Controlled    bool
+	RedeemScript  []byte
+}
+
+// DescribeScript decodes an output script of a script version and describes
+// it.  Scripts which can not be decoded are described as non-standard.
+func (w *Wallet) DescribeScript(version uint16,
+	pkScript []byte) (*ScriptDescription, error) {
+	desc := &ScriptDescription{
+		Script:        pkScript,
+		Class:         txscript.NonStandardTy,
+		StakeSubClass: txscript.NonStandardTy,
+	}
+	class, addrs, reqSigs, err := txscript.ExtractPkScriptAddrs(version,
+		pkScript, w.chainParams)
+	if err != nil {
+		return desc, nil
+	}
+	desc.Class = class
+	desc.RequiredSigs = reqSigs
+	desc.Addresses = addrs
+
+	switch class {
+	case txscript.StakeSubmissionTy, txscript.StakeSubChangeTy,
+		txscript.StakeGenTy, txscript.StakeRevocationTy:
+		class, err = txscript.GetStakeOutSubclass(pkScript)
+		if err != nil {
+			return desc, nil
+		}
+		desc.StakeSubClass = class
+	}
+
+	if class == txscript.ScriptHashTy && len(addrs) == 1 {
+		redeemScript, err := w.redeemScript(addrs[0])
+		if err != nil {
+			return nil, err
+		}
+		if redeemScript != nil {
+			redeem, err := w.DescribeScript(txscript.DefaultScriptVersion,
+				redeemScript)
+			if err != nil {
+				return nil, err
+			}
+			desc.WalletKeys = redeem.WalletKeys
+			desc.Controlled = redeem.Controlled
+			desc.RedeemScript = redeemScript
+			return desc, nil
+		}
+	}
+
+	for _, addr := range addrs {
+		_, err := w.Manager.Address(addr)
+		if err == nil {
+			desc.WalletKeys++
+			continue
+		}
+		if !waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			return nil, err
+		}
+	}
+	desc.Controlled = class != txscript.NullDataTy &&
+		class != txscript.ScriptHashTy && reqSigs > 0 &&
+		desc.WalletKeys >= reqSigs && !w.Manager.WatchingOnly()
+	return desc, nil
+}
+
+// DescribeAddress describes the output script paying to an address.
+func (w *Wallet) DescribeAddress(addr dcrutil.Address) (*ScriptDescription, error) {
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	return w.DescribeScript(txscript.DefaultScriptVersion, pkScript)
+}
+
+// redeemScript returns the redeem script of a pay-to-script-hash address, or
+// nil if the script is not known to the wallet.  Scripts imported to the
+// address manager are only available when it is unlocked.
+func (w *Wallet) redeemScript(addr dcrutil.Address) ([]byte, error) {
+	script, err := w.TxStore.GetTxScript(addr.ScriptAddress())
+	if err != nil || script != nil {
+		return script, err
+	}
+	ma, err := w.Manager.Address(addr)
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sa, ok := ma.(waddrmgr.ManagedScriptAddress)
+	if !ok {
+		return nil, nil
+	}
+	script, err = sa.Script()
+	if err != nil {
+		return nil, nil
+	}
+	return script, nil
+}
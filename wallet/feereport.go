@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// FeeReportCategory totals the fees paid by one kind of transaction.
+type FeeReportCategory struct {
+	Count int
+	Fees  dcrutil.Amount
+}
+
+// FeeReport summarizes the fees paid by the wallet's transactions.  Unknown
+// counts the transactions spending wallet funds whose fee could not be
+// determined because some inputs are not controlled by the wallet.
+type FeeReport struct {
+	Regular     FeeReportCategory
+	Tickets     FeeReportCategory
+	Votes       FeeReportCategory
+	Revocations FeeReportCategory
+	Unknown     int
+	Total       dcrutil.Amount
+}
+
+// transactionFee returns the fee paid by a transaction, or false if the fee
+// can not be determined from the wallet's records.  The value of every input
+// must be known, either because it spends a wallet credit or because it is
+// the stakebase input of a vote, whose value is the vote subsidy.
+func transactionFee(details *wtxmgr.TxDetails) (dcrutil.Amount, bool) {
+	debits := make(map[uint32]dcrutil.Amount, len(details.Debits))
+	for _, debit := range details.Debits {
+		debits[debit.Index] = debit.Amount
+	}
+
+	var inputs dcrutil.Amount
+	for i, txIn := range details.MsgTx.TxIn {
+		amount, ok := debits[uint32(i)]
+		switch {
+		case ok:
+			inputs += amount
+		case i == 0 && details.TxType == stake.TxTypeSSGen:
+			inputs += dcrutil.Amount(txIn.ValueIn)
+		default:
+			return 0, false
+		}
+	}
+
+	var outputs dcrutil.Amount
+	for _, txOut := range details.MsgTx.TxOut {
+		outputs += dcrutil.Amount(txOut.Value)
+	}
+	return inputs - outputs, true
+}
+
+// FeeReport totals the fees paid by the wallet's transactions between
+// startTime (inclusive) and endTime (exclusive), grouped by the kind of
+// transaction.  The block time of mined transactions, or the time unmined
+// transactions were received, is compared against the range.  Zero times do
+// not bound the range.  Only transactions which spend wallet funds are
+// included, and the fees implied by votes are the vote subsidy and ticket
+// value which are not returned by the vote's outputs.
+func (w *Wallet) FeeReport(startTime, endTime time.Time) (*FeeReport, error) {
+	report := new(FeeReport)
+	err := w.TxStore.RangeTransactions(0, -1, func(details []wtxmgr.TxDetails) (bool, error) {
+		for i := range details {
+			detail := &details[i]
+			if len(detail.Debits) == 0 {
+				continue
+			}
+
+			t := detail.Received
+			if detail.Block.Height != -1 {
+				t = detail.Block.Time
+			}
+			if !startTime.IsZero() && t.Before(startTime) {
+				continue
+			}
+			if !endTime.IsZero() && !t.Before(endTime) {
+				continue
+			}
+
+			fee, ok := transactionFee(detail)
+			if !ok {
+				report.Unknown++
+				continue
+			}
+			var category *FeeReportCategory
+			switch detail.TxType {
+			case stake.TxTypeSStx:
+				category = &report.Tickets
+			case stake.TxTypeSSGen:
+				category = &report.Votes
+			case stake.TxTypeSSRtx:
+				category = &report.Revocations
+			default:
+				category = &report.Regular
+			}
+			category.Count++
+			category.Fees += fee
+			report.Total += fee
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
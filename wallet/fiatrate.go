@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+const (
+	// fiatRateTimeout is the maximum time spent fetching a rate from the
+	// price source.
+	fiatRateTimeout = 30 * time.Second
+
+	// fiatRateCacheTime is the time a fetched rate is reused for stamping
+	// further transactions before it is fetched again.
+	fiatRateCacheTime = time.Minute
+
+	// fiatRateMaxBlockAge is the maximum age of the block of a mined
+	// transaction seen for the first time for it to be stamped with the
+	// current rate.  Older transactions, such as those found by a rescan,
+	// are not stamped, as the current rate does not describe them.
+	fiatRateMaxBlockAge = time.Hour
+)
+
+// FiatRateSource describes where the fiat exchange rates stamped on
+// transactions are fetched from.  URL must respond with JSON holding the rate
+// of a coin in Currency, either as the whole response or as the value of a key
+// matching the currency code in any object nested in the response, so sources
+// responding with objects such as {"decred":{"usd":12.34}} are understood.
+// Rates may be numbers or strings.  Occurrences of {currency} in the URL are
+// replaced with the lowercase currency code.
+type FiatRateSource struct {
+	URL      string
+	Currency string
+}
+
+// SetFiatRateSource sets the source of fiat exchange rates stamped on new
+// transactions.  A nil source disables stamping.
+func (w *Wallet) SetFiatRateSource(src *FiatRateSource) {
+	w.fiatRateMu.Lock()
+	if src != nil {
+		src = &FiatRateSource{
+			URL:      src.URL,
+			Currency: strings.ToUpper(src.Currency),
+		}
+	}
+	w.fiatRateSource = src
+	w.fiatRate = nil
+	w.fiatRateMu.Unlock()
+}
+
+// findFiatRate searches a decoded JSON response of a price source for the rate
+// of the currency.  Keys of nested objects are searched in order so the result
+// does not depend on map iteration order.
+func findFiatRate(v interface{}, currency string) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		rate, err := strconv.ParseFloat(v, 64)
+		return rate, err == nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !strings.EqualFold(k, currency) {
+				continue
+			}
+			switch v[k].(type) {
+			case float64, string:
+				return findFiatRate(v[k], currency)
+			}
+		}
+		for _, k := range keys {
+			if _, ok := v[k].(map[string]interface{}); !ok {
+				continue
+			}
+			rate, ok := findFiatRate(v[k], currency)
+			if ok {
+				return rate, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// fetchFiatRate fetches the current rate from a price source.
+func fetchFiatRate(src *FiatRateSource) (*wtxmgr.FiatRate, error) {
+	url := strings.Replace(src.URL, "{currency}",
+		strings.ToLower(src.Currency), -1)
+	client := http.Client{Timeout: fiatRateTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("price source responded %v", resp.Status)
+	}
+	var v interface{}
+	err = json.NewDecoder(resp.Body).Decode(&v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode price source response: %v",
+			err)
+	}
+	rate, ok := findFiatRate(v, src.Currency)
+	if !ok || rate <= 0 {
+		return nil, fmt.Errorf("price source response holds no %s rate",
+			src.Currency)
+	}
+	return &wtxmgr.FiatRate{
+		Currency: src.Currency,
+		Rate:     rate,
+		Time:     time.Now(),
+	}, nil
+}
+
+// currentFiatRate returns the current fiat rate, fetching it from the price
+// source unless it was fetched recently.  A nil rate is returned when no price
+// source is set.
+func (w *Wallet) currentFiatRate() (*wtxmgr.FiatRate, error) {
+	w.fiatRateMu.Lock()
+	defer w.fiatRateMu.Unlock()
+
+	src := w.fiatRateSource
+	if src == nil {
+		return nil, nil
+	}
+	if w.fiatRate != nil && time.Since(w.fiatRate.Time) < fiatRateCacheTime {
+		return w.fiatRate, nil
+	}
+	rate, err := fetchFiatRate(src)
+	if err != nil {
+		return nil, err
+	}
+	w.fiatRate = rate
+	return rate, nil
+}
+
+// stampFiatRate records the current fiat rate for a transaction the first time
+// it is seen.  Mined transactions are only stamped when their block is recent.
+// The rate is fetched and recorded in another goroutine, and failures are
+// logged, as stamping is best effort and must not delay processing the
+// transaction.
+func (w *Wallet) stampFiatRate(txHash *chainhash.Hash, block *wtxmgr.BlockMeta) {
+	w.fiatRateMu.Lock()
+	enabled := w.fiatRateSource != nil
+	w.fiatRateMu.Unlock()
+	if !enabled {
+		return
+	}
+	if block != nil && time.Since(block.Time) > fiatRateMaxBlockAge {
+		return
+	}
+
+	hash := *txHash
+	go func() {
+		err := w.recordFiatRate(&hash)
+		if err != nil {
+			log.Warnf("Cannot stamp transaction %v with a fiat "+
+				"rate: %v", &hash, err)
+		}
+	}()
+}
+
+func (w *Wallet) recordFiatRate(txHash *chainhash.Hash) error {
+	known, err := w.TxStore.FiatRate(txHash)
+	if err != nil || known != nil {
+		return err
+	}
+	rate, err := w.currentFiatRate()
+	if err != nil || rate == nil {
+		return err
+	}
+	recorded, err := w.TxStore.RecordFiatRate(txHash, rate)
+	if err != nil {
+		return err
+	}
+	if recorded {
+		log.Debugf("Stamped transaction %v with fiat rate %v %s",
+			txHash, rate.Rate, rate.Currency)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package wallet
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFindFiatRate(t *testing.T) {
+	tests := []struct {
+		response string
+		currency string
+		rate     float64
+		ok       bool
+	}{
+		{`12.5`, "USD", 12.5, true},
+		{`"12.5"`, "USD", 12.5, true},
+		{`{"decred":{"usd":12.5}}`, "USD", 12.5, true},
+		{`{"USD":"12.5","EUR":"11"}`, "EUR", 11, true},
+		{`{"data":{"rates":{"usd":12.5}},"usd":{"bad":true}}`, "USD", 12.5, true},
+		{`{"decred":{"eur":11}}`, "USD", 0, false},
+		{`{"decred":{"usd":"price"}}`, "USD", 0, false},
+		{`[12.5]`, "USD", 0, false},
+	}
+	for _, test := range tests {
+		var v interface{}
+		err := json.Unmarshal([]byte(test.response), &v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rate, ok := findFiatRate(v, test.currency)
+		if ok != test.ok || (ok && rate != test.rate) {
+			t.Errorf("findFiatRate(%s, %q) = %v, %v, want %v, %v",
+				test.response, test.currency, rate, ok, test.rate,
+				test.ok)
+		}
+	}
+}
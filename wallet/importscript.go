@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"math"
+
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// ImportedScript describes a redeem script imported by ImportScript.
+// Address is the P2SH address of the script, and ScriptAddresses are the
+// addresses the script pays to.  Outputs are the unspent outputs to the
+// script address found in the wallet after rescanning.
+type ImportedScript struct {
+	Address         dcrutil.Address
+	ScriptAddresses []dcrutil.Address
+	Outputs         []UnspentOutput
+}
+
+// ImportScript imports a redeem script, adding it to the scripts watched by
+// the transaction store and its P2SH address to the address manager, and
+// rescans the main chain from the block at height firstSeen, which should be
+// the height the script was first used at, through the best block for outputs
+// to the script address.  Unlike rescanning from the genesis block, only the
+// blocks which may include transactions for the script are rescanned.  The
+// rescan is waited on so the outputs it discovered can be reported.  Scripts
+// which were already imported are rescanned again.
+func (w *Wallet) ImportScript(script []byte,
+	firstSeen int32) (*ImportedScript, error) {
+	if len(script) == 0 {
+		return nil, errors.New("passed empty script")
+	}
+
+	bs, err := w.chainSvr.BlockStamp()
+	if err != nil {
+		return nil, err
+	}
+	if firstSeen < 0 || firstSeen > bs.Height {
+		return nil, errors.New("first seen height is not in the main chain")
+	}
+	firstSeenHash, err := w.chainSvr.GetBlockHash(int64(firstSeen))
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.TxStore.InsertTxScript(script)
+	if err != nil {
+		return nil, err
+	}
+
+	var addr dcrutil.Address
+	managedAddr, err := w.Manager.ImportScript(script, bs)
+	switch {
+	case err == nil:
+		addr = managedAddr.Address()
+		log.Infof("Redeem script hash %x (address %v) successfully added.",
+			addr.ScriptAddress(), addr.EncodeAddress())
+	case waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress):
+		addr, err = dcrutil.NewAddressScriptHash(script, w.chainParams)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	job := &RescanJob{
+		Addrs: []dcrutil.Address{addr},
+		BlockStamp: waddrmgr.BlockStamp{
+			Height: firstSeen,
+			Hash:   *firstSeenHash,
+		},
+	}
+	err = <-w.SubmitRescan(job)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := &ImportedScript{Address: addr}
+	_, imported.ScriptAddresses, _, _ = txscript.ExtractPkScriptAddrs(
+		txscript.DefaultScriptVersion, script, w.chainParams)
+	imported.Outputs, err = w.ListUnspentFiltered(&UnspentFilter{
+		MinConf:              0,
+		MaxConf:              math.MaxInt32,
+		Addresses:            map[string]struct{}{addr.EncodeAddress(): {}},
+		IncludeImmatureStake: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return imported, nil
+}
@@ -0,0 +1,489 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// keyRotationMaxInputs is the maximum number of outputs swept by a single
+// key rotation.  Remaining outputs are skipped, and are swept by rotating the
+// key again.
+const keyRotationMaxInputs = 400
+
+// ErrNotImportedAddress describes an error where an address passed to
+// RotateImportedAddress is not an imported key or script.
+var ErrNotImportedAddress = errors.New("address is not an imported key " +
+	"or script")
+
+// KeyRotation describes the rotation of an imported key or script.  The
+// unspent outputs paid to the imported address are swept by a single
+// transaction to a new internal address of a wallet account, after which the
+// output script of the address is retired.
+//
+// Inputs are the outputs swept and Amount their total value, of which Fee is
+// paid to the miner.  Outputs which are immature, locked, unconfirmed, too
+// small to pay for their own sweep, or paid to a script the wallet can not
+// fully sign for are not swept, and are counted by SkippedCount and Skipped.
+// The address is only retired when no outputs were skipped, so a partial
+// rotation must be repeated.  Destination and SweepTx are only set when the
+// rotation was executed and there were outputs to sweep.
+type KeyRotation struct {
+	Address      dcrutil.Address
+	PkScript     []byte
+	Inputs       []wtxmgr.Credit
+	Amount       dcrutil.Amount
+	Fee          dcrutil.Amount
+	SkippedCount int
+	Skipped      dcrutil.Amount
+	Destination  dcrutil.Address
+	SweepTx      *chainhash.Hash
+	Retired      bool
+}
+
+// RotateImportedAddress sweeps the funds controlled by the imported key or
+// script of addr to a new internal address of account, and retires the
+// output script of addr so that later payments to it are reported.  This is
+// the response to a suspected exposure of an imported private key.  Funds
+// paid to an imported script are only swept if the wallet holds enough keys
+// to fully sign for the script.  The output script is not retired while
+// outputs paid to it are skipped.
+//
+// Unless execute is set, nothing is signed, published, or retired, and the
+// returned rotation describes the sweep which would be made.  Previews do not
+// require the wallet to be unlocked.  The sweep is signed for origin.
+func (w *Wallet) RotateImportedAddress(addr dcrutil.Address, account uint32,
+	execute bool, origin string) (*KeyRotation, error) {
+
+	if w.votingOnly {
+		return nil, ErrVotingOnly
+	}
+	if account == waddrmgr.ImportedAddrAccount {
+		return nil, errors.New("funds of imported keys may not be swept " +
+			"to the imported account")
+	}
+	if _, err := w.Manager.AccountName(account); err != nil {
+		return nil, err
+	}
+
+	ma, err := w.Manager.Address(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !ma.Imported() {
+		return nil, ErrNotImportedAddress
+	}
+	var multisig bool
+	switch ma.(type) {
+	case waddrmgr.ManagedPubKeyAddress:
+	case waddrmgr.ManagedScriptAddress:
+		multisig = true
+	default:
+		return nil, ErrNotImportedAddress
+	}
+	addr = ma.Address()
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.chainReorganizing() {
+		return nil, ErrBlockchainReorganizing
+	}
+	bs, err := w.chainBlockStamp()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &KeyRotation{Address: addr, PkScript: pkScript}
+	var redeemScript []byte
+	var sigScriptSize int
+	if multisig {
+		redeemScript, sigScriptSize, err = w.multisigRotationInputs(r, addr,
+			bs)
+	} else {
+		err = w.pubKeyRotationInputs(r, bs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Outputs which do not pay for the size they add to the sweep are
+	// left unswept.
+	feeIncrement := w.FeeIncrement()
+	if len(r.Inputs) != 0 {
+		sz := estimateTxSize(len(r.Inputs), 1) + sigScriptSize
+		r.Fee = feeForSize(feeIncrement, sz)
+		if r.Amount <= r.Fee {
+			r.SkippedCount += len(r.Inputs)
+			r.Skipped += r.Amount
+			r.Inputs = nil
+			r.Amount = 0
+			r.Fee = 0
+		}
+	}
+	if len(r.Inputs) != 0 {
+		if err := w.checkFeeLimit(r.Fee, r.Amount-r.Fee); err != nil {
+			return nil, err
+		}
+	}
+	if !execute {
+		return r, nil
+	}
+
+	if len(r.Inputs) != 0 {
+		// Lock the inputs while the sweep is built so that transactions
+		// created meanwhile do not spend them.
+		for i := range r.Inputs {
+			w.LockOutpoint(r.Inputs[i].OutPoint)
+		}
+		err = w.sweepRotatedInputs(r, account, redeemScript, feeIncrement,
+			origin)
+		for i := range r.Inputs {
+			w.UnlockOutpoint(r.Inputs[i].OutPoint)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Retiring the script while outputs paid to it are unswept would hide
+	// that the funds are still controlled by the exposed key.
+	if r.SkippedCount != 0 {
+		log.Warnf("Partially rotated imported address %v: %d outputs "+
+			"totaling %v were skipped; rotate it again once they are "+
+			"spendable", addr, r.SkippedCount, r.Skipped)
+		return r, nil
+	}
+
+	retired := &wtxmgr.RetiredScript{
+		PkScript: pkScript,
+		Time:     time.Now(),
+	}
+	if r.SweepTx != nil {
+		retired.SweepTx = *r.SweepTx
+	}
+	if err := w.TxStore.RetireScript(retired); err != nil {
+		return nil, err
+	}
+	r.Retired = true
+
+	log.Infof("Retired imported address %v", addr)
+	return r, nil
+}
+
+// pubKeyRotationInputs adds the spendable outputs paid to the P2PKH output
+// script of r to the inputs of r, and counts the others as skipped.
+func (w *Wallet) pubKeyRotationInputs(r *KeyRotation,
+	bs *waddrmgr.BlockStamp) error {
+
+	unspent, err := w.TxStore.UnspentOutputs()
+	if err != nil {
+		return err
+	}
+	minconf := w.SpendPolicy().MinConf
+	for _, output := range unspent {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			txscript.DefaultScriptVersion, output.PkScript, w.chainParams)
+		if err != nil || len(addrs) != 1 ||
+			!bytes.Equal(addrs[0].ScriptAddress(), r.Address.ScriptAddress()) {
+			continue
+		}
+
+		// Only regular P2PKH outputs are swept.  Outputs of stake
+		// transactions paying to the key must be spent by the stake
+		// transactions which may spend them.
+		spendable := bytes.Equal(output.PkScript, r.PkScript) &&
+			confirmed(minconf, output.Height, bs.Height) &&
+			!w.LockedOutpoint(output.OutPoint) &&
+			len(r.Inputs) < keyRotationMaxInputs
+		if spendable && output.FromCoinBase {
+			target := int32(w.chainParams.CoinbaseMaturity)
+			spendable = confirmed(target, output.Height, bs.Height)
+		}
+		if !spendable {
+			r.SkippedCount++
+			r.Skipped += output.Amount
+			continue
+		}
+		r.Inputs = append(r.Inputs, *output)
+		r.Amount += output.Amount
+	}
+	return nil
+}
+
+// multisigRotationInputs adds the spendable multisignature outputs paid to the
+// P2SH address addr to the inputs of r, and counts the others as skipped.
+// Outputs are only spendable if the wallet holds enough keys to fully sign for
+// their script.  The redeem script is returned with an estimate of how much
+// the signature scripts of the inputs exceed the size estimated for P2PKH
+// inputs.
+func (w *Wallet) multisigRotationInputs(r *KeyRotation, addr dcrutil.Address,
+	bs *waddrmgr.BlockStamp) ([]byte, int, error) {
+
+	credits, err := w.TxStore.UnspentMultisigCreditsForAddress(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	minconf := w.SpendPolicy().MinConf
+	var redeemScript []byte
+	var sigScriptSize int
+	for _, c := range credits {
+		spendable := len(r.Inputs) < keyRotationMaxInputs &&
+			!w.LockedOutpoint(*c.OutPoint)
+		if spendable {
+			details, err := w.TxStore.TxDetails(&c.OutPoint.Hash)
+			if err != nil {
+				return nil, 0, err
+			}
+			spendable = details != nil &&
+				confirmed(minconf, details.Height(), bs.Height)
+		}
+		if spendable {
+			held, err := w.multisigKeysHeld(c.MSScript)
+			if err != nil {
+				return nil, 0, err
+			}
+			spendable = held >= int(c.M)
+		}
+		if !spendable {
+			r.SkippedCount++
+			r.Skipped += c.Amount
+			continue
+		}
+		redeemScript = c.MSScript
+		r.Inputs = append(r.Inputs, wtxmgr.Credit{
+			OutPoint: *c.OutPoint,
+			Amount:   c.Amount,
+			PkScript: r.PkScript,
+		})
+		r.Amount += c.Amount
+
+		// Each signature script pushes M signatures and the redeem
+		// script rather than a single signature and public key.
+		sigScriptSize += int(c.M)*(1+73+1) + 3 + len(c.MSScript) -
+			sigScriptEstimate
+	}
+	if sigScriptSize < 0 {
+		sigScriptSize = 0
+	}
+	return redeemScript, sigScriptSize, nil
+}
+
+// multisigKeysHeld returns the number of keys of a multisignature script whose
+// private keys are held by the wallet.  While the wallet is locked, the keys
+// of all pubkey addresses of the wallet are counted, as imported public keys
+// can only be told apart from private keys once it is unlocked.
+func (w *Wallet) multisigKeysHeld(script []byte) (int, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+		txscript.DefaultScriptVersion, script, w.chainParams)
+	if err != nil {
+		return 0, err
+	}
+	held := 0
+	for _, addr := range addrs {
+		ma, err := w.Manager.Address(addr)
+		if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		pka, ok := ma.(waddrmgr.ManagedPubKeyAddress)
+		if !ok {
+			continue
+		}
+		_, err = pka.PrivKey()
+		if err != nil && !waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			continue
+		}
+		held++
+	}
+	return held, nil
+}
+
+// sweepRotatedInputs signs and publishes the transaction sweeping the inputs
+// of r to a new internal address of account for origin, setting the
+// destination and transaction hash of r.  The fee of r is increased if the
+// signed transaction is larger than estimated, and the increased fee is
+// checked against the fee limits of the wallet again.
+func (w *Wallet) sweepRotatedInputs(r *KeyRotation, account uint32,
+	redeemScript []byte, feeIncrement dcrutil.Amount, origin string) error {
+
+	release, err := w.holdSigningUnlock()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	r.Destination, err = w.NewChangeAddress(account)
+	if err != nil {
+		return err
+	}
+	pkScript, err := txscript.PayToAddrScript(r.Destination)
+	if err != nil {
+		return fmt.Errorf("cannot create txout script: %s", err)
+	}
+
+	msgtx := wire.NewMsgTx()
+	for i := range r.Inputs {
+		msgtx.AddTxIn(wire.NewTxIn(&r.Inputs[i].OutPoint, nil))
+	}
+	msgtx.AddTxOut(wire.NewTxOut(int64(r.Amount-r.Fee), pkScript))
+
+	for {
+		if redeemScript != nil {
+			err = w.signMultisigInputs(msgtx, r.Inputs, redeemScript)
+		} else {
+			err = w.signMsgTx(msgtx, r.Inputs)
+		}
+		if err != nil {
+			return err
+		}
+		required := feeForSize(feeIncrement, msgtx.SerializeSize())
+		if required <= r.Fee {
+			break
+		}
+		if required >= r.Amount {
+			return fmt.Errorf("swept amount %v does not pay the fee %v",
+				r.Amount, required)
+		}
+		r.Fee = required
+		msgtx.TxOut[0].Value = int64(r.Amount - r.Fee)
+	}
+	if err := w.checkFeeLimit(r.Fee, r.Amount-r.Fee); err != nil {
+		return err
+	}
+
+	if err := validateMsgTx(msgtx, r.Inputs); err != nil {
+		return err
+	}
+	if err := w.checkSigningPolicy(msgtx, origin); err != nil {
+		return err
+	}
+	if err := w.recordSigning(msgtx, origin); err != nil {
+		return err
+	}
+
+	r.SweepTx, err = w.sendRawTransaction(msgtx)
+	if err != nil {
+		return err
+	}
+
+	// Insert the transaction and credits into the transaction manager.
+	rec, err := w.insertIntoTxMgr(msgtx)
+	if err != nil {
+		return err
+	}
+	err = w.insertCreditsIntoTxMgr(msgtx, rec)
+	if err != nil {
+		return err
+	}
+	if redeemScript != nil {
+		for i, txIn := range msgtx.TxIn {
+			err := w.TxStore.SpendMultisigOut(&txIn.PreviousOutPoint,
+				*r.SweepTx, uint32(i))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Infof("Swept %v from imported address %v to %v in transaction %v",
+		r.Amount-r.Fee, r.Address, r.Destination, r.SweepTx)
+	return nil
+}
+
+// signMultisigInputs signs every input of msgtx spending a P2SH output of the
+// multisignature script redeemScript with the keys of the wallet.  An error
+// is returned if the wallet does not hold enough keys to complete the
+// signature scripts.
+func (w *Wallet) signMultisigInputs(msgtx *wire.MsgTx,
+	prevOutputs []wtxmgr.Credit, redeemScript []byte) error {
+
+	getKey := txscript.KeyClosure(func(addr dcrutil.Address) (
+		chainec.PrivateKey, bool, error) {
+		address, err := w.Manager.Address(addr)
+		if err != nil {
+			return nil, false, err
+		}
+		pka, ok := address.(waddrmgr.ManagedPubKeyAddress)
+		if !ok {
+			return nil, false, fmt.Errorf("address %v is not a pubkey "+
+				"address", addr.EncodeAddress())
+		}
+		key, err := pka.PrivKey()
+		if err != nil {
+			return nil, false, err
+		}
+		return key, pka.Compressed(), nil
+	})
+	getScript := txscript.ScriptClosure(func(
+		addr dcrutil.Address) ([]byte, error) {
+		return redeemScript, nil
+	})
+
+	for i, output := range prevOutputs {
+		sigScript, err := txscript.SignTxOutput(w.chainParams, msgtx, i,
+			output.PkScript, txscript.SigHashAll, getKey, getScript,
+			nil, chainec.ECTypeSecp256k1)
+		if err != nil {
+			return err
+		}
+		msgtx.TxIn[i].SignatureScript = sigScript
+
+		vm, err := txscript.NewEngine(output.PkScript, msgtx, i,
+			txscript.StandardVerifyFlags, txscript.DefaultScriptVersion)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			return fmt.Errorf("wallet cannot fully sign for the "+
+				"script of input %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// warnRetiredScript logs a warning when output index of rec pays to the
+// output script of a retired imported key or script, as funds received by it
+// may be stolen by whoever obtained the key.
+func (w *Wallet) warnRetiredScript(rec *wtxmgr.TxRecord, index int) {
+	pkScript := rec.MsgTx.TxOut[index].PkScript
+	r, err := w.TxStore.RetiredScript(pkScript)
+	if err != nil {
+		log.Errorf("Cannot look up retired script: %v", err)
+		return
+	}
+	if r == nil {
+		return
+	}
+	log.Warnf("Transaction %v output %d pays to a script retired on %v; "+
+		"rotate the key again to sweep the funds", &rec.Hash, index,
+		r.Time.Format(time.RFC3339))
+}
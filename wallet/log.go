@@ -23,6 +23,10 @@ import "github.com/btcsuite/btclog"
 // requests it.
 var log btclog.Logger
 
+// tkbyLog is the logger used by the automatic ticket buyer.  It is kept
+// separate from log so ticket purchasing may be logged at its own level.
+var tkbyLog btclog.Logger
+
 // The default amount of logging is none.
 func init() {
 	DisableLog()
@@ -32,6 +36,7 @@ func init() {
 // by default until either UseLogger or SetLogWriter are called.
 func DisableLog() {
 	log = btclog.Disabled
+	tkbyLog = btclog.Disabled
 }
 
 // UseLogger uses a specified Logger to output package logging info.
@@ -41,6 +46,12 @@ func UseLogger(logger btclog.Logger) {
 	log = logger
 }
 
+// UseTicketBuyerLogger uses a specified Logger to output logging info of the
+// automatic ticket buyer.
+func UseTicketBuyerLogger(logger btclog.Logger) {
+	tkbyLog = logger
+}
+
 // LogClosure is a closure that can be printed with %v to be used to
 // generate expensive-to-create data for a detailed log level and avoid doing
 // the work if the data isn't printed.
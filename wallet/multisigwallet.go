@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrutil/hdkeychain"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+var (
+	// ErrNoCosigners describes an error where a multisig wallet was created
+	// without any cosigner keys.
+	ErrNoCosigners = errors.New("multisig wallet has no cosigners")
+
+	// ErrMultisigRequiredSigs describes an error where the number of
+	// signatures required by a multisig wallet is not between one and the
+	// number of cosigners.
+	ErrMultisigRequiredSigs = errors.New("required signatures must be " +
+		"between one and the number of cosigners")
+)
+
+// MultisigCosigner is the key of a cosigner of a multisig wallet.  Exactly
+// one of PubKey, a public key used by every script of the wallet, or XPub, an
+// account extended public key from which a key is derived for each script, is
+// set.
+type MultisigCosigner struct {
+	PubKey *dcrutil.AddressSecpPubKey
+	XPub   *hdkeychain.ExtendedKey
+}
+
+// MultisigScript is a redeem script of a multisig wallet.  Index is the
+// child index of the external branch of each cosigner extended public key
+// used by the script.
+type MultisigScript struct {
+	Index        uint32
+	Address      dcrutil.Address
+	RedeemScript []byte
+}
+
+// MultisigWallet describes the scripts imported by CreateMultisigWallet.
+// Height is the height of the best block when the scripts were imported, which
+// a restored wallet should rescan from.
+type MultisigWallet struct {
+	Scripts []MultisigScript
+	Height  int32
+}
+
+// byPubKey sorts public keys by their serialized bytes.
+type byPubKey []*dcrutil.AddressSecpPubKey
+
+func (s byPubKey) Len() int      { return len(s) }
+func (s byPubKey) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byPubKey) Less(i, j int) bool {
+	return bytes.Compare(s[i].ScriptAddress(), s[j].ScriptAddress()) < 0
+}
+
+// multisigScript returns the redeem script of a multisig wallet at a child
+// index.  The keys are sorted, so cosigners passing their keys in any order
+// create the same scripts.
+func multisigScript(cosigners []MultisigCosigner, nRequired int, index uint32,
+	params *chaincfg.Params) ([]byte, error) {
+	pubKeys := make([]*dcrutil.AddressSecpPubKey, len(cosigners))
+	for i, c := range cosigners {
+		if c.XPub == nil {
+			pubKeys[i] = c.PubKey
+			continue
+		}
+		branchKey, err := c.XPub.Child(waddrmgr.ExternalBranch)
+		if err != nil {
+			return nil, err
+		}
+		child, err := branchKey.Child(index)
+		if err != nil {
+			return nil, fmt.Errorf("cosigner %d has no child %d: %v",
+				i, index, err)
+		}
+		pubKey, err := child.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		pubKeys[i], err = dcrutil.NewAddressSecpPubKey(
+			pubKey.SerializeCompressed(), params)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Sort(byPubKey(pubKeys))
+	return txscript.MultiSigScript(pubKeys, nRequired)
+}
+
+// CreateMultisigWallet sets up a multisig wallet shared by cosigners.  When
+// any cosigner is identified by an extended public key, count scripts are
+// created using the child keys of the external branch at indexes 0 through
+// count-1, and otherwise the single script of the public keys is created.
+// The redeem scripts are saved by the transaction store, their P2SH addresses
+// are imported by the address manager, and the chain server is asked to
+// notify the wallet of transactions paying to them.
+//
+// Creating a multisig wallet again with the same cosigners imports the same
+// scripts, so a wallet may be restored from the keys.  The address manager
+// must be unlocked.
+func (w *Wallet) CreateMultisigWallet(nRequired int,
+	cosigners []MultisigCosigner, count uint32) (*MultisigWallet, error) {
+	if len(cosigners) == 0 {
+		return nil, ErrNoCosigners
+	}
+	if nRequired < 1 || nRequired > len(cosigners) {
+		return nil, ErrMultisigRequiredSigs
+	}
+	extended := false
+	for _, c := range cosigners {
+		if c.XPub != nil {
+			extended = true
+			break
+		}
+	}
+	if !extended {
+		count = 1
+	}
+
+	bs, err := w.chainSvr.BlockStamp()
+	if err != nil {
+		return nil, err
+	}
+
+	mw := &MultisigWallet{
+		Scripts: make([]MultisigScript, 0, count),
+		Height:  bs.Height,
+	}
+	addrs := make([]dcrutil.Address, 0, count)
+	for i := uint32(0); i < count; i++ {
+		script, err := multisigScript(cosigners, nRequired, i,
+			w.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		err = w.TxStore.InsertTxScript(script)
+		if err != nil {
+			return nil, err
+		}
+
+		var addr dcrutil.Address
+		managedAddr, err := w.Manager.ImportScript(script, bs)
+		switch {
+		case err == nil:
+			addr = managedAddr.Address()
+		case waddrmgr.IsError(err, waddrmgr.ErrDuplicateAddress):
+			addr, err = dcrutil.NewAddressScriptHash(script,
+				w.chainParams)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+
+		mw.Scripts = append(mw.Scripts, MultisigScript{
+			Index:        i,
+			Address:      addr,
+			RedeemScript: script,
+		})
+		addrs = append(addrs, addr)
+	}
+
+	err = w.chainSvr.NotifyReceived(addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Imported %d scripts of a %d-of-%d multisig wallet",
+		len(mw.Scripts), nRequired, len(cosigners))
+
+	return mw, nil
+}
@@ -0,0 +1,85 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrutil/hdkeychain"
+)
+
+func TestMultisigScript(t *testing.T) {
+	params := &chaincfg.TestNetParams
+	xpub := func(seedByte byte) *hdkeychain.ExtendedKey {
+		seed := bytes.Repeat([]byte{seedByte}, hdkeychain.RecommendedSeedLen)
+		master, err := hdkeychain.NewMaster(seed, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := master.Neuter()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pub
+	}
+	a := MultisigCosigner{XPub: xpub(1)}
+	b := MultisigCosigner{XPub: xpub(2)}
+
+	// The child key of the external branch of a.
+	branchKey, err := a.XPub.Child(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := branchKey.Child(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixedKey, err := dcrutil.NewAddressSecpPubKey(
+		pubKey.SerializeCompressed(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := MultisigCosigner{PubKey: fixedKey}
+
+	ab, err := multisigScript([]MultisigCosigner{a, b}, 2, 3, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ba, err := multisigScript([]MultisigCosigner{b, a}, 2, 3, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ab, ba) {
+		t.Errorf("scripts differ by key order")
+	}
+	cb, err := multisigScript([]MultisigCosigner{c, b}, 2, 3, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ab, cb) {
+		t.Errorf("derived key does not match the public key")
+	}
+	other, err := multisigScript([]MultisigCosigner{a, b}, 2, 4, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ab, other) {
+		t.Errorf("scripts of different indexes are equal")
+	}
+
+	class, addrs, reqSigs, err := txscript.ExtractPkScriptAddrs(
+		txscript.DefaultScriptVersion, ab, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class != txscript.MultiSigTy || len(addrs) != 2 || reqSigs != 2 {
+		t.Errorf("unexpected script class %v with %d keys requiring "+
+			"%d signatures", class, len(addrs), reqSigs)
+	}
+}
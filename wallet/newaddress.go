@@ -0,0 +1,264 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// DefaultGapLimit is the default number of consecutive unused external
+// addresses of an account.  Restoring a wallet from its seed may not discover
+// addresses following more unused addresses than this, as recommended by
+// BIP0044.
+const DefaultGapLimit = 20
+
+// ErrGapLimit describes an error where a new address was refused because it
+// would follow more consecutive unused addresses than the gap limit.
+var ErrGapLimit = errors.New("new address would exceed the gap limit of " +
+	"unused addresses")
+
+// AddressType selects the kind of address returned by NewAddressWithOptions.
+type AddressType int
+
+// These constants define the address types.
+const (
+	// AddressTypeP2PKH is a pay-to-pubkey-hash address.
+	AddressTypeP2PKH AddressType = iota
+
+	// AddressTypeP2PK is a pay-to-pubkey address of the public key of a
+	// new pubkey hash address.
+	AddressTypeP2PK
+
+	// AddressTypeP2SH is a pay-to-script-hash address of a 1-of-1 multisig
+	// script of the public key of a new pubkey hash address.  The script
+	// is imported, so outputs paying to it are credited to the imported
+	// account.
+	AddressTypeP2SH
+)
+
+// GapPolicy selects how NewAddressWithOptions handles a new address which
+// would exceed the gap limit.
+type GapPolicy int
+
+// These constants define the gap policies.
+const (
+	// GapPolicyIgnore returns the address regardless of the gap limit.
+	GapPolicyIgnore GapPolicy = iota
+
+	// GapPolicyWarn returns the address, reporting that it exceeds the
+	// gap limit.
+	GapPolicyWarn
+
+	// GapPolicyError refuses to derive the address and returns
+	// ErrGapLimit.
+	GapPolicyError
+)
+
+// NewAddressOptions selects the type of an address returned by
+// NewAddressWithOptions and how the gap limit is enforced.
+type NewAddressOptions struct {
+	Type      AddressType
+	GapPolicy GapPolicy
+}
+
+// NewAddressResult describes an address returned by NewAddressWithOptions.
+// Gap is the number of consecutive unused external addresses preceding the
+// derived address, counting at most the gap limit.
+type NewAddressResult struct {
+	Address         dcrutil.Address
+	PubKey          *dcrutil.AddressSecpPubKey
+	Script          []byte
+	Gap             uint32
+	ExceedsGapLimit bool
+}
+
+// nextExternalIndex returns the index of the next external address returned
+// for an account.  Addresses of the default account are handed out by the
+// external address pool, which derives them from the address manager in
+// batches, so the addresses of the pool not yet returned are excluded.  The
+// external address pool mutex must be held for the default account.
+func (w *Wallet) nextExternalIndex(account uint32) (uint32, error) {
+	_, last, err := w.Manager.LastExternalAddress(account)
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	next := last + 1
+	if account == waddrmgr.DefaultAccountNum {
+		pool := w.externalPool
+		pending := uint32(len(pool.addresses) - pool.cursor)
+		if pending >= next {
+			return 0, nil
+		}
+		next -= pending
+	}
+	return next, nil
+}
+
+// unusedAddressGap returns the number of consecutive unused external
+// addresses of an account preceding the address at index next, counting at
+// most limit addresses.
+func (w *Wallet) unusedAddressGap(account, next, limit uint32) (uint32,
+	error) {
+	var gap uint32
+	for i := next; i > 0 && gap < limit; i-- {
+		addr, err := w.Manager.GetAddress(i-1, account,
+			waddrmgr.ExternalBranch)
+		// Skip erroneous keys, which happen rarely.
+		if err != nil {
+			continue
+		}
+		maddr, err := w.Manager.Address(addr)
+		if err != nil {
+			return 0, err
+		}
+		used, err := maddr.Used()
+		if err != nil {
+			return 0, err
+		}
+		if used {
+			break
+		}
+		gap++
+	}
+	return gap, nil
+}
+
+// UnusedAddressGap returns the number of consecutive unused external
+// addresses of an account preceding the next address returned for it,
+// counting at most the gap limit.  A new address exceeds the gap limit when
+// the returned gap equals it.
+func (w *Wallet) UnusedAddressGap(account uint32) (uint32, error) {
+	if account == waddrmgr.DefaultAccountNum {
+		w.externalPool.mutex.Lock()
+		defer w.externalPool.mutex.Unlock()
+	}
+	next, err := w.nextExternalIndex(account)
+	if err != nil {
+		return 0, err
+	}
+	return w.unusedAddressGap(account, next, w.GapLimit)
+}
+
+// NewAddressWithOptions returns a new external address of an account, like
+// NewAddress, of the type selected by opts.  The new address is checked
+// against the gap limit before it is derived, and refused or reported
+// according to the gap policy of opts.
+func (w *Wallet) NewAddressWithOptions(account uint32,
+	opts *NewAddressOptions) (*NewAddressResult, error) {
+	switch opts.Type {
+	case AddressTypeP2PKH, AddressTypeP2PK, AddressTypeP2SH:
+	default:
+		return nil, fmt.Errorf("unknown address type %d", opts.Type)
+	}
+	if account == waddrmgr.ImportedAddrAccount {
+		return nil, fmt.Errorf("addresses can not be derived for the " +
+			"imported account")
+	}
+
+	// The default account hands out addresses from the external address
+	// pool, which is held so the checked address is the one returned.
+	if account == waddrmgr.DefaultAccountNum {
+		w.externalPool.mutex.Lock()
+		defer w.externalPool.mutex.Unlock()
+	}
+	next, err := w.nextExternalIndex(account)
+	if err != nil {
+		return nil, err
+	}
+	gap, err := w.unusedAddressGap(account, next, w.GapLimit)
+	if err != nil {
+		return nil, err
+	}
+	exceeds := gap >= w.GapLimit
+	if exceeds {
+		if opts.GapPolicy == GapPolicyError {
+			return nil, ErrGapLimit
+		}
+		if opts.GapPolicy == GapPolicyWarn {
+			log.Warnf("New address of account %d follows %d unused "+
+				"addresses, exceeding the gap limit", account, gap)
+		}
+	}
+
+	var addr dcrutil.Address
+	if account == waddrmgr.DefaultAccountNum {
+		addr, err = w.externalPool.GetNewAddress()
+	} else {
+		addr, err = w.NewAddress(account)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &NewAddressResult{
+		Address:         addr,
+		Gap:             gap,
+		ExceedsGapLimit: exceeds,
+	}
+
+	ainfo, err := w.Manager.Address(addr)
+	if err != nil {
+		return nil, err
+	}
+	pka, ok := ainfo.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, fmt.Errorf("address %v has no public key", addr)
+	}
+	result.PubKey, err = dcrutil.NewAddressSecpPubKey(
+		pka.PubKey().SerializeCompressed(), w.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.Type {
+	case AddressTypeP2PK:
+		// Outputs paying to the public key are credited to the pubkey
+		// hash address, which is already watched.
+		result.Address = result.PubKey
+
+	case AddressTypeP2SH:
+		script, err := txscript.MultiSigScript(
+			[]*dcrutil.AddressSecpPubKey{result.PubKey}, 1)
+		if err != nil {
+			return nil, err
+		}
+		err = w.TxStore.InsertTxScript(script)
+		if err != nil {
+			return nil, err
+		}
+		bs := w.Manager.SyncedTo()
+		managedAddr, err := w.Manager.ImportScript(script, &bs)
+		if err != nil {
+			return nil, err
+		}
+		result.Address = managedAddr.Address()
+		result.Script = script
+		err = w.notifyReceived([]dcrutil.Address{result.Address})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// ErrOffline indicates that an operation requiring a chain server was
+// requested from a wallet running without one.
+var ErrOffline = errors.New("wallet is offline and not connected to a " +
+	"chain server")
+
+// StartOffline starts the goroutines necessary to manage a wallet that is not
+// connected to a chain server.  Addresses may be derived, balances are
+// reported from the transaction store, and transactions may be created and
+// signed.  Transactions published while offline are recorded as unmined and
+// broadcast by the unmined transaction resender once the wallet is started
+// again with a chain server.
+//
+// A wallet running offline must be stopped before it is started with a chain
+// server.
+func (w *Wallet) StartOffline() {
+	if !w.begin() {
+		return
+	}
+
+	w.chainSvrLock.Lock()
+	w.chainSvr = nil
+	w.chainSvrLock.Unlock()
+
+	w.wg.Add(2)
+	go w.txCreator()
+	go w.walletLocker()
+
+	w.internalPool.initialize(waddrmgr.InternalBranch, w)
+	w.externalPool.initialize(waddrmgr.ExternalBranch, w)
+
+	log.Infof("Wallet is running offline")
+}
+
+// Offline returns whether the wallet is running without a chain server.
+func (w *Wallet) Offline() bool {
+	return w.chainSvr == nil
+}
+
+// chainReorganizing returns whether the chain server is currently
+// reorganizing the blockchain.  An offline wallet never is.
+func (w *Wallet) chainReorganizing() bool {
+	chainSvr := w.chainSvr
+	if chainSvr == nil {
+		return false
+	}
+	isReorganizing, _ := chainSvr.GetReorganizing()
+	return isReorganizing
+}
+
+// chainBlockStamp returns the best block of the chain server, or the block
+// the wallet is synced to when running offline.
+func (w *Wallet) chainBlockStamp() (*waddrmgr.BlockStamp, error) {
+	chainSvr := w.chainSvr
+	if chainSvr == nil {
+		bs := w.Manager.SyncedTo()
+		return &bs, nil
+	}
+	return chainSvr.BlockStamp()
+}
+
+// notifyReceived requests notifications from the chain server for
+// transactions paying to addrs.  Nothing is requested when running offline,
+// as every wallet address is registered when the wallet syncs with the next
+// chain server.
+func (w *Wallet) notifyReceived(addrs []dcrutil.Address) error {
+	chainSvr := w.chainSvr
+	if chainSvr == nil {
+		return nil
+	}
+	return chainSvr.NotifyReceived(addrs)
+}
+
+// sendRawTransaction broadcasts a transaction to the network.  When running
+// offline the transaction is not sent, and is instead broadcast with the
+// other unmined transactions after the wallet syncs with the next chain
+// server.  Callers must record the transaction as unmined in either case.
+//
+// A TxRejectedError is returned if the transaction fails the mempool policy
+// checks or is rejected by the chain server.
+func (w *Wallet) sendRawTransaction(msgTx *wire.MsgTx) (*chainhash.Hash, error) {
+	err := checkTxAcceptance(msgTx, w.Manager.SyncedTo().Height)
+	if err != nil {
+		return nil, err
+	}
+
+	chainSvr := w.chainSvr
+	if chainSvr == nil {
+		hash := msgTx.TxSha()
+		log.Infof("Queued transaction %v for broadcast", hash)
+		return &hash, nil
+	}
+	hash, err := chainSvr.SendRawTransaction(msgTx, false)
+	if err != nil {
+		return nil, rejectionError(err)
+	}
+	return hash, nil
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// singleOriginCredits returns the credits of eligible which share a single
+// origin, so they may be spent together without merging coins of different
+// origins.  The first origin, in the order the origins are defined, whose
+// credits total at least amount is chosen.  When no single origin suffices,
+// the origin with the greatest total is chosen, which leaves transaction
+// creation to report the insufficient funds.
+func singleOriginCredits(eligible []wtxmgr.Credit,
+	amount dcrutil.Amount) []wtxmgr.Credit {
+	totals := make(map[wtxmgr.CreditOrigin]dcrutil.Amount)
+	for i := range eligible {
+		totals[eligible[i].Origin] += eligible[i].Amount
+	}
+
+	var chosen wtxmgr.CreditOrigin
+	var sufficient bool
+	for origin, total := range totals {
+		switch {
+		case total >= amount:
+			if !sufficient || origin < chosen {
+				chosen = origin
+				sufficient = true
+			}
+		case sufficient:
+		case total > totals[chosen],
+			total == totals[chosen] && origin < chosen:
+			chosen = origin
+		}
+	}
+
+	credits := make([]wtxmgr.Credit, 0, len(eligible))
+	for i := range eligible {
+		if eligible[i].Origin == chosen {
+			credits = append(credits, eligible[i])
+		}
+	}
+	return credits
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrutil"
+)
+
+// PaymentURIScheme is the URI scheme of Decred payment requests.
+const PaymentURIScheme = "decred"
+
+// PaymentURI is a request for payment to an address, encoded as a URI like
+// the bitcoin URIs of BIP0021:
+//
+//	decred:<address>?amount=<coins>&label=<label>&message=<message>&expires=<unix time>
+//
+// All parameters are optional.  A zero Amount requests no specific amount,
+// and a zero Expires never expires.
+type PaymentURI struct {
+	Address dcrutil.Address
+	Amount  dcrutil.Amount
+	Label   string
+	Message string
+	Expires time.Time
+}
+
+// String returns the encoded payment URI.
+func (u *PaymentURI) String() string {
+	q := make(url.Values)
+	if u.Amount != 0 {
+		q.Set("amount", formatURIAmount(u.Amount))
+	}
+	if u.Label != "" {
+		q.Set("label", u.Label)
+	}
+	if u.Message != "" {
+		q.Set("message", u.Message)
+	}
+	if !u.Expires.IsZero() {
+		q.Set("expires", strconv.FormatInt(u.Expires.Unix(), 10))
+	}
+	uri := url.URL{
+		Scheme:   PaymentURIScheme,
+		Opaque:   u.Address.EncodeAddress(),
+		RawQuery: strings.Replace(q.Encode(), "+", "%20", -1),
+	}
+	return uri.String()
+}
+
+// Expired returns whether the payment request has expired at time t.
+func (u *PaymentURI) Expired(t time.Time) bool {
+	return !u.Expires.IsZero() && !t.Before(u.Expires)
+}
+
+// ParsePaymentURI decodes a payment URI requesting payment to an address of
+// the network described by params.  Unknown parameters are ignored, except
+// that parameters prefixed with "req-" are required to be understood by
+// BIP0021 and cause the URI to be rejected.
+func ParsePaymentURI(s string, params *chaincfg.Params) (*PaymentURI, error) {
+	uri, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if uri.Scheme != PaymentURIScheme {
+		return nil, fmt.Errorf("URI scheme is not %q", PaymentURIScheme)
+	}
+
+	// Both decred:<address> and decred://<address> are accepted.
+	encodedAddr := uri.Opaque
+	if encodedAddr == "" {
+		encodedAddr = uri.Host
+	}
+	if encodedAddr == "" {
+		return nil, errors.New("URI does not include an address")
+	}
+	addr, err := dcrutil.DecodeAddress(encodedAddr, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %v", encodedAddr, err)
+	}
+	if !addr.IsForNet(params) {
+		return nil, fmt.Errorf("address %v is not intended for use on %s",
+			encodedAddr, params.Name)
+	}
+
+	q, err := url.ParseQuery(uri.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	p := &PaymentURI{Address: addr}
+	for key, values := range q {
+		if len(values) != 1 {
+			return nil, fmt.Errorf("parameter %q is repeated", key)
+		}
+		value := values[0]
+		switch key {
+		case "amount":
+			p.Amount, err = parseURIAmount(value)
+			if err != nil {
+				return nil, err
+			}
+		case "label":
+			p.Label = value
+		case "message":
+			p.Message = value
+		case "expires":
+			expires, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || expires <= 0 {
+				return nil, fmt.Errorf("invalid expiry %q", value)
+			}
+			p.Expires = time.Unix(expires, 0)
+		default:
+			if strings.HasPrefix(key, "req-") {
+				return nil, fmt.Errorf("unsupported required "+
+					"parameter %q", key)
+			}
+		}
+	}
+	return p, nil
+}
+
+// formatURIAmount formats an amount in coins without trailing zeros.
+func formatURIAmount(amount dcrutil.Amount) string {
+	s := fmt.Sprintf("%d.%08d", amount/dcrutil.AtomsPerCoin,
+		amount%dcrutil.AtomsPerCoin)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// parseURIAmount parses a positive decimal amount in coins with at most
+// eight decimal places.  The amount is parsed exactly, without the rounding
+// of floating point numbers.
+func parseURIAmount(s string) (dcrutil.Amount, error) {
+	invalid := fmt.Errorf("invalid amount %q", s)
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" || len(frac) > 8 {
+		return 0, invalid
+	}
+	for _, digits := range []string{whole, frac} {
+		for _, c := range digits {
+			if c < '0' || c > '9' {
+				return 0, invalid
+			}
+		}
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	coins, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || coins > dcrutil.MaxAmount/dcrutil.AtomsPerCoin {
+		return 0, invalid
+	}
+	atoms, _ := strconv.ParseInt(frac+strings.Repeat("0", 8-len(frac)),
+		10, 64)
+	amount := dcrutil.Amount(coins*dcrutil.AtomsPerCoin + atoms)
+	if amount <= 0 || amount > dcrutil.MaxAmount {
+		return 0, invalid
+	}
+	return amount, nil
+}
@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrutil"
+)
+
+func TestPaymentURIRoundTrip(t *testing.T) {
+	params := &chaincfg.TestNetParams
+	addr, err := dcrutil.NewAddressPubKeyHash(bytes.Repeat([]byte{1}, 20),
+		params, chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []PaymentURI{
+		{Address: addr},
+		{Address: addr, Amount: 150000000},
+		{Address: addr, Amount: 1, Label: "Invoice #12",
+			Message: "Order & shipping", Expires: time.Unix(1500000000, 0)},
+	}
+	for i, test := range tests {
+		s := test.String()
+		got, err := ParsePaymentURI(s, params)
+		if err != nil {
+			t.Errorf("test %d: parse %q: %v", i, s, err)
+			continue
+		}
+		if got.Address.EncodeAddress() != addr.EncodeAddress() ||
+			got.Amount != test.Amount || got.Label != test.Label ||
+			got.Message != test.Message || !got.Expires.Equal(test.Expires) {
+			t.Errorf("test %d: %q decoded to %+v", i, s, got)
+		}
+	}
+}
+
+func TestParsePaymentURIErrors(t *testing.T) {
+	params := &chaincfg.TestNetParams
+	addr, err := dcrutil.NewAddressPubKeyHash(bytes.Repeat([]byte{1}, 20),
+		params, chainec.ECTypeSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := addr.EncodeAddress()
+	tests := []string{
+		"bitcoin:" + a,
+		"decred:",
+		"decred:notanaddress",
+		"decred:" + a + "?amount=1.123456789",
+		"decred:" + a + "?amount=-1",
+		"decred:" + a + "?amount=1e3",
+		"decred:" + a + "?amount=1&amount=2",
+		"decred:" + a + "?expires=soon",
+		"decred:" + a + "?req-refund=1",
+	}
+	for _, s := range tests {
+		if _, err := ParsePaymentURI(s, params); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+
+	// Addresses of other networks are rejected.
+	if _, err := ParsePaymentURI("decred:"+a, &chaincfg.MainNetParams); err == nil {
+		t.Errorf("accepted a testnet address on mainnet")
+	}
+
+	// Unknown optional parameters are ignored.
+	if _, err := ParsePaymentURI("decred:"+a+"?foo=bar", params); err != nil {
+		t.Errorf("rejected an unknown optional parameter: %v", err)
+	}
+}
+
+func TestParseURIAmount(t *testing.T) {
+	tests := []struct {
+		s    string
+		want dcrutil.Amount
+	}{
+		{"1", 100000000},
+		{"1.5", 150000000},
+		{".5", 50000000},
+		{"0.00000001", 1},
+		{"21000000", 21000000 * 100000000},
+	}
+	for _, test := range tests {
+		got, err := parseURIAmount(test.s)
+		if err != nil || got != test.want {
+			t.Errorf("%q: got %v, %v, want %v", test.s, got, err,
+				test.want)
+		}
+		if s := formatURIAmount(got); s != test.s && "0"+test.s != s {
+			t.Errorf("%v formatted as %q", got, s)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"math"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg"
+)
+
+// TicketPoolShare describes the wallet's share of the live ticket pool and
+// the statistical expectation of its tickets being called to vote, assuming
+// the pool size and the wallet's live tickets do not change.
+type TicketPoolShare struct {
+	Height      int32
+	LiveTickets int64   // Live tickets of the wallet
+	PoolSize    int64   // Live tickets of the network
+	Share       float64 // Fraction of the live ticket pool owned
+
+	// VoteProbability is the probability that at least one ticket of the
+	// wallet is called to vote in each block, and ExpectedBlocks and
+	// ExpectedTime are the expected number of blocks and time until the
+	// next vote.  ExpectedBlocks is zero when the wallet has no live
+	// tickets.
+	VoteProbability float64
+	ExpectedBlocks  float64
+	ExpectedTime    time.Duration
+
+	// WithinProbability is the probability that at least one ticket of
+	// the wallet is called to vote within the blocks of Within.
+	Within            time.Duration
+	WithinProbability float64
+}
+
+// blockVoteProbability returns the probability that at least one of owned
+// live tickets is called to vote by a block, when the block calls
+// TicketsPerBlock tickets out of a live ticket pool of poolSize tickets
+// without replacement.
+func blockVoteProbability(params *chaincfg.Params, poolSize, owned int64) float64 {
+	if owned <= 0 || poolSize <= 0 {
+		return 0
+	}
+	if owned > poolSize {
+		owned = poolSize
+	}
+	none := 1.0
+	for i := int64(0); i < int64(params.TicketsPerBlock); i++ {
+		if poolSize-i <= 0 {
+			break
+		}
+		none *= float64(poolSize-owned-i) / float64(poolSize-i)
+		if none <= 0 {
+			return 1
+		}
+	}
+	return 1 - none
+}
+
+// voteWithinProbability returns the probability that at least one vote occurs
+// within blocks blocks, when a vote occurs in each block with probability
+// perBlock.
+func voteWithinProbability(perBlock float64, blocks int64) float64 {
+	if perBlock <= 0 || blocks <= 0 {
+		return 0
+	}
+	return 1 - math.Pow(1-perBlock, float64(blocks))
+}
+
+// TicketPoolShare returns the wallet's share of the live ticket pool after the
+// best block and the expected time until one of its live tickets is called
+// to vote, along with the probability of a vote within the passed duration.
+func (w *Wallet) TicketPoolShare(within time.Duration) (*TicketPoolShare, error) {
+	summary, err := w.StakeMgr.TicketSummary()
+	if err != nil {
+		return nil, err
+	}
+	bs := w.Manager.SyncedTo()
+	poolSize, err := w.ticketPoolSize(&bs.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	params := w.chainParams
+	share := &TicketPoolShare{
+		Height:      bs.Height,
+		LiveTickets: int64(summary.Live),
+		PoolSize:    poolSize,
+		Within:      within,
+	}
+	if poolSize > 0 {
+		share.Share = float64(share.LiveTickets) / float64(poolSize)
+	}
+	share.VoteProbability = blockVoteProbability(params, poolSize,
+		share.LiveTickets)
+	if share.VoteProbability > 0 {
+		share.ExpectedBlocks = 1 / share.VoteProbability
+		share.ExpectedTime = time.Duration(share.ExpectedBlocks *
+			float64(params.TargetTimePerBlock))
+	}
+	blocks := int64(within / params.TargetTimePerBlock)
+	share.WithinProbability = voteWithinProbability(share.VoteProbability,
+		blocks)
+
+	return share, nil
+}
@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"math"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+)
+
+func TestBlockVoteProbability(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	perBlock := int64(params.TicketsPerBlock)
+	tests := []struct {
+		poolSize, owned int64
+		want            float64
+	}{
+		{40960, 0, 0},
+		{0, 10, 0},
+		{40960, 40960, 1},
+		{40960, 50000, 1},
+		{perBlock, 1, 1},
+		// One ticket is called with probability TicketsPerBlock/poolSize.
+		{40960, 1, float64(perBlock) / 40960},
+		// No ticket of two is called with probability
+		// (N-2)(N-3)(N-4)(N-5)(N-6) / N(N-1)(N-2)(N-3)(N-4).
+		{40960, 2, 1 - (40955.0*40954)/(40960.0*40959)},
+	}
+	for i, test := range tests {
+		got := blockVoteProbability(params, test.poolSize, test.owned)
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("test %d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+func TestVoteWithinProbability(t *testing.T) {
+	tests := []struct {
+		perBlock float64
+		blocks   int64
+		want     float64
+	}{
+		{0, 100, 0},
+		{0.5, 0, 0},
+		{0.5, 1, 0.5},
+		{0.5, 3, 0.875},
+		{1, 10, 1},
+	}
+	for i, test := range tests {
+		got := voteWithinProbability(test.perBlock, test.blocks)
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("test %d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// TxPreview describes the transaction that would be created by CreateSimpleTx
+// for the same arguments, without signing, broadcasting, or reserving any
+// inputs or change addresses.  The size and fee are estimates which assume
+// worst case signature scripts, so the created transaction may be slightly
+// smaller.
+type TxPreview struct {
+	Inputs        []wtxmgr.Credit
+	TotalInput    dcrutil.Amount
+	TotalOutput   dcrutil.Amount
+	Change        dcrutil.Amount
+	Fee           dcrutil.Amount
+	FeeRate       dcrutil.Amount // Atoms per kB
+	EstimatedSize int
+}
+
+// previewTx finds the eligible outputs of account like txToPairs and returns
+// a description of the transaction createTx would create from them.  Unlike
+// txToPairs, the wallet is not required to be unlocked, as nothing is signed.
+func (w *Wallet) previewTx(pairs map[string]dcrutil.Amount, account uint32,
+	minconf int32) (*TxPreview, error) {
+
+	if w.chainReorganizing() {
+		return nil, ErrBlockchainReorganizing
+	}
+
+	bs, err := w.chainBlockStamp()
+	if err != nil {
+		return nil, err
+	}
+
+	var needed dcrutil.Amount
+	for _, amt := range pairs {
+		needed += amt
+	}
+	feeIncrement := defaultFeeIncrement(w.chainParams)
+	needed += feeForSize(feeIncrement,
+		estimateTxSize(len(pairs), len(pairs)))
+
+	eligible, err := w.findEligibleOutputsAmount(account, minconf, needed, bs)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.previewCreateTx(eligible, pairs, bs, w.FeeIncrement(),
+		w.chainParams, w.DisallowFree)
+}
+
+// previewCreateTx describes the transaction createTx would create for the
+// same arguments.  Inputs are selected by the same selectInputs call and the
+// fee is raised by the same fundTx loop, so the preview only differs from the
+// created transaction in the rare case that the signed transaction requires a
+// larger fee than estimated.
+func (w *Wallet) previewCreateTx(eligible []wtxmgr.Credit,
+	outputs map[string]dcrutil.Amount, bs *waddrmgr.BlockStamp,
+	feeIncrement dcrutil.Amount, chainParams *chaincfg.Params,
+	disallowFree bool) (*TxPreview, error) {
+
+	msgtx := wire.NewMsgTx()
+	minAmount, err := addOutputs(msgtx, outputs, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	sel, err := w.selectInputs(eligible, msgtx.TxOut, minAmount, bs,
+		feeIncrement, disallowFree)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing is signed, so the fee is raised until it pays for the
+	// estimated size of the signed transaction.
+	numOutputs := len(msgtx.TxOut)
+	estimatedSize := func(inputs []wtxmgr.Credit, change dcrutil.Amount) (int,
+		error) {
+		if change > 0 {
+			return estimateTxSize(len(inputs), numOutputs+1), nil
+		}
+		return estimateTxSize(len(inputs), numOutputs), nil
+	}
+	f, err := w.fundTx(sel, msgtx.TxOut, minAmount, bs, feeIncrement,
+		disallowFree, estimatedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TxPreview{
+		Inputs:        f.inputs,
+		TotalInput:    f.totalAdded,
+		TotalOutput:   minAmount,
+		Change:        f.change,
+		Fee:           f.fee,
+		FeeRate:       f.fee * 1000 / dcrutil.Amount(f.size),
+		EstimatedSize: f.size,
+	}, nil
+}
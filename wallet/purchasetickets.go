@@ -0,0 +1,491 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+type (
+	purchaseTicketsRequest struct {
+		minBalance dcrutil.Amount
+		count      int
+		minConf    int32
+		ticketAddr dcrutil.Address
+		poolAddr   dcrutil.Address
+		poolFees   float64
+
+		account       uint32
+		rewardAccount uint32
+		rewardAddr    dcrutil.Address
+
+		origin string
+		resp   chan purchaseTicketsResponse
+	}
+
+	purchaseTicketsResponse struct {
+		hashes []*chainhash.Hash
+		err    error
+	}
+)
+
+// PurchaseTicketsOptions selects the accounts and addresses of a ticket
+// purchase.  The tickets are funded by Account, which also receives the
+// change of the split transaction.  Voting rights are given to
+// TicketAddress, and rewards are committed to RewardAddress, which must be a
+// pubkey hash address.  When either address is not set, a new address of
+// RewardAccount is used instead, except that the ticket and reward addresses
+// configured for the wallet are preferred for tickets rewarding the default
+// account.
+// The zero value funds and rewards the default account.
+type PurchaseTicketsOptions struct {
+	Account       uint32
+	RewardAccount uint32
+	TicketAddress dcrutil.Address
+	RewardAddress dcrutil.Address
+}
+
+// PurchaseTickets purchases count tickets at the current ticket price.  A
+// single split transaction first creates one output of exactly the ticket
+// price plus the ticket fee for each ticket, and each ticket then spends one
+// of these outputs without change.  This pays fewer fees and leaves fewer
+// small outputs than purchasing the tickets one at a time.  The hashes of
+// the published tickets are returned, along with an error if not all tickets
+// could be purchased.  The transactions are signed for origin.
+func (w *Wallet) PurchaseTickets(minBalance dcrutil.Amount, count int,
+	minConf int32, ticketAddr dcrutil.Address,
+	origin string) ([]*chainhash.Hash, error) {
+	return w.PurchaseTicketsWithOptions(minBalance, count, minConf,
+		&PurchaseTicketsOptions{TicketAddress: ticketAddr}, origin)
+}
+
+// PurchaseTicketsWithOptions purchases count tickets like PurchaseTickets,
+// funding them from and giving their voting rights and rewards to the
+// accounts and addresses of opts.  This keeps staking funds segregated from
+// the accounts funds are spent from.
+func (w *Wallet) PurchaseTicketsWithOptions(minBalance dcrutil.Amount,
+	count int, minConf int32, opts *PurchaseTicketsOptions,
+	origin string) ([]*chainhash.Hash, error) {
+	if w.votingOnly {
+		return nil, ErrVotingOnly
+	}
+	for _, account := range []uint32{opts.Account, opts.RewardAccount} {
+		if account == waddrmgr.ImportedAddrAccount {
+			return nil, fmt.Errorf("tickets can not be purchased with " +
+				"the imported account")
+		}
+		if _, err := w.Manager.AccountName(account); err != nil {
+			return nil, err
+		}
+	}
+	if opts.RewardAddress != nil {
+		if _, ok := opts.RewardAddress.(*dcrutil.AddressPubKeyHash); !ok {
+			return nil, fmt.Errorf("reward address %v is not a pubkey "+
+				"hash address", opts.RewardAddress)
+		}
+	}
+
+	req := purchaseTicketsRequest{
+		minBalance:    minBalance,
+		count:         count,
+		minConf:       minConf,
+		ticketAddr:    opts.TicketAddress,
+		account:       opts.Account,
+		rewardAccount: opts.RewardAccount,
+		rewardAddr:    opts.RewardAddress,
+		origin:        origin,
+		resp:          make(chan purchaseTicketsResponse),
+	}
+	w.purchaseTicketsRequests <- req
+	resp := <-req.resp
+	return resp.hashes, resp.err
+}
+
+// PurchasePoolTickets purchases count tickets voted by a stake pool, like
+// PurchaseTickets, except that each ticket also commits poolFees percent of
+// its price and fee to poolAddr.  The ticket address should be the P2SH
+// address of the multisig script shared with the pool.  The split
+// transaction creates a second output for each ticket paying exactly the
+// pool fee, which the ticket commits separately from the wallet's share.
+func (w *Wallet) PurchasePoolTickets(minBalance dcrutil.Amount, count int,
+	minConf int32, ticketAddr, poolAddr dcrutil.Address, poolFees float64,
+	origin string) ([]*chainhash.Hash, error) {
+	if w.votingOnly {
+		return nil, ErrVotingOnly
+	}
+	if _, ok := poolAddr.(*dcrutil.AddressPubKeyHash); !ok {
+		return nil, fmt.Errorf("pool address %v is not a pubkey hash "+
+			"address", poolAddr)
+	}
+	if poolFees <= 0 || poolFees >= 100 {
+		return nil, fmt.Errorf("invalid pool fee percentage %v", poolFees)
+	}
+
+	req := purchaseTicketsRequest{
+		minBalance: minBalance,
+		count:      count,
+		minConf:    minConf,
+		ticketAddr: ticketAddr,
+		poolAddr:   poolAddr,
+		poolFees:   poolFees,
+		origin:     origin,
+		resp:       make(chan purchaseTicketsResponse),
+	}
+	w.purchaseTicketsRequests <- req
+	resp := <-req.resp
+	return resp.hashes, resp.err
+}
+
+// stakePoolFee returns the pool fee a ticket committing amount in total must
+// commit to the pool address, rounded up so the pool never sees less than
+// poolFees percent.
+func stakePoolFee(amount dcrutil.Amount, poolFees float64) dcrutil.Amount {
+	return dcrutil.Amount(math.Ceil(float64(amount) * poolFees / 100))
+}
+
+// purchaseTickets purchases the tickets of a request by publishing a split
+// transaction followed by a ticket spending each of its outputs.
+func (w *Wallet) purchaseTickets(req purchaseTicketsRequest) ([]*chainhash.Hash,
+	error) {
+	if req.count < 1 {
+		return nil, fmt.Errorf("need a positive number of tickets")
+	}
+	if req.minConf < 0 {
+		return nil, fmt.Errorf("need positive minconf")
+	}
+
+	// Initialize the address pool for use.
+	pool := w.internalPool
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	txSucceeded := false
+	defer func() {
+		if txSucceeded {
+			pool.BatchFinish()
+		} else {
+			pool.BatchRollback()
+		}
+	}()
+	addrFunc := pool.GetNewAddress
+	if w.addressReuse {
+		addrFunc = w.ReusedAddress
+	}
+
+	// The split outputs and change of the split transaction and tickets
+	// belong to the funding account, and the tickets vote with and commit
+	// their rewards to addresses of the reward account.  The address pool
+	// only serves the default account.
+	changeFunc, rewardFunc := addrFunc, addrFunc
+	if req.account != waddrmgr.DefaultAccountNum {
+		changeFunc = func() (dcrutil.Address, error) {
+			return w.NewChangeAddress(req.account)
+		}
+	}
+	if req.rewardAccount != waddrmgr.DefaultAccountNum {
+		rewardFunc = func() (dcrutil.Address, error) {
+			return w.NewChangeAddress(req.rewardAccount)
+		}
+	}
+	commitFunc := rewardFunc
+	switch {
+	case req.rewardAddr != nil:
+		commitFunc = func() (dcrutil.Address, error) {
+			return req.rewardAddr, nil
+		}
+	case req.rewardAccount == waddrmgr.DefaultAccountNum:
+		commitFunc = func() (dcrutil.Address, error) {
+			return w.purchaseRewardAddress(rewardFunc)
+		}
+	}
+
+	if w.chainSvr == nil {
+		return nil, ErrOffline
+	}
+	isReorganizing, _ := w.chainSvr.GetReorganizing()
+	if isReorganizing {
+		return nil, ErrBlockchainReorganizing
+	}
+
+	account := req.account
+
+	ticketPrice := dcrutil.Amount(w.GetStakeDifficulty().StakeDifficulty)
+	if ticketPrice <= 0 {
+		return nil, ErrTicketPriceNotSet
+	}
+
+	bs, err := w.chainSvr.BlockStamp()
+	if err != nil {
+		return nil, err
+	}
+	window := int64(bs.Height) / w.chainParams.StakeDiffWindowSize
+	if !w.ticketWindowAvailable(window) {
+		return nil, ErrTicketWindowLimit
+	}
+
+	ticketAddr := req.ticketAddr
+	if ticketAddr == nil && req.rewardAccount != waddrmgr.DefaultAccountNum {
+		ticketAddr, err = rewardFunc()
+	} else {
+		ticketAddr, err = w.purchaseTicketAddress(req.ticketAddr, addrFunc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Each ticket spends a single split output and commits all of it, so
+	// the output must pay for the ticket price and the ticket fee.  Pool
+	// tickets spend a second output paying exactly the pool fee, which is
+	// subtracted from the wallet's output.
+	feeIncrement := w.ticketFeeIncrement(req.count)
+	numInputs := 1
+	if req.poolAddr != nil {
+		numInputs = 2
+	}
+	ticketFee := feeForSize(feeIncrement, estimateSSTxSize(numInputs,
+		numInputs))
+	splitAmount := ticketPrice + ticketFee
+	var poolFee dcrutil.Amount
+	if req.poolAddr != nil {
+		poolFee = stakePoolFee(splitAmount, req.poolFees)
+		if poolFee >= splitAmount {
+			return nil, fmt.Errorf("pool fee %v exceeds the ticket cost",
+				poolFee)
+		}
+	}
+
+	numOutputs := req.count * numInputs
+	pairs := make(map[string]dcrutil.Amount, numOutputs)
+	splitScripts := make(map[string]struct{}, req.count)
+	poolScripts := make(map[string]struct{}, req.count)
+	for len(pairs) < numOutputs {
+		addr, err := changeFunc()
+		if err != nil {
+			return nil, err
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		if poolFee > 0 && len(poolScripts) < req.count {
+			pairs[addr.String()] = poolFee
+			poolScripts[string(pkScript)] = struct{}{}
+			continue
+		}
+		pairs[addr.String()] = splitAmount - poolFee
+		splitScripts[string(pkScript)] = struct{}{}
+	}
+
+	// Create the split transaction.
+	needed := req.minBalance + splitAmount*dcrutil.Amount(req.count) +
+		feeForSize(feeIncrement, estimateTxSize(1, numOutputs+1))
+	eligible, err := w.findEligibleOutputsAmount(account, req.minConf,
+		needed, bs)
+	if err != nil {
+		return nil, err
+	}
+	// The address manager must be unlocked to sign the split transaction.
+	// The hold is released before creating the tickets, which hold the
+	// unlock themselves.
+	release, err := w.holdSigningUnlock()
+	if err != nil {
+		return nil, err
+	}
+	splitTx, err := w.createTx(eligible, pairs, bs, feeIncrement, account,
+		changeFunc, w.chainParams, w.DisallowFree, req.origin)
+	release()
+	if err != nil {
+		return nil, err
+	}
+	inputAmounts := make(map[wire.OutPoint]dcrutil.Amount, len(eligible))
+	for _, credit := range eligible {
+		inputAmounts[credit.OutPoint] = credit.Amount
+	}
+	var inputSum, outputSum dcrutil.Amount
+	for _, txIn := range splitTx.MsgTx.TxIn {
+		inputSum += inputAmounts[txIn.PreviousOutPoint]
+	}
+	for _, txOut := range splitTx.MsgTx.TxOut {
+		outputSum += txOut.Value
+	}
+	splitFee := inputSum - outputSum
+
+	// The ticket fees are paid when purchasing the tickets, so include
+	// them in the fee limit and balance to maintain checks.
+	totalFee := splitFee + ticketFee*dcrutil.Amount(req.count)
+	err = w.checkFeeLimit(totalFee, ticketPrice*dcrutil.Amount(req.count))
+	if err != nil {
+		return nil, err
+	}
+	reserve := req.minBalance
+	if w.BalanceToMaintain > reserve {
+		reserve = w.BalanceToMaintain
+	}
+	spendable, err := w.TxStore.Balance(req.minConf, bs.Height,
+		wtxmgr.BFBalanceSpendable)
+	if err != nil {
+		return nil, err
+	}
+	spent := ticketPrice*dcrutil.Amount(req.count) + totalFee
+	if spendable-spent < reserve {
+		return nil, ErrSStxBalanceReserve
+	}
+
+	splitHash, err := w.sendRawTransaction(splitTx.MsgTx)
+	if err != nil {
+		log.Warnf("Failed to send split transaction: %v", err)
+		return nil, ErrClientPurchaseTicket
+	}
+	txSucceeded = true
+	rec, err := w.insertIntoTxMgr(splitTx.MsgTx)
+	if err != nil {
+		return nil, err
+	}
+	err = w.insertCreditsIntoTxMgr(splitTx.MsgTx, rec)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Published split transaction %v funding %d tickets",
+		splitHash, req.count)
+
+	// Purchase a ticket with each split output, pairing it with a pool fee
+	// output for pool tickets.
+	var credits, poolCredits []wtxmgr.Credit
+	for i, txOut := range splitTx.MsgTx.TxOut {
+		credit := wtxmgr.Credit{
+			OutPoint: wire.OutPoint{
+				Hash:  *splitHash,
+				Index: uint32(i),
+				Tree:  dcrutil.TxTreeRegular,
+			},
+			BlockMeta: wtxmgr.BlockMeta{Block: wtxmgr.Block{Height: -1}},
+			Amount:    dcrutil.Amount(txOut.Value),
+			PkScript:  txOut.PkScript,
+			Received:  time.Now(),
+		}
+		if _, ok := splitScripts[string(txOut.PkScript)]; ok {
+			credits = append(credits, credit)
+		}
+		if _, ok := poolScripts[string(txOut.PkScript)]; ok {
+			poolCredits = append(poolCredits, credit)
+		}
+	}
+	// The split outputs of tickets which are not purchased remain outputs
+	// of the funding account, so they are reported rather than left
+	// unaccounted for in the split transaction.
+	purchased := 0
+	defer func() {
+		if purchased < len(credits) {
+			unused := credits[purchased:]
+			if len(poolCredits) != 0 {
+				unused = append(unused, poolCredits[purchased:]...)
+			}
+			reportUnusedSplitOutputs(unused)
+		}
+	}()
+
+	// The ticket change outputs never pay a value, so they do not use
+	// addresses of the wallet.
+	changeAddr, err := w.zeroChangeAddress()
+	if err != nil {
+		return nil, err
+	}
+	var hashes []*chainhash.Hash
+	for i, credit := range credits {
+		if !w.ticketWindowAvailable(window) {
+			return hashes, ErrTicketWindowLimit
+		}
+
+		commitAddr, err := commitFunc()
+		if err != nil {
+			return hashes, err
+		}
+		ticketCredits := []wtxmgr.Credit{credit}
+		couts := []dcrjson.SStxCommitOut{{
+			Addr:       commitAddr.String(),
+			CommitAmt:  int64(credit.Amount),
+			ChangeAddr: changeAddr.String(),
+			ChangeAmt:  0,
+		}}
+		if req.poolAddr != nil {
+			ticketCredits = []wtxmgr.Credit{poolCredits[i], credit}
+			couts = append([]dcrjson.SStxCommitOut{{
+				Addr:       req.poolAddr.EncodeAddress(),
+				CommitAmt:  int64(poolCredits[i].Amount),
+				ChangeAddr: changeAddr.String(),
+				ChangeAmt:  0,
+			}}, couts...)
+		}
+		inputs := make([]dcrjson.SStxInput, len(ticketCredits))
+		for j := range ticketCredits {
+			inputs[j] = dcrjson.SStxInput{
+				Txid: splitHash.String(),
+				Vout: ticketCredits[j].Index,
+				Tree: dcrutil.TxTreeRegular,
+				Amt:  int64(ticketCredits[j].Amount),
+			}
+		}
+		pair := map[string]dcrutil.Amount{ticketAddr.String(): ticketPrice}
+
+		ticket, err := w.txToSStx(pair, ticketCredits, inputs, couts,
+			account, changeFunc, req.minConf, req.origin)
+		if err != nil {
+			return hashes, err
+		}
+		ticketHash, err := w.sendRawTransaction(ticket.MsgTx)
+		if err != nil {
+			log.Warnf("Failed to send ticket spending split output "+
+				"%v:%d: %v", splitHash, credit.Index, err)
+			return hashes, ErrClientPurchaseTicket
+		}
+		purchased++
+		w.recordWindowPurchase(window)
+		err = w.recordTicketPurchase(ticket.MsgTx, ticketAddr, ticketPrice)
+		if err != nil {
+			return hashes, err
+		}
+		hashes = append(hashes, ticketHash)
+	}
+
+	return hashes, nil
+}
+
+// reportUnusedSplitOutputs logs the outputs of a published split transaction
+// which did not fund a ticket because the purchase stopped early.  They are
+// ordinary outputs of the funding account and may be spent by later
+// transactions.
+func reportUnusedSplitOutputs(credits []wtxmgr.Credit) {
+	var total dcrutil.Amount
+	for i := range credits {
+		total += credits[i].Amount
+	}
+	log.Warnf("%d split outputs totaling %v did not fund tickets and "+
+		"remain spendable:", len(credits), total)
+	for i := range credits {
+		log.Warnf("Unused split output %v:%d (%v)",
+			credits[i].Hash, credits[i].Index, credits[i].Amount)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// RemoteSigner signs signature hashes with the private keys of wallet
+// addresses kept outside of the wallet process, such as by a dcrsigner
+// process on a separate host.
+type RemoteSigner interface {
+	// SignHash returns the DER encoded signature of the 32-byte signature
+	// hash made with the private key of the P2PKH address.
+	SignHash(addr dcrutil.Address, sigHash []byte) ([]byte, error)
+}
+
+// SetRemoteSigner sets the signer used to sign the P2PKH inputs of
+// transactions created and signed by the wallet.  With a remote signer, the
+// wallet does not need to be unlocked to sign, and may be watching-only.  A
+// nil signer signs with the private keys of the address manager again.
+func (w *Wallet) SetRemoteSigner(signer RemoteSigner) {
+	w.remoteSignerMu.Lock()
+	w.remoteSigner = signer
+	w.remoteSignerMu.Unlock()
+}
+
+// RemoteSigner returns the remote signer of the wallet, or nil if it signs
+// with the private keys of the address manager.
+func (w *Wallet) RemoteSigner() RemoteSigner {
+	w.remoteSignerMu.Lock()
+	signer := w.remoteSigner
+	w.remoteSignerMu.Unlock()
+	return signer
+}
+
+// holdSigningUnlock prevents the wallet from being locked while it signs
+// transactions, returning a function releasing the hold.  No hold is required
+// when signing with a remote signer.
+func (w *Wallet) holdSigningUnlock() (func(), error) {
+	if w.RemoteSigner() != nil {
+		return func() {}, nil
+	}
+	heldUnlock, err := w.HoldUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return heldUnlock.Release, nil
+}
+
+// p2pkhSignatureScript returns the signature script redeeming the P2PKH output
+// script pkScript of address ai spent by input idx of tx.  The signature is
+// made with the remote signer if one is set, and with the private key of the
+// address otherwise.
+func (w *Wallet) p2pkhSignatureScript(tx *wire.MsgTx, idx int,
+	pkScript []byte, ai waddrmgr.ManagedAddress) ([]byte, error) {
+	pka, ok := ai.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, errors.New("address is not a public key address")
+	}
+
+	signer := w.RemoteSigner()
+	if signer == nil {
+		privKey, err := pka.PrivKey()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get private key: %v", err)
+		}
+		sigScript, err := txscript.SignatureScript(tx, idx, pkScript,
+			txscript.SigHashAll, privKey, ai.Compressed())
+		if err != nil {
+			return nil, fmt.Errorf("cannot create sigscript: %s", err)
+		}
+		return sigScript, nil
+	}
+
+	sigHash, err := txscript.CalcSignatureHash(pkScript, txscript.SigHashAll,
+		tx, idx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create signature hash: %v", err)
+	}
+	sig, err := signer.SignHash(ai.Address(), sigHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never include a signature which does not verify, so a misbehaving
+	// signer can not cause the wallet to publish invalid transactions.
+	pubKey := pka.PubKey()
+	parsed, err := chainec.Secp256k1.ParseDERSignature(sig)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid "+
+			"signature: %v", err)
+	}
+	if !chainec.Secp256k1.Verify(pubKey, sigHash, parsed.GetR(),
+		parsed.GetS()) {
+		return nil, errors.New("remote signer signature does not verify")
+	}
+
+	var serializedPubKey []byte
+	if ai.Compressed() {
+		serializedPubKey = pubKey.SerializeCompressed()
+	} else {
+		serializedPubKey = pubKey.SerializeUncompressed()
+	}
+	return txscript.NewScriptBuilder().
+		AddData(append(sig, byte(txscript.SigHashAll))).
+		AddData(serializedPubKey).
+		Script()
+}
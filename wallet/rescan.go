@@ -18,6 +18,11 @@
 package wallet
 
 import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
 	"github.com/decred/dcrwallet/chain"
@@ -41,14 +46,19 @@ type RescanFinishedMsg struct {
 
 // RescanJob is a job to be processed by the RescanManager.  The job includes
 // a set of wallet addresses, a starting height to begin the rescan, and
-// outpoints spendable by the addresses thought to be unspent.  After the
-// rescan completes, the error result of the rescan RPC is sent on the Err
-// channel.
+// outpoints spendable by the addresses thought to be unspent.  If EndBlock is
+// set, the rescan stops after the end block rather than the best block, and
+// the job is never merged with other jobs.  Historical jobs rescan blocks the
+// wallet is already synced through and leave the address manager's sync state
+// unchanged; they must set an end block.  After the rescan completes, the
+// error result of the rescan RPC is sent on the Err channel.
 type RescanJob struct {
 	InitialSync bool
+	Historical  bool
 	Addrs       []dcrutil.Address
 	OutPoints   []*wire.OutPoint
 	BlockStamp  waddrmgr.BlockStamp
+	EndBlock    *waddrmgr.BlockStamp
 	err         chan error
 }
 
@@ -56,12 +66,34 @@ type RescanJob struct {
 // together before a rescan is performed.
 type rescanBatch struct {
 	initialSync bool
+	historical  bool
 	addrs       []dcrutil.Address
 	outpoints   []*wire.OutPoint
 	bs          waddrmgr.BlockStamp
+	endBlock    *waddrmgr.BlockStamp
 	errChans    []chan error
 }
 
+// RescanQueueDepth returns the number of rescans which are running or waiting
+// to run.  Jobs which were merged into a single rescan are counted once.
+func (w *Wallet) RescanQueueDepth() int {
+	w.rescanQueueMu.Lock()
+	depth := w.rescanQueueDepth
+	w.rescanQueueMu.Unlock()
+	return depth
+}
+
+// setRescanQueueDepth records the number of running and waiting rescans.
+func (w *Wallet) setRescanQueueDepth(cur *rescanBatch, pending []*rescanBatch) {
+	depth := len(pending)
+	if cur != nil {
+		depth++
+	}
+	w.rescanQueueMu.Lock()
+	w.rescanQueueDepth = depth
+	w.rescanQueueMu.Unlock()
+}
+
 // SubmitRescan submits a RescanJob to the RescanManager.  A channel is
 // returned with the final error of the rescan.  The channel is buffered
 // and does not need to be read to prevent a deadlock.
@@ -76,9 +108,11 @@ func (w *Wallet) SubmitRescan(job *RescanJob) <-chan error {
 func (job *RescanJob) batch() *rescanBatch {
 	return &rescanBatch{
 		initialSync: job.InitialSync,
+		historical:  job.Historical,
 		addrs:       job.Addrs,
 		outpoints:   job.OutPoints,
 		bs:          job.BlockStamp,
+		endBlock:    job.EndBlock,
 		errChans:    []chan error{job.err},
 	}
 }
@@ -109,9 +143,11 @@ func (b *rescanBatch) done(err error) {
 
 // rescanBatchHandler handles incoming rescan request, serializing rescan
 // submissions, and possibly batching many waiting requests together so they
-// can be handled by a single rescan after the current one completes.
+// can be handled by a single rescan after the current one completes.  Jobs
+// with an end block are queued as their own batch.
 func (w *Wallet) rescanBatchHandler() {
-	var curBatch, nextBatch *rescanBatch
+	var curBatch *rescanBatch
+	var pending []*rescanBatch
 	quit := w.quitChan()
 
 out:
@@ -124,18 +160,27 @@ out:
 				curBatch = job.batch()
 				w.rescanBatch <- curBatch
 			} else {
-				// Create next batch if it doesn't exist, or
-				// merge the job.
-				if nextBatch == nil {
-					nextBatch = job.batch()
+				// Merge the job into the last pending batch if
+				// both rescan through the best block, or queue
+				// a new batch.
+				n := len(pending)
+				if n != 0 && pending[n-1].endBlock == nil &&
+					job.EndBlock == nil {
+					pending[n-1].merge(job)
 				} else {
-					nextBatch.merge(job)
+					pending = append(pending, job.batch())
 				}
 			}
+			w.setRescanQueueDepth(curBatch, pending)
 
 		case n := <-w.rescanNotifications:
 			switch n := n.(type) {
 			case *chain.RescanProgress:
+				if curBatch != nil && curBatch.historical {
+					log.Infof("Rescanned through block %v "+
+						"(height %d)", n.Hash, n.Height)
+					continue
+				}
 				w.rescanProgress <- &RescanProgressMsg{
 					Addresses:    curBatch.addrs,
 					Notification: n,
@@ -148,16 +193,23 @@ out:
 						"currently running")
 					continue
 				}
-				w.rescanFinished <- &RescanFinishedMsg{
-					Addresses:    curBatch.addrs,
-					Notification: n,
+				if curBatch.historical {
+					log.Infof("Finished historical rescan "+
+						"through block %v (height %d)",
+						n.Hash, n.Height)
+				} else {
+					w.rescanFinished <- &RescanFinishedMsg{
+						Addresses:    curBatch.addrs,
+						Notification: n,
+					}
 				}
 
-				curBatch, nextBatch = nextBatch, nil
-
-				if curBatch != nil {
+				curBatch = nil
+				if len(pending) != 0 {
+					curBatch, pending = pending[0], pending[1:]
 					w.rescanBatch <- curBatch
 				}
+				w.setRescanQueueDepth(curBatch, pending)
 
 			default:
 				// Unexpected message
@@ -169,6 +221,7 @@ out:
 		}
 	}
 
+	w.setRescanQueueDepth(nil, nil)
 	w.wg.Done()
 }
 
@@ -251,8 +304,15 @@ out:
 			log.Infof("Started rescan from block %v (height %d) for %d %s",
 				batch.bs.Hash, batch.bs.Height, numAddrs, noun)
 
-			err := w.chainSvr.Rescan(&batch.bs.Hash, batch.addrs,
-				batch.outpoints)
+			var err error
+			if batch.endBlock != nil {
+				err = w.chainSvr.RescanEndBlock(&batch.bs.Hash,
+					batch.addrs, batch.outpoints,
+					&batch.endBlock.Hash)
+			} else {
+				err = w.chainSvr.Rescan(&batch.bs.Hash,
+					batch.addrs, batch.outpoints)
+			}
 			if err != nil {
 				log.Errorf("Rescan for %d %s failed: %v", numAddrs,
 					noun, err)
@@ -280,3 +340,238 @@ func (w *Wallet) Rescan(addrs []dcrutil.Address, unspent []*wire.OutPoint) error
 	// Submit merged job and block until rescan completes.
 	return <-w.SubmitRescan(job)
 }
+
+// rescanChunkSize is the number of blocks rescanned by each rescan request
+// made by RescanWallet.  Progress is reported, and cancellation takes effect,
+// after each chunk.
+const rescanChunkSize = 2000
+
+var (
+	// ErrRescanInProgress describes an error where a wallet rescan was
+	// requested while another is running.
+	ErrRescanInProgress = errors.New("a wallet rescan is already in progress")
+
+	// ErrNoRescanInProgress describes an error where a wallet rescan was
+	// cancelled while none is running.
+	ErrNoRescanInProgress = errors.New("no wallet rescan is in progress")
+
+	// ErrRescanCancelled describes an error where a wallet rescan stopped
+	// before reaching the best block because it was cancelled.
+	ErrRescanCancelled = errors.New("wallet rescan cancelled")
+)
+
+// RescanWalletProgress describes the progress of a rescan started by
+// RescanWallet.  Height and Hash are the last rescanned block, and
+// Transactions is the number of wallet transactions in the blocks rescanned
+// so far.  The final progress of a rescan sets Done, and Err if the rescan
+// failed or was cancelled.
+type RescanWalletProgress struct {
+	StartHeight  int32
+	Height       int32
+	Hash         chainhash.Hash
+	Transactions int
+	Done         bool
+	Err          error
+}
+
+// heightForTime returns the height of the first main chain block with a
+// timestamp at or after t, or the best height if there is no such block.
+// Block timestamps are assumed to increase with height.
+func (w *Wallet) heightForTime(t time.Time, bestHeight int32) (int32, error) {
+	var err error
+	height := int32(sort.Search(int(bestHeight), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		var hash *chainhash.Hash
+		hash, err = w.chainSvr.GetBlockHash(int64(i))
+		if err != nil {
+			return true
+		}
+		block, e := w.chainSvr.GetBlock(hash)
+		if e != nil {
+			err = e
+			return true
+		}
+		return !block.MsgBlock().Header.Timestamp.Before(t)
+	}))
+	return height, err
+}
+
+// RescanWallet rescans the main chain from the block at startHeight, or from
+// the first block at or after startTime if it is not zero, through the
+// current best block for transactions involving the wallet's active
+// addresses and unspent outputs.  Unlike Rescan, the chain is rescanned in
+// chunks, and the rescan stops early with ErrRescanCancelled after
+// CancelRescanWallet is called.  The progress of the rescan is passed to
+// listeners of ListenRescanWalletProgress after each chunk, and the final
+// progress is returned.  Only one wallet rescan may run at a time.
+func (w *Wallet) RescanWallet(startHeight int32,
+	startTime time.Time) (*RescanWalletProgress, error) {
+	cancel, err := w.beginRescanWallet()
+	if err != nil {
+		return nil, err
+	}
+	defer w.endRescanWallet()
+
+	_, bestHeight, err := w.chainSvr.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if !startTime.IsZero() {
+		startHeight, err = w.heightForTime(startTime, bestHeight)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if startHeight < 0 || startHeight > bestHeight {
+		return nil, errors.New("rescan start height is not in the main chain")
+	}
+
+	addrs, unspent, err := w.rescanWalletData()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Started wallet rescan from height %d through height %d",
+		startHeight, bestHeight)
+
+	progress := &RescanWalletProgress{
+		StartHeight: startHeight,
+		Height:      startHeight - 1,
+	}
+	progress.Err = w.rescanChunks(progress, bestHeight, addrs, unspent,
+		false, cancel)
+	return w.finishRescanWallet(progress)
+}
+
+// beginRescanWallet marks a wallet rescan as running and returns the channel
+// closed by CancelRescanWallet.  ErrRescanInProgress is returned if another
+// wallet rescan is running.  endRescanWallet must be called when the rescan
+// finishes.
+func (w *Wallet) beginRescanWallet() (<-chan struct{}, error) {
+	w.rescanWalletMu.Lock()
+	defer w.rescanWalletMu.Unlock()
+
+	if w.rescanWalletCancel != nil {
+		return nil, ErrRescanInProgress
+	}
+	cancel := make(chan struct{})
+	w.rescanWalletCancel = cancel
+	return cancel, nil
+}
+
+// endRescanWallet marks the running wallet rescan as finished.
+func (w *Wallet) endRescanWallet() {
+	w.rescanWalletMu.Lock()
+	w.rescanWalletCancel = nil
+	w.rescanWalletMu.Unlock()
+}
+
+// rescanWalletData returns the active addresses and unspent outputs watched by
+// a wallet rescan.
+func (w *Wallet) rescanWalletData() ([]dcrutil.Address, []*wire.OutPoint, error) {
+	var addrs []dcrutil.Address
+	err := w.Manager.ForEachActiveAddress(func(addr dcrutil.Address) error {
+		addrs = append(addrs, addr)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	unspent, err := w.TxStore.UnspentOutpoints()
+	if err != nil {
+		return nil, nil, err
+	}
+	return addrs, unspent, nil
+}
+
+// rescanChunks rescans the blocks after progress.Height through endHeight in
+// chunks of rescanChunkSize blocks, updating progress and notifying progress
+// listeners after each chunk but the last.  Historical rescans do not change
+// the address manager's sync state.
+func (w *Wallet) rescanChunks(progress *RescanWalletProgress, endHeight int32,
+	addrs []dcrutil.Address, unspent []*wire.OutPoint, historical bool,
+	cancel <-chan struct{}) error {
+	for height := progress.Height + 1; height <= endHeight; {
+		select {
+		case <-cancel:
+			return ErrRescanCancelled
+		default:
+		}
+
+		end := height + rescanChunkSize - 1
+		if end > endHeight {
+			end = endHeight
+		}
+		startHash, err := w.chainSvr.GetBlockHash(int64(height))
+		if err != nil {
+			return err
+		}
+		endHash, err := w.chainSvr.GetBlockHash(int64(end))
+		if err != nil {
+			return err
+		}
+
+		job := &RescanJob{
+			Historical: historical,
+			Addrs:      addrs,
+			OutPoints:  unspent,
+			BlockStamp: waddrmgr.BlockStamp{Height: height, Hash: *startHash},
+			EndBlock:   &waddrmgr.BlockStamp{Height: end, Hash: *endHash},
+		}
+		err = <-w.SubmitRescan(job)
+		if err != nil {
+			return err
+		}
+
+		err = w.TxStore.RangeTransactions(height, end,
+			func(details []wtxmgr.TxDetails) (bool, error) {
+				progress.Transactions += len(details)
+				return false, nil
+			})
+		if err != nil {
+			return err
+		}
+		progress.Height = end
+		progress.Hash = *endHash
+		if end != endHeight {
+			w.notifyRescanWalletProgress(*progress)
+		}
+		height = end + 1
+	}
+	return nil
+}
+
+// finishRescanWallet marks the progress of a wallet rescan as done, notifies
+// progress listeners, and returns the final progress and its error.
+func (w *Wallet) finishRescanWallet(progress *RescanWalletProgress) (*RescanWalletProgress, error) {
+	progress.Done = true
+	w.notifyRescanWalletProgress(*progress)
+	if progress.Err != nil {
+		log.Infof("Wallet rescan stopped at height %d: %v",
+			progress.Height, progress.Err)
+		return progress, progress.Err
+	}
+	log.Infof("Finished wallet rescan through height %d (%d %s)",
+		progress.Height, progress.Transactions,
+		pickNoun(progress.Transactions, "transaction", "transactions"))
+	return progress, nil
+}
+
+// CancelRescanWallet stops the running wallet rescan after the chunk being
+// rescanned.
+func (w *Wallet) CancelRescanWallet() error {
+	w.rescanWalletMu.Lock()
+	defer w.rescanWalletMu.Unlock()
+
+	if w.rescanWalletCancel == nil {
+		return ErrNoRescanInProgress
+	}
+	select {
+	case <-w.rescanWalletCancel:
+	default:
+		close(w.rescanWalletCancel)
+	}
+	return nil
+}
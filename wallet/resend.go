@@ -0,0 +1,223 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+const (
+	// resendCheckInterval is how often the unmined transaction resender
+	// checks for transactions which are due to be rebroadcast.
+	resendCheckInterval = time.Minute
+
+	// resendInitialBackoff is the delay before an unmined transaction is
+	// first rebroadcast.  The delay doubles after each attempt.
+	resendInitialBackoff = 2 * time.Minute
+
+	// resendMaxBackoff is the maximum delay between rebroadcasts of a
+	// single unmined transaction.
+	resendMaxBackoff = 2 * time.Hour
+
+	// resendMaxAge is the age after which an unmined transaction is no
+	// longer rebroadcast and is removed from the transaction store.
+	resendMaxAge = 14 * 24 * time.Hour
+)
+
+// resendState tracks the rebroadcast schedule of a single unmined
+// transaction.
+type resendState struct {
+	next    time.Time
+	backoff time.Duration
+}
+
+// unminedTxResender periodically rebroadcasts all unmined wallet transactions
+// to the chain server.  Each transaction is resent with an exponential
+// backoff.  Transactions older than resendMaxAge, or which the chain server
+// rejects because an input has already been spent in the main chain, are
+// removed from the transaction store along with any transactions which spend
+// them.
+//
+// This must be run as a goroutine.
+func (w *Wallet) unminedTxResender() {
+	ticker := time.NewTicker(resendCheckInterval)
+	defer ticker.Stop()
+
+	schedule := make(map[chainhash.Hash]*resendState)
+	quit := w.quitChan()
+out:
+	for {
+		select {
+		case <-ticker.C:
+			if !w.ChainSynced() {
+				continue
+			}
+			w.resendDueTxs(schedule, time.Now())
+
+		case <-quit:
+			break out
+		}
+	}
+	w.wg.Done()
+}
+
+// resendDueTxs rebroadcasts every unmined transaction whose backoff has
+// expired, and removes transactions which have exceeded the maximum age or
+// which can no longer be mined.  Schedule entries for transactions no longer
+// in the store are dropped.
+func (w *Wallet) resendDueTxs(schedule map[chainhash.Hash]*resendState,
+	now time.Time) {
+
+	recs, err := w.TxStore.UnminedTxRecords()
+	if err != nil {
+		log.Errorf("Cannot load unmined transactions for resending: %v",
+			err)
+		return
+	}
+
+	unmined := make(map[chainhash.Hash]struct{}, len(recs))
+	for _, rec := range recs {
+		unmined[rec.Hash] = struct{}{}
+	}
+	for hash := range schedule {
+		if _, ok := unmined[hash]; !ok {
+			delete(schedule, hash)
+		}
+	}
+
+	removed := make(map[chainhash.Hash]struct{})
+	for _, rec := range recs {
+		// Records are sorted by dependency, so any transaction spending
+		// from a removed transaction was removed along with it.
+		if spendsRemoved(rec, removed) {
+			removed[rec.Hash] = struct{}{}
+			delete(schedule, rec.Hash)
+			continue
+		}
+
+		if now.Sub(rec.Received) > resendMaxAge {
+			log.Infof("Removing unmined transaction %v: not mined "+
+				"after %v", rec.Hash, resendMaxAge)
+			w.removeUnminedTx(rec, schedule, removed)
+			continue
+		}
+
+		state, ok := schedule[rec.Hash]
+		if !ok {
+			state = &resendState{
+				next:    now.Add(resendInitialBackoff),
+				backoff: resendInitialBackoff,
+			}
+			schedule[rec.Hash] = state
+			continue
+		}
+		if now.Before(state.next) {
+			continue
+		}
+
+		state.backoff *= 2
+		if state.backoff > resendMaxBackoff {
+			state.backoff = resendMaxBackoff
+		}
+		state.next = now.Add(state.backoff)
+
+		_, err := w.chainSvr.SendRawTransaction(&rec.MsgTx, false)
+		if err == nil {
+			log.Tracef("Resent unmined transaction %v", rec.Hash)
+			continue
+		}
+		err = rejectionError(err)
+		if e, ok := err.(TxRejectedError); ok && e.Code != TxRejectDuplicate {
+			log.Warnf("Chain server rejected unmined transaction "+
+				"%v: %v", rec.Hash, err)
+		} else {
+			log.Debugf("Could not resend transaction %v: %v",
+				rec.Hash, err)
+		}
+
+		// The chain server may have rejected the transaction because
+		// it conflicts with the main chain.  If any input spends an
+		// output that is no longer unspent, the transaction can never
+		// be mined.
+		if w.unminedTxConflicts(rec, unmined) {
+			log.Infof("Removing unmined transaction %v: double "+
+				"spends a mined output", rec.Hash)
+			w.removeUnminedTx(rec, schedule, removed)
+		}
+	}
+}
+
+// removeUnminedTx removes rec from the transaction store and records it as
+// removed.
+func (w *Wallet) removeUnminedTx(rec *wtxmgr.TxRecord,
+	schedule map[chainhash.Hash]*resendState,
+	removed map[chainhash.Hash]struct{}) {
+
+	err := w.TxStore.RemoveUnminedTx(rec)
+	if err != nil {
+		log.Errorf("Failed to remove unmined transaction %v: %v",
+			rec.Hash, err)
+		return
+	}
+	removed[rec.Hash] = struct{}{}
+	delete(schedule, rec.Hash)
+}
+
+// unminedTxConflicts queries the chain server for each previous output spent
+// by rec and returns whether any output not created by another unmined
+// wallet transaction is missing from the main chain's unspent set.
+func (w *Wallet) unminedTxConflicts(rec *wtxmgr.TxRecord,
+	unmined map[chainhash.Hash]struct{}) bool {
+
+	var zeroHash chainhash.Hash
+	for _, txIn := range rec.MsgTx.TxIn {
+		prevOut := &txIn.PreviousOutPoint
+		if prevOut.Hash == zeroHash {
+			// Stakebase inputs do not reference a previous output.
+			continue
+		}
+		if _, ok := unmined[prevOut.Hash]; ok {
+			continue
+		}
+		txOut, err := w.chainSvr.GetTxOut(&prevOut.Hash, prevOut.Index,
+			false)
+		if err != nil {
+			return false
+		}
+		// This returns nil if the output is spent.
+		if txOut == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// spendsRemoved returns whether any input of rec spends an output of a
+// transaction in removed.
+func spendsRemoved(rec *wtxmgr.TxRecord,
+	removed map[chainhash.Hash]struct{}) bool {
+
+	for _, txIn := range rec.MsgTx.TxIn {
+		if _, ok := removed[txIn.PreviousOutPoint.Hash]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// RevocationSummary reports the revocations automatically created by the
+// wallet since it was started, and the total amount returned by them.
+type RevocationSummary struct {
+	Revoked   int
+	Recovered dcrutil.Amount
+}
+
+// RevocationSummary returns the number of tickets automatically revoked by
+// the wallet since it was started and the funds recovered by revoking them.
+func (w *Wallet) RevocationSummary() RevocationSummary {
+	w.revocationMu.Lock()
+	defer w.revocationMu.Unlock()
+
+	return w.revocationSummary
+}
+
+// handleRevocationNtfns notifies listeners of newly created revocations and
+// adds them to the revocation summary.
+func (w *Wallet) handleRevocationNtfns(ntfns []*wstakemgr.StakeNotification,
+	reason string) {
+
+	w.recordStakeSigning(ntfns)
+	for _, ntfn := range ntfns {
+		if ntfn == nil {
+			continue
+		}
+		w.notifyRevocationCreated(*ntfn)
+
+		recovered := dcrutil.Amount(ntfn.Amount)
+		w.revocationMu.Lock()
+		w.revocationSummary.Revoked++
+		w.revocationSummary.Recovered += recovered
+		w.revocationMu.Unlock()
+
+		// Inform the console that we've revoked our ticket.
+		log.Infof("Revoked %s ticket %v recovering %v (tx hash: %v)",
+			reason, ntfn.SStxIn, recovered, ntfn.TxHash)
+	}
+}
+
+// revokeExpiredTickets creates revocations for all owned tickets which have
+// neither voted nor been revoked, and which expired before the block at
+// height.  Missed tickets are normally revoked as the chain server notifies
+// the wallet of them, but this also catches tickets which expired while the
+// wallet was not connected.
+func (w *Wallet) revokeExpiredTickets(blockHash *chainhash.Hash, height int32) {
+	if !w.autoRevoke || w.Locked() {
+		return
+	}
+
+	tickets, err := w.StakeMgr.UnresolvedTickets()
+	if err != nil {
+		log.Errorf("Failed to look up unresolved tickets: %v", err)
+		return
+	}
+
+	expiry := int32(w.chainParams.TicketMaturity) +
+		int32(w.chainParams.TicketExpiry)
+	var expired []*chainhash.Hash
+	for i := range tickets {
+		ticket := &tickets[i]
+		details, err := w.TxStore.TxDetails(ticket)
+		if err != nil || details == nil {
+			continue
+		}
+		minedHeight := details.Height()
+		if minedHeight <= 0 || height <= minedHeight+expiry {
+			continue
+		}
+
+		// The ticket may have been spent by a vote or revocation the
+		// wallet has not recorded.
+		txOut, err := w.chainSvr.GetTxOut(ticket, 0, true)
+		if err != nil || txOut == nil {
+			continue
+		}
+		expired = append(expired, ticket)
+	}
+	if len(expired) == 0 {
+		return
+	}
+
+	ntfns, err := w.StakeMgr.HandleMissedTicketsNtfn(blockHash,
+		int64(height), expired)
+	w.handleRevocationNtfns(ntfns, "expired")
+	if err != nil {
+		log.Debugf("Failed to revoke expired tickets: %v", err)
+	}
+}
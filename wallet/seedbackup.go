@@ -0,0 +1,348 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/internal/zero"
+	"github.com/decred/dcrwallet/pgpwordlist"
+	"github.com/decred/dcrwallet/snacl"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// Seed backup files begin with the magic and a big-endian version, followed
+// by the marshaled scrypt parameters of the key derived from the backup
+// passphrase and the JSON encoding of the SeedBackup encrypted with the key.
+var seedBackupMagic = []byte("DCRSEEDB")
+
+// seedBackupVersion is the version of the seed backup file format written by
+// EncodeSeedBackup.
+const seedBackupVersion = 1
+
+// seedBackupParamsSize is the size of the marshaled scrypt parameters of a
+// seed backup file.
+const seedBackupParamsSize = snacl.KeySize + 32 + 24
+
+// seedBackupMaxN limits the scrypt cost of seed backup files which are read,
+// so a crafted file can not exhaust the memory of the wallet.
+const seedBackupMaxN = 1 << 20
+
+// ErrInvalidSeedBackup describes a seed backup file which is malformed or of
+// an unknown version.
+var ErrInvalidSeedBackup = errors.New("invalid seed backup file")
+
+// SeedBackup describes everything required to restore a wallet: the seed,
+// the accounts derived from it and the number of addresses used by each, and
+// the imported keys and scripts which can not be recovered from the seed.
+type SeedBackup struct {
+	Network  string `json:"network"`
+	Created  int64  `json:"created"`
+	Birthday int64  `json:"birthday,omitempty"`
+
+	// Seed is the hex encoded wallet seed.
+	Seed string `json:"seed"`
+
+	Accounts []SeedBackupAccount `json:"accounts"`
+
+	// ImportedKeys are the WIF encoded imported private keys.
+	// ImportedPubKeys are the hex encoded imported public keys without a
+	// private key, such as keys held by an HSM.  ImportedScripts are the
+	// hex encoded imported redeem scripts.
+	ImportedKeys    []string `json:"importedkeys"`
+	ImportedPubKeys []string `json:"importedpubkeys"`
+	ImportedScripts []string `json:"importedscripts"`
+}
+
+// SeedBackupAccount describes an account of a seed backup.  ExternalCount
+// and InternalCount are the numbers of addresses returned from each branch.
+type SeedBackupAccount struct {
+	Number        uint32 `json:"number"`
+	Name          string `json:"name"`
+	ExternalCount uint32 `json:"externalcount"`
+	InternalCount uint32 `json:"internalcount"`
+}
+
+// SeedBackup describes the wallet for a seed backup.  The wallet must be
+// unlocked to reveal the seed and imported private keys.
+func (w *Wallet) SeedBackup() (*SeedBackup, error) {
+	heldUnlock, err := w.HoldUnlock()
+	if err != nil {
+		return nil, err
+	}
+	defer heldUnlock.Release()
+
+	seedWords, err := w.Manager.GetSeed()
+	if err != nil {
+		return nil, err
+	}
+	seed, err := pgpwordlist.ToBytesChecksum(seedWords)
+	if err != nil {
+		return nil, err
+	}
+	b := &SeedBackup{
+		Network:         w.chainParams.Name,
+		Created:         time.Now().Unix(),
+		Seed:            hex.EncodeToString(seed),
+		Accounts:        []SeedBackupAccount{},
+		ImportedKeys:    []string{},
+		ImportedPubKeys: []string{},
+		ImportedScripts: []string{},
+	}
+	zero.Bytes(seed)
+
+	birthday, err := w.Manager.Birthday()
+	if err != nil {
+		return nil, err
+	}
+	if !birthday.IsZero() {
+		b.Birthday = birthday.Unix()
+	}
+
+	lastAccount, err := w.Manager.LastAccount()
+	if err != nil {
+		return nil, err
+	}
+	for account := uint32(0); account <= lastAccount; account++ {
+		name, err := w.Manager.AccountName(account)
+		if err != nil {
+			return nil, err
+		}
+		a := SeedBackupAccount{Number: account, Name: name}
+		_, index, err := w.Manager.LastExternalAddress(account)
+		switch {
+		case err == nil:
+			a.ExternalCount = index + 1
+		case !waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound):
+			return nil, err
+		}
+		_, index, err = w.Manager.LastInternalAddress(account)
+		switch {
+		case err == nil:
+			a.InternalCount = index + 1
+		case !waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound):
+			return nil, err
+		}
+		b.Accounts = append(b.Accounts, a)
+	}
+
+	// Keys and scripts are revealed after iterating over the addresses,
+	// since the iteration holds the address manager mutex.
+	var imported []waddrmgr.ManagedAddress
+	err = w.Manager.ForEachAccountAddress(waddrmgr.ImportedAddrAccount,
+		func(maddr waddrmgr.ManagedAddress) error {
+			imported = append(imported, maddr)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	for _, maddr := range imported {
+		switch maddr := maddr.(type) {
+		case waddrmgr.ManagedPubKeyAddress:
+			wif, err := maddr.ExportPrivKey()
+			switch {
+			case err == nil:
+				b.ImportedKeys = append(b.ImportedKeys, wif.String())
+			case waddrmgr.IsError(err, waddrmgr.ErrWatchingOnly):
+				b.ImportedPubKeys = append(b.ImportedPubKeys,
+					maddr.ExportPubKey())
+			default:
+				return nil, err
+			}
+		case waddrmgr.ManagedScriptAddress:
+			script, err := maddr.Script()
+			if err != nil {
+				return nil, err
+			}
+			b.ImportedScripts = append(b.ImportedScripts,
+				hex.EncodeToString(script))
+		}
+	}
+	return b, nil
+}
+
+// EncodeSeedBackup encrypts the seed backup with a key derived from the
+// passphrase and returns the seed backup file.
+func EncodeSeedBackup(b *SeedBackup, pass []byte) ([]byte, error) {
+	plaintext, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	defer zero.Bytes(plaintext)
+
+	key, err := snacl.NewSecretKey(&pass, snacl.DefaultN, snacl.DefaultR,
+		snacl.DefaultP)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Zero()
+	ciphertext, err := key.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(seedBackupMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(seedBackupVersion))
+	buf.Write(key.Marshal())
+	buf.Write(ciphertext)
+	return buf.Bytes(), nil
+}
+
+// DecodeSeedBackup decrypts a seed backup file with the passphrase.
+// ErrInvalidSeedBackup is returned for malformed files and snacl's
+// ErrInvalidPassword for an incorrect passphrase.
+func DecodeSeedBackup(file, pass []byte) (*SeedBackup, error) {
+	headerSize := len(seedBackupMagic) + 4 + seedBackupParamsSize
+	if len(file) < headerSize ||
+		!bytes.Equal(file[:len(seedBackupMagic)], seedBackupMagic) {
+		return nil, ErrInvalidSeedBackup
+	}
+	file = file[len(seedBackupMagic):]
+	version := binary.BigEndian.Uint32(file)
+	if version != seedBackupVersion {
+		return nil, fmt.Errorf("%v: unsupported version %d",
+			ErrInvalidSeedBackup, version)
+	}
+	file = file[4:]
+
+	var key snacl.SecretKey
+	if err := key.Unmarshal(file[:seedBackupParamsSize]); err != nil {
+		return nil, ErrInvalidSeedBackup
+	}
+	p := &key.Parameters
+	if p.N <= 1 || p.N > seedBackupMaxN || p.N&(p.N-1) != 0 ||
+		p.R <= 0 || p.P <= 0 || p.R*p.P >= 1<<30 {
+		return nil, ErrInvalidSeedBackup
+	}
+	if err := key.DeriveKey(&pass); err != nil {
+		return nil, err
+	}
+	defer key.Zero()
+	plaintext, err := key.Decrypt(file[seedBackupParamsSize:])
+	if err != nil {
+		return nil, ErrInvalidSeedBackup
+	}
+	defer zero.Bytes(plaintext)
+
+	b := new(SeedBackup)
+	if err := json.Unmarshal(plaintext, b); err != nil {
+		return nil, ErrInvalidSeedBackup
+	}
+	return b, nil
+}
+
+// RestoreSeedBackup recreates the accounts, used addresses, and imported keys
+// and scripts of a seed backup in a new address manager created from the
+// seed of the backup, and adds the imported scripts to the transaction
+// store.  The address manager must be unlocked.
+//
+// Without imported material, the birthday of the backed up wallet is set so
+// rescans may begin at it.  Imported keys and scripts may have been used
+// before it, so otherwise the whole chain must be rescanned.
+func RestoreSeedBackup(b *SeedBackup, mgr *waddrmgr.Manager,
+	txStore *wtxmgr.Store) error {
+	if b.Network != mgr.ChainParams().Name {
+		return fmt.Errorf("seed backup is for the %s network", b.Network)
+	}
+
+	for _, a := range b.Accounts {
+		if a.Number == 0 {
+			name, err := mgr.AccountName(0)
+			if err != nil {
+				return err
+			}
+			if name != a.Name {
+				if err := mgr.RenameAccount(0, a.Name); err != nil {
+					return err
+				}
+			}
+		} else {
+			account, err := mgr.NewAccount(a.Name)
+			if err != nil {
+				return err
+			}
+			if account != a.Number {
+				return fmt.Errorf("account %q restored as account "+
+					"%d, not %d", a.Name, account, a.Number)
+			}
+		}
+		if a.ExternalCount != 0 {
+			_, err := mgr.NextExternalAddresses(a.Number, a.ExternalCount)
+			if err != nil {
+				return err
+			}
+		}
+		if a.InternalCount != 0 {
+			_, err := mgr.NextInternalAddresses(a.Number, a.InternalCount)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	bs := &waddrmgr.BlockStamp{
+		Hash:   *mgr.ChainParams().GenesisHash,
+		Height: 0,
+	}
+	for _, s := range b.ImportedKeys {
+		wif, err := dcrutil.DecodeWIF(s)
+		if err != nil {
+			return err
+		}
+		if _, err := mgr.ImportPrivateKey(wif, bs); err != nil {
+			return err
+		}
+	}
+	for _, s := range b.ImportedPubKeys {
+		pubKey, err := hex.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		if _, err := mgr.ImportPublicKey(pubKey, bs); err != nil {
+			return err
+		}
+	}
+	for _, s := range b.ImportedScripts {
+		script, err := hex.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		if err := txStore.InsertTxScript(script); err != nil {
+			return err
+		}
+		if _, err := mgr.ImportScript(script, bs); err != nil {
+			return err
+		}
+	}
+
+	importedMaterial := len(b.ImportedKeys) != 0 ||
+		len(b.ImportedPubKeys) != 0 || len(b.ImportedScripts) != 0
+	if b.Birthday != 0 && !importedMaterial {
+		return mgr.SetBirthday(time.Unix(b.Birthday, 0))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package wallet
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrwallet/snacl"
+)
+
+func TestSeedBackupEncoding(t *testing.T) {
+	b := &SeedBackup{
+		Network:  "testnet",
+		Created:  1470000000,
+		Birthday: 1460000000,
+		Seed:     "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20",
+		Accounts: []SeedBackupAccount{
+			{Number: 0, Name: "default", ExternalCount: 20, InternalCount: 7},
+			{Number: 1, Name: "savings", ExternalCount: 2},
+		},
+		ImportedKeys:    []string{"PtWUJWhSXsM9ztPkdtH8REe91z7uoidX8dsMChJUZ2spagm7YvrNm"},
+		ImportedPubKeys: []string{},
+		ImportedScripts: []string{"51"},
+	}
+	pass := []byte("backup passphrase")
+	file, err := EncodeSeedBackup(b, pass)
+	if err != nil {
+		t.Fatalf("EncodeSeedBackup: %v", err)
+	}
+
+	decoded, err := DecodeSeedBackup(file, pass)
+	if err != nil {
+		t.Fatalf("DecodeSeedBackup: %v", err)
+	}
+	if !reflect.DeepEqual(b, decoded) {
+		t.Errorf("decoded seed backup %+v differs from encoded %+v",
+			decoded, b)
+	}
+
+	_, err = DecodeSeedBackup(file, []byte("wrong passphrase"))
+	if err != snacl.ErrInvalidPassword {
+		t.Errorf("decoding with the wrong passphrase returned %v, "+
+			"want ErrInvalidPassword", err)
+	}
+
+	corrupt := append([]byte(nil), file...)
+	corrupt[len(corrupt)-1] ^= 1
+	_, err = DecodeSeedBackup(corrupt, pass)
+	if err != ErrInvalidSeedBackup {
+		t.Errorf("decoding a corrupt file returned %v, want "+
+			"ErrInvalidSeedBackup", err)
+	}
+
+	unknownVersion := append([]byte(nil), file...)
+	unknownVersion[len(seedBackupMagic)+3] = 2
+	if _, err := DecodeSeedBackup(unknownVersion, pass); err == nil {
+		t.Errorf("decoding an unknown version succeeded")
+	}
+
+	if _, err := DecodeSeedBackup(file[:20], pass); err != ErrInvalidSeedBackup {
+		t.Errorf("decoding a truncated file returned %v, want "+
+			"ErrInvalidSeedBackup", err)
+	}
+}
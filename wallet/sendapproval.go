@@ -0,0 +1,360 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+var (
+	// ErrPendingSendNotFound describes an approval or rejection of a
+	// pending send which does not exist, either because it was never
+	// queued or because it was already approved, rejected, or expired.
+	ErrPendingSendNotFound = errors.New("no pending send with this id")
+
+	// ErrSelfApproval describes an attempt to approve a pending send by
+	// the same origin which requested it.
+	ErrSelfApproval = errors.New("a pending send must be approved by a " +
+		"different origin than the one which requested it")
+)
+
+// SendApprovalPolicy requires a second approval of sends paying more than a
+// threshold amount.  Such sends are created unsigned and queued, and are only
+// signed and broadcast once approved.  Unapproved sends expire, releasing the
+// inputs they reserved.
+type SendApprovalPolicy struct {
+	// Threshold is the largest amount which may be sent without approval.
+	Threshold dcrutil.Amount
+
+	// Expiry is the duration a queued send waits for approval.
+	Expiry time.Duration
+}
+
+// PendingSend is an unsigned transaction waiting for approval.  Its inputs
+// are locked until it is approved, rejected, or expires.
+type PendingSend struct {
+	// ID is the hash of the transaction.  Signature scripts are not
+	// included in transaction hashes, so this is also the hash of the
+	// transaction once it is signed and broadcast.
+	ID chainhash.Hash
+
+	// Origin is the signing origin which requested the send.
+	Origin string
+
+	Tx *wire.MsgTx
+
+	// Inputs are the credits spent by the transaction, in the order of
+	// its inputs, so the transaction may be signed elsewhere.
+	Inputs []wtxmgr.Credit
+
+	Outputs map[string]dcrutil.Amount
+	Amount  dcrutil.Amount
+	Fee     dcrutil.Amount
+	Created time.Time
+	Expires time.Time
+
+	timer *time.Timer
+}
+
+// SendNotApprovedError describes a transaction which was not signed because
+// it pays more than the approval threshold outside of the wallet and was not
+// approved.  Sends which can not be queued for approval must be made in
+// amounts below the threshold.
+type SendNotApprovedError struct {
+	Origin    string
+	Amount    dcrutil.Amount
+	Threshold dcrutil.Amount
+}
+
+// Error satisfies the builtin error interface.
+func (e SendNotApprovedError) Error() string {
+	return fmt.Sprintf("send of %v for %s exceeds the approval threshold "+
+		"of %v and was not approved", e.Amount, e.Origin, e.Threshold)
+}
+
+// ApprovalRequiredError describes a send which was queued for approval
+// instead of being signed and broadcast.
+type ApprovalRequiredError struct {
+	Send *PendingSend
+}
+
+// Error satisfies the builtin error interface.
+func (e ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("send of %v requires approval: queued as pending "+
+		"send %v until %v", e.Send.Amount, &e.Send.ID,
+		e.Send.Expires.Format(time.RFC3339))
+}
+
+// SendApprovalPolicy returns the send approval policy of the wallet, or nil
+// if sends never require approval.
+func (w *Wallet) SendApprovalPolicy() *SendApprovalPolicy {
+	w.pendingSendsMu.Lock()
+	p := w.sendApprovalPolicy
+	w.pendingSendsMu.Unlock()
+	return p
+}
+
+// SetSendApprovalPolicy replaces the send approval policy of the wallet.  A
+// nil policy disables approval for new sends, but sends which are already
+// pending must still be approved or rejected.
+func (w *Wallet) SetSendApprovalPolicy(p *SendApprovalPolicy) {
+	w.pendingSendsMu.Lock()
+	w.sendApprovalPolicy = p
+	w.pendingSendsMu.Unlock()
+}
+
+// approvalRequired returns the send approval policy if sending the amounts
+// requires approval, or nil if it does not.  As when signing, only the amounts
+// paid outside of the wallet count towards the threshold.
+func (w *Wallet) approvalRequired(
+	pairs map[string]dcrutil.Amount) *SendApprovalPolicy {
+
+	p := w.SendApprovalPolicy()
+	if p == nil {
+		return nil
+	}
+	var total dcrutil.Amount
+	for addrStr, amt := range pairs {
+		addr, err := dcrutil.DecodeAddress(addrStr, w.chainParams)
+		if err == nil {
+			if _, err := w.Manager.Address(addr); err == nil {
+				continue
+			}
+		}
+		total += amt
+	}
+	if total <= p.Threshold {
+		return nil
+	}
+	return p
+}
+
+// CheckSendApproval returns a SendNotApprovedError if a transaction pays more
+// than the approval threshold outside of the wallet and is signed for origin
+// without approval.  It must be called before each transaction is signed
+// outside of the wallet package with wallet keys.
+func (w *Wallet) CheckSendApproval(tx *wire.MsgTx, origin string) error {
+	amount, _ := w.externalOutputs(tx)
+	return w.checkSendApproval(amount, origin)
+}
+
+// checkSendApproval returns a SendNotApprovedError if a send of amount for
+// origin requires approval.  Transactions the wallet signs on its own never
+// require approval.  It must be called before every send which was not
+// queued for approval is signed.
+func (w *Wallet) checkSendApproval(amount dcrutil.Amount, origin string) error {
+	if origin == SigningOriginWallet || origin == SigningOriginTicketBuyer {
+		return nil
+	}
+	p := w.SendApprovalPolicy()
+	if p == nil || amount <= p.Threshold {
+		return nil
+	}
+	return SendNotApprovedError{
+		Origin:    origin,
+		Amount:    amount,
+		Threshold: p.Threshold,
+	}
+}
+
+// queuePendingSend creates an unsigned transaction paying pairs and queues it
+// for approval, locking its inputs.  The transaction is created with the same
+// input selection and fee as a signed transaction created by CreateSimpleTx,
+// so the wallet does not need to be unlocked.  The send is requested by origin.
+func (w *Wallet) queuePendingSend(pairs map[string]dcrutil.Amount,
+	account uint32, minconf int32, p *SendApprovalPolicy,
+	origin string) (*PendingSend, error) {
+
+	if w.votingOnly {
+		return nil, ErrVotingOnly
+	}
+
+	preview, err := w.PreviewSimpleTx(account, pairs, minconf)
+	if err != nil {
+		return nil, err
+	}
+
+	msgtx := wire.NewMsgTx()
+	if _, err := addOutputs(msgtx, pairs, w.chainParams); err != nil {
+		return nil, err
+	}
+	for i := range preview.Inputs {
+		msgtx.AddTxIn(wire.NewTxIn(&preview.Inputs[i].OutPoint, nil))
+	}
+	if preview.Change > 0 {
+		// Change of imported address outputs is paid to the default
+		// account, as for signed transactions.
+		if account == waddrmgr.ImportedAddrAccount {
+			account = waddrmgr.DefaultAccountNum
+		}
+		changeAddr, err := w.NewChangeAddress(account)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := addChange(msgtx, preview.Change, changeAddr); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	s := &PendingSend{
+		ID:      msgtx.TxSha(),
+		Origin:  origin,
+		Tx:      msgtx,
+		Inputs:  preview.Inputs,
+		Outputs: pairs,
+		Amount:  preview.TotalOutput,
+		Fee:     preview.Fee,
+		Created: now,
+		Expires: now.Add(p.Expiry),
+	}
+	for _, txIn := range msgtx.TxIn {
+		w.LockOutpoint(txIn.PreviousOutPoint)
+	}
+	w.addPendingSend(s)
+
+	log.Infof("Queued send %v of %v requested by %s for approval",
+		&s.ID, s.Amount, s.Origin)
+	return s, nil
+}
+
+// addPendingSend adds a send to the queue, expiring it at its expiry time.
+func (w *Wallet) addPendingSend(s *PendingSend) {
+	id := s.ID
+	w.pendingSendsMu.Lock()
+	s.timer = time.AfterFunc(s.Expires.Sub(time.Now()), func() {
+		if w.removePendingSend(&id) != nil {
+			log.Infof("Pending send %v expired without approval", &id)
+		}
+	})
+	w.pendingSends[id] = s
+	w.pendingSendsMu.Unlock()
+}
+
+// removePendingSend removes a send from the queue, unlocking its inputs.  It
+// returns nil if the send is not queued.
+func (w *Wallet) removePendingSend(id *chainhash.Hash) *PendingSend {
+	w.pendingSendsMu.Lock()
+	s, ok := w.pendingSends[*id]
+	if ok {
+		delete(w.pendingSends, *id)
+		s.timer.Stop()
+	}
+	w.pendingSendsMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	for _, txIn := range s.Tx.TxIn {
+		w.UnlockOutpoint(txIn.PreviousOutPoint)
+	}
+	return s
+}
+
+// PendingSends returns the sends waiting for approval, ordered by the time
+// they were requested.  Pending sends are not saved to the database and are
+// lost when the wallet is closed.
+func (w *Wallet) PendingSends() []*PendingSend {
+	w.pendingSendsMu.Lock()
+	sends := make([]*PendingSend, 0, len(w.pendingSends))
+	for _, s := range w.pendingSends {
+		sends = append(sends, s)
+	}
+	w.pendingSendsMu.Unlock()
+
+	sort.Sort(pendingSendsByCreated(sends))
+	return sends
+}
+
+type pendingSendsByCreated []*PendingSend
+
+func (s pendingSendsByCreated) Len() int           { return len(s) }
+func (s pendingSendsByCreated) Less(i, j int) bool { return s[i].Created.Before(s[j].Created) }
+func (s pendingSendsByCreated) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// ApprovePendingSend approves a pending send, signing and broadcasting its
+// transaction and returning its hash.  The approval is given by approver,
+// which must differ from the origin which requested the send unless byToken
+// is set, indicating that the caller has verified an approval token from an
+// independent approver.  The
+// transaction is signed for the requesting origin, so it is recorded in the
+// signing log and checked against the signing policy as that origin.
+//
+// When the transaction can not be signed, such as because the wallet is
+// locked, the send remains pending and may be approved again.
+func (w *Wallet) ApprovePendingSend(id *chainhash.Hash, byToken bool,
+	approver string) (*chainhash.Hash, error) {
+
+	w.pendingSendsMu.Lock()
+	s, ok := w.pendingSends[*id]
+	if ok && (byToken || approver != s.Origin) {
+		// Remove the send while it is signed so it can not expire or
+		// be approved concurrently.
+		delete(w.pendingSends, *id)
+		s.timer.Stop()
+	}
+	w.pendingSendsMu.Unlock()
+	if !ok {
+		return nil, ErrPendingSendNotFound
+	}
+	if !byToken && approver == s.Origin {
+		return nil, ErrSelfApproval
+	}
+
+	signed, err := w.signTransaction(s.Tx, s.Origin, true)
+	if err == nil && signed != len(s.Tx.TxIn) {
+		err = fmt.Errorf("only %d of %d transaction inputs were signed",
+			signed, len(s.Tx.TxIn))
+	}
+	if err != nil {
+		for _, txIn := range s.Tx.TxIn {
+			txIn.SignatureScript = nil
+		}
+		w.addPendingSend(s)
+		return nil, err
+	}
+
+	for _, txIn := range s.Tx.TxIn {
+		w.UnlockOutpoint(txIn.PreviousOutPoint)
+	}
+	if byToken {
+		approver = "approval token"
+	}
+	log.Infof("Pending send %v requested by %s approved by %s", &s.ID,
+		s.Origin, approver)
+	return w.PublishTransaction(s.Tx)
+}
+
+// RejectPendingSend removes a pending send without signing it, unlocking the
+// inputs it reserved.
+func (w *Wallet) RejectPendingSend(id *chainhash.Hash) error {
+	s := w.removePendingSend(id)
+	if s == nil {
+		return ErrPendingSendNotFound
+	}
+	log.Infof("Pending send %v requested by %s rejected", &s.ID, s.Origin)
+	return nil
+}
@@ -0,0 +1,120 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+)
+
+func TestPendingSends(t *testing.T) {
+	w := &Wallet{
+		lockedOutpoints: map[wire.OutPoint]struct{}{},
+		pendingSends:    map[chainhash.Hash]*PendingSend{},
+	}
+
+	w.SetSendApprovalPolicy(&SendApprovalPolicy{
+		Threshold: 10e8,
+		Expiry:    time.Hour,
+	})
+	if w.approvalRequired(map[string]dcrutil.Amount{"a": 6e8, "b": 4e8}) != nil {
+		t.Errorf("send of the threshold amount requires approval")
+	}
+	if w.approvalRequired(map[string]dcrutil.Amount{"a": 6e8, "b": 5e8}) == nil {
+		t.Errorf("send above the threshold does not require approval")
+	}
+
+	// Sends which are signed without being queued are refused above the
+	// threshold, except when the wallet signs them on its own.
+	if err := w.checkSendApproval(10e8, "rpc:alice"); err != nil {
+		t.Errorf("unqueued send of the threshold amount refused: %v", err)
+	}
+	if _, ok := w.checkSendApproval(11e8, "rpc:alice").(SendNotApprovedError); !ok {
+		t.Errorf("unqueued send above the threshold was not refused")
+	}
+	if err := w.checkSendApproval(11e8, SigningOriginTicketBuyer); err != nil {
+		t.Errorf("ticket buyer send refused: %v", err)
+	}
+
+	newSend := func(index uint32, expiry time.Duration) *PendingSend {
+		tx := wire.NewMsgTx()
+		op := wire.OutPoint{Index: index}
+		tx.AddTxIn(wire.NewTxIn(&op, nil))
+		tx.AddTxOut(wire.NewTxOut(int64(index), nil))
+		w.LockOutpoint(op)
+		now := time.Now()
+		s := &PendingSend{
+			ID:      tx.TxSha(),
+			Origin:  "rpc:alice",
+			Tx:      tx,
+			Created: now.Add(time.Duration(index)),
+			Expires: now.Add(expiry),
+		}
+		w.addPendingSend(s)
+		return s
+	}
+	first := newSend(1, time.Hour)
+	second := newSend(2, time.Hour)
+	expiring := newSend(3, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	sends := w.PendingSends()
+	if len(sends) != 2 || sends[0] != first || sends[1] != second {
+		t.Fatalf("unexpected pending sends %v", sends)
+	}
+	if w.LockedOutpoint(expiring.Tx.TxIn[0].PreviousOutPoint) {
+		t.Errorf("input of expired send is still locked")
+	}
+
+	// Sends may not be approved by the origin which requested them, and
+	// remain pending.
+	_, err := w.ApprovePendingSend(&first.ID, false, "rpc:alice")
+	if err != ErrSelfApproval {
+		t.Errorf("self approval returned error %v", err)
+	}
+	if len(w.PendingSends()) != 2 {
+		t.Errorf("rejected self approval removed pending send")
+	}
+	if _, err := w.ApprovePendingSend(&expiring.ID, true, "rpc:bob"); err != ErrPendingSendNotFound {
+		t.Errorf("approval of expired send returned error %v", err)
+	}
+
+	if err := w.RejectPendingSend(&second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.RejectPendingSend(&second.ID); err != ErrPendingSendNotFound {
+		t.Errorf("second rejection returned error %v", err)
+	}
+	if w.LockedOutpoint(second.Tx.TxIn[0].PreviousOutPoint) {
+		t.Errorf("input of rejected send is still locked")
+	}
+	if !w.LockedOutpoint(first.Tx.TxIn[0].PreviousOutPoint) {
+		t.Errorf("input of pending send is not locked")
+	}
+}
+
+func TestApprovalRequiredExternal(t *testing.T) {
+	w, teardown := newTestWallet(t)
+	defer teardown()
+
+	w.SetSendApprovalPolicy(&SendApprovalPolicy{
+		Threshold: 10e8,
+		Expiry:    time.Hour,
+	})
+	addrs, _ := balanceTestAddrs(t, w)
+	owned, foreign := addrs[0].EncodeAddress(), addrs[2].EncodeAddress()
+
+	// Payments to the wallet do not count towards the threshold, as they
+	// do not when the transaction is signed.
+	pairs := map[string]dcrutil.Amount{owned: 20e8, foreign: 10e8}
+	if w.approvalRequired(pairs) != nil {
+		t.Errorf("payment to the wallet counted towards the threshold")
+	}
+	pairs[foreign] = 11e8
+	if w.approvalRequired(pairs) == nil {
+		t.Errorf("external send above the threshold does not require " +
+			"approval")
+	}
+}
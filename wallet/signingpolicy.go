@@ -0,0 +1,268 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+)
+
+// SigningPolicy restricts the transactions the wallet signs for requests of
+// restricted origins, such as RPC users without the admin permission.  The
+// policy is evaluated before a transaction is signed for a restricted origin,
+// and transactions which violate it are not signed.
+type SigningPolicy struct {
+	// Origins are the signing origins the policy is enforced for.
+	Origins map[string]struct{}
+
+	// DailyLimit is the maximum amount that transactions signed for the
+	// restricted origins may pay outside of the wallet within any 24
+	// hours, or zero when the amount is not limited.
+	DailyLimit dcrutil.Amount
+
+	// AllowedAddresses, when not empty, are the only addresses outside of
+	// the wallet that restricted transactions may pay, and
+	// BlockedAddresses may never be paid.  Addresses are keyed by their
+	// encoding.
+	AllowedAddresses map[string]struct{}
+	BlockedAddresses map[string]struct{}
+
+	// MinConf is the number of confirmations required of every output
+	// spent by restricted transactions.
+	MinConf int32
+
+	// Hours, when set, is the time of each day during which restricted
+	// transactions may be signed.
+	Hours *DailyWindow
+}
+
+// DailyWindow is a window of time during each day.  Start and End are
+// offsets from midnight in local time, and a window whose End is before its
+// Start wraps past midnight.
+type DailyWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseDailyWindow parses a daily window in the form HH:MM-HH:MM.
+func ParseDailyWindow(s string) (*DailyWindow, error) {
+	parseOffset := func(s string) (time.Duration, bool) {
+		parts := strings.Split(s, ":")
+		if len(parts) != 2 {
+			return 0, false
+		}
+		h, err := strconv.Atoi(parts[0])
+		if err != nil || h < 0 || h > 24 {
+			return 0, false
+		}
+		m, err := strconv.Atoi(parts[1])
+		if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+			return 0, false
+		}
+		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute,
+			true
+	}
+	bounds := strings.Split(s, "-")
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("daily window %q is not in the form "+
+			"HH:MM-HH:MM", s)
+	}
+	start, ok := parseOffset(bounds[0])
+	if !ok {
+		return nil, fmt.Errorf("invalid start time %q", bounds[0])
+	}
+	end, ok := parseOffset(bounds[1])
+	if !ok {
+		return nil, fmt.Errorf("invalid end time %q", bounds[1])
+	}
+	if start == end {
+		return nil, fmt.Errorf("daily window %q is empty", s)
+	}
+	return &DailyWindow{Start: start, End: end}, nil
+}
+
+// Contains returns whether the time t is within the window.
+func (d *DailyWindow) Contains(t time.Time) bool {
+	year, month, day := t.Date()
+	offset := t.Sub(time.Date(year, month, day, 0, 0, 0, 0, t.Location()))
+	if d.Start <= d.End {
+		return offset >= d.Start && offset < d.End
+	}
+	return offset >= d.Start || offset < d.End
+}
+
+// SigningPolicyError describes a transaction which was not signed for a
+// restricted origin because it violates the signing policy.
+type SigningPolicyError struct {
+	Origin string
+	Reason string
+}
+
+// Error satisfies the builtin error interface.
+func (e SigningPolicyError) Error() string {
+	return fmt.Sprintf("signing policy does not permit transaction for %s: "+
+		"%s", e.Origin, e.Reason)
+}
+
+// SigningPolicy returns the signing policy of the wallet, or nil if signing
+// is not restricted.
+func (w *Wallet) SigningPolicy() *SigningPolicy {
+	w.signingPolicyMu.Lock()
+	p := w.signingPolicy
+	w.signingPolicyMu.Unlock()
+	return p
+}
+
+// SetSigningPolicy replaces the signing policy of the wallet.  A nil policy
+// removes all restrictions.  The policy must not be modified after it is set.
+func (w *Wallet) SetSigningPolicy(p *SigningPolicy) {
+	w.signingPolicyMu.Lock()
+	w.signingPolicy = p
+	w.signingPolicyMu.Unlock()
+}
+
+// externalOutputs returns the total amount paid by the outputs of a
+// transaction which do not pay to the wallet, and the addresses they pay.
+// Outputs without addresses, such as the commitments of tickets, are counted
+// as external.
+func (w *Wallet) externalOutputs(tx *wire.MsgTx) (dcrutil.Amount,
+	[]dcrutil.Address) {
+	var amount dcrutil.Amount
+	var external []dcrutil.Address
+	for _, txOut := range tx.TxOut {
+		_, addrs, _, _ := txscript.ExtractPkScriptAddrs(txOut.Version,
+			txOut.PkScript, w.chainParams)
+		owned := len(addrs) != 0
+		for _, addr := range addrs {
+			if _, err := w.Manager.Address(addr); err != nil {
+				owned = false
+				break
+			}
+		}
+		if owned {
+			continue
+		}
+		amount += dcrutil.Amount(txOut.Value)
+		external = append(external, addrs...)
+	}
+	return amount, external
+}
+
+// restrictedSpending returns the total amount paid outside of the wallet by
+// the transactions signed for the restricted origins of a policy since the
+// time since.
+func (w *Wallet) restrictedSpending(p *SigningPolicy,
+	since time.Time) (dcrutil.Amount, error) {
+	recs, err := w.TxStore.SigningRecordsSince(since)
+	if err != nil {
+		return 0, err
+	}
+	var total dcrutil.Amount
+	for _, rec := range recs {
+		if _, ok := p.Origins[rec.Origin]; !ok {
+			continue
+		}
+		amount, _ := w.externalOutputs(&rec.MsgTx)
+		total += amount
+	}
+	return total, nil
+}
+
+// CheckSigningPolicy returns a SigningPolicyError if the signing policy does
+// not permit signing a transaction for origin.  It must be called before each
+// transaction is signed outside of the wallet package with wallet keys.
+func (w *Wallet) CheckSigningPolicy(tx *wire.MsgTx, origin string) error {
+	return w.checkSigningPolicy(tx, origin)
+}
+
+// checkSigningPolicy returns a SigningPolicyError if the signing policy does
+// not permit signing a transaction for origin.  It must be called before every
+// transaction created by the wallet is signed.
+func (w *Wallet) checkSigningPolicy(tx *wire.MsgTx, origin string) error {
+	p := w.SigningPolicy()
+	if p == nil {
+		return nil
+	}
+	if _, ok := p.Origins[origin]; !ok {
+		return nil
+	}
+	violation := func(format string, args ...interface{}) error {
+		return SigningPolicyError{
+			Origin: origin,
+			Reason: fmt.Sprintf(format, args...),
+		}
+	}
+
+	now := time.Now()
+	if p.Hours != nil && !p.Hours.Contains(now) {
+		return violation("transactions may not be signed at %s",
+			now.Format("15:04"))
+	}
+
+	if p.MinConf > 0 {
+		syncHeight := w.Manager.SyncedTo().Height
+		for _, txIn := range tx.TxIn {
+			op := &txIn.PreviousOutPoint
+			details, err := w.TxStore.TxDetails(&op.Hash)
+			if err != nil {
+				return err
+			}
+			// Outputs unknown to the wallet are not signed by it.
+			if details == nil {
+				continue
+			}
+			if !confirmed(p.MinConf, details.Height(), syncHeight) {
+				return violation("input %v has fewer than %d "+
+					"confirmations", op, p.MinConf)
+			}
+		}
+	}
+
+	amount, addrs := w.externalOutputs(tx)
+	for _, addr := range addrs {
+		encoded := addr.EncodeAddress()
+		if _, ok := p.BlockedAddresses[encoded]; ok {
+			return violation("address %v is blocked", encoded)
+		}
+		if len(p.AllowedAddresses) == 0 {
+			continue
+		}
+		if _, ok := p.AllowedAddresses[encoded]; !ok {
+			return violation("address %v is not allowed", encoded)
+		}
+	}
+
+	if p.DailyLimit > 0 {
+		spent, err := w.restrictedSpending(p, now.Add(-24*time.Hour))
+		if err != nil {
+			return err
+		}
+		if spent+amount > p.DailyLimit {
+			return violation("sending %v would exceed the daily "+
+				"limit of %v, of which %v was already sent",
+				amount, p.DailyLimit, spent)
+		}
+	}
+
+	return nil
+}
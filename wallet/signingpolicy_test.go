@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/wire"
+)
+
+func TestDailyWindow(t *testing.T) {
+	for _, s := range []string{"", "9:00", "9-17", "09:00-25:00",
+		"09:60-17:00", "09:00-09:00", "24:30-01:00"} {
+		if _, err := ParseDailyWindow(s); err == nil {
+			t.Errorf("ParseDailyWindow accepted %q", s)
+		}
+	}
+
+	at := func(hour, min int) time.Time {
+		return time.Date(2016, 6, 1, hour, min, 0, 0, time.Local)
+	}
+	tests := []struct {
+		window string
+		t      time.Time
+		in     bool
+	}{
+		{"09:00-17:30", at(9, 0), true},
+		{"09:00-17:30", at(17, 29), true},
+		{"09:00-17:30", at(17, 30), false},
+		{"09:00-17:30", at(8, 59), false},
+		{"22:00-06:00", at(23, 0), true},
+		{"22:00-06:00", at(5, 59), true},
+		{"22:00-06:00", at(12, 0), false},
+		{"00:00-24:00", at(23, 59), true},
+	}
+	for _, test := range tests {
+		w, err := ParseDailyWindow(test.window)
+		if err != nil {
+			t.Fatalf("ParseDailyWindow(%q) failed: %v", test.window,
+				err)
+		}
+		if w.Contains(test.t) != test.in {
+			t.Errorf("window %v contains %v: expected %v",
+				test.window, test.t.Format("15:04"), test.in)
+		}
+	}
+}
+
+func TestSigningPolicyOrigins(t *testing.T) {
+	// Restrict signing to a window of the day which does not contain the
+	// current time, so every transaction signed for a restricted origin
+	// violates the policy.
+	now := time.Now()
+	year, month, day := now.Date()
+	offset := now.Sub(time.Date(year, month, day, 0, 0, 0, 0, now.Location()))
+	hours := &DailyWindow{
+		Start: (offset + time.Hour) % (24 * time.Hour),
+		End:   (offset + 2*time.Hour) % (24 * time.Hour),
+	}
+	w := &Wallet{}
+	w.SetSigningPolicy(&SigningPolicy{
+		Origins: map[string]struct{}{"rpc:alice": {}},
+		Hours:   hours,
+	})
+
+	tx := wire.NewMsgTx()
+	err := w.checkSigningPolicy(tx, "rpc:alice")
+	if e, ok := err.(SigningPolicyError); !ok || e.Origin != "rpc:alice" {
+		t.Errorf("restricted origin returned error %v", err)
+	}
+
+	// The policy is evaluated for the origin a transaction is signed for,
+	// not for other origins signing concurrently, so the ticket buyer and
+	// unrestricted RPC users are not limited by it.
+	for _, origin := range []string{SigningOriginTicketBuyer,
+		SigningOriginWallet, "rpc:admin"} {
+		if err := w.checkSigningPolicy(tx, origin); err != nil {
+			t.Errorf("unrestricted origin %s returned error %v",
+				origin, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// Origins of signing operations which the wallet performs on its own.
+const (
+	// SigningOriginWallet is the origin of transactions the wallet signs on
+	// its own, such as votes and revocations.
+	SigningOriginWallet = "wallet"
+
+	// SigningOriginTicketBuyer is the origin of tickets purchased by the
+	// automatic ticket buyer.
+	SigningOriginTicketBuyer = "ticketbuyer"
+)
+
+// RecordSigning appends a signed transaction to the signing log, attributing
+// it to origin.  It must be called for each transaction signed outside of the
+// wallet package with wallet keys.
+func (w *Wallet) RecordSigning(tx *wire.MsgTx, origin string) error {
+	return w.recordSigning(tx, origin)
+}
+
+// recordSigning appends a signed transaction to the signing log and records
+// the indexes of its change addresses.
+func (w *Wallet) recordSigning(tx *wire.MsgTx, origin string) error {
+	seq, err := w.TxStore.AppendSigningRecord(origin, tx)
+	if err != nil {
+		log.Errorf("Failed to record signing of transaction %v in the "+
+			"signing log: %v", tx.TxSha(), err)
+		return err
+	}
+	log.Debugf("Recorded signing of transaction %v requested by %s "+
+		"(signing log record %d)", tx.TxSha(), origin, seq)
+
+	if err := w.recordChangeIndexes(tx); err != nil {
+		log.Errorf("Failed to record change indexes of transaction %v: %v",
+			tx.TxSha(), err)
+		return err
+	}
+	return nil
+}
+
+// recordStakeSigning records the votes and revocations signed by the stake
+// manager in the signing log.  These are always signed by the wallet on its
+// own, and are already published, so errors are only logged.
+func (w *Wallet) recordStakeSigning(ntfns []*wstakemgr.StakeNotification) {
+	for _, ntfn := range ntfns {
+		if ntfn != nil && ntfn.Tx != nil {
+			_ = w.recordSigning(ntfn.Tx, SigningOriginWallet)
+		}
+	}
+}
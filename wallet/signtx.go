@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// SignTransaction signs every input of a transaction which spends a P2PKH
+// output controlled by the wallet, returning the number of inputs signed.
+// Other inputs are left unchanged.  The transaction is checked against the
+// signing policy and recorded in the signing log as signed for origin, and is
+// not signed if it pays more than the send approval threshold outside of the
+// wallet.  The wallet must be unlocked unless it signs with a remote signer.
+func (w *Wallet) SignTransaction(msgTx *wire.MsgTx, origin string) (int, error) {
+	return w.signTransaction(msgTx, origin, false)
+}
+
+// signTransaction signs a transaction like SignTransaction.  Unless approved
+// is set, the send approval threshold applies to the transaction.
+func (w *Wallet) signTransaction(msgTx *wire.MsgTx, origin string,
+	approved bool) (int, error) {
+
+	release, err := w.holdSigningUnlock()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	if err := w.checkSigningPolicy(msgTx, origin); err != nil {
+		return 0, err
+	}
+	if !approved {
+		if err := w.CheckSendApproval(msgTx, origin); err != nil {
+			return 0, err
+		}
+	}
+
+	signed := 0
+	for i, txIn := range msgTx.TxIn {
+		prevOut := &txIn.PreviousOutPoint
+		details, err := w.TxStore.TxDetails(&prevOut.Hash)
+		if err != nil {
+			return signed, err
+		}
+		if details == nil ||
+			int(prevOut.Index) >= len(details.MsgTx.TxOut) {
+			continue
+		}
+		prevScript := details.MsgTx.TxOut[prevOut.Index].PkScript
+
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			txscript.DefaultScriptVersion, prevScript, w.chainParams)
+		if err != nil || len(addrs) != 1 {
+			continue
+		}
+		ai, err := w.Manager.Address(addrs[0])
+		if err != nil {
+			continue
+		}
+		if _, ok := ai.(waddrmgr.ManagedPubKeyAddress); !ok {
+			continue
+		}
+		sigScript, err := w.p2pkhSignatureScript(msgTx, i, prevScript, ai)
+		if err != nil {
+			return signed, err
+		}
+		txIn.SignatureScript = sigScript
+		signed++
+	}
+
+	if signed != 0 {
+		hash := msgTx.TxSha()
+		w.RecordAuthoredTx(&hash)
+		if err := w.recordSigning(msgTx, origin); err != nil {
+			return signed, err
+		}
+	}
+
+	return signed, nil
+}
+
+// PublishTransaction records a signed transaction in the transaction store
+// and broadcasts it to the network.  When the wallet is offline, the
+// transaction is broadcast after the wallet syncs with a chain server.
+func (w *Wallet) PublishTransaction(msgTx *wire.MsgTx) (*chainhash.Hash, error) {
+	if w.votingOnly {
+		return nil, ErrVotingOnly
+	}
+	for i, txIn := range msgTx.TxIn {
+		if len(txIn.SignatureScript) == 0 {
+			return nil, fmt.Errorf("transaction input %d is not signed", i)
+		}
+	}
+
+	hash, err := w.sendRawTransaction(msgTx)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := w.insertIntoTxMgr(msgTx)
+	if err != nil {
+		return nil, err
+	}
+	err = w.insertCreditsIntoTxMgr(msgTx, rec)
+	if err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
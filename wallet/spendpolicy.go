@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// SpendPolicy describes which unspent outputs may be spent by the
+// transactions created by the wallet.  It is enforced by the coin selection
+// of every created transaction.
+type SpendPolicy struct {
+	// MinConf is the number of confirmations required of spent outputs
+	// when a request does not specify it.
+	MinConf int32
+
+	// SpendUnconfirmedChange allows spending the unmined outputs of
+	// transactions which spend wallet funds, such as change and transfers
+	// between the wallet's own accounts, regardless of the confirmations
+	// otherwise required.
+	SpendUnconfirmedChange bool
+}
+
+// DefaultSpendPolicy is the spend policy of a wallet which was not configured
+// otherwise.  Spent outputs require a single confirmation.
+var DefaultSpendPolicy = SpendPolicy{MinConf: 1}
+
+// SpendPolicy returns the spend policy of the wallet.
+func (w *Wallet) SpendPolicy() SpendPolicy {
+	w.spendPolicyMu.Lock()
+	p := w.spendPolicy
+	w.spendPolicyMu.Unlock()
+	return p
+}
+
+// SetSpendPolicy replaces the spend policy of the wallet.
+func (w *Wallet) SetSpendPolicy(p SpendPolicy) error {
+	if p.MinConf < 0 {
+		return errors.New("minconf must not be negative")
+	}
+	w.spendPolicyMu.Lock()
+	w.spendPolicy = p
+	w.spendPolicyMu.Unlock()
+	return nil
+}
+
+// unminedChangeSpendable returns whether the unmined outputs of the
+// transaction with the given hash may be spent by the policy p despite not
+// meeting the required confirmations.  This is the case when the policy
+// allows spending unconfirmed change and the transaction debits wallet
+// credits.  Results are cached in spendsWallet across the outputs considered
+// by a single coin selection.
+func (w *Wallet) unminedChangeSpendable(p *SpendPolicy, hash *chainhash.Hash,
+	spendsWallet map[chainhash.Hash]bool) (bool, error) {
+	if !p.SpendUnconfirmedChange {
+		return false, nil
+	}
+	if spends, ok := spendsWallet[*hash]; ok {
+		return spends, nil
+	}
+	details, err := w.TxStore.TxDetails(hash)
+	if err != nil {
+		return false, err
+	}
+	spends := details != nil && len(details.Debits) != 0
+	spendsWallet[*hash] = spends
+	return spends, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// UnexpectedSpend describes a transaction spending wallet credits which was
+// neither created nor signed by this wallet process.  This happens when the
+// wallet's keys are used by another wallet, either a second instance of the
+// same wallet or an attacker who has compromised the keys.  Height is -1 for
+// unmined transactions.
+type UnexpectedSpend struct {
+	TxHash chainhash.Hash
+	Inputs []wire.OutPoint
+	Amount dcrutil.Amount
+	Height int32
+}
+
+// RecordAuthoredTx records that the transaction with the given hash was
+// signed by the wallet, so spends of wallet credits by the transaction are
+// expected.  Transactions created by the wallet are recorded in the
+// transaction store before they are published and do not need to be recorded.
+func (w *Wallet) RecordAuthoredTx(hash *chainhash.Hash) {
+	w.authoredTxsMu.Lock()
+	if w.authoredTxs == nil {
+		w.authoredTxs = make(map[chainhash.Hash]struct{})
+	}
+	w.authoredTxs[*hash] = struct{}{}
+	w.authoredTxsMu.Unlock()
+}
+
+// authoredTx returns whether the transaction was recorded as signed by the
+// wallet with RecordAuthoredTx.
+func (w *Wallet) authoredTx(hash *chainhash.Hash) bool {
+	w.authoredTxsMu.Lock()
+	_, ok := w.authoredTxs[*hash]
+	w.authoredTxsMu.Unlock()
+	return ok
+}
+
+// watchedTx returns whether a relevant transaction which was not yet known to
+// the transaction store must be checked for unexpected spends.  Only
+// transactions seen while the wallet is synced, either unmined or mined in
+// new blocks, are checked, so the history found by rescans does not raise
+// alerts.  Votes and revocations only spend tickets and pay the amounts
+// committed to by the ticket, so they are never unexpected.
+func (w *Wallet) watchedTx(tx *dcrutil.Tx, block *wtxmgr.BlockMeta) bool {
+	if !w.ChainSynced() || w.authoredTx(tx.Sha()) {
+		return false
+	}
+	if block != nil && block.Height < w.Manager.SyncedTo().Height {
+		return false
+	}
+	if is, _ := stake.IsSSGen(tx); is {
+		return false
+	}
+	if is, _ := stake.IsSSRtx(tx); is {
+		return false
+	}
+	return true
+}
+
+// checkUnexpectedSpend notifies an unexpected spend if the newly inserted
+// transaction debits any wallet credits.
+func (w *Wallet) checkUnexpectedSpend(rec *wtxmgr.TxRecord,
+	block *wtxmgr.BlockMeta) error {
+	details, err := w.TxStore.TxDetails(&rec.Hash)
+	if err != nil || details == nil || len(details.Debits) == 0 {
+		return err
+	}
+
+	spend := UnexpectedSpend{
+		TxHash: rec.Hash,
+		Inputs: make([]wire.OutPoint, 0, len(details.Debits)),
+		Height: -1,
+	}
+	for _, debit := range details.Debits {
+		txIn := rec.MsgTx.TxIn[debit.Index]
+		spend.Inputs = append(spend.Inputs, txIn.PreviousOutPoint)
+		spend.Amount += debit.Amount
+	}
+	if block != nil {
+		spend.Height = block.Height
+	}
+
+	log.Criticalf("UNEXPECTED SPEND: transaction %v spends %v of wallet "+
+		"funds but was not created by this wallet.  The wallet keys "+
+		"may be compromised or in use by another wallet.", rec.Hash,
+		spend.Amount)
+	w.notifyUnexpectedSpend(spend)
+	return nil
+}
@@ -0,0 +1,331 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// splitTicketCommitLimits are the fee limits encoded in the commitments of
+// split tickets.  They match the limits of tickets purchased by the wallet:
+// revocations may pay up to 2^24 atoms in fees, and votes may not pay fees.
+const splitTicketCommitLimits = uint16(0x5800)
+
+// SplitTicketContribution is a single input contributed by a participant to
+// a split ticket, along with the commitment and change outputs paying the
+// participant.  The commitment amount includes the participant's share of the
+// ticket price and of the transaction fee.  Each contribution must exactly
+// spend its input, that is, Amount must equal CommitAmount plus ChangeAmount.
+// ChangeAddr is an unspendable placeholder when ChangeAmount is zero.
+type SplitTicketContribution struct {
+	OutPoint     wire.OutPoint
+	Amount       dcrutil.Amount
+	CommitAddr   dcrutil.Address
+	CommitAmount dcrutil.Amount
+	ChangeAddr   dcrutil.Address
+	ChangeAmount dcrutil.Amount
+}
+
+// SplitTicketShares divides the ticket price and fee of a split ticket
+// between participants according to the passed fractions, which must be
+// positive and sum to one.  The returned amounts are the commitment amounts
+// each participant must contribute.  Any remainder lost to rounding is
+// assigned to the first participant.
+func SplitTicketShares(ticketPrice, fee dcrutil.Amount,
+	fractions []float64) ([]dcrutil.Amount, error) {
+
+	if len(fractions) == 0 {
+		return nil, fmt.Errorf("no split ticket participants")
+	}
+	sum := 0.0
+	for _, f := range fractions {
+		if f <= 0 {
+			return nil, fmt.Errorf("participant fractions must be positive")
+		}
+		sum += f
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		return nil, fmt.Errorf("participant fractions sum to %v, not 1", sum)
+	}
+
+	total := ticketPrice + fee
+	shares := make([]dcrutil.Amount, len(fractions))
+	var assigned dcrutil.Amount
+	for i, f := range fractions {
+		shares[i] = dcrutil.Amount(float64(total) * f)
+		assigned += shares[i]
+	}
+	shares[0] += total - assigned
+
+	return shares, nil
+}
+
+// CreateSplitTicketContribution selects unspent outputs from an account
+// totaling at least amount and creates the contributions committing exactly
+// amount to a split ticket, with any excess returned as change.  The selected
+// outputs are locked so they are not spent by other transactions; they must
+// be unlocked with CancelSplitTicketContribution if the split ticket is not
+// published.
+func (w *Wallet) CreateSplitTicketContribution(account uint32,
+	amount dcrutil.Amount, minconf int32) ([]*SplitTicketContribution, error) {
+
+	if amount <= 0 {
+		return nil, ErrNonPositiveAmount
+	}
+
+	bs, err := w.chainSvr.BlockStamp()
+	if err != nil {
+		return nil, err
+	}
+	eligible, err := w.findEligibleOutputsAmount(account, minconf, amount, bs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(ByAmount(eligible)))
+
+	var contribs []*SplitTicketContribution
+	var committed dcrutil.Amount
+	for _, credit := range eligible {
+		if committed >= amount {
+			break
+		}
+		if len(contribs) >= stake.MaxInputsPerSStx {
+			return nil, ErrSStxInputOverflow
+		}
+
+		commitAddr, err := w.NewAddress(account)
+		if err != nil {
+			return nil, err
+		}
+		commit := credit.Amount
+		if committed+commit > amount {
+			commit = amount - committed
+		}
+		committed += commit
+
+		// Only change which pays a value is sent to a wallet address.
+		var changeAddr dcrutil.Address
+		if credit.Amount > commit {
+			changeAddr, err = w.NewChangeAddress(account)
+		} else {
+			changeAddr, err = w.zeroChangeAddress()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		contribs = append(contribs, &SplitTicketContribution{
+			OutPoint:     credit.OutPoint,
+			Amount:       credit.Amount,
+			CommitAddr:   commitAddr,
+			CommitAmount: commit,
+			ChangeAddr:   changeAddr,
+			ChangeAmount: credit.Amount - commit,
+		})
+	}
+	if committed < amount {
+		return nil, ErrSStxNotEnoughFunds
+	}
+
+	for _, c := range contribs {
+		w.LockOutpoint(c.OutPoint)
+	}
+
+	return contribs, nil
+}
+
+// CancelSplitTicketContribution unlocks the outputs selected for split ticket
+// contributions so they may be spent by other transactions.
+func (w *Wallet) CancelSplitTicketContribution(contribs []*SplitTicketContribution) {
+	for _, c := range contribs {
+		w.UnlockOutpoint(c.OutPoint)
+	}
+}
+
+// NewSplitTicket creates an unsigned split ticket purchasing a ticket for
+// ticketPrice with the voting rights assigned to voteAddr, which is commonly
+// a multisig script address shared by the participants or the address of a
+// stake pool.  The ticket pays the difference between the commitments and
+// the ticket price as the transaction fee.
+func NewSplitTicket(voteAddr dcrutil.Address, ticketPrice dcrutil.Amount,
+	contribs []*SplitTicketContribution) (*wire.MsgTx, error) {
+
+	if len(contribs) == 0 {
+		return nil, fmt.Errorf("no split ticket contributions")
+	}
+	if len(contribs) > stake.MaxInputsPerSStx {
+		return nil, ErrSStxInputOverflow
+	}
+
+	msgTx := wire.NewMsgTx()
+	pkScript, err := txscript.PayToSStx(voteAddr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create txout script: %s", err)
+	}
+	msgTx.AddTxOut(wire.NewTxOut(int64(ticketPrice), pkScript))
+
+	var committed dcrutil.Amount
+	for _, c := range contribs {
+		if c.CommitAmount <= 0 || c.ChangeAmount < 0 ||
+			c.CommitAmount+c.ChangeAmount != c.Amount {
+			return nil, fmt.Errorf("contribution spending %v does not "+
+				"exactly spend its input", c.OutPoint)
+		}
+		committed += c.CommitAmount
+
+		prevOut := c.OutPoint
+		txIn := wire.NewTxIn(&prevOut, nil)
+		txIn.ValueIn = int64(c.Amount)
+		msgTx.AddTxIn(txIn)
+
+		pkScript, err := txscript.GenerateSStxAddrPush(c.CommitAddr,
+			c.CommitAmount, splitTicketCommitLimits)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create txout script: %s", err)
+		}
+		msgTx.AddTxOut(wire.NewTxOut(0, pkScript))
+
+		err = addSStxChange(msgTx, c.ChangeAmount, c.ChangeAddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if committed < ticketPrice {
+		return nil, ErrSStxNotEnoughFunds
+	}
+
+	if _, err := stake.IsSStx(dcrutil.NewTx(msgTx)); err != nil {
+		return nil, err
+	}
+
+	return msgTx, nil
+}
+
+// SignSplitTicket signs every input of a split ticket which spends an output
+// controlled by the wallet, returning the number of inputs signed.  Inputs
+// contributed by other participants are left unchanged.  The ticket is signed
+// for origin.  The wallet must be unlocked.  A FeeLimitExceededError is
+// returned if the fee of the ticket, the amount committed above the ticket
+// price, exceeds the fee limits of the wallet.
+func (w *Wallet) SignSplitTicket(msgTx *wire.MsgTx, origin string) (int, error) {
+	tx := dcrutil.NewTx(msgTx)
+	if _, err := stake.IsSStx(tx); err != nil {
+		return 0, err
+	}
+
+	_, _, amts, _, _, _ := stake.GetSStxStakeOutputInfo(tx)
+	var committed dcrutil.Amount
+	for _, amt := range amts {
+		committed += dcrutil.Amount(amt)
+	}
+	ticketPrice := dcrutil.Amount(msgTx.TxOut[0].Value)
+	if err := w.checkFeeLimit(committed-ticketPrice, ticketPrice); err != nil {
+		return 0, err
+	}
+
+	return w.SignTransaction(msgTx, origin)
+}
+
+// PublishSplitTicket broadcasts a split ticket signed by all participants.
+func (w *Wallet) PublishSplitTicket(msgTx *wire.MsgTx) (*chainhash.Hash, error) {
+	if w.chainSvr == nil {
+		return nil, ErrOffline
+	}
+	if _, err := stake.IsSStx(dcrutil.NewTx(msgTx)); err != nil {
+		return nil, err
+	}
+	for i, txIn := range msgTx.TxIn {
+		if len(txIn.SignatureScript) == 0 {
+			return nil, fmt.Errorf("split ticket input %d is not signed", i)
+		}
+	}
+
+	return w.sendRawTransaction(msgTx)
+}
+
+// SplitTickets returns all split tickets the wallet contributed to.
+func (w *Wallet) SplitTickets() ([]*wstakemgr.SplitTicket, error) {
+	return w.StakeMgr.SplitTickets()
+}
+
+// recordSplitTicket records a ticket as a split ticket if some, but not all,
+// of its commitments pay the wallet.  The outputs spent by the ticket are
+// unlocked as they were contributed and are no longer unspent.  The returned
+// bool reports whether the ticket was recorded.
+func (w *Wallet) recordSplitTicket(tx *dcrutil.Tx) bool {
+	payTypes, pkhs, amts, _, _, _ := stake.GetSStxStakeOutputInfo(tx)
+
+	var contribution, total int64
+	ownedAll := true
+	for i := range pkhs {
+		var addr dcrutil.Address
+		var err error
+		if payTypes[i] {
+			addr, err = dcrutil.NewAddressScriptHashFromHash(pkhs[i],
+				w.chainParams)
+		} else {
+			addr, err = dcrutil.NewAddressPubKeyHash(pkhs[i],
+				w.chainParams, chainec.ECTypeSecp256k1)
+		}
+		if err != nil {
+			return false
+		}
+
+		total += amts[i]
+		if _, err := w.Manager.Address(addr); err == nil {
+			contribution += amts[i]
+		} else {
+			ownedAll = false
+		}
+	}
+	if contribution == 0 || ownedAll {
+		return false
+	}
+
+	for _, txIn := range tx.MsgTx().TxIn {
+		w.UnlockOutpoint(txIn.PreviousOutPoint)
+	}
+
+	err := w.StakeMgr.InsertSplitTicket(&wstakemgr.SplitTicket{
+		Ticket:       *tx.Sha(),
+		Contribution: dcrutil.Amount(contribution),
+		Total:        dcrutil.Amount(total),
+	})
+	if err != nil {
+		log.Errorf("Failed to record split ticket %v: %v", tx.Sha(), err)
+		return false
+	}
+
+	return true
+}
+
+// isSplitTicket returns whether the wallet contributed to a ticket as a
+// split ticket.
+func (w *Wallet) isSplitTicket(hash *chainhash.Hash) bool {
+	record, err := w.StakeMgr.SplitTicket(hash)
+	return err == nil && record != nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+)
+
+// VoteDetails describes a vote: the ticket it spends, the block it votes
+// on, and its vote bits.
+type VoteDetails struct {
+	Ticket      chainhash.Hash
+	BlockHash   chainhash.Hash
+	BlockHeight uint32
+	VoteBits    uint16
+}
+
+// RevocationDetails describes a revocation: the ticket it spends and the
+// amount refunded to the ticket's commitment addresses.
+type RevocationDetails struct {
+	Ticket   chainhash.Hash
+	Refunded dcrutil.Amount
+}
+
+// StakeDetails describes the stake-specific details of a wallet transaction.
+// At most one of the fields is set, matching the transaction's type, and
+// none are set for regular transactions.
+type StakeDetails struct {
+	Ticket     *TicketCommitments
+	Vote       *VoteDetails
+	Revocation *RevocationDetails
+}
+
+// nullDataPush returns the data pushed by an OP_RETURN output script with a
+// single data push of at least minSize bytes.
+func nullDataPush(pkScript []byte, minSize int) ([]byte, bool) {
+	if len(pkScript) < 2 || pkScript[0] != txscript.OP_RETURN {
+		return nil, false
+	}
+	size := int(pkScript[1])
+	if size < minSize || size > txscript.OP_DATA_75 ||
+		len(pkScript) != 2+size {
+		return nil, false
+	}
+	return pkScript[2:], true
+}
+
+// voteDetails decodes the details of a vote.  The first output commits to
+// the hash and height of the block voted on, and the second output begins
+// with the vote bits.
+func voteDetails(tx *wire.MsgTx) (*VoteDetails, error) {
+	if len(tx.TxIn) < 2 || len(tx.TxOut) < 2 {
+		return nil, fmt.Errorf("vote %v is malformed", tx.TxSha())
+	}
+	block, ok := nullDataPush(tx.TxOut[0].PkScript, chainhash.HashSize+4)
+	if !ok {
+		return nil, fmt.Errorf("vote %v has a malformed block "+
+			"commitment", tx.TxSha())
+	}
+	voteBits, ok := nullDataPush(tx.TxOut[1].PkScript, 2)
+	if !ok {
+		return nil, fmt.Errorf("vote %v has malformed vote bits",
+			tx.TxSha())
+	}
+	v := &VoteDetails{
+		Ticket: tx.TxIn[1].PreviousOutPoint.Hash,
+		BlockHeight: binary.LittleEndian.Uint32(
+			block[chainhash.HashSize:]),
+		VoteBits: binary.LittleEndian.Uint16(voteBits),
+	}
+	copy(v.BlockHash[:], block[:chainhash.HashSize])
+	return v, nil
+}
+
+// revocationDetails decodes the details of a revocation.
+func revocationDetails(tx *wire.MsgTx) (*RevocationDetails, error) {
+	if len(tx.TxIn) < 1 {
+		return nil, fmt.Errorf("revocation %v is malformed", tx.TxSha())
+	}
+	r := &RevocationDetails{Ticket: tx.TxIn[0].PreviousOutPoint.Hash}
+	for _, txOut := range tx.TxOut {
+		r.Refunded += dcrutil.Amount(txOut.Value)
+	}
+	return r, nil
+}
+
+// StakeDetails returns the stake-specific details of a wallet transaction,
+// decoded from the transaction saved by the wallet.  A nil result is returned
+// for regular transactions.
+func (w *Wallet) StakeDetails(txHash *chainhash.Hash) (*StakeDetails, error) {
+	details, err := w.TxStore.TxDetails(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, fmt.Errorf("transaction %v not found", txHash)
+	}
+
+	return w.stakeDetails(&details.MsgTx, details.TxType)
+}
+
+// stakeDetails decodes the stake-specific details of a transaction of a
+// transaction type.  The transaction does not need to be saved by the wallet.
+func (w *Wallet) stakeDetails(tx *wire.MsgTx,
+	txType stake.TxType) (*StakeDetails, error) {
+	switch txType {
+	case stake.TxTypeSStx:
+		commitments, err := w.ticketCommitments(tx)
+		if err != nil {
+			return nil, err
+		}
+		return &StakeDetails{Ticket: commitments}, nil
+	case stake.TxTypeSSGen:
+		vote, err := voteDetails(tx)
+		if err != nil {
+			return nil, err
+		}
+		return &StakeDetails{Vote: vote}, nil
+	case stake.TxTypeSSRtx:
+		revocation, err := revocationDetails(tx)
+		if err != nil {
+			return nil, err
+		}
+		return &StakeDetails{Revocation: revocation}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,341 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+)
+
+// stakeDiffWaitMargin is the fraction by which the expected ticket price of
+// the next stake difficulty window must be lower than the current price for
+// the ticket buyer to wait for the next window instead of purchasing.
+const stakeDiffWaitMargin = 0.05
+
+// StakeDifficultyEstimate is a forecast of the ticket price of the stake
+// difficulty window following the current window.  The next price depends
+// on the tickets still mined in the current window, so the forecast is made
+// for the least, the expected, and the greatest number of tickets that may
+// still be mined.
+type StakeDifficultyEstimate struct {
+	Height    int64          // Height of the block the forecast is made at
+	Remaining int64          // Blocks remaining in the current window
+	Current   dcrutil.Amount // Ticket price of the current window
+	Min       dcrutil.Amount // Next price if no more tickets are mined
+	Expected  dcrutil.Amount // Next price if tickets are mined at the current rate
+	Max       dcrutil.Amount // Next price if every remaining block is full
+}
+
+// stakeDiffWindow summarizes a stake difficulty window for the calculation
+// of the stake difficulty.
+type stakeDiffWindow struct {
+	poolSize   int64 // Live tickets after the first block of the window
+	freshStake int64 // Tickets mined in the window
+}
+
+// stakeDiffBlock is the part of a block header used to estimate the stake
+// difficulty, cached to avoid fetching every block of the recent windows for
+// each estimate.
+type stakeDiffBlock struct {
+	hash       chainhash.Hash
+	prevHash   chainhash.Hash
+	poolSize   int64
+	freshStake int64
+}
+
+// weightedStakeDiff returns oldDiff scaled by the exponentially weighted
+// average ratio of the window values to target, with the most recent window
+// first and weighted the most.  Fixed point 32.32 arithmetic is used to
+// match the consensus calculation exactly.
+func weightedStakeDiff(params *chaincfg.Params, oldDiff int64, values []int64,
+	target int64) int64 {
+	weightedSum := big.NewInt(0)
+	weights := int64(0)
+	for i, value := range values {
+		// Don't let the value be negative or zero.
+		if value <= 0 {
+			value = 1
+		}
+		shift := uint((params.StakeDiffWindows - int64(i)) *
+			params.StakeDiffAlpha)
+
+		change := big.NewInt(value)
+		change.Lsh(change, 32)
+		change.Div(change, big.NewInt(target))
+		change.Lsh(change, shift)
+		weightedSum.Add(weightedSum, change)
+		weights += 1 << shift
+	}
+	if weights == 0 {
+		return oldDiff
+	}
+
+	weightedSum.Div(weightedSum, big.NewInt(weights))
+	weightedSum.Mul(weightedSum, big.NewInt(oldDiff))
+	weightedSum.Rsh(weightedSum, 32)
+	return weightedSum.Int64()
+}
+
+// limitRetarget limits the change from oldDiff to nextDiff to the maximum
+// retarget adjustment factor of the network.
+func limitRetarget(params *chaincfg.Params, oldDiff, nextDiff int64) int64 {
+	maxRetarget := params.RetargetAdjustmentFactor
+	switch {
+	case oldDiff == 0:
+		return nextDiff
+	case nextDiff == 0:
+		return oldDiff / maxRetarget
+	case nextDiff/oldDiff > maxRetarget-1:
+		return oldDiff * maxRetarget
+	case oldDiff/nextDiff > maxRetarget-1:
+		return oldDiff / maxRetarget
+	}
+	return nextDiff
+}
+
+// mergeStakeDiff combines the changes from oldDiff to newDiff1 and newDiff2
+// using scaled multiplication.
+func mergeStakeDiff(oldDiff, newDiff1, newDiff2 int64) int64 {
+	oldDiffBig := big.NewInt(oldDiff)
+	change1 := new(big.Int).Lsh(oldDiffBig, 32)
+	change1.Div(change1, big.NewInt(newDiff1))
+	change2 := new(big.Int).Lsh(big.NewInt(newDiff2), 32)
+	change2.Div(change2, oldDiffBig)
+
+	merged := new(big.Int).Lsh(change2, 32)
+	merged.Div(merged, change1)
+	merged.Mul(merged, oldDiffBig)
+	merged.Rsh(merged, 32)
+	return merged.Int64()
+}
+
+// nextStakeDifficulty returns the stake difficulty of the window following
+// the windows summarized by windows, most recent window first, when the
+// stake difficulty of the most recent window is oldDiff.  This is the
+// consensus calculation of the network, which adjusts the difficulty by both
+// the size of the live ticket pool and the number of tickets purchased in
+// each window, relative to their targets.  Only the windows passed are
+// weighted, so the result is an estimate when fewer than StakeDiffWindows
+// windows are known.
+func nextStakeDifficulty(params *chaincfg.Params, oldDiff int64,
+	windows []stakeDiffWindow) int64 {
+	if oldDiff == 0 || len(windows) == 0 {
+		return oldDiff
+	}
+	if int64(len(windows)) > params.StakeDiffWindows {
+		windows = windows[:params.StakeDiffWindows]
+	}
+
+	// Skew the difference in pool size from the target by the pool size
+	// weight to weight the pool size against the tickets per window.
+	targetPoolSize := int64(params.TicketsPerBlock) *
+		int64(params.TicketPoolSize)
+	poolSizeWeight := int64(params.TicketPoolSizeWeight)
+	poolSizes := make([]int64, len(windows))
+	freshStake := make([]int64, len(windows))
+	for i, window := range windows {
+		poolSizes[i] = (window.poolSize-targetPoolSize)*poolSizeWeight +
+			targetPoolSize
+		freshStake[i] = window.freshStake
+	}
+	targetFreshStake := params.StakeDiffWindowSize *
+		int64(params.TicketsPerBlock)
+
+	nextDiffPoolSize := limitRetarget(params, oldDiff,
+		weightedStakeDiff(params, oldDiff, poolSizes, targetPoolSize))
+	nextDiffFreshStake := limitRetarget(params, oldDiff,
+		weightedStakeDiff(params, oldDiff, freshStake, targetFreshStake))
+	if nextDiffPoolSize == 0 || nextDiffFreshStake == 0 {
+		return params.MinimumStakeDiff
+	}
+
+	nextDiff := limitRetarget(params, oldDiff, mergeStakeDiff(oldDiff,
+		nextDiffPoolSize, nextDiffFreshStake))
+	if nextDiff < params.MinimumStakeDiff {
+		return params.MinimumStakeDiff
+	}
+	return nextDiff
+}
+
+// projectWindowFreshStake returns the least, expected and greatest number of
+// tickets mined in a stake difficulty window, when observed tickets were
+// mined in the first observedBlocks blocks of the window and remaining
+// blocks remain.  Tickets are expected to continue to be mined at the rate
+// observed, or at the rate of the previous window, which mined prevFresh
+// tickets, when no blocks of the window have been observed.
+func projectWindowFreshStake(params *chaincfg.Params, observed, observedBlocks,
+	remaining, prevFresh int64) (min, expected, max int64) {
+	min = observed
+	max = observed + remaining*int64(params.MaxFreshStakePerBlock)
+	if observedBlocks > 0 {
+		expected = observed * (observedBlocks + remaining) / observedBlocks
+	} else {
+		expected = prevFresh
+	}
+	if expected < min {
+		expected = min
+	}
+	if expected > max {
+		expected = max
+	}
+	return min, expected, max
+}
+
+// stakeDiffBlockRange returns the cached block summaries of the blocks from
+// height first through the block with the passed hash at height, fetching
+// the blocks which are not cached or were replaced by a reorganization.
+// Summaries outside of the range are removed from the cache.
+func (w *Wallet) stakeDiffBlockRange(hash *chainhash.Hash, height,
+	first int64) ([]stakeDiffBlock, error) {
+	w.stakeDiffMu.Lock()
+	defer w.stakeDiffMu.Unlock()
+
+	if w.stakeDiffBlocks == nil {
+		w.stakeDiffBlocks = make(map[int64]stakeDiffBlock)
+	}
+	for h := range w.stakeDiffBlocks {
+		if h < first || h > height {
+			delete(w.stakeDiffBlocks, h)
+		}
+	}
+
+	blocks := make([]stakeDiffBlock, height-first+1)
+	next := *hash
+	for h := height; h >= first; h-- {
+		b, ok := w.stakeDiffBlocks[h]
+		if !ok || b.hash != next {
+			block, err := w.chainSvr.GetBlock(&next)
+			if err != nil {
+				return nil, err
+			}
+			header := &block.MsgBlock().Header
+			b = stakeDiffBlock{
+				hash:       next,
+				prevHash:   header.PrevBlock,
+				poolSize:   int64(header.PoolSize),
+				freshStake: int64(header.FreshStake),
+			}
+			w.stakeDiffBlocks[h] = b
+		}
+		blocks[h-first] = b
+		next = b.prevHash
+	}
+	return blocks, nil
+}
+
+// estimateStakeDifficulty forecasts the ticket price of the stake difficulty
+// window following the window of the block after the block with the passed
+// hash and height.  The current window is projected from the tickets mined
+// in it so far, and the previous windows are summarized from their blocks.
+func (w *Wallet) estimateStakeDifficulty(hash *chainhash.Hash,
+	height int64) (*StakeDifficultyEstimate, error) {
+	stakeDiff := w.GetStakeDifficulty()
+	if stakeDiff == nil || stakeDiff.StakeDifficulty <= 0 {
+		return nil, errors.New("ticket price not yet established")
+	}
+	oldDiff := stakeDiff.StakeDifficulty
+
+	params := w.chainParams
+	size := params.StakeDiffWindowSize
+	window, remaining := stakeWindowPosition(params, height)
+	windowStart := window * size
+	first := (window - params.StakeDiffWindows + 1) * size
+	if first < 0 {
+		first = 0
+	}
+	blocks, err := w.stakeDiffBlockRange(hash, height, first)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pool size of the current window is that of the current block
+	// until the first block of the window is mined.
+	var observed int64
+	observedBlocks := height - windowStart + 1
+	current := stakeDiffWindow{poolSize: blocks[height-first].poolSize}
+	if observedBlocks > 0 {
+		current.poolSize = blocks[windowStart-first].poolSize
+		for _, b := range blocks[windowStart-first:] {
+			observed += b.freshStake
+		}
+	}
+
+	windows := []stakeDiffWindow{current}
+	for start := windowStart - size; start >= first; start -= size {
+		summary := stakeDiffWindow{poolSize: blocks[start-first].poolSize}
+		for _, b := range blocks[start-first : start-first+size] {
+			summary.freshStake += b.freshStake
+		}
+		windows = append(windows, summary)
+	}
+	var prevFresh int64
+	if len(windows) > 1 {
+		prevFresh = windows[1].freshStake
+	}
+
+	min, expected, max := projectWindowFreshStake(params, observed,
+		observedBlocks, remaining, prevFresh)
+	estimate := func(freshStake int64) dcrutil.Amount {
+		windows[0].freshStake = freshStake
+		return dcrutil.Amount(nextStakeDifficulty(params, oldDiff, windows))
+	}
+	return &StakeDifficultyEstimate{
+		Height:    height,
+		Remaining: remaining,
+		Current:   dcrutil.Amount(oldDiff),
+		Min:       estimate(min),
+		Expected:  estimate(expected),
+		Max:       estimate(max),
+	}, nil
+}
+
+// EstimateStakeDifficulty forecasts the ticket price of the stake difficulty
+// window following the current window from the live ticket pool size and the
+// tickets purchased in the recent windows.
+func (w *Wallet) EstimateStakeDifficulty() (*StakeDifficultyEstimate, error) {
+	bs := w.Manager.SyncedTo()
+	return w.estimateStakeDifficulty(&bs.Hash, int64(bs.Height))
+}
+
+// stakeDiffForecastWait returns whether the ticket buyer should wait for the
+// next stake difficulty window instead of purchasing tickets at the block
+// after the block with the passed hash and height, along with the reason.
+// Purchases are only delayed in the second half of a window, when the ticket
+// price of the next window is expected to be lower by more than
+// stakeDiffWaitMargin.
+func (w *Wallet) stakeDiffForecastWait(hash *chainhash.Hash,
+	height int64) (bool, string, error) {
+	_, remaining := stakeWindowPosition(w.chainParams, height)
+	if remaining > w.chainParams.StakeDiffWindowSize/2 {
+		return false, "", nil
+	}
+	estimate, err := w.estimateStakeDifficulty(hash, height)
+	if err != nil {
+		return false, "", err
+	}
+	limit := float64(estimate.Current) * (1 - stakeDiffWaitMargin)
+	if float64(estimate.Expected) >= limit {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("next ticket price is expected to decrease "+
+		"to %v in %d blocks", estimate.Expected, remaining), nil
+}
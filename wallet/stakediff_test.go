@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+)
+
+// targetStakeDiffWindows returns n windows at the target pool size and
+// number of tickets per window.
+func targetStakeDiffWindows(params *chaincfg.Params, n int) []stakeDiffWindow {
+	windows := make([]stakeDiffWindow, n)
+	for i := range windows {
+		windows[i] = stakeDiffWindow{
+			poolSize: int64(params.TicketsPerBlock) *
+				int64(params.TicketPoolSize),
+			freshStake: params.StakeDiffWindowSize *
+				int64(params.TicketsPerBlock),
+		}
+	}
+	return windows
+}
+
+func TestNextStakeDifficulty(t *testing.T) {
+	for _, params := range ticketWindowNetParams {
+		const oldDiff = 20e8
+		n := int(params.StakeDiffWindows)
+
+		windows := targetStakeDiffWindows(params, n)
+		if diff := nextStakeDifficulty(params, oldDiff, windows); diff != oldDiff {
+			t.Errorf("%s: targets met: got %d, want %d", params.Name,
+				diff, int64(oldDiff))
+		}
+
+		windows = targetStakeDiffWindows(params, n)
+		windows[0].freshStake *= 2
+		if diff := nextStakeDifficulty(params, oldDiff, windows); diff <= oldDiff {
+			t.Errorf("%s: more tickets purchased: got %d, want more "+
+				"than %d", params.Name, diff, int64(oldDiff))
+		}
+
+		windows = targetStakeDiffWindows(params, n)
+		windows[0].freshStake = 0
+		diff := nextStakeDifficulty(params, oldDiff, windows)
+		if diff >= oldDiff {
+			t.Errorf("%s: no tickets purchased: got %d, want less "+
+				"than %d", params.Name, diff, int64(oldDiff))
+		}
+		if diff < oldDiff/params.RetargetAdjustmentFactor {
+			t.Errorf("%s: no tickets purchased: got %d, exceeding "+
+				"the maximum retarget", params.Name, diff)
+		}
+
+		windows = targetStakeDiffWindows(params, n)
+		windows[0].freshStake = 0
+		diff = nextStakeDifficulty(params, params.MinimumStakeDiff, windows)
+		if diff != params.MinimumStakeDiff {
+			t.Errorf("%s: minimum price: got %d, want %d", params.Name,
+				diff, params.MinimumStakeDiff)
+		}
+	}
+}
+
+func TestProjectWindowFreshStake(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	maxPerBlock := int64(params.MaxFreshStakePerBlock)
+	tests := []struct {
+		observed, observedBlocks, remaining, prevFresh int64
+		min, expected, max                             int64
+	}{
+		{0, 0, 144, 500, 0, 500, 144 * maxPerBlock},
+		{0, 0, 144, 1e6, 0, 144 * maxPerBlock, 144 * maxPerBlock},
+		{100, 72, 72, 0, 100, 200, 100 + 72*maxPerBlock},
+		{100, 144, 0, 0, 100, 100, 100},
+	}
+	for i, test := range tests {
+		min, expected, max := projectWindowFreshStake(params, test.observed,
+			test.observedBlocks, test.remaining, test.prevFresh)
+		if min != test.min || expected != test.expected || max != test.max {
+			t.Errorf("test %d: got %d/%d/%d, want %d/%d/%d", i, min,
+				expected, max, test.min, test.expected, test.max)
+		}
+	}
+}
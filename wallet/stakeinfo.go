@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrutil"
+)
+
+// StakeInfo describes the tickets of the wallet in each stage of their
+// lifecycle and the subsidy earned by voting.
+type StakeInfo struct {
+	BlockHeight int32
+	Difficulty  dcrutil.Amount
+
+	OwnMempoolTix int
+	Immature      int
+	Live          int
+	Voted         int
+	Missed        int
+	Expired       int
+	Revoked       int
+
+	TotalSubsidy dcrutil.Amount
+}
+
+// StakeInfo returns the stake info of the wallet.  It is computed entirely
+// from the ticket lifecycle records of the stake store and the last stake
+// difficulty notified by the chain server, without querying the chain server
+// about individual tickets.
+func (w *Wallet) StakeInfo() (*StakeInfo, error) {
+	summary, err := w.StakeMgr.TicketSummary()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &StakeInfo{
+		BlockHeight:   w.Manager.SyncedTo().Height,
+		OwnMempoolTix: summary.Unmined,
+		Immature:      summary.Immature,
+		Live:          summary.Live,
+		Voted:         summary.Voted,
+		Missed:        summary.Missed,
+		Expired:       summary.Expired,
+		Revoked:       summary.Revoked,
+		TotalSubsidy:  summary.TotalSubsidy,
+	}
+	if sdiff := w.GetStakeDifficulty(); sdiff != nil &&
+		sdiff.StakeDifficulty > 0 {
+		info.Difficulty = dcrutil.Amount(sdiff.StakeDifficulty)
+	}
+
+	return info, nil
+}
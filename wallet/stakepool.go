@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"errors"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wstakemgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// ErrStakePoolDisabled describes the error where a stake pool operation is
+// requested but the wallet is not running in stake pool mode.
+var ErrStakePoolDisabled = errors.New("stake pool mode is not enabled")
+
+// StakePoolUserInfo returns the tickets submitted to the stake pool by a
+// user, identified by the address of their ticket commitment, along with
+// the tickets which were rejected for paying an insufficient pool fee.
+func (w *Wallet) StakePoolUserInfo(user dcrutil.Address) (*wstakemgr.StakePoolUser,
+	error) {
+	if !w.stakePoolEnabled {
+		return nil, ErrStakePoolDisabled
+	}
+	return w.StakeMgr.StakePoolUserInfo(user)
+}
+
+// PoolFees returns the address pool fees must be committed to and the
+// minimum percentage of a ticket's commitment which must be paid to it.
+func (w *Wallet) PoolFees() (dcrutil.Address, float64) {
+	return w.poolAddress, w.poolFees
+}
+
+// stakePoolTicketUser returns the address of the user who submitted the
+// ticket to the stake pool, and whether the ticket commits at least the
+// configured pool fee percentage to the pool address.  The user is the first
+// commitment address other than the pool address.  A nil address is
+// returned if every commitment of the ticket pays an address controlled by
+// the wallet, as the ticket was then purchased by the wallet itself.
+func (w *Wallet) stakePoolTicketUser(tx *dcrutil.Tx) (dcrutil.Address, bool,
+	error) {
+
+	payTypes, pkhs, amts, _, _, _ := stake.GetSStxStakeOutputInfo(tx)
+
+	poolAddr := w.poolAddress.EncodeAddress()
+	var user dcrutil.Address
+	var poolAmt, totalAmt int64
+	ownedAll := true
+	for i := range pkhs {
+		var addr dcrutil.Address
+		var err error
+		if payTypes[i] {
+			addr, err = dcrutil.NewAddressScriptHashFromHash(pkhs[i],
+				w.chainParams)
+		} else {
+			addr, err = dcrutil.NewAddressPubKeyHash(pkhs[i],
+				w.chainParams, chainec.ECTypeSecp256k1)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		totalAmt += amts[i]
+		if addr.EncodeAddress() == poolAddr {
+			poolAmt += amts[i]
+			continue
+		}
+		if user == nil {
+			user = addr
+		}
+		if _, err := w.Manager.Address(addr); err != nil {
+			ownedAll = false
+		}
+	}
+	if user == nil || ownedAll {
+		return nil, true, nil
+	}
+
+	// Compare the pool commitment against the fee percentage without
+	// losing precision to rounding of the required amount.
+	paid := float64(poolAmt)*100 >= w.poolFees*float64(totalAmt)
+	return user, paid, nil
+}
+
+// evaluateStakePoolTicket records a ticket delegating voting rights to the
+// wallet for the user who submitted it, and reports whether the wallet
+// should vote the ticket.  Tickets which do not pay the pool fee are recorded
+// as invalid and are not voted.
+func (w *Wallet) evaluateStakePoolTicket(tx *dcrutil.Tx,
+	block *wtxmgr.BlockMeta) bool {
+
+	user, paid, err := w.stakePoolTicketUser(tx)
+	if err != nil {
+		log.Errorf("Failed to determine stake pool user for ticket %v: %v",
+			tx.Sha(), err)
+		return false
+	}
+	if user == nil {
+		return true
+	}
+
+	if !paid {
+		log.Warnf("Ticket %v submitted by %v does not pay the pool fee "+
+			"of %v%%; it will not be voted", tx.Sha(), user.EncodeAddress(),
+			w.poolFees)
+		err := w.StakeMgr.UpdateStakePoolUserInvalTickets(user, tx.Sha())
+		if err != nil {
+			log.Errorf("Failed to record invalid stake pool ticket %v: %v",
+				tx.Sha(), err)
+		}
+		return false
+	}
+
+	record := &wstakemgr.PoolTicket{
+		Ticket: *tx.Sha(),
+		Status: wstakemgr.TSImmatureOrLive,
+	}
+	if block != nil {
+		record.HeightTicket = uint32(block.Height)
+	}
+	err = w.StakeMgr.UpdateStakePoolUserTickets(user, record)
+	if err != nil {
+		log.Errorf("Failed to record stake pool ticket %v: %v", tx.Sha(), err)
+	}
+	return true
+}
+
+// updateStakePoolTicket records that a stake pool ticket was spent by a vote
+// or revocation.
+func (w *Wallet) updateStakePoolTicket(ticketHash *chainhash.Hash,
+	status wstakemgr.PoolTicketStatus, block *wtxmgr.BlockMeta,
+	spentBy *chainhash.Hash) {
+
+	details, err := w.TxStore.TxDetails(ticketHash)
+	if err != nil || details == nil {
+		return
+	}
+	user, paid, err := w.stakePoolTicketUser(dcrutil.NewTx(&details.MsgTx))
+	if err != nil || user == nil || !paid {
+		return
+	}
+
+	record := &wstakemgr.PoolTicket{
+		Ticket:      *ticketHash,
+		Status:      status,
+		HeightSpent: uint32(block.Height),
+		SpentBy:     *spentBy,
+	}
+	if height := details.Height(); height > 0 {
+		record.HeightTicket = uint32(height)
+	}
+	err = w.StakeMgr.UpdateStakePoolUserTickets(user, record)
+	if err != nil {
+		log.Errorf("Failed to update stake pool ticket %v: %v", ticketHash,
+			err)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// year is the duration used to annualize staking returns.
+const year = 365 * 24 * time.Hour
+
+// StakeStatistics aggregates the staking activity of the wallet over a time
+// window.  Tickets, votes, and revocations are counted when the block they
+// were mined in has a timestamp within the window.
+type StakeStatistics struct {
+	Start time.Time
+	End   time.Time
+
+	TicketsBought int
+	TotalLocked   dcrutil.Amount // Sum of ticket prices bought in window
+
+	Votes       int
+	Misses      int // Revocations of tickets which had not expired
+	Revocations int
+
+	// AverageLifetime is the average time between the purchase and vote
+	// of the tickets which voted in the window.
+	AverageLifetime time.Duration
+
+	FeesPaid      dcrutil.Amount // Ticket and revocation fees
+	RewardsEarned dcrutil.Amount // Vote subsidies
+
+	// AnnualizedReturn is the net return (rewards less fees) relative to
+	// the price of the tickets which voted in the window, scaled to a year
+	// using the average ticket lifetime.
+	AnnualizedReturn float64
+}
+
+// stakeTicketInfo is the purchase time, height, and price of a ticket, used
+// to determine the lifetime and return of votes and revocations.
+type stakeTicketInfo struct {
+	time   time.Time
+	height int32
+	price  dcrutil.Amount
+}
+
+// txFee returns the fee paid by a transaction, calculated from the input
+// values recorded in the transaction.
+func txFee(tx *wire.MsgTx) dcrutil.Amount {
+	var in, out int64
+	for _, txIn := range tx.TxIn {
+		in += txIn.ValueIn
+	}
+	for _, txOut := range tx.TxOut {
+		out += txOut.Value
+	}
+	return dcrutil.Amount(in - out)
+}
+
+// StakeStatistics returns the staking statistics of the wallet for tickets,
+// votes, and revocations mined in blocks with timestamps in [start, end).  A
+// zero end time includes all blocks after start.
+func (w *Wallet) StakeStatistics(start, end time.Time) (*StakeStatistics,
+	error) {
+
+	inWindow := func(t time.Time) bool {
+		return !t.Before(start) && (end.IsZero() || t.Before(end))
+	}
+	expiry := int32(w.chainParams.TicketMaturity) +
+		int32(w.chainParams.TicketExpiry)
+
+	stats := &StakeStatistics{Start: start, End: end}
+	tickets := make(map[chainhash.Hash]stakeTicketInfo)
+	var lifetimes time.Duration
+	var votedLocked dcrutil.Amount
+
+	tipHeight := w.Manager.SyncedTo().Height
+	rangeFn := func(details []wtxmgr.TxDetails) (bool, error) {
+		for i := range details {
+			d := &details[i]
+			tx := &d.MsgTx
+
+			switch d.TxType {
+			case stake.TxTypeSStx:
+				// Only count tickets purchased with wallet funds.
+				if len(d.Debits) == 0 {
+					continue
+				}
+				price := dcrutil.Amount(tx.TxOut[0].Value)
+				tickets[d.Hash] = stakeTicketInfo{
+					time:   d.Block.Time,
+					height: d.Block.Height,
+					price:  price,
+				}
+				if !inWindow(d.Block.Time) {
+					continue
+				}
+				stats.TicketsBought++
+				stats.TotalLocked += price
+				stats.FeesPaid += txFee(tx)
+
+			case stake.TxTypeSSGen:
+				ticket, ok := tickets[tx.TxIn[1].PreviousOutPoint.Hash]
+				if !ok || !inWindow(d.Block.Time) {
+					continue
+				}
+				stats.Votes++
+				stats.RewardsEarned += dcrutil.Amount(tx.TxIn[0].ValueIn)
+				lifetimes += d.Block.Time.Sub(ticket.time)
+				votedLocked += ticket.price
+
+			case stake.TxTypeSSRtx:
+				ticket, ok := tickets[tx.TxIn[0].PreviousOutPoint.Hash]
+				if !ok || !inWindow(d.Block.Time) {
+					continue
+				}
+				stats.Revocations++
+				if d.Block.Height <= ticket.height+expiry {
+					stats.Misses++
+				}
+				stats.FeesPaid += txFee(tx)
+			}
+		}
+		return false, nil
+	}
+	err := w.TxStore.RangeTransactions(0, tipHeight, rangeFn)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Votes > 0 {
+		stats.AverageLifetime = lifetimes / time.Duration(stats.Votes)
+	}
+	if votedLocked > 0 && stats.AverageLifetime > 0 {
+		net := float64(stats.RewardsEarned - stats.FeesPaid)
+		stats.AnnualizedReturn = net / float64(votedLocked) *
+			float64(year) / float64(stats.AverageLifetime)
+	}
+
+	return stats, nil
+}
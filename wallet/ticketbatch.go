@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// TicketBatchReport describes the cost and outcome of a batch of tickets
+// purchased by a single run of the ticket buyer.  Cost is the sum of the
+// ticket prices, Fees is the sum of the purchase transaction fees, and Net is
+// the subsidy earned by the batch's votes less the fees paid.
+type TicketBatchReport struct {
+	*wstakemgr.TicketBatch
+	Cost dcrutil.Amount
+	Fees dcrutil.Amount
+	Net  dcrutil.Amount
+}
+
+// TicketBatchReports returns a report for every batch of tickets purchased
+// by the ticket buyer, in the order the batches were purchased.
+func (w *Wallet) TicketBatchReports() ([]*TicketBatchReport, error) {
+	batches, err := w.StakeMgr.TicketBatches()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*TicketBatchReport, 0, len(batches))
+	for _, batch := range batches {
+		report := &TicketBatchReport{TicketBatch: batch}
+		for i := range batch.Tickets {
+			details, err := w.TxStore.TxDetails(&batch.Tickets[i])
+			if err != nil {
+				return nil, err
+			}
+			if details == nil {
+				continue
+			}
+			report.Cost += dcrutil.Amount(details.MsgTx.TxOut[0].Value)
+			report.Fees += txFee(&details.MsgTx)
+		}
+		report.Net = batch.Summary.TotalSubsidy - report.Fees
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,506 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"sort"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wstakemgr"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+const (
+	// maxTicketBuyerDecisions is the number of most recent ticket buyer
+	// decisions kept in the database for auditing, about a month of
+	// mainnet blocks.
+	maxTicketBuyerDecisions = 8640
+
+	// ticketFeeBidBlocks is the number of blocks a ticket should be mined
+	// within when bidding on the fee needed to outcompete the tickets in
+	// the mempool.
+	ticketFeeBidBlocks = 3
+
+	// defaultTicketMaxFeeRateMultiplier is the multiple of the network's
+	// minimum fee increment used as the maximum ticket fee rate when the
+	// ticket buyer policy does not set one.
+	defaultTicketMaxFeeRateMultiplier = 10
+)
+
+// TicketBuyerDecisions returns the count most recent decisions made by the
+// automatic ticket buyer, or all recorded decisions if count is not positive,
+// oldest first.
+func (w *Wallet) TicketBuyerDecisions(count int) ([]*wstakemgr.TicketBuyerDecision,
+	error) {
+	return w.StakeMgr.TicketBuyerDecisions(count)
+}
+
+// TicketBuyerPolicy returns the maximum number of tickets the automatic
+// ticket buyer will purchase per block and the maximum median mempool ticket
+// fee per kB it will compete with.  When the policy sets no maximum fee, the
+// default of defaultTicketMaxFeeRateMultiplier times the network's minimum fee
+// increment is returned.
+func (w *Wallet) TicketBuyerPolicy() (int, dcrutil.Amount) {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	maxFeeRate := w.ticketMaxFeeRate
+	if maxFeeRate <= 0 {
+		maxFeeRate = w.defaultTicketMaxFeeRate()
+	}
+	return w.maxTicketsPerBlock, maxFeeRate
+}
+
+// defaultTicketMaxFeeRate returns the maximum ticket fee per kB used when the
+// ticket buyer policy does not set one.
+func (w *Wallet) defaultTicketMaxFeeRate() dcrutil.Amount {
+	return defaultTicketMaxFeeRateMultiplier *
+		defaultFeeIncrement(w.chainParams)
+}
+
+// SetTicketBuyerPolicy sets the maximum number of tickets the automatic
+// ticket buyer will purchase per block and the maximum median mempool ticket
+// fee per kB it will compete with.  A zero fee selects the default maximum.
+func (w *Wallet) SetTicketBuyerPolicy(maxPerBlock int, maxFeeRate dcrutil.Amount) {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	w.maxTicketsPerBlock = maxPerBlock
+	w.ticketMaxFeeRate = maxFeeRate
+}
+
+// TicketBatchLabel returns the label recorded with each batch of tickets
+// purchased by the automatic ticket buyer.
+func (w *Wallet) TicketBatchLabel() string {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	return w.ticketBatchLabel
+}
+
+// SetTicketBatchLabel sets the label recorded with each following batch of
+// tickets purchased by the automatic ticket buyer.  Labelling batches by the
+// purchasing strategy in use allows the results of strategies to be compared.
+func (w *Wallet) SetTicketBatchLabel(label string) {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	w.ticketBatchLabel = label
+}
+
+// TicketsPerWindow returns the maximum number of tickets the wallet will
+// purchase in a single stake difficulty window.  Zero means no limit.
+func (w *Wallet) TicketsPerWindow() int {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	return w.maxTicketsPerWindow
+}
+
+// SetTicketsPerWindow sets the maximum number of tickets the wallet will
+// purchase in a single stake difficulty window.  Zero disables the limit.
+func (w *Wallet) SetTicketsPerWindow(maxPerWindow int) {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	w.maxTicketsPerWindow = maxPerWindow
+}
+
+// ticketWindowAvailable returns whether another ticket may be purchased in
+// the stake difficulty window with the passed index.
+func (w *Wallet) ticketWindowAvailable(window int64) bool {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	if w.maxTicketsPerWindow <= 0 || window != w.purchaseWindow {
+		return true
+	}
+	return w.windowPurchases < w.maxTicketsPerWindow
+}
+
+// recordWindowPurchase counts a ticket purchased in the stake difficulty
+// window with the passed index.
+func (w *Wallet) recordWindowPurchase(window int64) {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	if window != w.purchaseWindow {
+		w.purchaseWindow = window
+		w.windowPurchases = 0
+	}
+	w.windowPurchases++
+
+	err := w.StakeMgr.SetTicketWindow(window, w.windowPurchases)
+	if err != nil {
+		tkbyLog.Errorf("Failed to record ticket purchase window: %v", err)
+	}
+}
+
+// loadTicketWindow restores the number of tickets purchased in the stake
+// difficulty window of the last purchase from the stake store.
+func (w *Wallet) loadTicketWindow() error {
+	window, count, err := w.StakeMgr.TicketWindow()
+	if err != nil {
+		return err
+	}
+
+	w.ticketBuyerMu.Lock()
+	w.purchaseWindow = window
+	w.windowPurchases = count
+	w.ticketBuyerMu.Unlock()
+	return nil
+}
+
+// recordTicketBuyerDecision logs and stores a ticket buyer decision,
+// discarding the oldest decision if the maximum number are already kept.
+func (w *Wallet) recordTicketBuyerDecision(d *wstakemgr.TicketBuyerDecision) {
+	tkbyLog.Infof("Ticket buyer at height %v: purchased %v of %v attempted "+
+		"tickets (price %v, spendable %v, mempool fee %v/kB, fee %v/kB): "+
+		"%v", d.Height, d.Purchased, d.Attempted, d.TicketPrice,
+		d.Spendable, d.MempoolFee, d.FeeRate, d.Reason)
+
+	err := w.StakeMgr.InsertTicketBuyerDecision(d, maxTicketBuyerDecisions)
+	if err != nil {
+		tkbyLog.Errorf("Failed to record ticket buyer decision: %v", err)
+	}
+}
+
+// lastTicketFeeRate returns the fee per kB paid by the most recently
+// purchased ticket.
+func (w *Wallet) lastTicketFeeRate() dcrutil.Amount {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	return w.ticketFeeRate
+}
+
+// setLastTicketFeeRate records the fee per kB paid by a purchased ticket.
+func (w *Wallet) setLastTicketFeeRate(feeRate dcrutil.Amount) {
+	w.ticketBuyerMu.Lock()
+	w.ticketFeeRate = feeRate
+	w.ticketBuyerMu.Unlock()
+}
+
+// medianMempoolTicketFee returns the median fee per kB paid by the tickets
+// currently in the mempool of the chain server, or zero if there are none.
+func (w *Wallet) medianMempoolTicketFee() (dcrutil.Amount, error) {
+	feeRates, err := w.mempoolTicketFeeRates()
+	if err != nil {
+		return 0, err
+	}
+	if len(feeRates) == 0 {
+		return 0, nil
+	}
+
+	return feeRates[len(feeRates)/2], nil
+}
+
+// mempoolTicketFeeRates returns the fees per kB paid by the tickets currently
+// in the mempool of the chain server, in increasing order.
+func (w *Wallet) mempoolTicketFeeRates() ([]dcrutil.Amount, error) {
+	mempool, err := w.chainSvr.GetRawMempoolVerbose(dcrjson.GRMTickets)
+	if err != nil {
+		return nil, err
+	}
+
+	feeRates := make([]dcrutil.Amount, 0, len(mempool))
+	for _, tx := range mempool {
+		if tx.Size <= 0 {
+			continue
+		}
+		fee, err := dcrutil.NewAmount(tx.Fee)
+		if err != nil {
+			continue
+		}
+		feeRates = append(feeRates, fee*1000/dcrutil.Amount(tx.Size))
+	}
+
+	sort.Sort(amountSorter(feeRates))
+	return feeRates, nil
+}
+
+// ticketFeeBid returns the fee per kB which count newly purchased tickets must
+// pay for all of them to be mined within the next slots fresh stake slots,
+// given the fee rates of the tickets in the mempool in increasing order.  The
+// purchased tickets take count of the slots, so they must outbid every
+// mempool ticket but the slots-count paying the highest fees.  False is
+// returned when the mempool leaves enough slots free that no bid is needed.
+func ticketFeeBid(feeRates []dcrutil.Amount, slots, count int) (dcrutil.Amount,
+	bool) {
+	if count < 1 {
+		count = 1
+	}
+	if len(feeRates)+count <= slots {
+		return 0, false
+	}
+	idx := len(feeRates) - (slots - count) - 1
+	if idx >= len(feeRates) {
+		idx = len(feeRates) - 1
+	}
+	return feeRates[idx] + 1, true
+}
+
+// ticketFeeIncrement returns the fee per kB to pay for each of count tickets
+// purchased together.  When the mempool and the purchased tickets exceed the
+// number of tickets which may be mined in the next ticketFeeBidBlocks
+// blocks, the fee is raised just above the lowest fee of the mempool tickets
+// which would be mined before them, up to the maximum ticket fee rate of the
+// ticket buyer policy, or the default maximum when the policy sets none.  The
+// mempool is queried again for every purchase so the bid follows the
+// competing fees.  The fee is never lower than the network's minimum fee
+// increment.
+func (w *Wallet) ticketFeeIncrement(count int) dcrutil.Amount {
+	feeIncrement := defaultFeeIncrement(w.chainParams)
+
+	feeRates, err := w.mempoolTicketFeeRates()
+	if err != nil {
+		tkbyLog.Warnf("Unable to query mempool ticket fees: %v", err)
+		return feeIncrement
+	}
+	slots := int(w.chainParams.MaxFreshStakePerBlock) * ticketFeeBidBlocks
+	bid, ok := ticketFeeBid(feeRates, slots, count)
+	if !ok {
+		return feeIncrement
+	}
+
+	_, maxFeeRate := w.TicketBuyerPolicy()
+	if bid > maxFeeRate {
+		tkbyLog.Warnf("Ticket fee of %v/kB needed to mine %d tickets "+
+			"within %d blocks exceeds the maximum of %v/kB", bid, count,
+			ticketFeeBidBlocks, maxFeeRate)
+		bid = maxFeeRate
+	}
+	if bid > feeIncrement {
+		tkbyLog.Debugf("Bidding ticket fee of %v/kB for %d tickets "+
+			"against %d mempool tickets", bid, count, len(feeRates))
+		return bid
+	}
+	return feeIncrement
+}
+
+// amountSorter implements sort.Interface to sort a slice of amounts in
+// increasing order.
+type amountSorter []dcrutil.Amount
+
+func (s amountSorter) Len() int           { return len(s) }
+func (s amountSorter) Less(i, j int) bool { return s[i] < s[j] }
+func (s amountSorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// handleTicketPurchases autopurchases stake tickets for the wallet if stake
+// mining is enabled.  The number of tickets purchased is limited by the
+// spendable balance in excess of the balance to maintain, the maximum number
+// of tickets per block, and the number of fresh stake transactions allowed
+// in a block.  No tickets are purchased if the ticket price exceeds the
+// maximum price, or if the median fee of tickets in the mempool exceeds the
+// maximum fee rate.  Purchases are further scheduled by the position in the
+// stake difficulty window and the expiry risk of the live ticket pool after
+// the block with the passed hash, and are delayed late in a window when the
+// ticket price of the next window is forecast to be lower.  The tickets
+// purchased are recorded as a ticket batch, and each decision is recorded
+// for later audit.
+func (w *Wallet) handleTicketPurchases(hash *chainhash.Hash, height int32) {
+	decision := &wstakemgr.TicketBuyerDecision{
+		Height: height,
+		Time:   time.Now(),
+	}
+	defer w.recordTicketBuyerDecision(decision)
+
+	maxPerBlock, maxFeeRate := w.TicketBuyerPolicy()
+	maxTickets := int(w.chainParams.MaxFreshStakePerBlock)
+	if maxPerBlock > 0 && maxPerBlock < maxTickets {
+		maxTickets = maxPerBlock
+	}
+	maxAttempts := 20 // Sane-ish?
+
+	sdiff := dcrutil.Amount(w.GetStakeDifficulty().StakeDifficulty)
+	decision.TicketPrice = sdiff
+	if sdiff <= 0 {
+		decision.Reason = "ticket price not yet established"
+		return
+	}
+	maxToPay := w.GetTicketMaxPrice()
+	if sdiff > maxToPay {
+		decision.Reason = "ticket price exceeds maximum price " +
+			maxToPay.String()
+		return
+	}
+
+	spendable, err := w.TxStore.Balance(0, height,
+		wtxmgr.BFBalanceSpendable)
+	if err != nil {
+		decision.Reason = "unable to calculate spendable balance: " +
+			err.Error()
+		return
+	}
+	decision.Spendable = spendable
+	if spendable <= w.BalanceToMaintain {
+		decision.Reason = "spendable balance does not exceed balance " +
+			"to maintain"
+		return
+	}
+	affordable := int((spendable - w.BalanceToMaintain) / sdiff)
+	if affordable < maxTickets {
+		maxTickets = affordable
+	}
+	if maxTickets == 0 {
+		decision.Reason = "insufficient funds to purchase a ticket"
+		return
+	}
+
+	mempoolFee, err := w.medianMempoolTicketFee()
+	if err != nil {
+		tkbyLog.Warnf("Unable to query mempool ticket fees: %v", err)
+	}
+	decision.MempoolFee = mempoolFee
+	if mempoolFee > maxFeeRate {
+		decision.Reason = "median mempool ticket fee exceeds maximum " +
+			"fee rate " + maxFeeRate.String()
+		return
+	}
+
+	poolSize, err := w.ticketPoolSize(hash)
+	if err != nil {
+		tkbyLog.Warnf("Unable to query ticket pool size: %v", err)
+	}
+	scheduled, reason := ticketWindowSchedule(w.chainParams, int64(height),
+		sdiff, w.previousWindowPrice(), poolSize, maxTickets)
+	if scheduled == 0 {
+		decision.Reason = reason
+		return
+	}
+	if scheduled < maxTickets {
+		tkbyLog.Debugf("Limiting ticket purchases to %d: %v", scheduled,
+			reason)
+		maxTickets = scheduled
+	}
+
+	wait, reason, err := w.stakeDiffForecastWait(hash, int64(height))
+	if err != nil {
+		tkbyLog.Warnf("Unable to forecast the next ticket price: %v", err)
+	}
+	if wait {
+		decision.Reason = reason
+		return
+	}
+
+	decision.Reason = "purchased maximum number of tickets"
+	attempts := 0
+	var purchased []chainhash.Hash
+	defer func() {
+		if len(purchased) == 0 {
+			return
+		}
+		batch, err := w.StakeMgr.InsertTicketBatch(height,
+			w.TicketBatchLabel(), purchased)
+		if err != nil {
+			tkbyLog.Errorf("Failed to record ticket batch: %v", err)
+			return
+		}
+		decision.Batch = batch
+	}()
+
+ticketPurchaseLoop:
+	for {
+		if decision.Purchased >= maxTickets {
+			break
+		}
+
+		if attempts >= maxAttempts {
+			decision.Reason = "reached maximum purchase attempts"
+			break
+		}
+
+		// eligible may also be the tx hash as a string; however, for the
+		// too many inputs error, the list of eligible Credits from
+		// wtxmgr is instead returned. We can use this to compress the
+		// amount to the ticket price, thus avoiding more costly db
+		// lookups.
+		decision.Attempted++
+		eligible, err := w.CreatePurchaseTicket(w.BalanceToMaintain, -1,
+			0, nil, SigningOriginTicketBuyer)
+		if err != nil {
+			switch {
+			case err == ErrSStxNotEnoughFunds:
+				decision.Reason = "insufficient funds to purchase " +
+					"more tickets"
+				break ticketPurchaseLoop
+			case err == ErrSStxBalanceReserve:
+				decision.Reason = "purchase would spend below balance " +
+					"to maintain"
+				break ticketPurchaseLoop
+			case err == ErrTicketWindowLimit:
+				decision.Reason = "reached maximum tickets per stake " +
+					"difficulty window"
+				break ticketPurchaseLoop
+			case err == ErrSStxInputOverflow:
+				switch v := eligible.(type) {
+				case string:
+					tkbyLog.Errorf("Was given a string instead of eligible credits!")
+					continue
+				case []wtxmgr.Credit:
+					err := w.compressEligible(v, SigningOriginTicketBuyer)
+					if err != nil {
+						tkbyLog.Errorf("Failed to compress outputs: %v", err.Error())
+					}
+					attempts++
+					continue
+				}
+			case waddrmgr.IsError(err, waddrmgr.ErrLocked):
+				tkbyLog.Warnf("Ticket purchase for stake mining is enabled, " +
+					"but tickets could not be purchased because the " +
+					"wallet is currently locked!")
+				decision.Reason = "wallet is locked"
+				break ticketPurchaseLoop
+			case err == ErrTicketPriceNotSet:
+				// TODO make this trigger a request to the daemon
+				// through chainsvr to get the latest ticket price.
+				// The current behaviour simply waits for a block
+				// to be connected to get the stake difficulty.
+				// Probably need a retrigger for the ntfn like
+				// "rebroadcaststakediff"
+				tkbyLog.Warnf("Ticket prices not yet established because the " +
+					"client was recently connected; aborting ticket purchase " +
+					"attempts")
+				decision.Reason = "ticket price not yet established"
+				break ticketPurchaseLoop
+			case err == ErrClientPurchaseTicket:
+				tkbyLog.Warnf("A chainSvr error was returned attempting to " +
+					"purchase a ticket; ticket purchases aborted.")
+				decision.Reason = "chain server rejected ticket purchase"
+				break ticketPurchaseLoop
+			default:
+				tkbyLog.Errorf("PurchaseTicket error returned: %v", err)
+				decision.Reason = "purchase error: " + err.Error()
+			}
+		} else {
+			decision.Purchased++
+			decision.FeeRate = w.lastTicketFeeRate()
+			if txHash, ok := eligible.(string); ok {
+				hash, err := chainhash.NewHashFromStr(txHash)
+				if err == nil {
+					purchased = append(purchased, *hash)
+				}
+			}
+		}
+
+		attempts++
+	}
+}
@@ -0,0 +1,60 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/decred/dcrutil"
+)
+
+func TestTicketFeeBid(t *testing.T) {
+	feeRates := []dcrutil.Amount{10, 20, 30, 40, 50}
+	tests := []struct {
+		slots, count int
+		bid          dcrutil.Amount
+		ok           bool
+	}{
+		{slots: 10, count: 1, ok: false},
+		{slots: 6, count: 1, ok: false},
+		{slots: 5, count: 1, bid: 11, ok: true},
+		{slots: 3, count: 1, bid: 31, ok: true},
+		{slots: 3, count: 2, bid: 41, ok: true},
+		{slots: 6, count: 2, bid: 11, ok: true},
+		{slots: 3, count: 3, bid: 51, ok: true},
+		{slots: 3, count: 5, bid: 51, ok: true},
+		{slots: 3, count: 0, bid: 31, ok: true},
+	}
+	for _, test := range tests {
+		bid, ok := ticketFeeBid(feeRates, test.slots, test.count)
+		if ok != test.ok || bid != test.bid {
+			t.Errorf("ticketFeeBid(%d slots, %d tickets) = %v, %v; "+
+				"want %v, %v", test.slots, test.count, bid, ok, test.bid,
+				test.ok)
+		}
+	}
+}
+
+func TestTicketBuyerPolicyDefaultFeeRate(t *testing.T) {
+	w, cleanup := newTestWallet(t)
+	defer cleanup()
+
+	_, maxFeeRate := w.TicketBuyerPolicy()
+	want := dcrutil.Amount(defaultTicketMaxFeeRateMultiplier *
+		FeeIncrementTestnet)
+	if maxFeeRate != want {
+		t.Errorf("default maximum ticket fee rate is %v, want %v",
+			maxFeeRate, want)
+	}
+
+	w.SetTicketBuyerPolicy(5, 5e4)
+	maxPerBlock, maxFeeRate := w.TicketBuyerPolicy()
+	if maxPerBlock != 5 || maxFeeRate != 5e4 {
+		t.Errorf("ticket buyer policy is %d, %v; want 5, %v",
+			maxPerBlock, maxFeeRate, dcrutil.Amount(5e4))
+	}
+
+	w.SetTicketBuyerPolicy(5, 0)
+	if _, maxFeeRate := w.TicketBuyerPolicy(); maxFeeRate != want {
+		t.Errorf("maximum ticket fee rate after clearing it is %v, "+
+			"want %v", maxFeeRate, want)
+	}
+}
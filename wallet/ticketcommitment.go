@@ -0,0 +1,151 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainec"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+)
+
+// TicketCommitment describes one commitment output of a ticket.  Amount is
+// the amount committed to Address, which is returned the committed share of
+// the vote or revocation outputs, and Share is the commitment's percentage
+// of all committed amounts.  The fee limits are the maximum fees which a
+// vote or revocation may deduct from the commitment's output, and are only
+// meaningful when the matching Allowed field is set.  ChangeAddress and
+// ChangeAmount describe the change output paired with the commitment.
+type TicketCommitment struct {
+	Address            dcrutil.Address
+	Amount             dcrutil.Amount
+	Share              float64
+	Owned              bool
+	VoteFeeAllowed     bool
+	VoteFeeLimit       dcrutil.Amount
+	RevocationAllowed  bool
+	RevocationFeeLimit dcrutil.Amount
+	ChangeAddress      dcrutil.Address
+	ChangeAmount       dcrutil.Amount
+}
+
+// TicketCommitments describes the commitments of a ticket.
+type TicketCommitments struct {
+	Ticket      chainhash.Hash
+	Price       dcrutil.Amount
+	Commitments []*TicketCommitment
+}
+
+// commitmentFeeLimit returns the fee allowance encoded by a commitment's
+// spend limit, which is a power of two number of atoms.
+func commitmentFeeLimit(limit uint16) dcrutil.Amount {
+	if limit >= 63 {
+		return dcrutil.Amount(dcrutil.MaxAmount)
+	}
+	return dcrutil.Amount(int64(1) << limit)
+}
+
+// commitmentAddress returns the address of a commitment from the hash and
+// pay type decoded from its output.
+func (w *Wallet) commitmentAddress(isScriptHash bool,
+	hash []byte) (dcrutil.Address, error) {
+	if isScriptHash {
+		return dcrutil.NewAddressScriptHashFromHash(hash, w.chainParams)
+	}
+	return dcrutil.NewAddressPubKeyHash(hash, w.chainParams,
+		chainec.ECTypeSecp256k1)
+}
+
+// TicketCommitments decodes the commitment outputs of one of the wallet's
+// tickets.  Users of a stake pool may use this to verify the share of the
+// ticket committed to the pool before the ticket votes.
+func (w *Wallet) TicketCommitments(ticket *chainhash.Hash) (*TicketCommitments,
+	error) {
+	details, err := w.TxStore.TxDetails(ticket)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, fmt.Errorf("ticket %v not found", ticket)
+	}
+	return w.ticketCommitments(&details.MsgTx)
+}
+
+// ticketCommitments decodes the commitment outputs of a ticket, which does not
+// need to be saved by the wallet.
+func (w *Wallet) ticketCommitments(msgTx *wire.MsgTx) (*TicketCommitments,
+	error) {
+	tx := dcrutil.NewTx(msgTx)
+	if is, err := stake.IsSStx(tx); !is {
+		return nil, fmt.Errorf("transaction %v is not a ticket: %v",
+			tx.Sha(), err)
+	}
+
+	payTypes, pkhs, amts, changeAmts, spendRules, spendLimits :=
+		stake.GetSStxStakeOutputInfo(tx)
+
+	var total int64
+	for _, amt := range amts {
+		total += amt
+	}
+
+	commitments := &TicketCommitments{
+		Ticket:      *tx.Sha(),
+		Price:       dcrutil.Amount(msgTx.TxOut[0].Value),
+		Commitments: make([]*TicketCommitment, 0, len(pkhs)),
+	}
+	for i := range pkhs {
+		addr, err := w.commitmentAddress(payTypes[i], pkhs[i])
+		if err != nil {
+			return nil, err
+		}
+		c := &TicketCommitment{
+			Address:            addr,
+			Amount:             dcrutil.Amount(amts[i]),
+			VoteFeeAllowed:     spendRules[i][0],
+			VoteFeeLimit:       commitmentFeeLimit(spendLimits[i][0]),
+			RevocationAllowed:  spendRules[i][1],
+			RevocationFeeLimit: commitmentFeeLimit(spendLimits[i][1]),
+			ChangeAmount:       dcrutil.Amount(changeAmts[i]),
+		}
+		if total > 0 {
+			c.Share = float64(amts[i]) * 100 / float64(total)
+		}
+		if _, err := w.Manager.Address(addr); err == nil {
+			c.Owned = true
+		}
+
+		// Change outputs follow each commitment output.
+		changeIdx := i*2 + 2
+		if changeIdx < len(msgTx.TxOut) {
+			txOut := msgTx.TxOut[changeIdx]
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+				txOut.Version, txOut.PkScript, w.chainParams)
+			if err == nil && len(addrs) == 1 {
+				c.ChangeAddress = addrs[0]
+			}
+		}
+
+		commitments.Commitments = append(commitments.Commitments, c)
+	}
+
+	return commitments, nil
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// TicketOutcome describes one of the wallet's tickets voting, being missed,
+// expiring, or being revoked in the block at Height.  Amount is the stake
+// subsidy earned by a vote, or the fees lost by a ticket which did not vote
+// as a negative amount.  Only the wallet's share is included for split
+// tickets.  TxHash is the hash of the vote or revocation, if any.
+type TicketOutcome struct {
+	Ticket chainhash.Hash
+	Status wstakemgr.TicketStatus
+	Height int32
+	Amount dcrutil.Amount
+	TxHash chainhash.Hash
+}
+
+// ticketShare returns the wallet's share of an amount paid by a ticket.  This
+// is the whole amount unless the ticket is a split ticket.
+func (w *Wallet) ticketShare(ticket *chainhash.Hash,
+	amount dcrutil.Amount) dcrutil.Amount {
+	split, err := w.StakeMgr.SplitTicket(ticket)
+	if err == nil && split != nil && split.Total > 0 {
+		return amount * split.Contribution / split.Total
+	}
+	return amount
+}
+
+// ticketLoss returns the wallet's share of the fees lost by a ticket which
+// did not vote: the fee of the ticket purchase, and the fee of the
+// revocation if the ticket was revoked.
+func (w *Wallet) ticketLoss(ticket *chainhash.Hash,
+	revocation *wire.MsgTx) dcrutil.Amount {
+	var loss dcrutil.Amount
+	details, err := w.TxStore.TxDetails(ticket)
+	if err == nil && details != nil {
+		loss += txFee(&details.MsgTx)
+	}
+	if revocation != nil {
+		loss += txFee(revocation)
+	}
+	return w.ticketShare(ticket, loss)
+}
+
+// advanceTicketStatus records a change in the lifecycle status of a ticket
+// and returns whether the status changed.
+func (w *Wallet) advanceTicketStatus(ticket *chainhash.Hash,
+	status wstakemgr.TicketStatus, height int32) bool {
+	prev, err := w.StakeMgr.TicketStatus(ticket)
+	if err != nil {
+		log.Errorf("Failed to fetch status of ticket %v: %v", ticket, err)
+		return false
+	}
+	w.updateTicketStatus(ticket, status, height)
+	return prev < status
+}
+
+// ticketVoted records the vote of a ticket, along with the subsidy earned,
+// and notifies the outcome.
+func (w *Wallet) ticketVoted(ticket *chainhash.Hash, vote *dcrutil.Tx,
+	height int32) {
+	changed := w.advanceTicketStatus(ticket, wstakemgr.TicketStatusVoted,
+		height)
+
+	reward := w.ticketShare(ticket,
+		dcrutil.Amount(vote.MsgTx().TxIn[0].ValueIn))
+	err := w.StakeMgr.RecordVoteReward(ticket, reward)
+	if err != nil {
+		log.Errorf("Failed to record vote reward of ticket %v: %v",
+			ticket, err)
+	}
+
+	if changed {
+		w.notifyTicketOutcome(TicketOutcome{
+			Ticket: *ticket,
+			Status: wstakemgr.TicketStatusVoted,
+			Height: height,
+			Amount: reward,
+			TxHash: *vote.Sha(),
+		})
+	}
+}
+
+// ticketRevoked records the revocation of a ticket and notifies the outcome.
+func (w *Wallet) ticketRevoked(ticket *chainhash.Hash, revocation *dcrutil.Tx,
+	height int32) {
+	if !w.advanceTicketStatus(ticket, wstakemgr.TicketStatusRevoked, height) {
+		return
+	}
+	w.notifyTicketOutcome(TicketOutcome{
+		Ticket: *ticket,
+		Status: wstakemgr.TicketStatusRevoked,
+		Height: height,
+		Amount: -w.ticketLoss(ticket, revocation.MsgTx()),
+		TxHash: *revocation.Sha(),
+	})
+}
+
+// ticketMissed records that a ticket was missed and notifies the outcome.
+func (w *Wallet) ticketMissed(ticket *chainhash.Hash, height int32) {
+	if !w.advanceTicketStatus(ticket, wstakemgr.TicketStatusMissed, height) {
+		return
+	}
+	w.notifyTicketOutcome(TicketOutcome{
+		Ticket: *ticket,
+		Status: wstakemgr.TicketStatusMissed,
+		Height: height,
+		Amount: -w.ticketLoss(ticket, nil),
+	})
+}
+
+// notifyTicketsExpired notifies the outcome of tickets which the stake store
+// moved to expired when the block at height was connected.
+func (w *Wallet) notifyTicketsExpired(tickets []chainhash.Hash, height int32) {
+	for i := range tickets {
+		w.notifyTicketOutcome(TicketOutcome{
+			Ticket: tickets[i],
+			Status: wstakemgr.TicketStatusExpired,
+			Height: height,
+			Amount: -w.ticketLoss(&tickets[i], nil),
+		})
+	}
+}
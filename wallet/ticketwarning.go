@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// ticketWarningBlocks is the number of blocks before an unmined ticket
+// becomes invalid, or before the stake difficulty window ends, at which the
+// wallet warns about it.
+const ticketWarningBlocks = 6
+
+// TicketWarningKind describes the event a ticket warning warns about.
+type TicketWarningKind uint8
+
+const (
+	// TicketWarningExpiring warns that an unmined ticket of the wallet
+	// becomes invalid unless it is mined within the remaining blocks,
+	// either because the stake difficulty window ends or because the
+	// transaction expires.
+	TicketWarningExpiring TicketWarningKind = iota
+
+	// TicketWarningWindowEnding warns that the stake difficulty window
+	// ends, and the ticket price changes, after the remaining blocks.
+	TicketWarningWindowEnding
+)
+
+// String returns the TicketWarningKind as a human-readable name.
+func (k TicketWarningKind) String() string {
+	switch k {
+	case TicketWarningExpiring:
+		return "ticketexpiring"
+	case TicketWarningWindowEnding:
+		return "windowending"
+	}
+	return "unknown"
+}
+
+// TicketWarning warns that an unmined ticket is about to become invalid or
+// that the stake difficulty window is about to end.  Deadline is the last
+// height at which the ticket may be mined, or the last block of the window,
+// and Remaining is the number of blocks after Height up to and including
+// Deadline.  Ticket is only set for TicketWarningExpiring warnings.  Price is
+// the ticket price of the current window.
+type TicketWarning struct {
+	Kind      TicketWarningKind
+	Ticket    chainhash.Hash
+	Height    int32
+	Deadline  int32
+	Remaining int32
+	Price     dcrutil.Amount
+}
+
+// ticketMineDeadline returns the last height at which a ticket unmined after
+// the block at height may be mined: the last block of the stake difficulty
+// window, or the block before the ticket's expiry height when it is
+// earlier.  An expiry of zero means the ticket does not expire.
+func ticketMineDeadline(params *chaincfg.Params, height int64,
+	expiry uint32) int64 {
+	_, remaining := stakeWindowPosition(params, height)
+	deadline := height + remaining
+	if expiry != 0 && int64(expiry)-1 < deadline {
+		deadline = int64(expiry) - 1
+	}
+	return deadline
+}
+
+// checkTicketWarnings warns about the stake difficulty window ending and
+// about unmined tickets of the wallet becoming invalid within
+// ticketWarningBlocks blocks of the block at height.  Each window and ticket
+// is only warned about once.
+func (w *Wallet) checkTicketWarnings(height int32) {
+	price := dcrutil.Amount(w.GetStakeDifficulty().StakeDifficulty)
+	window, remaining := stakeWindowPosition(w.chainParams, int64(height))
+
+	var warnings []TicketWarning
+	w.ticketWarningsMu.Lock()
+	if remaining <= ticketWarningBlocks && window != w.ticketWarnWindow {
+		w.ticketWarnWindow = window
+		warnings = append(warnings, TicketWarning{
+			Kind:      TicketWarningWindowEnding,
+			Height:    height,
+			Deadline:  height + int32(remaining),
+			Remaining: int32(remaining),
+			Price:     price,
+		})
+	}
+	w.ticketWarningsMu.Unlock()
+
+	unmined, err := w.TxStore.UnminedTxs()
+	if err != nil {
+		log.Errorf("Failed to fetch unmined transactions: %v", err)
+		return
+	}
+
+	w.ticketWarningsMu.Lock()
+	warned := make(map[chainhash.Hash]struct{})
+	for _, msgTx := range unmined {
+		tx := dcrutil.NewTx(msgTx)
+		if is, _ := stake.IsSStx(tx); !is {
+			continue
+		}
+		hash := tx.Sha()
+		status, err := w.StakeMgr.TicketStatus(hash)
+		if err != nil || status != wstakemgr.TicketStatusUnmined {
+			continue
+		}
+		if _, ok := w.ticketWarned[*hash]; ok {
+			warned[*hash] = struct{}{}
+			continue
+		}
+		deadline := ticketMineDeadline(w.chainParams, int64(height),
+			msgTx.Expiry)
+		left := deadline - int64(height)
+		if left < 1 || left > ticketWarningBlocks {
+			continue
+		}
+		warned[*hash] = struct{}{}
+		warnings = append(warnings, TicketWarning{
+			Kind:      TicketWarningExpiring,
+			Ticket:    *hash,
+			Height:    height,
+			Deadline:  int32(deadline),
+			Remaining: int32(left),
+			Price:     price,
+		})
+	}
+	// Forget tickets which are no longer unmined.
+	w.ticketWarned = warned
+	w.ticketWarningsMu.Unlock()
+
+	for _, warning := range warnings {
+		switch warning.Kind {
+		case TicketWarningWindowEnding:
+			log.Infof("Stake difficulty window ends in %d blocks at "+
+				"height %d; the ticket price of %v then changes",
+				warning.Remaining, warning.Deadline, warning.Price)
+		case TicketWarningExpiring:
+			log.Warnf("Unmined ticket %v becomes invalid unless it is "+
+				"mined within %d blocks (by height %d)",
+				&warning.Ticket, warning.Remaining, warning.Deadline)
+		}
+		w.notifyTicketWarning(warning)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+)
+
+// maxTicketExpiryRisk is the highest probability of a purchased ticket
+// expiring without being called to vote that the ticket buyer accepts.
+const maxTicketExpiryRisk = 0.05
+
+// stakeWindowPosition returns the index of the stake difficulty window of
+// the block following the block at height, and the number of blocks,
+// starting with that next block, which may still mine a ticket at the
+// window's price.
+func stakeWindowPosition(params *chaincfg.Params, height int64) (window,
+	remaining int64) {
+	next := height + 1
+	window = next / params.StakeDiffWindowSize
+	remaining = params.StakeDiffWindowSize - next%params.StakeDiffWindowSize
+	return window, remaining
+}
+
+// ticketExpiryProbability returns the probability that a ticket entering a
+// live ticket pool of poolSize tickets expires without being called to vote.
+// Every block calls TicketsPerBlock tickets out of the pool, so a ticket
+// survives each block with probability 1 - TicketsPerBlock/poolSize.
+func ticketExpiryProbability(params *chaincfg.Params, poolSize int64) float64 {
+	if poolSize <= 0 {
+		return 0
+	}
+	perBlock := float64(params.TicketsPerBlock) / float64(poolSize)
+	if perBlock >= 1 {
+		return 0
+	}
+	return math.Pow(1-perBlock, float64(params.TicketExpiry))
+}
+
+// ticketWindowSchedule returns how many of maxTickets tickets should be
+// purchased for the block following the block at height, given the ticket
+// price of the current and previous stake difficulty windows, along with the
+// reason when fewer tickets should be purchased.  A previous price of zero
+// means the price of the previous window is unknown.
+//
+// No tickets are purchased when the window ends before a ticket could be
+// mined within ticketFeeBidBlocks blocks, since a ticket not mined before
+// the window is repriced is invalid.  Purchases are made as early as
+// possible in windows not more expensive than the previous window, while
+// in more expensive windows at most one ticket is purchased per block to
+// keep funds available for cheaper windows.  No tickets are purchased when
+// the live ticket pool of poolSize tickets makes expiry likely.
+func ticketWindowSchedule(params *chaincfg.Params, height int64,
+	price, prevPrice dcrutil.Amount, poolSize int64, maxTickets int) (int,
+	string) {
+	_, remaining := stakeWindowPosition(params, height)
+	if remaining < ticketFeeBidBlocks {
+		return 0, fmt.Sprintf("ticket price changes in %d blocks",
+			remaining)
+	}
+
+	risk := ticketExpiryProbability(params, poolSize)
+	if risk > maxTicketExpiryRisk {
+		return 0, fmt.Sprintf("ticket expiry probability %.1f%% "+
+			"exceeds %.1f%% with %d live tickets", risk*100,
+			maxTicketExpiryRisk*100, poolSize)
+	}
+
+	if prevPrice > 0 && price > prevPrice && maxTickets > 1 {
+		return 1, fmt.Sprintf("ticket price increased from %v; "+
+			"purchasing one ticket per block", prevPrice)
+	}
+
+	return maxTickets, ""
+}
+
+// recordWindowPrice records the ticket price of the stake difficulty window
+// of the block following the block at height, keeping the price of the
+// previous window when the window changes.
+func (w *Wallet) recordWindowPrice(height int64, price dcrutil.Amount) {
+	window, _ := stakeWindowPosition(w.chainParams, height)
+
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	switch window {
+	case w.priceWindow:
+	case w.priceWindow + 1:
+		w.prevWindowPrice = w.windowPrice
+	default:
+		w.prevWindowPrice = 0
+	}
+	w.priceWindow = window
+	w.windowPrice = price
+}
+
+// previousWindowPrice returns the ticket price of the stake difficulty window
+// preceding the current window, or zero if it is unknown.
+func (w *Wallet) previousWindowPrice() dcrutil.Amount {
+	w.ticketBuyerMu.Lock()
+	defer w.ticketBuyerMu.Unlock()
+
+	return w.prevWindowPrice
+}
+
+// ticketPoolSize returns the size of the live ticket pool after the block
+// with the passed hash.
+func (w *Wallet) ticketPoolSize(hash *chainhash.Hash) (int64, error) {
+	block, err := w.chainSvr.GetBlock(hash)
+	if err != nil {
+		return 0, err
+	}
+	return int64(block.MsgBlock().Header.PoolSize), nil
+}
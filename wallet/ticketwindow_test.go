@@ -0,0 +1,121 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrutil"
+)
+
+var ticketWindowNetParams = []*chaincfg.Params{
+	&chaincfg.MainNetParams,
+	&chaincfg.TestNetParams,
+	&chaincfg.SimNetParams,
+}
+
+func TestStakeWindowPosition(t *testing.T) {
+	for _, params := range ticketWindowNetParams {
+		size := params.StakeDiffWindowSize
+		tests := []struct {
+			height    int64
+			window    int64
+			remaining int64
+		}{
+			{0, 0, size - 1},
+			{size - 2, 0, 1},
+			{size - 1, 1, size},
+			{size, 1, size - 1},
+			{3*size - 2, 2, 1},
+			{3*size - 1, 3, size},
+		}
+		for _, test := range tests {
+			window, remaining := stakeWindowPosition(params, test.height)
+			if window != test.window || remaining != test.remaining {
+				t.Errorf("%s: height %d: got window %d remaining %d, "+
+					"want window %d remaining %d", params.Name,
+					test.height, window, remaining, test.window,
+					test.remaining)
+			}
+		}
+	}
+}
+
+func TestTicketExpiryProbability(t *testing.T) {
+	for _, params := range ticketWindowNetParams {
+		target := int64(params.TicketPoolSize) * int64(params.TicketsPerBlock)
+		if p := ticketExpiryProbability(params, target); p > maxTicketExpiryRisk {
+			t.Errorf("%s: expiry probability %v at target pool size "+
+				"exceeds %v", params.Name, p, maxTicketExpiryRisk)
+		}
+		if p := ticketExpiryProbability(params, 3*target); p <= maxTicketExpiryRisk {
+			t.Errorf("%s: expiry probability %v at three times target "+
+				"pool size does not exceed %v", params.Name, p,
+				maxTicketExpiryRisk)
+		}
+		if p := ticketExpiryProbability(params, 0); p != 0 {
+			t.Errorf("%s: expiry probability %v for unknown pool size",
+				params.Name, p)
+		}
+	}
+}
+
+func TestTicketWindowSchedule(t *testing.T) {
+	for _, params := range ticketWindowNetParams {
+		size := params.StakeDiffWindowSize
+		pool := int64(params.TicketPoolSize) * int64(params.TicketsPerBlock)
+		price := dcrutil.Amount(10e8)
+		tests := []struct {
+			name      string
+			height    int64
+			prevPrice dcrutil.Amount
+			poolSize  int64
+			want      int
+		}{
+			{"window start", size - 1, 0, pool, 5},
+			{"before repricing", size + size - ticketFeeBidBlocks - 1, 0, pool, 5},
+			{"about to reprice", size + size - ticketFeeBidBlocks, 0, pool, 0},
+			{"last block", size + size - 2, 0, pool, 0},
+			{"cheaper window", size, price + 1, pool, 5},
+			{"same price window", size, price, pool, 5},
+			{"expensive window", size, price - 1, pool, 1},
+			{"expiry likely", size, 0, 3 * pool, 0},
+			{"unknown pool size", size, 0, 0, 5},
+		}
+		for _, test := range tests {
+			n, reason := ticketWindowSchedule(params, test.height, price,
+				test.prevPrice, test.poolSize, 5)
+			if n != test.want {
+				t.Errorf("%s: %s: got %d tickets (%q), want %d",
+					params.Name, test.name, n, reason, test.want)
+			}
+			if n < 5 && reason == "" {
+				t.Errorf("%s: %s: no reason for limiting purchases",
+					params.Name, test.name)
+			}
+		}
+	}
+}
+
+func TestTicketMineDeadline(t *testing.T) {
+	params := &chaincfg.SimNetParams
+	size := params.StakeDiffWindowSize
+	tests := []struct {
+		height   int64
+		expiry   uint32
+		deadline int64
+	}{
+		{0, 0, size - 1},
+		{size - 2, 0, size - 1},
+		{size - 1, 0, 2*size - 1},
+		{size, 0, 2*size - 1},
+		{size, uint32(size + 3), size + 2},
+		{size, uint32(3 * size), 2*size - 1},
+	}
+	for _, test := range tests {
+		deadline := ticketMineDeadline(params, test.height, test.expiry)
+		if deadline != test.deadline {
+			t.Errorf("ticketMineDeadline(%d, %d) = %d, want %d",
+				test.height, test.expiry, deadline, test.deadline)
+		}
+	}
+}
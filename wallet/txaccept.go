@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+)
+
+// TxRejectCode describes why a transaction would not be accepted to the
+// mempool of the chain server.
+type TxRejectCode int
+
+// These constants define the possible transaction rejection reasons.
+const (
+	// TxRejectFeeTooLow indicates the transaction does not pay the fee
+	// required for relay.
+	TxRejectFeeTooLow TxRejectCode = iota
+
+	// TxRejectExpiryTooSoon indicates the transaction expires before it
+	// could be mined in the next block.
+	TxRejectExpiryTooSoon
+
+	// TxRejectNonStandard indicates the transaction violates the
+	// standardness rules of the mempool, such as including a non-standard
+	// output script or exceeding the maximum standard size.
+	TxRejectNonStandard
+
+	// TxRejectDuplicate indicates the chain server already has the
+	// transaction.
+	TxRejectDuplicate
+
+	// TxRejectDoubleSpend indicates the transaction spends an output that
+	// is already spent.
+	TxRejectDoubleSpend
+
+	// TxRejectOther indicates the chain server rejected the transaction for
+	// any other reason.
+	TxRejectOther
+)
+
+var txRejectCodeStrings = map[TxRejectCode]string{
+	TxRejectFeeTooLow:     "fee too low",
+	TxRejectExpiryTooSoon: "expiry too soon",
+	TxRejectNonStandard:   "non-standard",
+	TxRejectDuplicate:     "duplicate",
+	TxRejectDoubleSpend:   "double spend",
+	TxRejectOther:         "rejected",
+}
+
+// String returns the TxRejectCode as a human-readable string.
+func (c TxRejectCode) String() string {
+	if s, ok := txRejectCodeStrings[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown TxRejectCode (%d)", int(c))
+}
+
+// TxRejectedError represents an error where a transaction was not published
+// because it would be, or was, rejected by the mempool of the chain server.
+type TxRejectedError struct {
+	Code   TxRejectCode
+	Reason string
+}
+
+// Error satisifies the builtin error interface.
+func (e TxRejectedError) Error() string {
+	return fmt.Sprintf("transaction rejected (%v): %s", e.Code, e.Reason)
+}
+
+// checkTxAcceptance performs the mempool policy checks which may be done
+// without the chain server, returning a TxRejectedError if the transaction
+// would not be accepted at the given height of the main chain.  Checks which
+// require the chain state, such as the fee paid by inputs not controlled by
+// the wallet, are left to the chain server.
+func checkTxAcceptance(msgTx *wire.MsgTx, height int32) error {
+	if size := msgTx.SerializeSize(); size > maxTxSize {
+		return TxRejectedError{TxRejectNonStandard, fmt.Sprintf(
+			"size of %d bytes exceeds the maximum of %d", size, maxTxSize)}
+	}
+
+	// A transaction with an expiry must be mineable in the next block.
+	if msgTx.Expiry != 0 && int64(msgTx.Expiry) <= int64(height)+1 {
+		return TxRejectedError{TxRejectExpiryTooSoon, fmt.Sprintf(
+			"expiry height %d is not after the next block height %d",
+			msgTx.Expiry, height+1)}
+	}
+
+	for i, txOut := range msgTx.TxOut {
+		if txOut.Version != txscript.DefaultScriptVersion {
+			return TxRejectedError{TxRejectNonStandard, fmt.Sprintf(
+				"output %d uses unsupported script version %d", i,
+				txOut.Version)}
+		}
+		class := txscript.GetScriptClass(txOut.Version, txOut.PkScript)
+		if class == txscript.NonStandardTy {
+			return TxRejectedError{TxRejectNonStandard, fmt.Sprintf(
+				"output %d script is non-standard", i)}
+		}
+	}
+
+	return nil
+}
+
+// rejectionError converts an error returned by the chain server when sending
+// a transaction to a TxRejectedError describing the rejection reason.  Errors
+// not caused by a mempool rejection are returned unchanged.
+func rejectionError(err error) error {
+	rpcErr, ok := err.(*dcrjson.RPCError)
+	if !ok {
+		return err
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	code := TxRejectOther
+	switch {
+	case strings.Contains(msg, "already have"):
+		code = TxRejectDuplicate
+	case strings.Contains(msg, "already spent"),
+		strings.Contains(msg, "double spend"):
+		code = TxRejectDoubleSpend
+	case strings.Contains(msg, "fee"), strings.Contains(msg, "priority"):
+		code = TxRejectFeeTooLow
+	case strings.Contains(msg, "expir"):
+		code = TxRejectExpiryTooSoon
+	case strings.Contains(msg, "standard"):
+		code = TxRejectNonStandard
+	}
+	return TxRejectedError{code, rpcErr.Message}
+}
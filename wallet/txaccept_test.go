@@ -0,0 +1,79 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrd/wire"
+)
+
+func TestCheckTxAcceptance(t *testing.T) {
+	// OP_DUP OP_HASH160 <20 bytes> OP_EQUALVERIFY OP_CHECKSIG
+	p2pkh := append(append([]byte{0x76, 0xa9, 0x14}, make([]byte, 20)...),
+		0x88, 0xac)
+
+	newTx := func(expiry uint32, pkScript []byte) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil))
+		tx.AddTxOut(wire.NewTxOut(1e8, pkScript))
+		tx.Expiry = expiry
+		return tx
+	}
+	tests := []struct {
+		tx   *wire.MsgTx
+		code TxRejectCode
+		ok   bool
+	}{
+		{newTx(0, p2pkh), 0, true},
+		{newTx(102, p2pkh), 0, true},
+		{newTx(101, p2pkh), TxRejectExpiryTooSoon, false},
+		{newTx(0, []byte{0xff}), TxRejectNonStandard, false},
+	}
+	for i, test := range tests {
+		err := checkTxAcceptance(test.tx, 100)
+		if test.ok {
+			if err != nil {
+				t.Errorf("test %d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		e, ok := err.(TxRejectedError)
+		if !ok || e.Code != test.code {
+			t.Errorf("test %d: got error %v, expected %v", i, err,
+				test.code)
+		}
+	}
+}
+
+func TestRejectionError(t *testing.T) {
+	tests := []struct {
+		message string
+		code    TxRejectCode
+	}{
+		{"already have transaction abc", TxRejectDuplicate},
+		{"transaction abc has 0 fees which is under the required amount " +
+			"of 1000", TxRejectFeeTooLow},
+		{"transaction abc is not standard: non-standard script form",
+			TxRejectNonStandard},
+		{"output abc:0 already spent", TxRejectDoubleSpend},
+		{"something else", TxRejectOther},
+	}
+	for _, test := range tests {
+		err := rejectionError(&dcrjson.RPCError{
+			Code:    dcrjson.ErrRPCMisc,
+			Message: test.message,
+		})
+		e, ok := err.(TxRejectedError)
+		if !ok || e.Code != test.code {
+			t.Errorf("%q: got %v, expected code %v", test.message, err,
+				test.code)
+		}
+	}
+
+	// Errors other than rejections by the chain server are unchanged.
+	other := errors.New("connection refused")
+	if err := rejectionError(other); err != other {
+		t.Errorf("unexpected conversion of %v to %v", other, err)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrjson"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+// ErrInvalidTransactionCursor describes an error where a transaction cursor
+// no longer refers to a transaction at its height, which happens when the
+// transaction is removed or moved to another block by a reorganization.
+var ErrInvalidTransactionCursor = errors.New("transaction cursor is no " +
+	"longer valid")
+
+// TransactionCursor is the position of a transaction in the wallet's history,
+// ordered newest first.  Height is -1 for unmined transactions.
+type TransactionCursor struct {
+	Height int32
+	Hash   chainhash.Hash
+}
+
+// String returns the cursor encoded as height:hash.
+func (c *TransactionCursor) String() string {
+	return fmt.Sprintf("%d:%v", c.Height, &c.Hash)
+}
+
+// ParseTransactionCursor decodes a cursor encoded by TransactionCursor.String.
+func ParseTransactionCursor(s string) (*TransactionCursor, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed transaction cursor %q", s)
+	}
+	height, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil || height < -1 {
+		return nil, fmt.Errorf("malformed transaction cursor %q", s)
+	}
+	hash, err := chainhash.NewHashFromStr(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed transaction cursor %q", s)
+	}
+	return &TransactionCursor{Height: int32(height), Hash: *hash}, nil
+}
+
+// TransactionFilter selects the transactions returned by
+// ListTransactionsFiltered.  Zero values of the fields do not filter.
+// Account and Addresses match transactions paying to or spending from the
+// account or an encoded address.  StartTime and EndTime bound the block time
+// of mined transactions, or the time unmined transactions were received.
+// When Cursor is set, only transactions older than the cursor are returned.
+// The first Skip matching transactions are skipped, and at most Count
+// transactions are returned if Count is positive.
+type TransactionFilter struct {
+	Account   *uint32
+	Addresses map[string]struct{}
+	TxTypes   []stake.TxType
+	StartTime time.Time
+	EndTime   time.Time
+	Cursor    *TransactionCursor
+	Skip      int
+	Count     int
+}
+
+// matchesScript returns whether an output script pays to an address selected
+// by the filter's account and addresses.
+func (w *Wallet) matchesScript(f *TransactionFilter, version uint16,
+	pkScript []byte) bool {
+	_, addrs, _, _ := txscript.ExtractPkScriptAddrs(version, pkScript,
+		w.chainParams)
+	for _, addr := range addrs {
+		if f.Addresses != nil {
+			if _, ok := f.Addresses[addr.EncodeAddress()]; !ok {
+				continue
+			}
+		}
+		if f.Account != nil {
+			account, err := w.Manager.AddrAccount(addr)
+			if err != nil || account != *f.Account {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// matchesFilter returns whether a transaction is selected by the filter.
+func (w *Wallet) matchesFilter(f *TransactionFilter,
+	details *wtxmgr.TxDetails) (bool, error) {
+	if len(f.TxTypes) != 0 {
+		var ok bool
+		for _, t := range f.TxTypes {
+			if details.TxType == t {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	if !f.StartTime.IsZero() || !f.EndTime.IsZero() {
+		t := details.Received
+		if details.Block.Height != -1 {
+			t = details.Block.Time
+		}
+		if !f.StartTime.IsZero() && t.Before(f.StartTime) {
+			return false, nil
+		}
+		if !f.EndTime.IsZero() && !t.Before(f.EndTime) {
+			return false, nil
+		}
+	}
+
+	if f.Account == nil && f.Addresses == nil {
+		return true, nil
+	}
+	for _, txOut := range details.MsgTx.TxOut {
+		if w.matchesScript(f, txOut.Version, txOut.PkScript) {
+			return true, nil
+		}
+	}
+	if len(details.Debits) == 0 {
+		return false, nil
+	}
+	var block *wtxmgr.Block
+	if details.Block.Height != -1 {
+		block = &details.Block.Block
+	}
+	pkScripts, err := w.TxStore.PreviousPkScripts(&details.TxRecord, block)
+	if err != nil {
+		return false, err
+	}
+	for _, pkScript := range pkScripts {
+		if w.matchesScript(f, txscript.DefaultScriptVersion, pkScript) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListTransactionsFiltered returns the listtransactions results of the
+// transactions selected by a filter, newest first, and the cursor of the last
+// returned transaction.  The cursor may be passed in a later filter to page
+// through the history.  The history is ranged from the cursor's height, so
+// only the transactions which are returned or skipped are read.  A nil cursor
+// is returned if no transactions were returned.
+func (w *Wallet) ListTransactionsFiltered(f *TransactionFilter) (
+	[]dcrjson.ListTransactionsResult, *TransactionCursor, error) {
+	txList := []dcrjson.ListTransactionsResult{}
+	var next *TransactionCursor
+
+	syncBlock := w.Manager.SyncedTo()
+
+	begin := int32(-1)
+	if f.Cursor != nil {
+		begin = f.Cursor.Height
+	}
+
+	skipped := 0
+	n := 0
+	err := w.TxStore.RangeTransactions(begin, 0, func(details []wtxmgr.TxDetails) (bool, error) {
+		// Transactions at the cursor's height up to and including the
+		// cursor were returned by an earlier call.  Unmined
+		// transactions are ordered by hash, so the cursor remains
+		// usable even after the transaction it refers to is mined.
+		i := len(details) - 1
+		height := details[0].Block.Height
+		if f.Cursor != nil && height == f.Cursor.Height {
+			if height == -1 {
+				for i >= 0 && bytes.Compare(details[i].Hash[:],
+					f.Cursor.Hash[:]) >= 0 {
+					i--
+				}
+			} else {
+				for i >= 0 && details[i].Hash != f.Cursor.Hash {
+					i--
+				}
+				if i < 0 {
+					return true, ErrInvalidTransactionCursor
+				}
+				i--
+			}
+		}
+
+		// Iterate over transactions at this height in reverse order,
+		// matching ListTransactions.
+		for ; i >= 0; i-- {
+			ok, err := w.matchesFilter(f, &details[i])
+			if err != nil {
+				return true, err
+			}
+			if !ok {
+				continue
+			}
+			if f.Skip > skipped {
+				skipped++
+				continue
+			}
+			if f.Count > 0 && n >= f.Count {
+				return true, nil
+			}
+			n++
+
+			jsonResults := ListTransactions(&details[i],
+				w.Manager, syncBlock.Height, w.chainParams)
+			txList = append(txList, jsonResults...)
+			next = &TransactionCursor{
+				Height: details[i].Block.Height,
+				Hash:   details[i].Hash,
+			}
+		}
+
+		return false, nil
+	})
+
+	return txList, next, err
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wstakemgr"
+)
+
+// VSPTicket describes a ticket voted by a voting service provider.  PoolFee
+// is the amount the ticket commits to the pool address, and FeePaid reports
+// whether it is at least the pool's fee percentage, without which the pool
+// will not vote the ticket.
+type VSPTicket struct {
+	Hash    chainhash.Hash
+	Status  wstakemgr.TicketStatus
+	Price   dcrutil.Amount
+	PoolFee dcrutil.Amount
+	FeePaid bool
+}
+
+// VSPPubKeyAddress returns the public key address of a new external address
+// of an account, which may be registered with a voting service provider to
+// create the multisig script tickets delegated to the provider vote with.
+func (w *Wallet) VSPPubKeyAddress(account uint32) (*dcrutil.AddressSecpPubKey,
+	error) {
+	addr, err := w.NewAddress(account)
+	if err != nil {
+		return nil, err
+	}
+	ainfo, err := w.Manager.Address(addr)
+	if err != nil {
+		return nil, err
+	}
+	pka, ok := ainfo.(waddrmgr.ManagedPubKeyAddress)
+	if !ok {
+		return nil, fmt.Errorf("address %v has no public key", addr)
+	}
+	return dcrutil.NewAddressSecpPubKey(pka.PubKey().SerializeCompressed(),
+		w.chainParams)
+}
+
+// ImportVSPScript checks that the redeem script returned by a voting service
+// provider is a multisig script which the wallet holds a key of and which
+// hashes to the provider's ticket address, and then imports it.  The
+// blockchain is rescanned from height firstSeen for tickets already
+// purchased with the script.
+func (w *Wallet) ImportVSPScript(script []byte, ticketAddr dcrutil.Address,
+	firstSeen int32) (*ImportedScript, error) {
+	scriptAddr, err := dcrutil.NewAddressScriptHash(script, w.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(scriptAddr.ScriptAddress(), ticketAddr.ScriptAddress()) {
+		return nil, fmt.Errorf("redeem script hashes to %v, not the "+
+			"ticket address %v", scriptAddr, ticketAddr)
+	}
+
+	class, addrs, _, err := txscript.ExtractPkScriptAddrs(
+		txscript.DefaultScriptVersion, script, w.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	if class != txscript.MultiSigTy {
+		return nil, fmt.Errorf("redeem script is %v, not multisig", class)
+	}
+	owned := false
+	for _, addr := range addrs {
+		if _, err := w.Manager.Address(addr); err == nil {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return nil, fmt.Errorf("redeem script does not include a key " +
+			"of the wallet")
+	}
+
+	return w.ImportScript(script, firstSeen)
+}
+
+// VSPTickets returns the tickets which vote with the ticket address of a
+// voting service provider, sorted by hash, along with the pool fee each
+// commits to poolAddr and whether that satisfies the pool's fee of poolFees
+// percent.
+func (w *Wallet) VSPTickets(ticketAddr, poolAddr dcrutil.Address,
+	poolFees float64) ([]VSPTicket, error) {
+	tickets, err := w.StakeMgr.TicketsForAddress(ticketAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	poolAddrStr := poolAddr.EncodeAddress()
+	results := make([]VSPTicket, 0, len(tickets))
+	for i := range tickets {
+		t := &tickets[i]
+		if !t.Voting {
+			continue
+		}
+		status, err := w.StakeMgr.TicketStatus(&t.Hash)
+		if err != nil {
+			return nil, err
+		}
+		commitments, err := w.TicketCommitments(&t.Hash)
+		if err != nil {
+			return nil, err
+		}
+		var poolFee, total dcrutil.Amount
+		for _, c := range commitments.Commitments {
+			total += c.Amount
+			if c.Address.EncodeAddress() == poolAddrStr {
+				poolFee += c.Amount
+			}
+		}
+		results = append(results, VSPTicket{
+			Hash:    t.Hash,
+			Status:  status,
+			Price:   commitments.Price,
+			PoolFee: poolFee,
+			FeePaid: float64(poolFee)*100 >= poolFees*float64(total),
+		})
+	}
+	sort.Sort(vspTicketsByHash(results))
+	return results, nil
+}
+
+// vspTicketsByHash sorts VSP tickets by hash.
+type vspTicketsByHash []VSPTicket
+
+func (s vspTicketsByHash) Len() int { return len(s) }
+func (s vspTicketsByHash) Less(i, j int) bool {
+	return bytes.Compare(s[i].Hash[:], s[j].Hash[:]) < 0
+}
+func (s vspTicketsByHash) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
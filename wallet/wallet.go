@@ -98,6 +98,44 @@ type Wallet struct {
 	CurrentVotingInfo  *VotingInfo
 	TicketMaxPrice     dcrutil.Amount
 
+	// Automatic ticket buyer policy and the fee of the last purchased ticket.
+	ticketBuyerMu      sync.Mutex
+	maxTicketsPerBlock int
+	ticketMaxFeeRate   dcrutil.Amount
+	ticketBatchLabel   string
+	ticketFeeRate      dcrutil.Amount
+
+	// Tickets purchased in the current stake difficulty window, limited
+	// to maxTicketsPerWindow when it is non-zero.  The count is stored in
+	// the stake store so it is restored when the wallet is opened.
+	maxTicketsPerWindow int
+	purchaseWindow      int64
+	windowPurchases     int
+
+	// Ticket prices of the current and previous stake difficulty windows.
+	priceWindow     int64
+	windowPrice     dcrutil.Amount
+	prevWindowPrice dcrutil.Amount
+
+	// Block summaries of the recent stake difficulty windows, keyed by
+	// height, used to forecast the next stake difficulty.
+	stakeDiffMu     sync.Mutex
+	stakeDiffBlocks map[int64]stakeDiffBlock
+
+	// Automatic revocation of missed and expired tickets.
+	autoRevoke        bool
+	revocationMu      sync.Mutex
+	revocationSummary RevocationSummary
+
+	// Stake pool mode.
+	stakePoolEnabled bool
+	poolAddress      dcrutil.Address
+	poolFees         float64
+
+	// Voting-only mode, voting tickets delegated to the wallet without
+	// spending any funds.
+	votingOnly bool
+
 	automaticRepair bool
 
 	chainSvr        *chain.Client
@@ -109,8 +147,39 @@ type Wallet struct {
 
 	feeIncrementLock sync.Mutex
 	feeIncrement     dcrutil.Amount
+	maxFee           dcrutil.Amount
+	maxFeePercent    float64
 	DisallowFree     bool
 
+	// SeparateCreditOrigins restricts the inputs selected for created
+	// transactions to credits of a single origin.
+	SeparateCreditOrigins bool
+
+	// GapLimit is the number of consecutive unused external addresses
+	// after which new addresses exceed the gap limit.
+	GapLimit uint32
+
+	spendPolicyMu sync.Mutex
+	spendPolicy   SpendPolicy
+
+	signingPolicyMu sync.Mutex
+	signingPolicy   *SigningPolicy
+
+	// Source of the fiat rates stamped on new transactions, and the last
+	// rate fetched from it.
+	fiatRateMu     sync.Mutex
+	fiatRateSource *FiatRateSource
+	fiatRate       *wtxmgr.FiatRate
+
+	// Signer holding the private keys of a watching-only wallet, if any.
+	remoteSignerMu sync.Mutex
+	remoteSigner   RemoteSigner
+
+	// Sends waiting for approval, keyed by transaction hash.
+	pendingSends       map[chainhash.Hash]*PendingSend
+	sendApprovalPolicy *SendApprovalPolicy
+	pendingSendsMu     sync.Mutex
+
 	// Channels for rescan processing.  Requests are added and merged with
 	// any waiting requests, before being sent to another goroutine to
 	// call the rescan RPC.
@@ -119,22 +188,42 @@ type Wallet struct {
 	rescanNotifications chan interface{} // From chain server
 	rescanProgress      chan *RescanProgressMsg
 	rescanFinished      chan *RescanFinishedMsg
+	rescanQueueMu       sync.Mutex
+	rescanQueueDepth    int
+
+	// Cancellation of the running RescanWallet call, if any.
+	rescanWalletMu     sync.Mutex
+	rescanWalletCancel chan struct{}
 
 	// Channel for transaction creation requests.
 	createTxRequests         chan createTxRequest
+	previewTxRequests        chan previewTxRequest
 	createMultisigTxRequests chan createMultisigTxRequest
 
 	// Channels for stake tx creation requests.
-	createSStxRequests     chan createSStxRequest
-	createSSGenRequests    chan createSSGenRequest
-	createSSRtxRequests    chan createSSRtxRequest
-	purchaseTicketRequests chan purchaseTicketRequest
+	createSStxRequests      chan createSStxRequest
+	createSSGenRequests     chan createSSGenRequest
+	createSSRtxRequests     chan createSSRtxRequest
+	purchaseTicketRequests  chan purchaseTicketRequest
+	purchaseTicketsRequests chan purchaseTicketsRequest
+
+	// Ticket warnings already sent, so each unmined ticket and stake
+	// difficulty window is only warned about once.
+	ticketWarnWindow int64
+	ticketWarned     map[chainhash.Hash]struct{}
+	ticketWarningsMu sync.Mutex
+
+	// Transactions signed by the wallet which are not recorded in the
+	// transaction store, such as those signed by signrawtransaction.
+	authoredTxs   map[chainhash.Hash]struct{}
+	authoredTxsMu sync.Mutex
 
 	// Internal address handling.
 	internalPool  *addressPool
 	externalPool  *addressPool
 	addressReuse  bool
 	ticketAddress dcrutil.Address
+	rewardAddress dcrutil.Address
 
 	// Live rollback testing for wtxmgr.
 	rollbackTesting bool
@@ -145,6 +234,8 @@ type Wallet struct {
 	lockRequests       chan struct{}
 	holdUnlockRequests chan chan HeldUnlock
 	lockState          chan bool
+	lockInfo           chan LockInfo
+	lockTimeouts       chan lockTimeoutRequest
 	changePassphrase   chan changePassphraseRequest
 
 	// Notification channels so other components can listen in on wallet
@@ -155,6 +246,10 @@ type Wallet struct {
 	ticketsPurchased        chan wstakemgr.StakeNotification
 	votesCreated            chan wstakemgr.StakeNotification
 	revocationsCreated      chan wstakemgr.StakeNotification
+	ticketOutcomes          chan TicketOutcome
+	ticketWarnings          chan TicketWarning
+	unexpectedSpends        chan UnexpectedSpend
+	rescanWalletProgress    chan RescanWalletProgress
 	relevantTxs             chan chain.RelevantTx
 	lockStateChanges        chan bool // true when locked
 	confirmedBalance        chan dcrutil.Amount
@@ -172,61 +267,129 @@ type Wallet struct {
 	quitMu  sync.Mutex
 }
 
-// newWallet creates a new Wallet structure with the provided address manager
-// and transaction store.
-func newWallet(vb uint16, esm bool, btm dcrutil.Amount, addressReuse bool,
-	rollbackTest bool, ticketAddress dcrutil.Address, tmp dcrutil.Amount,
-	autoRepair bool, mgr *waddrmgr.Manager, txs *wtxmgr.Store,
+// Config holds the settings of a wallet opened by Open.  Amounts are in coins
+// and addresses are encoded as strings, as they are read from the
+// configuration of the wallet process, and are validated by Open.  The zero
+// value disables every optional feature.
+type Config struct {
+	VoteBits           uint16
+	StakeMiningEnabled bool
+	BalanceToMaintain  float64
+	AddressReuse       bool
+	RollbackTest       bool
+	PruneTickets       bool
+	AutoRepair         bool
+
+	// TicketAddress is given the voting rights of purchased tickets, and
+	// RewardAddress, which must be a pubkey hash address, is committed
+	// the rewards.  Addresses of the wallet are used when they are empty.
+	TicketAddress string
+	RewardAddress string
+
+	// Limits of the fees paid and tickets purchased by the wallet.
+	TicketMaxPrice      float64
+	MaxFee              float64
+	MaxFeePercent       float64
+	MaxTicketsPerBlock  int
+	TicketMaxFeeRate    float64
+	MaxTicketsPerWindow int
+
+	AutoRevoke bool
+
+	// StakePoolEnabled votes tickets delegated to the wallet which pay
+	// PoolFees percent of their reward to PoolAddress.
+	StakePoolEnabled bool
+	PoolAddress      string
+	PoolFees         float64
+
+	VotingOnly bool
+}
+
+// walletConfig holds the settings of a new Wallet structure, parsed from a
+// Config by Open.
+type walletConfig struct {
+	voteBits            uint16
+	stakeMiningEnabled  bool
+	balanceToMaintain   dcrutil.Amount
+	addressReuse        bool
+	rollbackTest        bool
+	autoRepair          bool
+	ticketAddress       dcrutil.Address
+	rewardAddress       dcrutil.Address
+	ticketMaxPrice      dcrutil.Amount
+	maxFee              dcrutil.Amount
+	maxFeePercent       float64
+	maxTicketsPerBlock  int
+	ticketMaxFeeRate    dcrutil.Amount
+	maxTicketsPerWindow int
+	autoRevoke          bool
+	stakePoolEnabled    bool
+	poolAddress         dcrutil.Address
+	poolFees            float64
+	votingOnly          bool
+}
+
+// newWallet creates a new Wallet structure with the provided settings, address
+// manager and transaction store.
+func newWallet(cfg *walletConfig, mgr *waddrmgr.Manager, txs *wtxmgr.Store,
 	smgr *wstakemgr.StakeStore, db *walletdb.DB, params *chaincfg.Params) *Wallet {
 	var rollbackBlockDB map[uint32]*wtxmgr.DatabaseContents
-	if rollbackTest {
+	if cfg.rollbackTest {
 		rollbackBlockDB = make(map[uint32]*wtxmgr.DatabaseContents)
 	}
 
-	var feeIncrement dcrutil.Amount
-	switch {
-	case params == &chaincfg.MainNetParams:
-		feeIncrement = FeeIncrementMainnet
-	case params == &chaincfg.TestNetParams:
-		feeIncrement = FeeIncrementTestnet
-	default:
-		feeIncrement = FeeIncrementTestnet
-	}
-
 	return &Wallet{
 		db:                       *db,
 		Manager:                  mgr,
 		TxStore:                  txs,
 		StakeMgr:                 smgr,
-		StakeMiningEnabled:       esm,
-		VoteBits:                 vb,
-		BalanceToMaintain:        btm,
+		StakeMiningEnabled:       cfg.stakeMiningEnabled,
+		VoteBits:                 cfg.voteBits,
+		BalanceToMaintain:        cfg.balanceToMaintain,
 		CurrentStakeDiff:         &StakeDifficultyInfo{nil, -1, -1},
 		lockedOutpoints:          map[wire.OutPoint]struct{}{},
-		feeIncrement:             feeIncrement,
+		pendingSends:             map[chainhash.Hash]*PendingSend{},
+		feeIncrement:             defaultFeeIncrement(params),
+		maxFee:                   cfg.maxFee,
+		maxFeePercent:            cfg.maxFeePercent,
+		spendPolicy:              DefaultSpendPolicy,
+		GapLimit:                 DefaultGapLimit,
 		rescanAddJob:             make(chan *RescanJob),
 		rescanBatch:              make(chan *rescanBatch),
 		rescanNotifications:      make(chan interface{}),
 		rescanProgress:           make(chan *RescanProgressMsg),
 		rescanFinished:           make(chan *RescanFinishedMsg),
 		createTxRequests:         make(chan createTxRequest),
+		previewTxRequests:        make(chan previewTxRequest),
 		createMultisigTxRequests: make(chan createMultisigTxRequest),
 		createSStxRequests:       make(chan createSStxRequest),
 		createSSGenRequests:      make(chan createSSGenRequest),
 		createSSRtxRequests:      make(chan createSSRtxRequest),
 		purchaseTicketRequests:   make(chan purchaseTicketRequest),
+		purchaseTicketsRequests:  make(chan purchaseTicketsRequest),
 		internalPool:             new(addressPool),
 		externalPool:             new(addressPool),
-		addressReuse:             addressReuse,
-		ticketAddress:            ticketAddress,
-		TicketMaxPrice:           tmp,
-		automaticRepair:          autoRepair,
-		rollbackTesting:          rollbackTest,
+		addressReuse:             cfg.addressReuse,
+		ticketAddress:            cfg.ticketAddress,
+		rewardAddress:            cfg.rewardAddress,
+		TicketMaxPrice:           cfg.ticketMaxPrice,
+		maxTicketsPerBlock:       cfg.maxTicketsPerBlock,
+		ticketMaxFeeRate:         cfg.ticketMaxFeeRate,
+		maxTicketsPerWindow:      cfg.maxTicketsPerWindow,
+		autoRevoke:               cfg.autoRevoke,
+		stakePoolEnabled:         cfg.stakePoolEnabled,
+		poolAddress:              cfg.poolAddress,
+		poolFees:                 cfg.poolFees,
+		votingOnly:               cfg.votingOnly,
+		automaticRepair:          cfg.autoRepair,
+		rollbackTesting:          cfg.rollbackTest,
 		rollbackBlockDB:          rollbackBlockDB,
 		unlockRequests:           make(chan unlockRequest),
 		lockRequests:             make(chan struct{}),
 		holdUnlockRequests:       make(chan chan HeldUnlock),
 		lockState:                make(chan bool),
+		lockInfo:                 make(chan LockInfo),
+		lockTimeouts:             make(chan lockTimeoutRequest),
 		changePassphrase:         make(chan changePassphraseRequest),
 		chainParams:              params,
 		quit:                     make(chan struct{}),
@@ -276,6 +439,26 @@ func (w *Wallet) SetFeeIncrement(fee dcrutil.Amount) {
 	w.feeIncrementLock.Unlock()
 }
 
+// FeeLimits returns the maximum absolute fee and the maximum fee as a
+// percentage of the amount sent that the wallet will pay for a transaction.
+// A zero value means the respective limit is disabled.
+func (w *Wallet) FeeLimits() (dcrutil.Amount, float64) {
+	w.feeIncrementLock.Lock()
+	maxFee, maxFeePercent := w.maxFee, w.maxFeePercent
+	w.feeIncrementLock.Unlock()
+
+	return maxFee, maxFeePercent
+}
+
+// SetFeeLimits sets the maximum absolute fee and the maximum fee as a
+// percentage of the amount sent that the wallet will pay for a transaction.
+func (w *Wallet) SetFeeLimits(maxFee dcrutil.Amount, maxFeePercent float64) {
+	w.feeIncrementLock.Lock()
+	w.maxFee = maxFee
+	w.maxFeePercent = maxFeePercent
+	w.feeIncrementLock.Unlock()
+}
+
 // SetGenerate is used to enable or disable stake mining in the
 // wallet.
 func (w *Wallet) SetGenerate(flag bool) error {
@@ -299,6 +482,7 @@ func (w *Wallet) SetGenerate(flag bool) error {
 			return err
 		}
 
+		w.recordStakeSigning(ntfns)
 		if ntfns != nil {
 			// Send notifications for newly created votes by the RPC.
 			for _, ntfn := range ntfns {
@@ -344,6 +528,24 @@ func (w *Wallet) SetCurrentVotingInfo(blockHash *chainhash.Hash,
 	w.CurrentVotingInfo = &VotingInfo{blockHash, blockHeight, tickets}
 }
 
+// GetVoteBits gets the vote bits used when voting with tickets which do not
+// have their own vote bits set.
+func (w *Wallet) GetVoteBits() uint16 {
+	w.stakeSettingsLock.Lock()
+	defer w.stakeSettingsLock.Unlock()
+
+	return w.VoteBits
+}
+
+// SetVoteBits sets the vote bits used when voting with tickets which do not
+// have their own vote bits set.
+func (w *Wallet) SetVoteBits(voteBits uint16) {
+	w.stakeSettingsLock.Lock()
+	defer w.stakeSettingsLock.Unlock()
+
+	w.VoteBits = voteBits
+}
+
 // GetTicketMaxPrice gets the current maximum price the user is willing to pay
 // for a ticket.
 func (w *Wallet) GetTicketMaxPrice() dcrutil.Amount {
@@ -451,6 +653,71 @@ func (w *Wallet) ListenRevocationsCreated() (<-chan wstakemgr.StakeNotification,
 	return w.revocationsCreated, nil
 }
 
+// ListenTicketOutcomes returns a channel that passes the outcome of each of
+// the wallet's tickets which votes, is missed, expires, or is revoked.  This
+// channel must be read, or other wallet methods will block.
+//
+// If this is called twice, ErrDuplicateListen is returned.
+func (w *Wallet) ListenTicketOutcomes() (<-chan TicketOutcome, error) {
+	defer w.notificationMu.Unlock()
+	w.notificationMu.Lock()
+
+	if w.ticketOutcomes != nil {
+		return nil, ErrDuplicateListen
+	}
+	w.ticketOutcomes = make(chan TicketOutcome)
+	return w.ticketOutcomes, nil
+}
+
+// ListenTicketWarnings returns a channel that passes warnings of unmined
+// tickets about to become invalid and of stake difficulty windows about to
+// end.  This channel must be read, or other wallet methods will block.
+//
+// If this is called twice, ErrDuplicateListen is returned.
+func (w *Wallet) ListenTicketWarnings() (<-chan TicketWarning, error) {
+	defer w.notificationMu.Unlock()
+	w.notificationMu.Lock()
+
+	if w.ticketWarnings != nil {
+		return nil, ErrDuplicateListen
+	}
+	w.ticketWarnings = make(chan TicketWarning)
+	return w.ticketWarnings, nil
+}
+
+// ListenUnexpectedSpends returns a channel that passes each transaction which
+// spends wallet credits but was not created or signed by the wallet.  This
+// channel must be read, or other wallet methods will block.
+//
+// If this is called twice, ErrDuplicateListen is returned.
+func (w *Wallet) ListenUnexpectedSpends() (<-chan UnexpectedSpend, error) {
+	defer w.notificationMu.Unlock()
+	w.notificationMu.Lock()
+
+	if w.unexpectedSpends != nil {
+		return nil, ErrDuplicateListen
+	}
+	w.unexpectedSpends = make(chan UnexpectedSpend)
+	return w.unexpectedSpends, nil
+}
+
+// ListenRescanWalletProgress returns a channel that passes the progress of
+// rescans started by RescanWallet.  This channel must be read, or other
+// wallet methods will block.
+//
+// If this is called twice, ErrDuplicateListen is returned.
+func (w *Wallet) ListenRescanWalletProgress() (<-chan RescanWalletProgress,
+	error) {
+	defer w.notificationMu.Unlock()
+	w.notificationMu.Lock()
+
+	if w.rescanWalletProgress != nil {
+		return nil, ErrDuplicateListen
+	}
+	w.rescanWalletProgress = make(chan RescanWalletProgress)
+	return w.rescanWalletProgress, nil
+}
+
 // ListenLockStatus returns a channel that passes the current lock state
 // of the wallet whenever the lock state is changed.  The value is true for
 // locked, and false for unlocked.  The channel must be read, or other wallet
@@ -595,6 +862,38 @@ func (w *Wallet) notifyRevocationCreated(sn wstakemgr.StakeNotification) {
 	w.notificationMu.Unlock()
 }
 
+func (w *Wallet) notifyTicketOutcome(outcome TicketOutcome) {
+	w.notificationMu.Lock()
+	if w.ticketOutcomes != nil {
+		w.ticketOutcomes <- outcome
+	}
+	w.notificationMu.Unlock()
+}
+
+func (w *Wallet) notifyTicketWarning(warning TicketWarning) {
+	w.notificationMu.Lock()
+	if w.ticketWarnings != nil {
+		w.ticketWarnings <- warning
+	}
+	w.notificationMu.Unlock()
+}
+
+func (w *Wallet) notifyUnexpectedSpend(spend UnexpectedSpend) {
+	w.notificationMu.Lock()
+	if w.unexpectedSpends != nil {
+		w.unexpectedSpends <- spend
+	}
+	w.notificationMu.Unlock()
+}
+
+func (w *Wallet) notifyRescanWalletProgress(progress RescanWalletProgress) {
+	w.notificationMu.Lock()
+	if w.rescanWalletProgress != nil {
+		w.rescanWalletProgress <- progress
+	}
+	w.notificationMu.Unlock()
+}
+
 func (w *Wallet) notifyRelevantTx(relevantTx chain.RelevantTx) {
 	w.notificationMu.Lock()
 	if w.relevantTxs != nil {
@@ -603,9 +902,13 @@ func (w *Wallet) notifyRelevantTx(relevantTx chain.RelevantTx) {
 	w.notificationMu.Unlock()
 }
 
-// Start starts the goroutines necessary to manage a wallet.
-func (w *Wallet) Start(chainServer *chain.Client) {
+// begin prepares the wallet to start its goroutines, waiting for a previous
+// shutdown to finish if necessary.  It returns false if the wallet is still
+// running and must not be started again.
+func (w *Wallet) begin() bool {
 	w.quitMu.Lock()
+	defer w.quitMu.Unlock()
+
 	select {
 	case <-w.quit:
 		// Restart the wallet goroutines after shutdown finishes.
@@ -614,19 +917,25 @@ func (w *Wallet) Start(chainServer *chain.Client) {
 	default:
 		// Ignore when the wallet is still running.
 		if w.started {
-			w.quitMu.Unlock()
-			return
+			return false
 		}
 		w.started = true
 	}
-	w.quitMu.Unlock()
+	return true
+}
+
+// Start starts the goroutines necessary to manage a wallet.
+func (w *Wallet) Start(chainServer *chain.Client) {
+	if !w.begin() {
+		return
+	}
 
 	w.chainSvrLock.Lock()
 	defer w.chainSvrLock.Unlock()
 	w.chainSvr = chainServer
 	w.StakeMgr.SetChainSvr(chainServer)
 
-	w.wg.Add(7)
+	w.wg.Add(8)
 
 	go w.handleChainNotifications()
 	go w.handleChainVotingNotifications()
@@ -635,6 +944,7 @@ func (w *Wallet) Start(chainServer *chain.Client) {
 	go w.rescanBatchHandler()
 	go w.rescanProgressHandler()
 	go w.rescanRPCHandler()
+	go w.unminedTxResender()
 
 	// Request notifications for winning tickets.
 	err := w.chainSvr.NotifyWinningTickets()
@@ -663,6 +973,10 @@ func (w *Wallet) Start(chainServer *chain.Client) {
 		log.Infof("PLEASE ENSURE YOUR WALLET IS UNLOCKED SO IT MAY " +
 			"VOTE ON BLOCKS AND RECEIVE STAKE REWARDS")
 	}
+	if w.votingOnly {
+		log.Infof("Voting-only mode is enabled; tickets will not be " +
+			"purchased and funds will not be spent")
+	}
 
 	// Spin up the address pools.
 	w.internalPool.initialize(waddrmgr.InternalBranch, w)
@@ -1022,6 +1336,44 @@ func (w *Wallet) activeData() ([]dcrutil.Address, []*wire.OutPoint, error) {
 	return addrs, unspent, err
 }
 
+// birthdayMargin is subtracted from the wallet birthday when finding the
+// first block an initial sync must rescan, to allow for inaccurate
+// birthdays and block timestamps.
+const birthdayMargin = 48 * time.Hour
+
+// syncToBirthday marks the address manager as synced through the last block
+// before the wallet birthday, less birthdayMargin, so that the initial rescan
+// of a restored wallet skips the blocks which cannot involve its keys.  It
+// does nothing if the birthday is not known.
+func (w *Wallet) syncToBirthday() error {
+	birthday, err := w.Manager.Birthday()
+	if err != nil || birthday.IsZero() {
+		return err
+	}
+	_, bestHeight, err := w.chainSvr.GetBestBlock()
+	if err != nil {
+		return err
+	}
+	height, err := w.heightForTime(birthday.Add(-birthdayMargin),
+		bestHeight)
+	if err != nil {
+		return err
+	}
+	if height <= 1 {
+		return nil
+	}
+	hash, err := w.chainSvr.GetBlockHash(int64(height - 1))
+	if err != nil {
+		return err
+	}
+	log.Infof("Skipping rescan of blocks before height %d (wallet "+
+		"birthday %v)", height, birthday)
+	return w.Manager.SetSyncedTo(&waddrmgr.BlockStamp{
+		Height: height - 1,
+		Hash:   *hash,
+	})
+}
+
 // syncWithChain brings the wallet up to date with the current chain server
 // connection.  It creates a rescan request and blocks until the rescan has
 // finished.
@@ -1093,6 +1445,15 @@ func (w *Wallet) syncWithChain() error {
 		}
 	}
 
+	// A wallet which has never been synced only needs to rescan the blocks
+	// after its birthday.
+	if w.Manager.SyncedTo().Height == 0 {
+		err = w.syncToBirthday()
+		if err != nil {
+			return err
+		}
+	}
+
 	err = w.Rescan(addrs, unspent)
 	if err != nil {
 		return err
@@ -1161,14 +1522,22 @@ type (
 		account uint32
 		pairs   map[string]dcrutil.Amount
 		minconf int32
+		origin  string
 		resp    chan createTxResponse
 	}
+	previewTxRequest struct {
+		account uint32
+		pairs   map[string]dcrutil.Amount
+		minconf int32
+		resp    chan previewTxResponse
+	}
 	createMultisigTxRequest struct {
 		account   uint32
 		amount    dcrutil.Amount
 		pubkeys   []*dcrutil.AddressSecpPubKey
 		nrequired int8
 		minconf   int32
+		origin    string
 		resp      chan createMultisigTxResponse
 	}
 	createSStxRequest struct {
@@ -1177,6 +1546,7 @@ type (
 		couts      []dcrjson.SStxCommitOut
 		inputs     []dcrjson.SStxInput
 		minconf    int32
+		origin     string
 		resp       chan createSStxResponse
 	}
 	createSSGenRequest struct {
@@ -1195,6 +1565,7 @@ type (
 		spendLimit dcrutil.Amount
 		minConf    int32
 		ticketAddr dcrutil.Address
+		origin     string
 		resp       chan purchaseTicketResponse
 	}
 
@@ -1202,6 +1573,10 @@ type (
 		tx  *CreatedTx
 		err error
 	}
+	previewTxResponse struct {
+		preview *TxPreview
+		err     error
+	}
 	createMultisigTxResponse struct {
 		tx           *CreatedTx
 		address      dcrutil.Address
@@ -1248,7 +1623,7 @@ out:
 			addrFunc := pool.GetNewAddress
 
 			tx, err := w.txToPairs(txr.pairs, txr.account, txr.minconf,
-				addrFunc)
+				addrFunc, txr.origin)
 			if err == nil {
 				pool.BatchFinish()
 			} else {
@@ -1258,9 +1633,15 @@ out:
 
 			txr.resp <- createTxResponse{tx, err}
 
+		case txr := <-w.previewTxRequests:
+			preview, err := w.previewTx(txr.pairs, txr.account,
+				txr.minconf)
+			txr.resp <- previewTxResponse{preview, err}
+
 		case txr := <-w.createMultisigTxRequests:
 			tx, address, redeemScript, err := w.txToMultisig(txr.account,
-				txr.amount, txr.pubkeys, txr.nrequired, txr.minconf)
+				txr.amount, txr.pubkeys, txr.nrequired, txr.minconf,
+				txr.origin)
 			txr.resp <- createMultisigTxResponse{tx, address, redeemScript, err}
 
 		case txr := <-w.createSStxRequests:
@@ -1275,7 +1656,8 @@ out:
 				txr.couts,
 				waddrmgr.DefaultAccountNum,
 				addrFunc,
-				txr.minconf)
+				txr.minconf,
+				txr.origin)
 			if err == nil {
 				pool.BatchFinish()
 			} else {
@@ -1300,6 +1682,10 @@ out:
 			data, err := w.purchaseTicket(txr)
 			txr.resp <- purchaseTicketResponse{data, err}
 
+		case txr := <-w.purchaseTicketsRequests:
+			hashes, err := w.purchaseTickets(txr)
+			txr.resp <- purchaseTicketsResponse{hashes, err}
+
 		case <-quit:
 			break out
 		}
@@ -1312,14 +1698,18 @@ out:
 // address/amount pairs.  Change and an appropiate transaction fee are
 // automatically included, if necessary.  All transaction creation through
 // this function is serialized to prevent the creation of many transactions
-// which spend the same outputs.
+// which spend the same outputs.  The transaction is signed for origin.
 func (w *Wallet) CreateSimpleTx(account uint32, pairs map[string]dcrutil.Amount,
-	minconf int32) (*CreatedTx, error) {
+	minconf int32, origin string) (*CreatedTx, error) {
+	if w.votingOnly {
+		return nil, ErrVotingOnly
+	}
 
 	req := createTxRequest{
 		account: account,
 		pairs:   pairs,
 		minconf: minconf,
+		origin:  origin,
 		resp:    make(chan createTxResponse),
 	}
 	w.createTxRequests <- req
@@ -1327,11 +1717,33 @@ func (w *Wallet) CreateSimpleTx(account uint32, pairs map[string]dcrutil.Amount,
 	return resp.tx, resp.err
 }
 
-// CreateSStxTx receives a request from the RPC and ships it to txCreator to
-// generate a new SStx.
+// PreviewSimpleTx performs a dry run of CreateSimpleTx, returning the
+// estimated size, fee, selected inputs, and change amount of the transaction
+// that would be created.  Nothing is signed or broadcast, and no inputs or
+// addresses are reserved, so the wallet does not need to be unlocked.
+func (w *Wallet) PreviewSimpleTx(account uint32,
+	pairs map[string]dcrutil.Amount, minconf int32) (*TxPreview, error) {
+
+	req := previewTxRequest{
+		account: account,
+		pairs:   pairs,
+		minconf: minconf,
+		resp:    make(chan previewTxResponse),
+	}
+	w.previewTxRequests <- req
+	resp := <-req.resp
+	return resp.preview, resp.err
+}
+
+// CreateMultisigTx receives a request from the RPC and ships it to txCreator
+// to generate a new transaction paying to a multisig output, signed for
+// origin.
 func (w *Wallet) CreateMultisigTx(account uint32, amount dcrutil.Amount,
 	pubkeys []*dcrutil.AddressSecpPubKey, nrequired int8,
-	minconf int32) (*CreatedTx, dcrutil.Address, []byte, error) {
+	minconf int32, origin string) (*CreatedTx, dcrutil.Address, []byte, error) {
+	if w.votingOnly {
+		return nil, nil, nil, ErrVotingOnly
+	}
 
 	req := createMultisigTxRequest{
 		account:   account,
@@ -1339,6 +1751,7 @@ func (w *Wallet) CreateMultisigTx(account uint32, amount dcrutil.Amount,
 		pubkeys:   pubkeys,
 		nrequired: nrequired,
 		minconf:   minconf,
+		origin:    origin,
 		resp:      make(chan createMultisigTxResponse),
 	}
 	w.createMultisigTxRequests <- req
@@ -1347,12 +1760,16 @@ func (w *Wallet) CreateMultisigTx(account uint32, amount dcrutil.Amount,
 }
 
 // CreateSStxTx receives a request from the RPC and ships it to txCreator to
-// generate a new SStx.
+// generate a new SStx, signed for origin.
 func (w *Wallet) CreateSStxTx(pair map[string]dcrutil.Amount,
 	usedInputs []wtxmgr.Credit,
 	inputs []dcrjson.SStxInput,
 	couts []dcrjson.SStxCommitOut,
-	minconf int32) (*CreatedTx, error) {
+	minconf int32,
+	origin string) (*CreatedTx, error) {
+	if w.votingOnly {
+		return nil, ErrVotingOnly
+	}
 
 	req := createSStxRequest{
 		usedInputs: usedInputs,
@@ -1360,6 +1777,7 @@ func (w *Wallet) CreateSStxTx(pair map[string]dcrutil.Amount,
 		inputs:     inputs,
 		couts:      couts,
 		minconf:    minconf,
+		origin:     origin,
 		resp:       make(chan createSStxResponse),
 	}
 	w.createSStxRequests <- req
@@ -1400,15 +1818,20 @@ func (w *Wallet) CreateSSRtx(ticketHash chainhash.Hash) (*CreatedTx, error) {
 }
 
 // CreatePurchaseTicket receives a request from the RPC and ships it to txCreator
-// to purchase a new ticket.
+// to purchase a new ticket, signed for origin.
 func (w *Wallet) CreatePurchaseTicket(minBalance, spendLimit dcrutil.Amount,
-	minConf int32, ticketAddr dcrutil.Address) (interface{}, error) {
+	minConf int32, ticketAddr dcrutil.Address,
+	origin string) (interface{}, error) {
+	if w.votingOnly {
+		return nil, ErrVotingOnly
+	}
 
 	req := purchaseTicketRequest{
 		minBalance: minBalance,
 		spendLimit: spendLimit,
 		minConf:    minConf,
 		ticketAddr: ticketAddr,
+		origin:     origin,
 		resp:       make(chan purchaseTicketResponse),
 	}
 	w.purchaseTicketRequests <- req
@@ -1423,6 +1846,11 @@ type (
 		err        chan error
 	}
 
+	lockTimeoutRequest struct {
+		timeout time.Duration // Zero value prevents the timeout.
+		err     chan error
+	}
+
 	changePassphraseRequest struct {
 		old, new []byte
 		err      chan error
@@ -1439,6 +1867,16 @@ type (
 // walletLocker manages the locked/unlocked state of a wallet.
 func (w *Wallet) walletLocker() {
 	var timeout <-chan time.Time
+	var lockTime time.Time // Zero when there is no timeout.
+	setTimeout := func(d time.Duration) {
+		if d == 0 {
+			timeout = nil
+			lockTime = time.Time{}
+		} else {
+			timeout = time.After(d)
+			lockTime = time.Now().Add(d)
+		}
+	}
 	holdChan := make(HeldUnlock)
 	quit := w.quitChan()
 out:
@@ -1451,18 +1889,43 @@ out:
 				continue
 			}
 			w.notifyLockStateChange(false)
-			if req.timeout == 0 {
-				timeout = nil
-			} else {
-				timeout = time.After(req.timeout)
+			setTimeout(req.timeout)
+			req.err <- nil
+			continue
+
+		case req := <-w.lockTimeouts:
+			if w.Manager.IsLocked() {
+				req.err <- waddrmgr.ManagerError{
+					ErrorCode:   waddrmgr.ErrLocked,
+					Description: "address manager is locked",
+				}
+				continue
 			}
+			setTimeout(req.timeout)
 			req.err <- nil
 			continue
 
 		case req := <-w.changePassphrase:
 			err := w.Manager.ChangePassphrase(req.old, req.new, true,
 				&waddrmgr.DefaultScryptOptions)
-			req.err <- err
+			if err != nil || w.Manager.IsLocked() {
+				req.err <- err
+				continue
+			}
+
+			// The crypto keys were re-encrypted under the new
+			// passphrase, so relock the manager to remove any
+			// clear text keys cached by the previous unlock.  The
+			// wallet must be unlocked again using the new
+			// passphrase.
+			setTimeout(0)
+			err = w.Manager.Lock()
+			if err != nil && !waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+				log.Errorf("Could not lock wallet: %v", err)
+			} else {
+				w.notifyLockStateChange(true)
+			}
+			req.err <- nil
 			continue
 
 		case req := <-w.holdUnlockRequests:
@@ -1489,6 +1952,9 @@ out:
 		case w.lockState <- w.Manager.IsLocked():
 			continue
 
+		case w.lockInfo <- LockInfo{w.Manager.IsLocked(), lockTime}:
+			continue
+
 		case <-quit:
 			break out
 
@@ -1498,7 +1964,7 @@ out:
 
 		// Select statement fell through by an explicit lock or the
 		// timer expiring.  Lock the manager here.
-		timeout = nil
+		setTimeout(0)
 		err := w.Manager.Lock()
 		if err != nil && !waddrmgr.IsError(err, waddrmgr.ErrLocked) {
 			log.Errorf("Could not lock wallet: %v", err)
@@ -1536,6 +2002,33 @@ func (w *Wallet) Locked() bool {
 	return <-w.lockState
 }
 
+// LockInfo describes the lock state of a wallet.  LockTime is the time an
+// unlocked wallet will be locked again, or the zero time if the wallet is
+// locked or was unlocked without a timeout.
+type LockInfo struct {
+	Locked   bool
+	LockTime time.Time
+}
+
+// LockInfo returns whether the wallet is locked and when an unlocked wallet
+// will be locked again.
+func (w *Wallet) LockInfo() LockInfo {
+	return <-w.lockInfo
+}
+
+// SetUnlockTimeout replaces the timeout of an unlocked wallet, locking the
+// wallet after timeout has expired.  A zero timeout keeps the wallet unlocked
+// until it is explicitly locked.  An error is returned if the wallet is
+// locked.
+func (w *Wallet) SetUnlockTimeout(timeout time.Duration) error {
+	err := make(chan error, 1)
+	w.lockTimeouts <- lockTimeoutRequest{
+		timeout: timeout,
+		err:     err,
+	}
+	return <-err
+}
+
 // HoldUnlock prevents the wallet from being locked.  The HeldUnlock object
 // *must* be released, or the wallet will forever remain unlocked.
 //
@@ -1566,8 +2059,10 @@ func (c HeldUnlock) Release() {
 
 // ChangePassphrase attempts to change the passphrase for a wallet from old
 // to new.  Changing the passphrase is synchronized with all other address
-// manager locking and unlocking.  The lock state will be the same as it was
-// before the password change.
+// manager locking and unlocking.  The crypto keys are re-encrypted under the
+// new passphrase in a single database transaction, and if the wallet was
+// unlocked, it is locked afterwards so that no keys cached under the old
+// unlock remain in memory.
 func (w *Wallet) ChangePassphrase(old, new []byte) error {
 	err := make(chan error, 1)
 	w.changePassphrase <- changePassphraseRequest{
@@ -1654,6 +2149,144 @@ func (w *Wallet) CalculateAccountBalance(account uint32,
 	return bal, nil
 }
 
+// outputBalanceTypes returns whether an unspent output is included in the
+// spendable balance, and whether it is locked in a ticket.  Outputs of stake
+// transactions are only spendable after their maturity, and tickets are
+// never spendable.
+func (w *Wallet) outputBalanceTypes(output *wtxmgr.Credit, confirms,
+	syncHeight int32) (spendable, locked bool) {
+	if output.FromCoinBase {
+		target := int32(w.chainParams.CoinbaseMaturity)
+		if !confirmed(target, output.Height, syncHeight) {
+			return false, false
+		}
+	}
+
+	class := txscript.GetScriptClass(txscript.DefaultScriptVersion,
+		output.PkScript)
+	switch class {
+	case txscript.StakeSubmissionTy:
+		return false, output.Height != -1
+	case txscript.StakeSubChangeTy:
+		target := int32(w.chainParams.SStxChangeMaturity)
+		if !confirmed(target, output.Height, syncHeight) {
+			return false, false
+		}
+	case txscript.StakeGenTy, txscript.StakeRevocationTy:
+		target := int32(w.chainParams.CoinbaseMaturity)
+		if !confirmed(target, output.Height, syncHeight) {
+			return false, false
+		}
+	}
+	return confirmed(confirms, output.Height, syncHeight), false
+}
+
+// includeInBalance returns whether an unspent output is summed by a balance
+// of type balanceType.
+func (w *Wallet) includeInBalance(output *wtxmgr.Credit, confirms,
+	syncHeight int32, balanceType wtxmgr.BehaviorFlags) (bool, error) {
+	spendable, locked := w.outputBalanceTypes(output, confirms, syncHeight)
+	switch balanceType {
+	case wtxmgr.BFBalanceSpendable, wtxmgr.BFBalanceFullScan:
+		return spendable, nil
+	case wtxmgr.BFBalanceLockedStake:
+		return locked, nil
+	case wtxmgr.BFBalanceAll:
+		return confirmed(confirms, output.Height, syncHeight), nil
+	default:
+		return false, fmt.Errorf("unknown balance type flag")
+	}
+}
+
+// CalculateAccountBalances returns the balance of a type of each account
+// with unspent outputs, keyed by account number.  The spendable and full
+// scan balances both sum the spendable outputs of each account.  The locked
+// balance sums the mined tickets of each account, and the total balance sums
+// every unspent output with at least confirms confirmations.
+func (w *Wallet) CalculateAccountBalances(confirms int32,
+	balanceType wtxmgr.BehaviorFlags) (map[uint32]dcrutil.Amount, error) {
+
+	syncBlock := w.Manager.SyncedTo()
+
+	unspent, err := w.TxStore.UnspentOutputs()
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[uint32]dcrutil.Amount)
+	for _, output := range unspent {
+		include, err := w.includeInBalance(output, confirms,
+			syncBlock.Height, balanceType)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			txscript.DefaultScriptVersion, output.PkScript, w.chainParams)
+		if err != nil || len(addrs) == 0 {
+			continue
+		}
+		account, err := w.Manager.AddrAccount(addrs[0])
+		if err != nil {
+			continue
+		}
+		balances[account] += output.Amount
+	}
+	return balances, nil
+}
+
+// ImportedBalance splits a balance by whether its outputs can be recovered by
+// restoring the wallet from its seed.  Seed sums the outputs paying addresses
+// of the accounts derived from the seed.  Imported sums the outputs paying
+// imported keys and scripts, and outputs to scripts whose addresses are not
+// managed by the wallet, which are only recoverable from a backup of the
+// imported material.
+type ImportedBalance struct {
+	Seed     dcrutil.Amount
+	Imported dcrutil.Amount
+}
+
+// CalculateImportedBalance returns the balance of a type, as summed by
+// CalculateAccountBalances, split by whether the outputs can be recovered
+// from the wallet seed.
+func (w *Wallet) CalculateImportedBalance(confirms int32,
+	balanceType wtxmgr.BehaviorFlags) (*ImportedBalance, error) {
+
+	syncBlock := w.Manager.SyncedTo()
+
+	unspent, err := w.TxStore.UnspentOutputs()
+	if err != nil {
+		return nil, err
+	}
+	var bal ImportedBalance
+	for _, output := range unspent {
+		include, err := w.includeInBalance(output, confirms,
+			syncBlock.Height, balanceType)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+
+		seed := false
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			txscript.DefaultScriptVersion, output.PkScript, w.chainParams)
+		if err == nil && len(addrs) > 0 {
+			account, err := w.Manager.AddrAccount(addrs[0])
+			seed = err == nil && account != waddrmgr.ImportedAddrAccount
+		}
+		if seed {
+			bal.Seed += output.Amount
+		} else {
+			bal.Imported += output.Amount
+		}
+	}
+	return &bal, nil
+}
+
 // CurrentAddress gets the most recently requested payment address from a wallet.
 // If the address has already been used (there is at least one transaction
 // spending to it in the blockchain or dcrd mempool), the next chained address
@@ -1683,6 +2316,9 @@ func (w *Wallet) CurrentAddress(account uint32) (dcrutil.Address, error) {
 // existsAddressOnChain checks the chain on daemon to see if the given address
 // has been used before on the main chain.
 func (w *Wallet) existsAddressOnChain(address dcrutil.Address) (bool, error) {
+	if w.chainSvr == nil {
+		return false, ErrOffline
+	}
 	reply, err := w.chainSvr.ExistsAddress(address)
 	if err != nil {
 		return false, err
@@ -2057,9 +2693,95 @@ func (s creditSlice) Swap(i, j int) {
 func (w *Wallet) ListUnspent(minconf, maxconf int32,
 	addresses map[string]struct{}) ([]*dcrjson.ListUnspentResult, error) {
 
+	outputs, err := w.ListUnspentFiltered(&UnspentFilter{
+		MinConf:   minconf,
+		MaxConf:   maxconf,
+		Addresses: addresses,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*dcrjson.ListUnspentResult, len(outputs))
+	for i := range outputs {
+		results[i] = &outputs[i].ListUnspentResult
+	}
+	return results, nil
+}
+
+// UnspentFilter selects the outputs returned by ListUnspentFiltered.  Only
+// outputs with between MinConf and MaxConf confirmations are included.  If
+// Addresses is populated, only outputs paying to one of the encoded addresses
+// are included, and if Account is set, only outputs of the account.  Stake
+// outputs which are not yet spendable are only included when
+// IncludeImmatureStake is set.
+type UnspentFilter struct {
+	MinConf              int32
+	MaxConf              int32
+	Addresses            map[string]struct{}
+	Account              *uint32
+	IncludeImmatureStake bool
+}
+
+// UnspentOutput describes an unspent wallet output.  ScriptClass is the class
+// of the output script, which for stake outputs includes the stake opcode
+// tagging the script.  Outputs which are not Spendable include the Reason
+// they may not yet be spent.
+type UnspentOutput struct {
+	dcrjson.ListUnspentResult
+	ScriptClass txscript.ScriptClass
+	Spendable   bool
+	Reason      string
+}
+
+// immatureStakeReason returns the reason an output of a stake transaction
+// may not yet be spent, or the empty string if the output is spendable.
+func (w *Wallet) immatureStakeReason(details *wtxmgr.TxDetails, index uint32,
+	syncHeight int32) string {
+	switch details.TxRecord.TxType {
+	case stake.TxTypeSStx:
+		// Ticket commitment, only spendable after ticket maturity.
+		// You can only spent it after TM many blocks has gone past, so
+		// ticket maturity + 1??? Check this DECRED TODO
+		if index == 0 {
+			if !confirmed(int32(w.chainParams.TicketMaturity+1),
+				details.Height(), syncHeight) {
+				return "immature ticket"
+			}
+		}
+		// Change outputs.
+		if (index > 0) && (index%2 == 0) {
+			if !confirmed(int32(w.chainParams.SStxChangeMaturity),
+				details.Height(), syncHeight) {
+				return "immature ticket change"
+			}
+		}
+	case stake.TxTypeSSGen:
+		// All non-OP_RETURN outputs for SSGen tx are only spendable
+		// after coinbase maturity many blocks.
+		if !confirmed(int32(w.chainParams.CoinbaseMaturity),
+			details.Height(), syncHeight) {
+			return "immature vote"
+		}
+	case stake.TxTypeSSRtx:
+		// All outputs for SSRtx tx are only spendable
+		// after coinbase maturity many blocks.
+		if !confirmed(int32(w.chainParams.CoinbaseMaturity),
+			details.Height(), syncHeight) {
+			return "immature revocation"
+		}
+	}
+	return ""
+}
+
+// ListUnspentFiltered returns the unspent wallet outputs selected by a
+// filter, ordered by decreasing height.  Locked outputs and immature coinbase
+// outputs are never included.
+func (w *Wallet) ListUnspentFiltered(f *UnspentFilter) ([]UnspentOutput,
+	error) {
+
 	syncBlock := w.Manager.SyncedTo()
 
-	filter := len(addresses) != 0
+	filter := len(f.Addresses) != 0
 	unspent, err := w.TxStore.UnspentOutputs()
 	if err != nil {
 		return nil, err
@@ -2071,7 +2793,7 @@ func (w *Wallet) ListUnspent(minconf, maxconf int32,
 		return nil, err
 	}
 
-	results := make([]*dcrjson.ListUnspentResult, 0, len(unspent))
+	results := make([]UnspentOutput, 0, len(unspent))
 	for i := range unspent {
 		output := unspent[i]
 
@@ -2083,7 +2805,7 @@ func (w *Wallet) ListUnspent(minconf, maxconf int32,
 		// Outputs with fewer confirmations than the minimum or more
 		// confs than the maximum are excluded.
 		confs := confirms(output.Height, syncBlock.Height)
-		if confs < minconf || confs > maxconf {
+		if confs < f.MinConf || confs > f.MaxConf {
 			continue
 		}
 
@@ -2095,39 +2817,10 @@ func (w *Wallet) ListUnspent(minconf, maxconf int32,
 			}
 		}
 
-		switch details.TxRecord.TxType {
-		case stake.TxTypeSStx:
-			// Ticket commitment, only spendable after ticket maturity.
-			// You can only spent it after TM many blocks has gone past, so
-			// ticket maturity + 1??? Check this DECRED TODO
-			if output.Index == 0 {
-				if !confirmed(int32(w.chainParams.TicketMaturity+1),
-					details.Height(), syncBlock.Height) {
-					continue
-				}
-			}
-			// Change outputs.
-			if (output.Index > 0) && (output.Index%2 == 0) {
-				if !confirmed(int32(w.chainParams.SStxChangeMaturity),
-					details.Height(), syncBlock.Height) {
-					continue
-				}
-			}
-		case stake.TxTypeSSGen:
-			// All non-OP_RETURN outputs for SSGen tx are only spendable
-			// after coinbase maturity many blocks.
-			if !confirmed(int32(w.chainParams.CoinbaseMaturity),
-				details.Height(), syncBlock.Height) {
-				continue
-			}
-		case stake.TxTypeSSRtx:
-			// All outputs for SSRtx tx are only spendable
-			// after coinbase maturity many blocks.
-			if !confirmed(int32(w.chainParams.CoinbaseMaturity),
-				details.Height(), syncBlock.Height) {
-				continue
-			}
-
+		reason := w.immatureStakeReason(details, output.Index,
+			syncBlock.Height)
+		if reason != "" && !f.IncludeImmatureStake {
+			continue
 		}
 
 		// Exclude locked outputs from the result set.
@@ -2141,6 +2834,7 @@ func (w *Wallet) ListUnspent(minconf, maxconf int32,
 		//
 		// This will be unnecessary once transactions and outputs are
 		// grouped under the associated account in the db.
+		acct := uint32(waddrmgr.DefaultAccountNum)
 		acctName := defaultAccountName
 		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
 			txscript.DefaultScriptVersion, output.PkScript, w.chainParams)
@@ -2148,18 +2842,22 @@ func (w *Wallet) ListUnspent(minconf, maxconf int32,
 			continue
 		}
 		if len(addrs) > 0 {
-			acct, err := w.Manager.AddrAccount(addrs[0])
+			a, err := w.Manager.AddrAccount(addrs[0])
 			if err == nil {
+				acct = a
 				s, err := w.Manager.AccountName(acct)
 				if err == nil {
 					acctName = s
 				}
 			}
 		}
+		if f.Account != nil && acct != *f.Account {
+			continue
+		}
 
 		if filter {
 			for _, addr := range addrs {
-				_, ok := addresses[addr.EncodeAddress()]
+				_, ok := f.Addresses[addr.EncodeAddress()]
 				if ok {
 					goto include
 				}
@@ -2168,15 +2866,21 @@ func (w *Wallet) ListUnspent(minconf, maxconf int32,
 		}
 
 	include:
-		result := &dcrjson.ListUnspentResult{
-			TxID:          output.OutPoint.Hash.String(),
-			Vout:          output.OutPoint.Index,
-			Tree:          output.OutPoint.Tree,
-			Account:       acctName,
-			ScriptPubKey:  hex.EncodeToString(output.PkScript),
-			TxType:        int(details.TxType),
-			Amount:        output.Amount.ToCoin(),
-			Confirmations: int64(confs),
+		result := UnspentOutput{
+			ListUnspentResult: dcrjson.ListUnspentResult{
+				TxID:          output.OutPoint.Hash.String(),
+				Vout:          output.OutPoint.Index,
+				Tree:          output.OutPoint.Tree,
+				Account:       acctName,
+				ScriptPubKey:  hex.EncodeToString(output.PkScript),
+				TxType:        int(details.TxType),
+				Amount:        output.Amount.ToCoin(),
+				Confirmations: int64(confs),
+			},
+			ScriptClass: txscript.GetScriptClass(
+				txscript.DefaultScriptVersion, output.PkScript),
+			Spendable: reason == "",
+			Reason:    reason,
 		}
 
 		// BUG: this should be a JSON array so that all
@@ -2287,6 +2991,40 @@ func (w *Wallet) ImportPrivateKey(wif *dcrutil.WIF, bs *waddrmgr.BlockStamp,
 	return addrStr, nil
 }
 
+// ImportPublicKey imports a serialized public key to the wallet as a
+// watching-only P2PKH address of the imported account, returning the address.
+// Outputs paid to the address are spendable when the wallet signs with a
+// remote signer holding the private key, such as a hardware security module.
+func (w *Wallet) ImportPublicKey(serializedPubKey []byte,
+	bs *waddrmgr.BlockStamp, rescan bool) (dcrutil.Address, error) {
+
+	// The starting block for the key is the genesis block unless otherwise
+	// specified.
+	if bs == nil {
+		bs = &waddrmgr.BlockStamp{
+			Hash:   *w.chainParams.GenesisHash,
+			Height: 0,
+		}
+	}
+
+	addr, err := w.Manager.ImportPublicKey(serializedPubKey, bs)
+	if err != nil {
+		return nil, err
+	}
+
+	if rescan {
+		job := &RescanJob{
+			Addrs:      []dcrutil.Address{addr.Address()},
+			OutPoints:  nil,
+			BlockStamp: *bs,
+		}
+		_ = w.SubmitRescan(job)
+	}
+
+	log.Infof("Imported watching-only address %v", addr.Address())
+	return addr.Address(), nil
+}
+
 // exportBase64 exports a wallet's serialized database as a base64-encoded
 // string.
 func (w *Wallet) exportBase64() (string, error) {
@@ -2394,10 +3132,14 @@ func confirms(txHeight, curHeight int32) int32 {
 	}
 }
 
-// TotalReceivedForAccount iterates through a wallet's transaction history,
-// returning the total amount of decred received for a single wallet
-// account.
-func (w *Wallet) TotalReceivedForAccount(account uint32, minConf int32) (dcrutil.Amount, int32, error) {
+// totalReceived iterates through a wallet's transaction history, returning
+// the total amount of decred received by outputs whose addresses are matched
+// by match, and the confirmations of the last matching transaction.  Only
+// transactions with at least minConf confirmations are included.  Outputs of
+// stake transactions which are not yet mature are skipped unless
+// includeImmatureStake is set.
+func (w *Wallet) totalReceived(minConf int32, includeImmatureStake bool,
+	match func(addrs []dcrutil.Address) bool) (dcrutil.Amount, int32, error) {
 	syncBlock := w.Manager.SyncedTo()
 
 	var (
@@ -2408,6 +3150,10 @@ func (w *Wallet) TotalReceivedForAccount(account uint32, minConf int32) (dcrutil
 
 	if minConf > 0 {
 		stopHeight = syncBlock.Height - minConf + 1
+		if stopHeight < 0 {
+			// No block has enough confirmations.
+			return 0, 0, nil
+		}
 	} else {
 		stopHeight = -1
 	}
@@ -2415,15 +3161,20 @@ func (w *Wallet) TotalReceivedForAccount(account uint32, minConf int32) (dcrutil
 		for i := range details {
 			detail := &details[i]
 			for _, cred := range detail.Credits {
+				if !includeImmatureStake && w.immatureStakeReason(
+					detail, cred.Index, syncBlock.Height) != "" {
+					continue
+				}
 				pkVersion := detail.MsgTx.TxOut[cred.Index].Version
 				pkScript := detail.MsgTx.TxOut[cred.Index].PkScript
-				var outputAcct uint32
 				_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkVersion,
 					pkScript, w.chainParams)
-				if err == nil && len(addrs) > 0 {
-					outputAcct, err = w.Manager.AddrAccount(addrs[0])
+				// An error creating addresses from the output script only
+				// indicates a non-standard script, so ignore this credit.
+				if err != nil {
+					continue
 				}
-				if err == nil && outputAcct == account {
+				if match(addrs) {
 					amount += cred.Amount
 					lastConf = confirms(detail.Block.Height, syncBlock.Height)
 				}
@@ -2435,57 +3186,58 @@ func (w *Wallet) TotalReceivedForAccount(account uint32, minConf int32) (dcrutil
 	return amount, lastConf, err
 }
 
+// TotalReceivedForAccount iterates through a wallet's transaction history,
+// returning the total amount of decred received for a single wallet
+// account, and the confirmations of the last transaction received by it.
+func (w *Wallet) TotalReceivedForAccount(account uint32, minConf int32,
+	includeImmatureStake bool) (dcrutil.Amount, int32, error) {
+	return w.totalReceived(minConf, includeImmatureStake,
+		func(addrs []dcrutil.Address) bool {
+			if len(addrs) == 0 {
+				return false
+			}
+			outputAcct, err := w.Manager.AddrAccount(addrs[0])
+			return err == nil && outputAcct == account
+		})
+}
+
 // TotalReceivedForAddr iterates through a wallet's transaction history,
 // returning the total amount of decred received for a single wallet
 // address.
-func (w *Wallet) TotalReceivedForAddr(addr dcrutil.Address, minConf int32) (dcrutil.Amount, error) {
-	syncBlock := w.Manager.SyncedTo()
-
-	var (
-		addrStr    = addr.EncodeAddress()
-		amount     dcrutil.Amount
-		stopHeight int32
-	)
-
-	if minConf > 0 {
-		stopHeight = syncBlock.Height - minConf + 1
-	} else {
-		stopHeight = -1
-	}
-	err := w.TxStore.RangeTransactions(0, stopHeight, func(details []wtxmgr.TxDetails) (bool, error) {
-		for i := range details {
-			detail := &details[i]
-			for _, cred := range detail.Credits {
-				pkVersion := detail.MsgTx.TxOut[cred.Index].Version
-				pkScript := detail.MsgTx.TxOut[cred.Index].PkScript
-				_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkVersion,
-					pkScript, w.chainParams)
-				// An error creating addresses from the output script only
-				// indicates a non-standard script, so ignore this credit.
-				if err != nil {
-					continue
-				}
-				for _, a := range addrs {
-					if addrStr == a.EncodeAddress() {
-						amount += cred.Amount
-						break
-					}
+func (w *Wallet) TotalReceivedForAddr(addr dcrutil.Address, minConf int32,
+	includeImmatureStake bool) (dcrutil.Amount, error) {
+	addrStr := addr.EncodeAddress()
+	amount, _, err := w.totalReceived(minConf, includeImmatureStake,
+		func(addrs []dcrutil.Address) bool {
+			for _, a := range addrs {
+				if addrStr == a.EncodeAddress() {
+					return true
 				}
 			}
-		}
-		return false, nil
-	})
+			return false
+		})
 	return amount, err
 }
 
 // SendPairs creates and sends payment transactions. It returns the transaction
-// hash upon success
+// hash upon success.  The transaction is signed for origin.  When the send
+// requires approval, the transaction is queued unsigned and an
+// ApprovalRequiredError is returned.
 func (w *Wallet) SendPairs(amounts map[string]dcrutil.Amount, account uint32,
-	minconf int32) (*CreatedTx, error) {
+	minconf int32, origin string) (*CreatedTx, error) {
+
+	// Sends above the approval threshold are queued unsigned instead.
+	if p := w.approvalRequired(amounts); p != nil {
+		s, err := w.queuePendingSend(amounts, account, minconf, p, origin)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ApprovalRequiredError{s}
+	}
 
 	// Create transaction, replying with an error if the creation
 	// was not successful.
-	createdTx, err := w.CreateSimpleTx(account, amounts, minconf)
+	createdTx, err := w.CreateSimpleTx(account, amounts, minconf, origin)
 	if err != nil {
 		return nil, err
 	}
@@ -2495,17 +3247,16 @@ func (w *Wallet) SendPairs(amounts map[string]dcrutil.Amount, account uint32,
 	return createdTx, nil
 }
 
-// Open loads an already-created wallet from the passed database and namespaces.
+// Open loads an already-created wallet from the passed database and namespaces
+// with the settings of cfg.
 func Open(pubPass []byte, params *chaincfg.Params, db walletdb.DB, waddrmgrNS,
 	wtxmgrNS, wstmgrNS walletdb.Namespace, cbs *waddrmgr.OpenCallbacks,
-	voteBits uint16, stakeMiningEnabled bool, balanceToMaintain float64,
-	addressReuse bool, rollbackTest bool, pruneTickets bool, ticketAddress string,
-	ticketMaxPrice float64, autoRepair bool) (*Wallet, error) {
+	cfg *Config) (*Wallet, error) {
 	addrMgr, err := waddrmgr.Open(waddrmgrNS, pubPass, params, cbs)
 	if err != nil {
 		return nil, err
 	}
-	txMgr, err := wtxmgr.Open(wtxmgrNS, pruneTickets, params)
+	txMgr, err := wtxmgr.Open(wtxmgrNS, cfg.PruneTickets, params)
 	if err != nil {
 		if !wtxmgr.IsNoExists(err) {
 			return nil, err
@@ -2520,6 +3271,7 @@ func Open(pubPass []byte, params *chaincfg.Params, db walletdb.DB, waddrmgrNS,
 			return nil, err
 		}
 	}
+	txMgr.SetChangeSource(&addressChangeSource{addrMgr, params})
 
 	smgr, err := wstakemgr.Open(wstmgrNS, addrMgr, params)
 	if err != nil {
@@ -2531,40 +3283,100 @@ func Open(pubPass []byte, params *chaincfg.Params, db walletdb.DB, waddrmgrNS,
 	}
 
 	// XXX Should we check error here?  Right now error gives default (0).
-	btm, err := dcrutil.NewAmount(balanceToMaintain)
+	btm, err := dcrutil.NewAmount(cfg.BalanceToMaintain)
 	if err != nil {
 		return nil, err
 	}
 
 	var ticketAddr dcrutil.Address
-	if ticketAddress != "" {
-		ticketAddr, err = dcrutil.DecodeAddress(ticketAddress, params)
+	if cfg.TicketAddress != "" {
+		ticketAddr, err = dcrutil.DecodeAddress(cfg.TicketAddress, params)
 		if err != nil {
 			return nil, fmt.Errorf("ticket address could not parse: %v",
 				err.Error())
 		}
 	}
 
-	tmp, err := dcrutil.NewAmount(ticketMaxPrice)
+	var rewardAddr dcrutil.Address
+	if cfg.RewardAddress != "" {
+		rewardAddr, err = dcrutil.DecodeAddress(cfg.RewardAddress, params)
+		if err != nil {
+			return nil, fmt.Errorf("reward address could not parse: %v",
+				err.Error())
+		}
+		if _, ok := rewardAddr.(*dcrutil.AddressPubKeyHash); !ok {
+			return nil, fmt.Errorf("reward address %v is not a pubkey "+
+				"hash address", rewardAddr)
+		}
+	}
+
+	tmp, err := dcrutil.NewAmount(cfg.TicketMaxPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	mf, err := dcrutil.NewAmount(cfg.MaxFee)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxFeePercent < 0 {
+		return nil, fmt.Errorf("maximum fee percentage may not be negative")
+	}
+
+	tmfr, err := dcrutil.NewAmount(cfg.TicketMaxFeeRate)
 	if err != nil {
 		return nil, err
 	}
 
+	var poolAddr dcrutil.Address
+	if cfg.StakePoolEnabled {
+		if cfg.PoolAddress == "" {
+			return nil, fmt.Errorf("stake pool mode requires a pool address")
+		}
+		poolAddr, err = dcrutil.DecodeAddress(cfg.PoolAddress, params)
+		if err != nil {
+			return nil, fmt.Errorf("pool address could not parse: %v",
+				err.Error())
+		}
+		if cfg.PoolFees < 0 || cfg.PoolFees > 100 {
+			return nil, fmt.Errorf("pool fees must be a percentage " +
+				"between 0 and 100")
+		}
+	}
+
 	log.Infof("Opened wallet") // TODO: log balance? last sync height?
 
-	w := newWallet(voteBits,
-		stakeMiningEnabled,
-		btm,
-		addressReuse,
-		rollbackTest,
-		ticketAddr,
-		tmp,
-		autoRepair,
-		addrMgr,
-		txMgr,
-		smgr,
-		&db,
-		params)
+	wcfg := &walletConfig{
+		voteBits:            cfg.VoteBits,
+		stakeMiningEnabled:  cfg.StakeMiningEnabled,
+		balanceToMaintain:   btm,
+		addressReuse:        cfg.AddressReuse,
+		rollbackTest:        cfg.RollbackTest,
+		autoRepair:          cfg.AutoRepair,
+		ticketAddress:       ticketAddr,
+		rewardAddress:       rewardAddr,
+		ticketMaxPrice:      tmp,
+		maxFee:              mf,
+		maxFeePercent:       cfg.MaxFeePercent,
+		maxTicketsPerBlock:  cfg.MaxTicketsPerBlock,
+		ticketMaxFeeRate:    tmfr,
+		maxTicketsPerWindow: cfg.MaxTicketsPerWindow,
+		autoRevoke:          cfg.AutoRevoke,
+		stakePoolEnabled:    cfg.StakePoolEnabled,
+		poolAddress:         poolAddr,
+		poolFees:            cfg.PoolFees,
+		votingOnly:          cfg.VotingOnly,
+	}
+	w := newWallet(wcfg, addrMgr, txMgr, smgr, &db, params)
+
+	// Tickets owned before ticket statuses were tracked are recorded as
+	// unmined until their mined height is found in the transaction store.
+	if err := w.resolveTicketStatuses(); err != nil {
+		log.Errorf("Failed to resolve ticket statuses: %v", err)
+	}
+	if err := w.loadTicketWindow(); err != nil {
+		log.Errorf("Failed to load ticket purchase window: %v", err)
+	}
 
 	return w, nil
 }
@@ -20,6 +20,7 @@ package bdb
 import (
 	"io"
 	"os"
+	"time"
 
 	"github.com/btcsuite/bolt"
 	"github.com/decred/dcrwallet/walletdb"
@@ -33,6 +34,10 @@ func convertErr(err error) error {
 		return walletdb.ErrDbNotOpen
 	case bolt.ErrInvalid:
 		return walletdb.ErrInvalid
+	case bolt.ErrDatabaseReadOnly:
+		return walletdb.ErrDbReadOnly
+	case bolt.ErrTimeout:
+		return walletdb.ErrDbAlreadyOpen
 
 	// Transaction errors.
 	case bolt.ErrTxNotWritable:
@@ -354,8 +359,12 @@ func (ns *namespace) Update(fn func(walletdb.Tx) error) error {
 // transactions which are obtained through the specific Namespace.
 type db bolt.DB
 
-// Enforce db implements the walletdb.Db interface.
-var _ walletdb.DB = (*db)(nil)
+// Enforce db implements the walletdb.Db and walletdb.NamespacesViewer
+// interfaces.
+var (
+	_ walletdb.DB               = (*db)(nil)
+	_ walletdb.NamespacesViewer = (*db)(nil)
+)
 
 // Namespace returns a Namespace interface for the provided key.  See the
 // Namespace interface documentation for more details.  Attempting to access a
@@ -379,6 +388,11 @@ func (db *db) Namespace(key []byte) (walletdb.Namespace, error) {
 		return nil, convertErr(err)
 	}
 
+	// Namespaces can not be created in read-only databases.
+	if doCreate && (*bolt.DB)(db).IsReadOnly() {
+		return nil, walletdb.ErrBucketNotFound
+	}
+
 	// Create the namespace if needed by using an writable update
 	// transaction.
 	if doCreate {
@@ -404,6 +418,27 @@ func (db *db) DeleteNamespace(key []byte) error {
 	}))
 }
 
+// ViewNamespaces invokes the passed function with a managed read-only
+// transaction of each namespace of keys, all viewing the same state of the
+// database.  ErrBucketNotFound is returned if any of the namespaces does not
+// exist.
+//
+// This function is part of the walletdb.NamespacesViewer interface
+// implementation.
+func (db *db) ViewNamespaces(keys [][]byte, fn func([]walletdb.Tx) error) error {
+	return convertErr((*bolt.DB)(db).View(func(boltTx *bolt.Tx) error {
+		txs := make([]walletdb.Tx, len(keys))
+		for i, key := range keys {
+			bucket := boltTx.Bucket(key)
+			if bucket == nil {
+				return walletdb.ErrBucketNotFound
+			}
+			txs[i] = &transaction{boltTx: boltTx, rootBucket: bucket}
+		}
+		return fn(txs)
+	}))
+}
+
 // Copy writes a copy of the database to the provided writer.  This call will
 // start a read-only transaction to perform all operations.
 //
@@ -441,3 +476,21 @@ func openDB(dbPath string, create bool) (walletdb.DB, error) {
 	boltDB, err := bolt.Open(dbPath, 0600, nil)
 	return (*db)(boltDB), convertErr(err)
 }
+
+// readOnlyLockTimeout is the time opening a database read-only waits for the
+// file lock when another process has the database open for writing.
+const readOnlyLockTimeout = time.Second
+
+// openReadOnlyDB opens the database at the provided path without permitting
+// any modifications.  Multiple processes may open the database read-only at
+// the same time, but not while it is open for writing.
+// walletdb.ErrDbAlreadyOpen is returned if the database is open for writing.
+func openReadOnlyDB(dbPath string) (walletdb.DB, error) {
+	if !fileExists(dbPath) {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+
+	opts := &bolt.Options{ReadOnly: true, Timeout: readOnlyLockTimeout}
+	boltDB, err := bolt.Open(dbPath, 0600, opts)
+	return (*db)(boltDB), convertErr(err)
+}
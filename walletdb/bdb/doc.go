@@ -34,5 +34,10 @@ database path as a string:
 	if err != nil {
 		// Handle error
 	}
+
+The database may also be opened with walletdb.OpenReadOnly.  Any number of
+processes may open the database read-only at the same time, but not while
+another process has it open for writing, in which case ErrDbAlreadyOpen is
+returned.
 */
 package bdb
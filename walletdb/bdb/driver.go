@@ -54,6 +54,17 @@ func openDBDriver(args ...interface{}) (walletdb.DB, error) {
 	return openDB(dbPath, false)
 }
 
+// openReadOnlyDBDriver is the callback provided during driver registration
+// that opens an existing database without permitting any modifications.
+func openReadOnlyDBDriver(args ...interface{}) (walletdb.DB, error) {
+	dbPath, err := parseArgs("OpenReadOnly", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return openReadOnlyDB(dbPath)
+}
+
 // createDBDriver is the callback provided during driver registration that
 // creates, initializes, and opens a database for use.
 func createDBDriver(args ...interface{}) (walletdb.DB, error) {
@@ -68,9 +79,10 @@ func createDBDriver(args ...interface{}) (walletdb.DB, error) {
 func init() {
 	// Register the driver.
 	driver := walletdb.Driver{
-		DbType: dbType,
-		Create: createDBDriver,
-		Open:   openDBDriver,
+		DbType:       dbType,
+		Create:       createDBDriver,
+		Open:         openDBDriver,
+		OpenReadOnly: openReadOnlyDBDriver,
 	}
 	if err := walletdb.RegisterDriver(driver); err != nil {
 		panic(fmt.Sprintf("Failed to register database driver '%s': %v",
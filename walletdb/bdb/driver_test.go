@@ -185,6 +185,101 @@ func TestPersistence(t *testing.T) {
 	}
 }
 
+// TestReadOnly ensures that a database opened read-only can be read but not
+// modified.
+func TestReadOnly(t *testing.T) {
+	// Create a new database with a namespace and value to read back after
+	// opening it read-only.
+	dbPath := "readonlytest.db"
+	db, err := walletdb.Create(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	nsKey := []byte("ns")
+	key, value := []byte("key"), []byte("value")
+	ns, err := db.Namespace(nsKey)
+	if err != nil {
+		t.Errorf("Namespace: unexpected error: %v", err)
+		return
+	}
+	err = ns.Update(func(tx walletdb.Tx) error {
+		return tx.RootBucket().Put(key, value)
+	})
+	if err != nil {
+		t.Errorf("Update: unexpected error: %v", err)
+		return
+	}
+	// Close the database since it may not be opened read-only while it is
+	// open for writing.
+	db.Close()
+
+	// Ensure that attempting to open a database that doesn't exist returns
+	// the expected error.
+	wantErr := walletdb.ErrDbDoesNotExist
+	if _, err := walletdb.OpenReadOnly(dbType, "noexist"); err != wantErr {
+		t.Errorf("OpenReadOnly: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	roDB, err := walletdb.OpenReadOnly(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to open test database read-only (%s) %v",
+			dbType, err)
+		return
+	}
+	defer roDB.Close()
+
+	// Ensure the value can be read.
+	ns, err = roDB.Namespace(nsKey)
+	if err != nil {
+		t.Errorf("Namespace: unexpected error: %v", err)
+		return
+	}
+	err = ns.View(func(tx walletdb.Tx) error {
+		gotVal := tx.RootBucket().Get(key)
+		if !reflect.DeepEqual(gotVal, value) {
+			return fmt.Errorf("Get: key '%s' does not match "+
+				"expected value - got %s, want %s", key, gotVal,
+				value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("View: unexpected error: %v", err)
+		return
+	}
+
+	// Ensure the database can not be modified.
+	wantErr = walletdb.ErrDbReadOnly
+	if _, err := ns.Begin(true); err != wantErr {
+		t.Errorf("Begin: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+	}
+	err = ns.Update(func(tx walletdb.Tx) error {
+		return tx.RootBucket().Put(key, value)
+	})
+	if err != wantErr {
+		t.Errorf("Update: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+	}
+	if err := roDB.DeleteNamespace(nsKey); err != wantErr {
+		t.Errorf("DeleteNamespace: did not receive expected error - "+
+			"got %v, want %v", err, wantErr)
+	}
+
+	// Ensure namespaces are not created on first access.
+	wantErr = walletdb.ErrBucketNotFound
+	if _, err := roDB.Namespace([]byte("noexist")); err != wantErr {
+		t.Errorf("Namespace: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+	}
+}
+
 // TestInterface performs all interfaces tests for this database driver.
 func TestInterface(t *testing.T) {
 	// Create a new database to run tests against.
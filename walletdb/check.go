@@ -0,0 +1,165 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package walletdb
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// RecordValidator checks the record stored under key k.  The path holds the
+// keys of the nested buckets leading from the namespace's root bucket to the
+// bucket containing the record, and is empty for records of the root bucket.
+// A nil v indicates that k is the key of a nested bucket.  A non-nil error
+// marks the record, and for buckets all of its contents, as invalid.
+type RecordValidator func(path [][]byte, k, v []byte) error
+
+// Problem describes an invalid or unreadable record found while checking a
+// namespace.  Key is nil when an entire bucket could not be read.
+type Problem struct {
+	Path [][]byte
+	Key  []byte
+	Err  error
+}
+
+// String returns the location of the problem record followed by the reason it
+// is invalid.
+func (p Problem) String() string {
+	var buf bytes.Buffer
+	for _, k := range p.Path {
+		buf.WriteString(formatKey(k))
+		buf.WriteByte('/')
+	}
+	if p.Key != nil {
+		buf.WriteString(formatKey(p.Key))
+	}
+	return fmt.Sprintf("%s: %v", buf.String(), p.Err)
+}
+
+// formatKey returns k as a string when it is printable ASCII, such as most
+// bucket names, or hex encoded otherwise.
+func formatKey(k []byte) string {
+	for _, c := range k {
+		if c < 0x20 || c > 0x7e {
+			return fmt.Sprintf("%x", k)
+		}
+	}
+	return string(k)
+}
+
+// CheckNamespace walks every bucket of the namespace and returns a problem for
+// each record that is rejected by validate or could not be read.  A nil
+// validate only checks that all buckets can be traversed.  Panics raised by
+// the database or the validator while reading a damaged bucket are recovered
+// and reported as problems of that bucket, so a partially corrupted database
+// can be checked in full.  The returned error is only non-nil when the
+// namespace could not be read at all.
+func CheckNamespace(ns Namespace, validate RecordValidator) ([]Problem, error) {
+	c := checker{validate: validate}
+	err := ns.View(func(tx Tx) error {
+		return c.walk(nil, tx.RootBucket(), nil)
+	})
+	return c.problems, err
+}
+
+// SalvageNamespace copies every record of the src namespace that passes
+// CheckNamespace into the dst namespace and returns the problems of the
+// records that were skipped.  Nested buckets are only created in dst when the
+// bucket itself is valid.  This allows the readable contents of a partially
+// corrupted database to be recovered into a new database.
+func SalvageNamespace(dst, src Namespace, validate RecordValidator) ([]Problem, error) {
+	c := checker{validate: validate}
+	err := src.View(func(srcTx Tx) error {
+		return dst.Update(func(dstTx Tx) error {
+			return c.walk(dstTx.RootBucket(), srcTx.RootBucket(), nil)
+		})
+	})
+	return c.problems, err
+}
+
+// checker records the problems found while walking a namespace.
+type checker struct {
+	validate RecordValidator
+	problems []Problem
+}
+
+// report records a problem for the key k in the bucket at path.  Both are
+// copied since they are only valid during the transaction.
+func (c *checker) report(path [][]byte, k []byte, err error) {
+	p := Problem{Path: make([][]byte, len(path)), Err: err}
+	for i := range path {
+		p.Path[i] = append([]byte{}, path[i]...)
+	}
+	if k != nil {
+		p.Key = append([]byte{}, k...)
+	}
+	c.problems = append(c.problems, p)
+}
+
+// check calls the validator for a single record, converting any panic into an
+// error.
+func (c *checker) check(path [][]byte, k, v []byte) (err error) {
+	if c.validate == nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed record: %v", r)
+		}
+	}()
+	return c.validate(path, k, v)
+}
+
+// walk recursively checks the contents of the src bucket at path, copying all
+// valid records to dst when it is non-nil.  Errors writing to dst are
+// returned, while unreadable or invalid records of src are only reported.
+func (c *checker) walk(dst, src Bucket, path [][]byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.report(path, nil, fmt.Errorf("unreadable bucket: %v", r))
+		}
+	}()
+
+	return src.ForEach(func(k, v []byte) error {
+		if err := c.check(path, k, v); err != nil {
+			c.report(path, k, err)
+			return nil
+		}
+
+		// Nested buckets are reported with a nil value.
+		if v != nil {
+			if dst == nil {
+				return nil
+			}
+			return dst.Put(k, v)
+		}
+		child := src.Bucket(k)
+		if child == nil {
+			c.report(path, k, ErrBucketNotFound)
+			return nil
+		}
+		var dstChild Bucket
+		if dst != nil {
+			dstChild, err = dst.CreateBucketIfNotExists(k)
+			if err != nil {
+				return err
+			}
+		}
+		childPath := append(path[:len(path):len(path)], k)
+		return c.walk(dstChild, child, childPath)
+	})
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package walletdb
+
+// CopyNamespace copies every key/value pair and nested bucket of the src
+// namespace into the dst namespace, overwriting any existing values of the
+// same keys.  The copy is performed within a single read-only transaction of
+// src and a single read-write transaction of dst, so either the entire
+// namespace is copied or dst is left unmodified.
+//
+// Since only the live data is copied, copying all namespaces of a database
+// into a newly created database results in a database without any of the free
+// space left behind by deleted records.
+func CopyNamespace(dst, src Namespace) error {
+	return src.View(func(srcTx Tx) error {
+		return dst.Update(func(dstTx Tx) error {
+			return copyBucket(dstTx.RootBucket(), srcTx.RootBucket())
+		})
+	})
+}
+
+// CopyNamespaces copies every namespace of src with a key of keys into the
+// namespace of dst with the same key, as CopyNamespace does.  All namespaces
+// are copied from a single read-only transaction of src, so the copies are
+// consistent with each other even while src is being modified.  Each
+// namespace is written to dst in its own read-write transaction.
+func CopyNamespaces(dst, src DB, keys [][]byte) error {
+	return ViewNamespaces(src, keys, func(srcTxs []Tx) error {
+		for i, key := range keys {
+			dstNS, err := dst.Namespace(key)
+			if err != nil {
+				return err
+			}
+			err = dstNS.Update(func(dstTx Tx) error {
+				return copyBucket(dstTx.RootBucket(),
+					srcTxs[i].RootBucket())
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// copyBucket recursively copies the contents of the src bucket into dst.
+func copyBucket(dst, src Bucket) error {
+	return src.ForEach(func(k, v []byte) error {
+		// Nested buckets are reported with a nil value.
+		if v == nil {
+			dstChild, err := dst.CreateBucketIfNotExists(k)
+			if err != nil {
+				return err
+			}
+			return copyBucket(dstChild, src.Bucket(k))
+		}
+		return dst.Put(k, v)
+	})
+}
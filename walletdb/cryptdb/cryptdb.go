@@ -0,0 +1,827 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package cryptdb
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/decred/dcrwallet/snacl"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// NamespaceKey is the key of the namespace holding the parameters of the
+// master key and the encrypted crypto key of an encrypted database.  The
+// values of this namespace are stored unencrypted and it can not be accessed
+// through an encrypted database.
+var NamespaceKey = []byte("cryptdb")
+
+// Keys of the values in the namespace of the encryption parameters.
+var (
+	masterKeyParamsKey = []byte("masterkeyparams")
+	cryptoKeyKey       = []byte("cryptokey")
+	versionKey         = []byte("version")
+)
+
+// version is the version of the format of encrypted keys and values.
+// Databases without a version stored their keys in plaintext and are not
+// supported.
+const version = 2
+
+// maxKeySize is the maximum size of a key.  Keys are stored hashed, so the
+// limit of the underlying database does not apply and is enforced here.
+const maxKeySize = 32768
+
+// Tags of the records stored under hashed keys.  A value record holds a key
+// and its value, and a bucket record holds the key of a nested bucket, which
+// is itself stored under a hash of its key.
+const (
+	valueTag      byte = 0
+	bucketTag     byte = 1
+	bucketNameTag byte = 2
+)
+
+// Errors that can occur when creating or opening an encrypted database.
+var (
+	// ErrEncrypted is returned when creating an encrypted database from a
+	// database which is already encrypted.
+	ErrEncrypted = errors.New("database is already encrypted")
+
+	// ErrNotEncrypted is returned when opening a database as encrypted
+	// which was not created encrypted.
+	ErrNotEncrypted = errors.New("database is not encrypted")
+
+	// ErrInvalidPassphrase is returned when opening an encrypted database
+	// with the wrong passphrase.
+	ErrInvalidPassphrase = errors.New("invalid passphrase")
+
+	// ErrUnsupportedVersion is returned when opening an encrypted database
+	// whose keys are stored in a format that is not supported.
+	ErrUnsupportedVersion = errors.New("database was encrypted with an " +
+		"unsupported format")
+
+	// ErrReservedNamespace is returned when accessing or deleting the
+	// namespace of the encryption parameters through an encrypted database.
+	ErrReservedNamespace = errors.New("namespace is reserved for the " +
+		"encryption parameters")
+)
+
+// IsEncrypted returns whether db was created as an encrypted database.
+func IsEncrypted(db walletdb.DB) (bool, error) {
+	ns, err := db.Namespace(NamespaceKey)
+	if err == walletdb.ErrBucketNotFound {
+		// Databases opened read-only do not create the namespace.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var encrypted bool
+	err = ns.View(func(tx walletdb.Tx) error {
+		encrypted = tx.RootBucket().Get(masterKeyParamsKey) != nil
+		return nil
+	})
+	return encrypted, err
+}
+
+// Create enables encryption of db, which must not yet contain any namespaces
+// other than the namespace of the encryption parameters.  A random crypto key
+// which encrypts every key and value is generated and stored encrypted by a
+// master key derived from passphrase.  The returned database encrypts and
+// decrypts the keys and values of every namespace and must be used instead of
+// db for all further access.
+func Create(db walletdb.DB, passphrase []byte) (walletdb.DB, error) {
+	encrypted, err := IsEncrypted(db)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		return nil, ErrEncrypted
+	}
+
+	masterKey, err := snacl.NewSecretKey(&passphrase, snacl.DefaultN,
+		snacl.DefaultR, snacl.DefaultP)
+	if err != nil {
+		return nil, err
+	}
+	defer masterKey.Zero()
+	cryptoKey, err := snacl.GenerateCryptoKey()
+	if err != nil {
+		return nil, err
+	}
+	cryptoKeyEnc, err := masterKey.Encrypt(cryptoKey[:])
+	if err != nil {
+		cryptoKey.Zero()
+		return nil, err
+	}
+
+	ns, err := db.Namespace(NamespaceKey)
+	if err != nil {
+		cryptoKey.Zero()
+		return nil, err
+	}
+	err = ns.Update(func(tx walletdb.Tx) error {
+		root := tx.RootBucket()
+		err := root.Put(masterKeyParamsKey, masterKey.Marshal())
+		if err != nil {
+			return err
+		}
+		err = root.Put(cryptoKeyKey, cryptoKeyEnc)
+		if err != nil {
+			return err
+		}
+		var v [4]byte
+		binary.BigEndian.PutUint32(v[:], version)
+		return root.Put(versionKey, v[:])
+	})
+	if err != nil {
+		cryptoKey.Zero()
+		return nil, err
+	}
+
+	return &encryptedDB{db: db, keys: newKeys(cryptoKey)}, nil
+}
+
+// Open returns the encrypted database db after deriving its master key from
+// passphrase.  ErrNotEncrypted is returned if db was not created encrypted and
+// ErrInvalidPassphrase is returned if passphrase is not the passphrase the
+// database was created with.  The returned database must be used instead of db
+// for all further access.
+func Open(db walletdb.DB, passphrase []byte) (walletdb.DB, error) {
+	ns, err := db.Namespace(NamespaceKey)
+	if err == walletdb.ErrBucketNotFound {
+		// Databases opened read-only do not create the namespace.
+		return nil, ErrNotEncrypted
+	}
+	if err != nil {
+		return nil, err
+	}
+	var params, cryptoKeyEnc, ver []byte
+	err = ns.View(func(tx walletdb.Tx) error {
+		root := tx.RootBucket()
+		params = copyBytes(root.Get(masterKeyParamsKey))
+		cryptoKeyEnc = copyBytes(root.Get(cryptoKeyKey))
+		ver = copyBytes(root.Get(versionKey))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return nil, ErrNotEncrypted
+	}
+	if len(ver) != 4 || binary.BigEndian.Uint32(ver) != version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	var masterKey snacl.SecretKey
+	if err := masterKey.Unmarshal(params); err != nil {
+		return nil, err
+	}
+	defer masterKey.Zero()
+	if err := masterKey.DeriveKey(&passphrase); err != nil {
+		if err == snacl.ErrInvalidPassword {
+			return nil, ErrInvalidPassphrase
+		}
+		return nil, err
+	}
+
+	decrypted, err := masterKey.Decrypt(cryptoKeyEnc)
+	if err != nil {
+		return nil, err
+	}
+	if len(decrypted) != snacl.KeySize {
+		return nil, snacl.ErrMalformed
+	}
+	cryptoKey := new(snacl.CryptoKey)
+	copy(cryptoKey[:], decrypted)
+	zeroBytes(decrypted)
+
+	return &encryptedDB{db: db, keys: newKeys(cryptoKey)}, nil
+}
+
+// copyBytes returns a copy of b, or nil if b is nil.
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// zeroBytes sets every byte of b to zero.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// cryptoKeys holds the crypto key which encrypts the records of an encrypted
+// database and the key of the HMAC which hashes their keys.
+type cryptoKeys struct {
+	crypto *snacl.CryptoKey
+	mac    [sha256.Size]byte
+}
+
+// newKeys returns the keys of an encrypted database with the crypto key
+// cryptoKey.  The HMAC key is derived from the crypto key.
+func newKeys(cryptoKey *snacl.CryptoKey) *cryptoKeys {
+	keys := &cryptoKeys{crypto: cryptoKey}
+	mac := hmac.New(sha256.New, cryptoKey[:])
+	mac.Write([]byte("cryptdb key hashing"))
+	copy(keys.mac[:], mac.Sum(nil))
+	return keys
+}
+
+// zero sets both keys to zero.
+func (k *cryptoKeys) zero() {
+	k.crypto.Zero()
+	zeroBytes(k.mac[:])
+}
+
+// hashKey returns the key a record with tag for key is stored under.
+func (k *cryptoKeys) hashKey(tag byte, key []byte) []byte {
+	mac := hmac.New(sha256.New, k.mac[:])
+	mac.Write([]byte{tag})
+	mac.Write(key)
+	return mac.Sum(nil)
+}
+
+// encryptRecord encrypts a record with tag holding key and value.
+func (k *cryptoKeys) encryptRecord(tag byte, key, value []byte) ([]byte, error) {
+	record := make([]byte, 5+len(key)+len(value))
+	record[0] = tag
+	binary.BigEndian.PutUint32(record[1:5], uint32(len(key)))
+	copy(record[5:], key)
+	copy(record[5+len(key):], value)
+	ciphertext, err := k.crypto.Encrypt(record)
+	zeroBytes(record)
+	return ciphertext, err
+}
+
+// decryptRecord decrypts the stored record v and returns its tag, key and
+// value.  The value is returned as a non-nil slice, which may be empty, so it
+// is not mistaken for a nested bucket.
+func (k *cryptoKeys) decryptRecord(v []byte) (tag byte, key, value []byte, err error) {
+	record, err := k.crypto.Decrypt(v)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if len(record) < 5 {
+		return 0, nil, nil, snacl.ErrMalformed
+	}
+	keyLen := binary.BigEndian.Uint32(record[1:5])
+	if uint64(keyLen) > uint64(len(record)-5) {
+		return 0, nil, nil, snacl.ErrMalformed
+	}
+	key = record[5 : 5+keyLen]
+	value = record[5+keyLen:]
+	if value == nil {
+		value = []byte{}
+	}
+	return record[0], key, value, nil
+}
+
+// encryptedDB wraps a walletdb.DB and encrypts and decrypts the keys and
+// values of every namespace.  It implements the walletdb.DB interface.
+type encryptedDB struct {
+	db   walletdb.DB
+	keys *cryptoKeys
+}
+
+// Enforce encryptedDB implements the walletdb.DB and walletdb.NamespacesViewer
+// interfaces.
+var (
+	_ walletdb.DB               = (*encryptedDB)(nil)
+	_ walletdb.NamespacesViewer = (*encryptedDB)(nil)
+)
+
+// Namespace returns a Namespace interface for the provided key whose keys and
+// values are encrypted.  ErrReservedNamespace is returned for the namespace of
+// the encryption parameters.
+//
+// This function is part of the walletdb.DB interface implementation.
+func (d *encryptedDB) Namespace(key []byte) (walletdb.Namespace, error) {
+	if bytes.Equal(key, NamespaceKey) {
+		return nil, ErrReservedNamespace
+	}
+	ns, err := d.db.Namespace(key)
+	if err != nil {
+		return nil, err
+	}
+	return &namespace{ns: ns, keys: d.keys}, nil
+}
+
+// DeleteNamespace deletes the namespace for the passed key.
+// ErrReservedNamespace is returned for the namespace of the encryption
+// parameters.
+//
+// This function is part of the walletdb.DB interface implementation.
+func (d *encryptedDB) DeleteNamespace(key []byte) error {
+	if bytes.Equal(key, NamespaceKey) {
+		return ErrReservedNamespace
+	}
+	return d.db.DeleteNamespace(key)
+}
+
+// ViewNamespaces invokes the passed function with a managed read-only
+// transaction of each namespace of keys whose keys and values are encrypted,
+// all viewing the same state of the database.  ErrReservedNamespace is
+// returned if keys includes the namespace of the encryption parameters.
+//
+// This function is part of the walletdb.NamespacesViewer interface
+// implementation.
+func (d *encryptedDB) ViewNamespaces(keys [][]byte, fn func([]walletdb.Tx) error) error {
+	for _, key := range keys {
+		if bytes.Equal(key, NamespaceKey) {
+			return ErrReservedNamespace
+		}
+	}
+	return walletdb.ViewNamespaces(d.db, keys, func(txs []walletdb.Tx) error {
+		encTxs := make([]walletdb.Tx, len(txs))
+		for i, tx := range txs {
+			encTxs[i] = &transaction{tx: tx, keys: d.keys}
+		}
+		return fn(encTxs)
+	})
+}
+
+// Copy writes a copy of the underlying database to the provided writer.  The
+// keys and values of the copy remain encrypted and the copy includes the
+// encryption parameters, so it may be opened with the same passphrase.
+//
+// This function is part of the walletdb.DB interface implementation.
+func (d *encryptedDB) Copy(w io.Writer) error {
+	return d.db.Copy(w)
+}
+
+// Close closes the underlying database and zeros the crypto keys.
+//
+// This function is part of the walletdb.DB interface implementation.
+func (d *encryptedDB) Close() error {
+	err := d.db.Close()
+	if err == nil {
+		d.keys.zero()
+	}
+	return err
+}
+
+// namespace wraps a walletdb.Namespace and implements the walletdb.Namespace
+// interface.
+type namespace struct {
+	ns   walletdb.Namespace
+	keys *cryptoKeys
+}
+
+// Enforce namespace implements the walletdb.Namespace interface.
+var _ walletdb.Namespace = (*namespace)(nil)
+
+// Begin starts a transaction which is either read-only or read-write depending
+// on the specified flag.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) Begin(writable bool) (walletdb.Tx, error) {
+	tx, err := ns.ns.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction{tx: tx, keys: ns.keys}, nil
+}
+
+// View invokes the passed function in the context of a managed read-only
+// transaction.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) View(fn func(walletdb.Tx) error) error {
+	return ns.ns.View(func(tx walletdb.Tx) error {
+		return fn(&transaction{tx: tx, keys: ns.keys})
+	})
+}
+
+// Update invokes the passed function in the context of a managed read-write
+// transaction.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) Update(fn func(walletdb.Tx) error) error {
+	return ns.ns.Update(func(tx walletdb.Tx) error {
+		return fn(&transaction{tx: tx, keys: ns.keys})
+	})
+}
+
+// transaction wraps a walletdb.Tx and implements the walletdb.Tx interface.
+type transaction struct {
+	tx   walletdb.Tx
+	keys *cryptoKeys
+}
+
+// Enforce transaction implements the walletdb.Tx interface.
+var _ walletdb.Tx = (*transaction)(nil)
+
+// RootBucket returns the top-most bucket for the namespace the transaction was
+// created from.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) RootBucket() walletdb.Bucket {
+	return &bucket{b: tx.tx.RootBucket(), keys: tx.keys}
+}
+
+// Commit commits all changes that have been made through the root bucket and
+// all of its sub-buckets to persistent storage.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Commit() error {
+	return tx.tx.Commit()
+}
+
+// Rollback undoes all changes that have been made to the root bucket and all of
+// its sub-buckets.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Rollback() error {
+	return tx.tx.Rollback()
+}
+
+// bucket wraps a walletdb.Bucket and implements the walletdb.Bucket interface.
+// Each value is stored as an encrypted record holding its key and value, under
+// an HMAC of the key, so neither keys nor values are readable without the
+// crypto key.  Each nested bucket is stored under an HMAC of its key, along
+// with an encrypted record of the key so the bucket can be iterated.
+//
+// The hashed keys do not preserve the ordering of the keys, so iterating a
+// bucket decrypts and sorts all of its records.
+type bucket struct {
+	b    walletdb.Bucket
+	keys *cryptoKeys
+}
+
+// Enforce bucket implements the walletdb.Bucket and walletdb.CheckedBucket
+// interfaces.
+var (
+	_ walletdb.Bucket        = (*bucket)(nil)
+	_ walletdb.CheckedBucket = (*bucket)(nil)
+)
+
+// Bucket retrieves a nested bucket with the given key.  Returns nil if the
+// bucket does not exist.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Bucket(key []byte) walletdb.Bucket {
+	child := b.b.Bucket(b.keys.hashKey(bucketTag, key))
+	if child == nil {
+		return nil
+	}
+	return &bucket{b: child, keys: b.keys}
+}
+
+// checkCreateBucket returns the error of creating a nested bucket with the
+// given key, other than the bucket already existing.
+func (b *bucket) checkCreateBucket(key []byte) error {
+	if !b.b.Writable() {
+		return walletdb.ErrTxNotWritable
+	}
+	if len(key) == 0 {
+		return walletdb.ErrBucketNameRequired
+	}
+	if len(key) > maxKeySize {
+		return walletdb.ErrKeyTooLarge
+	}
+	if b.b.Get(b.keys.hashKey(valueTag, key)) != nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	return nil
+}
+
+// putBucketName stores the encrypted record of the key of a nested bucket.
+func (b *bucket) putBucketName(key []byte) error {
+	record, err := b.keys.encryptRecord(bucketNameTag, key, nil)
+	if err != nil {
+		return err
+	}
+	return b.b.Put(b.keys.hashKey(bucketNameTag, key), record)
+}
+
+// CreateBucket creates and returns a new nested bucket with the given key.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucket(key []byte) (walletdb.Bucket, error) {
+	if err := b.checkCreateBucket(key); err != nil {
+		return nil, err
+	}
+	child, err := b.b.CreateBucket(b.keys.hashKey(bucketTag, key))
+	if err != nil {
+		return nil, err
+	}
+	if err := b.putBucketName(key); err != nil {
+		return nil, err
+	}
+	return &bucket{b: child, keys: b.keys}, nil
+}
+
+// CreateBucketIfNotExists creates and returns a new nested bucket with the
+// given key if it does not already exist.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucketIfNotExists(key []byte) (walletdb.Bucket, error) {
+	if err := b.checkCreateBucket(key); err != nil {
+		return nil, err
+	}
+	if child := b.Bucket(key); child != nil {
+		return child, nil
+	}
+	return b.CreateBucket(key)
+}
+
+// DeleteBucket removes a nested bucket with the given key.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) DeleteBucket(key []byte) error {
+	if !b.b.Writable() {
+		return walletdb.ErrTxNotWritable
+	}
+	if len(key) == 0 || b.b.Get(b.keys.hashKey(valueTag, key)) != nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	err := b.b.DeleteBucket(b.keys.hashKey(bucketTag, key))
+	if err != nil {
+		return err
+	}
+	return b.b.Delete(b.keys.hashKey(bucketNameTag, key))
+}
+
+// ForEach invokes the passed function with every decrypted key/value pair in
+// the bucket in key order.  The error of decrypting a record is returned.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) ForEach(fn func(k, v []byte) error) error {
+	pairs, err := b.sortedPairs()
+	if err != nil {
+		return err
+	}
+	for _, p := range pairs {
+		if err := fn(p.k, p.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writable returns whether or not the bucket is writable.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Writable() bool {
+	return b.b.Writable()
+}
+
+// Put encrypts the key and value and saves them to the bucket.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Put(key, value []byte) error {
+	if !b.b.Writable() {
+		return walletdb.ErrTxNotWritable
+	}
+	if len(key) == 0 {
+		return walletdb.ErrKeyRequired
+	}
+	if len(key) > maxKeySize {
+		return walletdb.ErrKeyTooLarge
+	}
+	if b.b.Bucket(b.keys.hashKey(bucketTag, key)) != nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	record, err := b.keys.encryptRecord(valueTag, key, value)
+	if err != nil {
+		return err
+	}
+	return b.b.Put(b.keys.hashKey(valueTag, key), record)
+}
+
+// Get returns the decrypted value for the given key.  Returns nil if the key
+// does not exist in this bucket, or if the value can not be decrypted.  Use
+// GetChecked to tell the two apart.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Get(key []byte) []byte {
+	value, _ := b.GetChecked(key)
+	return value
+}
+
+// GetChecked returns the decrypted value for the given key, or nil if the key
+// does not exist in this bucket.  The error of decrypting the value is
+// returned, and snacl.ErrMalformed if the record stored for the key does not
+// hold a value of the key.
+//
+// This function is part of the walletdb.CheckedBucket interface
+// implementation.
+func (b *bucket) GetChecked(key []byte) ([]byte, error) {
+	v := b.b.Get(b.keys.hashKey(valueTag, key))
+	if v == nil {
+		return nil, nil
+	}
+	tag, k, value, err := b.keys.decryptRecord(v)
+	if err != nil {
+		return nil, err
+	}
+	if tag != valueTag || !bytes.Equal(k, key) {
+		return nil, snacl.ErrMalformed
+	}
+	return value, nil
+}
+
+// Delete removes the specified key from the bucket.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Delete(key []byte) error {
+	if !b.b.Writable() {
+		return walletdb.ErrTxNotWritable
+	}
+	if b.b.Bucket(b.keys.hashKey(bucketTag, key)) != nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	return b.b.Delete(b.keys.hashKey(valueTag, key))
+}
+
+// Cursor returns a new cursor, allowing for iteration over the bucket's
+// decrypted key/value pairs and nested buckets in forward or backward order.
+// The cursor iterates the pairs as of its creation.  Creating a cursor
+// decrypts and sorts every record of the bucket, so its cost grows with the
+// size of the bucket, while positioning it is cheap.  Callers should reuse a
+// cursor rather than create one per lookup.  If any record can not be
+// decrypted, the cursor fails: it iterates no pairs and its Err method returns
+// the error.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Cursor() walletdb.Cursor {
+	pairs, err := b.sortedPairs()
+	if err != nil {
+		return &cursor{bucket: b, pos: -1, err: err}
+	}
+	return &cursor{bucket: b, pairs: pairs, pos: -1}
+}
+
+// pair is a decrypted key/value pair of a bucket.  The value of a nested
+// bucket is nil.
+type pair struct {
+	k, v []byte
+}
+
+// pairsByKey sorts pairs by key.
+type pairsByKey []pair
+
+func (p pairsByKey) Len() int           { return len(p) }
+func (p pairsByKey) Less(i, j int) bool { return bytes.Compare(p[i].k, p[j].k) < 0 }
+func (p pairsByKey) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// sortedPairs decrypts the records of the bucket and returns the key/value
+// pairs and nested buckets they describe, sorted by key.  Decryption stops at
+// the first record which can not be decrypted, and the pairs decrypted before
+// it are returned along with the error.
+func (b *bucket) sortedPairs() ([]pair, error) {
+	var pairs []pair
+	err := b.b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			// Nested buckets are iterated by their key records.
+			return nil
+		}
+		tag, key, value, err := b.keys.decryptRecord(v)
+		if err != nil {
+			return err
+		}
+		switch tag {
+		case valueTag:
+			pairs = append(pairs, pair{key, value})
+		case bucketNameTag:
+			pairs = append(pairs, pair{key, nil})
+		default:
+			return snacl.ErrMalformed
+		}
+		return nil
+	})
+	sort.Sort(pairsByKey(pairs))
+	return pairs, err
+}
+
+// cursor implements the walletdb.Cursor interface over the sorted pairs of a
+// bucket.  A cursor which failed to decrypt the records of its bucket holds
+// no pairs and the error.
+type cursor struct {
+	bucket *bucket
+	pairs  []pair
+	pos    int
+	err    error
+}
+
+// Enforce cursor implements the walletdb.Cursor and walletdb.CheckedCursor
+// interfaces.
+var (
+	_ walletdb.Cursor        = (*cursor)(nil)
+	_ walletdb.CheckedCursor = (*cursor)(nil)
+)
+
+// current returns the pair at the position of the cursor, or nils if the
+// cursor is positioned past either end of the pairs.
+func (c *cursor) current() (key, value []byte) {
+	if c.pos < 0 || c.pos >= len(c.pairs) {
+		return nil, nil
+	}
+	p := &c.pairs[c.pos]
+	return p.k, p.v
+}
+
+// Bucket returns the bucket the cursor was created for.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Bucket() walletdb.Bucket {
+	return c.bucket
+}
+
+// Delete removes the current key/value pair the cursor is at without
+// invalidating the cursor.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Delete() error {
+	if c.err != nil {
+		return c.err
+	}
+	k, v := c.current()
+	if k == nil {
+		return nil
+	}
+	if v == nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	return c.bucket.Delete(k)
+}
+
+// Err returns the error of decrypting the records of the bucket, or nil if
+// the cursor did not fail.
+//
+// This function is part of the walletdb.CheckedCursor interface
+// implementation.
+func (c *cursor) Err() error {
+	return c.err
+}
+
+// First positions the cursor at the first key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) First() (key, value []byte) {
+	c.pos = 0
+	return c.current()
+}
+
+// Last positions the cursor at the last key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Last() (key, value []byte) {
+	c.pos = len(c.pairs) - 1
+	return c.current()
+}
+
+// Next moves the cursor one key/value pair forward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Next() (key, value []byte) {
+	if c.pos < len(c.pairs) {
+		c.pos++
+	}
+	return c.current()
+}
+
+// Prev moves the cursor one key/value pair backward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Prev() (key, value []byte) {
+	if c.pos >= 0 {
+		c.pos--
+	}
+	return c.current()
+}
+
+// Seek positions the cursor at the passed seek key.  If the key does not exist,
+// the cursor is moved to the next key after seek.  Returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Seek(seek []byte) (key, value []byte) {
+	c.pos = sort.Search(len(c.pairs), func(i int) bool {
+		return bytes.Compare(c.pairs[i].k, seek) >= 0
+	})
+	return c.current()
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package cryptdb_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/decred/dcrwallet/walletdb"
+	"github.com/decred/dcrwallet/walletdb/cryptdb"
+	_ "github.com/decred/dcrwallet/walletdb/memdb"
+)
+
+// TestCreateOpen ensures that encrypted databases can be created and reopened
+// with the correct passphrase, and that their values are not stored in
+// plaintext.
+func TestCreateOpen(t *testing.T) {
+	const dbName = "cryptdbtest"
+	passphrase := []byte("public")
+	nsKey := []byte("ns")
+	key, value := []byte("key"), []byte("value")
+
+	db, err := walletdb.Create("memdb", dbName)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	// Ensure opening a database which was not created encrypted returns
+	// the expected error.
+	if _, err := cryptdb.Open(db, passphrase); err != cryptdb.ErrNotEncrypted {
+		t.Fatalf("Open: unexpected error - got %v, want %v", err,
+			cryptdb.ErrNotEncrypted)
+	}
+
+	edb, err := cryptdb.Create(db, passphrase)
+	if err != nil {
+		t.Fatalf("Create: unexpected error: %v", err)
+	}
+	ns, err := edb.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	err = ns.Update(func(tx walletdb.Tx) error {
+		return tx.RootBucket().Put(key, value)
+	})
+	if err != nil {
+		t.Fatalf("Put: unexpected error: %v", err)
+	}
+
+	// Ensure the namespace of the encryption parameters can not be
+	// accessed through the encrypted database.
+	if _, err := edb.Namespace(cryptdb.NamespaceKey); err != cryptdb.ErrReservedNamespace {
+		t.Errorf("Namespace: unexpected error - got %v, want %v", err,
+			cryptdb.ErrReservedNamespace)
+	}
+	if err := edb.Close(); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+
+	db, err = walletdb.Open("memdb", dbName)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// Ensure the database is reported as encrypted and can not be
+	// encrypted again.
+	encrypted, err := cryptdb.IsEncrypted(db)
+	if err != nil {
+		t.Fatalf("IsEncrypted: unexpected error: %v", err)
+	}
+	if !encrypted {
+		t.Errorf("IsEncrypted: database is not reported as encrypted")
+	}
+	if _, err := cryptdb.Create(db, passphrase); err != cryptdb.ErrEncrypted {
+		t.Errorf("Create: unexpected error - got %v, want %v", err,
+			cryptdb.ErrEncrypted)
+	}
+
+	// Ensure neither the key nor the value are stored in plaintext.
+	ns, err = db.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	err = ns.View(func(tx walletdb.Tx) error {
+		if stored := tx.RootBucket().Get(key); stored != nil {
+			t.Errorf("Key is stored unencrypted")
+		}
+		return tx.RootBucket().ForEach(func(k, v []byte) error {
+			if bytes.Contains(k, key) || bytes.Contains(v, key) {
+				t.Errorf("Key is stored unencrypted: %x", k)
+			}
+			if bytes.Contains(v, value) {
+				t.Errorf("Value is stored unencrypted: %x", v)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+
+	// Ensure opening the database with the wrong passphrase fails.
+	_, err = cryptdb.Open(db, []byte("wrong"))
+	if err != cryptdb.ErrInvalidPassphrase {
+		t.Errorf("Open: unexpected error - got %v, want %v", err,
+			cryptdb.ErrInvalidPassphrase)
+	}
+
+	// Ensure the value is decrypted after opening the database with the
+	// correct passphrase.
+	edb, err = cryptdb.Open(db, passphrase)
+	if err != nil {
+		t.Fatalf("Open: unexpected error: %v", err)
+	}
+	ns, err = edb.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	err = ns.View(func(tx walletdb.Tx) error {
+		if got := tx.RootBucket().Get(key); !bytes.Equal(got, value) {
+			t.Errorf("Get: unexpected value - got %q, want %q", got,
+				value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+}
+
+// TestInterface performs all interfaces tests against an encrypted database.
+func TestInterface(t *testing.T) {
+	db, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	edb, err := cryptdb.Create(db, []byte("public"))
+	if err != nil {
+		db.Close()
+		t.Fatalf("Failed to encrypt test database: %v", err)
+	}
+	defer edb.Close()
+
+	// Run all of the interface tests against the database.
+	testInterface(t, edb)
+}
+
+// TestCorruptRecord ensures that a record which can not be decrypted is
+// reported by GetChecked and fails cursors, rather than being mistaken for a
+// missing key or silently skipped.
+func TestCorruptRecord(t *testing.T) {
+	nsKey := []byte("ns")
+	key, value := []byte("key"), []byte("value")
+
+	db, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	edb, err := cryptdb.Create(db, []byte("public"))
+	if err != nil {
+		db.Close()
+		t.Fatalf("Failed to encrypt test database: %v", err)
+	}
+	defer edb.Close()
+
+	ns, err := edb.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	err = ns.Update(func(tx walletdb.Tx) error {
+		return tx.RootBucket().Put(key, value)
+	})
+	if err != nil {
+		t.Fatalf("Put: unexpected error: %v", err)
+	}
+
+	// Overwrite the encrypted record through the underlying database.
+	rawNS, err := db.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	err = rawNS.Update(func(tx walletdb.Tx) error {
+		var hashedKeys [][]byte
+		err := tx.RootBucket().ForEach(func(k, v []byte) error {
+			hashedKeys = append(hashedKeys, append([]byte{}, k...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range hashedKeys {
+			err := tx.RootBucket().Put(k, []byte("corrupt record"))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update: unexpected error: %v", err)
+	}
+
+	err = ns.View(func(tx walletdb.Tx) error {
+		bucket := tx.RootBucket()
+		if got, err := walletdb.GetChecked(bucket, key); got != nil || err == nil {
+			t.Errorf("GetChecked: got %q and error %v for a corrupt "+
+				"record, want an error", got, err)
+		}
+		got, err := walletdb.GetChecked(bucket, []byte("missing"))
+		if got != nil || err != nil {
+			t.Errorf("GetChecked: got %q and error %v for a missing "+
+				"key, want neither", got, err)
+		}
+		if got := bucket.Get(key); got != nil {
+			t.Errorf("Get: got %q for a corrupt record, want nil", got)
+		}
+
+		c := bucket.Cursor()
+		if k, v := c.First(); k != nil || v != nil {
+			t.Errorf("First: got %q=%q from a failed cursor", k, v)
+		}
+		if k, v := c.Next(); k != nil || v != nil {
+			t.Errorf("Next: got %q=%q from a failed cursor", k, v)
+		}
+		if walletdb.CursorErr(c) == nil {
+			t.Errorf("CursorErr: failed cursor reports no error")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+}
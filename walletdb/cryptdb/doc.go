@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+/*
+Package cryptdb encrypts the keys and values stored in a walletdb database so
+that someone copying the database file can not read transaction history,
+addresses, or balances without the passphrase.
+
+A random crypto key encrypts every key and value of every namespace.  The
+crypto key is itself stored encrypted by a master key derived from a passphrase
+with scrypt, using the same key scheme as the waddrmgr package.  Each key/value
+pair is stored as an encrypted record under an HMAC of its key, so keys such as
+the transaction hashes of the wtxmgr namespace are not readable either.  The
+names of namespaces, the number and sizes of records, and the nesting of
+buckets are not hidden.
+
+Hashed keys do not preserve the ordering of keys, so iterating a bucket with
+ForEach or a cursor decrypts and sorts all of its records.
+
+Usage
+
+Encryption is enabled for a newly created database with Create, and an
+encrypted database is opened with Open.  Both return a walletdb.DB which
+encrypts and decrypts values transparently and must be used in place of the
+underlying database:
+
+	db, err := walletdb.Create("bdb", "path/to/database.db")
+	if err != nil {
+		// Handle error
+	}
+	edb, err := cryptdb.Create(db, passphrase)
+	if err != nil {
+		// Handle error
+	}
+
+	db, err := walletdb.Open("bdb", "path/to/database.db")
+	if err != nil {
+		// Handle error
+	}
+	edb, err := cryptdb.Open(db, passphrase)
+	if err != nil {
+		// Handle error
+	}
+*/
+package cryptdb
@@ -18,12 +18,15 @@
 package walletdb_test
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/decred/dcrwallet/walletdb"
 	_ "github.com/decred/dcrwallet/walletdb/bdb"
+	_ "github.com/decred/dcrwallet/walletdb/memdb"
 )
 
 var (
@@ -117,6 +120,15 @@ func TestCreateOpenFail(t *testing.T) {
 			openError)
 		return
 	}
+
+	// Ensure opening a database read-only with the new type, which does not
+	// support read-only access, fails with the expected error.
+	_, err = walletdb.OpenReadOnly(dbType)
+	if err != walletdb.ErrReadOnlyUnsupported {
+		t.Errorf("expected error not received - got: %v, want %v", err,
+			walletdb.ErrReadOnlyUnsupported)
+		return
+	}
 }
 
 // TestCreateOpenUnsupported ensures that attempting to create or open an
@@ -140,4 +152,270 @@ func TestCreateOpenUnsupported(t *testing.T) {
 			walletdb.ErrDbUnknownType)
 		return
 	}
+
+	// Ensure opening a database read-only with an unsupported type fails
+	// with the expected error.
+	_, err = walletdb.OpenReadOnly(dbType)
+	if err != walletdb.ErrDbUnknownType {
+		t.Errorf("expected error not received - got: %v, want %v", err,
+			walletdb.ErrDbUnknownType)
+		return
+	}
+}
+
+// populateTestNamespace stores key/value pairs and nested buckets in ns which
+// are verified by checkTestNamespace.
+func populateTestNamespace(ns walletdb.Namespace) error {
+	return ns.Update(func(tx walletdb.Tx) error {
+		root := tx.RootBucket()
+		if err := root.Put([]byte("key1"), []byte("foo1")); err != nil {
+			return err
+		}
+		if err := root.Put([]byte("empty"), []byte{}); err != nil {
+			return err
+		}
+		nested, err := root.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+		if err := nested.Put([]byte("key2"), []byte("foo2")); err != nil {
+			return err
+		}
+		nested2, err := nested.CreateBucket([]byte("nested2"))
+		if err != nil {
+			return err
+		}
+		return nested2.Put([]byte("key3"), []byte("foo3"))
+	})
+}
+
+// checkTestNamespace ensures ns contains the key/value pairs and nested buckets
+// stored by populateTestNamespace.
+func checkTestNamespace(ns walletdb.Namespace) error {
+	return ns.View(func(tx walletdb.Tx) error {
+		root := tx.RootBucket()
+		if v := root.Get([]byte("key1")); string(v) != "foo1" {
+			return fmt.Errorf("key1: got %q, want %q", v, "foo1")
+		}
+		if v := root.Get([]byte("empty")); v == nil || len(v) != 0 {
+			return fmt.Errorf("empty: got %q, want empty value", v)
+		}
+		nested := root.Bucket([]byte("nested"))
+		if nested == nil {
+			return fmt.Errorf("nested bucket does not exist")
+		}
+		if v := nested.Get([]byte("key2")); string(v) != "foo2" {
+			return fmt.Errorf("key2: got %q, want %q", v, "foo2")
+		}
+		nested2 := nested.Bucket([]byte("nested2"))
+		if nested2 == nil {
+			return fmt.Errorf("nested2 bucket does not exist")
+		}
+		if v := nested2.Get([]byte("key3")); string(v) != "foo3" {
+			return fmt.Errorf("key3: got %q, want %q", v, "foo3")
+		}
+		return nil
+	})
+}
+
+// TestCopyNamespace ensures copying a namespace copies all nested buckets and
+// key/value pairs into the destination namespace.
+func TestCopyNamespace(t *testing.T) {
+	srcDB, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatalf("Failed to create source database: %v", err)
+	}
+	defer srcDB.Close()
+	dstDB, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatalf("Failed to create destination database: %v", err)
+	}
+	defer dstDB.Close()
+
+	nsKey := []byte("ns")
+	srcNS, err := srcDB.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	if err := populateTestNamespace(srcNS); err != nil {
+		t.Fatalf("Failed to populate source namespace: %v", err)
+	}
+
+	dstNS, err := dstDB.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	if err := walletdb.CopyNamespace(dstNS, srcNS); err != nil {
+		t.Fatalf("CopyNamespace: unexpected error: %v", err)
+	}
+	if err := checkTestNamespace(dstNS); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestExportImportNamespace ensures a namespace export can be imported into a
+// namespace of another database and that invalid exports are rejected without
+// modifying the namespace.
+func TestExportImportNamespace(t *testing.T) {
+	srcDB, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatalf("Failed to create source database: %v", err)
+	}
+	defer srcDB.Close()
+	dstDB, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatalf("Failed to create destination database: %v", err)
+	}
+	defer dstDB.Close()
+
+	srcNS, err := srcDB.Namespace([]byte("src"))
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	if err := populateTestNamespace(srcNS); err != nil {
+		t.Fatalf("Failed to populate source namespace: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := walletdb.ExportNamespace(&buf, srcNS); err != nil {
+		t.Fatalf("ExportNamespace: unexpected error: %v", err)
+	}
+	export := buf.Bytes()
+
+	// Ensure truncated exports, exports with trailing data, and data that
+	// is not an export are rejected and leave the namespace empty.
+	dstNS, err := dstDB.Namespace([]byte("dst"))
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	invalid := [][]byte{
+		export[:len(export)-1],
+		append(append([]byte{}, export...), 0),
+		[]byte("not an export"),
+		nil,
+	}
+	for i, data := range invalid {
+		err := walletdb.ImportNamespace(dstNS, bytes.NewReader(data))
+		if err != walletdb.ErrInvalidExport {
+			t.Errorf("ImportNamespace #%d: unexpected error - got %v, "+
+				"want %v", i, err, walletdb.ErrInvalidExport)
+		}
+	}
+	err = dstNS.View(func(tx walletdb.Tx) error {
+		return tx.RootBucket().ForEach(func(k, v []byte) error {
+			return fmt.Errorf("unexpected key %q after invalid import", k)
+		})
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Ensure the valid export is imported.
+	err = walletdb.ImportNamespace(dstNS, bytes.NewReader(export))
+	if err != nil {
+		t.Fatalf("ImportNamespace: unexpected error: %v", err)
+	}
+	if err := checkTestNamespace(dstNS); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestCheckSalvageNamespace ensures records rejected by a validator, including
+// validators that panic, are reported by CheckNamespace and skipped by
+// SalvageNamespace while all other records are salvaged.
+func TestCheckSalvageNamespace(t *testing.T) {
+	srcDB, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatalf("Failed to create source database: %v", err)
+	}
+	defer srcDB.Close()
+	dstDB, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatalf("Failed to create destination database: %v", err)
+	}
+	defer dstDB.Close()
+
+	nsKey := []byte("ns")
+	srcNS, err := srcDB.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	if err := populateTestNamespace(srcNS); err != nil {
+		t.Fatalf("Failed to populate source namespace: %v", err)
+	}
+	err = srcNS.Update(func(tx walletdb.Tx) error {
+		root := tx.RootBucket()
+		if err := root.Put([]byte("bad"), []byte("x")); err != nil {
+			return err
+		}
+		return root.Bucket([]byte("nested")).Put([]byte("panic"), []byte("y"))
+	})
+	if err != nil {
+		t.Fatalf("Failed to store invalid records: %v", err)
+	}
+
+	validate := func(path [][]byte, k, v []byte) error {
+		switch string(k) {
+		case "bad":
+			return fmt.Errorf("bad record")
+		case "panic":
+			_ = v[len(v)]
+		}
+		return nil
+	}
+
+	// No problems must be reported without a validator.
+	problems, err := walletdb.CheckNamespace(srcNS, nil)
+	if err != nil {
+		t.Fatalf("CheckNamespace: unexpected error: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("CheckNamespace: unexpected problems %v", problems)
+	}
+
+	wantProblems := []string{"bad", "nested/panic"}
+	checkProblems := func(name string, problems []walletdb.Problem) {
+		if len(problems) != len(wantProblems) {
+			t.Fatalf("%s: got %d problems, want %d", name,
+				len(problems), len(wantProblems))
+		}
+		for i, p := range problems {
+			s := p.String()
+			if !strings.HasPrefix(s, wantProblems[i]+": ") {
+				t.Errorf("%s: problem %d is %q, want prefix %q", name,
+					i, s, wantProblems[i])
+			}
+		}
+	}
+	problems, err = walletdb.CheckNamespace(srcNS, validate)
+	if err != nil {
+		t.Fatalf("CheckNamespace: unexpected error: %v", err)
+	}
+	checkProblems("CheckNamespace", problems)
+
+	dstNS, err := dstDB.Namespace(nsKey)
+	if err != nil {
+		t.Fatalf("Namespace: unexpected error: %v", err)
+	}
+	problems, err = walletdb.SalvageNamespace(dstNS, srcNS, validate)
+	if err != nil {
+		t.Fatalf("SalvageNamespace: unexpected error: %v", err)
+	}
+	checkProblems("SalvageNamespace", problems)
+	if err := checkTestNamespace(dstNS); err != nil {
+		t.Error(err)
+	}
+	err = dstNS.View(func(tx walletdb.Tx) error {
+		root := tx.RootBucket()
+		if root.Get([]byte("bad")) != nil {
+			return fmt.Errorf("invalid record was salvaged")
+		}
+		if root.Bucket([]byte("nested")).Get([]byte("panic")) != nil {
+			return fmt.Errorf("invalid nested record was salvaged")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
 }
@@ -51,6 +51,22 @@ var (
 
 	// ErrInvalid is returned if the specified database is not valid.
 	ErrInvalid = errors.New("invalid database")
+
+	// ErrDbReadOnly is returned when attempting to modify a database that
+	// was opened read-only, such as by beginning a read-write transaction
+	// or creating or deleting a namespace.
+	ErrDbReadOnly = errors.New("database is read-only")
+
+	// ErrReadOnlyUnsupported is returned when attempting to open a database
+	// read-only with a driver which does not support read-only access.
+	ErrReadOnlyUnsupported = errors.New("database type does not support " +
+		"read-only access")
+
+	// ErrViewNamespacesUnsupported is returned when attempting to view
+	// several namespaces in a single transaction of a database which does
+	// not implement NamespacesViewer.
+	ErrViewNamespacesUnsupported = errors.New("database does not " +
+		"support viewing several namespaces in one transaction")
 )
 
 // Errors that can occur when beginning or committing a transaction.
@@ -90,3 +106,10 @@ var (
 	// delete a non-bucket key on an existing bucket key.
 	ErrIncompatibleValue = errors.New("incompatible value")
 )
+
+// Errors that can occur when importing a namespace.
+var (
+	// ErrInvalidExport is returned when importing data that was not
+	// written by ExportNamespace or that ends unexpectedly.
+	ErrInvalidExport = errors.New("invalid namespace export")
+)
@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package walletdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Namespaces are exported as a stream beginning with exportMagic and the
+// export format version, followed by the records of the root bucket.  Each
+// record begins with its type.  A value record is followed by the
+// uvarint-length-prefixed key and value.  A bucket record is followed by the
+// uvarint-length-prefixed key of a nested bucket, whose records follow until
+// the end record of the nested bucket.  The end record of the root bucket ends
+// the export.
+const (
+	recordValue byte = iota
+	recordBucket
+	recordEnd
+)
+
+// exportVersion is the version of the namespace export format.
+const exportVersion = 1
+
+// exportMagic begins every namespace export.
+var exportMagic = []byte("wdbns")
+
+// ExportNamespace writes every key/value pair and nested bucket of the
+// namespace to w.  The export is performed within a single read-only
+// transaction and may be imported into a namespace of any database with
+// ImportNamespace.
+func ExportNamespace(w io.Writer, ns Namespace) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(exportMagic); err != nil {
+		return err
+	}
+	if err := bw.WriteByte(exportVersion); err != nil {
+		return err
+	}
+
+	err := ns.View(func(tx Tx) error {
+		return exportBucket(bw, tx.RootBucket())
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// exportBucket recursively writes the records of the bucket b to w.
+func exportBucket(w *bufio.Writer, b Bucket) error {
+	err := b.ForEach(func(k, v []byte) error {
+		// Nested buckets are reported with a nil value.
+		if v == nil {
+			if err := writeRecord(w, recordBucket, k); err != nil {
+				return err
+			}
+			return exportBucket(w, b.Bucket(k))
+		}
+		return writeRecord(w, recordValue, k, v)
+	})
+	if err != nil {
+		return err
+	}
+	return w.WriteByte(recordEnd)
+}
+
+// writeRecord writes the record type typ followed by each of the fields
+// prefixed with their length.
+func writeRecord(w *bufio.Writer, typ byte, fields ...[]byte) error {
+	if err := w.WriteByte(typ); err != nil {
+		return err
+	}
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, field := range fields {
+		n := binary.PutUvarint(lenBuf[:], uint64(len(field)))
+		if _, err := w.Write(lenBuf[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportNamespace reads a namespace export written by ExportNamespace from r
+// and stores its key/value pairs and nested buckets in ns, overwriting any
+// existing values of the same keys.  The import is performed within a single
+// read-write transaction, so ns is left unmodified when the export can not be
+// read.  ErrInvalidExport is returned if r does not contain a complete
+// namespace export.
+func ImportNamespace(ns Namespace, r io.Reader) error {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(exportMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return importError(err)
+	}
+	if !bytes.Equal(header[:len(exportMagic)], exportMagic) ||
+		header[len(exportMagic)] != exportVersion {
+		return ErrInvalidExport
+	}
+
+	return ns.Update(func(tx Tx) error {
+		if err := importBucket(br, tx.RootBucket()); err != nil {
+			return err
+		}
+
+		// The export must end with the end record of the root bucket.
+		if _, err := br.ReadByte(); err != io.EOF {
+			if err != nil {
+				return err
+			}
+			return ErrInvalidExport
+		}
+		return nil
+	})
+}
+
+// importBucket recursively reads records from r into the bucket b until the
+// end record of the bucket is read.
+func importBucket(r *bufio.Reader, b Bucket) error {
+	for {
+		typ, err := r.ReadByte()
+		if err != nil {
+			return importError(err)
+		}
+
+		switch typ {
+		case recordEnd:
+			return nil
+
+		case recordBucket:
+			key, err := readField(r)
+			if err != nil {
+				return err
+			}
+			child, err := b.CreateBucketIfNotExists(key)
+			if err != nil {
+				return err
+			}
+			if err := importBucket(r, child); err != nil {
+				return err
+			}
+
+		case recordValue:
+			key, err := readField(r)
+			if err != nil {
+				return err
+			}
+			value, err := readField(r)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+
+		default:
+			return ErrInvalidExport
+		}
+	}
+}
+
+// readField reads a length-prefixed field from r.  The field is read into a
+// buffer which only grows as data is read so a corrupt length can not cause a
+// huge allocation.  Empty fields are returned as non-nil slices so they are
+// not mistaken for buckets.
+func readField(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, importError(err)
+	}
+	if n > math.MaxInt64 {
+		return nil, ErrInvalidExport
+	}
+	buf := bytes.NewBuffer([]byte{})
+	if _, err := io.CopyN(buf, r, int64(n)); err != nil {
+		return nil, importError(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// importError returns ErrInvalidExport when err indicates the export ended
+// before it was complete and err otherwise.
+func importError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrInvalidExport
+	}
+	return err
+}
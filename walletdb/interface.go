@@ -207,6 +207,69 @@ type DB interface {
 	Close() error
 }
 
+// NamespacesViewer is implemented by databases which can view several of
+// their namespaces in a single read-only transaction.  It is implemented by
+// the databases of every driver of this package.
+type NamespacesViewer interface {
+	// ViewNamespaces invokes the passed function with a managed read-only
+	// transaction of each namespace of keys, in the same order.  Every
+	// transaction views the same state of the database.  Any errors
+	// returned from the user-supplied function are returned from this
+	// function.
+	ViewNamespaces(keys [][]byte, fn func([]Tx) error) error
+}
+
+// ViewNamespaces invokes the passed function with a managed read-only
+// transaction of each namespace of db with a key of keys, in the same order,
+// all viewing the same state of the database.  ErrViewNamespacesUnsupported
+// is returned if db does not implement NamespacesViewer.
+func ViewNamespaces(db DB, keys [][]byte, fn func([]Tx) error) error {
+	viewer, ok := db.(NamespacesViewer)
+	if !ok {
+		return ErrViewNamespacesUnsupported
+	}
+	return viewer.ViewNamespaces(keys, fn)
+}
+
+// CheckedBucket is implemented by buckets whose values can exist but fail to
+// be read, such as the buckets of encrypted databases.
+type CheckedBucket interface {
+	// GetChecked returns the value for the given key, or nil if the key
+	// does not exist in this bucket.  An error is returned if the value
+	// exists but can not be read.
+	GetChecked(key []byte) ([]byte, error)
+}
+
+// GetChecked returns the value for the given key of bucket b, or nil if the
+// key does not exist.  An error is only returned by buckets implementing
+// CheckedBucket, when the value exists but can not be read.
+func GetChecked(b Bucket, key []byte) ([]byte, error) {
+	checked, ok := b.(CheckedBucket)
+	if !ok {
+		return b.Get(key), nil
+	}
+	return checked.GetChecked(key)
+}
+
+// CheckedCursor is implemented by cursors which can fail to read the
+// key/value pairs of their bucket, such as the cursors of encrypted
+// databases.  A failed cursor behaves as a cursor of an empty bucket.
+type CheckedCursor interface {
+	// Err returns the error which caused the cursor to fail, or nil if
+	// it did not fail.
+	Err() error
+}
+
+// CursorErr returns the error which caused cursor c to fail.  It returns nil
+// if the cursor did not fail or does not implement CheckedCursor.
+func CursorErr(c Cursor) error {
+	checked, ok := c.(CheckedCursor)
+	if !ok {
+		return nil
+	}
+	return checked.Err()
+}
+
 // Driver defines a structure for backend drivers to use when they registered
 // themselves as a backend which implements the Db interface.
 type Driver struct {
@@ -223,6 +286,13 @@ type Driver struct {
 	// arguments to open the database.  This function must return
 	// ErrDbDoesNotExist if the database has not already been created.
 	Open func(args ...interface{}) (DB, error)
+
+	// OpenReadOnly is the function that will be invoked with all
+	// user-specified arguments to open the database without permitting any
+	// modifications.  It is nil for drivers which do not support read-only
+	// access.  This function must return ErrDbDoesNotExist if the database
+	// has not already been created.
+	OpenReadOnly func(args ...interface{}) (DB, error)
 }
 
 // driverList holds all of the registered database backends.
@@ -277,3 +347,29 @@ func Open(dbType string, args ...interface{}) (DB, error) {
 
 	return drv.Open(args...)
 }
+
+// OpenReadOnly opens an existing database for the specified type without
+// permitting any modifications.  The arguments are specific to the database
+// type driver.  See the documentation for the database driver for further
+// details, including whether the database may be opened read-only while
+// another process has it open.
+//
+// Beginning a read-write transaction, or deleting a namespace, of a read-only
+// database returns ErrDbReadOnly.  Namespaces are not created on first access,
+// so ErrBucketNotFound is returned when accessing a namespace that does not
+// exist.
+//
+// ErrDbUnknownType will be returned if the the database type is not registered
+// and ErrReadOnlyUnsupported will be returned if the driver does not support
+// read-only access.
+func OpenReadOnly(dbType string, args ...interface{}) (DB, error) {
+	drv, exists := drivers[dbType]
+	if !exists {
+		return nil, ErrDbUnknownType
+	}
+	if drv.OpenReadOnly == nil {
+		return nil, ErrReadOnlyUnsupported
+	}
+
+	return drv.OpenReadOnly(args...)
+}
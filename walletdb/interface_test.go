@@ -26,6 +26,7 @@
 package walletdb_test
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
 	"testing"
@@ -493,6 +494,321 @@ func testManualTxInterface(tc *testContext, namespace walletdb.Namespace) bool {
 	return true
 }
 
+// testNestedBuckets ensures that buckets nested several levels deep work as
+// expected, including their interaction with the key/value pairs of their
+// parent buckets and the removal of their contents when a parent bucket is
+// deleted.
+func testNestedBuckets(tc *testContext, namespace walletdb.Namespace) bool {
+	bucketNames := [][]byte{[]byte("nested1"), []byte("nested2"),
+		[]byte("nested3")}
+	keyValues := []map[string]string{
+		{"key1": "bar1"},
+		{"key2": "bar2"},
+		{"key3": "bar3"},
+	}
+
+	// Create a chain of nested buckets with a value at each level and
+	// ensure values and buckets can not be confused with each other.
+	err := namespace.Update(func(tx walletdb.Tx) error {
+		bucket := tx.RootBucket()
+		if bucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		for i, name := range bucketNames {
+			nested, err := bucket.CreateBucket(name)
+			if err != nil {
+				return fmt.Errorf("CreateBucket: unexpected "+
+					"error: %v", err)
+			}
+			if !testPutValues(tc, nested, keyValues[i]) {
+				return subTestFailError
+			}
+
+			// Ensure the bucket key does not read as a value.
+			if v := bucket.Get(name); v != nil {
+				return fmt.Errorf("Get: unexpected value for "+
+					"bucket key '%s' - got %s, want nil",
+					name, v)
+			}
+
+			// Ensure values can not be written over or deleted
+			// using a bucket key.
+			wantErr := walletdb.ErrIncompatibleValue
+			if err := bucket.Put(name, name); err != wantErr {
+				return fmt.Errorf("Put: unexpected error - "+
+					"got %v, want %v", err, wantErr)
+			}
+			if err := bucket.Delete(name); err != wantErr {
+				return fmt.Errorf("Delete: unexpected error - "+
+					"got %v, want %v", err, wantErr)
+			}
+
+			bucket = nested
+		}
+
+		// Ensure buckets can not be created over or deleted using a
+		// value key.
+		for k := range keyValues[0] {
+			bucket := tx.RootBucket().Bucket(bucketNames[0])
+			wantErr := walletdb.ErrIncompatibleValue
+			if _, err := bucket.CreateBucket([]byte(k)); err != wantErr {
+				return fmt.Errorf("CreateBucket: unexpected "+
+					"error - got %v, want %v", err, wantErr)
+			}
+			if err := bucket.DeleteBucket([]byte(k)); err != wantErr {
+				return fmt.Errorf("DeleteBucket: unexpected "+
+					"error - got %v, want %v", err, wantErr)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err != subTestFailError {
+			tc.t.Errorf("%v", err)
+		}
+		return false
+	}
+
+	// Ensure the nested buckets and their values were committed and that
+	// iterating a bucket returns the values and nested buckets it directly
+	// contains in key order.
+	err = namespace.View(func(tx walletdb.Tx) error {
+		bucket := tx.RootBucket()
+		if bucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		for i, name := range bucketNames {
+			bucket = bucket.Bucket(name)
+			if bucket == nil {
+				return fmt.Errorf("Bucket: bucket '%s' does "+
+					"not exist", name)
+			}
+			if !testGetValues(tc, bucket, keyValues[i]) {
+				return subTestFailError
+			}
+
+			// The value keys sort before the nested bucket key.
+			type kv struct{ k, v string }
+			var wantPairs []kv
+			for k, v := range keyValues[i] {
+				wantPairs = append(wantPairs, kv{k, v})
+			}
+			if i+1 < len(bucketNames) {
+				wantPairs = append(wantPairs,
+					kv{string(bucketNames[i+1]), ""})
+			}
+
+			var gotPairs []kv
+			err := bucket.ForEach(func(k, v []byte) error {
+				gotPairs = append(gotPairs, kv{string(k), string(v)})
+				if v == nil && bucket.Bucket(k) == nil {
+					return fmt.Errorf("ForEach: nil value "+
+						"for non-bucket key '%s'", k)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if !reflect.DeepEqual(gotPairs, wantPairs) {
+				return fmt.Errorf("ForEach: unexpected pairs - "+
+					"got %v, want %v", gotPairs, wantPairs)
+			}
+
+			var cursorPairs []kv
+			c := bucket.Cursor()
+			for k, v := c.First(); k != nil; k, v = c.Next() {
+				cursorPairs = append(cursorPairs,
+					kv{string(k), string(v)})
+			}
+			if !reflect.DeepEqual(cursorPairs, wantPairs) {
+				return fmt.Errorf("Cursor: unexpected pairs - "+
+					"got %v, want %v", cursorPairs, wantPairs)
+			}
+			k, _ := c.Last()
+			wantK := wantPairs[len(wantPairs)-1].k
+			if string(k) != wantK {
+				return fmt.Errorf("Cursor: unexpected last "+
+					"key - got %s, want %s", k, wantK)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err != subTestFailError {
+			tc.t.Errorf("%v", err)
+		}
+		return false
+	}
+
+	// Ensure a cursor refuses to delete a nested bucket, and that deleting
+	// the top bucket removes everything nested beneath it.
+	err = namespace.Update(func(tx walletdb.Tx) error {
+		rootBucket := tx.RootBucket()
+		if rootBucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		c := rootBucket.Bucket(bucketNames[0]).Cursor()
+		k, v := c.Seek(bucketNames[1])
+		if !reflect.DeepEqual(k, bucketNames[1]) || v != nil {
+			return fmt.Errorf("Seek: unexpected pair - got (%s, "+
+				"%s), want (%s, nil)", k, v, bucketNames[1])
+		}
+		wantErr := walletdb.ErrIncompatibleValue
+		if err := c.Delete(); err != wantErr {
+			return fmt.Errorf("Cursor.Delete: unexpected error - "+
+				"got %v, want %v", err, wantErr)
+		}
+
+		if err := rootBucket.DeleteBucket(bucketNames[0]); err != nil {
+			return fmt.Errorf("DeleteBucket: unexpected error: %v",
+				err)
+		}
+		if b := rootBucket.Bucket(bucketNames[0]); b != nil {
+			return fmt.Errorf("DeleteBucket: bucket '%s' still "+
+				"exists", bucketNames[0])
+		}
+
+		// Recreating the bucket must not bring back its old contents.
+		bucket, err := rootBucket.CreateBucket(bucketNames[0])
+		if err != nil {
+			return fmt.Errorf("CreateBucket: unexpected error: %v",
+				err)
+		}
+		if b := bucket.Bucket(bucketNames[1]); b != nil {
+			return fmt.Errorf("CreateBucket: nested bucket '%s' "+
+				"survived deletion", bucketNames[1])
+		}
+		if !testGetValues(tc, bucket, rollbackValues(keyValues[0])) {
+			return subTestFailError
+		}
+
+		return rootBucket.DeleteBucket(bucketNames[0])
+	})
+	if err != nil {
+		if err != subTestFailError {
+			tc.t.Errorf("%v", err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// testBigValues ensures that large keys and values are stored and retrieved
+// intact, and that keys over the size limit are rejected.
+func testBigValues(tc *testContext, namespace walletdb.Namespace) bool {
+	// maxKeySize is the maximum key size all drivers must support.
+	const maxKeySize = 32768
+
+	bigKey := bytes.Repeat([]byte{0xaa}, maxKeySize)
+	bigValue := make([]byte, 4*1024*1024)
+	for i := range bigValue {
+		bigValue[i] = byte(i * 7)
+	}
+	smallKey := []byte("bigvaluekey")
+
+	// Store a big value under both a small and a maximum size key and
+	// ensure a key exceeding the limit is rejected.
+	err := namespace.Update(func(tx walletdb.Tx) error {
+		rootBucket := tx.RootBucket()
+		if rootBucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		if err := rootBucket.Put(smallKey, bigValue); err != nil {
+			return fmt.Errorf("Put: unexpected error: %v", err)
+		}
+		if err := rootBucket.Put(bigKey, bigValue); err != nil {
+			return fmt.Errorf("Put: unexpected error: %v", err)
+		}
+
+		wantErr := walletdb.ErrKeyTooLarge
+		tooBigKey := append(bigKey, 0xaa)
+		if err := rootBucket.Put(tooBigKey, nil); err != wantErr {
+			return fmt.Errorf("Put: unexpected error - got %v, "+
+				"want %v", err, wantErr)
+		}
+
+		return nil
+	})
+	if err != nil {
+		tc.t.Errorf("%v", err)
+		return false
+	}
+
+	// Ensure the big values were committed intact.  Then replace one of
+	// them with a small value and delete the other.
+	err = namespace.View(func(tx walletdb.Tx) error {
+		rootBucket := tx.RootBucket()
+		if rootBucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		for _, k := range [][]byte{smallKey, bigKey} {
+			if !bytes.Equal(rootBucket.Get(k), bigValue) {
+				return fmt.Errorf("Get: big value for key of "+
+					"size %d does not match", len(k))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		tc.t.Errorf("%v", err)
+		return false
+	}
+	err = namespace.Update(func(tx walletdb.Tx) error {
+		rootBucket := tx.RootBucket()
+		if rootBucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		if err := rootBucket.Put(smallKey, []byte("small")); err != nil {
+			return fmt.Errorf("Put: unexpected error: %v", err)
+		}
+		if err := rootBucket.Delete(bigKey); err != nil {
+			return fmt.Errorf("Delete: unexpected error: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		tc.t.Errorf("%v", err)
+		return false
+	}
+
+	// Ensure the replacement and deletion were committed and clean up.
+	err = namespace.Update(func(tx walletdb.Tx) error {
+		rootBucket := tx.RootBucket()
+		if rootBucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		if v := rootBucket.Get(smallKey); string(v) != "small" {
+			return fmt.Errorf("Get: unexpected value - got %s, "+
+				"want small", v)
+		}
+		if v := rootBucket.Get(bigKey); v != nil {
+			return fmt.Errorf("Get: deleted key of size %d "+
+				"still has a value", len(bigKey))
+		}
+
+		return rootBucket.Delete(smallKey)
+	})
+	if err != nil {
+		tc.t.Errorf("%v", err)
+		return false
+	}
+
+	return true
+}
+
 // testNamespaceAndTxInterfaces creates a namespace using the provided key and
 // tests all facets of it interface as well as  transaction and bucket
 // interfaces under it.
@@ -669,6 +985,16 @@ func testNamespaceAndTxInterfaces(tc *testContext, namespaceKey string) bool {
 		return false
 	}
 
+	// Test buckets nested several levels deep.
+	if !testNestedBuckets(tc, namespace) {
+		return false
+	}
+
+	// Test storing and retrieving big keys and values.
+	if !testBigValues(tc, namespace) {
+		return false
+	}
+
 	return true
 }
 
@@ -795,6 +1121,74 @@ func testAdditionalErrors(tc *testContext) bool {
 	return true
 }
 
+// testViewNamespaces ensures that several namespaces can be viewed in a single
+// read-only transaction.
+func testViewNamespaces(tc *testContext) bool {
+	keys := [][]byte{[]byte("viewns1"), []byte("viewns2")}
+	key := []byte("viewkey")
+	for _, nsKey := range keys {
+		ns, err := tc.db.Namespace(nsKey)
+		if err != nil {
+			tc.t.Errorf("Namespace: unexpected error: %v", err)
+			return false
+		}
+		err = ns.Update(func(tx walletdb.Tx) error {
+			return tx.RootBucket().Put(key, nsKey)
+		})
+		if err != nil {
+			tc.t.Errorf("Put: unexpected error: %v", err)
+			return false
+		}
+	}
+	defer func() {
+		for _, nsKey := range keys {
+			if err := tc.db.DeleteNamespace(nsKey); err != nil {
+				tc.t.Errorf("DeleteNamespace: unexpected "+
+					"error: %v", err)
+			}
+		}
+	}()
+
+	// Ensure the values of every namespace are viewed, and that the
+	// transactions are not writable.
+	err := walletdb.ViewNamespaces(tc.db, keys, func(txs []walletdb.Tx) error {
+		if len(txs) != len(keys) {
+			return fmt.Errorf("ViewNamespaces: got %d transactions, "+
+				"want %d", len(txs), len(keys))
+		}
+		for i, tx := range txs {
+			rootBucket := tx.RootBucket()
+			if v := rootBucket.Get(key); !bytes.Equal(v, keys[i]) {
+				return fmt.Errorf("Get: unexpected value - got "+
+					"%s, want %s", v, keys[i])
+			}
+			wantErr := walletdb.ErrTxNotWritable
+			if err := rootBucket.Put(key, key); err != wantErr {
+				return fmt.Errorf("Put: unexpected error - got "+
+					"%v, want %v", err, wantErr)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		tc.t.Errorf("%v", err)
+		return false
+	}
+
+	// Ensure viewing a namespace which does not exist fails.
+	missing := append(keys, []byte("viewnsmissing"))
+	err = walletdb.ViewNamespaces(tc.db, missing, func([]walletdb.Tx) error {
+		return nil
+	})
+	if err != walletdb.ErrBucketNotFound {
+		tc.t.Errorf("ViewNamespaces: unexpected error - got %v, want %v",
+			err, walletdb.ErrBucketNotFound)
+		return false
+	}
+
+	return true
+}
+
 // testInterface tests performs tests for the various interfaces of walletdb
 // which require state in the database for the given database type.
 func testInterface(t *testing.T, db walletdb.DB) {
@@ -815,4 +1209,9 @@ func testInterface(t *testing.T, db walletdb.DB) {
 	if !testAdditionalErrors(&context) {
 		return
 	}
+
+	// Check viewing several namespaces in a single transaction.
+	if !testViewNamespaces(&context) {
+		return
+	}
 }
@@ -0,0 +1,1131 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package ldb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/btcsuite/goleveldb/leveldb"
+	"github.com/btcsuite/goleveldb/leveldb/comparer"
+	"github.com/btcsuite/goleveldb/leveldb/iterator"
+	"github.com/btcsuite/goleveldb/leveldb/memdb"
+	"github.com/btcsuite/goleveldb/leveldb/opt"
+	"github.com/btcsuite/goleveldb/leveldb/storage"
+	"github.com/btcsuite/goleveldb/leveldb/util"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+const (
+	// maxKeySize is the maximum length of a key.  It matches the limit
+	// imposed by bolt so data is portable between the drivers.
+	maxKeySize = 32768
+
+	// maxValueSize is the maximum length of a value.  It matches the limit
+	// imposed by bolt so data is portable between the drivers.
+	maxValueSize = (1 << 31) - 2
+
+	// bucketIDSize is the size of the bucket ID that prefixes every key
+	// stored in leveldb.
+	bucketIDSize = 8
+)
+
+// Every leveldb value begins with a tag byte describing the kind of record it
+// holds.
+const (
+	// tagValue marks a regular key/value pair.  The remainder of the
+	// leveldb value is the user value.
+	tagValue byte = iota
+
+	// tagBucket marks a nested bucket.  The remainder of the leveldb value
+	// is the ID of the nested bucket.
+	tagBucket
+
+	// tagDeleted marks a key removed by a read-write transaction.  It is
+	// only ever written to the pending writes of a transaction and never
+	// reaches leveldb.
+	tagDeleted
+)
+
+var (
+	// rootBucketID is the ID of the bucket holding every namespace.
+	rootBucketID [bucketIDSize]byte
+
+	// nextBucketIDKey is the key of the record holding the next unused
+	// bucket ID.  Bucket keys may never be empty, so the bare root bucket
+	// prefix can not collide with a user key.
+	nextBucketIDKey = rootBucketID[:]
+
+	// writeOpts is used for all writes to leveldb so committed
+	// transactions are durable.
+	writeOpts = &opt.WriteOptions{Sync: true}
+)
+
+// convertErr converts some leveldb errors to the equivalent walletdb error.
+func convertErr(err error) error {
+	switch err {
+	case leveldb.ErrClosed:
+		return walletdb.ErrDbNotOpen
+	case leveldb.ErrSnapshotReleased, leveldb.ErrIterReleased:
+		return walletdb.ErrTxClosed
+	}
+
+	// Return the original error if none of the above applies.
+	return err
+}
+
+// copySlice returns a copy of the passed slice.  Slices returned by leveldb
+// iterators are only valid until the iterator is moved.
+func copySlice(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
+
+// bucket is an internal type used to represent a collection of key/value pairs
+// and implements the walletdb.Bucket interface.  All records of a bucket are
+// stored in leveldb with keys prefixed by the bucket ID.
+type bucket struct {
+	tx *transaction
+	id [bucketIDSize]byte
+}
+
+// Enforce bucket implements the walletdb.Bucket interface.
+var _ walletdb.Bucket = (*bucket)(nil)
+
+// recordKey returns the leveldb key for the passed bucket key.
+func (b *bucket) recordKey(key []byte) []byte {
+	k := make([]byte, bucketIDSize+len(key))
+	copy(k, b.id[:])
+	copy(k[bucketIDSize:], key)
+	return k
+}
+
+// keyRange returns the range of leveldb keys holding the records of the
+// bucket.
+func (b *bucket) keyRange() *util.Range {
+	prefix := make([]byte, bucketIDSize, bucketIDSize+1)
+	copy(prefix, b.id[:])
+	r := util.BytesPrefix(prefix)
+	// Skip the bare prefix which never holds a bucket record.
+	r.Start = append(prefix, 0)
+	return r
+}
+
+// Bucket retrieves a nested bucket with the given key.  Returns nil if
+// the bucket does not exist.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Bucket(key []byte) walletdb.Bucket {
+	// This nil check is intentional so the return value can be checked
+	// against nil directly.
+	nested := b.nestedBucket(key)
+	if nested == nil {
+		return nil
+	}
+	return nested
+}
+
+// nestedBucket returns the nested bucket with the given key, or nil if there is
+// no such bucket.
+func (b *bucket) nestedBucket(key []byte) *bucket {
+	rec := b.tx.get(b.recordKey(key))
+	if rec == nil || rec[0] != tagBucket {
+		return nil
+	}
+	nested := &bucket{tx: b.tx}
+	copy(nested.id[:], rec[1:])
+	return nested
+}
+
+// CreateBucket creates and returns a new nested bucket with the given key.
+// Returns ErrBucketExists if the bucket already exists, ErrBucketNameRequired
+// if the key is empty, or ErrIncompatibleValue if the key value is otherwise
+// invalid.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucket(key []byte) (walletdb.Bucket, error) {
+	if err := b.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(key) == 0:
+		return nil, walletdb.ErrBucketNameRequired
+	case len(key) > maxKeySize:
+		return nil, walletdb.ErrKeyTooLarge
+	}
+
+	recKey := b.recordKey(key)
+	if rec := b.tx.get(recKey); rec != nil {
+		if rec[0] == tagBucket {
+			return nil, walletdb.ErrBucketExists
+		}
+		return nil, walletdb.ErrIncompatibleValue
+	}
+
+	nested := &bucket{tx: b.tx}
+	b.tx.nextBucketID(nested.id[:])
+	b.tx.put(recKey, append([]byte{tagBucket}, nested.id[:]...))
+	return nested, nil
+}
+
+// CreateBucketIfNotExists creates and returns a new nested bucket with the
+// given key if it does not already exist.  Returns ErrBucketNameRequired if the
+// key is empty or ErrIncompatibleValue if the key value is otherwise invalid.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucketIfNotExists(key []byte) (walletdb.Bucket, error) {
+	nested, err := b.CreateBucket(key)
+	if err == walletdb.ErrBucketExists {
+		return b.Bucket(key), nil
+	}
+	return nested, err
+}
+
+// DeleteBucket removes a nested bucket with the given key.  Returns
+// ErrTxNotWritable if attempted against a read-only transaction and
+// ErrBucketNotFound if the specified bucket does not exist.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) DeleteBucket(key []byte) error {
+	if err := b.tx.checkWritable(); err != nil {
+		return err
+	}
+	if len(key) == 0 {
+		return walletdb.ErrIncompatibleValue
+	}
+
+	recKey := b.recordKey(key)
+	rec := b.tx.get(recKey)
+	switch {
+	case rec == nil:
+		return walletdb.ErrBucketNotFound
+	case rec[0] != tagBucket:
+		return walletdb.ErrIncompatibleValue
+	}
+
+	b.nestedBucket(key).deleteRecords()
+	b.tx.delete(recKey)
+	return nil
+}
+
+// deleteRecords removes every record of the bucket, recursing into nested
+// buckets.
+func (b *bucket) deleteRecords() {
+	var keys, nested [][]byte
+	c := b.tx.newCursor(b)
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil && c.isBucket() {
+			nested = append(nested, k)
+		}
+		keys = append(keys, k)
+	}
+	c.release()
+
+	for _, k := range nested {
+		b.nestedBucket(k).deleteRecords()
+	}
+	for _, k := range keys {
+		b.tx.delete(b.recordKey(k))
+	}
+}
+
+// ForEach invokes the passed function with every key/value pair in the bucket.
+// This includes nested buckets, in which case the value is nil, but it does not
+// include the key/value pairs within those nested buckets.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) ForEach(fn func(k, v []byte) error) error {
+	c := b.tx.newCursor(b)
+	defer c.release()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writable returns whether or not the bucket is writable.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Writable() bool {
+	return b.tx.writable
+}
+
+// Put saves the specified key/value pair to the bucket.  Keys that do not
+// already exist are added and keys that already exist are overwritten.  Returns
+// ErrTxNotWritable if attempted against a read-only transaction.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Put(key, value []byte) error {
+	if err := b.tx.checkWritable(); err != nil {
+		return err
+	}
+	switch {
+	case len(key) == 0:
+		return walletdb.ErrKeyRequired
+	case len(key) > maxKeySize:
+		return walletdb.ErrKeyTooLarge
+	case len(value) > maxValueSize:
+		return walletdb.ErrValueTooLarge
+	}
+
+	recKey := b.recordKey(key)
+	if rec := b.tx.get(recKey); rec != nil && rec[0] == tagBucket {
+		return walletdb.ErrIncompatibleValue
+	}
+
+	rec := make([]byte, 1+len(value))
+	rec[0] = tagValue
+	copy(rec[1:], value)
+	b.tx.put(recKey, rec)
+	return nil
+}
+
+// Get returns the value for the given key.  Returns nil if the key does
+// not exist in this bucket (or nested buckets).
+//
+// NOTE: The value returned by this function is only valid during a
+// transaction.  Modifying it results in undefined behavior.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Get(key []byte) []byte {
+	rec := b.tx.get(b.recordKey(key))
+	if rec == nil || rec[0] != tagValue {
+		return nil
+	}
+	return rec[1:]
+}
+
+// Delete removes the specified key from the bucket.  Deleting a key that does
+// not exist does not return an error.  Returns ErrTxNotWritable if attempted
+// against a read-only transaction.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Delete(key []byte) error {
+	if err := b.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	recKey := b.recordKey(key)
+	rec := b.tx.get(recKey)
+	switch {
+	case rec == nil:
+		return nil
+	case rec[0] == tagBucket:
+		return walletdb.ErrIncompatibleValue
+	}
+
+	b.tx.delete(recKey)
+	return nil
+}
+
+// Cursor returns a new cursor, allowing for iteration over the bucket's
+// key/value pairs and nested buckets in forward or backward order.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Cursor() walletdb.Cursor {
+	return b.tx.newCursor(b)
+}
+
+// cursor represents a cursor over key/value pairs and nested buckets of a
+// bucket.  It merges the records of the transaction snapshot with the pending
+// writes of the transaction, with pending writes taking precedence.
+//
+// Note that open cursors are not tracked on bucket changes and any
+// modifications to the bucket, with the exception of cursor.Delete, invalidate
+// the cursor. After invalidation, the cursor must be repositioned, or the keys
+// and values returned may be unpredictable.
+type cursor struct {
+	bucket *bucket
+
+	// dbIter iterates the records of the snapshot and pendingIter the
+	// pending writes of the transaction.  current is whichever of the two
+	// the cursor is positioned at, or nil when the cursor is exhausted.
+	dbIter      iterator.Iterator
+	pendingIter iterator.Iterator
+	current     iterator.Iterator
+
+	// forward records the direction of the last cursor movement.  When
+	// moving forward, both iterators are positioned at or after the
+	// current key.  When moving backward, both are at or before it.
+	forward bool
+}
+
+// Enforce cursor implements the walletdb.Cursor interface.
+var _ walletdb.Cursor = (*cursor)(nil)
+
+// release releases the iterators of the cursor.
+func (c *cursor) release() {
+	c.dbIter.Release()
+	c.pendingIter.Release()
+	c.current = nil
+	delete(c.bucket.tx.cursors, c)
+}
+
+// isBucket returns whether the cursor is positioned at a nested bucket.
+func (c *cursor) isBucket() bool {
+	return c.current != nil && c.current.Value()[0] == tagBucket
+}
+
+// atKey returns whether iter is positioned at the passed key.
+func atKey(iter iterator.Iterator, key []byte) bool {
+	return iter.Valid() && bytes.Equal(iter.Key(), key)
+}
+
+// entry returns the key/value pair the cursor is positioned at.
+func (c *cursor) entry() (key, value []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+	key = copySlice(c.current.Key()[bucketIDSize:])
+	rec := c.current.Value()
+	if rec[0] == tagBucket {
+		return key, nil
+	}
+	return key, copySlice(rec[1:])
+}
+
+// selectForward positions the cursor at the smaller key of the two iterators,
+// skipping keys deleted by the transaction, and returns the pair.
+func (c *cursor) selectForward() (key, value []byte) {
+	for {
+		dbValid, pendingValid := c.dbIter.Valid(), c.pendingIter.Valid()
+		switch {
+		case !dbValid && !pendingValid:
+			c.current = nil
+			return nil, nil
+		case !pendingValid:
+			c.current = c.dbIter
+		case !dbValid:
+			c.current = c.pendingIter
+		case bytes.Compare(c.dbIter.Key(), c.pendingIter.Key()) < 0:
+			c.current = c.dbIter
+		default:
+			c.current = c.pendingIter
+		}
+
+		if c.current.Value()[0] != tagDeleted {
+			return c.entry()
+		}
+		if atKey(c.dbIter, c.pendingIter.Key()) {
+			c.dbIter.Next()
+		}
+		c.pendingIter.Next()
+	}
+}
+
+// selectBackward positions the cursor at the larger key of the two iterators,
+// skipping keys deleted by the transaction, and returns the pair.
+func (c *cursor) selectBackward() (key, value []byte) {
+	for {
+		dbValid, pendingValid := c.dbIter.Valid(), c.pendingIter.Valid()
+		switch {
+		case !dbValid && !pendingValid:
+			c.current = nil
+			return nil, nil
+		case !pendingValid:
+			c.current = c.dbIter
+		case !dbValid:
+			c.current = c.pendingIter
+		case bytes.Compare(c.dbIter.Key(), c.pendingIter.Key()) > 0:
+			c.current = c.dbIter
+		default:
+			c.current = c.pendingIter
+		}
+
+		if c.current.Value()[0] != tagDeleted {
+			return c.entry()
+		}
+		if atKey(c.dbIter, c.pendingIter.Key()) {
+			c.dbIter.Prev()
+		}
+		c.pendingIter.Prev()
+	}
+}
+
+// Bucket returns the bucket the cursor was created for.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Bucket() walletdb.Bucket {
+	return c.bucket
+}
+
+// Delete removes the current key/value pair the cursor is at without
+// invalidating the cursor. Returns ErrTxNotWritable if attempted on a read-only
+// transaction, or ErrIncompatibleValue if attempted when the cursor points to a
+// nested bucket.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Delete() error {
+	if err := c.bucket.tx.checkWritable(); err != nil {
+		return err
+	}
+	if c.current == nil {
+		return nil
+	}
+	if c.isBucket() {
+		return walletdb.ErrIncompatibleValue
+	}
+
+	c.bucket.tx.delete(copySlice(c.current.Key()))
+	return nil
+}
+
+// First positions the cursor at the first key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) First() (key, value []byte) {
+	c.dbIter.First()
+	c.pendingIter.First()
+	c.forward = true
+	return c.selectForward()
+}
+
+// Last positions the cursor at the last key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Last() (key, value []byte) {
+	c.dbIter.Last()
+	c.pendingIter.Last()
+	c.forward = false
+	return c.selectBackward()
+}
+
+// Next moves the cursor one key/value pair forward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Next() (key, value []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+
+	currentKey := copySlice(c.current.Key())
+	for _, iter := range []iterator.Iterator{c.dbIter, c.pendingIter} {
+		if !c.forward {
+			// Position the iterator at or after the current key
+			// when changing direction.
+			iter.Seek(currentKey)
+		}
+		if atKey(iter, currentKey) {
+			iter.Next()
+		}
+	}
+	c.forward = true
+	return c.selectForward()
+}
+
+// Prev moves the cursor one key/value pair backward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Prev() (key, value []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+
+	currentKey := copySlice(c.current.Key())
+	for _, iter := range []iterator.Iterator{c.dbIter, c.pendingIter} {
+		if c.forward {
+			// Position the iterator before the current key when
+			// changing direction.
+			if iter.Seek(currentKey) {
+				iter.Prev()
+			} else {
+				iter.Last()
+			}
+			continue
+		}
+		if atKey(iter, currentKey) {
+			iter.Prev()
+		}
+	}
+	c.forward = false
+	return c.selectBackward()
+}
+
+// Seek positions the cursor at the passed seek key. If the key does not exist,
+// the cursor is moved to the next key after seek. Returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Seek(seek []byte) (key, value []byte) {
+	recKey := c.bucket.recordKey(seek)
+	c.dbIter.Seek(recKey)
+	c.pendingIter.Seek(recKey)
+	c.forward = true
+	return c.selectForward()
+}
+
+// transaction represents a database transaction.  It can either by read-only or
+// read-write and implements the walletdb.Tx interface.  Reads are served from
+// a leveldb snapshot taken when the transaction began.  Writes of a read-write
+// transaction are held in memory and written to leveldb as a single batch on
+// commit.
+type transaction struct {
+	db       *db
+	snapshot *leveldb.Snapshot
+	pending  *memdb.DB
+	cursors  map[*cursor]struct{}
+	root     *bucket
+	writable bool
+	managed  bool
+	closed   bool
+}
+
+// Enforce transaction implements the walletdb.Tx interface.
+var _ walletdb.Tx = (*transaction)(nil)
+
+// checkWritable returns an error if the transaction may not be written to.
+func (tx *transaction) checkWritable() error {
+	if tx.closed {
+		return walletdb.ErrTxClosed
+	}
+	if !tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	return nil
+}
+
+// get returns the record stored under the leveldb key, or nil if there is no
+// such record.
+func (tx *transaction) get(key []byte) []byte {
+	if tx.closed {
+		return nil
+	}
+	if tx.pending != nil {
+		rec, err := tx.pending.Get(key)
+		if err == nil {
+			if rec[0] == tagDeleted {
+				return nil
+			}
+			return rec
+		}
+	}
+	rec, err := tx.snapshot.Get(key, nil)
+	if err != nil {
+		return nil
+	}
+	return rec
+}
+
+// put records a write of the leveldb key to be applied on commit.
+func (tx *transaction) put(key, rec []byte) {
+	// Writes to a memdb can not fail.
+	_ = tx.pending.Put(key, rec)
+}
+
+// delete records a removal of the leveldb key to be applied on commit.
+func (tx *transaction) delete(key []byte) {
+	_ = tx.pending.Put(key, []byte{tagDeleted})
+}
+
+// nextBucketID writes an unused bucket ID to id and reserves it.
+func (tx *transaction) nextBucketID(id []byte) {
+	next := uint64(1)
+	if rec := tx.get(nextBucketIDKey); rec != nil {
+		next = binary.BigEndian.Uint64(rec[1:])
+	}
+	binary.BigEndian.PutUint64(id, next)
+
+	rec := make([]byte, 1+bucketIDSize)
+	rec[0] = tagValue
+	binary.BigEndian.PutUint64(rec[1:], next+1)
+	tx.put(nextBucketIDKey, rec)
+}
+
+// newCursor returns a cursor over the records of the bucket.  The cursor is
+// released when the transaction is closed.
+func (tx *transaction) newCursor(b *bucket) *cursor {
+	r := b.keyRange()
+	c := &cursor{bucket: b}
+	if tx.closed {
+		c.dbIter = iterator.NewEmptyIterator(walletdb.ErrTxClosed)
+		c.pendingIter = iterator.NewEmptyIterator(walletdb.ErrTxClosed)
+		return c
+	}
+	c.dbIter = tx.snapshot.NewIterator(r, nil)
+	if tx.pending != nil {
+		c.pendingIter = tx.pending.NewIterator(r)
+	} else {
+		c.pendingIter = iterator.NewEmptyIterator(nil)
+	}
+	tx.cursors[c] = struct{}{}
+	return c
+}
+
+// close releases all resources held by the transaction.
+func (tx *transaction) close() {
+	for c := range tx.cursors {
+		c.release()
+	}
+	tx.snapshot.Release()
+	tx.pending = nil
+	tx.closed = true
+	if tx.writable {
+		tx.db.writeMtx.Unlock()
+	}
+	tx.db.closeMtx.RUnlock()
+}
+
+// RootBucket returns the top-most bucket for the namespace the transaction was
+// created from.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) RootBucket() walletdb.Bucket {
+	return tx.root
+}
+
+// Commit commits all changes that have been made through the root bucket and
+// all of its sub-buckets to persistent storage.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Commit() error {
+	if tx.managed {
+		panic("managed tx commit not allowed")
+	}
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	iter := tx.pending.NewIterator(nil)
+	for iter.Next() {
+		if iter.Value()[0] == tagDeleted {
+			batch.Delete(iter.Key())
+		} else {
+			batch.Put(iter.Key(), iter.Value())
+		}
+	}
+	iter.Release()
+
+	err := tx.db.ldb.Write(batch, writeOpts)
+	tx.close()
+	return convertErr(err)
+}
+
+// Rollback undoes all changes that have been made to the root bucket and all of
+// its sub-buckets.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Rollback() error {
+	if tx.managed {
+		panic("managed tx rollback not allowed")
+	}
+	if tx.closed {
+		return walletdb.ErrTxClosed
+	}
+	tx.close()
+	return nil
+}
+
+// namespace represents a database namespace that is inteded to support the
+// concept of a single entity that controls the opening, creating, and closing
+// of a database while providing other entities their own namespace to work in.
+// It implements the walletdb.Namespace interface.
+type namespace struct {
+	db  *db
+	key []byte
+}
+
+// Enforce namespace implements the walletdb.Namespace interface.
+var _ walletdb.Namespace = (*namespace)(nil)
+
+// begin starts a transaction rooted at the namespace bucket.
+func (ns *namespace) begin(writable bool) (*transaction, error) {
+	tx, err := ns.db.begin(writable)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &bucket{tx: tx, id: rootBucketID}
+	tx.root = root.nestedBucket(ns.key)
+	if tx.root == nil {
+		tx.close()
+		return nil, walletdb.ErrBucketNotFound
+	}
+
+	return tx, nil
+}
+
+// Begin starts a transaction which is either read-only or read-write depending
+// on the specified flag.  Multiple read-only transactions can be started
+// simultaneously while only a single read-write transaction can be started at a
+// time.  The call will block when starting a read-write transaction when one is
+// already open.
+//
+// NOTE: The transaction must be closed by calling Rollback or Commit on it when
+// it is no longer needed.  Failure to do so will result in unclaimed memory and
+// will block all further read-write transactions.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) Begin(writable bool) (walletdb.Tx, error) {
+	return ns.begin(writable)
+}
+
+// View invokes the passed function in the context of a managed read-only
+// transaction.  Any errors returned from the user-supplied function are
+// returned from this function.
+//
+// Calling Rollback on the transaction passed to the user-supplied function will
+// result in a panic.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) View(fn func(walletdb.Tx) error) error {
+	tx, err := ns.begin(false)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the transaction is closed even if the user-supplied
+	// function panics.
+	defer func() {
+		if !tx.closed {
+			tx.close()
+		}
+	}()
+
+	tx.managed = true
+	err = fn(tx)
+	tx.managed = false
+	return err
+}
+
+// Update invokes the passed function in the context of a managed read-write
+// transaction.  Any errors returned from the user-supplied function will cause
+// the transaction to be rolled back and are returned from this function.
+// Otherwise, the transaction is commited when the user-supplied function
+// returns a nil error.
+//
+// Calling Rollback on the transaction passed to the user-supplied function will
+// result in a panic.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) Update(fn func(walletdb.Tx) error) error {
+	tx, err := ns.begin(true)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the transaction is rolled back even if the user-supplied
+	// function panics.
+	defer func() {
+		if !tx.closed {
+			tx.close()
+		}
+	}()
+
+	tx.managed = true
+	err = fn(tx)
+	tx.managed = false
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// db represents a collection of namespaces which are persisted and implements
+// the walletdb.Db interface.  All database access is performed through
+// transactions which are obtained through the specific Namespace.
+type db struct {
+	ldb *leveldb.DB
+
+	// writeMtx is held by the single open read-write transaction.
+	writeMtx sync.Mutex
+
+	// closeMtx is read locked by every open transaction so that closing
+	// the database waits for them to finish.
+	closeMtx sync.RWMutex
+	closed   bool
+
+	// readOnly is set for databases opened without permitting any
+	// modifications.
+	readOnly bool
+}
+
+// Enforce db implements the walletdb.Db and walletdb.NamespacesViewer
+// interfaces.
+var (
+	_ walletdb.DB               = (*db)(nil)
+	_ walletdb.NamespacesViewer = (*db)(nil)
+)
+
+// begin starts a transaction over the whole database.
+func (db *db) begin(writable bool) (*transaction, error) {
+	db.closeMtx.RLock()
+	if db.closed {
+		db.closeMtx.RUnlock()
+		return nil, walletdb.ErrDbNotOpen
+	}
+	if writable && db.readOnly {
+		db.closeMtx.RUnlock()
+		return nil, walletdb.ErrDbReadOnly
+	}
+	if writable {
+		db.writeMtx.Lock()
+	}
+
+	snapshot, err := db.ldb.GetSnapshot()
+	if err != nil {
+		if writable {
+			db.writeMtx.Unlock()
+		}
+		db.closeMtx.RUnlock()
+		return nil, convertErr(err)
+	}
+
+	tx := &transaction{
+		db:       db,
+		snapshot: snapshot,
+		cursors:  make(map[*cursor]struct{}),
+		writable: writable,
+	}
+	if writable {
+		tx.pending = memdb.New(comparer.DefaultComparer, 0)
+	}
+	return tx, nil
+}
+
+// Namespace returns a Namespace interface for the provided key.  See the
+// Namespace interface documentation for more details.  Attempting to access a
+// Namespace on a database that is not open yet or has been closed will result
+// in ErrDbNotOpen.  Namespaces are created in the database on first access.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Namespace(key []byte) (walletdb.Namespace, error) {
+	// Check if the namespace needs to be created using a read-only
+	// transaction.  This is done because read-only transactions are faster
+	// and don't block like write transactions.
+	tx, err := db.begin(false)
+	if err != nil {
+		return nil, err
+	}
+	root := &bucket{tx: tx, id: rootBucketID}
+	doCreate := root.nestedBucket(key) == nil
+	tx.close()
+
+	// Namespaces can not be created in read-only databases.
+	if doCreate && db.readOnly {
+		return nil, walletdb.ErrBucketNotFound
+	}
+
+	// Create the namespace if needed by using a writable transaction.
+	if doCreate {
+		tx, err := db.begin(true)
+		if err != nil {
+			return nil, err
+		}
+		root := &bucket{tx: tx, id: rootBucketID}
+		if _, err := root.CreateBucketIfNotExists(key); err != nil {
+			tx.close()
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &namespace{db: db, key: copySlice(key)}, nil
+}
+
+// DeleteNamespace deletes the namespace for the passed key.  ErrBucketNotFound
+// will be returned if the namespace does not exist.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) DeleteNamespace(key []byte) error {
+	tx, err := db.begin(true)
+	if err != nil {
+		return err
+	}
+	root := &bucket{tx: tx, id: rootBucketID}
+	if err := root.DeleteBucket(key); err != nil {
+		tx.close()
+		return err
+	}
+	return tx.Commit()
+}
+
+// viewTx is a managed read-only transaction rooted at one of the namespaces
+// viewed by ViewNamespaces.  The transactions of all viewed namespaces share a
+// single underlying transaction.  It implements the walletdb.Tx interface.
+type viewTx struct {
+	root *bucket
+}
+
+// RootBucket returns the top-most bucket for the namespace the transaction was
+// created for.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *viewTx) RootBucket() walletdb.Bucket {
+	return tx.root
+}
+
+// Commit panics since the transaction is managed by ViewNamespaces.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *viewTx) Commit() error {
+	panic("managed tx commit not allowed")
+}
+
+// Rollback panics since the transaction is managed by ViewNamespaces.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *viewTx) Rollback() error {
+	panic("managed tx rollback not allowed")
+}
+
+// ViewNamespaces invokes the passed function with a managed read-only
+// transaction of each namespace of keys, all viewing the same state of the
+// database.  ErrBucketNotFound is returned if any of the namespaces does not
+// exist.
+//
+// This function is part of the walletdb.NamespacesViewer interface
+// implementation.
+func (db *db) ViewNamespaces(keys [][]byte, fn func([]walletdb.Tx) error) error {
+	tx, err := db.begin(false)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the transaction is closed even if the user-supplied
+	// function panics.
+	defer func() {
+		if !tx.closed {
+			tx.close()
+		}
+	}()
+
+	root := &bucket{tx: tx, id: rootBucketID}
+	txs := make([]walletdb.Tx, len(keys))
+	for i, key := range keys {
+		nsRoot := root.nestedBucket(key)
+		if nsRoot == nil {
+			return walletdb.ErrBucketNotFound
+		}
+		txs[i] = &viewTx{root: nsRoot}
+	}
+	return fn(txs)
+}
+
+// Copy writes a copy of the database to the provided writer.  This call will
+// start a read-only transaction to perform all operations.
+//
+// The copy is a sequence of every leveldb key/value pair in key order, each
+// encoded as the varint length of the key, the key, the varint length of the
+// value, and the value.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Copy(w io.Writer) error {
+	tx, err := db.begin(false)
+	if err != nil {
+		return err
+	}
+	defer tx.close()
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	writeField := func(b []byte) error {
+		n := binary.PutUvarint(lenBuf[:], uint64(len(b)))
+		if _, err := w.Write(lenBuf[:n]); err != nil {
+			return err
+		}
+		_, err := w.Write(b)
+		return err
+	}
+
+	iter := tx.snapshot.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		if err := writeField(iter.Key()); err != nil {
+			return err
+		}
+		if err := writeField(iter.Value()); err != nil {
+			return err
+		}
+	}
+	return convertErr(iter.Error())
+}
+
+// Close cleanly shuts down the database and syncs all data.  It blocks until
+// all open transactions are closed.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Close() error {
+	db.closeMtx.Lock()
+	defer db.closeMtx.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	return convertErr(db.ldb.Close())
+}
+
+// fileExists reports whether the named file or directory exists.
+func fileExists(name string) bool {
+	if _, err := os.Stat(name); err != nil {
+		if os.IsNotExist(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// openDB opens the database at the provided path.  walletdb.ErrDbDoesNotExist
+// is returned if the database doesn't exist and the create flag is not set.
+func openDB(dbPath string, create bool) (walletdb.DB, error) {
+	if !create && !fileExists(dbPath) {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+
+	opts := &opt.Options{ErrorIfMissing: !create}
+	ldb, err := leveldb.OpenFile(dbPath, opts)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return &db{ldb: ldb}, nil
+}
+
+// openReadOnlyDB opens the database at the provided path without permitting
+// any modifications.  Multiple processes may open the database read-only at
+// the same time, but not while it is open for writing.
+func openReadOnlyDB(dbPath string) (walletdb.DB, error) {
+	if !fileExists(dbPath) {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+
+	opts := &opt.Options{ErrorIfMissing: true, ReadOnly: true}
+	ldb, err := leveldb.OpenFile(dbPath, opts)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return &db{ldb: ldb, readOnly: true}, nil
+}
+
+// OpenStorage opens a database kept in the passed leveldb storage, creating it
+// if the storage is empty.  It allows databases to be held somewhere other
+// than a directory of files, such as the in-memory storage returned by
+// storage.NewMemStorage.
+//
+// Most callers should instead use walletdb.Open and walletdb.Create.
+func OpenStorage(stor storage.Storage) (walletdb.DB, error) {
+	ldb, err := leveldb.Open(stor, nil)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return &db{ldb: ldb}, nil
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+/*
+Package ldb implements an instance of walletdb that uses leveldb for the backing
+datastore.
+
+Usage
+
+This package is only a driver to the walletdb package and provides the database
+type of "ldb".  The only parameter the Open and Create functions take is the
+database path as a string.  Unlike bolt, leveldb stores its data in several
+files, so the path names a directory:
+
+	db, err := walletdb.Open("ldb", "path/to/database")
+	if err != nil {
+		// Handle error
+	}
+
+	db, err := walletdb.Create("ldb", "path/to/database")
+	if err != nil {
+		// Handle error
+	}
+
+The database may also be opened with walletdb.OpenReadOnly.  Any number of
+processes may open the database read-only at the same time, but not while
+another process has it open for writing.
+
+Storage Layout
+
+Every bucket, including the bucket of each namespace, is assigned a unique
+8-byte ID.  Records are stored in leveldb under the ID of the bucket they belong
+to followed by their key, so the records of a bucket are contiguous and ordered
+by key.  The first byte of each leveldb value tags the record as either a
+key/value pair or a nested bucket, in which case the ID of the nested bucket
+follows.
+
+Read-only transactions read from a leveldb snapshot.  Read-write transactions
+keep their writes in memory, merged with the snapshot for reads, and write them
+to leveldb as a single synced batch on commit.  Only one read-write transaction
+may be open at a time.
+*/
+package ldb
@@ -0,0 +1,296 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package ldb_test
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrwallet/walletdb"
+	_ "github.com/decred/dcrwallet/walletdb/ldb"
+)
+
+// dbType is the database type name for this driver.
+const dbType = "ldb"
+
+// TestCreateOpenFail ensures that errors related to creating and opening a
+// database are handled properly.
+func TestCreateOpenFail(t *testing.T) {
+	// Ensure that attempting to open a database that doesn't exist returns
+	// the expected error.
+	wantErr := walletdb.ErrDbDoesNotExist
+	if _, err := walletdb.Open(dbType, "noexist"); err != wantErr {
+		t.Errorf("Open: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to open a database with the wrong number of
+	// parameters returns the expected error.
+	wantErr = fmt.Errorf("invalid arguments to %s.Open -- expected "+
+		"database path", dbType)
+	if _, err := walletdb.Open(dbType, 1, 2, 3); err.Error() != wantErr.Error() {
+		t.Errorf("Open: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to open a database with an invalid type for
+	// the first parameter returns the expected error.
+	wantErr = fmt.Errorf("first argument to %s.Open is invalid -- "+
+		"expected database path string", dbType)
+	if _, err := walletdb.Open(dbType, 1); err.Error() != wantErr.Error() {
+		t.Errorf("Open: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to create a database with the wrong number of
+	// parameters returns the expected error.
+	wantErr = fmt.Errorf("invalid arguments to %s.Create -- expected "+
+		"database path", dbType)
+	if _, err := walletdb.Create(dbType, 1, 2, 3); err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to open a database with an invalid type for
+	// the first parameter returns the expected error.
+	wantErr = fmt.Errorf("first argument to %s.Create is invalid -- "+
+		"expected database path string", dbType)
+	if _, err := walletdb.Create(dbType, 1); err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure operations against a closed database return the expected
+	// error.
+	dbPath := "createfail"
+	db, err := walletdb.Create(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Create: unexpected error: %v", err)
+		return
+	}
+	defer os.RemoveAll(dbPath)
+	db.Close()
+
+	wantErr = walletdb.ErrDbNotOpen
+	if _, err := db.Namespace([]byte("ns1")); err != wantErr {
+		t.Errorf("Namespace: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+}
+
+// TestPersistence ensures that values stored are still valid after closing and
+// reopening the database.
+func TestPersistence(t *testing.T) {
+	// Create a new database to run tests against.
+	dbPath := "persistencetest"
+	db, err := walletdb.Create(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.RemoveAll(dbPath)
+	defer db.Close()
+
+	// Create a namespace and put some values into it so they can be tested
+	// for existence on re-open.
+	storeValues := map[string]string{
+		"ns1key1": "foo1",
+		"ns1key2": "foo2",
+		"ns1key3": "foo3",
+	}
+	ns1Key := []byte("ns1")
+	ns1, err := db.Namespace(ns1Key)
+	if err != nil {
+		t.Errorf("Namespace: unexpected error: %v", err)
+		return
+	}
+	err = ns1.Update(func(tx walletdb.Tx) error {
+		rootBucket := tx.RootBucket()
+		if rootBucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		for k, v := range storeValues {
+			if err := rootBucket.Put([]byte(k), []byte(v)); err != nil {
+				return fmt.Errorf("Put: unexpected error: %v", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Errorf("ns1 Update: unexpected error: %v", err)
+		return
+	}
+
+	// Close and reopen the database to ensure the values persist.
+	db.Close()
+	db, err = walletdb.Open(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to open test database (%s) %v", dbType, err)
+		return
+	}
+	defer db.Close()
+
+	// Ensure the values previously stored in the 3rd namespace still exist
+	// and are correct.
+	ns1, err = db.Namespace(ns1Key)
+	if err != nil {
+		t.Errorf("Namespace: unexpected error: %v", err)
+		return
+	}
+	err = ns1.View(func(tx walletdb.Tx) error {
+		rootBucket := tx.RootBucket()
+		if rootBucket == nil {
+			return fmt.Errorf("RootBucket: unexpected nil root bucket")
+		}
+
+		for k, v := range storeValues {
+			gotVal := rootBucket.Get([]byte(k))
+			if !reflect.DeepEqual(gotVal, []byte(v)) {
+				return fmt.Errorf("Get: key '%s' does not "+
+					"match expected value - got %s, want %s",
+					k, gotVal, v)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Errorf("ns1 View: unexpected error: %v", err)
+		return
+	}
+}
+
+// TestReadOnly ensures that a database opened read-only can be read but not
+// modified.
+func TestReadOnly(t *testing.T) {
+	// Create a new database with a namespace and value to read back after
+	// opening it read-only.
+	dbPath := "readonlytest"
+	db, err := walletdb.Create(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.RemoveAll(dbPath)
+	defer db.Close()
+
+	nsKey := []byte("ns")
+	key, value := []byte("key"), []byte("value")
+	ns, err := db.Namespace(nsKey)
+	if err != nil {
+		t.Errorf("Namespace: unexpected error: %v", err)
+		return
+	}
+	err = ns.Update(func(tx walletdb.Tx) error {
+		return tx.RootBucket().Put(key, value)
+	})
+	if err != nil {
+		t.Errorf("Update: unexpected error: %v", err)
+		return
+	}
+	// Close the database since it may not be opened read-only while it is
+	// open for writing.
+	db.Close()
+
+	// Ensure that attempting to open a database that doesn't exist returns
+	// the expected error.
+	wantErr := walletdb.ErrDbDoesNotExist
+	if _, err := walletdb.OpenReadOnly(dbType, "noexist"); err != wantErr {
+		t.Errorf("OpenReadOnly: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	roDB, err := walletdb.OpenReadOnly(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to open test database read-only (%s) %v",
+			dbType, err)
+		return
+	}
+	defer roDB.Close()
+
+	// Ensure the value can be read.
+	ns, err = roDB.Namespace(nsKey)
+	if err != nil {
+		t.Errorf("Namespace: unexpected error: %v", err)
+		return
+	}
+	err = ns.View(func(tx walletdb.Tx) error {
+		gotVal := tx.RootBucket().Get(key)
+		if !reflect.DeepEqual(gotVal, value) {
+			return fmt.Errorf("Get: key '%s' does not match "+
+				"expected value - got %s, want %s", key, gotVal,
+				value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("View: unexpected error: %v", err)
+		return
+	}
+
+	// Ensure the database can not be modified.
+	wantErr = walletdb.ErrDbReadOnly
+	if _, err := ns.Begin(true); err != wantErr {
+		t.Errorf("Begin: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+	}
+	err = ns.Update(func(tx walletdb.Tx) error {
+		return tx.RootBucket().Put(key, value)
+	})
+	if err != wantErr {
+		t.Errorf("Update: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+	}
+	if err := roDB.DeleteNamespace(nsKey); err != wantErr {
+		t.Errorf("DeleteNamespace: did not receive expected error - "+
+			"got %v, want %v", err, wantErr)
+	}
+
+	// Ensure namespaces are not created on first access.
+	wantErr = walletdb.ErrBucketNotFound
+	if _, err := roDB.Namespace([]byte("noexist")); err != wantErr {
+		t.Errorf("Namespace: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+	}
+}
+
+// TestInterface performs all interfaces tests for this database driver.
+func TestInterface(t *testing.T) {
+	// Create a new database to run tests against.
+	dbPath := "interfacetest"
+	db, err := walletdb.Create(dbType, dbPath)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.RemoveAll(dbPath)
+	defer db.Close()
+
+	// Run all of the interface tests against the database.
+	testInterface(t, db)
+}
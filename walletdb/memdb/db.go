@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package memdb
+
+import (
+	"io"
+	"sync"
+
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// handle is a handle to a named database and implements the walletdb.DB
+// interface.  Closing a handle only prevents further use of the handle and
+// leaves the database open so it may be reopened.
+type handle struct {
+	db     walletdb.DB
+	mtx    sync.Mutex
+	closed bool
+}
+
+// Enforce handle implements the walletdb.DB and walletdb.NamespacesViewer
+// interfaces.
+var (
+	_ walletdb.DB               = (*handle)(nil)
+	_ walletdb.NamespacesViewer = (*handle)(nil)
+)
+
+// open returns the database of the handle, or ErrDbNotOpen if the handle is
+// closed.
+func (h *handle) open() (walletdb.DB, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.closed {
+		return nil, walletdb.ErrDbNotOpen
+	}
+	return h.db, nil
+}
+
+// Namespace returns a Namespace interface for the provided key.  See the
+// Namespace interface documentation for more details.  Attempting to access a
+// Namespace on a database that is not open yet or has been closed will result
+// in ErrDbNotOpen.  Namespaces are created in the database on first access.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (h *handle) Namespace(key []byte) (walletdb.Namespace, error) {
+	db, err := h.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.Namespace(key)
+}
+
+// DeleteNamespace deletes the namespace for the passed key.  ErrBucketNotFound
+// will be returned if the namespace does not exist.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (h *handle) DeleteNamespace(key []byte) error {
+	db, err := h.open()
+	if err != nil {
+		return err
+	}
+	return db.DeleteNamespace(key)
+}
+
+// ViewNamespaces invokes the passed function with a managed read-only
+// transaction of each namespace of keys, all viewing the same state of the
+// database.
+//
+// This function is part of the walletdb.NamespacesViewer interface
+// implementation.
+func (h *handle) ViewNamespaces(keys [][]byte, fn func([]walletdb.Tx) error) error {
+	db, err := h.open()
+	if err != nil {
+		return err
+	}
+	return walletdb.ViewNamespaces(db, keys, fn)
+}
+
+// Copy writes a copy of the database to the provided writer.  This call will
+// start a read-only transaction to perform all operations.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (h *handle) Copy(w io.Writer) error {
+	db, err := h.open()
+	if err != nil {
+		return err
+	}
+	return db.Copy(w)
+}
+
+// Close closes the handle.  The named database remains open and may be
+// reopened with walletdb.Open.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (h *handle) Close() error {
+	h.mtx.Lock()
+	h.closed = true
+	h.mtx.Unlock()
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+/*
+Package memdb implements an instance of walletdb that is held entirely in
+memory.  It is intended for tests and throwaway wallets which do not need to
+outlive the process.
+
+Usage
+
+This package is only a driver to the walletdb package and provides the database
+type of "memdb".  Create takes an optional database name.  A database created
+without a name is discarded when it is closed:
+
+	db, err := walletdb.Create("memdb")
+	if err != nil {
+		// Handle error
+	}
+
+A named database is kept until the process exits, so it may be closed and later
+reopened by passing the same name to Open:
+
+	db, err := walletdb.Create("memdb", "name")
+	if err != nil {
+		// Handle error
+	}
+
+	db, err := walletdb.Open("memdb", "name")
+	if err != nil {
+		// Handle error
+	}
+
+The database is a leveldb database using in-memory storage and has the same
+semantics as the ldb driver.
+*/
+package memdb
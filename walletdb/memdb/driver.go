@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package memdb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/goleveldb/leveldb/storage"
+	"github.com/decred/dcrwallet/walletdb"
+	"github.com/decred/dcrwallet/walletdb/ldb"
+)
+
+const (
+	dbType = "memdb"
+)
+
+var (
+	// named holds every named database.  They are never closed so they
+	// may be reopened after all handles to them are closed.
+	named    = make(map[string]walletdb.DB)
+	namedMtx sync.Mutex
+)
+
+// parseArgs parses the arguments from the walletdb Open/Create methods.
+func parseArgs(funcName string, args ...interface{}) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("invalid arguments to %s.%s -- "+
+			"expected optional database name", dbType, funcName)
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+
+	name, ok := args[0].(string)
+	if !ok {
+		return "", fmt.Errorf("first argument to %s.%s is invalid -- "+
+			"expected database name string", dbType, funcName)
+	}
+
+	return name, nil
+}
+
+// openDBDriver is the callback provided during driver registration that opens
+// an existing named database for use.
+func openDBDriver(args ...interface{}) (walletdb.DB, error) {
+	name, err := parseArgs("Open", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	namedMtx.Lock()
+	db, ok := named[name]
+	namedMtx.Unlock()
+	if !ok {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+
+	return &handle{db: db}, nil
+}
+
+// createDBDriver is the callback provided during driver registration that
+// creates, initializes, and opens a database for use.  Anonymous databases are
+// discarded when closed while named databases are kept for the lifetime of the
+// process.
+func createDBDriver(args ...interface{}) (walletdb.DB, error) {
+	name, err := parseArgs("Create", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return ldb.OpenStorage(storage.NewMemStorage())
+	}
+
+	namedMtx.Lock()
+	defer namedMtx.Unlock()
+	if _, exists := named[name]; exists {
+		return nil, walletdb.ErrDbExists
+	}
+	db, err := ldb.OpenStorage(storage.NewMemStorage())
+	if err != nil {
+		return nil, err
+	}
+	named[name] = db
+	return &handle{db: db}, nil
+}
+
+func init() {
+	// Register the driver.
+	driver := walletdb.Driver{
+		DbType: dbType,
+		Create: createDBDriver,
+		Open:   openDBDriver,
+	}
+	if err := walletdb.RegisterDriver(driver); err != nil {
+		panic(fmt.Sprintf("Failed to register database driver '%s': %v",
+			dbType, err))
+	}
+}
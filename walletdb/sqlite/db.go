@@ -0,0 +1,1036 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/decred/dcrwallet/walletdb"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	// maxKeySize is the maximum length of a key.  It matches the limit
+	// imposed by bolt so data is portable between the drivers.
+	maxKeySize = 32768
+
+	// maxValueSize is the maximum length of a value.  It is the default
+	// maximum length of a SQLite blob.
+	maxValueSize = 1000000000
+
+	// rootBucketID is the ID of the bucket holding every namespace.
+	rootBucketID = 0
+)
+
+// schema holds the statements creating the tables of the database.
+//
+// The buckets table only allocates IDs for nested buckets.  Every key/value
+// pair and nested bucket is a row of the entries table keyed by the ID of the
+// bucket it belongs to.  Key/value pairs set the value column while nested
+// buckets set the child column to the ID of the nested bucket.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS buckets (
+	id INTEGER PRIMARY KEY AUTOINCREMENT
+)`,
+	`CREATE TABLE IF NOT EXISTS entries (
+	bucket INTEGER NOT NULL,
+	key BLOB NOT NULL,
+	value BLOB,
+	child INTEGER,
+	PRIMARY KEY (bucket, key)
+)`,
+}
+
+// selectNestedBuckets selects the IDs of a bucket and of all buckets nested
+// beneath it.
+const selectNestedBuckets = `WITH RECURSIVE nested(id) AS (
+	VALUES(?)
+	UNION ALL
+	SELECT entries.child FROM entries JOIN nested ON entries.bucket = nested.id
+	WHERE entries.child IS NOT NULL
+) SELECT id FROM nested`
+
+// bucket is an internal type used to represent a collection of key/value pairs
+// and implements the walletdb.Bucket interface.
+type bucket struct {
+	tx *transaction
+	id int64
+}
+
+// Enforce bucket implements the walletdb.Bucket interface.
+var _ walletdb.Bucket = (*bucket)(nil)
+
+// entry looks up the entry for key in the bucket.  A nil entry is returned
+// when there is no such entry.
+func (b *bucket) entry(key []byte) (*entry, error) {
+	if b.tx.closed {
+		return nil, walletdb.ErrTxClosed
+	}
+	row := b.tx.sqlTx.QueryRow("SELECT key, value, child FROM entries "+
+		"WHERE bucket = ? AND key = ?", b.id, key)
+	e, err := scanEntry(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, err
+}
+
+// Bucket retrieves a nested bucket with the given key.  Returns nil if
+// the bucket does not exist.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Bucket(key []byte) walletdb.Bucket {
+	// This nil check is intentional so the return value can be checked
+	// against nil directly.
+	nested := b.nestedBucket(key)
+	if nested == nil {
+		return nil
+	}
+	return nested
+}
+
+// nestedBucket returns the nested bucket with the given key, or nil if there is
+// no such bucket.
+func (b *bucket) nestedBucket(key []byte) *bucket {
+	e, err := b.entry(key)
+	if err != nil || e == nil || !e.child.Valid {
+		return nil
+	}
+	return &bucket{tx: b.tx, id: e.child.Int64}
+}
+
+// CreateBucket creates and returns a new nested bucket with the given key.
+// Returns ErrBucketExists if the bucket already exists, ErrBucketNameRequired
+// if the key is empty, or ErrIncompatibleValue if the key value is otherwise
+// invalid.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucket(key []byte) (walletdb.Bucket, error) {
+	if err := b.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(key) == 0:
+		return nil, walletdb.ErrBucketNameRequired
+	case len(key) > maxKeySize:
+		return nil, walletdb.ErrKeyTooLarge
+	}
+
+	e, err := b.entry(key)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil {
+		if e.child.Valid {
+			return nil, walletdb.ErrBucketExists
+		}
+		return nil, walletdb.ErrIncompatibleValue
+	}
+
+	res, err := b.tx.sqlTx.Exec("INSERT INTO buckets DEFAULT VALUES")
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	_, err = b.tx.sqlTx.Exec("INSERT INTO entries (bucket, key, child) "+
+		"VALUES (?, ?, ?)", b.id, key, id)
+	if err != nil {
+		return nil, err
+	}
+	return &bucket{tx: b.tx, id: id}, nil
+}
+
+// CreateBucketIfNotExists creates and returns a new nested bucket with the
+// given key if it does not already exist.  Returns ErrBucketNameRequired if the
+// key is empty or ErrIncompatibleValue if the key value is otherwise invalid.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) CreateBucketIfNotExists(key []byte) (walletdb.Bucket, error) {
+	nested, err := b.CreateBucket(key)
+	if err == walletdb.ErrBucketExists {
+		return b.Bucket(key), nil
+	}
+	return nested, err
+}
+
+// DeleteBucket removes a nested bucket with the given key.  Returns
+// ErrTxNotWritable if attempted against a read-only transaction and
+// ErrBucketNotFound if the specified bucket does not exist.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) DeleteBucket(key []byte) error {
+	if err := b.tx.checkWritable(); err != nil {
+		return err
+	}
+	if len(key) == 0 {
+		return walletdb.ErrIncompatibleValue
+	}
+
+	e, err := b.entry(key)
+	if err != nil {
+		return err
+	}
+	switch {
+	case e == nil:
+		return walletdb.ErrBucketNotFound
+	case !e.child.Valid:
+		return walletdb.ErrIncompatibleValue
+	}
+
+	// Find the bucket and every bucket nested beneath it, and remove all
+	// of their entries.
+	rows, err := b.tx.sqlTx.Query(selectNestedBuckets, e.child.Int64)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		_, err := b.tx.sqlTx.Exec("DELETE FROM entries WHERE bucket = ?",
+			id)
+		if err != nil {
+			return err
+		}
+		_, err = b.tx.sqlTx.Exec("DELETE FROM buckets WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = b.tx.sqlTx.Exec("DELETE FROM entries WHERE bucket = ? AND "+
+		"key = ?", b.id, key)
+	return err
+}
+
+// ForEach invokes the passed function with every key/value pair in the bucket.
+// This includes nested buckets, in which case the value is nil, but it does not
+// include the key/value pairs within those nested buckets.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) ForEach(fn func(k, v []byte) error) error {
+	if b.tx.closed {
+		return walletdb.ErrTxClosed
+	}
+
+	// Read all entries before invoking the function so it may query the
+	// transaction.
+	rows, err := b.tx.sqlTx.Query("SELECT key, value, child FROM entries "+
+		"WHERE bucket = ? ORDER BY key", b.id)
+	if err != nil {
+		return err
+	}
+	var entries []*entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := fn(e.pair()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writable returns whether or not the bucket is writable.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Writable() bool {
+	return b.tx.writable
+}
+
+// Put saves the specified key/value pair to the bucket.  Keys that do not
+// already exist are added and keys that already exist are overwritten.  Returns
+// ErrTxNotWritable if attempted against a read-only transaction.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Put(key, value []byte) error {
+	if err := b.tx.checkWritable(); err != nil {
+		return err
+	}
+	switch {
+	case len(key) == 0:
+		return walletdb.ErrKeyRequired
+	case len(key) > maxKeySize:
+		return walletdb.ErrKeyTooLarge
+	case len(value) > maxValueSize:
+		return walletdb.ErrValueTooLarge
+	}
+
+	e, err := b.entry(key)
+	if err != nil {
+		return err
+	}
+	if e != nil && e.child.Valid {
+		return walletdb.ErrIncompatibleValue
+	}
+
+	if value == nil {
+		value = []byte{}
+	}
+	_, err = b.tx.sqlTx.Exec("INSERT OR REPLACE INTO entries (bucket, key, "+
+		"value, child) VALUES (?, ?, ?, NULL)", b.id, key, value)
+	return err
+}
+
+// Get returns the value for the given key.  Returns nil if the key does
+// not exist in this bucket (or nested buckets).
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Get(key []byte) []byte {
+	e, err := b.entry(key)
+	if err != nil || e == nil {
+		return nil
+	}
+	_, value := e.pair()
+	return value
+}
+
+// Delete removes the specified key from the bucket.  Deleting a key that does
+// not exist does not return an error.  Returns ErrTxNotWritable if attempted
+// against a read-only transaction.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Delete(key []byte) error {
+	if err := b.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	e, err := b.entry(key)
+	switch {
+	case err != nil:
+		return err
+	case e == nil:
+		return nil
+	case e.child.Valid:
+		return walletdb.ErrIncompatibleValue
+	}
+
+	_, err = b.tx.sqlTx.Exec("DELETE FROM entries WHERE bucket = ? AND "+
+		"key = ?", b.id, key)
+	return err
+}
+
+// Cursor returns a new cursor, allowing for iteration over the bucket's
+// key/value pairs and nested buckets in forward or backward order.
+//
+// This function is part of the walletdb.Bucket interface implementation.
+func (b *bucket) Cursor() walletdb.Cursor {
+	return &cursor{bucket: b}
+}
+
+// entry is a row of the entries table.
+type entry struct {
+	key   []byte
+	value []byte
+	child sql.NullInt64
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEntry scans the key, value, and child columns of an entry.
+func scanEntry(s scanner) (*entry, error) {
+	e := new(entry)
+	err := s.Scan(&e.key, &e.value, &e.child)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// pair returns the key/value pair of the entry as returned by the walletdb
+// interfaces.  The value of nested buckets is nil while the value of an empty
+// key/value pair is empty but not nil.
+func (e *entry) pair() (key, value []byte) {
+	if e.child.Valid {
+		return e.key, nil
+	}
+	if e.value == nil {
+		return e.key, []byte{}
+	}
+	return e.key, e.value
+}
+
+// cursor represents a cursor over key/value pairs and nested buckets of a
+// bucket.  Each movement of the cursor queries the entry adjacent to the key
+// the cursor is positioned at.
+//
+// Note that open cursors are not tracked on bucket changes and any
+// modifications to the bucket, with the exception of cursor.Delete, invalidate
+// the cursor. After invalidation, the cursor must be repositioned, or the keys
+// and values returned may be unpredictable.
+type cursor struct {
+	bucket  *bucket
+	current *entry
+}
+
+// Enforce cursor implements the walletdb.Cursor interface.
+var _ walletdb.Cursor = (*cursor)(nil)
+
+// move positions the cursor at the entry selected by the query condition and
+// order and returns the pair.
+func (c *cursor) move(cond, order string, args ...interface{}) (key, value []byte) {
+	c.current = nil
+	if c.bucket.tx.closed {
+		return nil, nil
+	}
+
+	query := "SELECT key, value, child FROM entries WHERE bucket = ?" +
+		cond + " ORDER BY key " + order + " LIMIT 1"
+	args = append([]interface{}{c.bucket.id}, args...)
+	e, err := scanEntry(c.bucket.tx.sqlTx.QueryRow(query, args...))
+	if err != nil {
+		return nil, nil
+	}
+	c.current = e
+	return e.pair()
+}
+
+// Bucket returns the bucket the cursor was created for.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Bucket() walletdb.Bucket {
+	return c.bucket
+}
+
+// Delete removes the current key/value pair the cursor is at without
+// invalidating the cursor. Returns ErrTxNotWritable if attempted on a read-only
+// transaction, or ErrIncompatibleValue if attempted when the cursor points to a
+// nested bucket.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Delete() error {
+	if err := c.bucket.tx.checkWritable(); err != nil {
+		return err
+	}
+	if c.current == nil {
+		return nil
+	}
+	if c.current.child.Valid {
+		return walletdb.ErrIncompatibleValue
+	}
+
+	_, err := c.bucket.tx.sqlTx.Exec("DELETE FROM entries WHERE bucket = ? "+
+		"AND key = ?", c.bucket.id, c.current.key)
+	return err
+}
+
+// First positions the cursor at the first key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) First() (key, value []byte) {
+	return c.move("", "ASC")
+}
+
+// Last positions the cursor at the last key/value pair and returns the pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Last() (key, value []byte) {
+	return c.move("", "DESC")
+}
+
+// Next moves the cursor one key/value pair forward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Next() (key, value []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+	return c.move(" AND key > ?", "ASC", c.current.key)
+}
+
+// Prev moves the cursor one key/value pair backward and returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Prev() (key, value []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+	return c.move(" AND key < ?", "DESC", c.current.key)
+}
+
+// Seek positions the cursor at the passed seek key. If the key does not exist,
+// the cursor is moved to the next key after seek. Returns the new pair.
+//
+// This function is part of the walletdb.Cursor interface implementation.
+func (c *cursor) Seek(seek []byte) (key, value []byte) {
+	// Keys are never empty, so seeking an empty key is the same as moving
+	// to the first pair.
+	if len(seek) == 0 {
+		return c.First()
+	}
+	return c.move(" AND key >= ?", "ASC", seek)
+}
+
+// transaction represents a database transaction.  It can either by read-only or
+// read-write and implements the walletdb.Tx interface.  The transaction
+// provides a root bucket against which all read and writes occur.
+type transaction struct {
+	db       *db
+	sqlTx    *sql.Tx
+	root     *bucket
+	writable bool
+	managed  bool
+	closed   bool
+}
+
+// Enforce transaction implements the walletdb.Tx interface.
+var _ walletdb.Tx = (*transaction)(nil)
+
+// checkWritable returns an error if the transaction may not be written to.
+func (tx *transaction) checkWritable() error {
+	if tx.closed {
+		return walletdb.ErrTxClosed
+	}
+	if !tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	return nil
+}
+
+// close marks the transaction closed and allows the database to be closed
+// once no other transactions remain open.
+func (tx *transaction) close() {
+	tx.closed = true
+	tx.db.closeMtx.RUnlock()
+}
+
+// RootBucket returns the top-most bucket for the namespace the transaction was
+// created from.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) RootBucket() walletdb.Bucket {
+	return tx.root
+}
+
+// Commit commits all changes that have been made through the root bucket and
+// all of its sub-buckets to persistent storage.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Commit() error {
+	if tx.managed {
+		panic("managed tx commit not allowed")
+	}
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	err := tx.sqlTx.Commit()
+	tx.close()
+	return err
+}
+
+// Rollback undoes all changes that have been made to the root bucket and all of
+// its sub-buckets.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *transaction) Rollback() error {
+	if tx.managed {
+		panic("managed tx rollback not allowed")
+	}
+	if tx.closed {
+		return walletdb.ErrTxClosed
+	}
+
+	err := tx.sqlTx.Rollback()
+	tx.close()
+	return err
+}
+
+// namespace represents a database namespace that is inteded to support the
+// concept of a single entity that controls the opening, creating, and closing
+// of a database while providing other entities their own namespace to work in.
+// It implements the walletdb.Namespace interface.
+type namespace struct {
+	db  *db
+	key []byte
+}
+
+// Enforce namespace implements the walletdb.Namespace interface.
+var _ walletdb.Namespace = (*namespace)(nil)
+
+// begin starts a transaction rooted at the namespace bucket.
+func (ns *namespace) begin(writable bool) (*transaction, error) {
+	tx, err := ns.db.begin(writable)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &bucket{tx: tx, id: rootBucketID}
+	tx.root = root.nestedBucket(ns.key)
+	if tx.root == nil {
+		_ = tx.Rollback()
+		return nil, walletdb.ErrBucketNotFound
+	}
+
+	return tx, nil
+}
+
+// Begin starts a transaction which is either read-only or read-write depending
+// on the specified flag.  Multiple read-only transactions can be started
+// simultaneously while only a single read-write transaction can be started at a
+// time.  The call will block when starting a read-write transaction when one is
+// already open.
+//
+// NOTE: The transaction must be closed by calling Rollback or Commit on it when
+// it is no longer needed.  Failure to do so will block all further read-write
+// transactions.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) Begin(writable bool) (walletdb.Tx, error) {
+	return ns.begin(writable)
+}
+
+// View invokes the passed function in the context of a managed read-only
+// transaction.  Any errors returned from the user-supplied function are
+// returned from this function.
+//
+// Calling Rollback on the transaction passed to the user-supplied function will
+// result in a panic.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) View(fn func(walletdb.Tx) error) error {
+	tx, err := ns.begin(false)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the transaction is closed even if the user-supplied
+	// function panics.
+	defer func() {
+		tx.managed = false
+		if !tx.closed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	tx.managed = true
+	return fn(tx)
+}
+
+// Update invokes the passed function in the context of a managed read-write
+// transaction.  Any errors returned from the user-supplied function will cause
+// the transaction to be rolled back and are returned from this function.
+// Otherwise, the transaction is commited when the user-supplied function
+// returns a nil error.
+//
+// Calling Rollback on the transaction passed to the user-supplied function will
+// result in a panic.
+//
+// This function is part of the walletdb.Namespace interface implementation.
+func (ns *namespace) Update(fn func(walletdb.Tx) error) error {
+	tx, err := ns.begin(true)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the transaction is rolled back even if the user-supplied
+	// function panics.
+	defer func() {
+		tx.managed = false
+		if !tx.closed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	tx.managed = true
+	err = fn(tx)
+	tx.managed = false
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// db represents a collection of namespaces which are persisted and implements
+// the walletdb.Db interface.  All database access is performed through
+// transactions which are obtained through the specific Namespace.
+type db struct {
+	// reader is used for read-only transactions.  writer is used for
+	// read-write transactions and is limited to a single connection which
+	// begins its transactions holding the database write lock, so only a
+	// single read-write transaction may be open at a time.  writer is nil
+	// for databases opened without permitting any modifications.
+	reader *sql.DB
+	writer *sql.DB
+
+	// closeMtx is read locked by every open transaction so that closing
+	// the database waits for them to finish.
+	closeMtx sync.RWMutex
+	closed   bool
+}
+
+// Enforce db implements the walletdb.Db and walletdb.NamespacesViewer
+// interfaces.
+var (
+	_ walletdb.DB               = (*db)(nil)
+	_ walletdb.NamespacesViewer = (*db)(nil)
+)
+
+// begin starts a transaction over the whole database.
+func (db *db) begin(writable bool) (*transaction, error) {
+	db.closeMtx.RLock()
+	if db.closed {
+		db.closeMtx.RUnlock()
+		return nil, walletdb.ErrDbNotOpen
+	}
+	if writable && db.writer == nil {
+		db.closeMtx.RUnlock()
+		return nil, walletdb.ErrDbReadOnly
+	}
+
+	conn := db.reader
+	if writable {
+		conn = db.writer
+	}
+	sqlTx, err := conn.Begin()
+	if err != nil {
+		db.closeMtx.RUnlock()
+		return nil, err
+	}
+
+	return &transaction{db: db, sqlTx: sqlTx, writable: writable}, nil
+}
+
+// Namespace returns a Namespace interface for the provided key.  See the
+// Namespace interface documentation for more details.  Attempting to access a
+// Namespace on a database that is not open yet or has been closed will result
+// in ErrDbNotOpen.  Namespaces are created in the database on first access.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Namespace(key []byte) (walletdb.Namespace, error) {
+	// Check if the namespace needs to be created using a read-only
+	// transaction.  This is done because read-only transactions are faster
+	// and don't block like write transactions.
+	tx, err := db.begin(false)
+	if err != nil {
+		return nil, err
+	}
+	root := &bucket{tx: tx, id: rootBucketID}
+	doCreate := root.nestedBucket(key) == nil
+	if err := tx.Rollback(); err != nil {
+		return nil, err
+	}
+
+	// Namespaces can not be created in read-only databases.
+	if doCreate && db.writer == nil {
+		return nil, walletdb.ErrBucketNotFound
+	}
+
+	// Create the namespace if needed by using a writable transaction.
+	if doCreate {
+		tx, err := db.begin(true)
+		if err != nil {
+			return nil, err
+		}
+		root := &bucket{tx: tx, id: rootBucketID}
+		if _, err := root.CreateBucketIfNotExists(key); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+
+	ns := &namespace{db: db, key: make([]byte, len(key))}
+	copy(ns.key, key)
+	return ns, nil
+}
+
+// DeleteNamespace deletes the namespace for the passed key.  ErrBucketNotFound
+// will be returned if the namespace does not exist.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) DeleteNamespace(key []byte) error {
+	tx, err := db.begin(true)
+	if err != nil {
+		return err
+	}
+	root := &bucket{tx: tx, id: rootBucketID}
+	if err := root.DeleteBucket(key); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// viewTx is a managed read-only transaction rooted at one of the namespaces
+// viewed by ViewNamespaces.  The transactions of all viewed namespaces share a
+// single underlying transaction.  It implements the walletdb.Tx interface.
+type viewTx struct {
+	root *bucket
+}
+
+// RootBucket returns the top-most bucket for the namespace the transaction was
+// created for.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *viewTx) RootBucket() walletdb.Bucket {
+	return tx.root
+}
+
+// Commit panics since the transaction is managed by ViewNamespaces.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *viewTx) Commit() error {
+	panic("managed tx commit not allowed")
+}
+
+// Rollback panics since the transaction is managed by ViewNamespaces.
+//
+// This function is part of the walletdb.Tx interface implementation.
+func (tx *viewTx) Rollback() error {
+	panic("managed tx rollback not allowed")
+}
+
+// ViewNamespaces invokes the passed function with a managed read-only
+// transaction of each namespace of keys, all viewing the same state of the
+// database.  ErrBucketNotFound is returned if any of the namespaces does not
+// exist.
+//
+// This function is part of the walletdb.NamespacesViewer interface
+// implementation.
+func (db *db) ViewNamespaces(keys [][]byte, fn func([]walletdb.Tx) error) error {
+	tx, err := db.begin(false)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the transaction is closed even if the user-supplied
+	// function panics.
+	defer func() {
+		if !tx.closed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	root := &bucket{tx: tx, id: rootBucketID}
+	txs := make([]walletdb.Tx, len(keys))
+	for i, key := range keys {
+		nsRoot := root.nestedBucket(key)
+		if nsRoot == nil {
+			return walletdb.ErrBucketNotFound
+		}
+		txs[i] = &viewTx{root: nsRoot}
+	}
+	return fn(txs)
+}
+
+// Copy writes a copy of the database to the provided writer.  This call will
+// start a read-only transaction to perform all operations.
+//
+// The copy is written as SQL statements which recreate the database when
+// executed against an empty SQLite database, for example with:
+//
+//	sqlite3 wallet-copy.sqlite < wallet-copy.sql
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Copy(w io.Writer) error {
+	tx, err := db.begin(false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := io.WriteString(w, "BEGIN TRANSACTION;\n"); err != nil {
+		return err
+	}
+	for _, stmt := range schema {
+		if _, err := fmt.Fprintf(w, "%s;\n", stmt); err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.sqlTx.Query("SELECT id FROM buckets ORDER BY id")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		_, err := fmt.Fprintf(w, "INSERT INTO buckets (id) VALUES (%d);\n",
+			id)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = tx.sqlTx.Query("SELECT bucket, key, value, child FROM entries " +
+		"ORDER BY bucket, key")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bucketID int64
+		e := new(entry)
+		if err := rows.Scan(&bucketID, &e.key, &e.value, &e.child); err != nil {
+			return err
+		}
+		value, child := "NULL", "NULL"
+		if e.child.Valid {
+			child = fmt.Sprintf("%d", e.child.Int64)
+		} else {
+			value = fmt.Sprintf("X'%x'", e.value)
+		}
+		_, err := fmt.Fprintf(w, "INSERT INTO entries (bucket, key, "+
+			"value, child) VALUES (%d, X'%x', %s, %s);\n", bucketID,
+			e.key, value, child)
+		if err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "COMMIT;\n")
+	return err
+}
+
+// Close cleanly shuts down the database and syncs all data.  It blocks until
+// all open transactions are closed.
+//
+// This function is part of the walletdb.Db interface implementation.
+func (db *db) Close() error {
+	db.closeMtx.Lock()
+	defer db.closeMtx.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+
+	var writerErr error
+	if db.writer != nil {
+		writerErr = db.writer.Close()
+	}
+	readerErr := db.reader.Close()
+	if writerErr != nil {
+		return writerErr
+	}
+	return readerErr
+}
+
+// fileExists reports whether the named file or directory exists.
+func fileExists(name string) bool {
+	if _, err := os.Stat(name); err != nil {
+		if os.IsNotExist(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// openDB opens the database at the provided path.  walletdb.ErrDbDoesNotExist
+// is returned if the database doesn't exist and the create flag is not set.
+func openDB(dbPath string, create bool) (walletdb.DB, error) {
+	if !create && !fileExists(dbPath) {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+
+	// Readers never block on the writer in WAL mode, but the busy timeout
+	// allows waiting on checkpoints and other processes inspecting the
+	// database.
+	dsn := dbPath + "?_busy_timeout=10000"
+	writer, err := sql.Open("sqlite3", dsn+"&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+	writer.SetMaxOpenConns(1)
+
+	// Switch the database to WAL mode, which is persisted in the database
+	// file, and create the tables when they do not exist yet.
+	stmts := append([]string{"PRAGMA journal_mode=WAL"}, schema...)
+	for _, stmt := range stmts {
+		if _, err := writer.Exec(stmt); err != nil {
+			writer.Close()
+			return nil, err
+		}
+	}
+
+	reader, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	return &db{reader: reader, writer: writer}, nil
+}
+
+// openReadOnlyDB opens the database at the provided path without permitting
+// any modifications.  Since the database is in WAL mode, it may be opened
+// read-only while another process has it open for writing, and its read-only
+// transactions never block the writer.
+func openReadOnlyDB(dbPath string) (walletdb.DB, error) {
+	if !fileExists(dbPath) {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+
+	// The mode parameter is only recognized in URI filenames.
+	dsn := "file:" + dbPath + "?mode=ro&_busy_timeout=10000"
+	reader, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the database can be read since sql.Open does not connect.
+	if err := reader.Ping(); err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	return &db{reader: reader}, nil
+}
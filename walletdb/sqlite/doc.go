@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+/*
+Package sqlite implements an instance of walletdb that uses SQLite for the
+backing datastore.
+
+Usage
+
+This package is only a driver to the walletdb package and provides the database
+type of "sqlite".  The only parameter the Open and Create functions take is the
+database path as a string:
+
+	db, err := walletdb.Open("sqlite", "path/to/database.sqlite")
+	if err != nil {
+		// Handle error
+	}
+
+	db, err := walletdb.Create("sqlite", "path/to/database.sqlite")
+	if err != nil {
+		// Handle error
+	}
+
+The database may also be opened with walletdb.OpenReadOnly, including while
+another process has it open for writing, such as a reporting process
+inspecting the wallet of a running daemon.
+
+Storage Layout
+
+The database is a single SQLite file in WAL mode, so read-only transactions do
+not block on the read-write transaction.  It can be opened with standard SQLite
+tools for backups and inspection.  Two tables are used:
+
+	buckets (id INTEGER PRIMARY KEY AUTOINCREMENT)
+	entries (bucket INTEGER, key BLOB, value BLOB, child INTEGER)
+
+Every key/value pair and nested bucket is a row of the entries table keyed by
+the ID of the bucket it belongs to.  Nested buckets set the child column to
+their own ID, allocated from the buckets table.  Namespaces are the nested
+buckets of the root bucket, which has ID 0.  For example, the key/value pairs
+directly within the waddrmgr namespace are listed by:
+
+	SELECT hex(key), hex(value) FROM entries WHERE bucket =
+		(SELECT child FROM entries WHERE bucket = 0 AND key = CAST('waddrmgr' AS BLOB));
+
+The copy written by DB.Copy is a SQL script which recreates the database when
+run against an empty SQLite database.
+*/
+package sqlite
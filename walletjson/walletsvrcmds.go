@@ -0,0 +1,794 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package walletjson provides the types of the wallet JSON-RPC commands,
+// results, and notifications which are specific to dcrwallet and are not
+// provided by dcrjson.  The commands and notifications are registered with
+// dcrjson when the package is imported, so they may be marshalled and
+// unmarshalled by dcrjson like any other command.
+package walletjson
+
+import "github.com/decred/dcrd/dcrjson"
+
+// ApproveSendCmd defines the approvesend JSON-RPC command.  ID is the id of
+// the pending send, and Signature is an optional approval token signed by the
+// wallet's approval address.
+type ApproveSendCmd struct {
+	ID        string
+	Signature *string
+}
+
+// NewApproveSendCmd returns a new instance which can be used to issue an
+// approvesend JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewApproveSendCmd(id string, signature *string) *ApproveSendCmd {
+	return &ApproveSendCmd{
+		ID:        id,
+		Signature: signature,
+	}
+}
+
+// CancelRescanCmd defines the cancelrescan JSON-RPC command.
+type CancelRescanCmd struct{}
+
+// NewCancelRescanCmd returns a new instance which can be used to issue a
+// cancelrescan JSON-RPC command.
+func NewCancelRescanCmd() *CancelRescanCmd {
+	return &CancelRescanCmd{}
+}
+
+// CreateMultisigWalletCmd defines the createmultisigwallet JSON-RPC command.
+// Keys are the public keys or account extended public keys of the cosigners,
+// and Count is the number of scripts created when any key is an extended
+// public key.
+type CreateMultisigWalletCmd struct {
+	NRequired int
+	Keys      []string
+	Count     *uint32 `jsonrpcdefault:"20"`
+}
+
+// NewCreateMultisigWalletCmd returns a new instance which can be used to
+// issue a createmultisigwallet JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCreateMultisigWalletCmd(nRequired int, keys []string,
+	count *uint32) *CreateMultisigWalletCmd {
+	return &CreateMultisigWalletCmd{
+		NRequired: nRequired,
+		Keys:      keys,
+		Count:     count,
+	}
+}
+
+// CreatePaymentURICmd defines the createpaymenturi JSON-RPC command.  Expiry
+// is the number of seconds the payment request is valid for, or zero if it
+// never expires.
+type CreatePaymentURICmd struct {
+	Account *string `jsonrpcdefault:"\"default\""`
+	Amount  *float64
+	Label   *string
+	Message *string
+	Expiry  *int64 `jsonrpcdefault:"0"`
+}
+
+// NewCreatePaymentURICmd returns a new instance which can be used to issue a
+// createpaymenturi JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCreatePaymentURICmd(account *string, amount *float64, label,
+	message *string, expiry *int64) *CreatePaymentURICmd {
+	return &CreatePaymentURICmd{
+		Account: account,
+		Amount:  amount,
+		Label:   label,
+		Message: message,
+		Expiry:  expiry,
+	}
+}
+
+// DecodeAddressCmd defines the decodeaddress JSON-RPC command.
+type DecodeAddressCmd struct {
+	Address string
+}
+
+// NewDecodeAddressCmd returns a new instance which can be used to issue a
+// decodeaddress JSON-RPC command.
+func NewDecodeAddressCmd(address string) *DecodeAddressCmd {
+	return &DecodeAddressCmd{
+		Address: address,
+	}
+}
+
+// DecodePaymentURICmd defines the decodepaymenturi JSON-RPC command.
+type DecodePaymentURICmd struct {
+	URI string
+}
+
+// NewDecodePaymentURICmd returns a new instance which can be used to issue a
+// decodepaymenturi JSON-RPC command.
+func NewDecodePaymentURICmd(uri string) *DecodePaymentURICmd {
+	return &DecodePaymentURICmd{
+		URI: uri,
+	}
+}
+
+// DescribeScriptCmd defines the describescript JSON-RPC command.  Script is a
+// hex-encoded output script of the script version Version.
+type DescribeScriptCmd struct {
+	Script  string
+	Version *uint16 `jsonrpcdefault:"0"`
+}
+
+// NewDescribeScriptCmd returns a new instance which can be used to issue a
+// describescript JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewDescribeScriptCmd(script string, version *uint16) *DescribeScriptCmd {
+	return &DescribeScriptCmd{
+		Script:  script,
+		Version: version,
+	}
+}
+
+// EstimateStakeDiffCmd defines the estimatestakediff JSON-RPC command.
+type EstimateStakeDiffCmd struct{}
+
+// NewEstimateStakeDiffCmd returns a new instance which can be used to issue
+// an estimatestakediff JSON-RPC command.
+func NewEstimateStakeDiffCmd() *EstimateStakeDiffCmd {
+	return &EstimateStakeDiffCmd{}
+}
+
+// ExportAccountHistoryCmd defines the exportaccounthistory JSON-RPC command.
+// Format is "csv" or "jsonl", Cursor is the nextcursor of a previous reply to
+// resume the history after, and Count limits the number of exported
+// transactions.
+type ExportAccountHistoryCmd struct {
+	Account string
+	Format  *string `jsonrpcdefault:"\"csv\""`
+	Cursor  *string
+	Count   *int `jsonrpcdefault:"1000"`
+}
+
+// NewExportAccountHistoryCmd returns a new instance which can be used to issue
+// an exportaccounthistory JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewExportAccountHistoryCmd(account string, format, cursor *string,
+	count *int) *ExportAccountHistoryCmd {
+	return &ExportAccountHistoryCmd{
+		Account: account,
+		Format:  format,
+		Cursor:  cursor,
+		Count:   count,
+	}
+}
+
+// ExportCapitalGainsCmd defines the exportcapitalgains JSON-RPC command.  Year
+// is the calendar year of the report, and Method is the lot matching method:
+// "fifo", "lifo", or "specific".
+type ExportCapitalGainsCmd struct {
+	Year   int
+	Method *string `jsonrpcdefault:"\"fifo\""`
+}
+
+// NewExportCapitalGainsCmd returns a new instance which can be used to issue
+// an exportcapitalgains JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewExportCapitalGainsCmd(year int, method *string) *ExportCapitalGainsCmd {
+	return &ExportCapitalGainsCmd{
+		Year:   year,
+		Method: method,
+	}
+}
+
+// ExportSigningLogCmd defines the exportsigninglog JSON-RPC command.  Start
+// is the sequence number of the first exported record, and Count limits the
+// number of exported records.
+type ExportSigningLogCmd struct {
+	Start *uint64 `jsonrpcdefault:"0"`
+	Count *int    `jsonrpcdefault:"1000"`
+}
+
+// NewExportSigningLogCmd returns a new instance which can be used to issue an
+// exportsigninglog JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewExportSigningLogCmd(start *uint64, count *int) *ExportSigningLogCmd {
+	return &ExportSigningLogCmd{
+		Start: start,
+		Count: count,
+	}
+}
+
+// ExportSeedBackupCmd defines the exportseedbackup JSON-RPC command.
+// Passphrase is the passphrase the seed backup file is encrypted with.
+type ExportSeedBackupCmd struct {
+	Passphrase string
+}
+
+// NewExportSeedBackupCmd returns a new instance which can be used to issue an
+// exportseedbackup JSON-RPC command.
+func NewExportSeedBackupCmd(passphrase string) *ExportSeedBackupCmd {
+	return &ExportSeedBackupCmd{
+		Passphrase: passphrase,
+	}
+}
+
+// GetAddressPathCmd defines the getaddresspath JSON-RPC command.
+type GetAddressPathCmd struct {
+	Address string
+}
+
+// NewGetAddressPathCmd returns a new instance which can be used to issue a
+// getaddresspath JSON-RPC command.
+func NewGetAddressPathCmd(address string) *GetAddressPathCmd {
+	return &GetAddressPathCmd{
+		Address: address,
+	}
+}
+
+// GetAPIInfoCmd defines the getapiinfo JSON-RPC command.  APIVersion is the
+// version of the wallet JSON-RPC API the client was written against.
+type GetAPIInfoCmd struct {
+	APIVersion *string
+}
+
+// NewGetAPIInfoCmd returns a new instance which can be used to issue a
+// getapiinfo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAPIInfoCmd(apiVersion *string) *GetAPIInfoCmd {
+	return &GetAPIInfoCmd{
+		APIVersion: apiVersion,
+	}
+}
+
+// GetBackendStateCmd defines the getbackendstate JSON-RPC command.
+type GetBackendStateCmd struct{}
+
+// NewGetBackendStateCmd returns a new instance which can be used to issue a
+// getbackendstate JSON-RPC command.
+func NewGetBackendStateCmd() *GetBackendStateCmd {
+	return &GetBackendStateCmd{}
+}
+
+// GetBalanceHistoryCmd defines the getbalancehistory JSON-RPC command.
+// StartHeight and EndHeight bound the heights of the returned balance changes,
+// and Interval is either "block" or "day".
+type GetBalanceHistoryCmd struct {
+	StartHeight *int32 `jsonrpcdefault:"0"`
+	EndHeight   *int32
+	Interval    *string `jsonrpcdefault:"\"block\""`
+}
+
+// NewGetBalanceHistoryCmd returns a new instance which can be used to issue a
+// getbalancehistory JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBalanceHistoryCmd(startHeight, endHeight *int32,
+	interval *string) *GetBalanceHistoryCmd {
+	return &GetBalanceHistoryCmd{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		Interval:    interval,
+	}
+}
+
+// GetBlockVoteBitsCmd defines the getblockvotebits JSON-RPC command.
+// StartHeight and EndHeight bound the heights of the returned blocks.
+type GetBlockVoteBitsCmd struct {
+	StartHeight int32
+	EndHeight   *int32
+}
+
+// NewGetBlockVoteBitsCmd returns a new instance which can be used to issue a
+// getblockvotebits JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockVoteBitsCmd(startHeight int32, endHeight *int32) *GetBlockVoteBitsCmd {
+	return &GetBlockVoteBitsCmd{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+}
+
+// GetCreditOriginCmd defines the getcreditorigin JSON-RPC command.
+type GetCreditOriginCmd struct {
+	TxID string
+	Vout uint32
+}
+
+// NewGetCreditOriginCmd returns a new instance which can be used to issue a
+// getcreditorigin JSON-RPC command.
+func NewGetCreditOriginCmd(txID string, vout uint32) *GetCreditOriginCmd {
+	return &GetCreditOriginCmd{
+		TxID: txID,
+		Vout: vout,
+	}
+}
+
+// GetFeesReportCmd defines the getfeesreport JSON-RPC command.  StartTime and
+// EndTime are Unix times bounding the reported transactions.
+type GetFeesReportCmd struct {
+	StartTime *int64
+	EndTime   *int64
+}
+
+// NewGetFeesReportCmd returns a new instance which can be used to issue a
+// getfeesreport JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetFeesReportCmd(startTime, endTime *int64) *GetFeesReportCmd {
+	return &GetFeesReportCmd{
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+}
+
+// GetLockInfoCmd defines the getlockinfo JSON-RPC command.
+type GetLockInfoCmd struct{}
+
+// NewGetLockInfoCmd returns a new instance which can be used to issue a
+// getlockinfo JSON-RPC command.
+func NewGetLockInfoCmd() *GetLockInfoCmd {
+	return &GetLockInfoCmd{}
+}
+
+// GetImportedBalanceCmd defines the getimportedbalance JSON-RPC command.
+// MinConf and BalanceType select the balance as for the getbalance command.
+type GetImportedBalanceCmd struct {
+	MinConf     *int    `jsonrpcdefault:"1"`
+	BalanceType *string `jsonrpcdefault:"\"spendable\""`
+}
+
+// NewGetImportedBalanceCmd returns a new instance which can be used to issue
+// a getimportedbalance JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetImportedBalanceCmd(minConf *int,
+	balanceType *string) *GetImportedBalanceCmd {
+	return &GetImportedBalanceCmd{
+		MinConf:     minConf,
+		BalanceType: balanceType,
+	}
+}
+
+// GetJobStatusCmd defines the getjobstatus JSON-RPC command.
+type GetJobStatusCmd struct {
+	JobID int64
+}
+
+// NewGetJobStatusCmd returns a new instance which can be used to issue a
+// getjobstatus JSON-RPC command.
+func NewGetJobStatusCmd(jobID int64) *GetJobStatusCmd {
+	return &GetJobStatusCmd{
+		JobID: jobID,
+	}
+}
+
+// GetTicketBuyerLogCmd defines the getticketbuyerlog JSON-RPC command.  Count
+// is the number of most recent ticket buyer decisions to return.
+type GetTicketBuyerLogCmd struct {
+	Count *int `jsonrpcdefault:"100"`
+}
+
+// NewGetTicketBuyerLogCmd returns a new instance which can be used to issue a
+// getticketbuyerlog JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetTicketBuyerLogCmd(count *int) *GetTicketBuyerLogCmd {
+	return &GetTicketBuyerLogCmd{
+		Count: count,
+	}
+}
+
+// GetTicketPoolShareCmd defines the getticketpoolshare JSON-RPC command.  Days
+// is the number of days for which the probability of a vote is reported.
+type GetTicketPoolShareCmd struct {
+	Days *int `jsonrpcdefault:"30"`
+}
+
+// NewGetTicketPoolShareCmd returns a new instance which can be used to issue
+// a getticketpoolshare JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetTicketPoolShareCmd(days *int) *GetTicketPoolShareCmd {
+	return &GetTicketPoolShareCmd{
+		Days: days,
+	}
+}
+
+// ImportPubKeyCmd defines the importpubkey JSON-RPC command.  PubKey is a hex
+// encoded public key, and Rescan selects whether the chain is rescanned for
+// outputs paying to its address.
+type ImportPubKeyCmd struct {
+	PubKey string
+	Rescan *bool `jsonrpcdefault:"true"`
+}
+
+// NewImportPubKeyCmd returns a new instance which can be used to issue an
+// importpubkey JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewImportPubKeyCmd(pubKey string, rescan *bool) *ImportPubKeyCmd {
+	return &ImportPubKeyCmd{
+		PubKey: pubKey,
+		Rescan: rescan,
+	}
+}
+
+// ListAddressTicketsCmd defines the listaddresstickets JSON-RPC command.
+type ListAddressTicketsCmd struct {
+	Address string
+}
+
+// NewListAddressTicketsCmd returns a new instance which can be used to issue
+// a listaddresstickets JSON-RPC command.
+func NewListAddressTicketsCmd(address string) *ListAddressTicketsCmd {
+	return &ListAddressTicketsCmd{
+		Address: address,
+	}
+}
+
+// ListAddressUsageCmd defines the listaddressusage JSON-RPC command.  Account
+// limits the report to the addresses of an account, and all addresses are
+// reported when it is nil or "*".
+type ListAddressUsageCmd struct {
+	Account *string
+}
+
+// NewListAddressUsageCmd returns a new instance which can be used to issue a
+// listaddressusage JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewListAddressUsageCmd(account *string) *ListAddressUsageCmd {
+	return &ListAddressUsageCmd{
+		Account: account,
+	}
+}
+
+// ListJobsCmd defines the listjobs JSON-RPC command.
+type ListJobsCmd struct{}
+
+// NewListJobsCmd returns a new instance which can be used to issue a listjobs
+// JSON-RPC command.
+func NewListJobsCmd() *ListJobsCmd {
+	return &ListJobsCmd{}
+}
+
+// ListPendingSendsCmd defines the listpendingsends JSON-RPC command.
+type ListPendingSendsCmd struct{}
+
+// NewListPendingSendsCmd returns a new instance which can be used to issue a
+// listpendingsends JSON-RPC command.
+func NewListPendingSendsCmd() *ListPendingSendsCmd {
+	return &ListPendingSendsCmd{}
+}
+
+// ListRetiredAddressesCmd defines the listretiredaddresses JSON-RPC command.
+type ListRetiredAddressesCmd struct{}
+
+// NewListRetiredAddressesCmd returns a new instance which can be used to
+// issue a listretiredaddresses JSON-RPC command.
+func NewListRetiredAddressesCmd() *ListRetiredAddressesCmd {
+	return &ListRetiredAddressesCmd{}
+}
+
+// ListVSPTicketsCmd defines the listvsptickets JSON-RPC command.
+type ListVSPTicketsCmd struct{}
+
+// NewListVSPTicketsCmd returns a new instance which can be used to issue a
+// listvsptickets JSON-RPC command.
+func NewListVSPTicketsCmd() *ListVSPTicketsCmd {
+	return &ListVSPTicketsCmd{}
+}
+
+// LoadWalletCmd defines the loadwallet JSON-RPC command.
+type LoadWalletCmd struct {
+	Name string
+}
+
+// NewLoadWalletCmd returns a new instance which can be used to issue a
+// loadwallet JSON-RPC command.
+func NewLoadWalletCmd(name string) *LoadWalletCmd {
+	return &LoadWalletCmd{
+		Name: name,
+	}
+}
+
+// NotifyConfirmationsCmd defines the notifyconfirmations JSON-RPC command.
+// The client is notified once the transaction TxID has reached Confirmations
+// confirmations.
+type NotifyConfirmationsCmd struct {
+	TxID          string
+	Confirmations int32
+}
+
+// NewNotifyConfirmationsCmd returns a new instance which can be used to issue
+// a notifyconfirmations JSON-RPC command.
+func NewNotifyConfirmationsCmd(txID string, confirmations int32) *NotifyConfirmationsCmd {
+	return &NotifyConfirmationsCmd{
+		TxID:          txID,
+		Confirmations: confirmations,
+	}
+}
+
+// PurchaseVSPTicketsCmd defines the purchasevsptickets JSON-RPC command.
+type PurchaseVSPTicketsCmd struct {
+	Count      int
+	MinBalance *float64 `jsonrpcdefault:"0"`
+	MinConf    *int
+}
+
+// NewPurchaseVSPTicketsCmd returns a new instance which can be used to issue
+// a purchasevsptickets JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewPurchaseVSPTicketsCmd(count int, minBalance *float64,
+	minConf *int) *PurchaseVSPTicketsCmd {
+	return &PurchaseVSPTicketsCmd{
+		Count:      count,
+		MinBalance: minBalance,
+		MinConf:    minConf,
+	}
+}
+
+// RegisterVSPCmd defines the registervsp JSON-RPC command.  The blockchain
+// is rescanned from RescanFrom for tickets already purchased with the
+// imported script, or from the best block when it is not set.
+type RegisterVSPCmd struct {
+	RescanFrom *int32
+}
+
+// NewRegisterVSPCmd returns a new instance which can be used to issue a
+// registervsp JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewRegisterVSPCmd(rescanFrom *int32) *RegisterVSPCmd {
+	return &RegisterVSPCmd{
+		RescanFrom: rescanFrom,
+	}
+}
+
+// RejectSendCmd defines the rejectsend JSON-RPC command.
+type RejectSendCmd struct {
+	ID string
+}
+
+// NewRejectSendCmd returns a new instance which can be used to issue a
+// rejectsend JSON-RPC command.
+func NewRejectSendCmd(id string) *RejectSendCmd {
+	return &RejectSendCmd{
+		ID: id,
+	}
+}
+
+// RotateImportedKeyCmd defines the rotateimportedkey JSON-RPC command.  The
+// funds of the imported address are swept to a new internal address of
+// Account, and the address is retired, only when Confirm is set.
+type RotateImportedKeyCmd struct {
+	Address string
+	Account *string `jsonrpcdefault:"\"default\""`
+	Confirm *bool   `jsonrpcdefault:"false"`
+}
+
+// NewRotateImportedKeyCmd returns a new instance which can be used to issue a
+// rotateimportedkey JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewRotateImportedKeyCmd(address string, account *string,
+	confirm *bool) *RotateImportedKeyCmd {
+	return &RotateImportedKeyCmd{
+		Address: address,
+		Account: account,
+		Confirm: confirm,
+	}
+}
+
+// RescanWalletCmd defines the rescanwallet JSON-RPC command.  The rescan
+// begins at BeginTime instead of BeginHeight when BeginTime is set.
+type RescanWalletCmd struct {
+	BeginHeight *int32 `jsonrpcdefault:"0"`
+	BeginTime   *int64
+}
+
+// NewRescanWalletCmd returns a new instance which can be used to issue a
+// rescanwallet JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewRescanWalletCmd(beginHeight *int32, beginTime *int64) *RescanWalletCmd {
+	return &RescanWalletCmd{
+		BeginHeight: beginHeight,
+		BeginTime:   beginTime,
+	}
+}
+
+// SetBirthdayCmd defines the setbirthday JSON-RPC command.  Birthday is a Unix
+// time.
+type SetBirthdayCmd struct {
+	Birthday int64
+}
+
+// NewSetBirthdayCmd returns a new instance which can be used to issue a
+// setbirthday JSON-RPC command.
+func NewSetBirthdayCmd(birthday int64) *SetBirthdayCmd {
+	return &SetBirthdayCmd{
+		Birthday: birthday,
+	}
+}
+
+// SetCreditOriginCmd defines the setcreditorigin JSON-RPC command.  Origin is
+// one of "normal", "mixed", "swap", or "poolreward".
+type SetCreditOriginCmd struct {
+	TxID   string
+	Vout   uint32
+	Origin string
+}
+
+// NewSetCreditOriginCmd returns a new instance which can be used to issue a
+// setcreditorigin JSON-RPC command.
+func NewSetCreditOriginCmd(txID string, vout uint32,
+	origin string) *SetCreditOriginCmd {
+	return &SetCreditOriginCmd{
+		TxID:   txID,
+		Vout:   vout,
+		Origin: origin,
+	}
+}
+
+// SetUnlockTimeoutCmd defines the setunlocktimeout JSON-RPC command.
+type SetUnlockTimeoutCmd struct {
+	Timeout int64
+}
+
+// NewSetUnlockTimeoutCmd returns a new instance which can be used to issue a
+// setunlocktimeout JSON-RPC command.
+func NewSetUnlockTimeoutCmd(timeout int64) *SetUnlockTimeoutCmd {
+	return &SetUnlockTimeoutCmd{
+		Timeout: timeout,
+	}
+}
+
+// StartJobCmd defines the startjob JSON-RPC command.  Method and Params
+// describe the request which is run as a job.
+type StartJobCmd struct {
+	Method string
+	Params *[]interface{}
+}
+
+// NewStartJobCmd returns a new instance which can be used to issue a startjob
+// JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewStartJobCmd(method string, params *[]interface{}) *StartJobCmd {
+	return &StartJobCmd{
+		Method: method,
+		Params: params,
+	}
+}
+
+// UnloadWalletCmd defines the unloadwallet JSON-RPC command.
+type UnloadWalletCmd struct {
+	Name string
+}
+
+// NewUnloadWalletCmd returns a new instance which can be used to issue an
+// unloadwallet JSON-RPC command.
+func NewUnloadWalletCmd(name string) *UnloadWalletCmd {
+	return &UnloadWalletCmd{
+		Name: name,
+	}
+}
+
+func init() {
+	// The commands in this file are only usable with a wallet server.
+	flags := dcrjson.UFWalletOnly
+
+	dcrjson.MustRegisterCmd("approvesend", (*ApproveSendCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("cancelrescan", (*CancelRescanCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("createmultisigwallet",
+		(*CreateMultisigWalletCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("createpaymenturi",
+		(*CreatePaymentURICmd)(nil), flags)
+	dcrjson.MustRegisterCmd("decodeaddress", (*DecodeAddressCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("decodepaymenturi",
+		(*DecodePaymentURICmd)(nil), flags)
+	dcrjson.MustRegisterCmd("describescript", (*DescribeScriptCmd)(nil),
+		flags)
+	dcrjson.MustRegisterCmd("estimatestakediff",
+		(*EstimateStakeDiffCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("exportaccounthistory",
+		(*ExportAccountHistoryCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("exportcapitalgains",
+		(*ExportCapitalGainsCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("exportsigninglog", (*ExportSigningLogCmd)(nil),
+		flags)
+	dcrjson.MustRegisterCmd("exportseedbackup",
+		(*ExportSeedBackupCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getaddresspath", (*GetAddressPathCmd)(nil),
+		flags)
+	dcrjson.MustRegisterCmd("getapiinfo", (*GetAPIInfoCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getbackendstate", (*GetBackendStateCmd)(nil),
+		flags)
+	dcrjson.MustRegisterCmd("getbalancehistory",
+		(*GetBalanceHistoryCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getblockvotebits",
+		(*GetBlockVoteBitsCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getcreditorigin", (*GetCreditOriginCmd)(nil),
+		flags)
+	dcrjson.MustRegisterCmd("getfeesreport", (*GetFeesReportCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getimportedbalance",
+		(*GetImportedBalanceCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getjobstatus", (*GetJobStatusCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getlockinfo", (*GetLockInfoCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getticketbuyerlog",
+		(*GetTicketBuyerLogCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("getticketpoolshare",
+		(*GetTicketPoolShareCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("importpubkey", (*ImportPubKeyCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("listaddresstickets",
+		(*ListAddressTicketsCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("listaddressusage",
+		(*ListAddressUsageCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("listjobs", (*ListJobsCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("listpendingsends",
+		(*ListPendingSendsCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("listretiredaddresses",
+		(*ListRetiredAddressesCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("listvsptickets", (*ListVSPTicketsCmd)(nil),
+		flags)
+	dcrjson.MustRegisterCmd("loadwallet", (*LoadWalletCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("notifyconfirmations",
+		(*NotifyConfirmationsCmd)(nil), flags|dcrjson.UFWebsocketOnly)
+	dcrjson.MustRegisterCmd("purchasevsptickets",
+		(*PurchaseVSPTicketsCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("registervsp", (*RegisterVSPCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("rejectsend", (*RejectSendCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("rescanwallet", (*RescanWalletCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("rotateimportedkey",
+		(*RotateImportedKeyCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("setbirthday", (*SetBirthdayCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("setcreditorigin", (*SetCreditOriginCmd)(nil),
+		flags)
+	dcrjson.MustRegisterCmd("setunlocktimeout", (*SetUnlockTimeoutCmd)(nil),
+		flags)
+	dcrjson.MustRegisterCmd("startjob", (*StartJobCmd)(nil), flags)
+	dcrjson.MustRegisterCmd("unloadwallet", (*UnloadWalletCmd)(nil), flags)
+}
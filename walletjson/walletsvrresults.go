@@ -0,0 +1,558 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package walletjson
+
+import "github.com/decred/dcrd/dcrjson"
+
+// ChangeIndexResult describes a change output of a transaction authored by
+// the wallet in verbose gettransaction results.  Index is the child index of
+// the change address in the internal branch of the account.
+type ChangeIndexResult struct {
+	Vout    uint32 `json:"vout"`
+	Address string `json:"address"`
+	Account string `json:"account"`
+	Index   uint32 `json:"index"`
+}
+
+// CreateMultisigWalletResult models the recovery bundle returned by the
+// createmultisigwallet command, which each cosigner should store.  Passing
+// NRequired, Keys, and Count to createmultisigwallet recreates the scripts,
+// and Height is the block height a restored wallet should rescan from.
+type CreateMultisigWalletResult struct {
+	NRequired int                    `json:"nrequired"`
+	Keys      []string               `json:"keys"`
+	Count     uint32                 `json:"count"`
+	Branch    uint32                 `json:"branch"`
+	Network   string                 `json:"network"`
+	Height    int32                  `json:"height"`
+	Scripts   []MultisigWalletScript `json:"scripts"`
+}
+
+// CreatePaymentURIResult models the data returned by the createpaymenturi
+// command.  Expires is the Unix time the payment request expires at, or zero
+// if it never expires.
+type CreatePaymentURIResult struct {
+	URI     string `json:"uri"`
+	Address string `json:"address"`
+	Expires int64  `json:"expires"`
+}
+
+// DecodePaymentURIResult models the data returned by the decodepaymenturi
+// command.  When the URI is not valid, Error describes why and no other
+// fields are set.  Expires is zero for payment requests which never expire,
+// and IsMine reports whether the requested address belongs to the wallet.
+type DecodePaymentURIResult struct {
+	IsValid bool    `json:"isvalid"`
+	Error   string  `json:"error,omitempty"`
+	Address string  `json:"address,omitempty"`
+	Amount  float64 `json:"amount,omitempty"`
+	Label   string  `json:"label,omitempty"`
+	Message string  `json:"message,omitempty"`
+	Expires int64   `json:"expires,omitempty"`
+	Expired bool    `json:"expired"`
+	IsMine  bool    `json:"ismine"`
+}
+
+// DecodeRawTransactionResult models the data returned by the
+// decoderawtransaction command.  Type is the stake type of the transaction,
+// and only the stake details matching it are set.
+type DecodeRawTransactionResult struct {
+	TxID       string                       `json:"txid"`
+	Type       string                       `json:"type"`
+	Version    int32                        `json:"version"`
+	LockTime   uint32                       `json:"locktime"`
+	Expiry     uint32                       `json:"expiry"`
+	Vin        []DecodeRawTransactionInput  `json:"vin"`
+	Vout       []DecodeRawTransactionOutput `json:"vout"`
+	Ticket     *TicketDetailsResult         `json:"ticket,omitempty"`
+	Vote       *VoteDetailsResult           `json:"vote,omitempty"`
+	Revocation *RevocationDetailsResult     `json:"revocation,omitempty"`
+}
+
+// DecodeRawTransactionInput models an input of a transaction decoded by the
+// decoderawtransaction command.  Stakebase inputs of votes do not spend an
+// output, and Mine reports whether the input spends an output of the wallet.
+type DecodeRawTransactionInput struct {
+	TxID      string  `json:"txid"`
+	Vout      uint32  `json:"vout"`
+	Tree      int8    `json:"tree"`
+	Sequence  uint32  `json:"sequence"`
+	AmountIn  float64 `json:"amountin"`
+	Stakebase bool    `json:"stakebase,omitempty"`
+	Mine      bool    `json:"mine"`
+}
+
+// DecodeRawTransactionOutput models an output of a transaction decoded by the
+// decoderawtransaction command.  The output script is described as by the
+// describescript command.
+type DecodeRawTransactionOutput struct {
+	N            uint32               `json:"n"`
+	Value        float64              `json:"value"`
+	Version      uint16               `json:"version"`
+	ScriptPubKey DescribeScriptResult `json:"scriptpubkey"`
+}
+
+// DescribeScriptResult models the data returned by the describescript and
+// decodeaddress commands.  Hashes are the hex-encoded public keys and hashes
+// of the addresses involved in the script.  For pay-to-script-hash scripts
+// whose redeem script is known, WalletKeys and Controlled describe the redeem
+// script.
+type DescribeScriptResult struct {
+	Script        string   `json:"script"`
+	Class         string   `json:"class"`
+	StakeSubClass string   `json:"stakesubclass,omitempty"`
+	ReqSigs       int32    `json:"reqsigs"`
+	Addresses     []string `json:"addresses"`
+	Hashes        []string `json:"hashes"`
+	WalletKeys    int32    `json:"walletkeys"`
+	Controlled    bool     `json:"controlled"`
+	RedeemScript  string   `json:"redeemscript,omitempty"`
+}
+
+// EstimateStakeDiffResult models the data returned by the estimatestakediff
+// command.  Min, Expected and Max are the forecast ticket prices of the next
+// stake difficulty window when no more tickets, tickets at the current rate,
+// and the maximum number of tickets are purchased in the remaining blocks of
+// the current window.
+type EstimateStakeDiffResult struct {
+	Height    int64   `json:"height"`
+	Remaining int64   `json:"remaining"`
+	Current   float64 `json:"current"`
+	Min       float64 `json:"min"`
+	Expected  float64 `json:"expected"`
+	Max       float64 `json:"max"`
+}
+
+// ExportAccountHistoryResult models the data returned by the
+// exportaccounthistory command.  Data holds a CSV row or JSON object line for
+// each exported transaction.  Complete reports whether the history was
+// exported up to the latest mined transaction, and NextCursor resumes the
+// history after the last exported transaction.
+type ExportAccountHistoryResult struct {
+	Account    string `json:"account"`
+	Format     string `json:"format"`
+	Count      int    `json:"count"`
+	Complete   bool   `json:"complete"`
+	NextCursor string `json:"nextcursor,omitempty"`
+	Data       string `json:"data"`
+}
+
+// ExportCapitalGainsResult models the data returned by the exportcapitalgains
+// command.  CSV holds a row for each realized gain, and the totals only
+// include the gains whose proceeds and cost basis are known.  UnknownCount is
+// the number of gains of transactions without a recorded fiat rate or of
+// coins whose acquisition is not recorded.
+type ExportCapitalGainsResult struct {
+	Year          int     `json:"year"`
+	Method        string  `json:"method"`
+	Currency      string  `json:"currency"`
+	TotalProceeds float64 `json:"totalproceeds"`
+	TotalCost     float64 `json:"totalcost"`
+	TotalGain     float64 `json:"totalgain"`
+	UnknownCount  int     `json:"unknowncount"`
+	CSV           string  `json:"csv"`
+}
+
+// GetAddressPathResult models the data returned by the getaddresspath
+// command.  Path is the BIP0032 derivation path of the key of the address,
+// m/44'/<coin type>'/<account>'/<branch>/<index>, where the branch is 0 for
+// external addresses and 1 for internal (change) addresses.
+type GetAddressPathResult struct {
+	Address       string `json:"address"`
+	Account       string `json:"account"`
+	AccountNumber uint32 `json:"accountnumber"`
+	Branch        uint32 `json:"branch"`
+	Index         uint32 `json:"index"`
+	Path          string `json:"path"`
+}
+
+// GetAPIInfoResult models the data returned by the getapiinfo command.  The
+// version of the wallet JSON-RPC API follows the semantic versioning 2.0.0
+// spec, and Capabilities lists the optional features provided by the server.
+type GetAPIInfoResult struct {
+	Version      string   `json:"version"`
+	Major        uint32   `json:"major"`
+	Minor        uint32   `json:"minor"`
+	Patch        uint32   `json:"patch"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// GetBackendStateResult models the data returned by the getbackendstate
+// command.  Times are Unix times, and are zero when the event never occurred.
+// BlocksBehind is the number of blocks the wallet must process to catch up
+// with the best block notified by the chain server.
+type GetBackendStateResult struct {
+	Connected           bool   `json:"connected"`
+	Endpoint            string `json:"endpoint,omitempty"`
+	ConnectedTime       int64  `json:"connectedtime"`
+	BestBlockHash       string `json:"bestblockhash,omitempty"`
+	BestBlockHeight     int32  `json:"bestblockheight"`
+	BestBlockTime       int64  `json:"bestblocktime"`
+	SyncedHeight        int32  `json:"syncedheight"`
+	BlocksBehind        int32  `json:"blocksbehind"`
+	ChainSynced         bool   `json:"chainsynced"`
+	QueuedNotifications int    `json:"queuednotifications"`
+	LastHealthCheck     int64  `json:"lasthealthcheck"`
+	RescanQueueDepth    int    `json:"rescanqueuedepth"`
+}
+
+// GetBalanceHistoryResult models the data returned by the getbalancehistory
+// command for each block or day which changed the balance.  Height and Time
+// describe the last block of the interval, Delta is the change of the balance
+// during the interval, and Balance is the balance after it.
+type GetBalanceHistoryResult struct {
+	Height  int32   `json:"height"`
+	Time    int64   `json:"time"`
+	Delta   float64 `json:"delta"`
+	Balance float64 `json:"balance"`
+}
+
+// GetBlockVoteBitsResult models the data returned by the getblockvotebits
+// command for each block.  ParentApproved reports whether the vote bits of the
+// block approve the regular transaction tree of its parent.
+type GetBlockVoteBitsResult struct {
+	Height         int32  `json:"height"`
+	Hash           string `json:"hash"`
+	Time           int64  `json:"time"`
+	VoteBits       uint16 `json:"votebits"`
+	ParentApproved bool   `json:"parentapproved"`
+}
+
+// GetFeesReportResult models the data returned by the getfeesreport command.
+// The fees paid by each kind of transaction are totalled separately, and
+// UnknownCount is the number of transactions whose fee could not be
+// determined.
+type GetFeesReportResult struct {
+	RegularCount    int     `json:"regularcount"`
+	RegularFees     float64 `json:"regularfees"`
+	TicketCount     int     `json:"ticketcount"`
+	TicketFees      float64 `json:"ticketfees"`
+	VoteCount       int     `json:"votecount"`
+	VoteFees        float64 `json:"votefees"`
+	RevocationCount int     `json:"revocationcount"`
+	RevocationFees  float64 `json:"revocationfees"`
+	UnknownCount    int     `json:"unknowncount"`
+	TotalFees       float64 `json:"totalfees"`
+}
+
+// GetImportedBalanceResult models the data returned by the getimportedbalance
+// command.  Seed is the balance recoverable by restoring the wallet from its
+// seed, and Imported is the balance of imported keys and scripts, which is
+// only recoverable from a backup.  BackupRequired reports whether Imported is
+// not zero.
+type GetImportedBalanceResult struct {
+	Total          float64 `json:"total"`
+	Seed           float64 `json:"seed"`
+	Imported       float64 `json:"imported"`
+	BackupRequired bool    `json:"backuprequired"`
+}
+
+// GetLockInfoResult models the data returned by the getlockinfo command.
+// LockTime and Remaining are zero when the wallet is locked or was unlocked
+// without a timeout.
+type GetLockInfoResult struct {
+	Locked    bool  `json:"locked"`
+	LockTime  int64 `json:"locktime"`
+	Remaining int64 `json:"remaining"`
+}
+
+// GetNewAddressResult models the data returned by the getnewaddress command
+// when an options object is passed.  Gap is the number of consecutive unused
+// addresses preceding the address, counting at most the gap limit, and
+// RedeemScript is set for P2SH addresses.
+type GetNewAddressResult struct {
+	Address         string `json:"address"`
+	Type            string `json:"type"`
+	PubKey          string `json:"pubkey"`
+	RedeemScript    string `json:"redeemscript,omitempty"`
+	Gap             uint32 `json:"gap"`
+	ExceedsGapLimit bool   `json:"exceedsgaplimit"`
+	Warning         string `json:"warning,omitempty"`
+}
+
+// GetStakeInfoResult models the data returned by the getstakeinfo command.
+// OwnMempoolTix counts the wallet's tickets which have not been mined, and
+// Difficulty and TotalSubsidy are in coins.
+type GetStakeInfoResult struct {
+	BlockHeight   int32   `json:"blockheight"`
+	Difficulty    float64 `json:"difficulty"`
+	OwnMempoolTix int     `json:"ownmempooltix"`
+	Immature      int     `json:"immature"`
+	Live          int     `json:"live"`
+	Voted         int     `json:"voted"`
+	Missed        int     `json:"missed"`
+	Expired       int     `json:"expired"`
+	Revoked       int     `json:"revoked"`
+	TotalSubsidy  float64 `json:"totalsubsidy"`
+}
+
+// GetTicketBuyerLogResult models the data returned by the getticketbuyerlog
+// command for each run of the automatic ticket buyer.  FeeRate is the fee per
+// kB paid by the purchased tickets, and Batch is the ticket batch of the
+// purchased tickets, if any.
+type GetTicketBuyerLogResult struct {
+	ID          uint64  `json:"id"`
+	Height      int32   `json:"height"`
+	Time        int64   `json:"time"`
+	TicketPrice float64 `json:"ticketprice"`
+	Spendable   float64 `json:"spendable"`
+	MempoolFee  float64 `json:"mempoolfee"`
+	FeeRate     float64 `json:"feerate"`
+	Attempted   int     `json:"attempted"`
+	Purchased   int     `json:"purchased"`
+	Batch       uint32  `json:"batch,omitempty"`
+	Reason      string  `json:"reason"`
+}
+
+// GetTicketPoolShareResult models the data returned by the getticketpoolshare
+// command.  Share is the fraction of the live ticket pool owned by the wallet,
+// ExpectedTime is the expected number of seconds until the next vote, and
+// WithinProbability is the probability of at least one vote within Days days.
+type GetTicketPoolShareResult struct {
+	Height            int32   `json:"height"`
+	LiveTickets       int64   `json:"livetickets"`
+	PoolSize          int64   `json:"poolsize"`
+	Share             float64 `json:"share"`
+	VoteProbability   float64 `json:"voteprobability"`
+	ExpectedBlocks    float64 `json:"expectedblocks"`
+	ExpectedTime      int64   `json:"expectedtime"`
+	Days              int     `json:"days"`
+	WithinProbability float64 `json:"withinprobability"`
+}
+
+// ImportScriptResult models the data returned by the importscript command
+// when an options object is passed.  Address is the P2SH address of the
+// imported script, and ScriptAddresses are the addresses the script pays to.
+type ImportScriptResult struct {
+	Address         string                      `json:"address"`
+	ScriptAddresses []string                    `json:"scriptaddresses"`
+	Outputs         []dcrjson.ListUnspentResult `json:"outputs"`
+}
+
+// JobStatusResult models the data returned by the getjobstatus and listjobs
+// commands for each job.  Result and Error are only set once the job has
+// finished, and Result is omitted by listjobs.
+type JobStatusResult struct {
+	JobID    int64       `json:"jobid"`
+	Method   string      `json:"method"`
+	Status   string      `json:"status"`
+	Started  int64       `json:"started"`
+	Finished int64       `json:"finished,omitempty"`
+	Result   interface{} `json:"result,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// ListAddressTicketsResult models the data returned by the listaddresstickets
+// command for each ticket.  Voting and Commitment describe whether the
+// address is the ticket's voting address or one of its commitment addresses.
+type ListAddressTicketsResult struct {
+	Ticket           string  `json:"ticket"`
+	Voting           bool    `json:"voting"`
+	Commitment       bool    `json:"commitment"`
+	CommitmentAmount float64 `json:"commitmentamount"`
+	Status           string  `json:"status"`
+}
+
+// ListAddressUsageResult models the data returned by the listaddressusage
+// command for each address.  Branch is "external", "internal", or "imported",
+// FirstUsed is the Unix time of the first transaction paying to the address
+// or zero for unused addresses, and Reused reports whether the address was
+// paid by more than one transaction.
+type ListAddressUsageResult struct {
+	Address       string  `json:"address"`
+	Account       string  `json:"account"`
+	Branch        string  `json:"branch"`
+	FirstUsed     int64   `json:"firstused"`
+	TotalReceived float64 `json:"totalreceived"`
+	Balance       float64 `json:"balance"`
+	TxCount       int     `json:"txcount"`
+	Reused        bool    `json:"reused"`
+}
+
+// ListRetiredAddressesResult models the data returned by the
+// listretiredaddresses command for each retired imported key or script.
+// Retired is the Unix time the address was retired, and SweepTxID is the
+// hash of the transaction sweeping its funds, which is empty when there were
+// no funds to sweep.
+type ListRetiredAddressesResult struct {
+	Address   string `json:"address"`
+	Script    string `json:"script"`
+	Retired   int64  `json:"retired"`
+	SweepTxID string `json:"sweeptxid,omitempty"`
+}
+
+// ListVSPTicketsResult models the data returned by the listvsptickets command
+// for each ticket voted by the voting service provider.  PoolFee is the
+// amount committed to the pool address, and FeePaid reports whether it
+// satisfies the pool's fee percentage.
+type ListVSPTicketsResult struct {
+	Ticket  string  `json:"ticket"`
+	Status  string  `json:"status"`
+	Price   float64 `json:"price"`
+	PoolFee float64 `json:"poolfee"`
+	FeePaid bool    `json:"feepaid"`
+}
+
+// MultisigWalletScript models a redeem script of a multisig wallet returned by
+// the createmultisigwallet command.  Index is the child index of the cosigner
+// extended public keys used by the script.
+type MultisigWalletScript struct {
+	Index        uint32 `json:"index"`
+	Address      string `json:"address"`
+	RedeemScript string `json:"redeemscript"`
+}
+
+// PendingSendResult models the data returned by the listpendingsends command
+// for each send waiting for approval.  Inputs are the outputs spent by the
+// send, in the order of the transaction inputs, Outputs are the payments of
+// the send, excluding change, and Hex is the unsigned transaction.  Created
+// and Expires are Unix times.
+type PendingSendResult struct {
+	ID      string              `json:"id"`
+	Origin  string              `json:"origin"`
+	Amount  float64             `json:"amount"`
+	Fee     float64             `json:"fee"`
+	Inputs  []PendingSendInput  `json:"inputs"`
+	Outputs []PendingSendOutput `json:"outputs"`
+	Created int64               `json:"created"`
+	Expires int64               `json:"expires"`
+	Hex     string              `json:"hex"`
+}
+
+// PendingSendInput models an output spent by a send waiting for approval.
+// Path is the derivation path of the key of the address, so that a hardware
+// or remote signer can sign the input without searching for the key, and is
+// empty for imported addresses and scripts.
+type PendingSendInput struct {
+	TxID    string  `json:"txid"`
+	Vout    uint32  `json:"vout"`
+	Tree    int8    `json:"tree"`
+	Amount  float64 `json:"amount"`
+	Address string  `json:"address,omitempty"`
+	Account string  `json:"account,omitempty"`
+	Path    string  `json:"path,omitempty"`
+}
+
+// PendingSendOutput models a payment of a send waiting for approval.
+type PendingSendOutput struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"`
+}
+
+// RegisterVSPResult models the data returned by the registervsp command.
+// Registered is false when a public key address was already registered with
+// the voting service provider, in which case only its script was imported.
+type RegisterVSPResult struct {
+	Registered    bool    `json:"registered"`
+	TicketAddress string  `json:"ticketaddress"`
+	Script        string  `json:"script"`
+	PoolAddress   string  `json:"pooladdress"`
+	PoolFees      float64 `json:"poolfees"`
+}
+
+// RotateImportedKeyResult models the data returned by the rotateimportedkey
+// command.  Inputs and Amount describe the outputs swept from the imported
+// address, of which Fee is paid to the miner, and Skipped and SkippedAmount
+// the outputs which could not be swept yet.  Destination and TxID are only
+// set when the rotation was confirmed and there were outputs to sweep.
+type RotateImportedKeyResult struct {
+	Address       string  `json:"address"`
+	Inputs        int     `json:"inputs"`
+	Amount        float64 `json:"amount"`
+	Fee           float64 `json:"fee"`
+	Skipped       int     `json:"skipped"`
+	SkippedAmount float64 `json:"skippedamount"`
+	Destination   string  `json:"destination,omitempty"`
+	TxID          string  `json:"txid,omitempty"`
+	Retired       bool    `json:"retired"`
+}
+
+// RescanWalletResult models the data returned by the rescanwallet command.
+// Height and Hash describe the last rescanned block, and Transactions is the
+// number of wallet transactions in the rescanned blocks.
+type RescanWalletResult struct {
+	StartHeight  int32  `json:"startheight"`
+	Height       int32  `json:"height"`
+	Hash         string `json:"hash"`
+	Transactions int    `json:"transactions"`
+	Cancelled    bool   `json:"cancelled"`
+}
+
+// RevocationDetailsResult describes the ticket spent by a revocation and the
+// amount refunded to its commitment addresses.
+type RevocationDetailsResult struct {
+	Ticket   string  `json:"ticket"`
+	Refunded float64 `json:"refunded"`
+}
+
+// SigningRecordResult models the data returned by the exportsigninglog
+// command for each record of the signing log.  Time is the Unix time the
+// transaction was signed, and Origin describes who requested the signature.
+type SigningRecordResult struct {
+	Sequence uint64                `json:"sequence"`
+	Time     int64                 `json:"time"`
+	Origin   string                `json:"origin"`
+	TxHash   string                `json:"txhash"`
+	Inputs   []SigningRecordInput  `json:"inputs"`
+	Outputs  []SigningRecordOutput `json:"outputs"`
+	Hex      string                `json:"hex"`
+}
+
+// SigningRecordInput models an input of a transaction recorded in the signing
+// log.  Amount is the value of the spent output committed to by the input.
+type SigningRecordInput struct {
+	TxID   string  `json:"txid"`
+	Vout   uint32  `json:"vout"`
+	Tree   int8    `json:"tree"`
+	Amount float64 `json:"amount"`
+}
+
+// SigningRecordOutput models an output of a transaction recorded in the
+// signing log.
+type SigningRecordOutput struct {
+	Amount     float64  `json:"amount"`
+	ScriptType string   `json:"scripttype"`
+	Addresses  []string `json:"addresses"`
+}
+
+// TicketCommitmentResult describes a commitment output of a ticket.  The fee
+// limits are omitted when the fee is not allowed.
+type TicketCommitmentResult struct {
+	Address            string   `json:"address"`
+	Amount             float64  `json:"amount"`
+	Share              float64  `json:"share"`
+	Owned              bool     `json:"owned"`
+	VoteFeeLimit       *float64 `json:"votefeelimit,omitempty"`
+	RevocationFeeLimit *float64 `json:"revocationfeelimit,omitempty"`
+	ChangeAddress      string   `json:"changeaddress,omitempty"`
+	ChangeAmount       float64  `json:"changeamount"`
+}
+
+// TicketDetailsResult describes the price and commitments of a ticket.
+type TicketDetailsResult struct {
+	Price       float64                  `json:"price"`
+	Commitments []TicketCommitmentResult `json:"commitments"`
+}
+
+// VoteDetailsResult describes the ticket spent by a vote and the block and
+// vote bits it votes with.
+type VoteDetailsResult struct {
+	Ticket      string `json:"ticket"`
+	BlockHash   string `json:"blockhash"`
+	BlockHeight uint32 `json:"blockheight"`
+	VoteBits    uint16 `json:"votebits"`
+}
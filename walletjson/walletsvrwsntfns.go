@@ -0,0 +1,217 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package walletjson
+
+import "github.com/decred/dcrd/dcrjson"
+
+const (
+	// JobStatusNtfnMethod is the method used for notifications of a job
+	// started by the startjob command finishing.
+	JobStatusNtfnMethod = "jobstatus"
+
+	// RescanWalletProgressNtfnMethod is the method used for notifications
+	// of the progress of a rescan started by the rescanwallet command.
+	RescanWalletProgressNtfnMethod = "rescanwalletprogress"
+
+	// TicketOutcomeNtfnMethod is the method used for notifications of one
+	// of the wallet's tickets voting, being missed, expiring, or being
+	// revoked.
+	TicketOutcomeNtfnMethod = "ticketoutcome"
+
+	// TicketWarningNtfnMethod is the method used for notifications of one
+	// of the wallet's unmined tickets being about to become invalid, or of
+	// the stake difficulty window being about to end.
+	TicketWarningNtfnMethod = "ticketwarning"
+
+	// TxConfirmedNtfnMethod is the method used for notifications of a
+	// transaction watched with the notifyconfirmations command reaching
+	// its confirmation threshold.
+	TxConfirmedNtfnMethod = "txconfirmed"
+
+	// UnexpectedSpendNtfnMethod is the method used for notifications of a
+	// transaction spending wallet funds which was not created or signed by
+	// the wallet.
+	UnexpectedSpendNtfnMethod = "unexpectedspend"
+)
+
+// JobStatusNtfn is a notification describing a job started by the startjob
+// command which has finished.  Status is either "done", with the result of
+// the job's request, or "failed", with the error of the request.
+type JobStatusNtfn struct {
+	JobID  int64
+	Method string
+	Status string
+	Result interface{}
+	Error  string
+}
+
+// NewJobStatusNtfn returns a new instance which can be used to issue a
+// jobstatus JSON-RPC notification.
+func NewJobStatusNtfn(jobID int64, method, status string, result interface{},
+	err string) *JobStatusNtfn {
+	return &JobStatusNtfn{
+		JobID:  jobID,
+		Method: method,
+		Status: status,
+		Result: result,
+		Error:  err,
+	}
+}
+
+// RescanWalletProgressNtfn is a notification describing the progress of a
+// rescan started by the rescanwallet command.  Done is set for the final
+// notification of the rescan, along with Error if the rescan stopped before
+// the best block.
+type RescanWalletProgressNtfn struct {
+	StartHeight  int32
+	Height       int32
+	Hash         string
+	Transactions int
+	Done         bool
+	Error        string
+}
+
+// NewRescanWalletProgressNtfn returns a new instance which can be used to
+// issue a rescanwalletprogress JSON-RPC notification.
+func NewRescanWalletProgressNtfn(startHeight, height int32, hash string,
+	transactions int, done bool, err string) *RescanWalletProgressNtfn {
+	return &RescanWalletProgressNtfn{
+		StartHeight:  startHeight,
+		Height:       height,
+		Hash:         hash,
+		Transactions: transactions,
+		Done:         done,
+		Error:        err,
+	}
+}
+
+// TicketOutcomeNtfn is a notification describing the outcome of one of the
+// wallet's tickets.  Amount is the reward earned by a vote, or the negative
+// fees lost by a ticket which did not vote.  TxHash is empty when the outcome
+// has no vote or revocation transaction.
+type TicketOutcomeNtfn struct {
+	Ticket string
+	Status string
+	Height int32
+	Amount float64
+	TxHash string
+}
+
+// NewTicketOutcomeNtfn returns a new instance which can be used to issue a
+// ticketoutcome JSON-RPC notification.
+func NewTicketOutcomeNtfn(ticket, status string, height int32, amount float64,
+	txHash string) *TicketOutcomeNtfn {
+	return &TicketOutcomeNtfn{
+		Ticket: ticket,
+		Status: status,
+		Height: height,
+		Amount: amount,
+		TxHash: txHash,
+	}
+}
+
+// TicketWarningNtfn is a notification warning that one of the wallet's
+// unmined tickets is about to become invalid or that the stake difficulty
+// window is about to end.  Kind is "ticketexpiring" or "windowending", and
+// Ticket is empty for windowending warnings.
+type TicketWarningNtfn struct {
+	Kind      string
+	Ticket    string
+	Height    int32
+	Deadline  int32
+	Remaining int32
+	Price     float64
+}
+
+// NewTicketWarningNtfn returns a new instance which can be used to issue a
+// ticketwarning JSON-RPC notification.
+func NewTicketWarningNtfn(kind, ticket string, height, deadline,
+	remaining int32, price float64) *TicketWarningNtfn {
+	return &TicketWarningNtfn{
+		Kind:      kind,
+		Ticket:    ticket,
+		Height:    height,
+		Deadline:  deadline,
+		Remaining: remaining,
+		Price:     price,
+	}
+}
+
+// TxConfirmedNtfn is a notification describing a transaction watched with the
+// notifyconfirmations command which has reached the requested number of
+// confirmations.  BlockHash and BlockHeight describe the block that mined the
+// transaction.
+type TxConfirmedNtfn struct {
+	TxID          string
+	Confirmations int32
+	BlockHash     string
+	BlockHeight   int32
+}
+
+// NewTxConfirmedNtfn returns a new instance which can be used to issue a
+// txconfirmed JSON-RPC notification.
+func NewTxConfirmedNtfn(txID string, confirmations int32, blockHash string,
+	blockHeight int32) *TxConfirmedNtfn {
+	return &TxConfirmedNtfn{
+		TxID:          txID,
+		Confirmations: confirmations,
+		BlockHash:     blockHash,
+		BlockHeight:   blockHeight,
+	}
+}
+
+// UnexpectedSpendNtfn is a notification describing a transaction spending
+// wallet funds which was not created or signed by the wallet.  Inputs are the
+// spent wallet outpoints formatted as hash:index, and Height is -1 for unmined
+// transactions.  The JSON tags name the fields of the webhook alert.
+type UnexpectedSpendNtfn struct {
+	TxHash string   `json:"txhash"`
+	Inputs []string `json:"inputs"`
+	Amount float64  `json:"amount"`
+	Height int32    `json:"height"`
+}
+
+// NewUnexpectedSpendNtfn returns a new instance which can be used to issue an
+// unexpectedspend JSON-RPC notification.
+func NewUnexpectedSpendNtfn(txHash string, inputs []string, amount float64,
+	height int32) *UnexpectedSpendNtfn {
+	return &UnexpectedSpendNtfn{
+		TxHash: txHash,
+		Inputs: inputs,
+		Amount: amount,
+		Height: height,
+	}
+}
+
+func init() {
+	// The commands in this file are only usable by websockets and are
+	// notifications.
+	flags := dcrjson.UFWalletOnly | dcrjson.UFWebsocketOnly |
+		dcrjson.UFNotification
+
+	dcrjson.MustRegisterCmd(JobStatusNtfnMethod, (*JobStatusNtfn)(nil), flags)
+	dcrjson.MustRegisterCmd(RescanWalletProgressNtfnMethod,
+		(*RescanWalletProgressNtfn)(nil), flags)
+	dcrjson.MustRegisterCmd(TicketOutcomeNtfnMethod,
+		(*TicketOutcomeNtfn)(nil), flags)
+	dcrjson.MustRegisterCmd(TicketWarningNtfnMethod,
+		(*TicketWarningNtfn)(nil), flags)
+	dcrjson.MustRegisterCmd(TxConfirmedNtfnMethod, (*TxConfirmedNtfn)(nil),
+		flags)
+	dcrjson.MustRegisterCmd(UnexpectedSpendNtfnMethod,
+		(*UnexpectedSpendNtfn)(nil), flags)
+}
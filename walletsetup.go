@@ -26,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainec"
@@ -34,11 +35,17 @@ import (
 	"github.com/decred/dcrutil/hdkeychain"
 	"github.com/decred/dcrwallet/internal/legacy/keystore"
 	"github.com/decred/dcrwallet/pgpwordlist"
+	"github.com/decred/dcrwallet/signer"
 	"github.com/decred/dcrwallet/waddrmgr"
 	"github.com/decred/dcrwallet/wallet"
 	"github.com/decred/dcrwallet/walletdb"
 	_ "github.com/decred/dcrwallet/walletdb/bdb"
+	"github.com/decred/dcrwallet/walletdb/cryptdb"
+	_ "github.com/decred/dcrwallet/walletdb/ldb"
+	_ "github.com/decred/dcrwallet/walletdb/memdb"
+	_ "github.com/decred/dcrwallet/walletdb/sqlite"
 	"github.com/decred/dcrwallet/wstakemgr"
+	"github.com/decred/dcrwallet/wtxmgr"
 
 	"github.com/btcsuite/golangcrypto/ssh/terminal"
 )
@@ -69,6 +76,16 @@ func networkDir(dataDir string, chainParams *chaincfg.Params) string {
 	return filepath.Join(dataDir, netname)
 }
 
+// walletDbFilename returns the file name of the wallet database stored by the
+// database backend dbType.  Bolt databases keep the original wallet.db name
+// while other backends use their type as the extension.
+func walletDbFilename(dbType string) string {
+	if dbType == defaultDbType {
+		return walletDbName
+	}
+	return "wallet." + dbType
+}
+
 // promptSeed is used to prompt for the wallet seed which maybe required during
 // upgrades.
 func promptSeed() ([]byte, error) {
@@ -310,24 +327,29 @@ func promptConsolePublicPass(reader *bufio.Reader, privPass []byte, cfg *config)
 // promptConsoleSeed prompts the user whether they want to use an existing
 // wallet generation seed.  When the user answers no, a seed will be generated
 // and displayed to the user along with prompting them for confirmation.  When
-// the user answers yes, a the user is prompted for it.  All prompts are
-// repeated until the user enters a valid response.
-func promptConsoleSeed(reader *bufio.Reader) ([]byte, error) {
+// the user answers yes, a the user is prompted for it and the date the seed
+// was created.  All prompts are repeated until the user enters a valid
+// response.
+//
+// The birthday of the seed is returned with the seed.  It is the current time
+// for a generated seed, and the zero time if the user does not know when an
+// existing seed was created.
+func promptConsoleSeed(reader *bufio.Reader) ([]byte, time.Time, error) {
 	// Ascertain the wallet generation seed.
 	useUserSeed, err := promptConsoleListBool(reader, "Do you have an "+
 		"existing wallet seed you want to use?", "no")
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 	if !useUserSeed {
 		seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
 		if err != nil {
-			return nil, err
+			return nil, time.Time{}, err
 		}
 
 		seedStr, err := pgpwordlist.ToStringChecksum(seed)
 		if err != nil {
-			return nil, err
+			return nil, time.Time{}, err
 		}
 		seedStrSplit := strings.Split(seedStr, " ")
 
@@ -353,7 +375,7 @@ func promptConsoleSeed(reader *bufio.Reader) ([]byte, error) {
 				`and secure location, enter "OK" to continue: `)
 			confirmSeed, err := reader.ReadString('\n')
 			if err != nil {
-				return nil, err
+				return nil, time.Time{}, err
 			}
 			confirmSeed = strings.TrimSpace(confirmSeed)
 			confirmSeed = strings.Trim(confirmSeed, `"`)
@@ -362,14 +384,21 @@ func promptConsoleSeed(reader *bufio.Reader) ([]byte, error) {
 			}
 		}
 
-		return seed, nil
+		return seed, time.Now(), nil
 	}
 
+	return promptConsoleExistingSeed(reader)
+}
+
+// promptConsoleExistingSeed prompts the user for an existing wallet seed,
+// repeating the prompt until a valid seed is entered, and then for the date
+// the seed was created.  The seed and its birthday are returned.
+func promptConsoleExistingSeed(reader *bufio.Reader) ([]byte, time.Time, error) {
 	for {
 		fmt.Print("Enter existing wallet seed: ")
 		seedStr, err := reader.ReadString('\n')
 		if err != nil {
-			return nil, err
+			return nil, time.Time{}, err
 		}
 
 		seedStrTrimmed := strings.TrimSpace(seedStr)
@@ -402,7 +431,37 @@ func promptConsoleSeed(reader *bufio.Reader) ([]byte, error) {
 
 		fmt.Printf("\nSeed input successful. \nHex: %x\n", seed)
 
-		return seed, nil
+		birthday, err := promptConsoleBirthday(reader)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return seed, birthday, nil
+	}
+}
+
+// promptConsoleBirthday prompts the user for the date an existing wallet seed
+// was created.  The wallet does not rescan blocks before this date, less a
+// safety margin, when it first syncs.  The zero time is returned if the user
+// does not know the date.
+func promptConsoleBirthday(reader *bufio.Reader) (time.Time, error) {
+	for {
+		fmt.Print("Enter the date the seed was created (YYYY-MM-DD), or " +
+			"leave blank to rescan the entire block chain: ")
+		reply, err := reader.ReadString('\n')
+		if err != nil {
+			return time.Time{}, err
+		}
+		reply = strings.TrimSpace(reply)
+		if reply == "" {
+			return time.Time{}, nil
+		}
+		birthday, err := time.Parse("2006-01-02", reply)
+		if err != nil || birthday.After(time.Now()) {
+			fmt.Println("Invalid date specified.  Must be a date " +
+				"in the past in the form YYYY-MM-DD.")
+			continue
+		}
+		return birthday, nil
 	}
 }
 
@@ -500,17 +559,17 @@ func createWallet(cfg *config) error {
 	// Ascertain the wallet generation seed.  This will either be an
 	// automatically generated value the user has already confirmed or a
 	// value the user has entered which has already been validated.
-	seed, err := promptConsoleSeed(reader)
+	seed, birthday, err := promptConsoleSeed(reader)
 	if err != nil {
 		return err
 	}
 
 	// Create the wallet.
-	dbPath := filepath.Join(netDir, walletDbName)
+	dbPath := filepath.Join(netDir, walletDbFilename(cfg.DbType))
 	fmt.Println("Creating the wallet...")
 
-	// Create the wallet database backed by bolt db.
-	db, err := walletdb.Create("bdb", dbPath)
+	// Create the wallet database using the configured backend.
+	db, err := createDb(cfg, dbPath, pubPass)
 	if err != nil {
 		return err
 	}
@@ -540,6 +599,10 @@ func createWallet(cfg *config) error {
 		legacyKeyStore.Lock()
 		legacyKeyStore = nil
 
+		// Imported keys may be older than the seed, so the whole
+		// block chain must be rescanned.
+		birthday = time.Time{}
+
 		// Remove the legacy key store.
 		if err := os.Remove(keystorePath); err != nil {
 			fmt.Printf("WARN: Failed to remove legacy wallet "+
@@ -547,6 +610,12 @@ func createWallet(cfg *config) error {
 		}
 	}
 
+	if !birthday.IsZero() {
+		if err := manager.SetBirthday(birthday); err != nil {
+			return err
+		}
+	}
+
 	manager.Close()
 	fmt.Println("The wallet has been created successfully.")
 	return nil
@@ -582,11 +651,11 @@ func createSimulationWallet(cfg *config) error {
 	}
 
 	// Create the wallet.
-	dbPath := filepath.Join(netDir, walletDbName)
+	dbPath := filepath.Join(netDir, walletDbFilename(cfg.DbType))
 	fmt.Println("Creating the wallet...")
 
-	// Create the wallet database backed by bolt db.
-	db, err := walletdb.Create("bdb", dbPath)
+	// Create the wallet database using the configured backend.
+	db, err := createDb(cfg, dbPath, pubPass)
 	if err != nil {
 		return err
 	}
@@ -654,11 +723,11 @@ func createWatchingOnlyWallet(cfg *config) error {
 	netDir := networkDir(cfg.DataDir, activeNet.Params)
 
 	// Create the wallet.
-	dbPath := filepath.Join(netDir, walletDbName)
+	dbPath := filepath.Join(netDir, walletDbFilename(cfg.DbType))
 	fmt.Println("Creating the wallet...")
 
-	// Create the wallet database backed by bolt db.
-	db, err := walletdb.Create("bdb", dbPath)
+	// Create the wallet database using the configured backend.
+	db, err := createDb(cfg, dbPath, pubPass)
 	if err != nil {
 		return err
 	}
@@ -714,18 +783,299 @@ func checkCreateDir(path string) error {
 	return nil
 }
 
-// openDb opens and returns a walletdb.DB (boltdb here) given the directory and
-// dbname.
-func openDb(directory string, dbname string) (walletdb.DB, error) {
-	dbPath := filepath.Join(directory, dbname)
+// createDb creates and returns a walletdb.DB at dbPath using the configured
+// database backend.  When database encryption is enabled, the keys and values
+// of the database are encrypted with a key protected by the public passphrase,
+// which must not be the default public passphrase.
+func createDb(cfg *config, dbPath string, pubPass []byte) (walletdb.DB, error) {
+	if cfg.EncryptDB && (len(pubPass) == 0 ||
+		bytes.Equal(pubPass, []byte(defaultPubPassphrase))) {
+		return nil, fmt.Errorf("--encryptdb requires a public " +
+			"passphrase other than the default")
+	}
+
+	db, err := walletdb.Create(cfg.DbType, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.EncryptDB {
+		return db, nil
+	}
+
+	edb, err := cryptdb.Create(db, pubPass)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return edb, nil
+}
+
+// openDb opens and returns a walletdb.DB of the database backend dbType given
+// the directory.
+func openDb(directory string, dbType string) (walletdb.DB, error) {
+	dbPath := filepath.Join(directory, walletDbFilename(dbType))
 
 	// Ensure that the network directory exists.
 	if err := checkCreateDir(directory); err != nil {
 		return nil, err
 	}
 
-	// Open the database using the boltdb backend.
-	return walletdb.Open("bdb", dbPath)
+	return walletdb.Open(dbType, dbPath)
+}
+
+// compactDb compacts the wallet database of the database backend dbType in
+// directory.  All live data of the wallet namespaces is copied into a new
+// database which then replaces the original, leaving behind the free space of
+// deleted records.  The sizes in bytes of the database before and after
+// compaction are returned.
+func compactDb(directory string, dbType string) (before, after int64, err error) {
+	dbPath := filepath.Join(directory, walletDbFilename(dbType))
+	compactPath := dbPath + ".compact"
+
+	before, err = dbSize(dbPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Remove the partially written database of any interrupted compaction.
+	if err := os.RemoveAll(compactPath); err != nil {
+		return 0, 0, err
+	}
+
+	if err := copyWalletDb(compactPath, dbPath, dbType); err != nil {
+		os.RemoveAll(compactPath)
+		return 0, 0, err
+	}
+	if err := replaceDb(dbPath, compactPath); err != nil {
+		return 0, 0, err
+	}
+
+	after, err = dbSize(dbPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, after, nil
+}
+
+// copyWalletDb creates a new database at dstPath and copies every wallet
+// namespace of the database at srcPath into it.
+func copyWalletDb(dstPath, srcPath, dbType string) error {
+	src, err := walletdb.Open(dbType, srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := walletdb.Create(dbType, dstPath)
+	if err != nil {
+		return err
+	}
+
+	// The values of encrypted databases are copied without decrypting
+	// them, so the encryption parameters must be copied as well.
+	namespaceKeys := [][]byte{waddrmgrNamespaceKey, wtxmgrNamespaceKey,
+		wstakemgrNamespaceKey, cryptdb.NamespaceKey}
+	for _, key := range namespaceKeys {
+		srcNS, err := src.Namespace(key)
+		if err != nil {
+			dst.Close()
+			return err
+		}
+		dstNS, err := dst.Namespace(key)
+		if err != nil {
+			dst.Close()
+			return err
+		}
+		if err := walletdb.CopyNamespace(dstNS, srcNS); err != nil {
+			dst.Close()
+			return err
+		}
+	}
+
+	return dst.Close()
+}
+
+// replaceDb replaces the closed database at dbPath with the closed database at
+// newPath.  Databases stored in a single file are replaced atomically by
+// renaming the new file over the original.  A database stored in a directory
+// can not be renamed over, so the original is first moved aside and only
+// removed once the new database is in place.
+func replaceDb(dbPath, newPath string) error {
+	fi, err := os.Stat(dbPath)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return os.Rename(newPath, dbPath)
+	}
+
+	oldPath := dbPath + ".old"
+	if err := os.RemoveAll(oldPath); err != nil {
+		return err
+	}
+	if err := os.Rename(dbPath, oldPath); err != nil {
+		return err
+	}
+	if err := os.Rename(newPath, dbPath); err != nil {
+		os.Rename(oldPath, dbPath)
+		return err
+	}
+	return os.RemoveAll(oldPath)
+}
+
+// dbSize returns the total size in bytes of the database at path, which may
+// either be a single file or a directory of files.
+func dbSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// walletNamespace describes a wallet namespace and the validator of its
+// records.
+type walletNamespace struct {
+	name     string
+	key      []byte
+	validate walletdb.RecordValidator
+}
+
+// walletNamespaces are the namespaces checked and salvaged by checkDb and
+// copied into backups.
+var walletNamespaces = []walletNamespace{
+	{"address manager", waddrmgrNamespaceKey, waddrmgr.ValidateRecord},
+	{"transaction store", wtxmgrNamespaceKey, wtxmgr.ValidateRecord},
+	{"stake manager", wstakemgrNamespaceKey, wstakemgr.ValidateRecord},
+}
+
+// checkDb checks every wallet namespace of the database of the database
+// backend dbType in directory for records that can not be read or
+// deserialized, and logs each problem found.  Encrypted databases are
+// decrypted using the public passphrase.  When any problems are found, all
+// valid records are salvaged into a new database next to the original and its
+// path is returned along with the number of problems.  The original database is
+// never modified.
+func checkDb(directory, dbType string, pubPass []byte) (salvagePath string,
+	problems int, err error) {
+	dbPath := filepath.Join(directory, walletDbFilename(dbType))
+	rawDb, err := walletdb.OpenReadOnly(dbType, dbPath)
+	if err == walletdb.ErrReadOnlyUnsupported {
+		rawDb, err = walletdb.Open(dbType, dbPath)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	// The decrypting database, when used, closes the underlying database
+	// and zeros its key.
+	db := rawDb
+	defer func() { db.Close() }()
+	encrypted, err := cryptdb.IsEncrypted(rawDb)
+	if err != nil {
+		return "", 0, err
+	}
+	if encrypted {
+		edb, err := cryptdb.Open(rawDb, pubPass)
+		if err != nil {
+			return "", 0, err
+		}
+		db = edb
+	}
+
+	// Namespaces missing from databases opened read-only, such as the
+	// stake manager namespace of wallets that never opened it, are skipped.
+	var namespaces []walletNamespace
+	for _, wns := range walletNamespaces {
+		ns, err := db.Namespace(wns.key)
+		if err == walletdb.ErrBucketNotFound {
+			log.Infof("Skipping missing %s namespace", wns.name)
+			continue
+		}
+		if err != nil {
+			return "", 0, err
+		}
+		nsProblems, err := walletdb.CheckNamespace(ns, wns.validate)
+		if err != nil {
+			return "", 0, err
+		}
+		for _, p := range nsProblems {
+			log.Warnf("Invalid %s record %v", wns.name, p)
+		}
+		problems += len(nsProblems)
+		namespaces = append(namespaces, wns)
+	}
+	if problems == 0 {
+		return "", 0, nil
+	}
+
+	// Remove the partially written database of any interrupted salvage.
+	salvagePath = dbPath + ".salvage"
+	if err := os.RemoveAll(salvagePath); err != nil {
+		return "", 0, err
+	}
+	err = salvageWalletDb(salvagePath, dbType, rawDb, db, namespaces,
+		encrypted, pubPass)
+	if err != nil {
+		os.RemoveAll(salvagePath)
+		return "", 0, err
+	}
+	return salvagePath, problems, nil
+}
+
+// salvageWalletDb creates a new database at dstPath and copies all valid
+// records of the passed namespaces of src into it.  The encryption parameters
+// of encrypted databases are copied from rawSrc so the salvaged database is
+// encrypted with the same key.
+func salvageWalletDb(dstPath, dbType string, rawSrc, src walletdb.DB,
+	namespaces []walletNamespace, encrypted bool, pubPass []byte) error {
+	rawDst, err := walletdb.Create(dbType, dstPath)
+	if err != nil {
+		return err
+	}
+
+	dst := rawDst
+	defer func() { dst.Close() }()
+	if encrypted {
+		srcNS, err := rawSrc.Namespace(cryptdb.NamespaceKey)
+		if err != nil {
+			return err
+		}
+		dstNS, err := rawDst.Namespace(cryptdb.NamespaceKey)
+		if err != nil {
+			return err
+		}
+		if err := walletdb.CopyNamespace(dstNS, srcNS); err != nil {
+			return err
+		}
+		edb, err := cryptdb.Open(rawDst, pubPass)
+		if err != nil {
+			return err
+		}
+		dst = edb
+	}
+
+	for _, wns := range namespaces {
+		srcNS, err := src.Namespace(wns.key)
+		if err != nil {
+			return err
+		}
+		dstNS, err := dst.Namespace(wns.key)
+		if err != nil {
+			return err
+		}
+		_, err = walletdb.SalvageNamespace(dstNS, srcNS, wns.validate)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // openWallet returns a wallet. The function handles opening an existing wallet
@@ -734,12 +1084,29 @@ func openDb(directory string, dbname string) (walletdb.DB, error) {
 func openWallet(cfg *config) (*wallet.Wallet, walletdb.DB, error) {
 	netdir := networkDir(cfg.DataDir, activeNet.Params)
 
-	db, err := openDb(netdir, walletDbName)
+	db, err := openDb(netdir, cfg.DbType)
 	if err != nil {
 		log.Errorf("Failed to open database: %v", err)
 		return nil, nil, err
 	}
 
+	// Databases created with encryption are decrypted using the public
+	// passphrase.
+	encrypted, err := cryptdb.IsEncrypted(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	if encrypted {
+		edb, err := cryptdb.Open(db, []byte(cfg.WalletPass))
+		if err != nil {
+			log.Errorf("Failed to decrypt database: %v", err)
+			db.Close()
+			return nil, nil, err
+		}
+		db = edb
+	}
+
 	addrMgrNS, err := db.Namespace(waddrmgrNamespaceKey)
 	if err != nil {
 		return nil, nil, err
@@ -758,9 +1125,60 @@ func openWallet(cfg *config) (*wallet.Wallet, walletdb.DB, error) {
 		ObtainPrivatePass: promptPrivPassPhrase,
 	}
 	w, err := wallet.Open([]byte(cfg.WalletPass), activeNet.Params, db,
-		addrMgrNS, txMgrNS, stMgrNS, cbs, cfg.VoteBits, cfg.EnableStakeMining,
-		cfg.BalanceToMaintain, cfg.ReuseAddresses, cfg.RollbackTest,
-		cfg.PruneTickets, cfg.TicketAddress, cfg.TicketMaxPrice,
-		cfg.AutomaticRepair)
+		addrMgrNS, txMgrNS, stMgrNS, cbs, &wallet.Config{
+			VoteBits:            cfg.VoteBits,
+			StakeMiningEnabled:  cfg.EnableStakeMining,
+			BalanceToMaintain:   cfg.BalanceToMaintain,
+			AddressReuse:        cfg.ReuseAddresses,
+			RollbackTest:        cfg.RollbackTest,
+			PruneTickets:        cfg.PruneTickets,
+			AutoRepair:          cfg.AutomaticRepair,
+			TicketAddress:       cfg.TicketAddress,
+			RewardAddress:       cfg.RewardAddress,
+			TicketMaxPrice:      cfg.TicketMaxPrice,
+			MaxFee:              cfg.MaxFee,
+			MaxFeePercent:       cfg.MaxFeePercent,
+			MaxTicketsPerBlock:  cfg.MaxPerBlock,
+			TicketMaxFeeRate:    cfg.TicketMaxFeeRate,
+			MaxTicketsPerWindow: cfg.MaxPerWindow,
+			AutoRevoke:          !cfg.NoAutoRevoke,
+			StakePoolEnabled:    cfg.StakePoolMode,
+			PoolAddress:         cfg.PoolAddress,
+			PoolFees:            cfg.PoolFees,
+			VotingOnly:          cfg.VotingOnly,
+		})
+	if err == nil {
+		w.SeparateCreditOrigins = cfg.SeparateOrigins
+		w.GapLimit = cfg.GapLimit
+		w.TxStore.SetInvariantSampling(cfg.InvariantSampling)
+		if cfg.RemoteSigner != "" {
+			w.SetRemoteSigner(signer.NewClient(cfg.RemoteSigner,
+				[]byte(cfg.RemoteSignerKey)))
+		}
+		if cfg.FiatRateSource != "" {
+			w.SetFiatRateSource(&wallet.FiatRateSource{
+				URL:      cfg.FiatRateSource,
+				Currency: cfg.FiatCurrency,
+			})
+		}
+		err = w.SetSpendPolicy(wallet.SpendPolicy{
+			MinConf:                cfg.MinConf,
+			SpendUnconfirmedChange: cfg.SpendUnconfirmedChange,
+		})
+	}
+	if err == nil {
+		var policy *wallet.SigningPolicy
+		policy, err = newSigningPolicy(cfg)
+		if err == nil {
+			w.SetSigningPolicy(policy)
+		}
+	}
+	if err == nil {
+		var policy *wallet.SendApprovalPolicy
+		policy, err = newSendApprovalPolicy(cfg)
+		if err == nil {
+			w.SetSendApprovalPolicy(policy)
+		}
+	}
 	return w, db, err
 }
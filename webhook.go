@@ -0,0 +1,374 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/chain"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+const (
+	// webhookTimeout is the maximum time spent on a single attempt to
+	// deliver an event to a webhook.
+	webhookTimeout = 30 * time.Second
+
+	// webhookAttempts is the number of attempts made to deliver an event
+	// to a webhook before it is dropped.
+	webhookAttempts = 6
+
+	// webhookRetryDelay is the delay before the first retry of a failed
+	// delivery.  The delay doubles with every further retry.
+	webhookRetryDelay = 5 * time.Second
+
+	// webhookWorkers is the number of goroutines delivering events to
+	// webhooks.  A worker is busy with a delivery until it succeeds or
+	// all attempts fail.
+	webhookWorkers = 4
+
+	// webhookQueueSize is the number of deliveries which may wait for a
+	// free worker.  Events are dropped while the queue is full.
+	webhookQueueSize = 1000
+
+	// maxWebhookWatches is the maximum number of deposits watched for
+	// reaching the --webhookconfirmations threshold at a time.
+	maxWebhookWatches = 10000
+
+	// webhookSignatureHeader is the HTTP header carrying the hex encoded
+	// HMAC-SHA256 of the request body keyed by the --webhooksecret.
+	webhookSignatureHeader = "X-Dcrwallet-Signature"
+)
+
+// Events POSTed to webhooks.
+const (
+	webhookDeposit      = "deposit"
+	webhookConfirmed    = "confirmed"
+	webhookTicketStatus = "ticketstatus"
+)
+
+// webhookDepositEvent is the JSON body POSTed to webhooks when an address of
+// the wallet receives funds, and again when the transaction reaches the
+// confirmation threshold.
+type webhookDepositEvent struct {
+	Event         string  `json:"event"`
+	TxID          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Address       string  `json:"address"`
+	Account       string  `json:"account"`
+	Amount        float64 `json:"amount"`
+	Category      string  `json:"category"`
+	Confirmations int64   `json:"confirmations"`
+	BlockHash     string  `json:"blockhash,omitempty"`
+	Time          int64   `json:"time"`
+}
+
+// webhookTicketEvent is the JSON body POSTed to webhooks when a ticket of the
+// wallet votes, is revoked, misses or expires.  It carries the same fields as
+// the ticketoutcome websocket notification.
+type webhookTicketEvent struct {
+	Event  string  `json:"event"`
+	Ticket string  `json:"ticket"`
+	Status string  `json:"status"`
+	Height int32   `json:"height"`
+	Amount float64 `json:"amount"`
+	TxHash string  `json:"txhash,omitempty"`
+	Time   int64   `json:"time"`
+}
+
+// webhookDelivery is an encoded event waiting to be POSTed to a webhook.
+type webhookDelivery struct {
+	url       string
+	body      []byte
+	signature string
+	kind      string
+	subject   string
+}
+
+// webhookNotifier POSTs wallet events to the --webhookurl targets.  Deposits
+// are remembered until their transaction reaches the confirmation threshold,
+// when a confirmed event is delivered for each of them.
+//
+// Events are fed to the notifier by the notification listener of the legacy
+// RPC server, so webhooks are only delivered while that server is running.
+// Notified and watched deposits are only remembered in memory, so a deposit
+// may be delivered again after a restart and receivers should deduplicate
+// events by txid and vout.
+type webhookNotifier struct {
+	urls          []string
+	secret        []byte
+	confirmations int32
+	queue         chan *webhookDelivery
+
+	mu            sync.Mutex
+	notified      map[chainhash.Hash]struct{}
+	notifiedOrder []chainhash.Hash
+	watches       map[chainhash.Hash]struct{}
+}
+
+// newWebhookNotifier returns a notifier for the webhook URLs and starts its
+// delivery workers, which run for the lifetime of the process.
+func newWebhookNotifier(urls []string, secret string,
+	confirmations int32) *webhookNotifier {
+	n := &webhookNotifier{
+		urls:          urls,
+		confirmations: confirmations,
+		queue:         make(chan *webhookDelivery, webhookQueueSize),
+		notified:      make(map[chainhash.Hash]struct{}),
+		watches:       make(map[chainhash.Hash]struct{}),
+	}
+	if secret != "" {
+		n.secret = []byte(secret)
+	}
+	for i := 0; i < webhookWorkers; i++ {
+		go n.deliveryWorker()
+	}
+	return n
+}
+
+// depositEvents returns an event for every output of a transaction which
+// credits the wallet, excluding change.
+func depositEvents(w *wallet.Wallet, event string,
+	details *wtxmgr.TxDetails) []*webhookDepositEvent {
+	syncBlock := w.Manager.SyncedTo()
+	ltr := wallet.ListTransactions(details, w.Manager, syncBlock.Height,
+		activeNet.Params)
+	var events []*webhookDepositEvent
+	for i := range ltr {
+		r := &ltr[i]
+		if r.Category == "send" {
+			continue
+		}
+		events = append(events, &webhookDepositEvent{
+			Event:         event,
+			TxID:          r.TxID,
+			Vout:          r.Vout,
+			Address:       r.Address,
+			Account:       r.Account,
+			Amount:        r.Amount,
+			Category:      r.Category,
+			Confirmations: r.Confirmations,
+			BlockHash:     r.BlockHash,
+			Time:          time.Now().Unix(),
+		})
+	}
+	return events
+}
+
+// relevantTx delivers a deposit event for each credit of a transaction the
+// first time the wallet is notified of it, and begins watching it for
+// reaching the confirmation threshold.
+func (n *webhookNotifier) relevantTx(w *wallet.Wallet, tx chain.RelevantTx) {
+	n.mu.Lock()
+	_, ok := n.notified[tx.TxRecord.Hash]
+	if !ok {
+		if len(n.notifiedOrder) >= maxWebhookWatches {
+			// Forget the oldest deposit rather than growing
+			// without bounds.
+			delete(n.notified, n.notifiedOrder[0])
+			n.notifiedOrder = n.notifiedOrder[1:]
+		}
+		n.notified[tx.TxRecord.Hash] = struct{}{}
+		n.notifiedOrder = append(n.notifiedOrder, tx.TxRecord.Hash)
+	}
+	n.mu.Unlock()
+	if ok {
+		return
+	}
+
+	var block *wtxmgr.Block
+	if tx.Block != nil {
+		block = &tx.Block.Block
+	}
+	details, err := w.TxStore.UniqueTxDetails(&tx.TxRecord.Hash, block)
+	if err != nil {
+		rpcsLog.Errorf("Cannot fetch transaction details for "+
+			"webhook: %v", err)
+		return
+	}
+	if details == nil {
+		return
+	}
+	events := depositEvents(w, webhookDeposit, details)
+	if len(events) == 0 {
+		return
+	}
+
+	n.mu.Lock()
+	if len(n.watches) < maxWebhookWatches {
+		n.watches[tx.TxRecord.Hash] = struct{}{}
+	} else {
+		rpcsLog.Warnf("Too many watched deposits: no %s webhook "+
+			"event will be delivered for transaction %v",
+			webhookConfirmed, &tx.TxRecord.Hash)
+	}
+	n.mu.Unlock()
+
+	for _, e := range events {
+		n.post(e, e.Event, e.TxID)
+	}
+}
+
+// connectedBlock delivers a confirmed event for the credits of every watched
+// deposit which reached the confirmation threshold and stops watching them.
+// Confirmations are counted from the block the transaction store currently
+// records a transaction in, so a deposit which is reorganized out of the main
+// chain before reaching the threshold must be mined and confirmed again.
+func (n *webhookNotifier) connectedBlock(w *wallet.Wallet) {
+	syncBlock := w.Manager.SyncedTo()
+	var reached []*wtxmgr.TxDetails
+	n.mu.Lock()
+	for hash := range n.watches {
+		hash := hash
+		details, err := w.TxStore.TxDetails(&hash)
+		if err != nil {
+			rpcsLog.Errorf("Cannot fetch details of watched "+
+				"deposit %v: %v", &hash, err)
+			continue
+		}
+		if details == nil {
+			// Removed from the transaction store as a double
+			// spend.
+			delete(n.watches, hash)
+			continue
+		}
+		if details.Block.Height == -1 ||
+			confirms(details.Block.Height, syncBlock.Height) <
+				n.confirmations {
+			continue
+		}
+		delete(n.watches, hash)
+		reached = append(reached, details)
+	}
+	n.mu.Unlock()
+
+	for _, details := range reached {
+		for _, e := range depositEvents(w, webhookConfirmed, details) {
+			n.post(e, e.Event, e.TxID)
+		}
+	}
+}
+
+// ticketOutcome delivers a ticket status event.
+func (n *webhookNotifier) ticketOutcome(o *wallet.TicketOutcome) {
+	var txHash string
+	if o.TxHash != (chainhash.Hash{}) {
+		txHash = o.TxHash.String()
+	}
+	e := &webhookTicketEvent{
+		Event:  webhookTicketStatus,
+		Ticket: o.Ticket.String(),
+		Status: o.Status.String(),
+		Height: o.Height,
+		Amount: o.Amount.ToCoin(),
+		TxHash: txHash,
+		Time:   time.Now().Unix(),
+	}
+	n.post(e, e.Event, e.Ticket)
+}
+
+// post encodes an event and queues its delivery to every webhook.  The
+// subject identifies the event in log messages.
+func (n *webhookNotifier) post(event interface{}, kind, subject string) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		rpcsLog.Errorf("Cannot encode %s webhook event: %v", kind, err)
+		return
+	}
+	var signature string
+	if n.secret != nil {
+		mac := hmac.New(sha256.New, n.secret)
+		mac.Write(body)
+		signature = hex.EncodeToString(mac.Sum(nil))
+	}
+	for _, url := range n.urls {
+		d := &webhookDelivery{url, body, signature, kind, subject}
+		select {
+		case n.queue <- d:
+		default:
+			rpcsLog.Warnf("Webhook delivery queue is full: dropping "+
+				"%s event for %v to %s", kind, subject, url)
+		}
+	}
+}
+
+// deliveryWorker delivers queued events to webhooks one at a time.
+func (n *webhookNotifier) deliveryWorker() {
+	for d := range n.queue {
+		deliverWebhook(d.url, d.body, d.signature, d.kind, d.subject)
+	}
+}
+
+// deliverWebhook POSTs the body of an event to a webhook URL, retrying with
+// exponential backoff until the webhook accepts it with a 2xx status or the
+// attempts are exhausted.  Events rejected with a 4xx status are not retried.
+func deliverWebhook(url string, body []byte, signature, kind, subject string) {
+	client := http.Client{Timeout: webhookTimeout}
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := postWebhook(&client, url, body, signature)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == webhookAttempts {
+			rpcsLog.Errorf("Cannot deliver %s webhook event for %v to "+
+				"%s: %v", kind, subject, url, err)
+			return
+		}
+		rpcsLog.Debugf("Retrying %s webhook event for %v to %s in %v: "+
+			"%v", kind, subject, url, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postWebhook makes a single attempt to deliver an event to a webhook URL,
+// returning whether a failed delivery may be retried.
+func postWebhook(client *http.Client, url string, body []byte,
+	signature string) (bool, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set(webhookSignatureHeader, signature)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode / 100 {
+	case 2:
+		return false, nil
+	case 4:
+		return false, errors.New(resp.Status)
+	default:
+		return true, errors.New(resp.Status)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrutil/hdkeychain"
+	"github.com/decred/dcrwallet/chain"
+	"github.com/decred/dcrwallet/waddrmgr"
+	"github.com/decred/dcrwallet/wallet"
+	"github.com/decred/dcrwallet/walletdb"
+	"github.com/decred/dcrwallet/wtxmgr"
+)
+
+func TestPostWebhook(t *testing.T) {
+	const secret = "webhook secret"
+	body := []byte(`{"event":"deposit"}`)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil || string(b) != string(body) {
+			t.Errorf("webhook received body %q (%v)", b, err)
+		}
+		if got := r.Header.Get(webhookSignatureHeader); got != signature {
+			t.Errorf("webhook received signature %q, want %q", got,
+				signature)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		status int
+		retry  bool
+		err    bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusAccepted, false, false},
+		{http.StatusBadRequest, false, true},
+		{http.StatusServiceUnavailable, true, true},
+	}
+	client := &http.Client{}
+	for _, test := range tests {
+		status = test.status
+		retry, err := postWebhook(client, srv.URL, body, signature)
+		if retry != test.retry || (err != nil) != test.err {
+			t.Errorf("status %d: got retry=%v err=%v, want retry=%v "+
+				"err=%v", test.status, retry, err, test.retry,
+				test.err)
+		}
+	}
+
+	srv.Close()
+	retry, err := postWebhook(client, srv.URL, body, signature)
+	if !retry || err == nil {
+		t.Errorf("unreachable webhook: got retry=%v err=%v, want "+
+			"retry=true and an error", retry, err)
+	}
+}
+
+// newWebhookTestWallet opens a new wallet backed by an in-memory database.
+func newWebhookTestWallet(t *testing.T) *wallet.Wallet {
+	db, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrMgrNS, err := db.Namespace(waddrmgrNamespaceKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txMgrNS, err := db.Namespace(wtxmgrNamespaceKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stMgrNS, err := db.Namespace(wstakemgrNamespaceKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr, err := waddrmgr.Create(addrMgrNS, seed, []byte("pub"),
+		[]byte("priv"), activeNet.Params,
+		&waddrmgr.ScryptOptions{N: 16, R: 8, P: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.Close()
+	if _, err := wtxmgr.Create(txMgrNS, activeNet.Params); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := wallet.Open([]byte("pub"), activeNet.Params, db, addrMgrNS,
+		txMgrNS, stMgrNS, nil, &wallet.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestWebhookRelevantTx(t *testing.T) {
+	w := newWebhookTestWallet(t)
+	defer w.CloseDatabases()
+
+	addrs, err := w.Manager.NextExternalAddresses(
+		waddrmgr.DefaultAccountNum, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := addrs[0].Address()
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgTx := wire.NewMsgTx()
+	msgTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil))
+	msgTx.AddTxOut(wire.NewTxOut(1e8, pkScript))
+	rec, err := wtxmgr.NewTxRecordFromMsgTx(msgTx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.TxStore.InsertTx(rec, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.TxStore.AddCredit(rec, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	bodies := make(chan []byte, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		bodies <- b
+	}))
+	defer srv.Close()
+
+	n := newWebhookNotifier([]string{srv.URL}, "", 6)
+	relevantTx := chain.RelevantTx{TxRecord: rec}
+	n.relevantTx(w, relevantTx)
+
+	var e webhookDepositEvent
+	select {
+	case b := <-bodies:
+		if err := json.Unmarshal(b, &e); err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("deposit event was not delivered")
+	}
+	if e.Event != webhookDeposit || e.TxID != rec.Hash.String() ||
+		e.Vout != 0 || e.Address != addr.EncodeAddress() ||
+		e.Amount != 1 {
+		t.Errorf("unexpected deposit event %+v", e)
+	}
+	if _, ok := n.watches[rec.Hash]; !ok {
+		t.Errorf("deposit %v is not watched for confirmation", &rec.Hash)
+	}
+
+	// A transaction is only delivered the first time it is notified.
+	n.relevantTx(w, relevantTx)
+	select {
+	case b := <-bodies:
+		t.Errorf("deposit event delivered again: %s", b)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
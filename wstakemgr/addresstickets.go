@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"bytes"
+
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// AddressTicket describes a ticket in the stake store which gives its voting
+// rights to, or commits an amount to, an address.  CommitmentAmount is the
+// total amount of the ticket's commitments to the address.
+type AddressTicket struct {
+	Hash             chainhash.Hash
+	Voting           bool
+	Commitment       bool
+	CommitmentAmount dcrutil.Amount
+}
+
+// ticketsForAddress returns the tickets whose voting or commitment address
+// is addr.
+func (s *StakeStore) ticketsForAddress(addr dcrutil.Address) ([]AddressTicket,
+	error) {
+	scriptHash := addr.ScriptAddress()
+	if len(scriptHash) != 20 {
+		str := "address is not a pubkey hash or script hash address"
+		return nil, stakeStoreError(ErrInput, str, nil)
+	}
+	_, isScriptHash := addr.(*dcrutil.AddressScriptHash)
+
+	var tickets []AddressTicket
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		for _, h := range s.dumpSStxHashes() {
+			record, err := fetchSStxRecord(tx, &h)
+			if err != nil {
+				return err
+			}
+			ticket := AddressTicket{Hash: h}
+
+			votingHash, err := fetchSStxRecordSStxTicketScriptHash(tx, &h)
+			if err != nil {
+				return err
+			}
+			ticket.Voting = bytes.Equal(scriptHash, votingHash)
+
+			payTypes, pkhs, amts, _, _, _ :=
+				stake.GetSStxStakeOutputInfo(record.tx)
+			for i := range pkhs {
+				if payTypes[i] == isScriptHash &&
+					bytes.Equal(scriptHash, pkhs[i]) {
+					ticket.Commitment = true
+					ticket.CommitmentAmount += dcrutil.Amount(amts[i])
+				}
+			}
+
+			if ticket.Voting || ticket.Commitment {
+				tickets = append(tickets, ticket)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		str := "failure reading ticket records from db"
+		return nil, stakeStoreError(ErrDatabase, str, err)
+	}
+
+	return tickets, nil
+}
+
+// TicketsForAddress returns every ticket in the stake store whose voting
+// address or any commitment address is addr.  Unlike
+// DumpSStxHashesForAddress, which only matches the voting address, this
+// allows the users of a stake pool to find the tickets they delegated to the
+// pool.  It is safe for concurrent access.
+func (s *StakeStore) TicketsForAddress(addr dcrutil.Address) ([]AddressTicket,
+	error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.ticketsForAddress(addr)
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// ValidateRecord checks that a record of the stake manager namespace can be
+// deserialized.  The path holds the keys of the buckets containing the record,
+// relative to the namespace's root bucket, and a nil v indicates a nested
+// bucket.  It has the signature of walletdb.RecordValidator so it can be used
+// to check and salvage the namespace with walletdb.CheckNamespace and
+// walletdb.SalvageNamespace.  Records without a known format are accepted.
+func ValidateRecord(path [][]byte, k, v []byte) error {
+	// Buckets carry no data of their own and the root bucket only contains
+	// buckets.
+	if v == nil || len(path) != 1 {
+		return nil
+	}
+
+	var err error
+	switch bucket := path[0]; {
+	case bytes.Equal(bucket, mainBucketName):
+		switch {
+		case bytes.Equal(k, stakeStoreVersionName):
+			err = checkRecordSize(k, v, int32Size)
+		case bytes.Equal(k, stakeStoreCreateDateName):
+			err = checkRecordSize(k, v, int64Size)
+		case bytes.Equal(k, ticketWindowName):
+			err = checkRecordSize(k, v, int64Size+int32Size)
+		}
+
+	case bytes.Equal(bucket, metaBucketName):
+		err = checkRecordSize(k, v, int32Size)
+
+	case bytes.Equal(bucket, sstxRecordsBucketName):
+		_, err = deserializeSStxRecord(v)
+
+	case bytes.Equal(bucket, ssgenRecordsBucketName):
+		_, err = deserializeSSGenRecords(v)
+
+	case bytes.Equal(bucket, ssrtxRecordsBucketName):
+		_, err = deserializeSSRtxRecords(v)
+
+	case bytes.Equal(bucket, ticketVoteBitsBucketName):
+		err = checkRecordSize(k, v, int16Size)
+
+	case bytes.Equal(bucket, stakePoolUserBucketName):
+		_, err = deserializeUserTickets(v)
+
+	case bytes.Equal(bucket, stakePoolInvalBucketName):
+		_, err = deserializeUserInvalTickets(v)
+
+	case bytes.Equal(bucket, ticketStatusBucketName):
+		_, err = deserializeTicketStatus(v)
+
+	case bytes.Equal(bucket, ticketStatusHeightName):
+		if len(k) != int32Size+hashSize {
+			return keySizeError(k, int32Size+hashSize)
+		}
+		err = checkRecordSize(k, v, 0)
+
+	case bytes.Equal(bucket, splitTicketsBucketName):
+		if len(k) != hashSize {
+			return keySizeError(k, hashSize)
+		}
+		var hash chainhash.Hash
+		copy(hash[:], k)
+		_, err = deserializeSplitTicket(&hash, v)
+
+	case bytes.Equal(bucket, agendaChoicesBucketName):
+		_, err = deserializeVoteChoice(string(k), v)
+
+	case bytes.Equal(bucket, ticketChoicesBucketName):
+		if len(k) < hashSize {
+			return keySizeError(k, hashSize)
+		}
+		_, err = deserializeVoteChoice(string(k[hashSize:]), v)
+
+	case bytes.Equal(bucket, ticketBatchesBucketName):
+		if len(k) != int32Size {
+			return keySizeError(k, int32Size)
+		}
+		_, err = deserializeTicketBatch(binary.BigEndian.Uint32(k), v)
+
+	case bytes.Equal(bucket, batchTicketsBucketName):
+		err = checkRecordSize(k, v, int32Size)
+
+	case bytes.Equal(bucket, ticketBuyerDecisionsName):
+		if len(k) != int64Size {
+			return keySizeError(k, int64Size)
+		}
+		_, err = deserializeTicketBuyerDecision(binary.BigEndian.Uint64(k), v)
+	}
+
+	return err
+}
+
+// keySizeError returns the error for a record key k that is not size bytes
+// long.
+func keySizeError(k []byte, size int) error {
+	str := fmt.Sprintf("bad size for key %x (expected %d bytes, read %d)",
+		k, size, len(k))
+	return stakeStoreError(ErrDatabase, str, nil)
+}
+
+// checkRecordSize returns an error when the value v stored under the key k is
+// not exactly size bytes long.
+func checkRecordSize(k, v []byte, size int) error {
+	if len(v) != size {
+		str := fmt.Sprintf("bad size for value of key %x (expected %d "+
+			"bytes, read %d)", k, size, len(v))
+		return stakeStoreError(ErrDatabase, str, nil)
+	}
+	return nil
+}
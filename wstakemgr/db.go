@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
@@ -34,7 +35,12 @@ import (
 
 const (
 	// LatestStakeMgrVersion is the most recent tx store version.
-	LatestStakeMgrVersion = 1
+	LatestStakeMgrVersion = 2
+
+	// ticketStatusIndexVersion is the first version of the stake store
+	// which records a status for every owned ticket and indexes the
+	// tickets awaiting a status change by height.
+	ticketStatusIndexVersion = 2
 
 	// Size of various types in bytes.
 	boolSize  = 1
@@ -51,6 +57,30 @@ const (
 	// Size of a serialized ssrtxRecord.
 	// hash + uint32 + hash + uint64
 	ssrtxRecordSize = 32 + 4 + 32 + 8
+
+	// Size of a serialized PoolTicket.
+	// hash + uint32 + uint8 + uint32 + hash
+	stakePoolTicketSize = 32 + 4 + 1 + 4 + 32
+
+	// Size of a serialized ticketStatusRecord.
+	// uint8 + int32 + int32 + int64
+	ticketStatusRecordSize = 1 + 4 + 4 + 8
+
+	// Size of a serialized ticketStatusRecord written before vote rewards
+	// were recorded.
+	ticketStatusRecordSizeV1 = 1 + 4 + 4
+
+	// Size of a serialized SplitTicket record.
+	// int64 + int64
+	splitTicketRecordSize = 8 + 8
+
+	// Size of the fixed fields of a serialized TicketBatch.
+	// int32 + int64 + uint32
+	ticketBatchHeaderSize = 4 + 8 + 4
+
+	// Size of the fixed fields of a serialized TicketBuyerDecision.
+	// int32 + int64 + 4 * int64 + 3 * uint32
+	ticketBuyerDecisionHeaderSize = 4 + 8 + 4*8 + 3*4
 )
 
 var (
@@ -87,18 +117,65 @@ func maybeConvertDbError(err error) error {
 // ssgenRecords
 //     key: sstx tx hash
 //     val: serialized slice of ssgenRecords
+// ticketVoteBits
+//     key: sstx tx hash
+//     val: uint16 vote bits
+// stakePoolUser
+//     key: user commitment address hash160
+//     val: serialized slice of PoolTickets
+// stakePoolInvalid
+//     key: user commitment address hash160
+//     val: serialized slice of ticket hashes
+// ticketStatus
+//     key: sstx tx hash
+//     val: ticketStatusRecord (status, heights, and vote reward)
+// ticketStatusHeight
+//     key: big endian uint32 height of the next status change + sstx tx hash
+//     val: empty
+// splitTickets
+//     key: sstx tx hash
+//     val: int64 wallet contribution + int64 total commitment
+// agendaChoices
+//     key: agenda id
+//     val: uint16 mask + uint16 bits + choice id
+// ticketChoices
+//     key: sstx tx hash + agenda id
+//     val: uint16 mask + uint16 bits + choice id
+// ticketBatches
+//     key: big endian uint32 batch id
+//     val: int32 height + int64 time + uint32 count + ticket hashes + label
+// batchTickets
+//     key: sstx tx hash
+//     val: uint32 batch id
+// ticketBuyerDecisions
+//     key: big endian uint64 decision id
+//     val: int32 height + int64 time + int64 price + int64 spendable +
+//          int64 mempool fee + int64 fee rate + uint32 attempted +
+//          uint32 purchased + uint32 batch id + reason
 //
 var (
 	// Bucket names.
-	mainBucketName         = []byte("main")
-	sstxRecordsBucketName  = []byte("sstxrecords")
-	ssgenRecordsBucketName = []byte("ssgenrecords")
-	ssrtxRecordsBucketName = []byte("ssrtxrecords")
-	metaBucketName         = []byte("meta")
+	mainBucketName           = []byte("main")
+	sstxRecordsBucketName    = []byte("sstxrecords")
+	ssgenRecordsBucketName   = []byte("ssgenrecords")
+	ssrtxRecordsBucketName   = []byte("ssrtxrecords")
+	metaBucketName           = []byte("meta")
+	ticketVoteBitsBucketName = []byte("ticketvotebits")
+	stakePoolUserBucketName  = []byte("stakepooluser")
+	stakePoolInvalBucketName = []byte("stakepoolinvalid")
+	ticketStatusBucketName   = []byte("ticketstatus")
+	ticketStatusHeightName   = []byte("ticketstatusheight")
+	splitTicketsBucketName   = []byte("splittickets")
+	agendaChoicesBucketName  = []byte("agendachoices")
+	ticketChoicesBucketName  = []byte("ticketchoices")
+	ticketBatchesBucketName  = []byte("ticketbatches")
+	batchTicketsBucketName   = []byte("batchtickets")
+	ticketBuyerDecisionsName = []byte("ticketbuyerdecisions")
 
 	// Db related key names (main bucket).
 	stakeStoreVersionName    = []byte("stakestorever")
 	stakeStoreCreateDateName = []byte("stakestorecreated")
+	ticketWindowName         = []byte("ticketwindow")
 )
 
 // int8ToBytes converts an 8 bit signed integer into a 1-byte slice.
@@ -532,6 +609,82 @@ func serializeSSRtxRecords(records []*ssrtxRecord) []byte {
 	return buf
 }
 
+// deserializeUserTickets deserializes the passed serialized pool ticket
+// records.
+func deserializeUserTickets(serializedTickets []byte) ([]*PoolTicket, error) {
+	if len(serializedTickets)%stakePoolTicketSize != 0 {
+		str := "serialized pool tickets have an invalid length"
+		return nil, stakeStoreError(ErrDatabase, str, nil)
+	}
+
+	numRecords := len(serializedTickets) / stakePoolTicketSize
+	records := make([]*PoolTicket, numRecords)
+	for i := 0; i < numRecords; i++ {
+		b := serializedTickets[i*stakePoolTicketSize:]
+		record := new(PoolTicket)
+		copy(record.Ticket[:], b[0:hashSize])
+		offset := hashSize
+		record.HeightTicket = byteOrder.Uint32(b[offset : offset+int32Size])
+		offset += int32Size
+		record.Status = PoolTicketStatus(b[offset])
+		offset += int8Size
+		record.HeightSpent = byteOrder.Uint32(b[offset : offset+int32Size])
+		offset += int32Size
+		copy(record.SpentBy[:], b[offset:offset+hashSize])
+		records[i] = record
+	}
+
+	return records, nil
+}
+
+// serializeUserTickets serializes a slice of pool ticket records.
+func serializeUserTickets(records []*PoolTicket) []byte {
+	buf := make([]byte, len(records)*stakePoolTicketSize)
+	for i, record := range records {
+		b := buf[i*stakePoolTicketSize:]
+		copy(b[0:hashSize], record.Ticket[:])
+		offset := hashSize
+		byteOrder.PutUint32(b[offset:offset+int32Size], record.HeightTicket)
+		offset += int32Size
+		b[offset] = byte(record.Status)
+		offset += int8Size
+		byteOrder.PutUint32(b[offset:offset+int32Size], record.HeightSpent)
+		offset += int32Size
+		copy(b[offset:offset+hashSize], record.SpentBy[:])
+	}
+
+	return buf
+}
+
+// deserializeUserInvalTickets deserializes the passed serialized slice of
+// invalid ticket hashes.
+func deserializeUserInvalTickets(serializedTickets []byte) ([]*chainhash.Hash,
+	error) {
+	if len(serializedTickets)%hashSize != 0 {
+		str := "serialized invalid tickets have an invalid length"
+		return nil, stakeStoreError(ErrDatabase, str, nil)
+	}
+
+	numRecords := len(serializedTickets) / hashSize
+	records := make([]*chainhash.Hash, numRecords)
+	for i := 0; i < numRecords; i++ {
+		records[i] = new(chainhash.Hash)
+		copy(records[i][:], serializedTickets[i*hashSize:(i+1)*hashSize])
+	}
+
+	return records, nil
+}
+
+// serializeUserInvalTickets serializes a slice of invalid ticket hashes.
+func serializeUserInvalTickets(records []*chainhash.Hash) []byte {
+	buf := make([]byte, len(records)*hashSize)
+	for i, record := range records {
+		copy(buf[i*hashSize:(i+1)*hashSize], record[:])
+	}
+
+	return buf
+}
+
 // stakeStoreExists returns whether or not the stake store has already
 // been created in the given database namespace.
 func stakeStoreExists(namespace walletdb.Namespace) (bool, error) {
@@ -746,6 +899,732 @@ func putSSRtxRecord(tx walletdb.Tx, hash *chainhash.Hash,
 	return updateSSRtxRecord(tx, hash, record)
 }
 
+// fetchTicketVoteBits retrieves the vote bits set for a ticket from the
+// ticket vote bits bucket.  The returned bool is false if no vote bits were
+// set for the ticket.
+func fetchTicketVoteBits(tx walletdb.Tx, hash *chainhash.Hash) (uint16, bool) {
+	bucket := tx.RootBucket().Bucket(ticketVoteBitsBucketName)
+
+	val := bucket.Get(hash.Bytes())
+	if len(val) != int16Size {
+		return 0, false
+	}
+
+	return byteOrder.Uint16(val), true
+}
+
+// putTicketVoteBits inserts or updates the vote bits for a ticket in the
+// ticket vote bits bucket.
+func putTicketVoteBits(tx walletdb.Tx, hash *chainhash.Hash,
+	voteBits uint16) error {
+	bucket := tx.RootBucket().Bucket(ticketVoteBitsBucketName)
+
+	var buf [int16Size]byte
+	byteOrder.PutUint16(buf[:], voteBits)
+	err := bucket.Put(hash.Bytes(), buf[:])
+	if err != nil {
+		str := fmt.Sprintf("failed to store vote bits for ticket '%s'", hash)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// deleteTicketVoteBits removes the vote bits for a ticket from the ticket
+// vote bits bucket.
+func deleteTicketVoteBits(tx walletdb.Tx, hash *chainhash.Hash) error {
+	bucket := tx.RootBucket().Bucket(ticketVoteBitsBucketName)
+
+	err := bucket.Delete(hash.Bytes())
+	if err != nil {
+		str := fmt.Sprintf("failed to delete vote bits for ticket '%s'", hash)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// fetchStakePoolUserTickets retrieves the pool ticket records of a stake pool
+// user.  A nil slice is returned if the user has no tickets.
+func fetchStakePoolUserTickets(tx walletdb.Tx,
+	scriptHash []byte) ([]*PoolTicket, error) {
+	bucket := tx.RootBucket().Bucket(stakePoolUserBucketName)
+
+	val := bucket.Get(scriptHash)
+	if val == nil {
+		return nil, nil
+	}
+
+	return deserializeUserTickets(val)
+}
+
+// updateStakePoolUserTickets inserts a pool ticket record for a stake pool
+// user, replacing any existing record for the same ticket.
+func updateStakePoolUserTickets(tx walletdb.Tx, scriptHash []byte,
+	record *PoolTicket) error {
+	records, err := fetchStakePoolUserTickets(tx, scriptHash)
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, r := range records {
+		if r.Ticket == record.Ticket {
+			records[i] = record
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		records = append(records, record)
+	}
+
+	bucket := tx.RootBucket().Bucket(stakePoolUserBucketName)
+	err = bucket.Put(scriptHash, serializeUserTickets(records))
+	if err != nil {
+		str := fmt.Sprintf("failed to store pool tickets for user %x",
+			scriptHash)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// fetchStakePoolUserInvalTickets retrieves the invalid tickets submitted by a
+// stake pool user.  A nil slice is returned if there are none.
+func fetchStakePoolUserInvalTickets(tx walletdb.Tx,
+	scriptHash []byte) ([]*chainhash.Hash, error) {
+	bucket := tx.RootBucket().Bucket(stakePoolInvalBucketName)
+
+	val := bucket.Get(scriptHash)
+	if val == nil {
+		return nil, nil
+	}
+
+	return deserializeUserInvalTickets(val)
+}
+
+// updateStakePoolInvalUserTickets records an invalid ticket submitted by a
+// stake pool user.
+func updateStakePoolInvalUserTickets(tx walletdb.Tx, scriptHash []byte,
+	ticket *chainhash.Hash) error {
+	records, err := fetchStakePoolUserInvalTickets(tx, scriptHash)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if r.IsEqual(ticket) {
+			return nil
+		}
+	}
+	records = append(records, ticket)
+
+	bucket := tx.RootBucket().Bucket(stakePoolInvalBucketName)
+	err = bucket.Put(scriptHash, serializeUserInvalTickets(records))
+	if err != nil {
+		str := fmt.Sprintf("failed to store invalid pool tickets for "+
+			"user %x", scriptHash)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// deserializeTicketStatus deserializes the passed serialized ticket status
+// record.
+func deserializeTicketStatus(serializedRecord []byte) (*ticketStatusRecord,
+	error) {
+	if len(serializedRecord) != ticketStatusRecordSize &&
+		len(serializedRecord) != ticketStatusRecordSizeV1 {
+		str := "bad size for serialized ticket status record"
+		return nil, stakeStoreError(ErrDatabase, str, nil)
+	}
+
+	record := &ticketStatusRecord{
+		status:       TicketStatus(serializedRecord[0]),
+		minedHeight:  int32(byteOrder.Uint32(serializedRecord[1:5])),
+		statusHeight: int32(byteOrder.Uint32(serializedRecord[5:9])),
+	}
+	if len(serializedRecord) == ticketStatusRecordSize {
+		record.voteReward = dcrutil.Amount(
+			byteOrder.Uint64(serializedRecord[9:17]))
+	}
+
+	return record, nil
+}
+
+// serializeTicketStatus serializes the passed ticket status record.
+func serializeTicketStatus(record *ticketStatusRecord) []byte {
+	buf := make([]byte, ticketStatusRecordSize)
+	buf[0] = byte(record.status)
+	byteOrder.PutUint32(buf[1:5], uint32(record.minedHeight))
+	byteOrder.PutUint32(buf[5:9], uint32(record.statusHeight))
+	byteOrder.PutUint64(buf[9:17], uint64(record.voteReward))
+	return buf
+}
+
+// fetchTicketStatus retrieves the status record of a ticket from the ticket
+// status bucket.  A nil record is returned if the ticket is not tracked.
+func fetchTicketStatus(tx walletdb.Tx,
+	hash *chainhash.Hash) (*ticketStatusRecord, error) {
+	bucket := tx.RootBucket().Bucket(ticketStatusBucketName)
+
+	val := bucket.Get(hash.Bytes())
+	if val == nil {
+		return nil, nil
+	}
+
+	return deserializeTicketStatus(val)
+}
+
+// fetchAllTicketStatuses retrieves the status records of all tracked tickets
+// from the ticket status bucket.
+func fetchAllTicketStatuses(tx walletdb.Tx) (map[chainhash.Hash]*ticketStatusRecord,
+	error) {
+	bucket := tx.RootBucket().Bucket(ticketStatusBucketName)
+
+	records := make(map[chainhash.Hash]*ticketStatusRecord)
+	err := bucket.ForEach(func(k []byte, v []byte) error {
+		hash, err := chainhash.NewHash(k)
+		if err != nil {
+			return err
+		}
+		record, err := deserializeTicketStatus(v)
+		if err != nil {
+			return err
+		}
+		records[*hash] = record
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// putTicketStatus inserts or updates the status record of a ticket in the
+// ticket status bucket.
+func putTicketStatus(tx walletdb.Tx, hash *chainhash.Hash,
+	record *ticketStatusRecord) error {
+	bucket := tx.RootBucket().Bucket(ticketStatusBucketName)
+
+	err := bucket.Put(hash.Bytes(), serializeTicketStatus(record))
+	if err != nil {
+		str := fmt.Sprintf("failed to store status for ticket '%s'", hash)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// fetchTicketWindow retrieves the index of the stake difficulty window the
+// wallet last purchased tickets in and the number of tickets purchased in it.
+// Zeros are returned if no purchase was recorded.
+func fetchTicketWindow(tx walletdb.Tx) (int64, uint32) {
+	v := tx.RootBucket().Bucket(mainBucketName).Get(ticketWindowName)
+	if len(v) != int64Size+int32Size {
+		return 0, 0
+	}
+	return int64(byteOrder.Uint64(v[:8])), byteOrder.Uint32(v[8:12])
+}
+
+// putTicketWindow stores the index of the stake difficulty window the wallet
+// last purchased tickets in and the number of tickets purchased in it.
+func putTicketWindow(tx walletdb.Tx, window int64, count uint32) error {
+	buf := make([]byte, int64Size+int32Size)
+	byteOrder.PutUint64(buf[:8], uint64(window))
+	byteOrder.PutUint32(buf[8:12], count)
+
+	err := tx.RootBucket().Bucket(mainBucketName).Put(ticketWindowName, buf)
+	if err != nil {
+		str := "failed to store ticket purchase window"
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// deleteTicketStatus removes the status record of a ticket from the ticket
+// status bucket.
+func deleteTicketStatus(tx walletdb.Tx, hash *chainhash.Hash) error {
+	bucket := tx.RootBucket().Bucket(ticketStatusBucketName)
+
+	err := bucket.Delete(hash.Bytes())
+	if err != nil {
+		str := fmt.Sprintf("failed to remove status of ticket '%s'", hash)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// ticketStatusHeightKey returns the key of the ticket status height index
+// entry of a ticket which changes status at height.
+func ticketStatusHeightKey(height int32, hash *chainhash.Hash) []byte {
+	key := make([]byte, int32Size+hashSize)
+	binary.BigEndian.PutUint32(key[:4], uint32(height))
+	copy(key[int32Size:], hash[:])
+	return key
+}
+
+// putTicketStatusHeight indexes a ticket which changes status at height.
+func putTicketStatusHeight(tx walletdb.Tx, height int32,
+	hash *chainhash.Hash) error {
+	bucket := tx.RootBucket().Bucket(ticketStatusHeightName)
+
+	err := bucket.Put(ticketStatusHeightKey(height, hash), []byte{})
+	if err != nil {
+		str := fmt.Sprintf("failed to index status height of ticket '%s'",
+			hash)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// deleteTicketStatusHeight removes the index entry of a ticket which changes
+// status at height.
+func deleteTicketStatusHeight(tx walletdb.Tx, height int32,
+	hash *chainhash.Hash) error {
+	bucket := tx.RootBucket().Bucket(ticketStatusHeightName)
+
+	err := bucket.Delete(ticketStatusHeightKey(height, hash))
+	if err != nil {
+		str := fmt.Sprintf("failed to remove status height index of "+
+			"ticket '%s'", hash)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// fetchTicketStatusHeights returns the hashes of the indexed tickets which
+// change status at or before height.
+func fetchTicketStatusHeights(tx walletdb.Tx,
+	height int32) ([]chainhash.Hash, error) {
+	bucket := tx.RootBucket().Bucket(ticketStatusHeightName)
+
+	var hashes []chainhash.Hash
+	c := bucket.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		if len(k) != int32Size+hashSize {
+			str := "bad size for ticket status height key"
+			return nil, stakeStoreError(ErrDatabase, str, nil)
+		}
+		if int32(binary.BigEndian.Uint32(k[:4])) > height {
+			break
+		}
+		var hash chainhash.Hash
+		copy(hash[:], k[int32Size:])
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// deserializeSplitTicket deserializes the passed serialized split ticket
+// record for the ticket with the passed hash.
+func deserializeSplitTicket(hash *chainhash.Hash,
+	serializedRecord []byte) (*SplitTicket, error) {
+	if len(serializedRecord) != splitTicketRecordSize {
+		str := "bad size for serialized split ticket record"
+		return nil, stakeStoreError(ErrDatabase, str, nil)
+	}
+
+	return &SplitTicket{
+		Ticket: *hash,
+		Contribution: dcrutil.Amount(
+			byteOrder.Uint64(serializedRecord[0:8])),
+		Total: dcrutil.Amount(byteOrder.Uint64(serializedRecord[8:16])),
+	}, nil
+}
+
+// serializeSplitTicket serializes the passed split ticket record.
+func serializeSplitTicket(record *SplitTicket) []byte {
+	buf := make([]byte, splitTicketRecordSize)
+	byteOrder.PutUint64(buf[0:8], uint64(record.Contribution))
+	byteOrder.PutUint64(buf[8:16], uint64(record.Total))
+	return buf
+}
+
+// fetchSplitTicket retrieves a split ticket record from the split tickets
+// bucket.  A nil record is returned if the ticket is not a split ticket.
+func fetchSplitTicket(tx walletdb.Tx, hash *chainhash.Hash) (*SplitTicket,
+	error) {
+	bucket := tx.RootBucket().Bucket(splitTicketsBucketName)
+
+	val := bucket.Get(hash.Bytes())
+	if val == nil {
+		return nil, nil
+	}
+
+	return deserializeSplitTicket(hash, val)
+}
+
+// fetchAllSplitTickets retrieves all records from the split tickets bucket.
+func fetchAllSplitTickets(tx walletdb.Tx) ([]*SplitTicket, error) {
+	bucket := tx.RootBucket().Bucket(splitTicketsBucketName)
+
+	var records []*SplitTicket
+	err := bucket.ForEach(func(k []byte, v []byte) error {
+		hash, err := chainhash.NewHash(k)
+		if err != nil {
+			return err
+		}
+		record, err := deserializeSplitTicket(hash, v)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// putSplitTicket inserts or updates a record in the split tickets bucket.
+func putSplitTicket(tx walletdb.Tx, record *SplitTicket) error {
+	bucket := tx.RootBucket().Bucket(splitTicketsBucketName)
+
+	err := bucket.Put(record.Ticket.Bytes(), serializeSplitTicket(record))
+	if err != nil {
+		str := fmt.Sprintf("failed to store split ticket '%s'",
+			record.Ticket)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// deserializeVoteChoice deserializes the passed serialized vote choice for
+// the agenda with the passed id.
+func deserializeVoteChoice(agendaID string,
+	serializedChoice []byte) (*VoteChoice, error) {
+	if len(serializedChoice) < 2*int16Size {
+		str := "bad size for serialized vote choice"
+		return nil, stakeStoreError(ErrDatabase, str, nil)
+	}
+
+	return &VoteChoice{
+		AgendaID: agendaID,
+		ChoiceID: string(serializedChoice[4:]),
+		Mask:     byteOrder.Uint16(serializedChoice[0:2]),
+		Bits:     byteOrder.Uint16(serializedChoice[2:4]),
+	}, nil
+}
+
+// serializeVoteChoice serializes the passed vote choice.
+func serializeVoteChoice(choice *VoteChoice) []byte {
+	buf := make([]byte, 2*int16Size+len(choice.ChoiceID))
+	byteOrder.PutUint16(buf[0:2], choice.Mask)
+	byteOrder.PutUint16(buf[2:4], choice.Bits)
+	copy(buf[4:], choice.ChoiceID)
+	return buf
+}
+
+// voteChoiceKey returns the key of a vote choice for an agenda.  Choices
+// for a ticket are keyed by the ticket hash followed by the agenda id, while
+// choices for the wallet are keyed by the agenda id alone.
+func voteChoiceKey(ticket *chainhash.Hash, agendaID string) []byte {
+	if ticket == nil {
+		return []byte(agendaID)
+	}
+	key := make([]byte, hashSize+len(agendaID))
+	copy(key, ticket[:])
+	copy(key[hashSize:], agendaID)
+	return key
+}
+
+// voteChoiceBucket returns the bucket holding the vote choices for a ticket,
+// or for the wallet if the ticket is nil.
+func voteChoiceBucket(tx walletdb.Tx, ticket *chainhash.Hash) walletdb.Bucket {
+	if ticket == nil {
+		return tx.RootBucket().Bucket(agendaChoicesBucketName)
+	}
+	return tx.RootBucket().Bucket(ticketChoicesBucketName)
+}
+
+// fetchVoteChoices retrieves the vote choices set for a ticket, or the vote
+// choices set for the wallet if the ticket is nil.
+func fetchVoteChoices(tx walletdb.Tx, ticket *chainhash.Hash) ([]*VoteChoice,
+	error) {
+	bucket := voteChoiceBucket(tx, ticket)
+
+	var choices []*VoteChoice
+	prefix := voteChoiceKey(ticket, "")
+	c := bucket.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		choice, err := deserializeVoteChoice(string(k[len(prefix):]), v)
+		if err != nil {
+			return nil, err
+		}
+		choices = append(choices, choice)
+	}
+
+	return choices, nil
+}
+
+// putVoteChoice inserts or updates a vote choice for a ticket, or for the
+// wallet if the ticket is nil.
+func putVoteChoice(tx walletdb.Tx, ticket *chainhash.Hash,
+	choice *VoteChoice) error {
+	bucket := voteChoiceBucket(tx, ticket)
+
+	err := bucket.Put(voteChoiceKey(ticket, choice.AgendaID),
+		serializeVoteChoice(choice))
+	if err != nil {
+		str := fmt.Sprintf("failed to store vote choice for agenda '%s'",
+			choice.AgendaID)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// deleteVoteChoice removes the vote choice for an agenda from a ticket, or
+// from the wallet if the ticket is nil.
+func deleteVoteChoice(tx walletdb.Tx, ticket *chainhash.Hash,
+	agendaID string) error {
+	bucket := voteChoiceBucket(tx, ticket)
+
+	err := bucket.Delete(voteChoiceKey(ticket, agendaID))
+	if err != nil {
+		str := fmt.Sprintf("failed to delete vote choice for agenda '%s'",
+			agendaID)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// deserializeTicketBatch deserializes the passed serialized ticket batch
+// with the passed id.
+func deserializeTicketBatch(id uint32, serializedBatch []byte) (*TicketBatch,
+	error) {
+	if len(serializedBatch) < ticketBatchHeaderSize {
+		str := "bad size for serialized ticket batch"
+		return nil, stakeStoreError(ErrDatabase, str, nil)
+	}
+
+	count := int(byteOrder.Uint32(serializedBatch[12:16]))
+	labelOffset := ticketBatchHeaderSize + count*hashSize
+	if len(serializedBatch) < labelOffset {
+		str := "bad size for serialized ticket batch tickets"
+		return nil, stakeStoreError(ErrDatabase, str, nil)
+	}
+
+	batch := &TicketBatch{
+		ID:      id,
+		Height:  int32(byteOrder.Uint32(serializedBatch[0:4])),
+		Time:    time.Unix(int64(byteOrder.Uint64(serializedBatch[4:12])), 0),
+		Tickets: make([]chainhash.Hash, count),
+		Label:   string(serializedBatch[labelOffset:]),
+	}
+	for i := range batch.Tickets {
+		offset := ticketBatchHeaderSize + i*hashSize
+		copy(batch.Tickets[i][:], serializedBatch[offset:offset+hashSize])
+	}
+
+	return batch, nil
+}
+
+// serializeTicketBatch serializes the passed ticket batch.
+func serializeTicketBatch(batch *TicketBatch) []byte {
+	labelOffset := ticketBatchHeaderSize + len(batch.Tickets)*hashSize
+	buf := make([]byte, labelOffset+len(batch.Label))
+	byteOrder.PutUint32(buf[0:4], uint32(batch.Height))
+	byteOrder.PutUint64(buf[4:12], uint64(batch.Time.Unix()))
+	byteOrder.PutUint32(buf[12:16], uint32(len(batch.Tickets)))
+	for i := range batch.Tickets {
+		offset := ticketBatchHeaderSize + i*hashSize
+		copy(buf[offset:offset+hashSize], batch.Tickets[i][:])
+	}
+	copy(buf[labelOffset:], batch.Label)
+	return buf
+}
+
+// ticketBatchKey returns the key of the ticket batch with the passed id.
+// Ids are encoded big endian so batches are iterated in creation order.
+func ticketBatchKey(id uint32) []byte {
+	var key [4]byte
+	binary.BigEndian.PutUint32(key[:], id)
+	return key[:]
+}
+
+// putTicketBatch stores a new ticket batch, assigning it the id following
+// the id of the last stored batch, and tags each ticket of the batch with the
+// batch id.  The assigned id is returned.
+func putTicketBatch(tx walletdb.Tx, batch *TicketBatch) (uint32, error) {
+	bucket := tx.RootBucket().Bucket(ticketBatchesBucketName)
+
+	id := uint32(1)
+	if k, _ := bucket.Cursor().Last(); k != nil {
+		id = binary.BigEndian.Uint32(k) + 1
+	}
+	batch.ID = id
+
+	err := bucket.Put(ticketBatchKey(id), serializeTicketBatch(batch))
+	if err != nil {
+		str := fmt.Sprintf("failed to store ticket batch %d", id)
+		return 0, stakeStoreError(ErrDatabase, str, err)
+	}
+
+	ticketsBucket := tx.RootBucket().Bucket(batchTicketsBucketName)
+	for i := range batch.Tickets {
+		err := ticketsBucket.Put(batch.Tickets[i][:], uint32ToBytes(id))
+		if err != nil {
+			str := fmt.Sprintf("failed to store batch of ticket '%s'",
+				batch.Tickets[i])
+			return 0, stakeStoreError(ErrDatabase, str, err)
+		}
+	}
+
+	return id, nil
+}
+
+// fetchTicketBatchID retrieves the id of the batch a ticket was purchased
+// in.  Zero is returned if the ticket does not belong to a batch.
+func fetchTicketBatchID(tx walletdb.Tx, hash *chainhash.Hash) uint32 {
+	bucket := tx.RootBucket().Bucket(batchTicketsBucketName)
+
+	val := bucket.Get(hash.Bytes())
+	if len(val) != int32Size {
+		return 0
+	}
+	return byteOrder.Uint32(val)
+}
+
+// fetchAllTicketBatches retrieves all ticket batches in creation order.
+func fetchAllTicketBatches(tx walletdb.Tx) ([]*TicketBatch, error) {
+	bucket := tx.RootBucket().Bucket(ticketBatchesBucketName)
+
+	var batches []*TicketBatch
+	err := bucket.ForEach(func(k []byte, v []byte) error {
+		if len(k) != int32Size {
+			str := "bad size for ticket batch key"
+			return stakeStoreError(ErrDatabase, str, nil)
+		}
+		batch, err := deserializeTicketBatch(binary.BigEndian.Uint32(k), v)
+		if err != nil {
+			return err
+		}
+		batches = append(batches, batch)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+// deserializeTicketBuyerDecision deserializes the passed serialized ticket
+// buyer decision with the passed id.
+func deserializeTicketBuyerDecision(id uint64,
+	serializedDecision []byte) (*TicketBuyerDecision, error) {
+	if len(serializedDecision) < ticketBuyerDecisionHeaderSize {
+		str := "bad size for serialized ticket buyer decision"
+		return nil, stakeStoreError(ErrDatabase, str, nil)
+	}
+
+	b := serializedDecision
+	return &TicketBuyerDecision{
+		ID:          id,
+		Height:      int32(byteOrder.Uint32(b[0:4])),
+		Time:        time.Unix(int64(byteOrder.Uint64(b[4:12])), 0),
+		TicketPrice: dcrutil.Amount(byteOrder.Uint64(b[12:20])),
+		Spendable:   dcrutil.Amount(byteOrder.Uint64(b[20:28])),
+		MempoolFee:  dcrutil.Amount(byteOrder.Uint64(b[28:36])),
+		FeeRate:     dcrutil.Amount(byteOrder.Uint64(b[36:44])),
+		Attempted:   int(byteOrder.Uint32(b[44:48])),
+		Purchased:   int(byteOrder.Uint32(b[48:52])),
+		Batch:       byteOrder.Uint32(b[52:56]),
+		Reason:      string(b[ticketBuyerDecisionHeaderSize:]),
+	}, nil
+}
+
+// serializeTicketBuyerDecision serializes the passed ticket buyer decision.
+func serializeTicketBuyerDecision(d *TicketBuyerDecision) []byte {
+	buf := make([]byte, ticketBuyerDecisionHeaderSize+len(d.Reason))
+	byteOrder.PutUint32(buf[0:4], uint32(d.Height))
+	byteOrder.PutUint64(buf[4:12], uint64(d.Time.Unix()))
+	byteOrder.PutUint64(buf[12:20], uint64(d.TicketPrice))
+	byteOrder.PutUint64(buf[20:28], uint64(d.Spendable))
+	byteOrder.PutUint64(buf[28:36], uint64(d.MempoolFee))
+	byteOrder.PutUint64(buf[36:44], uint64(d.FeeRate))
+	byteOrder.PutUint32(buf[44:48], uint32(d.Attempted))
+	byteOrder.PutUint32(buf[48:52], uint32(d.Purchased))
+	byteOrder.PutUint32(buf[52:56], d.Batch)
+	copy(buf[ticketBuyerDecisionHeaderSize:], d.Reason)
+	return buf
+}
+
+// putTicketBuyerDecision stores a new ticket buyer decision, assigning it the
+// id following the id of the last stored decision.  The oldest decisions are
+// removed so that at most max decisions are kept, unless max is zero.
+func putTicketBuyerDecision(tx walletdb.Tx, d *TicketBuyerDecision,
+	max int) error {
+	bucket := tx.RootBucket().Bucket(ticketBuyerDecisionsName)
+
+	id := uint64(1)
+	if k, _ := bucket.Cursor().Last(); k != nil {
+		id = binary.BigEndian.Uint64(k) + 1
+	}
+	d.ID = id
+
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], id)
+	err := bucket.Put(key[:], serializeTicketBuyerDecision(d))
+	if err != nil {
+		str := fmt.Sprintf("failed to store ticket buyer decision %d", id)
+		return stakeStoreError(ErrDatabase, str, err)
+	}
+
+	if max <= 0 || id <= uint64(max) {
+		return nil
+	}
+	var expired [][]byte
+	c := bucket.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		if binary.BigEndian.Uint64(k) > id-uint64(max) {
+			break
+		}
+		expired = append(expired, append([]byte(nil), k...))
+	}
+	for _, k := range expired {
+		err := bucket.Delete(k)
+		if err != nil {
+			str := "failed to remove expired ticket buyer decision"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+	}
+	return nil
+}
+
+// fetchTicketBuyerDecisions retrieves the count most recent ticket buyer
+// decisions, or all decisions if count is not positive, oldest first.
+func fetchTicketBuyerDecisions(tx walletdb.Tx,
+	count int) ([]*TicketBuyerDecision, error) {
+	bucket := tx.RootBucket().Bucket(ticketBuyerDecisionsName)
+
+	var decisions []*TicketBuyerDecision
+	c := bucket.Cursor()
+	for k, v := c.Last(); k != nil; k, v = c.Prev() {
+		if count > 0 && len(decisions) >= count {
+			break
+		}
+		if len(k) != int64Size {
+			str := "bad size for ticket buyer decision key"
+			return nil, stakeStoreError(ErrDatabase, str, nil)
+		}
+		d, err := deserializeTicketBuyerDecision(
+			binary.BigEndian.Uint64(k), v)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, d)
+	}
+
+	// Reverse the decisions so the oldest is first.
+	for i, j := 0, len(decisions)-1; i < j; i, j = i+1, j-1 {
+		decisions[i], decisions[j] = decisions[j], decisions[i]
+	}
+	return decisions, nil
+}
+
 // putMeta
 func putMeta(tx walletdb.Tx, key []byte, n int32) error {
 	bucket := tx.RootBucket().Bucket(metaBucketName)
@@ -813,6 +1692,72 @@ func initializeEmpty(namespace walletdb.Namespace) error {
 			return stakeStoreError(ErrDatabase, str, err)
 		}
 
+		_, err = rootBucket.CreateBucketIfNotExists(ticketVoteBitsBucketName)
+		if err != nil {
+			str := "failed to create ticket vote bits bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(stakePoolUserBucketName)
+		if err != nil {
+			str := "failed to create stake pool user bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(stakePoolInvalBucketName)
+		if err != nil {
+			str := "failed to create stake pool invalid tickets bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(ticketStatusBucketName)
+		if err != nil {
+			str := "failed to create ticket status bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(ticketStatusHeightName)
+		if err != nil {
+			str := "failed to create ticket status height bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(splitTicketsBucketName)
+		if err != nil {
+			str := "failed to create split tickets bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(agendaChoicesBucketName)
+		if err != nil {
+			str := "failed to create agenda choices bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(ticketChoicesBucketName)
+		if err != nil {
+			str := "failed to create ticket choices bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(ticketBatchesBucketName)
+		if err != nil {
+			str := "failed to create ticket batches bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(batchTicketsBucketName)
+		if err != nil {
+			str := "failed to create batch tickets bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(ticketBuyerDecisionsName)
+		if err != nil {
+			str := "failed to create ticket buyer decisions bucket"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+
 		// Save the most recent tx store version if it isn't already
 		// there, otherwise keep track of it for potential upgrades.
 		verBytes := mainBucket.Get(stakeStoreVersionName)
@@ -854,3 +1799,104 @@ func initializeEmpty(namespace walletdb.Namespace) error {
 
 	return nil
 }
+
+// upgradeStakeStore upgrades the stake store to the latest version.  Stores
+// older than ticketStatusIndexVersion are given a status record for every
+// ticket which was owned before ticket statuses were tracked, and the tickets
+// awaiting a status change are indexed by the height of that change.  The
+// status of backfilled tickets is taken from their recorded votes and
+// revocations; other backfilled tickets are recorded as unmined, and stop
+// being tracked when the next block is connected unless the wallet finds the
+// height they were mined at first.
+func upgradeStakeStore(namespace walletdb.Namespace,
+	params *chaincfg.Params) error {
+	err := namespace.Update(func(tx walletdb.Tx) error {
+		mainBucket := tx.RootBucket().Bucket(mainBucketName)
+		version := byteOrder.Uint32(mainBucket.Get(stakeStoreVersionName))
+		if version >= ticketStatusIndexVersion {
+			return nil
+		}
+
+		ssgenBucket := tx.RootBucket().Bucket(ssgenRecordsBucketName)
+		ssrtxBucket := tx.RootBucket().Bucket(ssrtxRecordsBucketName)
+		var hashes []chainhash.Hash
+		err := tx.RootBucket().Bucket(sstxRecordsBucketName).ForEach(
+			func(k []byte, v []byte) error {
+				hash, err := chainhash.NewHash(k)
+				if err != nil {
+					return err
+				}
+				hashes = append(hashes, *hash)
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+
+		for i := range hashes {
+			hash := &hashes[i]
+			record, err := fetchTicketStatus(tx, hash)
+			if err != nil {
+				return err
+			}
+			if record != nil {
+				continue
+			}
+
+			record = &ticketStatusRecord{status: TicketStatusUnmined}
+			if v := ssgenBucket.Get(hash[:]); v != nil {
+				ssgens, err := deserializeSSGenRecords(v)
+				if err != nil {
+					return err
+				}
+				if len(ssgens) > 0 {
+					record.status = TicketStatusVoted
+					record.statusHeight = int32(ssgens[0].blockHeight)
+				}
+			} else if v := ssrtxBucket.Get(hash[:]); v != nil {
+				ssrtxs, err := deserializeSSRtxRecords(v)
+				if err != nil {
+					return err
+				}
+				if len(ssrtxs) > 0 {
+					record.status = TicketStatusRevoked
+					record.statusHeight = int32(ssrtxs[0].blockHeight)
+				}
+			}
+			err = putTicketStatus(tx, hash, record)
+			if err != nil {
+				return err
+			}
+		}
+
+		records, err := fetchAllTicketStatuses(tx)
+		if err != nil {
+			return err
+		}
+		for hash, record := range records {
+			height, ok := pendingStatusHeight(record, params)
+			if !ok {
+				continue
+			}
+			hash := hash
+			err := putTicketStatusHeight(tx, height, &hash)
+			if err != nil {
+				return err
+			}
+		}
+
+		var buf [4]byte
+		byteOrder.PutUint32(buf[:], ticketStatusIndexVersion)
+		err = mainBucket.Put(stakeStoreVersionName, buf[:])
+		if err != nil {
+			str := "failed to store database version"
+			return stakeStoreError(ErrDatabase, str, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
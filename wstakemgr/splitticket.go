@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// SplitTicket is a ticket funded by the wallet together with other
+// participants.  Contribution is the sum of the ticket commitments paying
+// the wallet, and Total is the sum of all commitments of the ticket.  Vote
+// and revocation outputs are paid in proportion to the commitments, so the
+// wallet receives Contribution/Total of the returned funds and rewards.
+type SplitTicket struct {
+	Ticket       chainhash.Hash
+	Contribution dcrutil.Amount
+	Total        dcrutil.Amount
+}
+
+// InsertSplitTicket records a split ticket the wallet contributed to.
+func (s *StakeStore) InsertSplitTicket(record *SplitTicket) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return putSplitTicket(tx, record)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// SplitTicket returns the split ticket record for a ticket, or nil if the
+// wallet did not contribute to the ticket as a split ticket.
+func (s *StakeStore) SplitTicket(hash *chainhash.Hash) (*SplitTicket, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var record *SplitTicket
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		record, err = fetchSplitTicket(tx, hash)
+		return err
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return record, nil
+}
+
+// SplitTickets returns all split tickets the wallet contributed to.
+func (s *StakeStore) SplitTickets() ([]*SplitTicket, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var records []*SplitTicket
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		records, err = fetchAllSplitTickets(tx)
+		return err
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return records, nil
+}
@@ -82,6 +82,14 @@ type StakeStore struct {
 	isClosed  bool
 
 	ownedSStxs map[chainhash.Hash]struct{}
+
+	// The vote cache holds the owned tickets and the managed addresses
+	// used to sign votes.  It is protected by voteMtx rather than mtx so
+	// that votes are created and published without waiting on database
+	// updates made by other operations of the store.
+	voteMtx     *sync.Mutex
+	voteTickets map[chainhash.Hash]*voteTicket
+	voteAddrs   map[string]waddrmgr.ManagedAddress
 }
 
 // StakeNotification is the data structure that contains information
@@ -92,9 +100,10 @@ type StakeNotification struct {
 	TxHash    chainhash.Hash
 	BlockHash chainhash.Hash // SSGen only
 	Height    int32          // SSGen only
-	Amount    int64          // SStx only
+	Amount    int64          // SStx and SSRtx only
 	SStxIn    chainhash.Hash // SSGen and SSRtx
 	VoteBits  uint16         // SSGen only
+	Tx        *wire.MsgTx    // Signed SSGen and SSRtx created by the wallet
 }
 
 // checkHashInStore checks if a hash exists in ownedSStxs.
@@ -150,6 +159,7 @@ func (s *StakeStore) insertSStx(sstx *dcrutil.Tx) error {
 
 	// Add the SStx's hash to the internal list in the store.
 	s.addHashToStore(sstx.Sha())
+	s.cacheVoteTicket(sstx)
 
 	return nil
 }
@@ -255,6 +265,37 @@ func (s *StakeStore) DumpSStxHashesForAddress(addr dcrutil.Address) ([]chainhash
 	return s.dumpSStxHashesForAddress(addr)
 }
 
+// UnresolvedTickets returns the hashes of all owned tickets for which neither
+// a vote nor a revocation has been recorded.
+func (s *StakeStore) UnresolvedTickets() ([]chainhash.Hash, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var unresolved []chainhash.Hash
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		ssgenBucket := tx.RootBucket().Bucket(ssgenRecordsBucketName)
+		ssrtxBucket := tx.RootBucket().Bucket(ssrtxRecordsBucketName)
+		for hash := range s.ownedSStxs {
+			if ssgenBucket.Get(hash[:]) != nil ||
+				ssrtxBucket.Get(hash[:]) != nil {
+				continue
+			}
+			unresolved = append(unresolved, hash)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return unresolved, nil
+}
+
 // A function to get a single owned SStx.
 func (s *StakeStore) getSStx(hash *chainhash.Hash) (*sstxRecord, error) {
 	var record *sstxRecord
@@ -388,7 +429,7 @@ func (s *StakeStore) SignVRTransaction(msgTx *wire.MsgTx, sstx *dcrutil.Tx,
 	// look up the appropriate keys and scripts by address.
 	getKey := txscript.KeyClosure(func(addr dcrutil.Address) (
 		chainec.PrivateKey, bool, error) {
-		address, err := s.Manager.Address(addr)
+		address, err := s.managedAddress(addr)
 		if err != nil {
 			return nil, false, err
 		}
@@ -409,7 +450,7 @@ func (s *StakeStore) SignVRTransaction(msgTx *wire.MsgTx, sstx *dcrutil.Tx,
 
 	getScript := txscript.ScriptClosure(func(
 		addr dcrutil.Address) ([]byte, error) {
-		address, err := s.Manager.Address(addr)
+		address, err := s.managedAddress(addr)
 		if err != nil {
 			return nil, err
 		}
@@ -461,25 +502,23 @@ func (s *StakeStore) SignVRTransaction(msgTx *wire.MsgTx, sstx *dcrutil.Tx,
 	return nil
 }
 
-// GenerateVote creates a new SSGen given a header hash, height, sstx
-// tx hash, and votebits.
+// generateVote creates, signs and publishes a new SSGen given a header hash,
+// height, cached ticket, and votebits.  The vote is not recorded in the
+// database, which is left to the caller after all votes are published.
 func (s *StakeStore) generateVote(blockHash *chainhash.Hash, height int64,
-	sstxHash *chainhash.Hash, voteBits uint16) (*StakeNotification, error) {
-	// 1. Fetch the SStx, then calculate all the values we'll need later for
-	// the generation of the SSGen tx outputs.
-	sstxRecord, err := s.getSStx(sstxHash)
-	if err != nil {
-		return nil, err
-	}
-	sstx := sstxRecord.tx
+	ticket *voteTicket, voteBits uint16) (*StakeNotification, error) {
+	// 1. Use the cached SStx, then calculate all the values we'll need later
+	// for the generation of the SSGen tx outputs.
+	sstx := ticket.sstx
+	sstxHash := sstx.Sha()
 	sstxMsgTx := sstx.MsgTx()
 
-	// Store the sstx pubkeyhashes and amounts as found in the transaction
+	// The sstx pubkeyhashes and amounts as found in the transaction
 	// outputs.
 	// TODO Get information on the allowable fee range for the vote
 	// and check to make sure we don't overflow that.
-	ssgenPayTypes, ssgenPkhs, sstxAmts, _, _, _ :=
-		stake.GetSStxStakeOutputInfo(sstx)
+	ssgenPayTypes, ssgenPkhs, sstxAmts := ticket.payTypes, ticket.pkhs,
+		ticket.amounts
 
 	// Get the current reward.
 	stakeVoteSubsidy := blockchain.CalcStakeVoteSubsidy(height,
@@ -578,16 +617,6 @@ func (s *StakeStore) generateVote(blockHash *chainhash.Hash, height int64,
 		return nil, err
 	}
 
-	// Store the information about the SSGen.
-	err = s.insertSSGen(blockHash,
-		height,
-		ssgenSha,
-		voteBits,
-		sstx.Sha())
-	if err != nil {
-		return nil, err
-	}
-
 	log.Debugf("Generated SSGen %v , voting on block %v at height %v. "+
 		"The ticket used to generate the SSGen was %v.",
 		ssgenSha, blockHash, height, sstxHash)
@@ -601,6 +630,7 @@ func (s *StakeStore) generateVote(blockHash *chainhash.Hash, height int64,
 		Amount:    0,
 		SStxIn:    *sstx.Sha(),
 		VoteBits:  voteBits,
+		Tx:        msgTx,
 	}
 
 	return ntfn, nil
@@ -781,15 +811,23 @@ func (s *StakeStore) generateRevocation(blockHash *chainhash.Hash, height int64,
 		"The ticket used to generate the SSRtx was %v.",
 		ssrtxSha, sstx.Sha())
 
+	// The amount of a revocation notification is the total amount
+	// returned to the ticket's commitment addresses.
+	recovered := int64(0)
+	for _, txOut := range msgTx.TxOut {
+		recovered += txOut.Value
+	}
+
 	// Generate a notification to return.
 	ntfn := &StakeNotification{
 		TxType:    int8(stake.TxTypeSSRtx),
 		TxHash:    *ssrtxSha,
 		BlockHash: chainhash.Hash{},
 		Height:    0,
-		Amount:    0,
+		Amount:    recovered,
 		SStxIn:    *sstx.Sha(),
 		VoteBits:  0,
+		Tx:        msgTx,
 	}
 
 	return ntfn, nil
@@ -798,7 +836,12 @@ func (s *StakeStore) generateRevocation(blockHash *chainhash.Hash, height int64,
 // HandleWinningTicketsNtfn scans the list of eligible tickets and, if any
 // of these tickets in the sstx store match these tickets, spends them as
 // votes.
-func (s StakeStore) HandleWinningTicketsNtfn(blockHash *chainhash.Hash,
+//
+// Votes are time critical, so they are created from the vote cache and all
+// of them are published before any is recorded in the database.  Only the
+// recording waits on mtx, so votes are not delayed by database updates of
+// other operations of the store, such as those made during a rescan.
+func (s *StakeStore) HandleWinningTicketsNtfn(blockHash *chainhash.Hash,
 	blockHeight int64,
 	tickets []*chainhash.Hash,
 	voteBits uint16) ([]*StakeNotification, error) {
@@ -807,39 +850,71 @@ func (s StakeStore) HandleWinningTicketsNtfn(blockHash *chainhash.Hash,
 		return nil, stakeStoreError(ErrStoreClosed, str, nil)
 	}
 
-	s.mtx.Lock()
-	defer s.mtx.Unlock()
-
 	// Go through the list of tickets and see any of the
 	// ones we own match those eligible.
-	ticketsToPull := make([]*chainhash.Hash, 0)
-
-	// Lock the mutex because checkHashInStore touches
-	// a mutable element of StakeStore s.
-	for _, ticket := range tickets {
-		if s.checkHashInStore(ticket) {
-			ticketsToPull = append(ticketsToPull, ticket)
-		}
-	}
+	ticketsToPull := s.winningVoteTickets(tickets)
 
 	// No matching tickets (boo!), return.
 	if len(ticketsToPull) == 0 {
 		return nil, nil
 	}
 
+	// Use the vote bits set for each individual ticket, falling back to
+	// the passed vote bits with the wallet's agenda vote choices applied
+	// for tickets without their own setting.  Agenda vote choices set for
+	// a ticket are always applied last.
+	ticketVoteBits := make([]uint16, len(ticketsToPull))
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		walletChoices, err := fetchVoteChoices(tx, nil)
+		if err != nil {
+			return err
+		}
+		defaultVoteBits := applyVoteChoices(voteBits, walletChoices)
+
+		for i, ticket := range ticketsToPull {
+			ticketHash := ticket.sstx.Sha()
+			ticketVoteBits[i] = defaultVoteBits
+			if vb, ok := fetchTicketVoteBits(tx, ticketHash); ok {
+				ticketVoteBits[i] = vb
+			}
+
+			ticketChoices, err := fetchVoteChoices(tx, ticketHash)
+			if err != nil {
+				return err
+			}
+			ticketVoteBits[i] = applyVoteChoices(ticketVoteBits[i],
+				ticketChoices)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	ntfns := make([]*StakeNotification, len(ticketsToPull), len(ticketsToPull))
 	voteErrors := make([]error, len(ticketsToPull), len(ticketsToPull))
-	// Matching tickets (yay!), generate some SSGen.
+	// Matching tickets (yay!), generate and publish some SSGen.
 	for i, ticket := range ticketsToPull {
-		ntfns[i], voteErrors[i] = s.generateVote(blockHash, blockHeight, ticket,
-			voteBits)
+		ntfns[i], voteErrors[i] = s.generateVote(blockHash, blockHeight,
+			ticket, ticketVoteBits[i])
 	}
 
+	// Store the information about the published SSGen.
+	s.mtx.Lock()
+	for i, ntfn := range ntfns {
+		if ntfn == nil {
+			continue
+		}
+		voteErrors[i] = s.insertSSGen(blockHash, blockHeight, &ntfn.TxHash,
+			ntfn.VoteBits, &ntfn.SStxIn)
+	}
+	s.mtx.Unlock()
+
 	errStr := ""
 	for i, err := range voteErrors {
 		if err != nil {
 			errStr += fmt.Sprintf("Error encountered attempting to create "+
-				"vote using ticket %v: ", ticketsToPull[i])
+				"vote using ticket %v: ", ticketsToPull[i].sstx.Sha())
 			errStr += err.Error()
 			errStr += "\n"
 		}
@@ -852,6 +927,80 @@ func (s StakeStore) HandleWinningTicketsNtfn(blockHash *chainhash.Hash,
 	return ntfns, nil
 }
 
+// VoteBitsForTicket returns the vote bits set for an owned ticket.  The
+// returned bool is false if the ticket has no vote bits of its own, in which
+// case the wallet's global vote bits are used when voting.
+func (s *StakeStore) VoteBitsForTicket(ticket *chainhash.Hash) (uint16, bool,
+	error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return 0, false, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var voteBits uint16
+	var ok bool
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		voteBits, ok = fetchTicketVoteBits(tx, ticket)
+		return nil
+	})
+	if err != nil {
+		return 0, false, maybeConvertDbError(err)
+	}
+
+	return voteBits, ok, nil
+}
+
+// SetVoteBitsForTicket sets the vote bits used when voting with an owned
+// ticket, overriding the wallet's global vote bits.
+func (s *StakeStore) SetVoteBitsForTicket(ticket *chainhash.Hash,
+	voteBits uint16) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !s.checkHashInStore(ticket) {
+		str := fmt.Sprintf("ticket %v is not owned by the wallet", ticket)
+		return stakeStoreError(ErrSStxNotFound, str, nil)
+	}
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return putTicketVoteBits(tx, ticket, voteBits)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// ClearVoteBitsForTicket removes the vote bits set for a ticket, so that the
+// wallet's global vote bits are used when voting with it.
+func (s *StakeStore) ClearVoteBitsForTicket(ticket *chainhash.Hash) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return deleteTicketVoteBits(tx, ticket)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
 // HandleMissedTicketsNtfn scans the list of missed tickets and, if any
 // of these tickets in the sstx store match these tickets, spends them as
 // SSRtx.
@@ -915,6 +1064,7 @@ func (s *StakeStore) loadOwnedSStxs(namespace walletdb.Namespace) error {
 	// Regenerate the list of tickets.
 	// Perform all database lookups in a read-only view.
 	ticketList := make(map[chainhash.Hash]struct{})
+	voteTickets := make(map[chainhash.Hash]*voteTicket)
 
 	err := namespace.View(func(tx walletdb.Tx) error {
 		var errForEach error
@@ -932,6 +1082,13 @@ func (s *StakeStore) loadOwnedSStxs(namespace walletdb.Namespace) error {
 				return errNewHash
 			}
 			ticketList[*hash] = struct{}{}
+
+			// Precompute the vote information of the ticket.
+			record, err := deserializeSStxRecord(v)
+			if err != nil {
+				return err
+			}
+			voteTickets[*hash] = newVoteTicket(record.tx)
 			return nil
 		})
 
@@ -942,6 +1099,9 @@ func (s *StakeStore) loadOwnedSStxs(namespace walletdb.Namespace) error {
 	}
 
 	s.ownedSStxs = ticketList
+	s.voteMtx.Lock()
+	s.voteTickets = voteTickets
+	s.voteMtx.Unlock()
 	return nil
 }
 
@@ -964,11 +1124,16 @@ func newStakeStore(namespace walletdb.Namespace, params *chaincfg.Params,
 		chainSvr:   nil,
 		isClosed:   false,
 		ownedSStxs: make(map[chainhash.Hash]struct{}),
+
+		voteMtx:     &sync.Mutex{},
+		voteTickets: make(map[chainhash.Hash]*voteTicket),
+		voteAddrs:   make(map[string]waddrmgr.ManagedAddress),
 	}
 }
 
 // Open loads an existing stake manager from the given namespace, waddrmgr, and
-// network parameters.
+// network parameters.  The namespace may belong to a database opened
+// read-only.
 //
 // A ManagerError with an error code of ErrNoExist will be returned if the
 // passed manager does not exist in the specified namespace.
@@ -985,6 +1150,26 @@ func Open(namespace walletdb.Namespace, manager *waddrmgr.Manager,
 		return nil, stakeStoreError(ErrNoExist, str, nil)
 	}
 
+	// Create any buckets which were added after the store was created.
+	// Namespaces of read-only databases can not be modified, so their
+	// stores are used as is.
+	err = initializeEmpty(namespace)
+	if serr, ok := err.(StakeStoreError); ok &&
+		serr.Err == walletdb.ErrDbReadOnly {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = upgradeStakeStore(namespace, params)
+	if serr, ok := err.(StakeStoreError); ok &&
+		serr.Err == walletdb.ErrDbReadOnly {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	ss := newStakeStore(namespace, params, manager)
 
 	err = ss.loadOwnedSStxs(namespace)
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// PoolTicketStatus describes the state of a ticket submitted to a stake
+// pool.
+type PoolTicketStatus uint8
+
+const (
+	// TSImmatureOrLive indicates the ticket has been mined but has not yet
+	// voted or been missed.
+	TSImmatureOrLive PoolTicketStatus = iota
+
+	// TSVoted indicates the ticket was used to vote.
+	TSVoted
+
+	// TSMissed indicates the ticket was missed or expired and has been
+	// revoked.
+	TSMissed
+)
+
+// String returns the PoolTicketStatus as a human-readable name.
+func (s PoolTicketStatus) String() string {
+	switch s {
+	case TSImmatureOrLive:
+		return "live"
+	case TSVoted:
+		return "voted"
+	case TSMissed:
+		return "missed"
+	}
+	return "unknown"
+}
+
+// PoolTicket is a ticket submitted to the stake pool by a user, delegating
+// voting rights to the pool.
+type PoolTicket struct {
+	Ticket       chainhash.Hash
+	HeightTicket uint32
+	Status       PoolTicketStatus
+	HeightSpent  uint32
+	SpentBy      chainhash.Hash
+}
+
+// StakePoolUser describes all tickets submitted to the stake pool by a
+// single user, identified by the address of their ticket commitment.
+// InvalidTickets are tickets which delegated voting rights to the pool but
+// did not pay the pool fee, and which the pool will not vote.
+type StakePoolUser struct {
+	Tickets        []*PoolTicket
+	InvalidTickets []*chainhash.Hash
+}
+
+// UpdateStakePoolUserTickets inserts or updates the record of a ticket
+// submitted to the stake pool by user.
+func (s *StakeStore) UpdateStakePoolUserTickets(user dcrutil.Address,
+	ticket *PoolTicket) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return updateStakePoolUserTickets(tx, user.ScriptAddress(), ticket)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// UpdateStakePoolUserInvalTickets records a ticket submitted to the stake
+// pool by user which did not pay the pool fee.
+func (s *StakeStore) UpdateStakePoolUserInvalTickets(user dcrutil.Address,
+	ticket *chainhash.Hash) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return updateStakePoolInvalUserTickets(tx, user.ScriptAddress(),
+			ticket)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// StakePoolUserInfo returns the valid and invalid tickets submitted to the
+// stake pool by user.
+func (s *StakeStore) StakePoolUserInfo(user dcrutil.Address) (*StakePoolUser,
+	error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	info := new(StakePoolUser)
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		info.Tickets, err = fetchStakePoolUserTickets(tx,
+			user.ScriptAddress())
+		if err != nil {
+			return err
+		}
+		info.InvalidTickets, err = fetchStakePoolUserInvalTickets(tx,
+			user.ScriptAddress())
+		return err
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return info, nil
+}
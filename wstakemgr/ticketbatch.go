@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// TicketBatch is a group of tickets purchased by a single run of the ticket
+// buyer.  Label describes the purchasing strategy used, so the outcomes of
+// different strategies may be compared.  Summary counts the tickets of the
+// batch in each status when the batch is returned by TicketBatches.
+type TicketBatch struct {
+	ID      uint32
+	Height  int32
+	Time    time.Time
+	Label   string
+	Tickets []chainhash.Hash
+	Summary TicketSummary
+}
+
+// InsertTicketBatch records a batch of tickets purchased at height and tags
+// each ticket with the id of the batch, which is returned.
+func (s *StakeStore) InsertTicketBatch(height int32, label string,
+	tickets []chainhash.Hash) (uint32, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return 0, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	batch := &TicketBatch{
+		Height:  height,
+		Time:    time.Now(),
+		Label:   label,
+		Tickets: tickets,
+	}
+	var id uint32
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		var err error
+		id, err = putTicketBatch(tx, batch)
+		return err
+	})
+	if err != nil {
+		return 0, maybeConvertDbError(err)
+	}
+
+	return id, nil
+}
+
+// TicketBatchID returns the id of the batch a ticket was purchased in, or
+// zero if the ticket was not purchased by the ticket buyer.
+func (s *StakeStore) TicketBatchID(hash *chainhash.Hash) (uint32, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return 0, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var id uint32
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		id = fetchTicketBatchID(tx, hash)
+		return nil
+	})
+	if err != nil {
+		return 0, maybeConvertDbError(err)
+	}
+
+	return id, nil
+}
+
+// TicketBatches returns all ticket batches in the order they were purchased,
+// with the current status of their tickets summarized.
+func (s *StakeStore) TicketBatches() ([]*TicketBatch, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var batches []*TicketBatch
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		batches, err = fetchAllTicketBatches(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, batch := range batches {
+			for i := range batch.Tickets {
+				record, err := fetchTicketStatus(tx, &batch.Tickets[i])
+				if err != nil {
+					return err
+				}
+				if record != nil {
+					batch.Summary.add(record)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return batches, nil
+}
+
+// TicketWindow returns the index of the stake difficulty window the wallet
+// last purchased tickets in and the number of tickets purchased in it, so
+// the per-window purchase limit holds across restarts.
+func (s *StakeStore) TicketWindow() (int64, int, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return 0, 0, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var window int64
+	var count uint32
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		window, count = fetchTicketWindow(tx)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, maybeConvertDbError(err)
+	}
+
+	return window, int(count), nil
+}
+
+// SetTicketWindow records the number of tickets purchased in the stake
+// difficulty window with the passed index.
+func (s *StakeStore) SetTicketWindow(window int64, count int) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return putTicketWindow(tx, window, uint32(count))
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"time"
+
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// TicketBuyerDecision records the inputs and outcome of a single run of the
+// automatic ticket buyer, so the decisions may be audited after a restart.
+// FeeRate is the fee per kB paid by the purchased tickets, and Batch is the
+// ticket batch of the purchased tickets, if any.
+type TicketBuyerDecision struct {
+	ID          uint64
+	Height      int32
+	Time        time.Time
+	TicketPrice dcrutil.Amount
+	Spendable   dcrutil.Amount
+	MempoolFee  dcrutil.Amount
+	FeeRate     dcrutil.Amount
+	Attempted   int
+	Purchased   int
+	Batch       uint32
+	Reason      string
+}
+
+// InsertTicketBuyerDecision records a ticket buyer decision, assigning it the
+// next decision id.  The oldest decisions are removed so that at most max
+// decisions are kept, unless max is zero.
+func (s *StakeStore) InsertTicketBuyerDecision(d *TicketBuyerDecision,
+	max int) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return putTicketBuyerDecision(tx, d, max)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// TicketBuyerDecisions returns the count most recent ticket buyer decisions,
+// or all recorded decisions if count is not positive, oldest first.
+func (s *StakeStore) TicketBuyerDecisions(count int) ([]*TicketBuyerDecision,
+	error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var decisions []*TicketBuyerDecision
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		decisions, err = fetchTicketBuyerDecisions(tx, count)
+		return err
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return decisions, nil
+}
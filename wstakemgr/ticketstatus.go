@@ -0,0 +1,500 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// TicketStatus describes the state of an owned ticket in its lifecycle.
+// Statuses are ordered so that a ticket only ever moves to a greater status
+// as blocks are connected; lesser statuses are only restored when blocks are
+// disconnected.
+type TicketStatus uint8
+
+const (
+	// TicketStatusUnknown indicates the ticket is not tracked by the
+	// stake store.
+	TicketStatusUnknown TicketStatus = iota
+
+	// TicketStatusUnmined indicates the ticket has not been mined.
+	TicketStatusUnmined
+
+	// TicketStatusImmature indicates the ticket has been mined but has not
+	// yet reached ticket maturity.
+	TicketStatusImmature
+
+	// TicketStatusLive indicates the ticket is mature and may be called
+	// to vote.
+	TicketStatusLive
+
+	// TicketStatusExpired indicates the ticket was not called to vote
+	// before it expired.
+	TicketStatusExpired
+
+	// TicketStatusMissed indicates the ticket was called to vote but the
+	// vote was not included in the block.
+	TicketStatusMissed
+
+	// TicketStatusVoted indicates the ticket was spent by a vote.
+	TicketStatusVoted
+
+	// TicketStatusRevoked indicates the ticket was spent by a revocation.
+	TicketStatusRevoked
+)
+
+// String returns the TicketStatus as a human-readable name.
+func (s TicketStatus) String() string {
+	switch s {
+	case TicketStatusUnmined:
+		return "unmined"
+	case TicketStatusImmature:
+		return "immature"
+	case TicketStatusLive:
+		return "live"
+	case TicketStatusExpired:
+		return "expired"
+	case TicketStatusMissed:
+		return "missed"
+	case TicketStatusVoted:
+		return "voted"
+	case TicketStatusRevoked:
+		return "revoked"
+	}
+	return "unknown"
+}
+
+// ticketStatusRecord is the status of a ticket as stored in the database.
+// statusHeight is the height of the block which moved the ticket to its
+// current status.  voteReward is the stake subsidy earned by the ticket's
+// vote.
+type ticketStatusRecord struct {
+	status       TicketStatus
+	minedHeight  int32
+	statusHeight int32
+	voteReward   dcrutil.Amount
+}
+
+// pendingStatusHeight returns the height of the block which next changes the
+// status of a ticket as blocks are connected, and whether such a block
+// exists.  Unmined tickets pay the ticket price of the stake difficulty window
+// after the block they were seen at, so they are abandoned once a block of a
+// later window is connected.  Immature tickets become live at ticket maturity
+// and live tickets expire in the block after ticket expiry.  Tickets in any
+// other status only change status on notifications about their transactions.
+func pendingStatusHeight(record *ticketStatusRecord,
+	params *chaincfg.Params) (int32, bool) {
+	window := int32(params.StakeDiffWindowSize)
+	liveHeight := record.minedHeight + int32(params.TicketMaturity)
+	switch record.status {
+	case TicketStatusUnmined:
+		return ((record.statusHeight+1)/window + 1) * window, true
+	case TicketStatusImmature:
+		return liveHeight, true
+	case TicketStatusLive:
+		return liveHeight + int32(params.TicketExpiry) + 1, true
+	}
+	return 0, false
+}
+
+// storeTicketStatus stores the status record of a ticket which previously had
+// the status record old, which may be nil, and moves the ticket's entry in the
+// ticket status height index.
+func (s *StakeStore) storeTicketStatus(tx walletdb.Tx, hash *chainhash.Hash,
+	old, record *ticketStatusRecord) error {
+	oldHeight, oldPending := int32(0), false
+	if old != nil {
+		oldHeight, oldPending = pendingStatusHeight(old, s.Params)
+	}
+	height, pending := pendingStatusHeight(record, s.Params)
+	if oldPending && (!pending || oldHeight != height) {
+		err := deleteTicketStatusHeight(tx, oldHeight, hash)
+		if err != nil {
+			return err
+		}
+	}
+	if pending && (!oldPending || oldHeight != height) {
+		err := putTicketStatusHeight(tx, height, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return putTicketStatus(tx, hash, record)
+}
+
+// TicketSummary counts the owned tickets in each status, and totals the
+// subsidy earned by the tickets which voted.
+type TicketSummary struct {
+	Unmined  int
+	Immature int
+	Live     int
+	Expired  int
+	Missed   int
+	Voted    int
+	Revoked  int
+
+	TotalSubsidy dcrutil.Amount
+}
+
+// add counts a ticket with the passed status record in the summary.
+func (summary *TicketSummary) add(record *ticketStatusRecord) {
+	switch record.status {
+	case TicketStatusUnmined:
+		summary.Unmined++
+	case TicketStatusImmature:
+		summary.Immature++
+	case TicketStatusLive:
+		summary.Live++
+	case TicketStatusExpired:
+		summary.Expired++
+	case TicketStatusMissed:
+		summary.Missed++
+	case TicketStatusVoted:
+		summary.Voted++
+		summary.TotalSubsidy += record.voteReward
+	case TicketStatusRevoked:
+		summary.Revoked++
+	}
+}
+
+// TicketStatus returns the current status of a ticket.  TicketStatusUnknown
+// is returned for tickets which are not tracked by the stake store.
+func (s *StakeStore) TicketStatus(hash *chainhash.Hash) (TicketStatus, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return TicketStatusUnknown, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var record *ticketStatusRecord
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		record, err = fetchTicketStatus(tx, hash)
+		return err
+	})
+	if err != nil {
+		return TicketStatusUnknown, maybeConvertDbError(err)
+	}
+	if record == nil {
+		return TicketStatusUnknown, nil
+	}
+
+	return record.status, nil
+}
+
+// TicketSummary returns the number of tracked tickets in each status.
+func (s *StakeStore) TicketSummary() (*TicketSummary, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var records map[chainhash.Hash]*ticketStatusRecord
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		records, err = fetchAllTicketStatuses(tx)
+		return err
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	summary := new(TicketSummary)
+	for _, record := range records {
+		summary.add(record)
+	}
+
+	return summary, nil
+}
+
+// UpdateTicketStatus records that a ticket moved to status in the block at
+// height.  For TicketStatusUnmined, height is the height of the main chain
+// tip when the ticket was seen.  For TicketStatusImmature, height is the
+// height the ticket was mined at, and the mined height is recorded even if
+// the ticket has already reached a later status.  Otherwise, updates which would move the ticket to
+// an earlier status are ignored, as notifications may arrive out of order.
+func (s *StakeStore) UpdateTicketStatus(hash *chainhash.Hash,
+	status TicketStatus, height int32) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		record, err := fetchTicketStatus(tx, hash)
+		if err != nil {
+			return err
+		}
+		var old *ticketStatusRecord
+		if record == nil {
+			record = &ticketStatusRecord{status: TicketStatusUnknown}
+		} else {
+			prev := *record
+			old = &prev
+		}
+
+		if status == TicketStatusImmature {
+			record.minedHeight = height
+		}
+		if status > record.status {
+			record.status = status
+			record.statusHeight = height
+		}
+
+		return s.storeTicketStatus(tx, hash, old, record)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// RecordVoteReward records the stake subsidy earned by the vote of a ticket
+// which has voted.
+func (s *StakeStore) RecordVoteReward(hash *chainhash.Hash,
+	reward dcrutil.Amount) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		record, err := fetchTicketStatus(tx, hash)
+		if err != nil {
+			return err
+		}
+		if record == nil || record.status != TicketStatusVoted {
+			str := fmt.Sprintf("ticket %v has not voted", hash)
+			return stakeStoreError(ErrSSGensNotFound, str, nil)
+		}
+
+		record.voteReward = reward
+		return putTicketStatus(tx, hash, record)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// ConnectTicketStatuses moves immature tickets which reach ticket maturity
+// by height to live, and live tickets which expire by height to expired.
+// Unmined tickets which can no longer be mined because the stake difficulty
+// window they were purchased for ended are no longer tracked; they are
+// tracked again if they are mined regardless.  Only the tickets indexed to
+// change status at or before height are read.  The hashes of the tickets
+// which expired are returned.
+func (s *StakeStore) ConnectTicketStatuses(height int32) ([]chainhash.Hash,
+	error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	maturity := int32(s.Params.TicketMaturity)
+	expiry := int32(s.Params.TicketExpiry)
+
+	var expired []chainhash.Hash
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		hashes, err := fetchTicketStatusHeights(tx, height)
+		if err != nil {
+			return err
+		}
+
+		for i := range hashes {
+			hash := &hashes[i]
+			record, err := fetchTicketStatus(tx, hash)
+			if err != nil {
+				return err
+			}
+			if record == nil {
+				str := fmt.Sprintf("missing status of indexed "+
+					"ticket %v", hash)
+				return stakeStoreError(ErrDatabase, str, nil)
+			}
+
+			old := *record
+			if record.status == TicketStatusUnmined {
+				err := s.abandonTicketStatus(tx, hash, record)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			liveHeight := record.minedHeight + maturity
+			if record.status == TicketStatusImmature &&
+				height >= liveHeight {
+				record.status = TicketStatusLive
+				record.statusHeight = liveHeight
+			}
+			if record.status == TicketStatusLive &&
+				height > liveHeight+expiry {
+				record.status = TicketStatusExpired
+				record.statusHeight = liveHeight + expiry + 1
+				expired = append(expired, *hash)
+			}
+
+			err = s.storeTicketStatus(tx, hash, &old, record)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return expired, nil
+}
+
+// RollbackTicketStatuses restores the status of all tickets to their status
+// before the block at height was connected.  Tickets mined in or after the
+// block become unmined as of the block before it, and tickets which changed status in or after the
+// block become immature or live depending on the height they were mined at.
+func (s *StakeStore) RollbackTicketStatuses(height int32) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	maturity := int32(s.Params.TicketMaturity)
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		records, err := fetchAllTicketStatuses(tx)
+		if err != nil {
+			return err
+		}
+
+		for hash, record := range records {
+			old := *record
+			switch {
+			case record.status == TicketStatusUnmined:
+				continue
+
+			case record.minedHeight >= height:
+				record.status = TicketStatusUnmined
+				record.minedHeight = 0
+				record.statusHeight = height - 1
+
+			case record.statusHeight >= height:
+				record.voteReward = 0
+				liveHeight := record.minedHeight + maturity
+				if height-1 >= liveHeight {
+					record.status = TicketStatusLive
+					record.statusHeight = liveHeight
+				} else {
+					record.status = TicketStatusImmature
+					record.statusHeight = record.minedHeight
+				}
+
+			default:
+				continue
+			}
+
+			hash := hash
+			err := s.storeTicketStatus(tx, &hash, &old, record)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// abandonTicketStatus removes the status record and index entry of an unmined
+// ticket which can no longer be mined.
+func (s *StakeStore) abandonTicketStatus(tx walletdb.Tx, hash *chainhash.Hash,
+	record *ticketStatusRecord) error {
+	height, _ := pendingStatusHeight(record, s.Params)
+	err := deleteTicketStatusHeight(tx, height, hash)
+	if err != nil {
+		return err
+	}
+	err = deleteTicketStatus(tx, hash)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Ticket %v was not mined before the end of its stake "+
+		"difficulty window and is no longer tracked", hash)
+	return nil
+}
+
+// UnminedTickets returns the hashes of the tracked tickets which are recorded
+// as unmined.
+func (s *StakeStore) UnminedTickets() ([]chainhash.Hash, error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var hashes []chainhash.Hash
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		records, err := fetchAllTicketStatuses(tx)
+		if err != nil {
+			return err
+		}
+		for hash, record := range records {
+			if record.status == TicketStatusUnmined {
+				hashes = append(hashes, hash)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return hashes, nil
+}
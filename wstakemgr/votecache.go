@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/waddrmgr"
+)
+
+// voteTicket holds an owned ticket along with the output information needed
+// to create its vote, so that votes are created without reading the ticket
+// from the database.
+type voteTicket struct {
+	sstx     *dcrutil.Tx
+	payTypes []bool
+	pkhs     [][]byte
+	amounts  []int64
+}
+
+// newVoteTicket precomputes the vote information of a ticket.
+func newVoteTicket(sstx *dcrutil.Tx) *voteTicket {
+	payTypes, pkhs, amounts, _, _, _ := stake.GetSStxStakeOutputInfo(sstx)
+	return &voteTicket{
+		sstx:     sstx,
+		payTypes: payTypes,
+		pkhs:     pkhs,
+		amounts:  amounts,
+	}
+}
+
+// cacheVoteTicket adds an owned ticket to the vote cache.
+func (s *StakeStore) cacheVoteTicket(sstx *dcrutil.Tx) {
+	vt := newVoteTicket(sstx)
+
+	s.voteMtx.Lock()
+	s.voteTickets[*sstx.Sha()] = vt
+	s.voteMtx.Unlock()
+}
+
+// winningVoteTickets returns the cached owned tickets of the passed winning
+// tickets.  Only the vote cache is consulted, so the lookup never waits on
+// other operations of the store.
+func (s *StakeStore) winningVoteTickets(tickets []*chainhash.Hash) []*voteTicket {
+	s.voteMtx.Lock()
+	defer s.voteMtx.Unlock()
+
+	var owned []*voteTicket
+	for _, ticket := range tickets {
+		if vt, ok := s.voteTickets[*ticket]; ok {
+			owned = append(owned, vt)
+		}
+	}
+	return owned
+}
+
+// managedAddress returns the address manager's managed address for addr,
+// caching it so that later votes and revocations signed for the same address
+// do not look it up in the address manager's database again.
+func (s *StakeStore) managedAddress(addr dcrutil.Address) (waddrmgr.ManagedAddress,
+	error) {
+	key := addr.EncodeAddress()
+
+	s.voteMtx.Lock()
+	address, ok := s.voteAddrs[key]
+	s.voteMtx.Unlock()
+	if ok {
+		return address, nil
+	}
+
+	address, err := s.Manager.Address(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s.voteMtx.Lock()
+	s.voteAddrs[key] = address
+	s.voteMtx.Unlock()
+
+	return address, nil
+}
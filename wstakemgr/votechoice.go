@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wstakemgr
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// VoteChoice is the choice made for a consensus agenda.  Mask selects the
+// vote bits used by the agenda and Bits are the vote bits encoding the
+// choice.
+type VoteChoice struct {
+	AgendaID string
+	ChoiceID string
+	Mask     uint16
+	Bits     uint16
+}
+
+// applyVoteChoices returns the vote bits with the bits of each agenda
+// replaced by the bits of the vote choice made for it.
+func applyVoteChoices(voteBits uint16, choices []*VoteChoice) uint16 {
+	for _, c := range choices {
+		voteBits = voteBits&^c.Mask | c.Bits&c.Mask
+	}
+	return voteBits
+}
+
+// VoteChoices returns the agenda vote choices set for a ticket, or the vote
+// choices set for the wallet if the ticket is nil.
+func (s *StakeStore) VoteChoices(ticket *chainhash.Hash) ([]*VoteChoice,
+	error) {
+	if s.isClosed {
+		str := "stake store is closed"
+		return nil, stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var choices []*VoteChoice
+	err := s.namespace.View(func(tx walletdb.Tx) error {
+		var err error
+		choices, err = fetchVoteChoices(tx, ticket)
+		return err
+	})
+	if err != nil {
+		return nil, maybeConvertDbError(err)
+	}
+
+	return choices, nil
+}
+
+// SetVoteChoice sets the choice for an agenda when voting with an owned
+// ticket, or for all tickets of the wallet if the ticket is nil.  Choices set
+// for a ticket take precedence over the choices set for the wallet.
+func (s *StakeStore) SetVoteChoice(ticket *chainhash.Hash,
+	choice *VoteChoice) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if ticket != nil && !s.checkHashInStore(ticket) {
+		str := fmt.Sprintf("ticket %v is not owned by the wallet", ticket)
+		return stakeStoreError(ErrSStxNotFound, str, nil)
+	}
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return putVoteChoice(tx, ticket, choice)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
+
+// ClearVoteChoice removes the choice for an agenda from a ticket, or from
+// the wallet if the ticket is nil.
+func (s *StakeStore) ClearVoteChoice(ticket *chainhash.Hash,
+	agendaID string) error {
+	if s.isClosed {
+		str := "stake store is closed"
+		return stakeStoreError(ErrStoreClosed, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.namespace.Update(func(tx walletdb.Tx) error {
+		return deleteVoteChoice(tx, ticket, agendaID)
+	})
+	if err != nil {
+		return maybeConvertDbError(err)
+	}
+
+	return nil
+}
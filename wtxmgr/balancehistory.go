@@ -0,0 +1,208 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// The balance history records the change of the mined balance caused by each
+// block with wallet credits or debits, keyed by the block height:
+//
+//   [0:4] Block height (4 bytes)
+//
+// The value is the signed change of the mined balance:
+//
+//   [0:8] Balance delta (8 bytes)
+//
+// Heights without a change in balance are not recorded.  The records are
+// updated whenever a credit record is written or removed, so they always agree
+// with the credits bucket, including after rollbacks.  Like the mined balance,
+// outputs locked in tickets are not included.
+
+func keyBalanceDelta(height int32) []byte {
+	k := make([]byte, 4)
+	byteOrder.PutUint32(k, uint32(height))
+	return k
+}
+
+func readRawBalanceDelta(k, v []byte) (int32, dcrutil.Amount, error) {
+	if len(k) < 4 || len(v) < 8 {
+		str := fmt.Sprintf("%s: short balance delta record (expected "+
+			"%d/%d bytes, read %d/%d)", bucketBalanceHistory, 4, 8,
+			len(k), len(v))
+		return 0, 0, storeError(ErrData, str, nil)
+	}
+	height := int32(byteOrder.Uint32(k))
+	delta := dcrutil.Amount(int64(byteOrder.Uint64(v)))
+	return height, delta, nil
+}
+
+// addBalanceDelta adds amt to the recorded balance change at height.  Records
+// whose change becomes zero are removed.
+func addBalanceDelta(ns walletdb.Bucket, height int32, amt dcrutil.Amount) error {
+	if amt == 0 {
+		return nil
+	}
+
+	b := ns.Bucket(bucketBalanceHistory)
+	k := keyBalanceDelta(height)
+	if v := b.Get(k); v != nil {
+		_, delta, err := readRawBalanceDelta(k, v)
+		if err != nil {
+			return err
+		}
+		amt += delta
+	}
+	if amt == 0 {
+		err := b.Delete(k)
+		if err != nil {
+			str := "failed to delete balance delta"
+			return storeError(ErrDatabase, str, err)
+		}
+		return nil
+	}
+
+	v := make([]byte, 8)
+	byteOrder.PutUint64(v, uint64(int64(amt)))
+	err := b.Put(k, v)
+	if err != nil {
+		str := "failed to put balance delta"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// applyCreditBalanceDeltas adds (sign 1) or removes (sign -1) the balance
+// changes described by a raw credit: the credited amount at the height of the
+// credit, and the debited amount at the height of its mined spender, if any.
+// Ticket outputs never count toward the balance.
+func applyCreditBalanceDeltas(ns walletdb.Bucket, k, v []byte, sign dcrutil.Amount) error {
+	if len(k) < 72 || len(v) < 9 {
+		return nil
+	}
+	if fetchRawCreditTagOpCode(v) == txscript.OP_SSTX {
+		return nil
+	}
+
+	amt := dcrutil.Amount(byteOrder.Uint64(v[0:8])) * sign
+	err := addBalanceDelta(ns, extractRawCreditHeight(k), amt)
+	if err != nil {
+		return err
+	}
+	if len(v) >= 81 && v[8]&(1<<0) != 0 {
+		spenderHeight := int32(byteOrder.Uint32(v[41:45]))
+		return addBalanceDelta(ns, spenderHeight, -amt)
+	}
+	return nil
+}
+
+// updateCreditBalanceDeltas updates the balance history for a credit record
+// being rewritten from oldV to newV.  A nil value describes a missing record.
+func updateCreditBalanceDeltas(ns walletdb.Bucket, k, oldV, newV []byte) error {
+	err := applyCreditBalanceDeltas(ns, k, oldV, -1)
+	if err != nil {
+		return err
+	}
+	return applyCreditBalanceDeltas(ns, k, newV, 1)
+}
+
+// rebuildBalanceHistory recreates the balance history from every saved credit.
+func rebuildBalanceHistory(ns walletdb.Bucket) error {
+	err := ns.DeleteBucket(bucketBalanceHistory)
+	if err != nil && err != walletdb.ErrBucketNotFound {
+		str := "failed to delete balance history bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+	_, err = ns.CreateBucket(bucketBalanceHistory)
+	if err != nil {
+		str := "failed to create balance history bucket"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	return ns.Bucket(bucketCredits).ForEach(func(k, v []byte) error {
+		return applyCreditBalanceDeltas(ns, k, v, 1)
+	})
+}
+
+// BalanceDelta describes the change of the mined balance caused by the block
+// at some height, and the resulting balance.  Time is the zero time if the
+// block is not recorded by the store.
+type BalanceDelta struct {
+	Height  int32
+	Time    time.Time
+	Delta   dcrutil.Amount
+	Balance dcrutil.Amount
+}
+
+// BalanceHistory returns the changes of the mined balance caused by blocks
+// with a height in the range [start, end], in order of increasing height.
+// Heights without a change in balance are omitted.  The balance excludes
+// unmined transactions and outputs locked in tickets.
+func (s *Store) BalanceHistory(start, end int32) ([]BalanceDelta, error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return nil, storeError(ErrIsClosed, str, nil)
+	}
+	if start < 0 || end < start {
+		str := "invalid block height range"
+		return nil, storeError(ErrInput, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var history []BalanceDelta
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		var balance dcrutil.Amount
+		c := ns.Bucket(bucketBalanceHistory).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			height, delta, err := readRawBalanceDelta(k, v)
+			if err != nil {
+				return err
+			}
+			if height > end {
+				break
+			}
+			balance += delta
+			if height < start {
+				continue
+			}
+
+			var t time.Time
+			if _, bv := existsBlockRecord(ns, height); bv != nil {
+				t, err = fetchBlockTime(ns, height)
+				if err != nil {
+					return err
+				}
+			}
+			history = append(history, BalanceDelta{
+				Height:  height,
+				Time:    t,
+				Delta:   delta,
+				Balance: balance,
+			})
+		}
+		return nil
+	})
+	return history, err
+}
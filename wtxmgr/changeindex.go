@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// The change outputs of every transaction authored by the wallet are recorded
+// in the change indexes bucket, keyed by the transaction hash:
+//
+//   [0:32] Transaction hash (32 bytes)
+//
+// The value holds a 12 byte entry for each change output:
+//
+//   [0:4]  Output index (4 bytes)
+//   [4:8]  Account (4 bytes)
+//   [8:12] Child index of the account's internal branch (4 bytes)
+//
+// Records are written when the transaction is signed and are never removed,
+// even when the transaction is mined, rolled back, or replaced by a conflict,
+// so they remain an audit trail of which internal addresses were paid change.
+
+// changeIndexSize is the size of each serialized change index entry.
+const changeIndexSize = 12
+
+// ChangeIndex describes a change output of a transaction authored by the
+// wallet.  The output pays the address derived from the Index child of the
+// internal branch of Account, which may be rederived from the account's
+// extended public key to verify the change was paid to the wallet.
+type ChangeIndex struct {
+	Output  uint32
+	Account uint32
+	Index   uint32
+}
+
+func valueChangeIndexes(changes []ChangeIndex) []byte {
+	v := make([]byte, len(changes)*changeIndexSize)
+	for i, c := range changes {
+		e := v[i*changeIndexSize:]
+		byteOrder.PutUint32(e[0:4], c.Output)
+		byteOrder.PutUint32(e[4:8], c.Account)
+		byteOrder.PutUint32(e[8:12], c.Index)
+	}
+	return v
+}
+
+func readRawChangeIndexes(k, v []byte) ([]ChangeIndex, error) {
+	if len(v) == 0 || len(v)%changeIndexSize != 0 {
+		str := fmt.Sprintf("%s: bad change indexes length %d for key %x",
+			bucketChangeIndexes, len(v), k)
+		return nil, storeError(ErrData, str, nil)
+	}
+	changes := make([]ChangeIndex, len(v)/changeIndexSize)
+	for i := range changes {
+		e := v[i*changeIndexSize:]
+		changes[i] = ChangeIndex{
+			Output:  byteOrder.Uint32(e[0:4]),
+			Account: byteOrder.Uint32(e[4:8]),
+			Index:   byteOrder.Uint32(e[8:12]),
+		}
+	}
+	return changes, nil
+}
+
+func fetchChangeIndexes(ns walletdb.Bucket, txHash *chainhash.Hash) ([]ChangeIndex, error) {
+	v := ns.Bucket(bucketChangeIndexes).Get(txHash[:])
+	if v == nil {
+		return nil, nil
+	}
+	return readRawChangeIndexes(txHash[:], v)
+}
+
+// RecordChangeIndexes records the change outputs of a transaction authored by
+// the wallet, replacing any which were recorded before.  Nothing is recorded
+// for transactions without change.
+func (s *Store) RecordChangeIndexes(txHash *chainhash.Hash,
+	changes []ChangeIndex) error {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return storeError(ErrIsClosed, str, nil)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		err := ns.Bucket(bucketChangeIndexes).Put(txHash[:],
+			valueChangeIndexes(changes))
+		if err != nil {
+			str := "failed to put change indexes"
+			return storeError(ErrDatabase, str, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// ValidateRecord checks that a record of the transaction store namespace can be
+// deserialized.  The path holds the keys of the buckets containing the record,
+// relative to the namespace's root bucket, and a nil v indicates a nested
+// bucket.  It has the signature of walletdb.RecordValidator so it can be used
+// to check and salvage the namespace with walletdb.CheckNamespace and
+// walletdb.SalvageNamespace.  Records without a known format are accepted.
+func ValidateRecord(path [][]byte, k, v []byte) error {
+	// Buckets carry no data of their own.
+	if v == nil {
+		return nil
+	}
+
+	if len(path) == 0 {
+		switch {
+		case bytes.Equal(k, rootVersion):
+			return checkRecordSize(k, v, 4)
+		case bytes.Equal(k, rootCreateDate), bytes.Equal(k, rootMinedBalance):
+			return checkRecordSize(k, v, 8)
+		}
+		return nil
+	}
+	if len(path) > 1 {
+		return nil
+	}
+
+	bucket := path[0]
+	switch {
+	case bytes.Equal(bucket, bucketBlocks):
+		var block blockRecord
+		return readRawBlockRecord(k, v, &block)
+
+	case bytes.Equal(bucket, bucketTxRecords):
+		if err := checkKeySize(k, 68); err != nil {
+			return err
+		}
+		var txHash chainhash.Hash
+		copy(txHash[:], k)
+		var rec TxRecord
+		return readRawTxRecord(&txHash, v, &rec)
+
+	case bytes.Equal(bucket, bucketCredits):
+		if err := checkKeySize(k, 72); err != nil {
+			return err
+		}
+		// Spent credits additionally record the spending input.
+		if len(v) == 81 {
+			return nil
+		}
+		return checkRecordSize(k, v, 9)
+
+	case bytes.Equal(bucket, bucketUnspent):
+		if err := checkKeySize(k, 36); err != nil {
+			return err
+		}
+		return checkRecordSize(k, v, 36)
+
+	case bytes.Equal(bucket, bucketDebits):
+		if err := checkKeySize(k, 72); err != nil {
+			return err
+		}
+		return checkRecordSize(k, v, 80)
+
+	case bytes.Equal(bucket, bucketUnmined):
+		if err := checkKeySize(k, 32); err != nil {
+			return err
+		}
+		var txHash chainhash.Hash
+		copy(txHash[:], k)
+		var rec TxRecord
+		return readRawTxRecord(&txHash, v, &rec)
+
+	case bytes.Equal(bucket, bucketUnminedCredits):
+		if err := checkKeySize(k, 36); err != nil {
+			return err
+		}
+		return checkRecordSize(k, v, 9)
+
+	case bytes.Equal(bucket, bucketUnminedInputs):
+		if err := checkKeySize(k, 36); err != nil {
+			return err
+		}
+		return checkRecordSize(k, v, 32)
+
+	case bytes.Equal(bucket, bucketMultisig):
+		_, err := fetchMultisigOut(k, v)
+		return err
+
+	case bytes.Equal(bucket, bucketMultisigUsp):
+		return checkKeySize(k, 36)
+
+	case bytes.Equal(bucket, bucketSideChain):
+		if err := checkKeySize(k, 32); err != nil {
+			return err
+		}
+		_, _, err := readRawSideChainBlock(k, v)
+		return err
+
+	case bytes.Equal(bucket, bucketSignLog):
+		if err := checkKeySize(k, 8); err != nil {
+			return err
+		}
+		var rec SigningRecord
+		return readRawSigningRecord(k, v, &rec)
+
+	case bytes.Equal(bucket, bucketBalanceHistory):
+		if err := checkKeySize(k, 4); err != nil {
+			return err
+		}
+		return checkRecordSize(k, v, 8)
+
+	case bytes.Equal(bucket, bucketCreditOrigins):
+		if err := checkKeySize(k, 36); err != nil {
+			return err
+		}
+		if err := checkRecordSize(k, v, 1); err != nil {
+			return err
+		}
+		if o := CreditOrigin(v[0]); o == OriginNormal || o >= numCreditOrigins {
+			str := fmt.Sprintf("bad credit origin %d for key %x", o, k)
+			return storeError(ErrData, str, nil)
+		}
+
+	case bytes.Equal(bucket, bucketChangeIndexes):
+		if err := checkKeySize(k, 32); err != nil {
+			return err
+		}
+		_, err := readRawChangeIndexes(k, v)
+		return err
+
+	case bytes.Equal(bucket, bucketFiatRates):
+		if err := checkKeySize(k, 32); err != nil {
+			return err
+		}
+		_, err := readRawFiatRate(k, v)
+		return err
+
+	case bytes.Equal(bucket, bucketRetiredScripts):
+		_, err := readRawRetiredScript(k, v)
+		return err
+	}
+
+	return nil
+}
+
+// checkKeySize returns an error when the key k of a record is not exactly size
+// bytes long.
+func checkKeySize(k []byte, size int) error {
+	if len(k) != size {
+		str := fmt.Sprintf("bad key length %d for key %x (expected %d "+
+			"bytes)", len(k), k, size)
+		return storeError(ErrData, str, nil)
+	}
+	return nil
+}
+
+// checkRecordSize returns an error when the value v stored under the key k is
+// not exactly size bytes long.
+func checkRecordSize(k, v []byte, size int) error {
+	if len(v) != size {
+		str := fmt.Sprintf("bad value length %d for key %x (expected %d "+
+			"bytes)", len(v), k, size)
+		return storeError(ErrData, str, nil)
+	}
+	return nil
+}
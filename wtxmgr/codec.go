@@ -0,0 +1,273 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+)
+
+// The functions in this file expose the binary formats of the block, credit,
+// debit and multisig out records so they can be tested in isolation, most
+// notably by fuzzing.  Parse functions are strict: any value which could not
+// have been written by the store is rejected with an ErrData error, and each
+// Serialize function reproduces the exact key and value accepted by its Parse
+// function.
+
+// Record key and value sizes.
+const (
+	blockRecordKeySize    = 4
+	blockRecordHeaderSize = 46
+	recordKeySize         = 72
+	creditValueSize       = 9
+	spentCreditValueSize  = creditValueSize + recordKeySize
+	debitValueSize        = 8 + recordKeySize
+	multisigOutKeySize    = 36
+	multisigOutValueSize  = 135
+)
+
+// creditFlagsMask masks the bits of the credit flags byte which are in use:
+// spent, change, the three stake opcode bits and coinbase.
+const creditFlagsMask = 0x3f
+
+// multisigOutFlagsMask masks the bits of the multisig out flags byte which are
+// in use: spent and tree.
+const multisigOutFlagsMask = 0x03
+
+// RawBlock is the decoded form of a block record.
+type RawBlock struct {
+	BlockMeta
+	Transactions []chainhash.Hash
+}
+
+// RecordKey identifies an output or input of a mined transaction.  It is the
+// key of both credit and debit records.
+type RecordKey struct {
+	TxHash chainhash.Hash
+	Block  Block
+	Index  uint32
+}
+
+// RawCredit is the decoded form of a credit record.  SpentBy is the debit key
+// of the mined transaction input spending the credit, and is set exactly when
+// Spent is true.
+type RawCredit struct {
+	Key        RecordKey
+	Amount     dcrutil.Amount
+	Spent      bool
+	Change     bool
+	OpCode     uint8
+	IsCoinbase bool
+	SpentBy    *RecordKey
+}
+
+// RawDebit is the decoded form of a debit record.  Credit is the key of the
+// credit record which is spent by the debit.
+type RawDebit struct {
+	Key    RecordKey
+	Amount dcrutil.Amount
+	Credit RecordKey
+}
+
+func putRecordKey(b []byte, k *RecordKey) {
+	copy(b[0:32], k.TxHash[:])
+	byteOrder.PutUint32(b[32:36], uint32(k.Block.Height))
+	copy(b[36:68], k.Block.Hash[:])
+	byteOrder.PutUint32(b[68:72], k.Index)
+}
+
+func readRecordKey(b []byte, k *RecordKey) {
+	copy(k.TxHash[:], b[0:32])
+	k.Block.Height = int32(byteOrder.Uint32(b[32:36]))
+	copy(k.Block.Hash[:], b[36:68])
+	k.Index = byteOrder.Uint32(b[68:72])
+}
+
+// ParseBlockRecord decodes the key and value of a block record.
+func ParseBlockRecord(k, v []byte) (*RawBlock, error) {
+	if len(k) != blockRecordKeySize {
+		str := fmt.Sprintf("%s: bad key size (expected %d bytes, read %d)",
+			bucketBlocks, blockRecordKeySize, len(k))
+		return nil, storeError(ErrData, str, nil)
+	}
+	var br blockRecord
+	err := readRawBlockRecord(k, v, &br)
+	if err != nil {
+		return nil, err
+	}
+	expectedLen := blockRecordHeaderSize +
+		len(br.transactions)*chainhash.HashSize
+	if len(v) != expectedLen {
+		str := fmt.Sprintf("%s: bad value size (expected %d bytes, read %d)",
+			bucketBlocks, expectedLen, len(v))
+		return nil, storeError(ErrData, str, nil)
+	}
+	return &RawBlock{
+		BlockMeta: BlockMeta{
+			Block:    br.Block,
+			Time:     br.Time,
+			VoteBits: br.VoteBits,
+		},
+		Transactions: br.transactions,
+	}, nil
+}
+
+// SerializeBlockRecord encodes a block record.  Block times are recorded with a
+// resolution of one second.
+func SerializeBlockRecord(b *RawBlock) (k, v []byte) {
+	k = keyBlockRecord(b.Height)
+	v = valueBlockRecordEmpty(&b.BlockMeta)
+	v = append(v, make([]byte, len(b.Transactions)*chainhash.HashSize)...)
+	byteOrder.PutUint32(v[42:46], uint32(len(b.Transactions)))
+	off := blockRecordHeaderSize
+	for i := range b.Transactions {
+		copy(v[off:], b.Transactions[i][:])
+		off += chainhash.HashSize
+	}
+	return k, v
+}
+
+// ParseCredit decodes the key and value of a credit record.
+func ParseCredit(k, v []byte) (*RawCredit, error) {
+	if len(k) != recordKeySize {
+		str := fmt.Sprintf("%s: bad key size (expected %d bytes, read %d)",
+			bucketCredits, recordKeySize, len(k))
+		return nil, storeError(ErrData, str, nil)
+	}
+	if len(v) != creditValueSize && len(v) != spentCreditValueSize {
+		str := fmt.Sprintf("%s: bad value size (expected %d or %d bytes, "+
+			"read %d)", bucketCredits, creditValueSize,
+			spentCreditValueSize, len(v))
+		return nil, storeError(ErrData, str, nil)
+	}
+	flags := v[8]
+	if flags&^creditFlagsMask != 0 {
+		str := fmt.Sprintf("%s: unknown flags %#02x", bucketCredits, flags)
+		return nil, storeError(ErrData, str, nil)
+	}
+	if (flags>>2)&0x07 > txscript.OP_SSTXCHANGE-txscript.OP_NOP10 {
+		str := fmt.Sprintf("%s: unknown stake opcode tag %d", bucketCredits,
+			(flags>>2)&0x07)
+		return nil, storeError(ErrData, str, nil)
+	}
+
+	c := &RawCredit{
+		Amount:     dcrutil.Amount(byteOrder.Uint64(v)),
+		Spent:      flags&(1<<0) != 0,
+		Change:     flags&(1<<1) != 0,
+		OpCode:     fetchRawCreditTagOpCode(v),
+		IsCoinbase: fetchRawCreditIsCoinbase(v),
+	}
+	if c.Spent != (len(v) == spentCreditValueSize) {
+		str := fmt.Sprintf("%s: spent flag does not match the spender key",
+			bucketCredits)
+		return nil, storeError(ErrData, str, nil)
+	}
+	readRecordKey(k, &c.Key)
+	if c.Spent {
+		c.SpentBy = new(RecordKey)
+		readRecordKey(v[creditValueSize:], c.SpentBy)
+	}
+	return c, nil
+}
+
+// SerializeCredit encodes a credit record.  An ErrInput error is returned if
+// the opcode is not one of the tagged stake opcodes or if SpentBy is set
+// exactly when Spent is not.
+func SerializeCredit(c *RawCredit) (k, v []byte, err error) {
+	if c.OpCode < txscript.OP_NOP10 || c.OpCode > txscript.OP_SSTXCHANGE {
+		str := fmt.Sprintf("opcode %#02x cannot be recorded by a credit",
+			c.OpCode)
+		return nil, nil, storeError(ErrInput, str, nil)
+	}
+	if c.Spent != (c.SpentBy != nil) {
+		str := "spent credits require a spender and unspent credits " +
+			"must not have one"
+		return nil, nil, storeError(ErrInput, str, nil)
+	}
+
+	k = keyCredit(&c.Key.TxHash, c.Key.Index, &c.Key.Block)
+	v = valueUnspentCredit(&credit{
+		amount:     c.Amount,
+		change:     c.Change,
+		opCode:     c.OpCode,
+		isCoinbase: c.IsCoinbase,
+	})
+	if c.Spent {
+		v = append(v, make([]byte, recordKeySize)...)
+		v[8] |= 1 << 0
+		putRecordKey(v[creditValueSize:], c.SpentBy)
+	}
+	return k, v, nil
+}
+
+// ParseDebit decodes the key and value of a debit record.
+func ParseDebit(k, v []byte) (*RawDebit, error) {
+	if len(k) != recordKeySize {
+		str := fmt.Sprintf("%s: bad key size (expected %d bytes, read %d)",
+			bucketDebits, recordKeySize, len(k))
+		return nil, storeError(ErrData, str, nil)
+	}
+	if len(v) != debitValueSize {
+		str := fmt.Sprintf("%s: bad value size (expected %d bytes, read %d)",
+			bucketDebits, debitValueSize, len(v))
+		return nil, storeError(ErrData, str, nil)
+	}
+	d := &RawDebit{Amount: dcrutil.Amount(byteOrder.Uint64(v))}
+	readRecordKey(k, &d.Key)
+	readRecordKey(v[8:], &d.Credit)
+	return d, nil
+}
+
+// SerializeDebit encodes a debit record.
+func SerializeDebit(d *RawDebit) (k, v []byte) {
+	k = keyDebit(&d.Key.TxHash, d.Key.Index, &d.Key.Block)
+	v = make([]byte, debitValueSize)
+	byteOrder.PutUint64(v, uint64(d.Amount))
+	putRecordKey(v[8:], &d.Credit)
+	return k, v
+}
+
+// ParseMultisigOut decodes the key and value of a multisig out record.
+func ParseMultisigOut(k, v []byte) (*MultisigOut, error) {
+	mso, err := fetchMultisigOut(k, v)
+	if err != nil {
+		return nil, err
+	}
+	if v[22]&^multisigOutFlagsMask != 0 {
+		str := fmt.Sprintf("%s: unknown flags %#02x", bucketMultisig, v[22])
+		return nil, storeError(ErrData, str, nil)
+	}
+	return mso, nil
+}
+
+// SerializeMultisigOut encodes a multisig out record.  An ErrInput error is
+// returned if the outpoint is missing.
+func SerializeMultisigOut(mso *MultisigOut) (k, v []byte, err error) {
+	if mso.OutPoint == nil {
+		str := "multisig out is missing its outpoint"
+		return nil, nil, storeError(ErrInput, str, nil)
+	}
+	k = keyMultisigOut(mso.OutPoint.Hash, mso.OutPoint.Index)
+	v = valueMultisigOut(mso.ScriptHash, mso.M, mso.N, mso.Spent, mso.Tree,
+		mso.BlockHash, mso.BlockHeight, mso.Amount, mso.SpentBy,
+		mso.SpentByIndex, mso.TxHash)
+	return k, v, nil
+}
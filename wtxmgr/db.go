@@ -70,7 +70,32 @@ var byteOrder = binary.BigEndian
 // change.
 const (
 	// LatestVersion is the most recent store version.
-	LatestVersion = 1
+	LatestVersion = 8
+
+	// sideChainVersion is the first version with the side chain bucket.
+	sideChainVersion = 2
+
+	// signLogVersion is the first version with the signing log bucket.
+	signLogVersion = 3
+
+	// balanceHistoryVersion is the first version with the balance history
+	// bucket.
+	balanceHistoryVersion = 4
+
+	// creditOriginVersion is the first version with the credit origins
+	// bucket.
+	creditOriginVersion = 5
+
+	// changeIndexVersion is the first version with the change indexes
+	// bucket.
+	changeIndexVersion = 6
+
+	// fiatRateVersion is the first version with the fiat rates bucket.
+	fiatRateVersion = 7
+
+	// retiredScriptVersion is the first version with the retired scripts
+	// bucket.
+	retiredScriptVersion = 8
 )
 
 // This package makes assumptions that the width of a chainhash.Hash is always 32
@@ -92,6 +117,13 @@ var (
 	bucketScripts        = []byte("sc")
 	bucketMultisig       = []byte("ms")
 	bucketMultisigUsp    = []byte("mu")
+	bucketSideChain      = []byte("sb")
+	bucketSignLog        = []byte("sl")
+	bucketBalanceHistory = []byte("bh")
+	bucketCreditOrigins  = []byte("co")
+	bucketChangeIndexes  = []byte("ci")
+	bucketFiatRates      = []byte("fr")
+	bucketRetiredScripts = []byte("rs")
 )
 
 // Root (namespace) bucket keys
@@ -214,35 +246,31 @@ func removeRawBlockRecord(v []byte, txHash *chainhash.Hash) ([]byte, error) {
 			"(expected %d bytes, read %d)", bucketBlocks, 46, len(v))
 		return nil, storeError(ErrData, str, nil)
 	}
+	numHashes := int(byteOrder.Uint32(v[42:46]))
+	if (length-46)%32 != 0 || (length-46)/32 != numHashes {
+		str := fmt.Sprintf("%s: removeRawBlockRecord value of %d bytes "+
+			"does not hold %d hashes", bucketBlocks, length, numHashes)
+		return nil, storeError(ErrData, str, nil)
+	}
 
-	newLength := length - 32 // size of hash
-	oldNumHashes := (length - 46) / 32
-	newValue := make([]byte, newLength, newLength)
-	copy(newValue[0:46], v[0:46])
-
-	cursor := 46
-	newCursor := 46
+	newValue := make([]byte, 46, length)
+	copy(newValue, v[0:46])
 
 	// Only copy the hash in the new value if it's not the one we want to
 	// remove.
-	for i := 0; i < oldNumHashes; i++ {
-		h, err := chainhash.NewHash(v[cursor : cursor+32])
-		if err != nil {
-			return nil, err
-		}
-
-		if h.IsEqual(txHash) {
-			cursor += 32
+	for cursor := 46; cursor < length; cursor += 32 {
+		if bytes.Equal(v[cursor:cursor+32], txHash[:]) {
 			continue
 		}
-
-		copy(newValue[newCursor:newCursor+32], v[cursor:cursor+32])
-		cursor += 32
-		newCursor += 32
+		newValue = append(newValue, v[cursor:cursor+32]...)
+	}
+	if len(newValue) != length-32 {
+		str := fmt.Sprintf("%s: removeRawBlockRecord transaction %v is "+
+			"not recorded exactly once", bucketBlocks, txHash)
+		return nil, storeError(ErrData, str, nil)
 	}
 
-	n := byteOrder.Uint32(newValue[42:46])
-	byteOrder.PutUint32(newValue[42:46], n-1)
+	byteOrder.PutUint32(newValue[42:46], uint32(numHashes-1))
 	return newValue, nil
 }
 
@@ -318,11 +346,13 @@ func readRawBlockRecord(k, v []byte, block *blockRecord) error {
 		return storeError(ErrData, str, nil)
 	}
 
-	numTransactions := int(byteOrder.Uint32(v[42:46]))
-	expectedLen := 46 + chainhash.HashSize*numTransactions
-	if len(v) < expectedLen {
+	// Compare against the number of hashes that fit in the value rather
+	// than the expected length, which may overflow for corrupt counts.
+	numTransactions := byteOrder.Uint32(v[42:46])
+	if numTransactions > uint32((len(v)-46)/chainhash.HashSize) {
 		str := fmt.Sprintf("%s: short read readRawBlockRecord for hashes "+
-			"(expected %d bytes, read %d)", bucketBlocks, expectedLen, len(v))
+			"(expected %d hashes, read %d bytes)", bucketBlocks,
+			numTransactions, len(v))
 		return storeError(ErrData, str, nil)
 	}
 
@@ -655,7 +685,12 @@ func valueUnspentCredit(cred *credit) []byte {
 }
 
 func putRawCredit(ns walletdb.Bucket, k, v []byte) error {
-	err := ns.Bucket(bucketCredits).Put(k, v)
+	b := ns.Bucket(bucketCredits)
+	err := updateCreditBalanceDeltas(ns, k, b.Get(k), v)
+	if err != nil {
+		return err
+	}
+	err = b.Put(k, v)
 	if err != nil {
 		str := "failed to put credit"
 		return storeError(ErrDatabase, str, err)
@@ -777,7 +812,11 @@ func unspendRawCredit(ns walletdb.Bucket, k []byte) (dcrutil.Amount, error) {
 	copy(newv, v)
 	newv[8] &^= 1 << 0
 
-	err := b.Put(k, newv)
+	err := updateCreditBalanceDeltas(ns, k, v, newv)
+	if err != nil {
+		return 0, err
+	}
+	err = b.Put(k, newv)
 	if err != nil {
 		str := "failed to put credit"
 		return 0, storeError(ErrDatabase, str, err)
@@ -796,7 +835,12 @@ func existsRawCredit(ns walletdb.Bucket, k []byte) []byte {
 }
 
 func deleteRawCredit(ns walletdb.Bucket, k []byte) error {
-	err := ns.Bucket(bucketCredits).Delete(k)
+	b := ns.Bucket(bucketCredits)
+	err := updateCreditBalanceDeltas(ns, k, b.Get(k), nil)
+	if err != nil {
+		return err
+	}
+	err = b.Delete(k)
 	if err != nil {
 		str := "failed to delete credit"
 		return storeError(ErrDatabase, str, err)
@@ -1451,11 +1495,11 @@ func valueMultisigOut(sh [ripemd160.Size]byte, m uint8, n uint8,
 func fetchMultisigOut(k, v []byte) (*MultisigOut, error) {
 	if len(k) != 36 {
 		str := "multisig out k is wrong size"
-		return nil, storeError(ErrDatabase, str, nil)
+		return nil, storeError(ErrData, str, nil)
 	}
 	if len(v) != 135 {
 		str := "multisig out v is wrong size"
-		return nil, storeError(ErrDatabase, str, nil)
+		return nil, storeError(ErrData, str, nil)
 	}
 
 	var mso MultisigOut
@@ -1695,9 +1739,84 @@ func openStore(namespace walletdb.Namespace) error {
 		return storeError(ErrUnknownVersion, str, nil)
 	}
 
+	if version < LatestVersion {
+		err = upgradeStore(namespace, version)
+		if err != nil {
+			const desc = "failed to upgrade existing store"
+			if serr, ok := err.(Error); ok {
+				serr.Desc = desc + ": " + serr.Desc
+				return serr
+			}
+			return storeError(ErrDatabase, desc, err)
+		}
+	}
+
 	return nil
 }
 
+// upgradeStore upgrades a store from an older version to LatestVersion.
+func upgradeStore(namespace walletdb.Namespace, version uint32) error {
+	return scopedUpdate(namespace, func(ns walletdb.Bucket) error {
+		if version < sideChainVersion {
+			_, err := ns.CreateBucket(bucketSideChain)
+			if err != nil {
+				str := "failed to create side chain bucket"
+				return storeError(ErrDatabase, str, err)
+			}
+		}
+		if version < signLogVersion {
+			_, err := ns.CreateBucket(bucketSignLog)
+			if err != nil {
+				str := "failed to create signing log bucket"
+				return storeError(ErrDatabase, str, err)
+			}
+		}
+		if version < balanceHistoryVersion {
+			err := rebuildBalanceHistory(ns)
+			if err != nil {
+				return err
+			}
+		}
+		if version < creditOriginVersion {
+			_, err := ns.CreateBucket(bucketCreditOrigins)
+			if err != nil {
+				str := "failed to create credit origins bucket"
+				return storeError(ErrDatabase, str, err)
+			}
+		}
+		if version < changeIndexVersion {
+			_, err := ns.CreateBucket(bucketChangeIndexes)
+			if err != nil {
+				str := "failed to create change indexes bucket"
+				return storeError(ErrDatabase, str, err)
+			}
+		}
+		if version < fiatRateVersion {
+			_, err := ns.CreateBucket(bucketFiatRates)
+			if err != nil {
+				str := "failed to create fiat rates bucket"
+				return storeError(ErrDatabase, str, err)
+			}
+		}
+		if version < retiredScriptVersion {
+			_, err := ns.CreateBucket(bucketRetiredScripts)
+			if err != nil {
+				str := "failed to create retired scripts bucket"
+				return storeError(ErrDatabase, str, err)
+			}
+		}
+
+		v := make([]byte, 4)
+		byteOrder.PutUint32(v, LatestVersion)
+		err := ns.Put(rootVersion, v)
+		if err != nil {
+			str := "failed to store latest database version"
+			return storeError(ErrDatabase, str, err)
+		}
+		return nil
+	})
+}
+
 // createStore creates the tx store (with the latest db version) in the passed
 // namespace.  If a store already exists, ErrAlreadyExists is returned.
 func createStore(namespace walletdb.Namespace) error {
@@ -1802,6 +1921,48 @@ func createStore(namespace walletdb.Namespace) error {
 			return storeError(ErrDatabase, str, err)
 		}
 
+		_, err = ns.CreateBucket(bucketSideChain)
+		if err != nil {
+			str := "failed to create side chain bucket"
+			return storeError(ErrDatabase, str, err)
+		}
+
+		_, err = ns.CreateBucket(bucketSignLog)
+		if err != nil {
+			str := "failed to create signing log bucket"
+			return storeError(ErrDatabase, str, err)
+		}
+
+		_, err = ns.CreateBucket(bucketBalanceHistory)
+		if err != nil {
+			str := "failed to create balance history bucket"
+			return storeError(ErrDatabase, str, err)
+		}
+
+		_, err = ns.CreateBucket(bucketCreditOrigins)
+		if err != nil {
+			str := "failed to create credit origins bucket"
+			return storeError(ErrDatabase, str, err)
+		}
+
+		_, err = ns.CreateBucket(bucketChangeIndexes)
+		if err != nil {
+			str := "failed to create change indexes bucket"
+			return storeError(ErrDatabase, str, err)
+		}
+
+		_, err = ns.CreateBucket(bucketFiatRates)
+		if err != nil {
+			str := "failed to create fiat rates bucket"
+			return storeError(ErrDatabase, str, err)
+		}
+
+		_, err = ns.CreateBucket(bucketRetiredScripts)
+		if err != nil {
+			str := "failed to create retired scripts bucket"
+			return storeError(ErrDatabase, str, err)
+		}
+
 		return nil
 	})
 	if err != nil {
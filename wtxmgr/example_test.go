@@ -90,7 +90,7 @@ func ExampleStore_Balance() {
 		fmt.Println(err)
 		return
 	}
-	err = s.AddCredit(exampleTxRecordA, nil, 0, false)
+	err = s.AddCredit(exampleTxRecordA, nil, 0)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -181,20 +181,20 @@ func Example_basicUsage() {
 		fmt.Println(err)
 		return
 	}
-	err = s.AddCredit(exampleTxRecordA, nil, 0, false)
+	err = s.AddCredit(exampleTxRecordA, nil, 0)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
 	// Insert a second transaction which spends the output, and creates two
-	// outputs.  Mark the second one (5 Coin) as wallet change.
+	// outputs.  Mark the second one (5 Coin) as a wallet credit.
 	err = s.InsertTx(exampleTxRecordB, nil)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	err = s.AddCredit(exampleTxRecordB, nil, 1, true)
+	err = s.AddCredit(exampleTxRecordB, nil, 1)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// The fiat exchange rate at the time a transaction was first seen by the
+// wallet is recorded in the fiat rates bucket, keyed by the transaction hash:
+//
+//   [0:32] Transaction hash (32 bytes)
+//
+// The value is serialized as such:
+//
+//   [0:8]  Rate in fiat units per coin (8 bytes, IEEE 754 float64 bits)
+//   [8:16] Time the rate was quoted (8 bytes, UNIX seconds)
+//   [16:]  Currency code (remaining bytes)
+//
+// Only the first rate recorded for a transaction is kept, so the rate is never
+// changed by seeing the transaction again, such as when it is mined or
+// rescanned.  Records are never removed.
+
+// maxFiatCurrencyLen is the maximum length of a currency code.
+const maxFiatCurrencyLen = 16
+
+// FiatRate is the exchange rate of a coin in a fiat currency, quoted at Time.
+type FiatRate struct {
+	Currency string
+	Rate     float64
+	Time     time.Time
+}
+
+func valueFiatRate(r *FiatRate) []byte {
+	v := make([]byte, 16+len(r.Currency))
+	byteOrder.PutUint64(v[0:8], math.Float64bits(r.Rate))
+	byteOrder.PutUint64(v[8:16], uint64(r.Time.Unix()))
+	copy(v[16:], r.Currency)
+	return v
+}
+
+func readRawFiatRate(k, v []byte) (*FiatRate, error) {
+	if len(v) <= 16 || len(v) > 16+maxFiatCurrencyLen {
+		str := fmt.Sprintf("%s: bad fiat rate length %d for key %x",
+			bucketFiatRates, len(v), k)
+		return nil, storeError(ErrData, str, nil)
+	}
+	r := &FiatRate{
+		Rate:     math.Float64frombits(byteOrder.Uint64(v[0:8])),
+		Time:     time.Unix(int64(byteOrder.Uint64(v[8:16])), 0),
+		Currency: string(v[16:]),
+	}
+	return r, nil
+}
+
+func fetchFiatRate(ns walletdb.Bucket, txHash *chainhash.Hash) (*FiatRate, error) {
+	v := ns.Bucket(bucketFiatRates).Get(txHash[:])
+	if v == nil {
+		return nil, nil
+	}
+	return readRawFiatRate(txHash[:], v)
+}
+
+// RecordFiatRate records the fiat exchange rate at the time a transaction was
+// first seen.  Nothing is recorded if a rate was already recorded for the
+// transaction, and the return value reports whether the rate was recorded.
+func (s *Store) RecordFiatRate(txHash *chainhash.Hash, rate *FiatRate) (bool, error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return false, storeError(ErrIsClosed, str, nil)
+	}
+	if rate.Currency == "" || len(rate.Currency) > maxFiatCurrencyLen {
+		str := fmt.Sprintf("invalid fiat currency code %q", rate.Currency)
+		return false, storeError(ErrInput, str, nil)
+	}
+	if math.IsNaN(rate.Rate) || math.IsInf(rate.Rate, 0) || rate.Rate <= 0 {
+		str := fmt.Sprintf("invalid fiat rate %v", rate.Rate)
+		return false, storeError(ErrInput, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var recorded bool
+	err := scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		b := ns.Bucket(bucketFiatRates)
+		if b.Get(txHash[:]) != nil {
+			return nil
+		}
+		err := b.Put(txHash[:], valueFiatRate(rate))
+		if err != nil {
+			str := "failed to put fiat rate"
+			return storeError(ErrDatabase, str, err)
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// FiatRate returns the fiat exchange rate recorded for a transaction, or nil
+// if no rate was recorded.
+func (s *Store) FiatRate(txHash *chainhash.Hash) (*FiatRate, error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return nil, storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var rate *FiatRate
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		var err error
+		rate, err = fetchFiatRate(ns, txHash)
+		return err
+	})
+	return rate, err
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2016 The Decred developers
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+// This file provides the entry point for fuzzing the record codecs with
+// go-fuzz:
+//
+//   go-fuzz-build -tags gofuzz github.com/decred/dcrwallet/wtxmgr
+//   go-fuzz -bin wtxmgr-fuzz.zip -workdir fuzz
+
+//go:build gofuzz
+// +build gofuzz
+
+package wtxmgr
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// fuzzCodecs lists the codecs exercised by Fuzz with the key size of their
+// records.
+var fuzzCodecs = []struct {
+	keySize   int
+	roundTrip func(k, v []byte) (k2, v2 []byte, err error)
+}{
+	{blockRecordKeySize, func(k, v []byte) ([]byte, []byte, error) {
+		b, err := ParseBlockRecord(k, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		k, v = SerializeBlockRecord(b)
+		return k, v, nil
+	}},
+	{recordKeySize, func(k, v []byte) ([]byte, []byte, error) {
+		c, err := ParseCredit(k, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		k, v, err = SerializeCredit(c)
+		if err != nil {
+			panic(fmt.Sprintf("parsed credit does not serialize: %v", err))
+		}
+		return k, v, nil
+	}},
+	{recordKeySize, func(k, v []byte) ([]byte, []byte, error) {
+		d, err := ParseDebit(k, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		k, v = SerializeDebit(d)
+		return k, v, nil
+	}},
+	{multisigOutKeySize, func(k, v []byte) ([]byte, []byte, error) {
+		mso, err := ParseMultisigOut(k, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		k, v, err = SerializeMultisigOut(mso)
+		if err != nil {
+			panic(fmt.Sprintf("parsed multisig out does not "+
+				"serialize: %v", err))
+		}
+		return k, v, nil
+	}},
+}
+
+// Fuzz is the go-fuzz entry point.  The first byte of data selects the codec,
+// the following bytes up to the key size of the codec are the record key, and
+// the remaining bytes are the record value.  Values which fail to parse must
+// return an ErrData error, and values which parse must serialize back to the
+// exact input.
+func Fuzz(data []byte) int {
+	if len(data) == 0 {
+		return -1
+	}
+	codec := fuzzCodecs[int(data[0])%len(fuzzCodecs)]
+	data = data[1:]
+	if len(data) < codec.keySize {
+		return -1
+	}
+	k, v := data[:codec.keySize], data[codec.keySize:]
+
+	k2, v2, err := codec.roundTrip(k, v)
+	if err != nil {
+		if serr, ok := err.(Error); !ok || serr.Code != ErrData {
+			panic(fmt.Sprintf("unexpected error type %T: %v", err, err))
+		}
+		return 0
+	}
+	if !bytes.Equal(k, k2) || !bytes.Equal(v, v2) {
+		panic(fmt.Sprintf("round trip mismatch: %x:%x != %x:%x", k, v,
+			k2, v2))
+	}
+	return 1
+}
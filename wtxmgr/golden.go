@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrutil"
+)
+
+// Golden files hold a DatabaseContents in a canonical text form so that the
+// state of a store after a scripted scenario can be committed and compared
+// against in later test runs.  The format is line based:
+//
+//   minedbalance <atoms>
+//   oneconfbalance <atoms>
+//   oneconfcalcbalance <atoms>
+//   [<bucket>]
+//   <hex key> <hex value, or - when empty>
+//
+// Every bucket header is written, in a fixed order, and the records of each
+// bucket follow their header sorted by key.  Lines beginning with # are
+// comments.
+
+// goldenBuckets returns the dumped buckets of d in the order they are written
+// to golden files.
+func (d *DatabaseContents) goldenBuckets() []struct {
+	name string
+	m    *map[string][]byte
+} {
+	return []struct {
+		name string
+		m    *map[string][]byte
+	}{
+		{"blocks", &d.BucketBlocks},
+		{"txrecords", &d.BucketTxRecords},
+		{"credits", &d.BucketCredits},
+		{"unspent", &d.BucketUnspent},
+		{"debits", &d.BucketDebits},
+		{"unmined", &d.BucketUnmined},
+		{"unminedcredits", &d.BucketUnminedCredits},
+		{"unminedinputs", &d.BucketUnminedInputs},
+		{"scripts", &d.BucketScripts},
+		{"multisig", &d.BucketMultisig},
+		{"multisigusp", &d.BucketMultisigUsp},
+	}
+}
+
+// WriteGolden writes the contents to w in the canonical golden file form.
+// Equal contents always produce identical output.
+func (d *DatabaseContents) WriteGolden(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "minedbalance %d\n", int64(d.MinedBalance))
+	fmt.Fprintf(bw, "oneconfbalance %d\n", int64(d.OneConfBalance))
+	fmt.Fprintf(bw, "oneconfcalcbalance %d\n", int64(d.OneConfCalcBalance))
+	for _, b := range d.goldenBuckets() {
+		fmt.Fprintf(bw, "[%s]\n", b.name)
+		keys := make([]string, 0, len(*b.m))
+		for k := range *b.m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := (*b.m)[k]
+			if len(v) == 0 {
+				fmt.Fprintf(bw, "%s -\n", k)
+				continue
+			}
+			fmt.Fprintf(bw, "%s %x\n", k, v)
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadGolden parses database contents written by WriteGolden.
+func ReadGolden(r io.Reader) (*DatabaseContents, error) {
+	d := new(DatabaseContents)
+	buckets := make(map[string]*map[string][]byte)
+	for _, b := range d.goldenBuckets() {
+		*b.m = make(map[string][]byte)
+		buckets[b.name] = b.m
+	}
+	balances := map[string]*dcrutil.Amount{
+		"minedbalance":       &d.MinedBalance,
+		"oneconfbalance":     &d.OneConfBalance,
+		"oneconfcalcbalance": &d.OneConfCalcBalance,
+	}
+
+	var bucket map[string][]byte
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			m, ok := buckets[line[1:len(line)-1]]
+			if !ok {
+				str := fmt.Sprintf("golden line %d: unknown bucket %s",
+					lineNum, line)
+				return nil, storeError(ErrInput, str, nil)
+			}
+			bucket = *m
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			str := fmt.Sprintf("golden line %d: expected 2 fields", lineNum)
+			return nil, storeError(ErrInput, str, nil)
+		}
+		if bucket == nil {
+			amt, ok := balances[fields[0]]
+			if !ok {
+				str := fmt.Sprintf("golden line %d: unknown balance %s",
+					lineNum, fields[0])
+				return nil, storeError(ErrInput, str, nil)
+			}
+			atoms, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				str := fmt.Sprintf("golden line %d: bad balance",
+					lineNum)
+				return nil, storeError(ErrInput, str, err)
+			}
+			*amt = dcrutil.Amount(atoms)
+			continue
+		}
+		if _, err := hex.DecodeString(fields[0]); err != nil {
+			str := fmt.Sprintf("golden line %d: bad key", lineNum)
+			return nil, storeError(ErrInput, str, err)
+		}
+		if fields[1] == "-" {
+			bucket[fields[0]] = []byte{}
+			continue
+		}
+		v, err := hex.DecodeString(fields[1])
+		if err != nil {
+			str := fmt.Sprintf("golden line %d: bad value", lineNum)
+			return nil, storeError(ErrInput, str, err)
+		}
+		bucket[fields[0]] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, storeError(ErrInput, "failed to read golden file", err)
+	}
+	return d, nil
+}
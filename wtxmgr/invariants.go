@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/decred/dcrd/txscript"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// maxReportedViolations is the number of invariant violations described by the
+// error of CheckInvariants.  Any further violations are only counted.
+const maxReportedViolations = 10
+
+// CheckInvariants verifies the invariants relating the records of the store:
+//
+//   - every unspent output has an unspent credit recorded in the same block
+//   - every unspent credit is recorded as an unspent output
+//   - every spent credit references a debit, and every debit references a
+//     spent credit which references it in turn
+//   - the mined balance is the sum of all unspent credits except tickets
+//   - the spendable balance derived from the mined balance equals the
+//     balance of a full scan of the mature unspent credits
+//
+// An ErrData error describing the violations is returned if any invariant does
+// not hold.
+func (s *Store) CheckInvariants() error {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		return s.checkInvariants(ns)
+	})
+}
+
+// SetInvariantSampling sets the fraction, between 0 and 1, of store updates
+// after which the invariants are checked.  Violations found by sampling are
+// logged rather than failing the update.  Sampling is disabled by a rate of 0.
+func (s *Store) SetInvariantSampling(rate float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.invariantSampling = rate
+}
+
+// sampleInvariants checks the invariants after the update op with the
+// probability set by SetInvariantSampling and logs any violation.
+func (s *Store) sampleInvariants(ns walletdb.Bucket, op string) {
+	if s.invariantSampling <= 0 || rand.Float64() >= s.invariantSampling {
+		return
+	}
+	err := s.checkInvariants(ns)
+	if err != nil {
+		log.Errorf("Transaction store invariant violated after %s: %v",
+			op, err)
+	}
+}
+
+func (s *Store) checkInvariants(ns walletdb.Bucket) error {
+	var violations []string
+	violate := func(format string, args ...interface{}) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+
+	// Every unspent output must refer to an unspent credit.
+	err := ns.Bucket(bucketUnspent).ForEach(func(k, v []byte) error {
+		if len(k) != 36 || len(v) != 36 {
+			violate("unspent output %x has a malformed record", k)
+			return nil
+		}
+		credKey := make([]byte, 72)
+		copy(credKey[0:32], k[0:32])
+		copy(credKey[32:68], v)
+		copy(credKey[68:72], k[32:36])
+		credVal := existsRawCredit(ns, credKey)
+		switch {
+		case credVal == nil:
+			violate("unspent output %x has no credit", k)
+		case len(credVal) < 9:
+			violate("credit %x is malformed", credKey)
+		case credVal[8]&(1<<0) != 0:
+			violate("unspent output %x refers to a spent credit", k)
+		}
+		return nil
+	})
+	if err != nil {
+		str := "failed iterating unspent outputs"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	// Every credit must either be spent by a debit referencing it or be
+	// recorded as an unspent output.  Unspent credits other than tickets
+	// sum to the mined balance.
+	var unspentTotal dcrutil.Amount
+	err = ns.Bucket(bucketCredits).ForEach(func(k, v []byte) error {
+		c, err := ParseCredit(k, v)
+		if err != nil {
+			violate("credit %x is malformed: %v", k, err)
+			return nil
+		}
+		if c.Spent {
+			debitKey := v[9:81]
+			debitVal := ns.Bucket(bucketDebits).Get(debitKey)
+			if len(debitVal) != 80 {
+				violate("spent credit %x has no debit", k)
+			} else if !bytes.Equal(debitVal[8:80], k) {
+				violate("spent credit %x and its debit %x disagree",
+					k, debitKey)
+			}
+			return nil
+		}
+		unspentKey := canonicalOutPoint(&c.Key.TxHash, c.Key.Index)
+		unspentVal := existsRawUnspent(ns, unspentKey)
+		if !bytes.Equal(unspentVal, k[32:68]) {
+			violate("unspent credit %x is not recorded as an "+
+				"unspent output", k)
+		}
+		if c.OpCode != txscript.OP_SSTX {
+			unspentTotal += c.Amount
+		}
+		return nil
+	})
+	if err != nil {
+		str := "failed iterating credits"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	// Every debit must reference a spent credit.
+	err = ns.Bucket(bucketDebits).ForEach(func(k, v []byte) error {
+		if len(k) != 72 || len(v) != 80 {
+			violate("debit %x has a malformed record", k)
+			return nil
+		}
+		credKey := v[8:80]
+		credVal := existsRawCredit(ns, credKey)
+		if len(credVal) != 81 || credVal[8]&(1<<0) == 0 {
+			violate("debit %x refers to credit %x which is not "+
+				"spent", k, credKey)
+		} else if !bytes.Equal(credVal[9:81], k) {
+			violate("debit %x and its credit %x disagree", k, credKey)
+		}
+		return nil
+	})
+	if err != nil {
+		str := "failed iterating debits"
+		return storeError(ErrDatabase, str, err)
+	}
+
+	minedBalance, err := fetchMinedBalance(ns)
+	if err != nil {
+		return err
+	}
+	if minedBalance != unspentTotal {
+		violate("mined balance %v does not equal the unspent credit "+
+			"total %v", minedBalance, unspentTotal)
+	}
+
+	// Compare the incremental and full scan spendable balances at the
+	// height of the most recent block.
+	it := makeReverseBlockIterator(ns)
+	if it.prev() {
+		tip := it.elem.Height
+		spendable, err := s.balanceSpendable(ns, 1, tip)
+		if err != nil {
+			return err
+		}
+		scanned, err := s.balanceFullScan(ns, 1, tip)
+		if err != nil {
+			return err
+		}
+		if spendable != scanned {
+			violate("spendable balance %v at height %d does not "+
+				"equal the full scan balance %v", spendable, tip,
+				scanned)
+		}
+	} else if it.err != nil {
+		return it.err
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	n := len(violations)
+	if n > maxReportedViolations {
+		violations = append(violations[:maxReportedViolations],
+			fmt.Sprintf("and %d more", n-maxReportedViolations))
+	}
+	str := fmt.Sprintf("%d invariant violations: %s", n,
+		strings.Join(violations, "; "))
+	return storeError(ErrData, str, nil)
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// CreditOrigin tags a credit with where its funds came from, so coins of
+// different origins, such as the mixed outputs of a CoinJoin and outputs
+// linked to the user's identity, need not be merged by spending them in the
+// same transaction.
+type CreditOrigin uint8
+
+// Credit origins.  OriginNormal is the origin of every credit which was not
+// tagged otherwise.
+const (
+	OriginNormal CreditOrigin = iota
+	OriginMixed
+	OriginSwap
+	OriginPoolReward
+
+	// numCreditOrigins is the number of defined credit origins.
+	numCreditOrigins
+)
+
+var creditOriginStrings = [numCreditOrigins]string{
+	OriginNormal:     "normal",
+	OriginMixed:      "mixed",
+	OriginSwap:       "swap",
+	OriginPoolReward: "poolreward",
+}
+
+// String returns the name of the credit origin.
+func (o CreditOrigin) String() string {
+	if o < numCreditOrigins {
+		return creditOriginStrings[o]
+	}
+	return fmt.Sprintf("CreditOrigin(%d)", uint8(o))
+}
+
+// ParseCreditOrigin returns the credit origin named by s, as returned by the
+// String method.
+func ParseCreditOrigin(s string) (CreditOrigin, error) {
+	for o, name := range creditOriginStrings {
+		if s == name {
+			return CreditOrigin(o), nil
+		}
+	}
+	str := fmt.Sprintf("unknown credit origin %q", s)
+	return 0, storeError(ErrInput, str, nil)
+}
+
+// The origins of credits are recorded in the credit origins bucket, keyed by
+// the canonical outpoint of the credit:
+//
+//   [0:32] Transaction hash (32 bytes)
+//   [32:36] Output index (4 bytes)
+//
+// The value is the origin:
+//
+//   [0] Credit origin (1 byte)
+//
+// Only credits which are not of the normal origin are recorded.  Records are
+// keyed by outpoint rather than block so they are kept when transactions are
+// mined or rolled back, and are removed with unmined conflicting transactions.
+
+func fetchCreditOrigin(ns walletdb.Bucket, k []byte) CreditOrigin {
+	v := ns.Bucket(bucketCreditOrigins).Get(k)
+	if len(v) < 1 {
+		return OriginNormal
+	}
+	return CreditOrigin(v[0])
+}
+
+func putCreditOrigin(ns walletdb.Bucket, k []byte, origin CreditOrigin) error {
+	b := ns.Bucket(bucketCreditOrigins)
+	var err error
+	if origin == OriginNormal {
+		err = b.Delete(k)
+	} else {
+		err = b.Put(k, []byte{byte(origin)})
+	}
+	if err != nil {
+		str := "failed to put credit origin"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+func deleteCreditOrigin(ns walletdb.Bucket, k []byte) error {
+	err := ns.Bucket(bucketCreditOrigins).Delete(k)
+	if err != nil {
+		str := "failed to delete credit origin"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+// inheritCreditOrigin records the origin of a new credit of rec which was not
+// tagged by the author of the transaction.  The origin is preserved across
+// spend chains: when every input of rec spends a credit of the same origin,
+// the credit inherits it.  Inputs which do not spend credits of the wallet are
+// of the normal origin.
+func inheritCreditOrigin(ns walletdb.Bucket, rec *TxRecord, index uint32) error {
+	k := canonicalOutPoint(&rec.Hash, index)
+	if ns.Bucket(bucketCreditOrigins).Get(k) != nil {
+		return nil
+	}
+
+	origin := OriginNormal
+	for i, input := range rec.MsgTx.TxIn {
+		prevOut := &input.PreviousOutPoint
+		prevOrigin := fetchCreditOrigin(ns,
+			canonicalOutPoint(&prevOut.Hash, prevOut.Index))
+		if i != 0 && prevOrigin != origin {
+			return nil
+		}
+		origin = prevOrigin
+	}
+	if origin == OriginNormal {
+		return nil
+	}
+	return putCreditOrigin(ns, k, origin)
+}
+
+// SetCreditOrigin tags the output op with an origin.  It is called by the
+// author of a transaction, such as a mixing client, to record where the funds
+// of its outputs came from, and overrides any origin inherited from the spent
+// credits.  Outputs may be tagged with an origin other than the normal origin
+// before the transaction is added to the store.
+func (s *Store) SetCreditOrigin(op *wire.OutPoint, origin CreditOrigin) error {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return storeError(ErrIsClosed, str, nil)
+	}
+	if origin >= numCreditOrigins {
+		str := fmt.Sprintf("unknown credit origin %d", origin)
+		return storeError(ErrInput, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		k := canonicalOutPoint(&op.Hash, op.Index)
+		return putCreditOrigin(ns, k, origin)
+	})
+}
+
+// CreditOrigin returns the origin of the output op.  Outputs which were never
+// tagged are of the normal origin.
+func (s *Store) CreditOrigin(op *wire.OutPoint) (CreditOrigin, error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return 0, storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var origin CreditOrigin
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		origin = fetchCreditOrigin(ns, canonicalOutPoint(&op.Hash, op.Index))
+		return nil
+	})
+	return origin, err
+}
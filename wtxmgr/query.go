@@ -50,9 +50,19 @@ type DebitRecord struct {
 // debits.
 type TxDetails struct {
 	TxRecord
-	Block   BlockMeta
-	Credits []CreditRecord
-	Debits  []DebitRecord
+	Block    BlockMeta
+	Credits  []CreditRecord
+	Debits   []DebitRecord
+	Approval ApprovalStatus
+
+	// ChangeIndexes are the change outputs recorded when the wallet
+	// authored the transaction, and are empty for transactions authored
+	// elsewhere or without change.
+	ChangeIndexes []ChangeIndex
+
+	// FiatRate is the fiat exchange rate recorded when the wallet first
+	// saw the transaction, or nil if no rate was recorded.
+	FiatRate *FiatRate
 }
 
 // Height returns the height of a transaction according to the BlockMeta.
@@ -79,6 +89,11 @@ func (s *Store) minedTxDetails(ns walletdb.Bucket, txHash *chainhash.Hash, recKe
 	if err != nil {
 		return nil, err
 	}
+	details.Approval, err = fetchApprovalStatus(ns, details.Block.Height,
+		details.TxType)
+	if err != nil {
+		return nil, err
+	}
 
 	credIter := makeCreditIterator(ns, recKey)
 	for credIter.next() {
@@ -100,6 +115,15 @@ func (s *Store) minedTxDetails(ns walletdb.Bucket, txHash *chainhash.Hash, recKe
 		return nil, credIter.err
 	}
 
+	details.ChangeIndexes, err = fetchChangeIndexes(ns, txHash)
+	if err != nil {
+		return nil, err
+	}
+	details.FiatRate, err = fetchFiatRate(ns, txHash)
+	if err != nil {
+		return nil, err
+	}
+
 	debIter := makeDebitIterator(ns, recKey)
 	for debIter.next() {
 		if int(debIter.elem.Index) >= len(details.MsgTx.TxIn) {
@@ -123,6 +147,15 @@ func (s *Store) unminedTxDetails(ns walletdb.Bucket, txHash *chainhash.Hash, v [
 		return nil, err
 	}
 
+	details.ChangeIndexes, err = fetchChangeIndexes(ns, txHash)
+	if err != nil {
+		return nil, err
+	}
+	details.FiatRate, err = fetchFiatRate(ns, txHash)
+	if err != nil {
+		return nil, err
+	}
+
 	it := makeUnminedCreditIterator(ns, txHash)
 	for it.next() {
 		if int(it.elem.Index) >= len(details.MsgTx.TxOut) {
@@ -274,7 +274,12 @@ func TestStoreQueries(t *testing.T) {
 		}
 	}
 	addCredit := func(s *Store, rec *TxRecord, block *BlockMeta, index uint32, change bool) {
-		err := s.AddCredit(rec, block, index, change)
+		var err error
+		if change {
+			err = addChangeCredit(s, rec, block, index)
+		} else {
+			err = s.AddCredit(rec, block, index)
+		}
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -606,7 +611,7 @@ func TestPreviousPkScripts(t *testing.T) {
 		}
 	}
 	addCredit := func(rec *TxRecord, block *BlockMeta, index uint32) {
-		err := s.AddCredit(rec, block, index, false)
+		err := s.AddCredit(rec, block, index)
 		if err != nil {
 			t.Fatal(err)
 		}
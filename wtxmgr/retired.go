@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// The output scripts of imported keys and scripts which were retired, such as
+// after the key was suspected to be exposed, are recorded in the retired
+// scripts bucket, keyed by the output script:
+//
+//   [0:]   Output script (remaining bytes)
+//
+// The value is serialized as such:
+//
+//   [0:8]  Time the script was retired (8 bytes, UNIX seconds)
+//   [8:40] Hash of the transaction sweeping the funds paid to the script
+//          (32 bytes, zero when there were no funds to sweep)
+//
+// Retiring a script again replaces its record, so the record describes the
+// latest sweep.  Records are never removed.
+
+// RetiredScript describes a retired output script.  SweepTx is the hash of
+// the transaction which swept the funds paid to the script, or the zero hash
+// if there were no funds to sweep.
+type RetiredScript struct {
+	PkScript []byte
+	Time     time.Time
+	SweepTx  chainhash.Hash
+}
+
+func valueRetiredScript(r *RetiredScript) []byte {
+	v := make([]byte, 40)
+	byteOrder.PutUint64(v[0:8], uint64(r.Time.Unix()))
+	copy(v[8:40], r.SweepTx[:])
+	return v
+}
+
+func readRawRetiredScript(k, v []byte) (*RetiredScript, error) {
+	if len(k) == 0 || len(v) != 40 {
+		str := fmt.Sprintf("%s: bad retired script length %d for key %x",
+			bucketRetiredScripts, len(v), k)
+		return nil, storeError(ErrData, str, nil)
+	}
+	r := &RetiredScript{
+		PkScript: append([]byte(nil), k...),
+		Time:     time.Unix(int64(byteOrder.Uint64(v[0:8])), 0),
+	}
+	copy(r.SweepTx[:], v[8:40])
+	return r, nil
+}
+
+// RetireScript records an output script as retired, replacing any previous
+// record of the script.
+func (s *Store) RetireScript(r *RetiredScript) error {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return storeError(ErrIsClosed, str, nil)
+	}
+	if len(r.PkScript) == 0 {
+		str := "retired output script is empty"
+		return storeError(ErrInput, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		err := ns.Bucket(bucketRetiredScripts).Put(r.PkScript,
+			valueRetiredScript(r))
+		if err != nil {
+			str := "failed to put retired script"
+			return storeError(ErrDatabase, str, err)
+		}
+		return nil
+	})
+}
+
+// RetiredScript returns the record of a retired output script, or nil if the
+// script was never retired.
+func (s *Store) RetiredScript(pkScript []byte) (*RetiredScript, error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return nil, storeError(ErrIsClosed, str, nil)
+	}
+	if len(pkScript) == 0 {
+		return nil, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var r *RetiredScript
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		v := ns.Bucket(bucketRetiredScripts).Get(pkScript)
+		if v == nil {
+			return nil
+		}
+		var err error
+		r, err = readRawRetiredScript(pkScript, v)
+		return err
+	})
+	return r, err
+}
+
+// RetiredScripts returns the records of every retired output script, ordered
+// by script.
+func (s *Store) RetiredScripts() ([]*RetiredScript, error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return nil, storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var retired []*RetiredScript
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		return ns.Bucket(bucketRetiredScripts).ForEach(func(k, v []byte) error {
+			r, err := readRawRetiredScript(k, v)
+			if err != nil {
+				return err
+			}
+			retired = append(retired, r)
+			return nil
+		})
+	})
+	return retired, err
+}
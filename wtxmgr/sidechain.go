@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// Blocks removed from the main chain by Rollback are kept in the side chain
+// bucket, keyed by block hash, together with the wallet transactions they
+// contained.  If a reorganization later returns a cached block to the main
+// chain, its transactions can be attached immediately rather than waiting on
+// the chain server.
+//
+// The side chain block value format is:
+//
+//   [0:4]   Height (4 bytes)
+//   [4:12]  Unix time (8 bytes)
+//   [12:14] Vote bits (2 bytes)
+//   [14:18] Number of transactions (4 bytes)
+//   [18:]   For each transaction:
+//             Transaction hash (32 bytes)
+//             Length of the raw transaction record (4 bytes)
+//             Raw transaction record, as saved in the tx records bucket
+
+// MaxSideChainDepth is the maximum number of blocks removed by a single
+// Rollback which are kept in the side chain cache.  Only the most recent blocks
+// are kept, as reorganizations deeper than this are not expected.
+const MaxSideChainDepth = 256
+
+func valueSideChainBlock(b *blockRecord, hashes []chainhash.Hash,
+	rawRecs [][]byte) []byte {
+	size := 18
+	for _, v := range rawRecs {
+		size += 36 + len(v)
+	}
+	v := make([]byte, 18, size)
+	byteOrder.PutUint32(v[0:4], uint32(b.Height))
+	byteOrder.PutUint64(v[4:12], uint64(b.Time.Unix()))
+	byteOrder.PutUint16(v[12:14], b.VoteBits)
+	byteOrder.PutUint32(v[14:18], uint32(len(rawRecs)))
+	for i, rec := range rawRecs {
+		var n [4]byte
+		byteOrder.PutUint32(n[:], uint32(len(rec)))
+		v = append(v, hashes[i][:]...)
+		v = append(v, n[:]...)
+		v = append(v, rec...)
+	}
+	return v
+}
+
+func putSideChainBlock(ns walletdb.Bucket, b *blockRecord) error {
+	hashes := make([]chainhash.Hash, 0, len(b.transactions))
+	rawRecs := make([][]byte, 0, len(b.transactions))
+	for i := range b.transactions {
+		hash := &b.transactions[i]
+		v := ns.Bucket(bucketTxRecords).Get(keyTxRecord(hash, &b.Block))
+		if v == nil {
+			continue
+		}
+		hashes = append(hashes, *hash)
+		rawRecs = append(rawRecs, v)
+	}
+	v := valueSideChainBlock(b, hashes, rawRecs)
+	err := ns.Bucket(bucketSideChain).Put(b.Hash[:], v)
+	if err != nil {
+		str := "failed to store side chain block"
+		return storeError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
+func readRawSideChainBlock(k, v []byte) (*BlockMeta, []*TxRecord, error) {
+	if len(v) < 18 {
+		str := fmt.Sprintf("%s: short read for side chain block "+
+			"(expected %d bytes, read %d)", bucketSideChain, 18, len(v))
+		return nil, nil, storeError(ErrData, str, nil)
+	}
+	var block BlockMeta
+	copy(block.Hash[:], k)
+	block.Height = int32(byteOrder.Uint32(v[0:4]))
+	block.Time = time.Unix(int64(byteOrder.Uint64(v[4:12])), 0)
+	block.VoteBits = byteOrder.Uint16(v[12:14])
+	n := int(byteOrder.Uint32(v[14:18]))
+	recs := make([]*TxRecord, 0, n)
+	off := 18
+	for i := 0; i < n; i++ {
+		if len(v) < off+36 {
+			str := fmt.Sprintf("%s: short read for side chain "+
+				"transaction %d", bucketSideChain, i)
+			return nil, nil, storeError(ErrData, str, nil)
+		}
+		var hash chainhash.Hash
+		copy(hash[:], v[off:off+32])
+		recLen := int(byteOrder.Uint32(v[off+32 : off+36]))
+		off += 36
+		if len(v) < off+recLen {
+			str := fmt.Sprintf("%s: short read for side chain "+
+				"transaction %v", bucketSideChain, &hash)
+			return nil, nil, storeError(ErrData, str, nil)
+		}
+		rec := new(TxRecord)
+		err := readRawTxRecord(&hash, v[off:off+recLen], rec)
+		if err != nil {
+			return nil, nil, err
+		}
+		recs = append(recs, rec)
+		off += recLen
+	}
+	return &block, recs, nil
+}
+
+// SideChainBlock returns a block removed from the main chain by Rollback and
+// the wallet transactions it contained.  A nil block is returned if the block
+// is not cached.
+func (s *Store) SideChainBlock(hash *chainhash.Hash) (*BlockMeta,
+	[]*TxRecord, error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return nil, nil, storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var block *BlockMeta
+	var recs []*TxRecord
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		v := ns.Bucket(bucketSideChain).Get(hash[:])
+		if v == nil {
+			return nil
+		}
+		var err error
+		block, recs, err = readRawSideChainBlock(hash[:], v)
+		return err
+	})
+	return block, recs, err
+}
+
+// RemoveSideChainBlock removes a cached side chain block, if it exists.
+func (s *Store) RemoveSideChainBlock(hash *chainhash.Hash) error {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		err := ns.Bucket(bucketSideChain).Delete(hash[:])
+		if err != nil {
+			str := "failed to remove side chain block"
+			return storeError(ErrDatabase, str, err)
+		}
+		return nil
+	})
+}
+
+// PruneSideChainBlocks removes every cached side chain block below a height.
+func (s *Store) PruneSideChainBlocks(height int32) error {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		b := ns.Bucket(bucketSideChain)
+		var prune [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			if len(v) < 4 {
+				str := fmt.Sprintf("%s: short read for side "+
+					"chain block height", bucketSideChain)
+				return storeError(ErrData, str, nil)
+			}
+			if int32(byteOrder.Uint32(v[0:4])) < height {
+				prune = append(prune, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range prune {
+			err := b.Delete(k)
+			if err != nil {
+				str := "failed to prune side chain block"
+				return storeError(ErrDatabase, str, err)
+			}
+		}
+		return nil
+	})
+}
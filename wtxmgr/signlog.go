@@ -0,0 +1,207 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// Every transaction signed by the wallet is recorded in the signing log
+// bucket, keyed by a sequence number which increments with each record.
+// Records are only ever appended, so the log is an audit trail of everything
+// the wallet has signed.  The full signed transaction is saved so the inputs,
+// input amounts, and outputs can be recovered from the record.
+//
+// The signing log key format is:
+//
+//   [0:8] Sequence number (8 bytes)
+//
+// The signing log value format is:
+//
+//   [0:8]   Unix time (8 bytes)
+//   [8:9]   Length of the origin (1 byte)
+//   [9:n]   Origin, describing who requested the signature
+//   [n:]    Serialized signed transaction
+
+// maxSigningOriginLen is the maximum length of the origin of a signing record.
+// Longer origins are truncated.
+const maxSigningOriginLen = 255
+
+// SigningRecord is a record of the signing log, describing a transaction
+// signed by the wallet, when it was signed, and the origin of the request to
+// sign it.
+type SigningRecord struct {
+	Sequence uint64
+	Time     time.Time
+	Origin   string
+	MsgTx    wire.MsgTx
+}
+
+func keySigningRecord(seq uint64) []byte {
+	k := make([]byte, 8)
+	byteOrder.PutUint64(k, seq)
+	return k
+}
+
+func valueSigningRecord(t time.Time, origin string,
+	tx *wire.MsgTx) ([]byte, error) {
+	if len(origin) > maxSigningOriginLen {
+		origin = origin[:maxSigningOriginLen]
+	}
+	n := 9 + len(origin)
+	v := make([]byte, n, n+tx.SerializeSize())
+	byteOrder.PutUint64(v[0:8], uint64(t.Unix()))
+	v[8] = byte(len(origin))
+	copy(v[9:], origin)
+	buf := bytes.NewBuffer(v)
+	err := tx.Serialize(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func readRawSigningRecord(k, v []byte, rec *SigningRecord) error {
+	if len(v) < 9 || len(v) < 9+int(v[8]) {
+		str := fmt.Sprintf("%s: short read for signing record %x",
+			bucketSignLog, k)
+		return storeError(ErrData, str, nil)
+	}
+	n := 9 + int(v[8])
+	rec.Sequence = byteOrder.Uint64(k)
+	rec.Time = time.Unix(int64(byteOrder.Uint64(v[0:8])), 0)
+	rec.Origin = string(v[9:n])
+	err := rec.MsgTx.Deserialize(bytes.NewReader(v[n:]))
+	if err != nil {
+		str := fmt.Sprintf("%s: failed to deserialize signed "+
+			"transaction of signing record %d", bucketSignLog,
+			rec.Sequence)
+		return storeError(ErrData, str, err)
+	}
+	return nil
+}
+
+// AppendSigningRecord appends a record of a signed transaction to the signing
+// log, returning the sequence number of the new record.  The origin describes
+// who requested the signature.
+func (s *Store) AppendSigningRecord(origin string, tx *wire.MsgTx) (uint64,
+	error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return 0, storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	v, err := valueSigningRecord(time.Now(), origin, tx)
+	if err != nil {
+		str := "failed to serialize signed transaction"
+		return 0, storeError(ErrInput, str, err)
+	}
+
+	var seq uint64
+	err = scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		b := ns.Bucket(bucketSignLog)
+		lastKey, _ := b.Cursor().Last()
+		if lastKey != nil {
+			seq = byteOrder.Uint64(lastKey) + 1
+		}
+		err := b.Put(keySigningRecord(seq), v)
+		if err != nil {
+			str := "failed to store signing record"
+			return storeError(ErrDatabase, str, err)
+		}
+		return nil
+	})
+	return seq, err
+}
+
+// SigningRecords returns up to count records of the signing log, in the order
+// they were appended, beginning with the record numbered start.  All remaining
+// records are returned if count is not positive.
+func (s *Store) SigningRecords(start uint64, count int) ([]*SigningRecord,
+	error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return nil, storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var recs []*SigningRecord
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		c := ns.Bucket(bucketSignLog).Cursor()
+		for k, v := c.Seek(keySigningRecord(start)); k != nil; k, v = c.Next() {
+			if count > 0 && len(recs) == count {
+				break
+			}
+			rec := new(SigningRecord)
+			err := readRawSigningRecord(k, v, rec)
+			if err != nil {
+				return err
+			}
+			recs = append(recs, rec)
+		}
+		return nil
+	})
+	return recs, err
+}
+
+// SigningRecordsSince returns the records of the signing log appended at or
+// after the time since, in the order they were appended.  Records are read
+// from the end of the log, so only the recent records are read.
+func (s *Store) SigningRecordsSince(since time.Time) ([]*SigningRecord,
+	error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return nil, storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var recs []*SigningRecord
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		c := ns.Bucket(bucketSignLog).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			rec := new(SigningRecord)
+			err := readRawSigningRecord(k, v, rec)
+			if err != nil {
+				return err
+			}
+			if rec.Time.Before(since) {
+				break
+			}
+			recs = append(recs, rec)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(recs)-1; i < j; i, j = i+1, j-1 {
+		recs[i], recs[j] = recs[j], recs[i]
+	}
+	return recs, nil
+}
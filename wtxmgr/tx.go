@@ -187,6 +187,14 @@ type Credit struct {
 	PkScript     []byte
 	Received     time.Time
 	FromCoinBase bool
+	Origin       CreditOrigin
+}
+
+// ChangeSource determines whether transaction outputs pay to change addresses
+// of the wallet.  The wallet implements it by consulting the address manager
+// branch of the addresses paid by an output.
+type ChangeSource interface {
+	IsChangeOutput(output *wire.TxOut) (bool, error)
 }
 
 // Store implements a transaction store for storing and managing wallet
@@ -197,6 +205,9 @@ type Store struct {
 
 	namespace   walletdb.Namespace
 	chainParams *chaincfg.Params
+
+	changeSource      ChangeSource
+	invariantSampling float64
 }
 
 // SortedTxRecords is a list of transaction records that can be sorted.
@@ -386,6 +397,10 @@ func (s *Store) pruneOldTickets(ns walletdb.Bucket,
 // Open opens the wallet transaction store from a walletdb namespace.  If the
 // store does not exist, ErrNoExist is returned.  Existing stores will be
 // upgraded to new database formats as necessary.
+//
+// The namespace may belong to a database opened read-only, in which case the
+// store may be used to inspect transactions and balances.  Opening a store
+// which must be upgraded, or pruning tickets, fails for read-only databases.
 func Open(namespace walletdb.Namespace, pruneTickets bool,
 	chainParams *chaincfg.Params) (*Store, error) {
 	// Open the store, upgrading to the latest version as needed.
@@ -394,7 +409,7 @@ func Open(namespace walletdb.Namespace, pruneTickets bool,
 		return nil, err
 	}
 
-	s := &Store{new(sync.Mutex), false, namespace, chainParams}
+	s := &Store{new(sync.Mutex), false, namespace, chainParams, nil, 0}
 
 	// Skip pruning on simnet, because the adjustment times are
 	// so short.
@@ -423,7 +438,7 @@ func Create(namespace walletdb.Namespace, chainParams *chaincfg.Params) (*Store,
 	if err != nil {
 		return nil, err
 	}
-	return &Store{new(sync.Mutex), false, namespace, chainParams}, nil
+	return &Store{new(sync.Mutex), false, namespace, chainParams, nil, 0}, nil
 }
 
 // Close safely closes the transaction manager by waiting for the mutex to
@@ -435,6 +450,15 @@ func (s *Store) Close() {
 	s.isClosed = true
 }
 
+// SetChangeSource sets the source used by AddCredit to determine whether
+// credits are change.  Without a change source, no credit is change.
+func (s *Store) SetChangeSource(cs ChangeSource) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.changeSource = cs
+}
+
 // InsertBlock inserts a block into the block database if it doesn't already
 // exist.
 func (s *Store) InsertBlock(bm *BlockMeta) error {
@@ -644,10 +668,17 @@ func (s *Store) InsertTx(rec *TxRecord, block *BlockMeta) error {
 	defer s.mutex.Unlock()
 
 	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		var err error
 		if block == nil {
-			return s.insertMemPoolTx(ns, rec)
+			err = s.insertMemPoolTx(ns, rec)
+		} else {
+			err = s.insertMinedTx(ns, rec, block)
 		}
-		return s.insertMinedTx(ns, rec, block)
+		if err != nil {
+			return err
+		}
+		s.sampleInvariants(ns, "inserting a transaction")
+		return nil
 	})
 }
 
@@ -769,12 +800,12 @@ func (s *Store) insertMinedTx(ns walletdb.Bucket, rec *TxRecord,
 // AddCredit marks a transaction record as containing a transaction output
 // spendable by wallet.  The output is added unspent, and is marked spent
 // when a new transaction spending the output is inserted into the store.
+// Whether the output is change is determined by the store's change source.
 //
 // TODO(jrick): This should not be necessary.  Instead, pass the indexes
 // that are known to contain credits when a transaction or merkleblock is
 // inserted into the store.
-func (s *Store) AddCredit(rec *TxRecord, block *BlockMeta, index uint32,
-	change bool) error {
+func (s *Store) AddCredit(rec *TxRecord, block *BlockMeta, index uint32) error {
 	if s.isClosed {
 		str := "tx manager is closed"
 		return storeError(ErrIsClosed, str, nil)
@@ -788,8 +819,88 @@ func (s *Store) AddCredit(rec *TxRecord, block *BlockMeta, index uint32,
 		return storeError(ErrInput, str, nil)
 	}
 
+	change, err := s.isChangeOutput(rec.MsgTx.TxOut[index])
+	if err != nil {
+		return err
+	}
+
 	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
-		return s.addCredit(ns, rec, block, index, change)
+		err := s.addCredit(ns, rec, block, index, change)
+		if err != nil {
+			return err
+		}
+		s.sampleInvariants(ns, "adding a credit")
+		return nil
+	})
+}
+
+// isChangeOutput returns whether the output pays to a change address according
+// to the store's change source.
+func (s *Store) isChangeOutput(output *wire.TxOut) (bool, error) {
+	if s.changeSource == nil {
+		return false, nil
+	}
+	return s.changeSource.IsChangeOutput(output)
+}
+
+// ProcessBlock applies all wallet-relevant data of a block in a single database
+// transaction.  The block is recorded even when it contains no relevant
+// transactions.  Each record of relevantTxs is inserted as mined in the block,
+// in the order given, which must match the order of the transactions in the
+// block so outputs spent within the block are debited.  creditIndexes maps the
+// hash of a relevant transaction to the indexes of its outputs which are
+// wallet credits.  Either all of the block's data is applied, or none of it.
+func (s *Store) ProcessBlock(block *BlockMeta, relevantTxs []*TxRecord,
+	creditIndexes map[chainhash.Hash][]uint32) error {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return storeError(ErrIsClosed, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Validate the credits and determine which are change before opening
+	// the database transaction, as the change source may access the
+	// database itself.
+	change := make(map[chainhash.Hash][]bool, len(creditIndexes))
+	for _, rec := range relevantTxs {
+		indexes := creditIndexes[rec.Hash]
+		isChange := make([]bool, len(indexes))
+		for i, index := range indexes {
+			if int(index) >= len(rec.MsgTx.TxOut) {
+				str := fmt.Sprintf("transaction %v output %d does "+
+					"not exist", &rec.Hash, index)
+				return storeError(ErrInput, str, nil)
+			}
+			var err error
+			isChange[i], err = s.isChangeOutput(rec.MsgTx.TxOut[index])
+			if err != nil {
+				return err
+			}
+		}
+		change[rec.Hash] = isChange
+	}
+
+	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		err := s.insertBlock(ns, block)
+		if err != nil {
+			return err
+		}
+		for _, rec := range relevantTxs {
+			err := s.insertMinedTx(ns, rec, block)
+			if err != nil {
+				return err
+			}
+			for i, index := range creditIndexes[rec.Hash] {
+				err := s.addCredit(ns, rec, block, index,
+					change[rec.Hash][i])
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
 	})
 }
 
@@ -816,6 +927,11 @@ func (s *Store) addCredit(ns walletdb.Bucket, rec *TxRecord, block *BlockMeta,
 	opCode := getP2PKHOpCode(rec.MsgTx.TxOut[index].PkScript)
 	isCoinbase := blockchain.IsCoinBaseTx(&rec.MsgTx)
 
+	err := inheritCreditOrigin(ns, rec, index)
+	if err != nil {
+		return err
+	}
+
 	if block == nil {
 		k := canonicalOutPoint(&rec.Hash, index)
 		v := valueUnminedCredit(dcrutil.Amount(rec.MsgTx.TxOut[index].Value),
@@ -845,7 +961,7 @@ func (s *Store) addCredit(ns walletdb.Bucket, rec *TxRecord, block *BlockMeta,
 		isCoinbase: isCoinbase,
 	}
 	v = valueUnspentCredit(&cred)
-	err := putRawCredit(ns, k, v)
+	err = putRawCredit(ns, k, v)
 	if err != nil {
 		return err
 	}
@@ -1058,7 +1174,12 @@ func (s *Store) Rollback(height int32) error {
 	defer s.mutex.Unlock()
 
 	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
-		return s.rollback(ns, height)
+		err := s.rollback(ns, height)
+		if err != nil {
+			return err
+		}
+		s.sampleInvariants(ns, "a rollback")
+		return nil
 	})
 }
 
@@ -1335,6 +1456,21 @@ func (s *Store) rollback(ns walletdb.Bucket, height int32) error {
 
 	topHeight, err := fetchChainHeight(ns, height)
 
+	// Keep the removed blocks and their transactions in the side chain
+	// cache in case a later reorganization returns them to the main chain.
+	// This must be done before any transactions are rolled back, as the
+	// regular transactions of a block are rolled back with its child.
+	for i := topHeight; i >= height && i > topHeight-MaxSideChainDepth; i-- {
+		b, err := fetchBlockRecord(ns, i)
+		if err != nil {
+			return err
+		}
+		err = putSideChainBlock(ns, b)
+		if err != nil {
+			return err
+		}
+	}
+
 	// This loop is inefficient; you end up getting most blocks twice and
 	// redeserializing them from db. In the future, use a block iterator in
 	// some intelligent way.
@@ -1503,6 +1639,7 @@ func (s *Store) unspentOutputs(ns walletdb.Bucket) ([]*Credit, error) {
 			PkScript:     txOut.PkScript,
 			Received:     rec.Received,
 			FromCoinBase: blockchain.IsCoinBaseTx(&rec.MsgTx),
+			Origin:       fetchCreditOrigin(ns, k),
 		}
 		unspent = append(unspent, cred)
 		numUtxos++
@@ -1555,6 +1692,7 @@ func (s *Store) unspentOutputs(ns walletdb.Bucket) ([]*Credit, error) {
 			PkScript:     txOut.PkScript,
 			Received:     rec.Received,
 			FromCoinBase: blockchain.IsCoinBaseTx(&rec.MsgTx),
+			Origin:       fetchCreditOrigin(ns, k),
 		}
 
 		unspent = append(unspent, cred)
@@ -2345,6 +2483,8 @@ func (s *Store) unspentOutputsForAmount(ns walletdb.Bucket, needed dcrutil.Amoun
 				PkScript:     txOut.PkScript,
 				Received:     rec.Received,
 				FromCoinBase: blockchain.IsCoinBaseTx(&rec.MsgTx),
+				Origin: fetchCreditOrigin(ns,
+					canonicalOutPoint(opHash, mc.index)),
 			}
 			unspent = append(unspent, cred)
 
@@ -2378,6 +2518,8 @@ func (s *Store) unspentOutputsForAmount(ns walletdb.Bucket, needed dcrutil.Amoun
 				PkScript:     txOut.PkScript,
 				Received:     time.Now(),
 				FromCoinBase: false,
+				Origin: fetchCreditOrigin(ns,
+					canonicalOutPoint(&localOp.Hash, mc.index)),
 			}
 
 			unspent = append(unspent, cred)
@@ -18,9 +18,12 @@ package wtxmgr_test
 import (
 	"bytes"
 	"encoding/hex"
+	"flag"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,8 +31,9 @@ import (
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/internal/chaintest"
 	"github.com/decred/dcrwallet/walletdb"
-	_ "github.com/decred/dcrwallet/walletdb/bdb"
+	_ "github.com/decred/dcrwallet/walletdb/memdb"
 	"github.com/decred/dcrwallet/wtxmgr"
 	. "github.com/decred/dcrwallet/wtxmgr"
 )
@@ -60,29 +64,20 @@ var (
 )
 
 func testDB() (walletdb.DB, func(), error) {
-	tmpDir, err := ioutil.TempDir("", "wtxmgr_test")
+	db, err := walletdb.Create("memdb")
 	if err != nil {
 		return nil, func() {}, err
 	}
-	db, err := walletdb.Create("bdb", filepath.Join(tmpDir, "db"))
-	return db, func() { os.RemoveAll(tmpDir) }, err
+	return db, func() { db.Close() }, nil
 }
 
 func testStore() (*Store, func(), error) {
-	tmpDir, err := ioutil.TempDir("", "wtxmgr_test")
+	db, err := walletdb.Create("memdb")
 	if err != nil {
 		return nil, func() {}, err
 	}
-	db, err := walletdb.Create("bdb", filepath.Join(tmpDir, "db"))
-	if err != nil {
-		teardown := func() {
-			os.RemoveAll(tmpDir)
-		}
-		return nil, teardown, err
-	}
 	teardown := func() {
 		db.Close()
-		os.RemoveAll(tmpDir)
 	}
 	ns, err := db.Namespace([]byte("txstore"))
 	if err != nil {
@@ -92,6 +87,19 @@ func testStore() (*Store, func(), error) {
 	return s, teardown, err
 }
 
+// changeSource is a ChangeSource which reports every output as change.
+type changeSource struct{}
+
+func (changeSource) IsChangeOutput(*wire.TxOut) (bool, error) { return true, nil }
+
+// addChangeCredit adds the output at index of a transaction record as a change
+// credit.
+func addChangeCredit(s *Store, rec *TxRecord, block *BlockMeta, index uint32) error {
+	s.SetChangeSource(changeSource{})
+	defer s.SetChangeSource(nil)
+	return s.AddCredit(rec, block, index)
+}
+
 func serializeTx(tx *dcrutil.Tx) []byte {
 	var buf bytes.Buffer
 	err := tx.MsgTx().Serialize(&buf)
@@ -163,7 +171,7 @@ func TestInsertsCreditsDebitsRollbacks(t *testing.T) {
 					return nil, err
 				}
 
-				err = s.AddCredit(rec, nil, 0, false)
+				err = s.AddCredit(rec, nil, 0)
 				return s, err
 			},
 			bal: 0,
@@ -187,7 +195,7 @@ func TestInsertsCreditsDebitsRollbacks(t *testing.T) {
 					return nil, err
 				}
 
-				err = s.AddCredit(rec, nil, 0, false)
+				err = s.AddCredit(rec, nil, 0)
 				return s, err
 			},
 			bal: 0,
@@ -211,7 +219,7 @@ func TestInsertsCreditsDebitsRollbacks(t *testing.T) {
 					return nil, err
 				}
 
-				err = s.AddCredit(rec, TstRecvTxBlockDetails, 0, false)
+				err = s.AddCredit(rec, TstRecvTxBlockDetails, 0)
 				return s, err
 			},
 			bal: dcrutil.Amount(TstRecvTx.MsgTx().TxOut[0].Value),
@@ -233,7 +241,7 @@ func TestInsertsCreditsDebitsRollbacks(t *testing.T) {
 					return nil, err
 				}
 
-				err = s.AddCredit(rec, TstRecvTxBlockDetails, 0, false)
+				err = s.AddCredit(rec, TstRecvTxBlockDetails, 0)
 				return s, err
 			},
 			bal: dcrutil.Amount(TstRecvTx.MsgTx().TxOut[0].Value),
@@ -270,7 +278,7 @@ func TestInsertsCreditsDebitsRollbacks(t *testing.T) {
 					return nil, err
 				}
 
-				err = s.AddCredit(rec, TstRecvTxBlockDetails, 0, false)
+				err = s.AddCredit(rec, TstRecvTxBlockDetails, 0)
 				return s, err
 			},
 			bal: dcrutil.Amount(TstDoubleSpendTx.MsgTx().TxOut[0].Value),
@@ -326,7 +334,7 @@ func TestInsertsCreditsDebitsRollbacks(t *testing.T) {
 					return nil, err
 				}
 
-				err = s.AddCredit(rec, nil, 0, true)
+				err = addChangeCredit(s, rec, nil, 0)
 				return s, err
 			},
 			bal: 0,
@@ -349,7 +357,7 @@ func TestInsertsCreditsDebitsRollbacks(t *testing.T) {
 				if err != nil {
 					return nil, err
 				}
-				err = s.AddCredit(rec, nil, 1, true)
+				err = addChangeCredit(s, rec, nil, 1)
 				return s, err
 			},
 			bal: 0,
@@ -438,7 +446,7 @@ func TestInsertsCreditsDebitsRollbacks(t *testing.T) {
 				if err != nil {
 					return nil, err
 				}
-				err = s.AddCredit(rec, TstRecvTxBlockDetails, 0, false)
+				err = s.AddCredit(rec, TstRecvTxBlockDetails, 0)
 				return s, err
 			},
 			bal: dcrutil.Amount(TstRecvTx.MsgTx().TxOut[0].Value),
@@ -531,7 +539,7 @@ func TestFindingSpentCredits(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(recvRec, TstRecvTxBlockDetails, 0, false)
+	err = s.AddCredit(recvRec, TstRecvTxBlockDetails, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -546,7 +554,7 @@ func TestFindingSpentCredits(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(spendingRec, TstSignedTxBlockDetails, 0, false)
+	err = s.AddCredit(spendingRec, TstSignedTxBlockDetails, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -625,11 +633,11 @@ func TestCoinbases(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(cbRec, &b100, 0, false)
+	err = s.AddCredit(cbRec, &b100, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(cbRec, &b100, 2, false)
+	err = s.AddCredit(cbRec, &b100, 2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -737,7 +745,7 @@ func TestCoinbases(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(spenderARec, nil, 0, false)
+	err = s.AddCredit(spenderARec, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -905,7 +913,7 @@ func TestCoinbases(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(spenderBRec, &bMaturity, 0, false)
+	err = s.AddCredit(spenderBRec, &bMaturity, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1022,11 +1030,11 @@ func TestMoveMultipleToSameBlock(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(cbRec, &b100, 0, false)
+	err = s.AddCredit(cbRec, &b100, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(cbRec, &b100, 1, false)
+	err = s.AddCredit(cbRec, &b100, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1043,11 +1051,11 @@ func TestMoveMultipleToSameBlock(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(spenderARec, nil, 0, false)
+	err = s.AddCredit(spenderARec, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(spenderARec, nil, 1, false)
+	err = s.AddCredit(spenderARec, nil, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1061,11 +1069,11 @@ func TestMoveMultipleToSameBlock(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(spenderBRec, nil, 0, false)
+	err = s.AddCredit(spenderBRec, nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.AddCredit(spenderBRec, nil, 1, false)
+	err = s.AddCredit(spenderBRec, nil, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1220,3 +1228,1094 @@ func TestInsertUnserializedTx(t *testing.T) {
 		t.Fatal("Serialized txs for coinbase spender do not match")
 	}
 }
+
+func TestSideChainCache(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := NewTxRecordFromMsgTx(newCoinBase(50e8), timeNow())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b100 := makeBlockMeta(100)
+	err = s.InsertTx(rec, &b100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is cached until the block is rolled back.
+	block, recs, err := s.SideChainBlock(&b100.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block != nil || len(recs) != 0 {
+		t.Fatal("Block cached before rollback")
+	}
+
+	err = s.Rollback(b100.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, recs, err = s.SideChainBlock(&b100.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block == nil {
+		t.Fatal("Rolled back block was not cached")
+	}
+	if block.Height != b100.Height || block.Hash != b100.Hash {
+		t.Fatalf("Cached block mismatch: got %v at height %d",
+			block.Hash, block.Height)
+	}
+	if len(recs) != 1 || recs[0].Hash != rec.Hash {
+		t.Fatalf("Expected cached coinbase %v, got %d records",
+			rec.Hash, len(recs))
+	}
+	if !bytes.Equal(recs[0].SerializedTx, rec.SerializedTx) {
+		t.Fatal("Serialized txs for cached coinbase do not match")
+	}
+
+	// Pruning above the block's height removes it from the cache.
+	err = s.PruneSideChainBlocks(b100.Height + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _, err = s.SideChainBlock(&b100.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block != nil {
+		t.Fatal("Block still cached after pruning")
+	}
+}
+
+func TestSigningLog(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb := newCoinBase(20e8)
+	cbHash := cb.TxSha()
+	txs := []*wire.MsgTx{
+		spendOutput(&cbHash, 0, 10e8, 9e8),
+		spendOutput(&cbHash, 0, 19e8),
+		spendOutput(&cbHash, 0, 5e8, 5e8, 9e8),
+	}
+	origins := []string{"rpc:alice", "grpc", "ticketbuyer"}
+	for i, tx := range txs {
+		seq, err := s.AppendSigningRecord(origins[i], tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq != uint64(i) {
+			t.Fatalf("Signing record %d appended with sequence %d",
+				i, seq)
+		}
+	}
+
+	recs, err := s.SigningRecords(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != len(txs) {
+		t.Fatalf("Expected %d signing records, got %d", len(txs),
+			len(recs))
+	}
+	for i, rec := range recs {
+		if rec.Sequence != uint64(i) || rec.Origin != origins[i] {
+			t.Errorf("Signing record %d: got sequence %d origin %q",
+				i, rec.Sequence, rec.Origin)
+		}
+		if rec.MsgTx.TxSha() != txs[i].TxSha() {
+			t.Errorf("Signing record %d: transaction mismatch", i)
+		}
+	}
+
+	// Records are paged by sequence number.
+	recs, err = s.SigningRecords(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Sequence != 1 {
+		t.Fatalf("Expected only signing record 1, got %d records",
+			len(recs))
+	}
+
+	// Records are selected by the time they were appended.
+	recs, err = s.SigningRecordsSince(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != len(txs) || recs[0].Sequence != 0 {
+		t.Fatalf("Expected all %d signing records in order, got %d",
+			len(txs), len(recs))
+	}
+	recs, err = s.SigningRecordsSince(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 0 {
+		t.Fatalf("Expected no future signing records, got %d",
+			len(recs))
+	}
+}
+
+func TestBlockVoteBitsApproval(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := chaintest.New(&chaincfg.TestNetParams)
+	err = c.AddListener(&chaintest.StoreListener{Store: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := c.Generate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cbRec := blocks[0].Txs[0]
+
+	checkApproval := func(hash *chainhash.Hash, want ApprovalStatus) {
+		details, err := s.TxDetails(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if details.Approval != want {
+			t.Errorf("Transaction %v: got approval %v, want %v",
+				hash, details.Approval, want)
+		}
+	}
+
+	// Without a child block the approval of block 1 is unknown.
+	checkApproval(&cbRec.Hash, ApprovalPending)
+
+	// Block 2 approves block 1 and mines a spend of the coinbase.
+	spendRec, err := c.AddTx(spendOutput(&cbRec.Hash, 0, 9e7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Mine(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkApproval(&cbRec.Hash, ApprovalApproved)
+	checkApproval(&spendRec.Hash, ApprovalPending)
+
+	// Block 3 disapproves block 2.
+	_, err = c.Mine(&chaintest.BlockTemplate{DisapproveParent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkApproval(&spendRec.Hash, ApprovalDisapproved)
+
+	voteBits, err := s.BlockVoteBits(2, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(voteBits) != 2 {
+		t.Fatalf("Expected 2 blocks, got %d", len(voteBits))
+	}
+	if voteBits[0].Height != 2 || !voteBits[0].ParentApproved() {
+		t.Errorf("Block 2 should approve its parent")
+	}
+	if voteBits[1].Height != 3 || voteBits[1].ParentApproved() {
+		t.Errorf("Block 3 should disapprove its parent")
+	}
+}
+
+func TestBalanceHistory(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b100 := BlockMeta{
+		Block:    Block{Height: 100},
+		Time:     time.Unix(1460000000, 0),
+		VoteBits: dcrutil.BlockValid,
+	}
+	cb := newCoinBase(20e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.InsertTx(cbRec, &b100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.AddCredit(cbRec, &b100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Spend the coinbase in the next block, paying a fee of 1 coin.
+	b101 := BlockMeta{
+		Block:    Block{Height: 101},
+		Time:     time.Unix(1460000300, 0),
+		VoteBits: dcrutil.BlockValid,
+	}
+	spend := spendOutput(&cbRec.Hash, 0, 19e8)
+	spendRec, err := NewTxRecordFromMsgTx(spend, b101.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.InsertTx(spendRec, &b101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.AddCredit(spendRec, &b101, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := s.BalanceHistory(0, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []BalanceDelta{
+		{Height: 100, Time: b100.Time, Delta: 20e8, Balance: 20e8},
+		{Height: 101, Time: b101.Time, Delta: -1e8, Balance: 19e8},
+	}
+	if !reflect.DeepEqual(history, expected) {
+		t.Fatalf("Balance history mismatch: got %v, want %v", history,
+			expected)
+	}
+
+	// The balance of the first block in the range includes all earlier
+	// changes.
+	history, err = s.BalanceHistory(101, 101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(history, expected[1:]) {
+		t.Fatalf("Balance history mismatch: got %v, want %v", history,
+			expected[1:])
+	}
+
+	// Rolling back the spend removes its change to the balance.
+	err = s.Rollback(b101.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err = s.BalanceHistory(0, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(history, expected[:1]) {
+		t.Fatalf("Balance history mismatch after rollback: got %v, "+
+			"want %v", history, expected[:1])
+	}
+}
+
+func TestProcessBlock(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b100 := BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(20e8, 10e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spend := spendOutput(&cbRec.Hash, 0, 19e8)
+	spendRec, err := NewTxRecordFromMsgTx(spend, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relevantTxs := []*TxRecord{cbRec, spendRec}
+
+	// An invalid credit index rejects the entire block.
+	err = s.ProcessBlock(&b100, relevantTxs, map[chainhash.Hash][]uint32{
+		cbRec.Hash:    {0, 1},
+		spendRec.Hash: {1},
+	})
+	if serr, ok := err.(Error); !ok || serr.Code != ErrInput {
+		t.Fatalf("Expected ErrInput, got %v", err)
+	}
+	blocks, err := s.BlockVoteBits(0, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 0 {
+		t.Fatalf("Block recorded by rejected ProcessBlock")
+	}
+
+	// The spend of the coinbase in the same block debits its credit.
+	err = s.ProcessBlock(&b100, relevantTxs, map[chainhash.Hash][]uint32{
+		cbRec.Hash:    {0, 1},
+		spendRec.Hash: {0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos, err := s.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unspent := make(map[wire.OutPoint]dcrutil.Amount)
+	for _, c := range utxos {
+		op := c.OutPoint
+		op.Tree = 0
+		unspent[op] = c.Amount
+	}
+	expected := map[wire.OutPoint]dcrutil.Amount{
+		{Hash: cbRec.Hash, Index: 1}:    10e8,
+		{Hash: spendRec.Hash, Index: 0}: 19e8,
+	}
+	if !reflect.DeepEqual(unspent, expected) {
+		t.Fatalf("Unspent outputs mismatch: got %v, want %v", unspent,
+			expected)
+	}
+	details, err := s.TxDetails(&spendRec.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details == nil || details.Block.Height != b100.Height ||
+		len(details.Debits) != 1 || len(details.Credits) != 1 {
+		t.Fatalf("Unexpected details for spending transaction: %+v",
+			details)
+	}
+
+	// Processing the block again is a no-op.
+	err = s.ProcessBlock(&b100, relevantTxs, map[chainhash.Hash][]uint32{
+		cbRec.Hash:    {0, 1},
+		spendRec.Hash: {0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos, err = s.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != len(expected) {
+		t.Fatalf("Expected %d unspent outputs after reprocessing, got %d",
+			len(expected), len(utxos))
+	}
+}
+
+func TestCreditOrigin(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for o := OriginNormal; o <= OriginPoolReward; o++ {
+		parsed, err := ParseCreditOrigin(o.String())
+		if err != nil || parsed != o {
+			t.Fatalf("ParseCreditOrigin(%q) = %v, %v", o.String(),
+				parsed, err)
+		}
+	}
+	if _, err := ParseCreditOrigin("unknown"); err == nil {
+		t.Fatal("Parsed unknown credit origin")
+	}
+
+	b100 := BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	cb := newCoinBase(20e8, 10e8)
+	cbRec, err := NewTxRecordFromMsgTx(cb, b100.Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.InsertTx(cbRec, &b100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint32(0); i < 2; i++ {
+		err = s.AddCredit(cbRec, &b100, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	mixedOut := wire.OutPoint{Hash: cbRec.Hash, Index: 0}
+	err = s.SetCreditOrigin(&mixedOut, OriginMixed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The output of a transaction spending only the mixed credit inherits
+	// its origin.
+	spend := spendOutput(&cbRec.Hash, 0, 19e8)
+	spendRec, err := NewTxRecordFromMsgTx(spend, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.InsertTx(spendRec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.AddCredit(spendRec, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The output of a transaction merging the mixed output with a normal
+	// credit does not.
+	merge := spendOutput(&spendRec.Hash, 0, 28e8)
+	merge.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: cbRec.Hash, Index: 1},
+		nil))
+	mergeRec, err := NewTxRecordFromMsgTx(merge, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origins := func() map[wire.OutPoint]CreditOrigin {
+		utxos, err := s.UnspentOutputs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[wire.OutPoint]CreditOrigin)
+		for _, c := range utxos {
+			op := c.OutPoint
+			op.Tree = 0
+			m[op] = c.Origin
+		}
+		return m
+	}
+	expected := map[wire.OutPoint]CreditOrigin{
+		{Hash: cbRec.Hash, Index: 1}:    OriginNormal,
+		{Hash: spendRec.Hash, Index: 0}: OriginMixed,
+	}
+	if got := origins(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Credit origins mismatch: got %v, want %v", got, expected)
+	}
+
+	err = s.InsertTx(mergeRec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.AddCredit(mergeRec, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = map[wire.OutPoint]CreditOrigin{
+		{Hash: mergeRec.Hash, Index: 0}: OriginNormal,
+	}
+	if got := origins(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Credit origins mismatch: got %v, want %v", got, expected)
+	}
+
+	// Mining the spend keeps the inherited origin.
+	b101 := BlockMeta{
+		Block: Block{Height: 101},
+		Time:  time.Now(),
+	}
+	err = s.InsertTx(spendRec, &b101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin, err := s.CreditOrigin(&wire.OutPoint{Hash: spendRec.Hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if origin != OriginMixed {
+		t.Fatalf("Mined credit origin is %v, expected %v", origin,
+			OriginMixed)
+	}
+}
+
+func TestChangeIndexes(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb := newCoinBase(20e8)
+	cbHash := cb.TxSha()
+	spend := spendOutput(&cbHash, 0, 10e8, 9e8)
+	spendRec, err := NewTxRecordFromMsgTx(spend, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.InsertTx(spendRec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []ChangeIndex{{Output: 1, Account: 0, Index: 7}}
+	err = s.RecordChangeIndexes(&spendRec.Hash, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	details, err := s.TxDetails(&spendRec.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(details.ChangeIndexes, changes) {
+		t.Fatalf("Unmined change indexes mismatch: got %v, want %v",
+			details.ChangeIndexes, changes)
+	}
+
+	// The change indexes are kept when the transaction is mined.
+	b100 := BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	err = s.InsertTx(spendRec, &b100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	details, err = s.TxDetails(&spendRec.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(details.ChangeIndexes, changes) {
+		t.Fatalf("Mined change indexes mismatch: got %v, want %v",
+			details.ChangeIndexes, changes)
+	}
+
+	// Transactions without recorded change have none.
+	cb2 := newCoinBase(30e8)
+	cb2Hash := cb2.TxSha()
+	other := spendOutput(&cb2Hash, 0, 29e8)
+	otherRec, err := NewTxRecordFromMsgTx(other, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.InsertTx(otherRec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	details, err = s.TxDetails(&otherRec.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details.ChangeIndexes) != 0 {
+		t.Fatalf("Unexpected change indexes %v", details.ChangeIndexes)
+	}
+}
+
+func TestFiatRate(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cb := newCoinBase(20e8)
+	cbHash := cb.TxSha()
+	spend := spendOutput(&cbHash, 0, 19e8)
+	spendRec, err := NewTxRecordFromMsgTx(spend, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.InsertTx(spendRec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rate := &FiatRate{
+		Currency: "USD",
+		Rate:     12.34,
+		Time:     time.Unix(time.Now().Unix(), 0),
+	}
+	recorded, err := s.RecordFiatRate(&spendRec.Hash, rate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recorded {
+		t.Fatal("Fiat rate was not recorded")
+	}
+
+	// Only the first rate is kept.
+	later := &FiatRate{Currency: "USD", Rate: 56.78, Time: time.Now()}
+	recorded, err = s.RecordFiatRate(&spendRec.Hash, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded {
+		t.Fatal("Second fiat rate was recorded")
+	}
+
+	// The rate is kept when the transaction is mined.
+	b100 := BlockMeta{
+		Block: Block{Height: 100},
+		Time:  time.Now(),
+	}
+	err = s.InsertTx(spendRec, &b100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	details, err := s.TxDetails(&spendRec.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(details.FiatRate, rate) {
+		t.Fatalf("Fiat rate mismatch: got %v, want %v",
+			details.FiatRate, rate)
+	}
+
+	// Invalid rates are rejected.
+	invalid := []*FiatRate{
+		{Currency: "", Rate: 1, Time: time.Now()},
+		{Currency: "USD", Rate: 0, Time: time.Now()},
+		{Currency: "USD", Rate: -1, Time: time.Now()},
+	}
+	for _, r := range invalid {
+		_, err := s.RecordFiatRate(&cbHash, r)
+		if err == nil {
+			t.Errorf("Invalid fiat rate %v was recorded", r)
+		}
+	}
+
+	// Transactions without a recorded rate have none.
+	r, err := s.FiatRate(&cbHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != nil {
+		t.Fatalf("Unexpected fiat rate %v", r)
+	}
+}
+
+func TestRecordCodecs(t *testing.T) {
+	t.Parallel()
+
+	isErrData := func(err error) bool {
+		serr, ok := err.(Error)
+		return ok && serr.Code == ErrData
+	}
+
+	block := &RawBlock{
+		BlockMeta: BlockMeta{
+			Block:    Block{Hash: chainhash.Hash{1}, Height: 100},
+			Time:     time.Unix(1454954400, 0),
+			VoteBits: dcrutil.BlockValid,
+		},
+		Transactions: []chainhash.Hash{{2}, {3}},
+	}
+	k, v := SerializeBlockRecord(block)
+	parsedBlock, err := ParseBlockRecord(k, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsedBlock.Hash != block.Hash || parsedBlock.Height != block.Height ||
+		!parsedBlock.Time.Equal(block.Time) ||
+		parsedBlock.VoteBits != block.VoteBits ||
+		!reflect.DeepEqual(parsedBlock.Transactions, block.Transactions) {
+		t.Errorf("Block record mismatch: got %+v, want %+v",
+			parsedBlock, block)
+	}
+	badBlocks := [][2][]byte{
+		{k[:3], v},
+		{k, v[:45]},
+		{k, v[:len(v)-1]},
+		{k, append(v[:len(v):len(v)], 0)},
+		{k, append(append([]byte(nil), v[:42]...), 0xff, 0xff, 0xff, 0xff)},
+	}
+	for i, kv := range badBlocks {
+		if _, err := ParseBlockRecord(kv[0], kv[1]); !isErrData(err) {
+			t.Errorf("Bad block record %d: got error %v, want ErrData",
+				i, err)
+		}
+	}
+
+	spender := RecordKey{
+		TxHash: chainhash.Hash{4},
+		Block:  Block{Hash: chainhash.Hash{5}, Height: 101},
+		Index:  1,
+	}
+	credit := &RawCredit{
+		Key:        RecordKey{TxHash: chainhash.Hash{2}, Block: block.Block},
+		Amount:     1e8,
+		Spent:      true,
+		Change:     true,
+		OpCode:     OP_NONSTAKE,
+		IsCoinbase: true,
+		SpentBy:    &spender,
+	}
+	k, v, err = SerializeCredit(credit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedCredit, err := ParseCredit(k, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsedCredit, credit) {
+		t.Errorf("Credit mismatch: got %+v, want %+v", parsedCredit, credit)
+	}
+	badCredits := [][2][]byte{
+		{k[:71], v},
+		{k, v[:8]},
+		{k, v[:9]},
+		{k, v[:80]},
+		{k, append(append([]byte(nil), v[:8]...), 1<<7)},
+		{k, append(append([]byte(nil), v[:8]...), 7<<2)},
+	}
+	for i, kv := range badCredits {
+		if _, err := ParseCredit(kv[0], kv[1]); !isErrData(err) {
+			t.Errorf("Bad credit %d: got error %v, want ErrData", i, err)
+		}
+	}
+	_, _, err = SerializeCredit(&RawCredit{OpCode: 0})
+	if err == nil {
+		t.Errorf("Credit with an invalid opcode was serialized")
+	}
+
+	debit := &RawDebit{Key: spender, Amount: 1e8, Credit: credit.Key}
+	k, v = SerializeDebit(debit)
+	parsedDebit, err := ParseDebit(k, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsedDebit, debit) {
+		t.Errorf("Debit mismatch: got %+v, want %+v", parsedDebit, debit)
+	}
+	if _, err := ParseDebit(k, v[:79]); !isErrData(err) {
+		t.Errorf("Short debit: got error %v, want ErrData", err)
+	}
+
+	mso := &MultisigOut{
+		OutPoint:     &wire.OutPoint{Hash: chainhash.Hash{6}, Index: 2},
+		Tree:         dcrutil.TxTreeStake,
+		ScriptHash:   [20]byte{7},
+		M:            2,
+		N:            3,
+		TxHash:       chainhash.Hash{6},
+		BlockHash:    block.Hash,
+		BlockHeight:  uint32(block.Height),
+		Amount:       5e8,
+		Spent:        true,
+		SpentBy:      spender.TxHash,
+		SpentByIndex: 3,
+	}
+	k, v, err = SerializeMultisigOut(mso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedMso, err := ParseMultisigOut(k, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsedMso, mso) {
+		t.Errorf("Multisig out mismatch: got %+v, want %+v", parsedMso, mso)
+	}
+	v[22] |= 1 << 2
+	if _, err := ParseMultisigOut(k, v); !isErrData(err) {
+		t.Errorf("Multisig out with unknown flags: got error %v, want "+
+			"ErrData", err)
+	}
+	if _, err := ParseMultisigOut(k, v[:134]); !isErrData(err) {
+		t.Errorf("Short multisig out: got error %v, want ErrData", err)
+	}
+}
+
+// updateGolden rewrites the golden files of TestGoldenDumps from the current
+// store state instead of comparing against them.
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// checkGolden compares a dump of the store against the named golden file in
+// testdata.  Missing golden files are created.
+func checkGolden(t *testing.T, s *Store, height int32, name string) {
+	got, err := s.DatabaseDump(height, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("testdata", name+".golden")
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) || *updateGolden {
+		var buf bytes.Buffer
+		err = got.WriteGolden(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.MkdirAll("testdata", 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("Wrote golden file %s", path)
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want, err := ReadGolden(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if equal, diff := want.Equals(got, false); !equal {
+		t.Errorf("%s: database state differs from the golden file "+
+			"(map 1 is the golden file, rerun with -update if the "+
+			"change is intended):\n%s", path, diff)
+	}
+}
+
+func TestGoldenDumps(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name string
+		run  func(c *chaintest.Chain, s *Store, unmined *TxRecord) error
+	}{
+		{"insert", func(*chaintest.Chain, *Store, *TxRecord) error {
+			return nil
+		}},
+		{"reorg", func(c *chaintest.Chain, s *Store, unmined *TxRecord) error {
+			_, err := c.Reorg(2, nil, &chaintest.BlockTemplate{
+				Txs: []chainhash.Hash{},
+			}, nil)
+			return err
+		}},
+		{"disapprove", func(c *chaintest.Chain, s *Store, unmined *TxRecord) error {
+			_, err := c.Mine(&chaintest.BlockTemplate{
+				Txs:              []chainhash.Hash{},
+				DisapproveParent: true,
+			})
+			return err
+		}},
+		{"prune", func(c *chaintest.Chain, s *Store, unmined *TxRecord) error {
+			return s.RemoveUnminedTx(unmined)
+		}},
+	}
+
+	for _, scenario := range scenarios {
+		s, teardown, err := testStore()
+		if err != nil {
+			teardown()
+			t.Fatal(err)
+		}
+
+		// Every scenario begins with three blocks, a mined spend of the
+		// first coinbase, and an unmined spend of the mined spend.
+		c := chaintest.New(&chaincfg.TestNetParams)
+		err = c.AddListener(&chaintest.StoreListener{Store: s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocks, err := c.Generate(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spend, err := c.AddTx(spendOutput(&blocks[0].Txs[0].Hash, 0, 6e7, 3e7))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.Mine(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unmined, err := c.AddTx(spendOutput(&spend.Hash, 1, 2e7))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = scenario.run(c, s, unmined)
+		if err != nil {
+			t.Fatalf("%s: %v", scenario.name, err)
+		}
+		checkGolden(t, s, c.Height(), scenario.name)
+		teardown()
+	}
+}
+
+func TestGoldenRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	d := &DatabaseContents{
+		MinedBalance:       3e8,
+		OneConfBalance:     2e8,
+		OneConfCalcBalance: 2e8,
+		BucketBlocks: map[string][]byte{
+			"00000001": {1, 2, 3},
+			"00000000": {4},
+		},
+		BucketScripts: map[string][]byte{"ab": {}},
+	}
+	var buf bytes.Buffer
+	if err := d.WriteGolden(&buf); err != nil {
+		t.Fatal(err)
+	}
+	first := buf.String()
+	parsed, err := ReadGolden(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if equal, diff := d.Equals(parsed, false); !equal {
+		t.Fatalf("Golden round trip mismatch:\n%s", diff)
+	}
+	buf.Reset()
+	if err := parsed.WriteGolden(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != first {
+		t.Errorf("Golden output is not canonical:\n%s\n%s", first, buf.String())
+	}
+
+	_, err = ReadGolden(strings.NewReader("[nosuchbucket]\n"))
+	if err == nil {
+		t.Errorf("Unknown bucket was accepted")
+	}
+}
+
+func TestInvariants(t *testing.T) {
+	t.Parallel()
+
+	db, err := walletdb.Create("memdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ns, err := db.Namespace([]byte("txstore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Create(ns, &chaincfg.TestNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every change applied by the listener is followed by an invariant
+	// check.
+	c := chaintest.New(&chaincfg.TestNetParams)
+	err = c.AddListener(&chaintest.StoreListener{
+		Store:           s,
+		CheckInvariants: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := c.Generate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spend, err := c.AddTx(spendOutput(&blocks[0].Txs[0].Hash, 0, 6e7, 3e7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Mine(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unmined, err := c.AddTx(spendOutput(&spend.Hash, 0, 5e7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Mine(&chaintest.BlockTemplate{
+		Txs:              []chainhash.Hash{},
+		DisapproveParent: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.RemoveUnminedTx(unmined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.CheckInvariants()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A corrupt mined balance is reported.
+	err = ns.Update(func(tx walletdb.Tx) error {
+		return tx.RootBucket().Put([]byte("bal"), make([]byte, 8))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.CheckInvariants()
+	if serr, ok := err.(Error); !ok || serr.Code != ErrData {
+		t.Fatalf("Corrupt mined balance: got error %v, want ErrData", err)
+	}
+}
+
+func TestRetiredScripts(t *testing.T) {
+	t.Parallel()
+
+	s, teardown, err := testStore()
+	defer teardown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := s.RetiredScript([]byte{0x76, 0xa9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != nil {
+		t.Fatal("Unretired script reported as retired")
+	}
+
+	err = s.RetireScript(&RetiredScript{Time: time.Now()})
+	if err == nil {
+		t.Fatal("Retiring an empty script did not fail")
+	}
+
+	cb := newCoinBase(20e8)
+	scripts := [][]byte{{0xa9, 0x14, 0x02}, {0x76, 0xa9, 0x14, 0x01}}
+	for i, pkScript := range scripts {
+		retired := &RetiredScript{
+			PkScript: pkScript,
+			Time:     time.Unix(time.Now().Unix(), 0),
+		}
+		if i == 0 {
+			retired.SweepTx = cb.TxSha()
+		}
+		err := s.RetireScript(retired)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := s.RetiredScript(pkScript)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r, retired) {
+			t.Fatalf("Retired script %d: got %v, want %v", i, r, retired)
+		}
+	}
+
+	all, err := s.RetiredScripts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != len(scripts) {
+		t.Fatalf("Got %d retired scripts, want %d", len(all), len(scripts))
+	}
+	if !bytes.Equal(all[0].PkScript, scripts[1]) ||
+		!bytes.Equal(all[1].PkScript, scripts[0]) {
+		t.Fatal("Retired scripts are not ordered by script")
+	}
+}
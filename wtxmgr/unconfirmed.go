@@ -119,6 +119,10 @@ func (s *Store) removeConflict(ns walletdb.Bucket, rec *TxRecord) error {
 		if err != nil {
 			return err
 		}
+		err = deleteCreditOrigin(ns, k)
+		if err != nil {
+			return err
+		}
 	}
 
 	// If this tx spends any previous credits (either mined or unmined), set
@@ -153,6 +157,38 @@ func (s *Store) UnminedTxs() ([]*wire.MsgTx, error) {
 }
 
 func (s *Store) unminedTxs(ns walletdb.Bucket) ([]*wire.MsgTx, error) {
+	recs, err := s.unminedTxRecords(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*wire.MsgTx, len(recs), len(recs))
+	for i, txr := range recs {
+		txs[i] = &txr.MsgTx
+	}
+
+	return txs, nil
+}
+
+// UnminedTxRecords returns the transaction records for all unmined
+// transactions which are not known to have been mined in a block.  Records
+// are ordered such that any transaction appears after all unmined
+// transactions it depends on.
+func (s *Store) UnminedTxRecords() ([]*TxRecord, error) {
+	var recs []*TxRecord
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		var err error
+		recs, err = s.unminedTxRecords(ns)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recs, nil
+}
+
+func (s *Store) unminedTxRecords(ns walletdb.Bucket) ([]*TxRecord, error) {
 	var unmined []*TxRecord
 	err := ns.Bucket(bucketUnmined).ForEach(func(k, v []byte) error {
 		// TODO: Parsing transactions from the db may be a little
@@ -173,6 +209,9 @@ func (s *Store) unminedTxs(ns walletdb.Bucket) ([]*wire.MsgTx, error) {
 		unmined = append(unmined, &rec)
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Sort by dependency on other transactions, if any.
 	g, i, err := parseTxRecsAsGraph(unmined)
@@ -211,10 +250,24 @@ func (s *Store) unminedTxs(ns walletdb.Bucket) ([]*wire.MsgTx, error) {
 		}
 	}
 
-	txs := make([]*wire.MsgTx, numTxs, numTxs)
-	for i, txr := range allTxs {
-		txs[i] = &txr.MsgTx
-	}
+	return allTxs, nil
+}
 
-	return txs, err
+// RemoveUnminedTx removes an unmined transaction record and all unmined
+// transactions which spend from it from the store.  This is intended to be
+// used to remove transactions which can never be mined, such as those which
+// double spend an output already spent by a mined transaction.
+func (s *Store) RemoveUnminedTx(rec *TxRecord) error {
+	return scopedUpdate(s.namespace, func(ns walletdb.Bucket) error {
+		if existsRawUnmined(ns, rec.Hash[:]) == nil {
+			str := "transaction is not unmined"
+			return storeError(ErrInput, str, nil)
+		}
+		err := s.removeConflict(ns, rec)
+		if err != nil {
+			return err
+		}
+		s.sampleInvariants(ns, "removing an unmined transaction")
+		return nil
+	})
 }
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2016 The Decred developers
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package wtxmgr
+
+import (
+	"github.com/decred/dcrd/blockchain/stake"
+	"github.com/decred/dcrutil"
+	"github.com/decred/dcrwallet/walletdb"
+)
+
+// ApprovalStatus describes whether the regular transaction tree of the block
+// that mined a transaction was approved by the stakeholder votes included in
+// the next block.  Regular transactions of a disapproved block are returned
+// to the mempool and must be mined again.
+type ApprovalStatus uint8
+
+// These constants define the possible approval statuses of a transaction.
+const (
+	// ApprovalNone is used for unmined transactions and for transactions
+	// of the stake tree, which can not be disapproved.
+	ApprovalNone ApprovalStatus = iota
+
+	// ApprovalPending is used for regular transactions whose block does
+	// not yet have a child recorded by the store.
+	ApprovalPending
+
+	// ApprovalApproved is used for regular transactions whose block was
+	// approved by the votes of the next block.
+	ApprovalApproved
+
+	// ApprovalDisapproved is used for regular transactions whose block
+	// was disapproved by the votes of the next block.
+	ApprovalDisapproved
+)
+
+// String returns the ApprovalStatus in human-readable form.
+func (a ApprovalStatus) String() string {
+	switch a {
+	case ApprovalNone:
+		return "none"
+	case ApprovalPending:
+		return "pending"
+	case ApprovalApproved:
+		return "approved"
+	case ApprovalDisapproved:
+		return "disapproved"
+	default:
+		return "unknown"
+	}
+}
+
+// ParentApproved returns whether the vote bits of a block approve the
+// regular transaction tree of its parent.
+func (b *BlockMeta) ParentApproved() bool {
+	return dcrutil.IsFlagSet16(b.VoteBits, dcrutil.BlockValid)
+}
+
+// fetchApprovalStatus returns the approval status of a transaction of type
+// txType mined in the block at height.
+func fetchApprovalStatus(ns walletdb.Bucket, height int32,
+	txType stake.TxType) (ApprovalStatus, error) {
+	if height < 0 || txType != stake.TxTypeRegular {
+		return ApprovalNone, nil
+	}
+
+	k, v := existsBlockRecord(ns, height+1)
+	if v == nil {
+		return ApprovalPending, nil
+	}
+	var child blockRecord
+	err := readRawBlockRecord(k, v, &child)
+	if err != nil {
+		return ApprovalNone, err
+	}
+	if dcrutil.IsFlagSet16(child.VoteBits, dcrutil.BlockValid) {
+		return ApprovalApproved, nil
+	}
+	return ApprovalDisapproved, nil
+}
+
+// BlockVoteBits returns the metadata, including the vote bits, of each block
+// recorded by the store with a height in the range [start, end].  Heights for
+// which no block has been recorded are skipped.
+func (s *Store) BlockVoteBits(start, end int32) ([]BlockMeta, error) {
+	if s.isClosed {
+		str := "tx manager is closed"
+		return nil, storeError(ErrIsClosed, str, nil)
+	}
+	if start < 0 || end < start {
+		str := "invalid block height range"
+		return nil, storeError(ErrInput, str, nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var blocks []BlockMeta
+	err := scopedView(s.namespace, func(ns walletdb.Bucket) error {
+		c := ns.Bucket(bucketBlocks).Cursor()
+		for k, v := c.Seek(keyBlockRecord(start)); k != nil; k, v = c.Next() {
+			var br blockRecord
+			err := readRawBlockRecord(k, v, &br)
+			if err != nil {
+				return err
+			}
+			if br.Height > end {
+				break
+			}
+			blocks = append(blocks, BlockMeta{
+				Block:    br.Block,
+				Time:     br.Time,
+				VoteBits: br.VoteBits,
+			})
+		}
+		return nil
+	})
+	return blocks, err
+}